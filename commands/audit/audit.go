@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	auditCmdStr = "audit"
+)
+
+var AuditCmd = &cobra.Command{
+	Use:   auditCmdStr,
+	Short: "Manages the repo's release audit records",
+	Long:  "Moves the audit records of the repo's releases into the audit log configured under 'audit-log' in the kudet config, including those of the releases made before it was configured",
+}
+
+func init() {
+	AuditCmd.AddCommand(ExportCmd)
+	AuditCmd.AddCommand(ImportCmd)
+}
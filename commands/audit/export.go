@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	exportCmdStr = "export"
+
+	sinceFlagStr              = "since"
+	includePrereleasesFlagStr = "include-prereleases"
+
+	// Only the local tags and notes are read, so there's nothing to authenticate for
+	noToken = ""
+)
+
+var since string
+var shouldIncludePrereleases bool
+var ExportCmd = &cobra.Command{
+	Use:   exportCmdStr,
+	Short: "Prints the audit records of the repo's past releases",
+	Long:  "Rebuilds the audit record of each of the repo's past releases, oldest first, from its tags, its audit note under 'refs/notes/kudet' and the changelog at its release commit, and prints them as JSON lines for 'kudet audit import'. Only the local tags and notes are read, so fetch both first.",
+	Args:  cobra.NoArgs,
+	RunE:  runExport,
+}
+
+func init() {
+	ExportCmd.Flags().StringVar(&since, sinceFlagStr, "", "If set, only the releases after this version (e.g. '1.2.0'), or committed since this date ('2006-01-02' for the start of that day, or RFC 3339), are exported")
+	ExportCmd.Flags().BoolVar(&shouldIncludePrereleases, includePrereleasesFlagStr, false, "If set, prerelease versions (e.g. '1.2.0-rc.1') are exported too")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	filter := releaser.ReleaseListFilter{
+		Since:                    since,
+		ShouldIncludePrereleases: shouldIncludePrereleases,
+	}
+	records, err := releaser.ExportAuditRecords(repository, kudetConfig, filter)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred rebuilding the audit records of the repo's releases")
+	}
+
+	out := cmd.OutOrStdout()
+	for _, record := range records {
+		recordJson, err := json.Marshal(record)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred serializing the audit record of release '%s'", record.Version)
+		}
+		fmt.Fprintln(out, string(recordJson))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	importCmdStr = "import"
+
+	recordsFilepathArgKey = "records-filepath"
+	// Reads the records from stdin, e.g. when piped from 'kudet audit export'
+	stdinFilepath = "-"
+
+	// Release notes make for long lines
+	maxRecordLineBytes = 10 * 1024 * 1024
+)
+
+var ImportCmd = &cobra.Command{
+	Use:   importCmdStr + " " + recordsFilepathArgKey,
+	Short: "Sends audit records to the repo's audit log",
+	Long:  "Sends the audit records in the file, as JSON lines printed by 'kudet audit export', to the audit log configured under 'audit-log' in the kudet config, in order. Pass '-' to read them from stdin. It stops at the first record that the audit log doesn't accept, and says which ones were sent.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImport,
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	recordsFilepath := args[0]
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+
+	recordsReader := cmd.InOrStdin()
+	if recordsFilepath != stdinFilepath {
+		recordsFile, err := os.Open(recordsFilepath)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred opening audit records file '%s'", recordsFilepath)
+		}
+		defer recordsFile.Close()
+		recordsReader = recordsFile
+	}
+	records, err := readAuditRecords(recordsReader)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the audit records")
+	}
+
+	numImported, err := releaser.ImportAuditRecords(cmd.Context(), kudetConfig.AuditLog, records)
+	out := cmd.OutOrStdout()
+	for _, record := range records[:numImported] {
+		fmt.Fprintf(out, "Imported release '%s'\n", record.Version)
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "Imported %d of %d audit records; the rest weren't sent", numImported, len(records))
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func readAuditRecords(reader io.Reader) ([]*releaser.AuditRecord, error) {
+	records := []*releaser.AuditRecord{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxRecordLineBytes)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		record := &releaser.AuditRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, stacktrace.Propagate(err, "Line %d isn't a JSON audit record", lineNum)
+		}
+		if record.Version == "" {
+			return nil, stacktrace.NewError("The audit record on line %d has no version", lineNum)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred scanning the audit records")
+	}
+	return records, nil
+}
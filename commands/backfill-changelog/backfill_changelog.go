@@ -0,0 +1,105 @@
+package backfillchangelog
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	backfillChangelogCmdStr = "backfill-changelog"
+
+	stdoutFlagStr = "stdout"
+	forceFlagStr  = "force"
+
+	// Only the local tags and history are read, so there's nothing to authenticate for
+	noToken = ""
+
+	changelogDirMode  = 0755
+	changelogFileMode = 0644
+)
+
+var shouldPrintToStdout bool
+var shouldOverwrite bool
+var BackfillChangelogCmd = &cobra.Command{
+	Use:   backfillChangelogCmdStr,
+	Short: "Reconstructs the repo's changelog from its release tags",
+	Long:  "Generates the changelog of a repo that was released without one, for onboarding it to kudet: each release tag gets a version header, under which the subjects of the commits it added since the previous release are listed. Conventional commits are sorted into breaking changes, features and fixes, leaving out housekeeping like 'chore' and 'ci' commits, and the other commits are listed as changes. The changelog gets an empty TBD header, to be filled in before the next release. Only the local tags and history are read, so fetch the tags first. An existing changelog is only replaced if it's just an empty TBD header, like the one 'kudet init' creates, or with --force. Review the result before committing it.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	BackfillChangelogCmd.Flags().BoolVar(&shouldPrintToStdout, stdoutFlagStr, false, "If set, the changelog is printed rather than written to the repo's changelog file")
+	BackfillChangelogCmd.Flags().BoolVar(&shouldOverwrite, forceFlagStr, false, "If set, an existing changelog is replaced even if it has entries")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	changelogContents, err := releaser.BackfillChangelog(repository, kudetConfig.TagParsing)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reconstructing the changelog from the repo's release tags")
+	}
+
+	out := cmd.OutOrStdout()
+	if shouldPrintToStdout {
+		fmt.Fprint(out, string(changelogContents))
+		return nil
+	}
+	changelogFilepath := filepath.Join(currentWorkingDirpath, kudetConfig.ChangelogFilepath)
+	if !shouldOverwrite {
+		if err := checkChangelogIsReplaceable(changelogFilepath); err != nil {
+			return stacktrace.Propagate(err, "Refusing to replace the existing changelog; pass --%s to replace it anyway, or --%s to print the reconstructed one instead", forceFlagStr, stdoutFlagStr)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(changelogFilepath), changelogDirMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating the directory of changelog '%s'", changelogFilepath)
+	}
+	if err := os.WriteFile(changelogFilepath, changelogContents, changelogFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the changelog to '%s'", changelogFilepath)
+	}
+	fmt.Fprintf(out, "Wrote the reconstructed changelog to '%s'; review it, and list the unreleased changes under its TBD header, before committing it\n", kudetConfig.ChangelogFilepath)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// checkChangelogIsReplaceable allows replacing a changelog that doesn't exist or only has an empty TBD header
+func checkChangelogIsReplaceable(changelogFilepath string) error {
+	existingContents, err := os.ReadFile(changelogFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return stacktrace.Propagate(err, "An error occurred reading the existing changelog at '%s'", changelogFilepath)
+	}
+	if strings.TrimSpace(string(existingContents)) == "" {
+		return nil
+	}
+	existingChangelog, err := changelog.Parse(existingContents)
+	if err == nil && len(existingChangelog.Versions) == 1 && existingChangelog.Versions[0].IsUnreleased() && existingChangelog.Versions[0].IsEmpty() {
+		return nil
+	}
+	return stacktrace.NewError("Changelog '%s' already has entries", changelogFilepath)
+}
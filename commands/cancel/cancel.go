@@ -0,0 +1,47 @@
+package cancel
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+const (
+	cancelCmdStr = "cancel <token> <version>"
+
+	confirmTimeoutFlagStr = "confirm-timeout"
+)
+
+var confirmTimeout time.Duration
+
+var CancelCmd = &cobra.Command{
+	Use:   cancelCmdStr,
+	Short: "Undoes a release within its undo window",
+	Long:  "Deletes the tags of a release from origin, pushes a commit reverting its release commit, and deletes its GitHub or GitLab release, once approved. It only works within the kudet config's 'undo-window' of the release, during which the release holds back its post-release steps; past that, release a fix instead. The token authenticates fetches and pushes and calls to the forge.",
+	Args:  cobra.ExactArgs(2),
+	RunE:  run,
+}
+
+func init() {
+	CancelCmd.Flags().DurationVar(&confirmTimeout, confirmTimeoutFlagStr, releaser.NoConfirmTimeout, "If set (e.g. '30s'), the cancellation is aborted when it isn't approved within this long, for unattended contexts")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	token := args[0]
+	version := args[1]
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	repoReleaser := releaser.NewReleaser(
+		currentWorkingDirpath,
+		token,
+		releaser.WithConfirmer(releaser.NewPromptConfirmer(cmd.InOrStdin(), confirmTimeout)),
+	)
+	if err := repoReleaser.CancelRelease(cmd.Context(), version); err != nil {
+		return stacktrace.Propagate(err, "An error occurred cancelling release '%s'", version)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package completion
+
+import (
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+)
+
+const (
+	completionCmdStr = "completion bash|zsh|fish|powershell"
+
+	bashShell       = "bash"
+	zshShell        = "zsh"
+	fishShell       = "fish"
+	powershellShell = "powershell"
+
+	noDescriptionsFlagStr = "no-descriptions"
+)
+
+var shouldOmitDescriptions bool
+
+var CompletionCmd = &cobra.Command{
+	Use:   completionCmdStr,
+	Short: "Prints the shell completion script for kudet",
+	Long: `Prints the script that completes kudet's commands and flags in the given shell, to be sourced by it, e.g.:
+
+  bash:       source <(kudet completion bash)
+  zsh:        kudet completion zsh > "${fpath[1]}/_kudet"
+  fish:       kudet completion fish > ~/.config/fish/completions/kudet.fish
+  powershell: kudet completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs:             []string{bashShell, zshShell, fishShell, powershellShell},
+	Args:                  cobra.ExactValidArgs(1),
+	DisableFlagsInUseLine: true,
+	RunE:                  run,
+}
+
+func init() {
+	CompletionCmd.Flags().BoolVar(&shouldOmitDescriptions, noDescriptionsFlagStr, false, "If set, the completions don't show the commands' and flags' descriptions")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	shell := args[0]
+	rootCmd := cmd.Root()
+	output := cmd.OutOrStdout()
+	includeDescriptions := !shouldOmitDescriptions
+	var err error
+	switch shell {
+	case bashShell:
+		err = rootCmd.GenBashCompletionV2(output, includeDescriptions)
+	case zshShell:
+		if includeDescriptions {
+			err = rootCmd.GenZshCompletion(output)
+		} else {
+			err = rootCmd.GenZshCompletionNoDesc(output)
+		}
+	case fishShell:
+		err = rootCmd.GenFishCompletion(output, includeDescriptions)
+	case powershellShell:
+		if includeDescriptions {
+			err = rootCmd.GenPowerShellCompletionWithDesc(output)
+		} else {
+			err = rootCmd.GenPowerShellCompletion(output)
+		}
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred generating the %s completion script", shell)
+	}
+	return nil
+}
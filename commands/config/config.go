@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	configCmdStr = "config"
+)
+
+var ConfigCmd = &cobra.Command{
+	Use:   configCmdStr,
+	Short: "Manages the repo's kudet configuration",
+	Long:  "Manages the .kudet.yml at the root of the repo, which configures how kudet operates on the repo",
+}
+
+func init() {
+	ConfigCmd.AddCommand(EditCmd)
+	ConfigCmd.AddCommand(UpdateBaseCmd)
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	editCmdStr = "edit"
+
+	listValuesSeparator = ","
+	// Entering this at a list prompt empties the list, since an empty answer means "keep the current value"
+	clearListAnswer = "-"
+)
+
+var EditCmd = &cobra.Command{
+	Use:   editCmdStr,
+	Short: "Interactively edits the repo's kudet configuration",
+	Long:  fmt.Sprintf("Walks through the options of the '%s' at the root of the repo with guided prompts, then validates and rewrites the file, preserving its comments.", kudet_config.KudetConfigFilename),
+	Args:  cobra.NoArgs,
+	RunE:  runEdit,
+}
+
+// configPrompt is one guided question, which edits the config document if the operator gives a new answer
+type configPrompt struct {
+	// The question is looked up when it's asked, as the language isn't known until the CLI's flags are parsed
+	questionId   i18n.MessageId
+	questionArgs []interface{}
+	currentValue func(config *kudet_config.KudetConfig) string
+	applyAnswer  func(doc *kudet_config.KudetConfigDocument, answer string)
+}
+
+var configPrompts = []configPrompt{
+	{
+		questionId: i18n.ConfigReleaseBranchQuestion,
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return config.ReleaseBranch
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetString([]string{kudet_config.ReleaseBranchKey}, answer)
+		},
+	},
+	{
+		questionId: i18n.ConfigChangelogFilepathQuestion,
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return config.ChangelogFilepath
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetString([]string{kudet_config.ChangelogFilepathKey}, answer)
+		},
+	},
+	{
+		questionId: i18n.ConfigPreReleaseScriptsFilepathQuestion,
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return config.PreReleaseScriptsFilepath
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetString([]string{kudet_config.PreReleaseScriptsFilepathKey}, answer)
+		},
+	},
+	{
+		questionId: i18n.ConfigApprovedReleaseNotesFilepathQuestion,
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return config.ApprovedReleaseNotesFilepath
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetString([]string{kudet_config.ApprovedReleaseNotesFilepathKey}, answer)
+		},
+	},
+	{
+		questionId: i18n.ConfigDownstreamManifestFilepathQuestion,
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return config.Downstream.ManifestFilepath
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetString([]string{kudet_config.DownstreamKey, kudet_config.ManifestFilepathKey}, answer)
+		},
+	},
+	{
+		questionId: i18n.ConfigMajorChangesSubheaderRegexQuestion,
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return config.MajorChangesSubheaderRegex
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetString([]string{kudet_config.MajorChangesSubheaderRegexKey}, answer)
+		},
+	},
+	{
+		questionId:   i18n.ConfigAllowedDirtyPathsQuestion,
+		questionArgs: []interface{}{listValuesSeparator, clearListAnswer},
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return strings.Join(config.AllowedDirtyPaths, listValuesSeparator)
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetStringList([]string{kudet_config.AllowedDirtyPathsKey}, splitListAnswer(answer))
+		},
+	},
+	{
+		questionId:   i18n.ConfigWebhookUrlsQuestion,
+		questionArgs: []interface{}{listValuesSeparator, clearListAnswer},
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return strings.Join(config.Notifications.WebhookUrls, listValuesSeparator)
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetStringList([]string{kudet_config.NotificationsKey, kudet_config.WebhookUrlsKey}, splitListAnswer(answer))
+		},
+	},
+	{
+		questionId:   i18n.ConfigRequiredCiChecksQuestion,
+		questionArgs: []interface{}{listValuesSeparator, clearListAnswer},
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return strings.Join(config.Ci.RequiredChecks, listValuesSeparator)
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetStringList([]string{kudet_config.CiKey, kudet_config.RequiredChecksKey}, splitListAnswer(answer))
+		},
+	},
+	{
+		questionId:   i18n.ConfigForgeTypeQuestion,
+		questionArgs: []interface{}{kudet_config.ForgeTypes},
+		currentValue: func(config *kudet_config.KudetConfig) string {
+			return config.Forge.Type
+		},
+		applyAnswer: func(doc *kudet_config.KudetConfigDocument, answer string) {
+			doc.SetString([]string{kudet_config.ForgeKey, kudet_config.ForgeTypeKey}, answer)
+		},
+	},
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	doc, err := kudet_config.LoadKudetConfigDocument(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config for editing")
+	}
+	config, err := doc.GetConfig()
+	if err != nil {
+		return stacktrace.Propagate(err, "The existing kudet config is invalid; please fix it by hand before editing it")
+	}
+
+	out := cmd.OutOrStdout()
+	reader := bufio.NewReader(cmd.InOrStdin())
+	fmt.Fprintln(out, i18n.Sprintf(i18n.ConfigEditKeepCurrentValueHint))
+	for _, prompt := range configPrompts {
+		question := i18n.Sprintf(prompt.questionId, prompt.questionArgs...)
+		for {
+			fmt.Fprintf(out, "%s [%s]: ", question, prompt.currentValue(config))
+			answer, err := readAnswer(reader)
+			if err != nil {
+				return stacktrace.Propagate(err, "An error occurred reading the answer to '%s'; no changes were written", question)
+			}
+			if answer == "" {
+				break
+			}
+			// The answer is tried out on a copy so that an invalid one leaves the document untouched
+			candidateDoc, err := doc.Clone()
+			if err != nil {
+				return stacktrace.Propagate(err, "An error occurred copying the kudet config document")
+			}
+			prompt.applyAnswer(candidateDoc, answer)
+			updatedConfig, err := candidateDoc.GetConfig()
+			if err != nil {
+				fmt.Fprintln(out, i18n.Sprintf(i18n.ConfigEditInvalidValue, stacktrace.RootCause(err)))
+				continue
+			}
+			doc = candidateDoc
+			config = updatedConfig
+			break
+		}
+	}
+
+	if err := doc.Save(currentWorkingDirpath); err != nil {
+		return stacktrace.Propagate(err, "An error occurred saving the kudet config")
+	}
+	fmt.Fprintln(out, i18n.Sprintf(i18n.ConfigEditSaved, kudet_config.KudetConfigFilename))
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func readAnswer(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && !(err == io.EOF && line != "") {
+		return "", stacktrace.Propagate(err, "An error occurred reading from the input")
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func splitListAnswer(answer string) []string {
+	values := []string{}
+	if answer == clearListAnswer {
+		return values
+	}
+	for _, value := range strings.Split(answer, listValuesSeparator) {
+		trimmedValue := strings.TrimSpace(value)
+		if trimmedValue == "" {
+			continue
+		}
+		values = append(values, trimmedValue)
+	}
+	return values
+}
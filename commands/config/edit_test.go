@@ -0,0 +1,61 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitListAnswer(t *testing.T) {
+	require.Equal(t, []string{"https://a.example", "https://b.example"}, splitListAnswer(" https://a.example ,https://b.example,"))
+	require.Equal(t, []string{}, splitListAnswer(clearListAnswer))
+}
+
+func TestRunEdit(t *testing.T) {
+	repoDirpath := t.TempDir()
+	configYaml := `# Shared release settings
+release-branch: main # cut releases from here
+# Where the release notes live
+changelog-filepath: CHANGELOG.md
+`
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, kudet_config.KudetConfigFilename), []byte(configYaml), 0644))
+	originalWorkingDirpath, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repoDirpath))
+	defer func() {
+		require.NoError(t, os.Chdir(originalWorkingDirpath))
+	}()
+
+	// One answer per prompt, in order: the release branch is changed, the webhook URL is re-asked for after an invalid
+	// one, and everything else keeps its current value
+	answers := []string{"develop", "", "", "", "", "", "", "ftp://hooks.example.com/release", "https://hooks.example.com/release", "", ""}
+	out := &bytes.Buffer{}
+	EditCmd.SetIn(strings.NewReader(strings.Join(answers, "\n") + "\n"))
+	EditCmd.SetOut(out)
+	defer EditCmd.SetIn(nil)
+	defer EditCmd.SetOut(nil)
+	require.NoError(t, runEdit(EditCmd, nil))
+
+	webhookUrlsQuestion := i18n.Sprintf(i18n.ConfigWebhookUrlsQuestion, listValuesSeparator, clearListAnswer)
+	require.Equal(t, 2, strings.Count(out.String(), webhookUrlsQuestion))
+	require.Contains(t, out.String(), "Invalid value: ")
+	require.Contains(t, out.String(), i18n.Sprintf(i18n.ConfigEditSaved, kudet_config.KudetConfigFilename))
+
+	editedConfigBytes, err := os.ReadFile(filepath.Join(repoDirpath, kudet_config.KudetConfigFilename))
+	require.NoError(t, err)
+	expectedYaml := `# Shared release settings
+release-branch: develop # cut releases from here
+# Where the release notes live
+changelog-filepath: CHANGELOG.md
+notifications:
+  webhook-urls:
+    - https://hooks.example.com/release
+`
+	require.Equal(t, expectedYaml, string(editedConfigBytes))
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+const (
+	updateBaseCmdStr = "update-base"
+)
+
+var UpdateBaseCmd = &cobra.Command{
+	Use:   updateBaseCmdStr,
+	Short: "Locks the base config that the repo's kudet configuration extends",
+	Long:  fmt.Sprintf("Fetches the base config that the '%s' extends as of its ref, and locks it to the commit that the ref resolves to now by writing a copy of it to '%s', which should be committed. The config is only updated if it's still valid once merged into the new base.", kudet_config.KudetConfigFilename, kudet_config.BaseConfigLockFilename),
+	Args:  cobra.NoArgs,
+	RunE:  runUpdateBase,
+}
+
+func runUpdateBase(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	configFilepath := filepath.Join(currentWorkingDirpath, kudet_config.KudetConfigFilename)
+	configBytes, err := os.ReadFile(configFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the kudet config file at '%s'", configFilepath)
+	}
+	config, err := kudet_config.ParseKudetConfig(configBytes)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing the kudet config file at '%s'", configFilepath)
+	}
+	extendsConfig := config.Extends
+	if extendsConfig == (kudet_config.ExtendsConfig{}) {
+		return stacktrace.NewError("The kudet config doesn't extend a base config; set '%s' to the one it should", kudet_config.ExtendsKey)
+	}
+	token := ""
+	if extendsConfig.TokenEnvVar != "" {
+		token = os.Getenv(extendsConfig.TokenEnvVar)
+		if token == "" {
+			return stacktrace.NewError("The base config's repo is read with the token in environment variable '%s', which isn't set", extendsConfig.TokenEnvVar)
+		}
+	}
+
+	baseConfigBytes, commitHash, err := vcs.ReadGitFileAtRevision(cmd.Context(), extendsConfig.Url, token, extendsConfig.Ref, extendsConfig.GetPath())
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred fetching base config '%s' at '%s' of '%s'", extendsConfig.GetPath(), extendsConfig.Ref, extendsConfig.Url)
+	}
+	lock, err := kudet_config.NewBaseConfigLock(extendsConfig, commitHash, baseConfigBytes)
+	if err != nil {
+		return stacktrace.Propagate(err, "Base config '%s' at commit '%s' of '%s' can't be extended", extendsConfig.GetPath(), commitHash, extendsConfig.Url)
+	}
+	if _, err := lock.ExtendKudetConfig(configBytes); err != nil {
+		return stacktrace.Propagate(err, "The kudet config isn't valid once merged into the base config at commit '%s', so the lock wasn't updated", commitHash)
+	}
+	if err := lock.Save(currentWorkingDirpath); err != nil {
+		return stacktrace.Propagate(err, "An error occurred saving the base config lock")
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Locked '%s' at '%s' of '%s' to commit '%s' in '%s'\n", lock.Path, lock.Ref, lock.Url, lock.CommitHash, kudet_config.BaseConfigLockFilename)
+	return nil
+}
@@ -0,0 +1,19 @@
+package docs
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	docsCmdStr = "docs"
+)
+
+var DocsCmd = &cobra.Command{
+	Use:   docsCmdStr,
+	Short: "Generates kudet's documentation",
+	Long:  "Generates documentation of kudet's commands and flags from the commands themselves, so that it never falls behind the binary",
+}
+
+func init() {
+	DocsCmd.AddCommand(ManCmd)
+}
@@ -0,0 +1,176 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	manCmdStr = "man"
+
+	dirFlagStr     = "dir"
+	defaultDirpath = "."
+
+	// Section 1 is for user commands
+	manSection         = "1"
+	manPageFileMode    = 0644
+	manPageDirMode     = 0755
+	manPageManualTitle = "Kudet Manual"
+)
+
+var manPageDirpath string
+
+var ManCmd = &cobra.Command{
+	Use:   manCmdStr,
+	Short: "Writes man pages for kudet's commands",
+	Long:  "Writes a roff man page for kudet and each of its commands, e.g. 'kudet-release.1', to the directory, to be installed under a 'man1' directory on the MANPATH.",
+	Args:  cobra.NoArgs,
+	RunE:  runMan,
+}
+
+func init() {
+	ManCmd.Flags().StringVar(&manPageDirpath, dirFlagStr, defaultDirpath, "The directory to write the man pages to, which is created if it doesn't exist")
+}
+
+func runMan(cmd *cobra.Command, args []string) error {
+	manPageFilepaths, err := writeManPages(cmd.Root(), manPageDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the man pages to '%s'", manPageDirpath)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d man pages to '%s'\n", len(manPageFilepaths), manPageDirpath)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// writeManPages writes the man pages of the command and every available command under it
+func writeManPages(cmd *cobra.Command, dirpath string) ([]string, error) {
+	if err := os.MkdirAll(dirpath, manPageDirMode); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred creating directory '%s'", dirpath)
+	}
+	manPageFilepaths := []string{}
+	var writeCmdManPages func(cmd *cobra.Command) error
+	writeCmdManPages = func(cmd *cobra.Command) error {
+		manPageFilepath := filepath.Join(dirpath, getManPageName(cmd)+"."+manSection)
+		if err := os.WriteFile(manPageFilepath, getManPage(cmd), manPageFileMode); err != nil {
+			return stacktrace.Propagate(err, "An error occurred writing the man page of '%s' to '%s'", cmd.CommandPath(), manPageFilepath)
+		}
+		manPageFilepaths = append(manPageFilepaths, manPageFilepath)
+		for _, subcommand := range getDocumentedSubcommands(cmd) {
+			if err := writeCmdManPages(subcommand); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := writeCmdManPages(cmd); err != nil {
+		return nil, err
+	}
+	return manPageFilepaths, nil
+}
+
+// getManPage renders the command's man page in roff; it isn't dated, so that regenerating it from the same binary
+// doesn't change it
+func getManPage(cmd *cobra.Command) []byte {
+	manPage := &bytes.Buffer{}
+	fmt.Fprintf(manPage, ".TH \"%s\" \"%s\" \"\" \"kudet %s\" \"%s\"\n", strings.ToUpper(getManPageName(cmd)), manSection, escapeRoff(kudet_version.KudetVersion), manPageManualTitle)
+
+	fmt.Fprintf(manPage, ".SH NAME\n%s \\- %s\n", getManPageName(cmd), escapeRoff(cmd.Short))
+	fmt.Fprintf(manPage, ".SH SYNOPSIS\n.B %s\n", escapeRoff(cmd.UseLine()))
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+	fmt.Fprintf(manPage, ".SH DESCRIPTION\n%s\n", escapeRoffParagraphs(description))
+	if cmd.Example != "" {
+		fmt.Fprintf(manPage, ".SH EXAMPLES\n.nf\n%s\n.fi\n", escapeRoff(cmd.Example))
+	}
+	writeManPageFlags(manPage, "OPTIONS", cmd.NonInheritedFlags())
+	writeManPageFlags(manPage, "OPTIONS INHERITED FROM PARENT COMMANDS", cmd.InheritedFlags())
+
+	seeAlso := []string{}
+	if cmd.HasParent() {
+		seeAlso = append(seeAlso, getManPageReference(cmd.Parent()))
+	}
+	for _, subcommand := range getDocumentedSubcommands(cmd) {
+		seeAlso = append(seeAlso, getManPageReference(subcommand))
+	}
+	if len(seeAlso) > 0 {
+		fmt.Fprintf(manPage, ".SH SEE ALSO\n%s\n", strings.Join(seeAlso, ",\n"))
+	}
+	return manPage.Bytes()
+}
+
+func writeManPageFlags(manPage *bytes.Buffer, sectionName string, flags *pflag.FlagSet) {
+	if !flags.HasAvailableFlags() {
+		return
+	}
+	fmt.Fprintf(manPage, ".SH %s\n", sectionName)
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		flagNames := fmt.Sprintf("\\fB\\-\\-%s\\fR", escapeRoff(flag.Name))
+		if flag.Shorthand != "" {
+			flagNames = fmt.Sprintf("\\fB\\-%s\\fR, %s", escapeRoff(flag.Shorthand), flagNames)
+		}
+		usage := flag.Usage
+		if flag.DefValue != "" && flag.DefValue != "false" && flag.DefValue != "[]" {
+			usage = fmt.Sprintf("%s (default '%s')", usage, flag.DefValue)
+		}
+		fmt.Fprintf(manPage, ".TP\n%s\n%s\n", flagNames, escapeRoff(usage))
+	})
+}
+
+// getDocumentedSubcommands leaves out the help command and any hidden or deprecated commands
+func getDocumentedSubcommands(cmd *cobra.Command) []*cobra.Command {
+	subcommands := []*cobra.Command{}
+	for _, subcommand := range cmd.Commands() {
+		if subcommand.IsAvailableCommand() {
+			subcommands = append(subcommands, subcommand)
+		}
+	}
+	return subcommands
+}
+
+// getManPageName is the command's path joined with dashes, e.g. 'kudet-config-edit'
+func getManPageName(cmd *cobra.Command) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+}
+
+func getManPageReference(cmd *cobra.Command) string {
+	return fmt.Sprintf("\\fB%s\\fP(%s)", getManPageName(cmd), manSection)
+}
+
+// escapeRoffParagraphs keeps the blank lines between paragraphs, which roff would otherwise render as is
+func escapeRoffParagraphs(text string) string {
+	paragraphs := strings.Split(strings.TrimSpace(text), "\n\n")
+	for i, paragraph := range paragraphs {
+		paragraphs[i] = escapeRoff(paragraph)
+	}
+	return strings.Join(paragraphs, "\n.PP\n")
+}
+
+// escapeRoff stops roff from interpreting the text: backslashes and dashes are escaped, and lines that start with a
+// control character are prefixed with a zero-width space
+func escapeRoff(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\e")
+	text = strings.ReplaceAll(text, "-", "\\-")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = "\\&" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,35 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteManPages(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "kudet <action>", Short: "Releases repos"}
+	rootCmd.PersistentFlags().Bool("verbose", false, "Logs more")
+	configCmd := &cobra.Command{Use: "config", Short: "Manages the config"}
+	editCmd := &cobra.Command{Use: "edit", Short: "Edits the config", Long: "Edits the config.\n\n.kudet.yml is rewritten in place", Run: func(cmd *cobra.Command, args []string) {}}
+	editCmd.Flags().String("set", "", "The key=value to set")
+	hiddenCmd := &cobra.Command{Use: "hidden", Short: "Isn't documented", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}}
+	configCmd.AddCommand(editCmd, hiddenCmd)
+	rootCmd.AddCommand(configCmd)
+
+	dirpath := filepath.Join(t.TempDir(), "man1")
+	manPageFilepaths, err := writeManPages(rootCmd, dirpath)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dirpath, "kudet.1"), filepath.Join(dirpath, "kudet-config.1"), filepath.Join(dirpath, "kudet-config-edit.1")}, manPageFilepaths)
+
+	editManPage, err := os.ReadFile(filepath.Join(dirpath, "kudet-config-edit.1"))
+	require.NoError(t, err)
+	require.Contains(t, string(editManPage), ".TH \"KUDET-CONFIG-EDIT\" \"1\"")
+	require.Contains(t, string(editManPage), ".SH NAME\nkudet-config-edit \\- Edits the config\n")
+	require.Contains(t, string(editManPage), ".SH DESCRIPTION\nEdits the config.\n.PP\n\\&.kudet.yml is rewritten in place\n")
+	require.Contains(t, string(editManPage), ".SH OPTIONS\n.TP\n\\fB\\-\\-set\\fR\nThe key=value to set\n")
+	require.Contains(t, string(editManPage), ".SH OPTIONS INHERITED FROM PARENT COMMANDS\n.TP\n\\fB\\-\\-verbose\\fR\nLogs more\n")
+	require.Contains(t, string(editManPage), ".SH SEE ALSO\n\\fBkudet-config\\fP(1)\n")
+}
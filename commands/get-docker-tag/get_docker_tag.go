@@ -9,7 +9,7 @@ import (
 	"github.com/kurtosis-tech/stacktrace"
 	"github.com/spf13/cobra"
 	"os"
-	"path"
+	"path/filepath"
 	"regexp"
 	"sort"
 )
@@ -37,7 +37,7 @@ func run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
 	}
-	gitDirpath := path.Join(currentWorkingDirpath, gitDirname)
+	gitDirpath := filepath.Join(currentWorkingDirpath, gitDirname)
 	if _, err := os.Stat(gitDirpath); err != nil {
 		if os.IsNotExist(err) {
 			return stacktrace.Propagate(err, "An error occurred getting the git repository in this directory. This means that this binary is not being run from root of a git repository.")
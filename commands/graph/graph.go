@@ -0,0 +1,46 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	graphCmdStr = "graph"
+
+	formatFlagStr = "format"
+)
+
+var format string
+var GraphCmd = &cobra.Command{
+	Use:   graphCmdStr,
+	Short: "Renders the repo's release pipeline as a graph",
+	Long:  "Renders the steps that 'kudet release' goes through in this repo as a dependency graph, derived from the repo's kudet configuration, with the gates that can refuse the release and the hooks (pre-release scripts, git hooks and webhooks) that the steps run. The graph is printed in Graphviz DOT or Mermaid, to be rendered or embedded in docs.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	GraphCmd.Flags().StringVar(&format, formatFlagStr, releaser.DotGraphFormat, fmt.Sprintf("The format of the graph, one of '%s'", releaser.GraphFormats))
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config for the repo")
+	}
+	graph, err := releaser.RenderPipelineGraph(currentWorkingDirpath, kudetConfig, format)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred rendering the release pipeline graph")
+	}
+	fmt.Fprint(cmd.OutOrStdout(), graph)
+	return nil
+}
@@ -0,0 +1,157 @@
+// Package initcmd holds the 'init' command; it can't be called 'init' like its directory because Go reserves the name
+package initcmd
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	initCmdStr = "init"
+
+	githubWorkflowFlagStr = "github-workflow"
+
+	githubWorkflowRelFilepath = ".github/workflows/kudet-release.yml"
+
+	scaffoldDirMode  = 0755
+	scaffoldFileMode = 0644
+
+	changelogTemplate = "# TBD\n"
+
+	kudetConfigTemplate = `# The branch releases are cut from
+%s: %s
+# The changelog that gets validated and finalized on release
+%s: %s
+# The file listing the scripts to run before the release commit, one per line
+%s: %s
+`
+
+	// Releases are confirmed on stdin, so the workflow answers the prompt; a RELEASE_TOKEN secret that can push to the
+	// release branch and tags must be added to the repo
+	githubWorkflowTemplate = `name: Release
+on:
+  workflow_dispatch:
+    inputs:
+      bump-major:
+        description: "Bump the major version regardless of what the changelog says"
+        type: boolean
+        default: false
+
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v3
+        with:
+          ref: %s
+          fetch-depth: 0
+          token: ${{ secrets.RELEASE_TOKEN }}
+      - uses: actions/setup-go@v4
+        with:
+          go-version: "1.18"
+      - name: Install kudet
+        run: go install github.com/kurtosis-tech/kudet@latest
+      - name: Configure git
+        run: |
+          git config user.name "github-actions[bot]"
+          git config user.email "github-actions[bot]@users.noreply.github.com"
+      - name: Release
+        run: echo y | kudet release "${{ secrets.RELEASE_TOKEN }}" --bump-major=${{ inputs.bump-major }}
+`
+)
+
+// scaffoldFile is a file that 'kudet init' creates, unless the repo already has it
+type scaffoldFile struct {
+	relFilepath string
+	contents    string
+}
+
+var shouldCreateGithubWorkflow bool
+var InitCmd = &cobra.Command{
+	Use:   initCmdStr,
+	Short: "Scaffolds the files kudet expects in the repo",
+	Long:  "Creates a changelog with a TBD header, an empty pre-release scripts file and a starter kudet config in the repo, and optionally a GitHub Actions workflow that releases it with kudet. Files that already exist are left as they are.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	InitCmd.Flags().BoolVar(&shouldCreateGithubWorkflow, githubWorkflowFlagStr, false, fmt.Sprintf("If set, also creates a GitHub Actions workflow at '%s' that runs 'kudet release' when dispatched by hand, using a RELEASE_TOKEN repo secret", githubWorkflowRelFilepath))
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	files := getScaffoldFiles(shouldCreateGithubWorkflow)
+	if err := writeScaffoldFiles(currentWorkingDirpath, files, cmd.OutOrStdout()); err != nil {
+		return stacktrace.Propagate(err, "An error occurred scaffolding the kudet files in '%s'", currentWorkingDirpath)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getScaffoldFiles returns the files a repo needs to be released with kudet, following its default conventions
+func getScaffoldFiles(shouldIncludeGithubWorkflow bool) []scaffoldFile {
+	defaultConfig := kudet_config.NewDefaultKudetConfig()
+	files := []scaffoldFile{
+		{
+			relFilepath: defaultConfig.ChangelogFilepath,
+			contents:    changelogTemplate,
+		},
+		{
+			relFilepath: defaultConfig.PreReleaseScriptsFilepath,
+			contents:    "",
+		},
+		{
+			relFilepath: kudet_config.KudetConfigFilename,
+			contents: fmt.Sprintf(
+				kudetConfigTemplate,
+				kudet_config.ReleaseBranchKey,
+				defaultConfig.ReleaseBranch,
+				kudet_config.ChangelogFilepathKey,
+				defaultConfig.ChangelogFilepath,
+				kudet_config.PreReleaseScriptsFilepathKey,
+				defaultConfig.PreReleaseScriptsFilepath,
+			),
+		},
+	}
+	if shouldIncludeGithubWorkflow {
+		files = append(files, scaffoldFile{
+			relFilepath: githubWorkflowRelFilepath,
+			contents:    fmt.Sprintf(githubWorkflowTemplate, defaultConfig.ReleaseBranch),
+		})
+	}
+	return files
+}
+
+// writeScaffoldFiles creates each file that doesn't exist yet under the repo, never touching the ones that do
+func writeScaffoldFiles(repoDirpath string, files []scaffoldFile, out io.Writer) error {
+	for _, file := range files {
+		scaffoldFilepath := filepath.Join(repoDirpath, file.relFilepath)
+		if _, err := os.Stat(scaffoldFilepath); err == nil {
+			fmt.Fprintf(out, "Skipped '%s', which already exists\n", file.relFilepath)
+			continue
+		} else if !os.IsNotExist(err) {
+			return stacktrace.Propagate(err, "An error occurred checking whether '%s' exists", scaffoldFilepath)
+		}
+		if err := os.MkdirAll(filepath.Dir(scaffoldFilepath), scaffoldDirMode); err != nil {
+			return stacktrace.Propagate(err, "An error occurred creating the directory of '%s'", scaffoldFilepath)
+		}
+		if err := os.WriteFile(scaffoldFilepath, []byte(file.contents), scaffoldFileMode); err != nil {
+			return stacktrace.Propagate(err, "An error occurred writing '%s'", scaffoldFilepath)
+		}
+		fmt.Fprintf(out, "Created '%s'\n", file.relFilepath)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package initcmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteScaffoldFiles_SkipsExistingFiles(t *testing.T) {
+	repoDirpath := t.TempDir()
+	existingChangelog := "# TBD\n* Something\n"
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDirpath, "docs"), scaffoldDirMode))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "docs/changelog.md"), []byte(existingChangelog), scaffoldFileMode))
+
+	out := &bytes.Buffer{}
+	require.NoError(t, writeScaffoldFiles(repoDirpath, getScaffoldFiles(true), out))
+
+	changelog, err := os.ReadFile(filepath.Join(repoDirpath, "docs/changelog.md"))
+	require.NoError(t, err)
+	require.Equal(t, existingChangelog, string(changelog))
+	for _, relFilepath := range []string{".pre-release-scripts.txt", kudet_config.KudetConfigFilename, githubWorkflowRelFilepath} {
+		require.FileExists(t, filepath.Join(repoDirpath, relFilepath))
+	}
+	require.Contains(t, out.String(), "Skipped 'docs/changelog.md'")
+
+	// The starter config must be one kudet accepts
+	kudetConfig, err := kudet_config.LoadKudetConfig(repoDirpath)
+	require.NoError(t, err)
+	require.Equal(t, kudet_config.NewDefaultKudetConfig().ReleaseBranch, kudetConfig.ReleaseBranch)
+}
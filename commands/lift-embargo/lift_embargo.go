@@ -0,0 +1,58 @@
+package liftembargo
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	liftEmbargoCmdStr = "lift-embargo <token> <version>"
+
+	metadataDirFlagStr     = "metadata-dir"
+	uploadMetadataFlagStr  = "upload-metadata"
+	uploadArtifactsFlagStr = "upload-artifacts"
+	promoteFlagStr         = "promote"
+)
+
+var metadataDirpath string
+var shouldUploadMetadata bool
+var shouldUploadArtifacts bool
+var promotedEnvironmentNames []string
+
+var LiftEmbargoCmd = &cobra.Command{
+	Use:   liftEmbargoCmdStr,
+	Short: "Pushes a release prepared under embargo",
+	Long:  "Pushes the commit and tags of a release prepared with 'kudet release --embargo' and publishes its forge release, for running at the coordinated disclosure time. It must be run from the clone that prepared the release.",
+	Args:  cobra.ExactArgs(2),
+	RunE:  run,
+}
+
+func init() {
+	LiftEmbargoCmd.Flags().StringVar(&metadataDirpath, metadataDirFlagStr, "", "If set, a release-metadata.json describing the release is written to this directory once it's pushed")
+	LiftEmbargoCmd.Flags().BoolVar(&shouldUploadMetadata, uploadMetadataFlagStr, false, "If set, the release-metadata.json is uploaded as an asset of the release on GitHub")
+	LiftEmbargoCmd.Flags().BoolVar(&shouldUploadArtifacts, uploadArtifactsFlagStr, false, "If set, the artifacts that the pre-release scripts produced when the release was prepared are uploaded as assets of the release on GitHub")
+	LiftEmbargoCmd.Flags().StringSliceVar(&promotedEnvironmentNames, promoteFlagStr, nil, "The environments from the kudet config to promote the release to once it's pushed, on top of those promoted to on every release")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	token := args[0]
+	version := args[1]
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	repoReleaser := releaser.NewReleaser(
+		currentWorkingDirpath,
+		token,
+		releaser.WithMetadataOutput(metadataDirpath),
+		releaser.WithMetadataUpload(shouldUploadMetadata),
+		releaser.WithArtifactUpload(shouldUploadArtifacts),
+		releaser.WithPromotedEnvironments(promotedEnvironmentNames),
+	)
+	if err := repoReleaser.LiftEmbargo(cmd.Context(), version); err != nil {
+		return stacktrace.Propagate(err, "An error occurred lifting the embargo on version '%s'", version)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package lintrepo
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	lintRepoCmdStr = "lint-repo [token]"
+
+	passedCheckMarker = "[PASS]"
+	failedCheckMarker = "[FAIL]"
+)
+
+var LintRepoCmd = &cobra.Command{
+	Use:   lintRepoCmdStr,
+	Short: "Checks that the repo meets what releasing it needs",
+	Long:  "Prints a checklist of what releasing the repo needs: a changelog whose first line is its TBD header, pre-release scripts that exist and are executable, git's user.name and user.email, the origin remote, the release branch and, if a token is given, the token's scopes on the forge. Nothing is fetched, committed or pushed, so new repos can run it before their first release. Fails if any check does.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  run,
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	token := ""
+	if len(args) > 0 {
+		token = args[0]
+	}
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	checks := releaser.LintRepo(cmd.Context(), currentWorkingDirpath, token, kudetConfig)
+
+	out := cmd.OutOrStdout()
+	numFailedChecks := 0
+	for _, check := range checks {
+		marker := passedCheckMarker
+		if !check.IsPassed {
+			marker = failedCheckMarker
+			numFailedChecks++
+		}
+		fmt.Fprintf(out, "%s %s\n", marker, check.Description)
+		if check.Detail != "" {
+			fmt.Fprintf(out, "       %s\n", check.Detail)
+		}
+	}
+	if numFailedChecks > 0 {
+		return stacktrace.NewError("%d of the %d checks failed", numFailedChecks, len(checks))
+	}
+	fmt.Fprintln(out, "The repo is ready to be released")
+	return nil
+}
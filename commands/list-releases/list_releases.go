@@ -0,0 +1,112 @@
+package listreleases
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	listReleasesCmdStr = "list-releases"
+
+	sinceFlagStr              = "since"
+	limitFlagStr              = "limit"
+	jsonFlagStr               = "json"
+	includePrereleasesFlagStr = "include-prereleases"
+
+	// Only the local tags are listed, so there's nothing to authenticate for
+	noToken = ""
+
+	jsonOutputIndent = "  "
+	vPrefix          = "v"
+)
+
+var since string
+var limit int
+var shouldOutputJson bool
+var shouldIncludePrereleases bool
+var ListReleasesCmd = &cobra.Command{
+	Use:   listReleasesCmdStr,
+	Short: "Lists the repo's releases",
+	Long:  "Lists the versions that the repo's semver tags release, latest first, with the date and commit of each and which of its 'X.Y.Z' and 'vX.Y.Z' tags exist. Versions that the repo's tag parsing config ignores when determining the latest release are listed too, and marked as such, for debugging version detection. Only the local tags are read, so fetch first to list the remote's.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	ListReleasesCmd.Flags().StringVar(&since, sinceFlagStr, "", "If set, only the releases after this version (e.g. '1.2.0'), or committed since this date ('2006-01-02' for the start of that day, or RFC 3339), are listed")
+	ListReleasesCmd.Flags().IntVar(&limit, limitFlagStr, 0, "If set, only this many of the latest releases are listed")
+	ListReleasesCmd.Flags().BoolVar(&shouldOutputJson, jsonFlagStr, false, "If set, the releases are printed as a JSON array, for dashboards and scripts")
+	ListReleasesCmd.Flags().BoolVar(&shouldIncludePrereleases, includePrereleasesFlagStr, false, "If set, prerelease versions (e.g. '1.2.0-rc.1') are listed too")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	if limit < 0 {
+		return stacktrace.NewError("The '--%s' flag must not be negative, but was '%d'", limitFlagStr, limit)
+	}
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	filter := releaser.ReleaseListFilter{
+		Since:                    since,
+		Limit:                    limit,
+		ShouldIncludePrereleases: shouldIncludePrereleases,
+	}
+	releases, err := releaser.ListReleases(repository, kudetConfig.TagParsing, filter)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred listing the repo's releases")
+	}
+
+	out := cmd.OutOrStdout()
+	if shouldOutputJson {
+		releasesJson, err := json.MarshalIndent(releases, "", jsonOutputIndent)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred serializing the releases")
+		}
+		fmt.Fprintln(out, string(releasesJson))
+		return nil
+	}
+	for _, release := range releases {
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", release.Version, release.Date.Format(time.RFC3339), release.CommitHash, strings.Join(getReleaseNotes(release), ", "))
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getReleaseNotes describes the release's tags, and whether the releaser ignores it
+func getReleaseNotes(release releaser.ReleaseListing) []string {
+	notes := []string{}
+	if !release.HasTag {
+		notes = append(notes, fmt.Sprintf("no '%s' tag", release.Version))
+	}
+	if !release.HasVTag {
+		notes = append(notes, fmt.Sprintf("no '%s%s' tag", vPrefix, release.Version))
+	}
+	if release.IsPrerelease {
+		notes = append(notes, "prerelease")
+	}
+	if release.IsIgnored {
+		notes = append(notes, "ignored")
+	}
+	return notes
+}
@@ -0,0 +1,92 @@
+package notesfor
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+const (
+	notesForCmdStr = "notes-for"
+
+	pathsFlagStr = "paths"
+	fromFlagStr  = "from"
+	toFlagStr    = "to"
+
+	// Only the local tags and history are read, so there's nothing to authenticate for
+	noToken = ""
+
+	unlistedCommitsHeader = "## Other changes"
+	vPrefix               = "v"
+)
+
+var paths []string
+var fromVersion string
+var toVersion string
+var NotesForCmd = &cobra.Command{
+	Use:   notesForCmdStr,
+	Short: "Prints the release notes that concern some of the repo's paths",
+	Long:  "Prints the changelog entries of the releases after '--from' up to '--to' that were added by commits touching the given paths, e.g. an SDK's directory, so that its consumers only get the changes that affect them. The commits that touched the paths without adding a changelog entry are listed after the releases. Only the local tags and history are read, so fetch first.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	NotesForCmd.Flags().StringSliceVar(&paths, pathsFlagStr, nil, "The files or directories relative to the repo root, or globs, whose changes are kept (e.g. 'sdk/typescript')")
+	NotesForCmd.Flags().StringVar(&fromVersion, fromFlagStr, "", "The release that the notes start after, e.g. the version the consumers are on")
+	NotesForCmd.Flags().StringVar(&toVersion, toFlagStr, "", "The last release that the notes cover; defaults to the latest release")
+	for _, requiredFlagStr := range []string{pathsFlagStr, fromFlagStr} {
+		if err := NotesForCmd.MarkFlagRequired(requiredFlagStr); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	notes, err := releaser.GetConsumerNotes(repository, kudetConfig, strings.TrimPrefix(fromVersion, vPrefix), strings.TrimPrefix(toVersion, vPrefix), paths)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the release notes for paths '%s'", strings.Join(paths, "', '"))
+	}
+
+	out := cmd.OutOrStdout()
+	if len(notes.Releases) == 0 && len(notes.UnlistedCommitSubjects) == 0 {
+		fmt.Fprintln(out, "Nothing changed in these paths")
+		return nil
+	}
+	for idx, release := range notes.Releases {
+		if idx > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "# %s\n", release.Version)
+		for _, line := range release.Lines {
+			fmt.Fprintln(out, line)
+		}
+	}
+	if len(notes.UnlistedCommitSubjects) > 0 {
+		if len(notes.Releases) > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintln(out, unlistedCommitsHeader)
+		for _, subject := range notes.UnlistedCommitSubjects {
+			fmt.Fprintf(out, "* %s\n", subject)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package pinscripts
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	pinScriptsCmdStr = "pin-scripts"
+)
+
+var PinScriptsCmd = &cobra.Command{
+	Use:   pinScriptsCmdStr,
+	Short: "Pins the checksums of the repo's pre-release scripts",
+	Long:  "Records the SHA-256 checksum of each script listed in the repo's pre-release scripts file next to it, so that 'kudet release' refuses to run any of the scripts once one of them has changed without being pinned again. Commit the updated file along with the scripts.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config for the repo")
+	}
+	pinnedScriptRelFilepaths, err := releaser.PinPreReleaseScripts(currentWorkingDirpath, kudetConfig.PreReleaseScriptsFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred pinning the checksums of the pre-release scripts")
+	}
+	for _, scriptRelFilepath := range pinnedScriptRelFilepaths {
+		fmt.Fprintf(cmd.OutOrStdout(), "Pinned '%s'\n", scriptRelFilepath)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Pinned %d scripts in '%s'\n", len(pinnedScriptRelFilepaths), kudetConfig.PreReleaseScriptsFilepath)
+	return nil
+}
@@ -0,0 +1,60 @@
+package promoteenv
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+const (
+	promoteEnvCmdStr = "promote-env <token>"
+
+	versionFlagStr        = "version"
+	fromFlagStr           = "from"
+	toFlagStr             = "to"
+	confirmTimeoutFlagStr = "confirm-timeout"
+)
+
+var version string
+var fromEnvironmentName string
+var toEnvironmentName string
+var confirmTimeout time.Duration
+
+var PromoteEnvCmd = &cobra.Command{
+	Use:   promoteEnvCmdStr,
+	Short: "Promotes a version from one environment to another",
+	Long:  "Pins a version that's deployed to one of the environments in the kudet config in the manifest of another, once approved, and pushes the change to the GitOps repo as the record of the promotion. The token authenticates fetches and pushes to the GitOps repo.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  run,
+}
+
+func init() {
+	PromoteEnvCmd.Flags().StringVar(&version, versionFlagStr, "", "The version to promote, which must be the one pinned in the '--from' environment's manifest")
+	PromoteEnvCmd.Flags().StringVar(&fromEnvironmentName, fromFlagStr, "", "The environment the version is deployed to (e.g. 'staging')")
+	PromoteEnvCmd.Flags().StringVar(&toEnvironmentName, toFlagStr, "", "The environment to promote the version to (e.g. 'prod')")
+	PromoteEnvCmd.Flags().DurationVar(&confirmTimeout, confirmTimeoutFlagStr, releaser.NoConfirmTimeout, "If set (e.g. '30s'), the promotion is aborted when it isn't approved within this long, for unattended contexts")
+	for _, requiredFlagStr := range []string{versionFlagStr, fromFlagStr, toFlagStr} {
+		if err := PromoteEnvCmd.MarkFlagRequired(requiredFlagStr); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	token := args[0]
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	repoReleaser := releaser.NewReleaser(
+		currentWorkingDirpath,
+		token,
+		releaser.WithConfirmer(releaser.NewPromptConfirmer(cmd.InOrStdin(), confirmTimeout)),
+	)
+	if err := repoReleaser.PromoteEnvironment(cmd.Context(), version, fromEnvironmentName, toEnvironmentName); err != nil {
+		return stacktrace.Propagate(err, "An error occurred promoting version '%s' from environment '%s' to '%s'", version, fromEnvironmentName, toEnvironmentName)
+	}
+	return nil
+}
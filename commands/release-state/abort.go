@@ -0,0 +1,50 @@
+package releasestate
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	abortCmdStr = "abort"
+)
+
+var AbortCmd = &cobra.Command{
+	Use:   abortCmdStr,
+	Short: "Gives up on the in-progress release",
+	Long:  "Forgets the in-progress release so that the next 'kudet release' starts afresh rather than resuming it, e.g. when the clone holding its release commit is gone. Nothing that was already pushed is undone, so check the remote's release branch and tags afterwards. It's refused while another release holds the release lock.",
+	Args:  cobra.NoArgs,
+	RunE:  runAbort,
+}
+
+func runAbort(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	abortedVersion, err := releaser.AbortInProgressRelease(cmd.Context(), repository, kudetConfig)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred aborting the in-progress release")
+	}
+
+	out := cmd.OutOrStdout()
+	if abortedVersion == "" {
+		fmt.Fprintln(out, "No release is in progress")
+		return nil
+	}
+	fmt.Fprintf(out, "Aborted the in-progress release of version '%s'; check whether any of its commit or tags were already pushed\n", abortedVersion)
+	return nil
+}
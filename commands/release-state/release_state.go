@@ -0,0 +1,24 @@
+package releasestate
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	releaseStateCmdStr = "release-state"
+
+	// The release state is read and written without touching the remote
+	noToken = ""
+)
+
+var ReleaseStateCmd = &cobra.Command{
+	Use:   releaseStateCmdStr,
+	Short: "Manages the record of an in-progress release",
+	Long:  "Inspects and clears the record that lets an interrupted release be resumed, and the release lock, wherever the repo's release state config keeps them; with a bucket backend this works from any machine, not just the one that ran the release",
+}
+
+func init() {
+	ReleaseStateCmd.AddCommand(ShowCmd)
+	ReleaseStateCmd.AddCommand(AbortCmd)
+	ReleaseStateCmd.AddCommand(UnlockCmd)
+}
@@ -0,0 +1,61 @@
+package releasestate
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+const (
+	showCmdStr = "show"
+)
+
+var ShowCmd = &cobra.Command{
+	Use:   showCmdStr,
+	Short: "Shows the in-progress release and who holds the release lock",
+	Args:  cobra.NoArgs,
+	RunE:  runShow,
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	status, err := releaser.GetReleaseStateStatus(cmd.Context(), repository, kudetConfig)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the release state")
+	}
+
+	out := cmd.OutOrStdout()
+	switch {
+	case status.InProgressVersion == "":
+		fmt.Fprintf(out, "No release is in progress according to '%s'\n", status.StateLocation)
+	case status.IsEmbargoed:
+		fmt.Fprintf(out, "Version '%s' is prepared under embargo on release commit '%s', according to '%s'\n", status.InProgressVersion, status.ReleaseCommitHash, status.StateLocation)
+	default:
+		fmt.Fprintf(out, "Version '%s' was committed as '%s' but may not be fully pushed, according to '%s'; 'kudet release' resumes it\n", status.InProgressVersion, status.ReleaseCommitHash, status.StateLocation)
+	}
+	if status.LockLocation == "" {
+		return nil
+	}
+	if status.LockHolder == "" {
+		fmt.Fprintf(out, "The release lock at '%s' is free\n", status.LockLocation)
+		return nil
+	}
+	fmt.Fprintf(out, "The release lock at '%s' has been held by %s since %s\n", status.LockLocation, status.LockHolder, status.LockAcquiredAt.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,50 @@
+package releasestate
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	unlockCmdStr = "unlock"
+)
+
+var UnlockCmd = &cobra.Command{
+	Use:   unlockCmdStr,
+	Short: "Frees the release lock left by a release that died",
+	Long:  "Removes the release lock that a release run takes while the repo's release state is kept in a bucket, for when that run died without giving it up, e.g. with its CI runner. Make sure it really isn't running anymore: the next 'kudet release' then resumes whatever it recorded.",
+	Args:  cobra.NoArgs,
+	RunE:  runUnlock,
+}
+
+func runUnlock(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	wasLocked, err := releaser.UnlockRelease(cmd.Context(), repository, kudetConfig)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred unlocking releases")
+	}
+
+	out := cmd.OutOrStdout()
+	if !wasLocked {
+		fmt.Fprintln(out, "The release lock wasn't held")
+		return nil
+	}
+	fmt.Fprintln(out, "Removed the release lock")
+	return nil
+}
@@ -0,0 +1,62 @@
+package rollbackenv
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+const (
+	rollbackEnvCmdStr = "rollback-env <token>"
+
+	versionFlagStr        = "version"
+	envFlagStr            = "env"
+	reasonFlagStr         = "reason"
+	confirmTimeoutFlagStr = "confirm-timeout"
+
+	defaultReason = "The release was rolled back by hand."
+)
+
+var version string
+var environmentNames []string
+var reason string
+var confirmTimeout time.Duration
+
+var RollbackEnvCmd = &cobra.Command{
+	Use:   rollbackEnvCmdStr,
+	Short: "Rolls environments back off a bad release",
+	Long:  "Pins the environments in the kudet config that are on a version back to the release before it, once approved, and pushes the change to the GitOps repo. The version's GitHub or GitLab release is then marked as superseded and the notification webhooks are told of the rollback. The token authenticates fetches and pushes to the GitOps repo and calls to the forge.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  run,
+}
+
+func init() {
+	RollbackEnvCmd.Flags().StringVar(&version, versionFlagStr, "", "The version to roll back, which must be the one pinned in each environment's manifest")
+	RollbackEnvCmd.Flags().StringSliceVar(&environmentNames, envFlagStr, nil, "The environments to roll back (e.g. '--env staging,prod')")
+	RollbackEnvCmd.Flags().StringVar(&reason, reasonFlagStr, defaultReason, "Why the version is being rolled back, for the GitOps commit, the superseded release, and the notifications")
+	RollbackEnvCmd.Flags().DurationVar(&confirmTimeout, confirmTimeoutFlagStr, releaser.NoConfirmTimeout, "If set (e.g. '30s'), the rollback is aborted when it isn't approved within this long, for unattended contexts")
+	for _, requiredFlagStr := range []string{versionFlagStr, envFlagStr} {
+		if err := RollbackEnvCmd.MarkFlagRequired(requiredFlagStr); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	token := args[0]
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	repoReleaser := releaser.NewReleaser(
+		currentWorkingDirpath,
+		token,
+		releaser.WithConfirmer(releaser.NewPromptConfirmer(cmd.InOrStdin(), confirmTimeout)),
+	)
+	if err := repoReleaser.RollbackEnvironments(cmd.Context(), version, environmentNames, reason); err != nil {
+		return stacktrace.Propagate(err, "An error occurred rolling back version '%s'", version)
+	}
+	return nil
+}
@@ -1,18 +1,61 @@
 package commands
 
 import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands/audit"
+	"github.com/kurtosis-tech/kudet/commands/backfill-changelog"
+	"github.com/kurtosis-tech/kudet/commands/cancel"
+	"github.com/kurtosis-tech/kudet/commands/completion"
+	"github.com/kurtosis-tech/kudet/commands/config"
+	"github.com/kurtosis-tech/kudet/commands/docs"
 	"github.com/kurtosis-tech/kudet/commands/get-docker-tag"
+	"github.com/kurtosis-tech/kudet/commands/graph"
+	"github.com/kurtosis-tech/kudet/commands/init"
+	"github.com/kurtosis-tech/kudet/commands/lift-embargo"
+	"github.com/kurtosis-tech/kudet/commands/lint-repo"
+	"github.com/kurtosis-tech/kudet/commands/list-releases"
+	"github.com/kurtosis-tech/kudet/commands/notes-for"
+	"github.com/kurtosis-tech/kudet/commands/pin-scripts"
+	"github.com/kurtosis-tech/kudet/commands/promote-env"
 	"github.com/kurtosis-tech/kudet/commands/release"
+	"github.com/kurtosis-tech/kudet/commands/release-state"
+	"github.com/kurtosis-tech/kudet/commands/rollback-env"
+	"github.com/kurtosis-tech/kudet/commands/runbook"
+	"github.com/kurtosis-tech/kudet/commands/self-update"
+	"github.com/kurtosis-tech/kudet/commands/should-release"
+	"github.com/kurtosis-tech/kudet/commands/simulate"
+	"github.com/kurtosis-tech/kudet/commands/tag"
+	"github.com/kurtosis-tech/kudet/commands/tags"
 	"github.com/kurtosis-tech/kudet/commands/update-version-in-file"
+	"github.com/kurtosis-tech/kudet/commands/verify-release"
+	"github.com/kurtosis-tech/kudet/commands/why-not"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/analytics"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
 	"github.com/kurtosis-tech/stacktrace"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	cliCmdStr          = "kudet <action>"
 	cliLogLevelStrFlag = "cli-log-level"
+	verboseFlagStr     = "verbose"
+	traceFlagStr       = "trace"
+	langFlagStr        = "lang"
+
+	httpsProxyFlagStr            = "https-proxy"
+	caBundleFlagStr              = "ca-bundle"
+	insecureSkipTlsVerifyFlagStr = "insecure-skip-tls-verify"
+
+	// CI systems set these more easily than flags
+	caBundleEnvVar              = "KUDET_CA_BUNDLE"
+	insecureSkipTlsVerifyEnvVar = "KUDET_INSECURE_SKIP_TLS_VERIFY"
 )
 
 var RootCmd = &cobra.Command{
@@ -27,6 +70,12 @@ var RootCmd = &cobra.Command{
 
 var logLevelStr string
 var defaultLogLevelStr = logrus.InfoLevel.String()
+var isVerbose bool
+var isTrace bool
+var langStr string
+var httpsProxyStr string
+var caBundleFilepath string
+var isInsecureSkipTlsVerify bool
 
 func init() {
 	RootCmd.PersistentFlags().StringVar(
@@ -35,10 +84,66 @@ func init() {
 		defaultLogLevelStr,
 		"Sets the level that the CLI will log at ("+strings.Join(GetAcceptableLogLevelStrs(), "|")+")",
 	)
+	RootCmd.PersistentFlags().BoolVar(&isVerbose, verboseFlagStr, false, "If set, logs at debug level, including the git operations run (refspecs pushed, revisions resolved, refs fetched) and the pre-release script commands")
+	RootCmd.PersistentFlags().StringVar(&langStr, langFlagStr, "", "The language of prompts and hints ("+strings.Join(getSupportedLanguageStrs(), "|")+"); defaults to the one of the locale (LC_ALL, LC_MESSAGES or LANG), or English")
+	RootCmd.PersistentFlags().BoolVar(&isTrace, traceFlagStr, false, "If set, logs at trace level: everything '--"+verboseFlagStr+"' does, plus the remote's progress messages, every remote ref listed, and the output of pre-release scripts")
+	RootCmd.PersistentFlags().StringVar(&httpsProxyStr, httpsProxyFlagStr, "", "The URL of the proxy that git fetches and pushes over HTTPS, and forge API calls, go through; defaults to the one of HTTPS_PROXY (respecting NO_PROXY)")
+	RootCmd.PersistentFlags().StringVar(&caBundleFilepath, caBundleFlagStr, "", "The path of a PEM file of CA certificates to trust on top of the system's, e.g. a TLS-inspecting proxy's; defaults to "+caBundleEnvVar)
+	RootCmd.PersistentFlags().BoolVar(&isInsecureSkipTlsVerify, insecureSkipTlsVerifyFlagStr, false, "If set, TLS certificates aren't verified at all, which exposes the token to anyone in between; only for diagnosing CA problems (also set by "+insecureSkipTlsVerifyEnvVar+"=true)")
 
 	RootCmd.AddCommand(release.ReleaseCmd)
 	RootCmd.AddCommand(getdockertag.GetDockerTagCmd)
 	RootCmd.AddCommand(updateversioninfile.UpdateVersionInFileCmd)
+	RootCmd.AddCommand(runbook.RunbookCmd)
+	RootCmd.AddCommand(config.ConfigCmd)
+	RootCmd.AddCommand(selfupdate.SelfUpdateCmd)
+	RootCmd.AddCommand(liftembargo.LiftEmbargoCmd)
+	RootCmd.AddCommand(tags.TagsCmd)
+	RootCmd.AddCommand(promoteenv.PromoteEnvCmd)
+	RootCmd.AddCommand(rollbackenv.RollbackEnvCmd)
+	RootCmd.AddCommand(initcmd.InitCmd)
+	RootCmd.AddCommand(pinscripts.PinScriptsCmd)
+	RootCmd.AddCommand(verifyrelease.VerifyReleaseCmd)
+	RootCmd.AddCommand(graph.GraphCmd)
+	RootCmd.AddCommand(shouldrelease.ShouldReleaseCmd)
+	RootCmd.AddCommand(simulate.SimulateCmd)
+	RootCmd.AddCommand(releasestate.ReleaseStateCmd)
+	RootCmd.AddCommand(whynot.WhyNotCmd)
+	RootCmd.AddCommand(listreleases.ListReleasesCmd)
+	RootCmd.AddCommand(notesfor.NotesForCmd)
+	RootCmd.AddCommand(audit.AuditCmd)
+	RootCmd.AddCommand(lintrepo.LintRepoCmd)
+	RootCmd.AddCommand(cancel.CancelCmd)
+	RootCmd.AddCommand(completion.CompletionCmd)
+	RootCmd.AddCommand(docs.DocsCmd)
+	RootCmd.AddCommand(backfillchangelog.BackfillChangelogCmd)
+	RootCmd.AddCommand(tag.TagCmd)
+}
+
+// ReportUsage sends an anonymized usage event for the command that was run, if the repo has opted in to analytics;
+// analytics must never affect the command itself, so any problem is only logged at debug level
+func ReportUsage(executedCmd *cobra.Command, commandErr error, duration time.Duration) {
+	if executedCmd == nil {
+		return
+	}
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		logrus.Debugf("Not reporting usage; an error occurred getting the current working directory: %v", err)
+		return
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		logrus.Debugf("Not reporting usage; an error occurred loading the kudet config: %v", err)
+		return
+	}
+	if !kudetConfig.Analytics.Enabled {
+		return
+	}
+	event := analytics.NewUsageEvent(executedCmd.CommandPath(), commandErr, duration)
+	// The command's own context may have been cancelled by an interrupt, which is exactly the kind of run we want to hear about
+	if err := analytics.Report(context.Background(), kudetConfig.Analytics.Endpoint, event); err != nil {
+		logrus.Debugf("An error occurred reporting usage to '%s': %v", kudetConfig.Analytics.Endpoint, err)
+	}
 }
 
 // ====================================================================================================
@@ -48,6 +153,41 @@ func globalSetup(cmd *cobra.Command, args []string) error {
 	if err := setupCLILogs(cmd); err != nil {
 		return stacktrace.Propagate(err, "An error occurred setting up CLI logs")
 	}
+	language, err := i18n.DetectLanguage(langStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the language to show messages in")
+	}
+	i18n.SetLanguage(language)
+	if err := setupHttpsTransport(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred setting up the HTTPS transport")
+	}
+	return nil
+}
+
+// setupHttpsTransport only replaces Go's default transport, which already respects HTTPS_PROXY, if something about it
+// was asked for
+func setupHttpsTransport() error {
+	if caBundleFilepath == "" {
+		caBundleFilepath = os.Getenv(caBundleEnvVar)
+	}
+	if insecureSkipTlsVerifyStr := os.Getenv(insecureSkipTlsVerifyEnvVar); !isInsecureSkipTlsVerify && insecureSkipTlsVerifyStr != "" {
+		isInsecure, err := strconv.ParseBool(insecureSkipTlsVerifyStr)
+		if err != nil {
+			return stacktrace.Propagate(err, "Could not parse %s value '%s' as a boolean", insecureSkipTlsVerifyEnvVar, insecureSkipTlsVerifyStr)
+		}
+		isInsecureSkipTlsVerify = isInsecure
+	}
+	if httpsProxyStr == "" && caBundleFilepath == "" && !isInsecureSkipTlsVerify {
+		return nil
+	}
+	transportConfig := vcs.HttpsTransportConfig{
+		ProxyUrl:             httpsProxyStr,
+		CaBundleFilepath:     caBundleFilepath,
+		IsInsecureSkipVerify: isInsecureSkipTlsVerify,
+	}
+	if err := vcs.ConfigureHttpsTransport(transportConfig); err != nil {
+		return stacktrace.Propagate(err, "An error occurred configuring the HTTPS transport")
+	}
 	return nil
 }
 
@@ -56,11 +196,26 @@ func setupCLILogs(cmd *cobra.Command) error {
 	if err != nil {
 		return stacktrace.Propagate(err, "Could not parse log level string '%v'", logLevelStr)
 	}
+	// The flags only ever make the logs more detailed than the log level asked for
+	if isVerbose && logLevel < logrus.DebugLevel {
+		logLevel = logrus.DebugLevel
+	}
+	if isTrace {
+		logLevel = logrus.TraceLevel
+	}
 	logrus.SetOutput(cmd.OutOrStdout())
 	logrus.SetLevel(logLevel)
 	return nil
 }
 
+func getSupportedLanguageStrs() []string {
+	result := []string{}
+	for _, language := range i18n.SupportedLanguages {
+		result = append(result, string(language))
+	}
+	return result
+}
+
 func GetAcceptableLogLevelStrs() []string {
 	result := []string{}
 	for _, level := range logrus.AllLevels {
@@ -0,0 +1,67 @@
+package runbook
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+const (
+	runbookCmdStr = "runbook"
+
+	checkFlagStr        = "check"
+	checkFlagDefaultVal = false
+	checkFlagShortStr   = ""
+	runbookFileMode     = 0644
+)
+
+var shouldOnlyCheck bool
+var RunbookCmd = &cobra.Command{
+	Use:   runbookCmdStr,
+	Short: "Renders the repo's release runbook",
+	Long:  fmt.Sprintf("Renders a human-readable description of exactly what 'kudet release' does in this repo, derived from the repo's kudet configuration, into '%s' at the repo root.", releaser.RunbookFilename),
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	RunbookCmd.Flags().BoolVarP(&shouldOnlyCheck, checkFlagStr, checkFlagShortStr, checkFlagDefaultVal, fmt.Sprintf("If set, rather than writing '%s' the command fails if it's missing or out of date, for use in CI", releaser.RunbookFilename))
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config for the repo")
+	}
+	runbook, err := releaser.RenderRunbook(currentWorkingDirpath, kudetConfig)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred rendering the release runbook")
+	}
+
+	runbookFilepath := filepath.Join(currentWorkingDirpath, releaser.RunbookFilename)
+	if shouldOnlyCheck {
+		existingRunbook, err := os.ReadFile(runbookFilepath)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred reading the existing runbook at '%s'; run 'kudet %s' to generate it", runbookFilepath, runbookCmdStr)
+		}
+		if string(existingRunbook) != runbook {
+			return stacktrace.NewError("The runbook at '%s' is out of date with the repo's release configuration; run 'kudet %s' to regenerate it", runbookFilepath, runbookCmdStr)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Runbook at '%s' is up to date\n", runbookFilepath)
+		return nil
+	}
+
+	if err := os.WriteFile(runbookFilepath, []byte(runbook), runbookFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the release runbook to '%s'", runbookFilepath)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote runbook to '%s'\n", runbookFilepath)
+	return nil
+}
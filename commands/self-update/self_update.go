@@ -0,0 +1,312 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	selfUpdateCmdStr = "self-update"
+
+	signingKeyFilepathFlagStr = "signing-key"
+	versionFlagStr            = "version"
+	checkFlagStr              = "check"
+	latestVersion             = "latest"
+
+	// The repo that Goreleaser publishes kudet's release artifacts to
+	releasesApiUrlBase      = "https://api.github.com/repos/kurtosis-tech/kudet-release-artifacts/releases"
+	latestReleaseApiUrlPath = "latest"
+	taggedReleaseApiUrlPath = "tags"
+	githubApiAcceptHeader   = "application/vnd.github+json"
+
+	checksumsAssetName          = "checksums.txt"
+	checksumsSignatureAssetName = "checksums.txt.sig"
+	archiveAssetNameFormat      = "kudet_%s_%s_%s.tar.gz"
+	binaryFilenameInArchive     = "kudet"
+
+	downloadTimeout           = 5 * time.Minute
+	updatedBinaryMode         = 0755
+	tempBinaryFilenamePattern = ".kudet-self-update-*"
+)
+
+var signingKeyFilepath string
+var requestedVersion string
+var isCheckOnly bool
+var SelfUpdateCmd = &cobra.Command{
+	Use:   selfUpdateCmdStr,
+	Short: "Updates this kudet binary to the latest release",
+	Long:  "Downloads the kudet release binary for this platform, verifies its checksum against a checksums file signed by the given release signing key, and replaces the running binary with it. With '--check', it only reports whether the running binary is behind the release, failing if it is, so that CI can catch binaries that drifted.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	SelfUpdateCmd.Flags().StringVar(&signingKeyFilepath, signingKeyFilepathFlagStr, "", "Path to the armored PGP public key that kudet releases are signed with")
+	SelfUpdateCmd.Flags().StringVar(&requestedVersion, versionFlagStr, latestVersion, "The version to install; unlike the latest release, it's installed even if it's older than the running binary")
+	SelfUpdateCmd.Flags().BoolVar(&isCheckOnly, checkFlagStr, false, "If set, nothing is downloaded; the command fails if the running binary is behind the release")
+}
+
+// releaseInfo is the subset of the GitHub release API response that we need
+type releaseInfo struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name        string `json:"name"`
+	DownloadUrl string `json:"browser_download_url"`
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	ctx, cancelFunc := context.WithTimeout(cmd.Context(), downloadTimeout)
+	defer cancelFunc()
+
+	if isCheckOnly {
+		return checkForUpdate(ctx, cmd.OutOrStdout(), requestedVersion)
+	}
+	if signingKeyFilepath == "" {
+		return stacktrace.NewError("The '--%s' flag is required to verify the downloaded release", signingKeyFilepathFlagStr)
+	}
+	signingKeyFile, err := os.Open(signingKeyFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the release signing key at '%s'", signingKeyFilepath)
+	}
+	defer signingKeyFile.Close()
+	signingKeyRing, err := openpgp.ReadArmoredKeyRing(signingKeyFile)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the release signing key at '%s'", signingKeyFilepath)
+	}
+
+	release, err := getReleaseInfo(ctx, requestedVersion)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting information about kudet release '%s'", requestedVersion)
+	}
+	releaseVersion := strings.TrimPrefix(release.TagName, "v")
+	if releaseVersion == kudet_version.KudetVersion {
+		fmt.Fprintf(cmd.OutOrStdout(), "kudet is already at version '%s'\n", releaseVersion)
+		return nil
+	}
+	// A binary built ahead of the latest release, e.g. a pre-release, is only downgraded when a version is asked for
+	if !cmd.Flags().Changed(versionFlagStr) && !isBehindRelease(kudet_version.KudetVersion, releaseVersion) {
+		fmt.Fprintf(cmd.OutOrStdout(), "kudet is at version '%s', which is ahead of the latest release '%s'; pass '--%s %s' to downgrade to it\n", kudet_version.KudetVersion, releaseVersion, versionFlagStr, releaseVersion)
+		return nil
+	}
+
+	logrus.Infof("Verifying the checksums of kudet release '%s'...", releaseVersion)
+	checksumsBytes, err := downloadAsset(ctx, release, checksumsAssetName)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred downloading the release checksums")
+	}
+	checksumsSignatureBytes, err := downloadAsset(ctx, release, checksumsSignatureAssetName)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred downloading the release checksums signature")
+	}
+	if _, err := openpgp.CheckDetachedSignature(signingKeyRing, bytes.NewReader(checksumsBytes), bytes.NewReader(checksumsSignatureBytes), nil); err != nil {
+		return stacktrace.Propagate(err, "The checksums of kudet release '%s' aren't signed by the key at '%s'; refusing to update", releaseVersion, signingKeyFilepath)
+	}
+
+	archiveAssetName := fmt.Sprintf(archiveAssetNameFormat, releaseVersion, runtime.GOOS, runtime.GOARCH)
+	logrus.Infof("Downloading '%s'...", archiveAssetName)
+	archiveBytes, err := downloadAsset(ctx, release, archiveAssetName)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred downloading the kudet release archive for this platform")
+	}
+	if err := verifyChecksum(checksumsBytes, archiveAssetName, archiveBytes); err != nil {
+		return stacktrace.Propagate(err, "The downloaded archive failed checksum verification; refusing to update")
+	}
+	binaryBytes, err := extractBinary(archiveBytes, binaryFilenameInArchive)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred extracting the kudet binary from '%s'", archiveAssetName)
+	}
+
+	currentBinaryFilepath, err := os.Executable()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the path of the running kudet binary")
+	}
+	currentBinaryFilepath, err = filepath.EvalSymlinks(currentBinaryFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred resolving the path of the running kudet binary")
+	}
+	if err := replaceBinary(currentBinaryFilepath, binaryBytes); err != nil {
+		return stacktrace.Propagate(err, "An error occurred replacing the kudet binary at '%s'", currentBinaryFilepath)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Updated kudet at '%s' from version '%s' to '%s'\n", currentBinaryFilepath, kudet_version.KudetVersion, releaseVersion)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// checkForUpdate reports whether the running binary is behind the release, without downloading anything
+func checkForUpdate(ctx context.Context, output io.Writer, version string) error {
+	release, err := getReleaseInfo(ctx, version)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting information about kudet release '%s'", version)
+	}
+	releaseVersion := strings.TrimPrefix(release.TagName, "v")
+	if !isBehindRelease(kudet_version.KudetVersion, releaseVersion) {
+		fmt.Fprintf(output, "kudet is up to date at version '%s'\n", kudet_version.KudetVersion)
+		return nil
+	}
+	return stacktrace.NewError("kudet is at version '%s' but release '%s' is out; run 'kudet %s --%s <path>' to update", kudet_version.KudetVersion, releaseVersion, selfUpdateCmdStr, signingKeyFilepathFlagStr)
+}
+
+// isBehindRelease compares the versions as semver where it can, so that a binary built ahead of the release, e.g. from
+// the release branch, isn't reported as outdated; versions that don't parse are only compared for equality
+func isBehindRelease(currentVersion string, releaseVersion string) bool {
+	current, currentErr := semver.NewVersion(currentVersion)
+	release, releaseErr := semver.NewVersion(releaseVersion)
+	if currentErr != nil || releaseErr != nil {
+		return currentVersion != releaseVersion
+	}
+	return current.LessThan(release)
+}
+
+func getReleaseInfo(ctx context.Context, version string) (*releaseInfo, error) {
+	releaseApiUrl := fmt.Sprintf("%s/%s", releasesApiUrlBase, latestReleaseApiUrlPath)
+	if version != latestVersion {
+		releaseApiUrl = fmt.Sprintf("%s/%s/%s", releasesApiUrlBase, taggedReleaseApiUrlPath, version)
+	}
+	responseBytes, err := httpGet(ctx, releaseApiUrl, githubApiAcceptHeader)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred querying the GitHub release API")
+	}
+	release := &releaseInfo{}
+	if err := json.Unmarshal(responseBytes, release); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the GitHub release API response")
+	}
+	return release, nil
+}
+
+func downloadAsset(ctx context.Context, release *releaseInfo, assetName string) ([]byte, error) {
+	for _, asset := range release.Assets {
+		if asset.Name == assetName {
+			return httpGet(ctx, asset.DownloadUrl, "")
+		}
+	}
+	return nil, stacktrace.NewError("Release '%s' has no asset named '%s'; is this platform supported?", release.TagName, assetName)
+}
+
+func httpGet(ctx context.Context, url string, acceptHeader string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred building the request to '%s'", url)
+	}
+	if acceptHeader != "" {
+		request.Header.Set("Accept", acceptHeader)
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred requesting '%s'", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, stacktrace.NewError("Request to '%s' returned unexpected status '%s'", url, resp.Status)
+	}
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the response from '%s'", url)
+	}
+	return responseBytes, nil
+}
+
+// verifyChecksum checks the file against its entry in a Goreleaser checksums file, whose lines are '<sha256>  <filename>'
+func verifyChecksum(checksumsBytes []byte, filename string, fileBytes []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksumsBytes))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[1] != filename {
+			continue
+		}
+		actualChecksumBytes := sha256.Sum256(fileBytes)
+		actualChecksum := hex.EncodeToString(actualChecksumBytes[:])
+		if actualChecksum != fields[0] {
+			return stacktrace.NewError("File '%s' has checksum '%s' but the checksums file expects '%s'", filename, actualChecksum, fields[0])
+		}
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred scanning the checksums file")
+	}
+	return stacktrace.NewError("The checksums file has no entry for '%s'", filename)
+}
+
+func extractBinary(archiveBytes []byte, binaryFilename string) ([]byte, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archiveBytes))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred opening the archive as gzip")
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, stacktrace.NewError("The archive doesn't contain a '%s' binary", binaryFilename)
+		}
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading the archive")
+		}
+		if header.Typeflag != tar.TypeReg || path.Base(header.Name) != binaryFilename {
+			continue
+		}
+		binaryBytes, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading '%s' from the archive", header.Name)
+		}
+		return binaryBytes, nil
+	}
+}
+
+// replaceBinary writes the new binary next to the old one and renames it into place, so that the swap is atomic and
+// an interrupted update never leaves a truncated binary behind
+func replaceBinary(binaryFilepath string, binaryBytes []byte) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(binaryFilepath), tempBinaryFilenamePattern)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating a temporary file next to '%s'", binaryFilepath)
+	}
+	tempFilepath := tempFile.Name()
+	shouldRemoveTempFile := true
+	defer func() {
+		if shouldRemoveTempFile {
+			os.Remove(tempFilepath)
+		}
+	}()
+	if _, err := tempFile.Write(binaryBytes); err != nil {
+		tempFile.Close()
+		return stacktrace.Propagate(err, "An error occurred writing the new binary to '%s'", tempFilepath)
+	}
+	if err := tempFile.Close(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred closing '%s'", tempFilepath)
+	}
+	if err := os.Chmod(tempFilepath, updatedBinaryMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred making '%s' executable", tempFilepath)
+	}
+	if err := os.Rename(tempFilepath, binaryFilepath); err != nil {
+		return stacktrace.Propagate(err, "An error occurred moving the new binary into place at '%s'", binaryFilepath)
+	}
+	shouldRemoveTempFile = false
+	return nil
+}
@@ -0,0 +1,50 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	archiveBytes := []byte("pretend this is an archive")
+	checksumBytes := sha256.Sum256(archiveBytes)
+	checksums := fmt.Sprintf("%s  kudet_0.2.0_linux_amd64.tar.gz\n%s  kudet_0.2.0_darwin_arm64.tar.gz\n", hex.EncodeToString(checksumBytes[:]), "00")
+
+	require.NoError(t, verifyChecksum([]byte(checksums), "kudet_0.2.0_linux_amd64.tar.gz", archiveBytes))
+	require.ErrorContains(t, verifyChecksum([]byte(checksums), "kudet_0.2.0_darwin_arm64.tar.gz", archiveBytes), "checksums file expects")
+	require.ErrorContains(t, verifyChecksum([]byte(checksums), "kudet_0.2.0_linux_386.tar.gz", archiveBytes), "no entry")
+}
+
+func TestExtractBinary(t *testing.T) {
+	archiveBuffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(archiveBuffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	binaryBytes := []byte("#!/bin/kudet")
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "kudet", Mode: 0755, Size: int64(len(binaryBytes)), Typeflag: tar.TypeReg}))
+	_, err := tarWriter.Write(binaryBytes)
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	extractedBytes, err := extractBinary(archiveBuffer.Bytes(), "kudet")
+	require.NoError(t, err)
+	require.Equal(t, binaryBytes, extractedBytes)
+
+	_, err = extractBinary(archiveBuffer.Bytes(), "not-kudet")
+	require.ErrorContains(t, err, "doesn't contain")
+}
+
+func TestIsBehindRelease(t *testing.T) {
+	require.True(t, isBehindRelease("0.1.9", "0.2.0"))
+	require.False(t, isBehindRelease("0.2.0", "0.2.0"))
+	require.False(t, isBehindRelease("0.3.0", "0.2.0"))
+	require.True(t, isBehindRelease("dev", "0.2.0"))
+	require.False(t, isBehindRelease("dev", "dev"))
+}
@@ -0,0 +1,55 @@
+package shouldrelease
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	shouldReleaseCmdStr = "should-release"
+
+	// Only the local history and changelog are checked, so there's nothing to authenticate for
+	noToken = ""
+)
+
+var ShouldReleaseCmd = &cobra.Command{
+	Use:   shouldReleaseCmdStr,
+	Short: "Checks whether there's anything to release",
+	Long:  "Succeeds only if the changelog has entries under its TBD header and there are commits since the latest release's tag that change more than the non-releasable files, where only those changing skips the release, printing why not otherwise. Meant for scheduled workflows, e.g. a nightly release train that runs 'kudet release' only when there's something to ship.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	reasons, err := releaser.CheckReleaseEligibility(repository, kudetConfig, currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking whether there's anything to release")
+	}
+
+	out := cmd.OutOrStdout()
+	for _, reason := range reasons {
+		fmt.Fprintln(out, reason)
+	}
+	if len(reasons) > 0 {
+		return stacktrace.NewError("There's nothing to release")
+	}
+	fmt.Fprintln(out, "There are unreleased changes to ship")
+	return nil
+}
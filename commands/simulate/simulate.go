@@ -0,0 +1,87 @@
+package simulate
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	simulateCmdStr = "simulate"
+
+	asOfFlagStr = "as-of"
+
+	// The simulation only reads the local history, so there's nothing to authenticate for
+	noToken = ""
+
+	simulationOutputIndent = "    "
+)
+
+var asOf string
+var SimulateCmd = &cobra.Command{
+	Use:   simulateCmdStr,
+	Short: "Replays the release decisions at a past commit",
+	Long:  "Replays how 'kudet release' would have decided the next version and finalized the changelog at a past commit of the repo, using the current kudet config, to check changes to the config against the repo's real history before adopting them. Nothing in the repo is changed.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	SimulateCmd.Flags().StringVar(&asOf, asOfFlagStr, "", "The commit to simulate the release at: a revision (e.g. a commit hash or tag), or a date ('2006-01-02' for the end of that day, or RFC 3339) for the release branch as of then")
+	if err := SimulateCmd.MarkFlagRequired(asOfFlagStr); err != nil {
+		panic(err)
+	}
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	simulation, err := releaser.SimulateRelease(repository, kudetConfig, asOf)
+	if err != nil {
+		return stacktrace.Propagate(err, "The release simulated as of '%s' would have failed", asOf)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Release simulated as of commit '%s' (%s)\n", simulation.CommitHash, simulation.CommitTime.Format(time.RFC3339))
+	fmt.Fprintf(out, "Previous version: %s\n", simulation.PreviousVersion)
+	fmt.Fprintf(out, "Next version: %s\n", simulation.NextVersion)
+	fmt.Fprintf(out, "%s%s\n", simulationOutputIndent, simulation.BumpReason)
+	fmt.Fprintln(out, "Release commit message:")
+	fmt.Fprintln(out, indent(strings.Split(simulation.ReleaseCommitMessage, "\n")))
+	fmt.Fprintln(out, "Finalized changelog:")
+	fmt.Fprintln(out, indent(simulation.FinalizedChangelogHeadLines))
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func indent(lines []string) string {
+	indentedLines := []string{}
+	for _, line := range lines {
+		if line == "" {
+			indentedLines = append(indentedLines, line)
+			continue
+		}
+		indentedLines = append(indentedLines, simulationOutputIndent+line)
+	}
+	return strings.Join(indentedLines, "\n")
+}
@@ -0,0 +1,49 @@
+package tag
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	tagCmdStr = "tag <version> [<revision>]"
+
+	pushFlagStr  = "push"
+	tokenFlagStr = "token"
+
+	defaultRevision = "HEAD"
+)
+
+var shouldPush bool
+var token string
+var TagCmd = &cobra.Command{
+	Use:   tagCmdStr,
+	Short: "Creates the twin tags of a version by hand",
+	Long:  "Creates the annotated 'X.Y.Z' and 'vX.Y.Z' tags of a version on a revision (HEAD by default) the way 'kudet release' does, for hotfixes released outside the release flow. Nothing is committed and the changelog is left alone. With '--" + pushFlagStr + "', the tags are pushed to origin, 'vX.Y.Z' first, and both are deleted again if either can't be pushed.",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE:  run,
+}
+
+func init() {
+	TagCmd.Flags().BoolVar(&shouldPush, pushFlagStr, false, "If set, the tags are pushed to origin once they're created")
+	TagCmd.Flags().StringVar(&token, tokenFlagStr, "", "The token to push the tags with, unless the kudet config has a GitHub App to push as")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	version := args[0]
+	revision := defaultRevision
+	if len(args) > 1 {
+		revision = args[1]
+	}
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	repoReleaser := releaser.NewReleaser(currentWorkingDirpath, token)
+	if err := repoReleaser.TagRelease(cmd.Context(), version, revision, shouldPush); err != nil {
+		return stacktrace.Propagate(err, "An error occurred tagging version '%s' on '%s'", version, revision)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package tags
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	auditCmdStr = "audit"
+
+	fixFlagStr = "fix"
+
+	// Local tag operations don't need to authenticate
+	noToken = ""
+)
+
+var shouldFix bool
+var AuditCmd = &cobra.Command{
+	Use:   auditCmdStr,
+	Short: "Detects inconsistent version tags",
+	Long:  "Lists tags that look like versions but aren't valid semver, releases whose 'X.Y.Z' and 'vX.Y.Z' tags are missing or point at different commits, and tags the releaser ignores under the repo's tag parsing config. Fails if any problem other than an ignored tag is found.",
+	Args:  cobra.NoArgs,
+	RunE:  runAudit,
+}
+
+func init() {
+	AuditCmd.Flags().BoolVar(&shouldFix, fixFlagStr, false, "If set, missing twin tags are created locally on the same commit as their existing twin")
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, noToken)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	problems, err := releaser.AuditTags(repository, kudetConfig.TagParsing)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred auditing the repo's tags")
+	}
+
+	out := cmd.OutOrStdout()
+	numBlockingProblems := 0
+	for _, problem := range problems {
+		fmt.Fprintf(out, "%s\t%s\t%s\n", problem.TagName, problem.Kind, problem.Description)
+		if problem.IsBlocking() {
+			numBlockingProblems++
+		}
+	}
+
+	if shouldFix {
+		createdTagNames, err := releaser.CreateMissingTwinTags(repository, problems)
+		for _, tagName := range createdTagNames {
+			fmt.Fprintf(out, "Created tag '%s'\n", tagName)
+			numBlockingProblems--
+		}
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred creating the missing twin tags")
+		}
+		if len(createdTagNames) > 0 {
+			fmt.Fprintf(out, "Push the created tags with 'git push %s --tags'\n", vcs.OriginRemoteName)
+		}
+	}
+
+	if numBlockingProblems > 0 {
+		return stacktrace.NewError("Found %d version tag problem(s) that need fixing", numBlockingProblems)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package tags
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	tagsCmdStr = "tags"
+)
+
+var TagsCmd = &cobra.Command{
+	Use:   tagsCmdStr,
+	Short: "Manages the repo's version tags",
+	Long:  "Inspects and repairs the version tags that releases of the repo are based on",
+}
+
+func init() {
+	TagsCmd.AddCommand(AuditCmd)
+}
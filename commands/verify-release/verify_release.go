@@ -0,0 +1,106 @@
+package verifyrelease
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	verifyReleaseCmdStr = "verify-release <token> [<version>]"
+
+	allFlagStr        = "all"
+	allFlagDefaultVal = false
+	allFlagShortStr   = ""
+)
+
+var shouldVerifyAll bool
+var VerifyReleaseCmd = &cobra.Command{
+	Use:     verifyReleaseCmdStr,
+	Aliases: []string{"verify"},
+	Short:   "Checks that a published release is consistent",
+	Long:    "Checks that the 'X.Y.Z' and 'vX.Y.Z' tags of the version exist locally and on the remote and point at the same commit, that the commit is on the release branch of the remote, that the changelog has a header for the version with notes under it, and, if the repo verifies tag signatures, that the tags are signed by a trusted key. Fails if any problem is found, for asserting on a release in CI after it's cut or auditing past releases.",
+	Args:    validateArgs,
+	RunE:    run,
+}
+
+func init() {
+	VerifyReleaseCmd.Flags().BoolVarP(&shouldVerifyAll, allFlagStr, allFlagShortStr, allFlagDefaultVal, "If set, verifies every release the repo has tagged rather than a single version, reporting the problems of each")
+}
+
+func validateArgs(cmd *cobra.Command, args []string) error {
+	if shouldVerifyAll {
+		return cobra.ExactArgs(1)(cmd, args)
+	}
+	return cobra.ExactArgs(2)(cmd, args)
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	token := args[0]
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the kudet config")
+	}
+	repository, err := vcs.OpenRepository(currentWorkingDirpath, token)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	if shouldVerifyAll {
+		return verifyAllReleases(cmd, repository, kudetConfig, currentWorkingDirpath)
+	}
+
+	version := args[1]
+	problems, err := releaser.VerifyRelease(cmd.Context(), repository, kudetConfig, currentWorkingDirpath, version)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred verifying release '%s'", version)
+	}
+
+	out := cmd.OutOrStdout()
+	for _, problem := range problems {
+		fmt.Fprintln(out, problem)
+	}
+	if len(problems) > 0 {
+		return stacktrace.NewError("Found %d problem(s) with release '%s'", len(problems), version)
+	}
+	fmt.Fprintf(out, "Release '%s' is consistent\n", version)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func verifyAllReleases(cmd *cobra.Command, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, repoDirpath string) error {
+	verifications, err := releaser.VerifyAllReleases(cmd.Context(), repository, kudetConfig, repoDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred verifying the repo's releases")
+	}
+
+	out := cmd.OutOrStdout()
+	numInconsistentReleases := 0
+	for _, verification := range verifications {
+		if len(verification.Problems) == 0 {
+			fmt.Fprintf(out, "%s: consistent\n", verification.Version)
+			continue
+		}
+		numInconsistentReleases++
+		fmt.Fprintf(out, "%s: %d problem(s)\n", verification.Version, len(verification.Problems))
+		for _, problem := range verification.Problems {
+			fmt.Fprintf(out, "  %s\n", problem)
+		}
+	}
+	if numInconsistentReleases > 0 {
+		return stacktrace.NewError("Found problems with %d of the %d release(s)", numInconsistentReleases, len(verifications))
+	}
+	fmt.Fprintf(out, "All %d release(s) are consistent\n", len(verifications))
+	return nil
+}
@@ -0,0 +1,73 @@
+package whynot
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	whyNotCmdStr = "why-not <token>"
+
+	bumpMajorFlagStr            = "bump-major"
+	requireGreenCiFlagStr       = "require-green-ci"
+	acknowledgeNotesDiffFlagStr = "acknowledge-notes-diff"
+	forceFlagStr                = "force"
+)
+
+var shouldBumpMajorVersion bool
+var shouldRequireGreenCi bool
+var isNotesDiffAcknowledged bool
+var isForced bool
+var WhyNotCmd = &cobra.Command{
+	Use:   whyNotCmdStr,
+	Short: "Lists everything currently stopping a release",
+	Long:  "Runs the checks and gates of 'kudet release' without fetching, committing or pushing anything, and lists everything that would stop the release (e.g. a dirty worktree, a branch out of sync with the remote, an invalid changelog, failing CI, a release policy's freeze window) in the order the release would run into them, with a hint for each. Takes the same flags as 'kudet release' for the checks they affect. Fails if anything stops the release.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  run,
+}
+
+func init() {
+	WhyNotCmd.Flags().BoolVar(&shouldBumpMajorVersion, bumpMajorFlagStr, false, "If set, the release is checked as bumping the major version, like 'kudet release --bump-major'")
+	WhyNotCmd.Flags().BoolVar(&shouldRequireGreenCi, requireGreenCiFlagStr, false, "If set, CI not having passed on the remote's release branch is listed, like for 'kudet release --require-green-ci'")
+	WhyNotCmd.Flags().BoolVar(&isNotesDiffAcknowledged, acknowledgeNotesDiffFlagStr, false, "If set, release notes that differ from the approved copy aren't listed, like for 'kudet release --acknowledge-notes-diff'")
+	WhyNotCmd.Flags().BoolVar(&isForced, forceFlagStr, false, "If set, a previous release cut within the 'min-release-interval' isn't listed, like for 'kudet release --force'")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	token := args[0]
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	repoReleaser := releaser.NewReleaser(
+		currentWorkingDirpath,
+		token,
+		releaser.WithBumpMajorVersion(shouldBumpMajorVersion),
+		releaser.WithRequireGreenCi(shouldRequireGreenCi),
+		releaser.WithReleaseNotesDiffAcknowledged(isNotesDiffAcknowledged),
+		releaser.WithForce(isForced),
+	)
+	blockers, err := repoReleaser.GetReleaseBlockers(cmd.Context())
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking what stops the repo from being released")
+	}
+
+	out := cmd.OutOrStdout()
+	if len(blockers) == 0 {
+		fmt.Fprintln(out, "Nothing stops the repo from being released")
+		return nil
+	}
+	for idx, blocker := range blockers {
+		if blocker.Kind == nil {
+			fmt.Fprintf(out, "%d. %s\n", idx+1, blocker.Problem)
+			continue
+		}
+		fmt.Fprintf(out, "%d. [%s] %s\n", idx+1, blocker.Kind.Name, blocker.Problem)
+		fmt.Fprintf(out, "   %s\n", i18n.Sprintf(i18n.ReleaseErrorHint, blocker.Kind.GetRemediation()))
+	}
+	return stacktrace.NewError("Found %d thing(s) stopping the repo from being released", len(blockers))
+}
@@ -0,0 +1,107 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+const (
+	eventContentType = "application/json"
+	// Reporting happens after the command has finished, so it mustn't hold the operator up for long
+	reportTimeout = 3 * time.Second
+
+	SucceededOutcome = "succeeded"
+	FailedOutcome    = "failed"
+
+	// Release failures of a known kind are categorized by the release error's name instead, e.g. 'dirty-worktree'
+	InterruptedFailureCategory   = "interrupted"
+	TimedOutFailureCategory      = "timed-out"
+	ScriptFailedFailureCategory  = "script-failed"
+	UncategorizedFailureCategory = "uncategorized"
+)
+
+// UsageEvent is the JSON body POSTed to the analytics endpoint after each command; it deliberately carries nothing that
+// identifies the repo, the operator, or the arguments the command was run with
+type UsageEvent struct {
+	Command         string `json:"command"`
+	Outcome         string `json:"outcome"`
+	FailureCategory string `json:"failureCategory,omitempty"`
+	DurationMillis  int64  `json:"durationMillis"`
+	Os              string `json:"os"`
+	Arch            string `json:"arch"`
+}
+
+// NewUsageEvent describes a finished run of the given command, classifying its error (if any) into a coarse category
+// so that error messages, which may contain paths or tokens, never leave the machine
+func NewUsageEvent(commandPath string, commandErr error, duration time.Duration) *UsageEvent {
+	event := &UsageEvent{
+		Command:         commandPath,
+		Outcome:         SucceededOutcome,
+		FailureCategory: "",
+		DurationMillis:  duration.Milliseconds(),
+		Os:              runtime.GOOS,
+		Arch:            runtime.GOARCH,
+	}
+	if commandErr != nil {
+		event.Outcome = FailedOutcome
+		event.FailureCategory = categorizeFailure(commandErr)
+	}
+	return event
+}
+
+// Report sends the event to the given endpoint
+func Report(ctx context.Context, endpoint string, event *UsageEvent) error {
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the usage event")
+	}
+	ctxWithTimeout, cancelFunc := context.WithTimeout(ctx, reportTimeout)
+	defer cancelFunc()
+	request, err := http.NewRequestWithContext(ctxWithTimeout, http.MethodPost, endpoint, bytes.NewReader(eventBytes))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred building the usage event request to '%s'", endpoint)
+	}
+	request.Header.Set("Content-Type", eventContentType)
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred POSTing the usage event to '%s'", endpoint)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return stacktrace.NewError("The analytics endpoint responded with unexpected status '%s'", resp.Status)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// categorizeFailure puts interruptions and timeouts ahead of the kind of release failure, as a push that's cut off, for
+// one, fails as a rejected push
+func categorizeFailure(commandErr error) string {
+	rootCause := stacktrace.RootCause(commandErr)
+	releaseErr, isReleaseErr := releaser.GetReleaseError(commandErr)
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(rootCause, context.Canceled):
+		return InterruptedFailureCategory
+	case errors.Is(rootCause, context.DeadlineExceeded):
+		return TimedOutFailureCategory
+	case isReleaseErr:
+		return releaseErr.Name
+	case errors.As(rootCause, &exitErr):
+		return ScriptFailedFailureCategory
+	default:
+		return UncategorizedFailureCategory
+	}
+}
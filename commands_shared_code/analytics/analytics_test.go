@@ -0,0 +1,39 @@
+package analytics
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/releaser"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUsageEvent_Succeeded(t *testing.T) {
+	event := NewUsageEvent("kudet release", nil, 1500*time.Millisecond)
+	require.Equal(t, "kudet release", event.Command)
+	require.Equal(t, SucceededOutcome, event.Outcome)
+	require.Empty(t, event.FailureCategory)
+	require.Equal(t, int64(1500), event.DurationMillis)
+}
+
+func TestNewUsageEvent_CategorizesFailures(t *testing.T) {
+	interruptedErr := stacktrace.Propagate(context.Canceled, "The release was cancelled before committing")
+	require.Equal(t, InterruptedFailureCategory, NewUsageEvent("kudet release", interruptedErr, 0).FailureCategory)
+
+	scriptErr := stacktrace.Propagate(&exec.ExitError{}, "Pre release script command 'foo.sh 1.2.3' returned logs")
+	require.Equal(t, ScriptFailedFailureCategory, NewUsageEvent("kudet release", scriptErr, 0).FailureCategory)
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.ReleaseBranch = ""
+	invalidConfigErr := releaser.NewReleaser(t.TempDir(), "token", releaser.WithKudetConfig(kudetConfig)).Release(context.Background())
+	require.Equal(t, releaser.ErrInvalidConfig.Name, NewUsageEvent("kudet release", invalidConfigErr, 0).FailureCategory)
+
+	otherErr := stacktrace.NewError("The branch contains modified files at '/home/someone/repo'")
+	event := NewUsageEvent("kudet release", otherErr, 0)
+	require.Equal(t, FailedOutcome, event.Outcome)
+	require.Equal(t, UncategorizedFailureCategory, event.FailureCategory)
+}
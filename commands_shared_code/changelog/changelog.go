@@ -0,0 +1,331 @@
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	// UnreleasedVersionName is the placeholder that heads the changes that haven't been released yet
+	UnreleasedVersionName = "TBD"
+
+	// SemverPattern is the official regex from semver.org, unanchored so that it can be embedded in other patterns
+	SemverPattern = `(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?`
+
+	// DefaultMaxLineLength is the longest line, in bytes, that a streamed changelog is expected to have; a longer one is
+	// most likely a corrupted or binary file
+	DefaultMaxLineLength = 1024 * 1024
+
+	headerPrefix   = "#"
+	lfLineEnding   = "\n"
+	crlfLineEnding = "\r\n"
+)
+
+var (
+	unreleasedVersionHeaderRegex = regexp.MustCompile(fmt.Sprintf(`^%s\s*%s\s*$`, headerPrefix, UnreleasedVersionName))
+	versionHeaderRegex           = regexp.MustCompile(fmt.Sprintf(`^%s\s*(%s)\s*$`, headerPrefix, SemverPattern))
+	// Level-one headers are the version headers, so the sections under them start at level two
+	sectionHeaderRegex = regexp.MustCompile(`^(#{2,})\s*(.*?)\s*$`)
+)
+
+// Changelog is a changelog as a tree of its versions, their sections and the entries listed under them. It keeps every
+// line of the file, blank ones included, so that rendering an unmodified changelog gives back the file it was parsed
+// from, line endings aside.
+type Changelog struct {
+	// The lines before the first version header, which a valid changelog only has blank ones of
+	Preamble []*Entry
+
+	// The versions in the order they're listed, normally the unreleased TBD version followed by the released ones from
+	// newest to oldest
+	Versions []*Version
+
+	// The line ending that the changelog is rendered with, which is the first line's
+	LineEnding string
+}
+
+// Version is a '# TBD' or '# <semver>' header and everything listed under it
+type Version struct {
+	HeaderLine string
+
+	// The semantic version, or UnreleasedVersionName for the TBD version
+	Name string
+
+	// The lines listed before the first section
+	Entries []*Entry
+
+	Sections []*Section
+}
+
+// Section is a '##', '###' or deeper header under a version, where a section is nested under the closest header above
+// it with fewer '#'s
+type Section struct {
+	HeaderLine string
+
+	// The header's text after its '#'s, e.g. 'Breaking changes'
+	Title string
+
+	// The number of '#'s in the header
+	Level int
+
+	// The lines listed before the first subsection
+	Entries []*Entry
+
+	Subsections []*Section
+}
+
+// Entry is a line that isn't a version or section header, e.g. '* Added retries'; blank lines are kept as blank
+// entries
+type Entry struct {
+	Line string
+}
+
+// Parse parses the changelog into its versions; it doesn't check that the changelog is valid for releasing, only that
+// each released version is listed once
+func Parse(changelogBytes []byte) (*Changelog, error) {
+	return parseLines(strings.Split(string(changelogBytes), lfLineEnding))
+}
+
+// ParseHead streams the changelog from the reader up to its first released version, leaving the released versions
+// after it unread, since in a repo with years of history they're most of the changelog and finalizing a release
+// never changes them. Along with the head, it returns the offset in bytes of the first released version's header, which
+// the rest of the changelog starts at after the head's rendering and a line ending, or -1 if there's no released version
+// and the head is the whole changelog. A line longer than the max line length, in bytes, is refused rather than read.
+func ParseHead(reader io.Reader, maxLineLength int) (*Changelog, int64, error) {
+	scanner := bufio.NewScanner(reader)
+	maxTokenSize := maxLineLength + len(crlfLineEnding)
+	initialBufferSize := bufio.MaxScanTokenSize
+	if maxTokenSize < initialBufferSize {
+		initialBufferSize = maxTokenSize
+	}
+	scanner.Buffer(make([]byte, 0, initialBufferSize), maxTokenSize)
+	// Unlike bufio.ScanLines, the offset that each line starts at is tracked and the line's '\r' is kept until the line
+	// ending is known from the first line, like Parse does
+	numBytesScanned := int64(0)
+	isLastLineTerminated := true
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if newlineIdx := bytes.IndexByte(data, '\n'); newlineIdx >= 0 {
+			numBytesScanned += int64(newlineIdx + 1)
+			isLastLineTerminated = true
+			return newlineIdx + 1, data[:newlineIdx], nil
+		}
+		if atEOF && len(data) > 0 {
+			numBytesScanned += int64(len(data))
+			isLastLineTerminated = false
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+
+	lines := []string{}
+	releasedVersionOffset := int64(-1)
+	for {
+		lineStartOffset := numBytesScanned
+		if !scanner.Scan() {
+			break
+		}
+		line := scanner.Text()
+		if versionName, isVersionHeader := getVersionName(strings.TrimSuffix(line, "\r")); isVersionHeader && versionName != UnreleasedVersionName {
+			releasedVersionOffset = lineStartOffset
+			break
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			return nil, 0, stacktrace.NewError("The changelog has a line longer than the max line length of %d bytes", maxLineLength)
+		}
+		return nil, 0, stacktrace.Propagate(err, "An error occurred reading the changelog")
+	}
+	// Like splitting the whole changelog, a final line ending is followed by an empty line
+	if releasedVersionOffset < 0 && isLastLineTerminated {
+		lines = append(lines, "")
+	}
+	head, err := parseLines(lines)
+	if err != nil {
+		return nil, 0, stacktrace.Propagate(err, "An error occurred parsing the head of the changelog")
+	}
+	return head, releasedVersionOffset, nil
+}
+
+// NewVersion builds the version headed by the header line from the lines listed under it
+func NewVersion(headerLine string, lines []string) *Version {
+	versionName, _ := getVersionName(headerLine)
+	version := &Version{
+		HeaderLine: headerLine,
+		Name:       versionName,
+		Entries:    []*Entry{},
+		Sections:   []*Section{},
+	}
+	// The sections that the next line is nested under, outermost first
+	openSections := []*Section{}
+	for _, line := range lines {
+		if matches := sectionHeaderRegex.FindStringSubmatch(line); matches != nil {
+			section := &Section{
+				HeaderLine:  line,
+				Title:       matches[2],
+				Level:       len(matches[1]),
+				Entries:     []*Entry{},
+				Subsections: []*Section{},
+			}
+			for len(openSections) > 0 && openSections[len(openSections)-1].Level >= section.Level {
+				openSections = openSections[:len(openSections)-1]
+			}
+			if len(openSections) == 0 {
+				version.Sections = append(version.Sections, section)
+			} else {
+				parentSection := openSections[len(openSections)-1]
+				parentSection.Subsections = append(parentSection.Subsections, section)
+			}
+			openSections = append(openSections, section)
+			continue
+		}
+		entry := &Entry{Line: line}
+		if len(openSections) == 0 {
+			version.Entries = append(version.Entries, entry)
+		} else {
+			innermostSection := openSections[len(openSections)-1]
+			innermostSection.Entries = append(innermostSection.Entries, entry)
+		}
+	}
+	return version
+}
+
+// GetVersion gets the version with the name, e.g. '1.2.3' or UnreleasedVersionName
+func (changelog *Changelog) GetVersion(name string) (*Version, bool) {
+	for _, version := range changelog.Versions {
+		if version.Name == name {
+			return version, true
+		}
+	}
+	return nil, false
+}
+
+// Render writes the changelog back out
+func (changelog *Changelog) Render() []byte {
+	lines := []string{}
+	for _, entry := range changelog.Preamble {
+		lines = append(lines, entry.Line)
+	}
+	for _, version := range changelog.Versions {
+		lines = append(lines, version.getLines()...)
+	}
+	return []byte(strings.Join(lines, changelog.LineEnding))
+}
+
+// IsUnreleased is whether the version is the TBD version
+func (version *Version) IsUnreleased() bool {
+	return version.Name == UnreleasedVersionName
+}
+
+// IsEmpty is whether nothing but blank lines are listed under the version
+func (version *Version) IsEmpty() bool {
+	return len(version.Sections) == 0 && !hasNonBlankEntry(version.Entries)
+}
+
+// HasEntries is whether anything is listed under the section, including under its subsections
+func (section *Section) HasEntries() bool {
+	if hasNonBlankEntry(section.Entries) {
+		return true
+	}
+	for _, subsection := range section.Subsections {
+		if subsection.HasEntries() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBlank is whether the entry is a blank line
+func (entry *Entry) IsBlank() bool {
+	return strings.TrimSpace(entry.Line) == ""
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func parseLines(lines []string) (*Changelog, error) {
+	lineEnding := lfLineEnding
+	if len(lines) > 0 && strings.HasSuffix(lines[0], "\r") {
+		lineEnding = crlfLineEnding
+		for idx, line := range lines {
+			lines[idx] = strings.TrimSuffix(line, "\r")
+		}
+	}
+
+	changelog := &Changelog{
+		Preamble:   []*Entry{},
+		Versions:   []*Version{},
+		LineEnding: lineEnding,
+	}
+	seenVersionNames := map[string]bool{}
+	versionStartIdx := -1
+	for idx, line := range lines {
+		versionName, isVersionHeader := getVersionName(line)
+		if !isVersionHeader {
+			if versionStartIdx < 0 {
+				changelog.Preamble = append(changelog.Preamble, &Entry{Line: line})
+			}
+			continue
+		}
+		if versionName != UnreleasedVersionName && seenVersionNames[versionName] {
+			return nil, stacktrace.NewError("Version '%s' is listed more than once in the changelog", versionName)
+		}
+		seenVersionNames[versionName] = true
+		if versionStartIdx >= 0 {
+			changelog.Versions = append(changelog.Versions, NewVersion(lines[versionStartIdx], lines[versionStartIdx+1:idx]))
+		}
+		versionStartIdx = idx
+	}
+	if versionStartIdx >= 0 {
+		changelog.Versions = append(changelog.Versions, NewVersion(lines[versionStartIdx], lines[versionStartIdx+1:]))
+	}
+	return changelog, nil
+}
+
+func getVersionName(line string) (string, bool) {
+	if unreleasedVersionHeaderRegex.MatchString(line) {
+		return UnreleasedVersionName, true
+	}
+	if matches := versionHeaderRegex.FindStringSubmatch(line); matches != nil {
+		return matches[1], true
+	}
+	return "", false
+}
+
+func (version *Version) getLines() []string {
+	lines := []string{version.HeaderLine}
+	for _, entry := range version.Entries {
+		lines = append(lines, entry.Line)
+	}
+	for _, section := range version.Sections {
+		lines = append(lines, section.getLines()...)
+	}
+	return lines
+}
+
+func (section *Section) getLines() []string {
+	lines := []string{section.HeaderLine}
+	for _, entry := range section.Entries {
+		lines = append(lines, entry.Line)
+	}
+	for _, subsection := range section.Subsections {
+		lines = append(lines, subsection.getLines()...)
+	}
+	return lines
+}
+
+func hasNonBlankEntry(entries []*Entry) bool {
+	for _, entry := range entries {
+		if !entry.IsBlank() {
+			return true
+		}
+	}
+	return false
+}
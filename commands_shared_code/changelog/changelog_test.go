@@ -0,0 +1,110 @@
+package changelog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	changelogBytes := []byte("# TBD\n* Top-level change\n\n### API\n#### Breaking Changes\n* Removed the v1 endpoint\n#### Features\n* Added the v2 endpoint  \n### Fixes\n* Fixed a typo\n\n#1.2.0\n# Not a version\n* Old change\n")
+	changelog, err := Parse(changelogBytes)
+	require.NoError(t, err)
+	require.Empty(t, changelog.Preamble)
+	require.Len(t, changelog.Versions, 2)
+
+	unreleasedVersion := changelog.Versions[0]
+	require.True(t, unreleasedVersion.IsUnreleased())
+	require.False(t, unreleasedVersion.IsEmpty())
+	require.Equal(t, []*Entry{{Line: "* Top-level change"}, {Line: ""}}, unreleasedVersion.Entries)
+	require.Len(t, unreleasedVersion.Sections, 2)
+	apiSection := unreleasedVersion.Sections[0]
+	require.Equal(t, "API", apiSection.Title)
+	require.Equal(t, 3, apiSection.Level)
+	require.Empty(t, apiSection.Entries)
+	require.True(t, apiSection.HasEntries())
+	require.Len(t, apiSection.Subsections, 2)
+	require.Equal(t, "Breaking Changes", apiSection.Subsections[0].Title)
+	require.Equal(t, []*Entry{{Line: "* Removed the v1 endpoint"}}, apiSection.Subsections[0].Entries)
+	require.Equal(t, "Fixes", unreleasedVersion.Sections[1].Title)
+
+	// Level-one headers that aren't versions are entries of the version they're under
+	releasedVersion, found := changelog.GetVersion("1.2.0")
+	require.True(t, found)
+	require.Equal(t, "#1.2.0", releasedVersion.HeaderLine)
+	require.Equal(t, []*Entry{{Line: "# Not a version"}, {Line: "* Old change"}, {Line: ""}}, releasedVersion.Entries)
+
+	require.Equal(t, string(changelogBytes), string(changelog.Render()))
+}
+
+func TestParse_KeepsPreambleAndCrlfLineEndings(t *testing.T) {
+	changelogBytes := []byte("\r\n# Changelog\r\n# TBD\r\n\r\n### Fixes\r\n\r\n# 0.1.0\r\n* Initial")
+	changelog, err := Parse(changelogBytes)
+	require.NoError(t, err)
+	require.Equal(t, []*Entry{{Line: ""}, {Line: "# Changelog"}}, changelog.Preamble)
+	require.Equal(t, "\r\n", changelog.LineEnding)
+	// An empty section isn't an entry
+	require.False(t, changelog.Versions[0].IsEmpty())
+	require.False(t, changelog.Versions[0].Sections[0].HasEntries())
+	require.Equal(t, string(changelogBytes), string(changelog.Render()))
+
+	changelog, err = Parse([]byte{})
+	require.NoError(t, err)
+	require.Empty(t, changelog.Versions)
+	require.Empty(t, changelog.Render())
+}
+
+func TestParse_RejectsRepeatedVersions(t *testing.T) {
+	_, err := Parse([]byte("# TBD\n* Something\n# 0.1.0\n* Initial\n# 0.1.0\n"))
+	require.ErrorContains(t, err, "Version '0.1.0' is listed more than once")
+
+	// Repeated TBD headers are left for validation to report
+	changelog, err := Parse([]byte("# TBD\n# TBD\n# 0.1.0\n"))
+	require.NoError(t, err)
+	require.Len(t, changelog.Versions, 3)
+}
+
+func TestParseHead(t *testing.T) {
+	changelogStr := "# TBD\r\n### Fixes\r\n* Fixed a typo\r\n\r\n# 1.2.0\r\n* Old change\r\n"
+	head, releasedVersionsOffset, err := ParseHead(strings.NewReader(changelogStr), DefaultMaxLineLength)
+	require.NoError(t, err)
+	require.Len(t, head.Versions, 1)
+	require.True(t, head.Versions[0].IsUnreleased())
+	require.Equal(t, "\r\n", head.LineEnding)
+	require.Equal(t, "# 1.2.0\r\n* Old change\r\n", changelogStr[releasedVersionsOffset:])
+	// The head, a line ending and the rest make up the whole changelog
+	require.Equal(t, changelogStr, string(head.Render())+head.LineEnding+changelogStr[releasedVersionsOffset:])
+
+	// Without a released version, the head is the whole changelog
+	changelogStr = "# TBD\n* Initial\n"
+	head, releasedVersionsOffset, err = ParseHead(strings.NewReader(changelogStr), DefaultMaxLineLength)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), releasedVersionsOffset)
+	require.Equal(t, changelogStr, string(head.Render()))
+}
+
+func TestParseHead_RefusesLongLines(t *testing.T) {
+	longLine := strings.Repeat("x", 100)
+	_, _, err := ParseHead(strings.NewReader("# TBD\n* "+longLine+"\n"), 64)
+	require.ErrorContains(t, err, "longer than the max line length of 64 bytes")
+
+	// Lines after the head are never read, however long they are
+	changelogBytes := []byte("# TBD\n* Something\n# 0.1.0\n* " + longLine + "\n")
+	head, releasedVersionsOffset, err := ParseHead(bytes.NewReader(changelogBytes), 64)
+	require.NoError(t, err)
+	require.Equal(t, "# TBD\n* Something", string(head.Render()))
+	require.Equal(t, int64(len("# TBD\n* Something\n")), releasedVersionsOffset)
+}
+
+func TestNewVersion(t *testing.T) {
+	version := NewVersion("# 1.3.0", []string{"", "### Features", "* Added retries"})
+	require.Equal(t, "1.3.0", version.Name)
+	require.False(t, version.IsUnreleased())
+	require.False(t, version.IsEmpty())
+
+	version = NewVersion("# TBD", []string{"", "  "})
+	require.True(t, version.IsUnreleased())
+	require.True(t, version.IsEmpty())
+}
@@ -0,0 +1,193 @@
+package i18n
+
+// MessageId identifies a user-facing message in the catalog
+type MessageId string
+
+const (
+	ConfirmPrompt             MessageId = "confirm-prompt"
+	ConfirmPromptWithDeadline MessageId = "confirm-prompt-with-deadline"
+	UnrecognizedConfirmAnswer MessageId = "unrecognized-confirm-answer"
+	ConfirmAutoAbortCountdown MessageId = "confirm-auto-abort-countdown"
+
+	ConfirmReleaseQuestion  MessageId = "confirm-release-question"
+	ConfirmCancelQuestion   MessageId = "confirm-cancel-question"
+	ConfirmRollbackQuestion MessageId = "confirm-rollback-question"
+	ConfirmPromoteQuestion  MessageId = "confirm-promote-question"
+
+	WizardUnreleasedEntries       MessageId = "wizard-unreleased-entries"
+	WizardEditEntriesPrompt       MessageId = "wizard-edit-entries-prompt"
+	WizardChooseBump              MessageId = "wizard-choose-bump"
+	WizardSuggestedBump           MessageId = "wizard-suggested-bump"
+	WizardChooseBumpPrompt        MessageId = "wizard-choose-bump-prompt"
+	WizardUnrecognizedBumpChoice  MessageId = "wizard-unrecognized-bump-choice"
+	WizardChooseBumpWithArrowKeys MessageId = "wizard-choose-bump-with-arrow-keys"
+	WizardPreviewChanges          MessageId = "wizard-preview-changes"
+
+	ConfigEditKeepCurrentValueHint MessageId = "config-edit-keep-current-value-hint"
+	ConfigEditInvalidValue         MessageId = "config-edit-invalid-value"
+	ConfigEditSaved                MessageId = "config-edit-saved"
+
+	ConfigReleaseBranchQuestion                MessageId = "config-release-branch-question"
+	ConfigChangelogFilepathQuestion            MessageId = "config-changelog-filepath-question"
+	ConfigPreReleaseScriptsFilepathQuestion    MessageId = "config-pre-release-scripts-filepath-question"
+	ConfigApprovedReleaseNotesFilepathQuestion MessageId = "config-approved-release-notes-filepath-question"
+	ConfigDownstreamManifestFilepathQuestion   MessageId = "config-downstream-manifest-filepath-question"
+	ConfigMajorChangesSubheaderRegexQuestion   MessageId = "config-major-changes-subheader-regex-question"
+	ConfigAllowedDirtyPathsQuestion            MessageId = "config-allowed-dirty-paths-question"
+	ConfigWebhookUrlsQuestion                  MessageId = "config-webhook-urls-question"
+	ConfigRequiredCiChecksQuestion             MessageId = "config-required-ci-checks-question"
+	ConfigForgeTypeQuestion                    MessageId = "config-forge-type-question"
+
+	ReleaseErrorHint                    MessageId = "release-error-hint"
+	DirtyWorktreeRemediation            MessageId = "dirty-worktree-remediation"
+	OutOfSyncRemediation                MessageId = "out-of-sync-remediation"
+	ChangelogInvalidRemediation         MessageId = "changelog-invalid-remediation"
+	PushRejectedRemediation             MessageId = "push-rejected-remediation"
+	InvalidConfigRemediation            MessageId = "invalid-config-remediation"
+	ReleaseTagExistsRemediation         MessageId = "release-tag-exists-remediation"
+	CiNotGreenRemediation               MessageId = "ci-not-green-remediation"
+	ReleaseNotesUnapprovedRemediation   MessageId = "release-notes-unapproved-remediation"
+	PreReleaseScriptFailedRemediation   MessageId = "pre-release-script-failed-remediation"
+	ReleaseEmbargoedRemediation         MessageId = "release-embargoed-remediation"
+	CommitHookRejectedRemediation       MessageId = "commit-hook-rejected-remediation"
+	PolicyDeniedRemediation             MessageId = "policy-denied-remediation"
+	TagSignatureInvalidRemediation      MessageId = "tag-signature-invalid-remediation"
+	ReleaseLockedRemediation            MessageId = "release-locked-remediation"
+	VersionFileOutOfSyncRemediation     MessageId = "version-file-out-of-sync-remediation"
+	MirrorPushFailedRemediation         MessageId = "mirror-push-failed-remediation"
+	ChangelogSectionMissingRemediation  MessageId = "changelog-section-missing-remediation"
+	BuildFailedRemediation              MessageId = "build-failed-remediation"
+	OnlyNonReleasableChangesRemediation MessageId = "only-non-releasable-changes-remediation"
+	ReleasedTooRecentlyRemediation      MessageId = "released-too-recently-remediation"
+	ArtifactAnomalyRemediation          MessageId = "artifact-anomaly-remediation"
+	ReleaseCancelledRemediation         MessageId = "release-cancelled-remediation"
+	NotOnReleaseBranchRemediation       MessageId = "not-on-release-branch-remediation"
+	ReleaseNotApprovedRemediation       MessageId = "release-not-approved-remediation"
+)
+
+// catalog has every message in English, and the ones that have been translated in the other languages; translations
+// must take the same arguments in the same order as the English message
+var catalog = map[Language]map[MessageId]string{
+	English: {
+		ConfirmPrompt:             "VERIFICATION: %s [y/N]",
+		ConfirmPromptWithDeadline: "VERIFICATION: %s [y/N] (auto-aborts in %v)",
+		UnrecognizedConfirmAnswer: "Unrecognized answer '%s'; please answer 'y' or 'n'",
+		ConfirmAutoAbortCountdown: "Auto-aborting in %v if no answer is given...",
+
+		ConfirmReleaseQuestion:  "Release new version '%s'?",
+		ConfirmCancelQuestion:   "Cancel release '%s', deleting its tags from origin and reverting its release commit?",
+		ConfirmRollbackQuestion: "Roll environments '%s' back from version '%s' to '%s'?",
+		ConfirmPromoteQuestion:  "Promote version '%s' from environment '%s' to environment '%s'?",
+
+		WizardUnreleasedEntries:       "Unreleased entries in '%s':",
+		WizardEditEntriesPrompt:       "Edit them in your editor before releasing? [y/N]",
+		WizardChooseBump:              "Bump the version from '%s' to:",
+		WizardSuggestedBump:           "suggested by the changelog",
+		WizardChooseBumpPrompt:        "Choose [%d]:",
+		WizardUnrecognizedBumpChoice:  "Unrecognized choice '%s'; please enter one of the numbers shown",
+		WizardChooseBumpWithArrowKeys: "Choose with the up and down arrow keys, then press enter:",
+		WizardPreviewChanges:          "Releasing version '%s' will make these changes:",
+
+		ConfigEditKeepCurrentValueHint: "Press ENTER to keep the current value shown in brackets.",
+		ConfigEditInvalidValue:         "Invalid value: %v",
+		ConfigEditSaved:                "Saved '%s'",
+
+		ConfigReleaseBranchQuestion:                "Branch that releases are cut from",
+		ConfigChangelogFilepathQuestion:            "Changelog to validate and finalize on release",
+		ConfigPreReleaseScriptsFilepathQuestion:    "File listing the pre-release hook scripts",
+		ConfigApprovedReleaseNotesFilepathQuestion: "Approved copy of the release notes to check the changelog against, when it exists",
+		ConfigDownstreamManifestFilepathQuestion:   "Manifest of the downstream consumers to report releases to, when it exists",
+		ConfigMajorChangesSubheaderRegexQuestion:   "Regex for changelog subheaders under TBD that bump the major version",
+		ConfigAllowedDirtyPathsQuestion:            "Paths that may have uncommitted changes when releasing (globs, or directories ending in '/'), '%s'-separated ('%s' for none)",
+		ConfigWebhookUrlsQuestion:                  "Webhook URLs to notify of releases, '%s'-separated ('%s' for none)",
+		ConfigRequiredCiChecksQuestion:             "CI checks that must pass before releasing with --require-green-ci, '%s'-separated ('%s' for all checks)",
+		ConfigForgeTypeQuestion:                    "Forge hosting the origin remote, if it can't be detected from the remote URL; one of '%s'",
+
+		ReleaseErrorHint:                    "Hint: %s",
+		DirtyWorktreeRemediation:            "Commit, stash or revert the modified files, or allow them to be released along with the changelog under 'allowed-dirty-paths' in the kudet config.",
+		OutOfSyncRemediation:                "Pull or push so that the local release branch matches the remote one, then re-run the release; nothing has been pushed.",
+		ChangelogInvalidRemediation:         "Fix the changelog so that it starts with a '# TBD' section of release notes followed by the released versions' sections.",
+		PushRejectedRemediation:             "Check that the token can push to the release branch and tags, and that the branch hasn't moved, then re-run the release; a release that got partway through pushing is resumed.",
+		InvalidConfigRemediation:            "Fix the kudet config, e.g. with 'kudet config edit', which validates it.",
+		ReleaseTagExistsRemediation:         "Make sure the version's tags exist on the remote and point at the same commit, or delete the stray local tags if it was never released.",
+		CiNotGreenRemediation:               "Wait for the required CI checks to pass on the release branch, or fix them, then re-run the release.",
+		ReleaseNotesUnapprovedRemediation:   "Get the release notes changes approved by updating the approved copy, or acknowledge them with '--acknowledge-notes-diff'.",
+		PreReleaseScriptFailedRemediation:   "Fix the failing pre-release script, whose output is in the error, then re-run the release; its changes have been reset.",
+		ReleaseEmbargoedRemediation:         "Run 'kudet lift-embargo' at the disclosure time, or abandon the embargoed release as described in the error.",
+		CommitHookRejectedRemediation:       "Fix what the repo's git hook objected to, whose output is in the error, then re-run the release; to deliberately bypass the hooks, pass '--no-verify'.",
+		PolicyDeniedRemediation:             "Address the reasons the release policy gave, or release when its rules allow it, e.g. inside the release window; nothing has been changed.",
+		TagSignatureInvalidRemediation:      "Find out who created the latest release's tags and whether its history was tampered with; if the release is genuine, have a trusted key sign its tags, or add the key that signed them to the trusted keyring. Nothing has been changed.",
+		ReleaseLockedRemediation:            "Wait for the release holding the lock to finish. If it died, run 'kudet release-state unlock', then re-run the release to resume what it recorded, or run 'kudet release-state abort' to give up on it.",
+		VersionFileOutOfSyncRemediation:     "If the version file didn't have the previous release's version, bring it back in sync on the release branch; if it didn't have the next version after the pre-release scripts, fix the script that's meant to bump it. Then re-run the release.",
+		MirrorPushFailedRemediation:         "The release is out on origin, so don't re-run it. Push the release branch and tags to the mirror by hand, checking that its token is set and can push.",
+		ChangelogSectionMissingRemediation:  "Add entries for the changed files under the changelog subheader that the kudet config's 'required-changelog-sections' requires, and get them acknowledged by its owners with an 'Acked-by:' trailer on a commit, if it asks for one.",
+		BuildFailedRemediation:              "Fix the Bazel build of the release, whose output is in the error, then re-run the release; its changes have been reset.",
+		OnlyNonReleasableChangesRemediation: "Nothing that warrants a release changed since the previous one, so there's nothing to do. If something does need releasing, merge it first, or narrow the kudet config's 'non-releasable-changes' paths if they cover it.",
+		ReleasedTooRecentlyRemediation:      "The previous release was cut moments ago, e.g. by an earlier run of a retried CI job, so check that it went out before releasing again. Wait out the kudet config's 'min-release-interval', or pass '--force' if another release really is needed now. Nothing has been changed.",
+		ArtifactAnomalyRemediation:          "The release's artifacts differ from the previous release's in ways that suggest the build went wrong, e.g. a platform build is missing or a binary changed size drastically. Fix the pre-release scripts or build and release again; if the difference is intended, pass '--force'. Nothing has been committed.",
+		ReleaseCancelledRemediation:         "The release was cancelled with 'kudet cancel' during its undo window: its tags were deleted and its release commit was reverted, so the post-release steps were skipped. Fix what prompted the cancellation and release again.",
+		NotOnReleaseBranchRemediation:       "Release from the release branch's latest commit, e.g. from a CI workflow triggered by pushes to the release branch rather than by pull requests or merge queues; nothing has been pushed.",
+		ReleaseNotApprovedRemediation:       "None of the kudet config's 'approval' approvers approved the release on its approval issue: one of them denied it, or nobody decided in time. Nothing has been changed; address their concerns, or check that they were notified, and release again.",
+	},
+	Chinese: {
+		ConfirmPrompt:             "确认：%s [y/N]",
+		ConfirmPromptWithDeadline: "确认：%s [y/N]（%v 后自动中止）",
+		UnrecognizedConfirmAnswer: "无法识别的回答 '%s'；请回答 'y' 或 'n'",
+		ConfirmAutoAbortCountdown: "如果未作答，将在 %v 后自动中止...",
+
+		ConfirmReleaseQuestion:  "是否发布新版本 '%s'？",
+		ConfirmCancelQuestion:   "是否取消发布 '%s'，从 origin 删除其标签并还原其发布提交？",
+		ConfirmRollbackQuestion: "是否将环境 '%s' 从版本 '%s' 回滚到 '%s'？",
+		ConfirmPromoteQuestion:  "是否将版本 '%s' 从环境 '%s' 推广到环境 '%s'？",
+
+		WizardUnreleasedEntries:       "'%s' 中尚未发布的条目：",
+		WizardEditEntriesPrompt:       "发布前是否在编辑器中编辑这些条目？[y/N]",
+		WizardChooseBump:              "将版本从 '%s' 提升到：",
+		WizardSuggestedBump:           "变更日志建议",
+		WizardChooseBumpPrompt:        "请选择 [%d]：",
+		WizardUnrecognizedBumpChoice:  "无法识别的选择 '%s'；请输入所列出的编号之一",
+		WizardChooseBumpWithArrowKeys: "请使用上下方向键选择，然后按回车键：",
+		WizardPreviewChanges:          "发布版本 '%s' 将做出以下修改：",
+
+		ConfigEditKeepCurrentValueHint: "按回车键保留方括号中显示的当前值。",
+		ConfigEditInvalidValue:         "无效的值：%v",
+		ConfigEditSaved:                "已保存 '%s'",
+
+		ConfigReleaseBranchQuestion:                "用于发布的分支",
+		ConfigChangelogFilepathQuestion:            "发布时要校验并定稿的变更日志",
+		ConfigPreReleaseScriptsFilepathQuestion:    "列出发布前钩子脚本的文件",
+		ConfigApprovedReleaseNotesFilepathQuestion: "用于核对变更日志的已批准发布说明副本（如存在）",
+		ConfigDownstreamManifestFilepathQuestion:   "接收发布通知的下游使用方清单（如存在）",
+		ConfigMajorChangesSubheaderRegexQuestion:   "TBD 下会提升主版本号的变更日志子标题的正则表达式",
+		ConfigAllowedDirtyPathsQuestion:            "发布时允许存在未提交修改的路径（通配符，或以 '/' 结尾的目录），以 '%s' 分隔（输入 '%s' 表示无）",
+		ConfigWebhookUrlsQuestion:                  "发布时要通知的 Webhook URL，以 '%s' 分隔（输入 '%s' 表示无）",
+		ConfigRequiredCiChecksQuestion:             "使用 --require-green-ci 发布前必须通过的 CI 检查，以 '%s' 分隔（输入 '%s' 表示所有检查）",
+		ConfigForgeTypeQuestion:                    "托管 origin 远程仓库的代码托管平台（当无法从远程 URL 识别时）；可选值为 '%s'",
+
+		ReleaseErrorHint:                    "提示：%s",
+		DirtyWorktreeRemediation:            "提交、暂存（stash）或还原已修改的文件，或在 kudet 配置的 'allowed-dirty-paths' 中允许它们随变更日志一起发布。",
+		OutOfSyncRemediation:                "拉取或推送，使本地发布分支与远程分支一致，然后重新运行发布；目前尚未推送任何内容。",
+		ChangelogInvalidRemediation:         "修正变更日志，使其以包含发布说明的 '# TBD' 部分开头，其后是各已发布版本的部分。",
+		PushRejectedRemediation:             "确认令牌有权限推送发布分支和标签，且该分支未被移动，然后重新运行发布；推送到一半的发布会被继续完成。",
+		InvalidConfigRemediation:            "修正 kudet 配置，例如使用会校验配置的 'kudet config edit'。",
+		ReleaseTagExistsRemediation:         "确认该版本的标签已存在于远程仓库并指向同一提交；如果该版本从未发布，请删除本地残留的标签。",
+		CiNotGreenRemediation:               "等待发布分支上必需的 CI 检查通过，或修复它们，然后重新运行发布。",
+		ReleaseNotesUnapprovedRemediation:   "通过更新已批准的副本来批准发布说明的修改，或使用 '--acknowledge-notes-diff' 确认这些修改。",
+		PreReleaseScriptFailedRemediation:   "修复失败的发布前脚本（其输出见错误信息），然后重新运行发布；脚本所做的修改已被重置。",
+		ReleaseEmbargoedRemediation:         "在披露时间运行 'kudet lift-embargo'，或按照错误信息中的说明放弃该禁运发布。",
+		CommitHookRejectedRemediation:       "修复仓库的 git 钩子所指出的问题（其输出见错误信息），然后重新运行发布；如需有意跳过这些钩子，请传入 '--no-verify'。",
+		PolicyDeniedRemediation:             "处理发布策略给出的原因，或在其规则允许时（例如在发布时间窗口内）再发布；目前尚未做任何修改。",
+		TagSignatureInvalidRemediation:      "查明最新发布的标签由谁创建、其历史是否被篡改；若该发布属实，请用受信任的密钥为其标签签名，或将签名所用的密钥加入受信任的密钥环。目前尚未做任何修改。",
+		ReleaseLockedRemediation:            "请等待持有锁的发布完成。如果该发布已中断，请运行 'kudet release-state unlock'，然后重新运行发布以继续其记录的进度，或运行 'kudet release-state abort' 放弃该发布。",
+		VersionFileOutOfSyncRemediation:     "如果版本文件中不是上一个发布的版本，请在发布分支上将其同步；如果运行发布前脚本后其中不是下一个版本，请修复本应更新它的脚本。然后重新运行发布。",
+		MirrorPushFailedRemediation:         "发布已推送到 origin，请勿重新运行发布。请手动将发布分支和标签推送到镜像，并检查其令牌已设置且具有推送权限。",
+		ChangelogSectionMissingRemediation:  "在 kudet 配置的 'required-changelog-sections' 所要求的变更日志子标题下，为已变更的文件添加条目；如果配置要求确认，请让其负责人在某个提交中通过 'Acked-by:' 尾注确认。",
+		BuildFailedRemediation:              "修复发布的 Bazel 构建（其输出见错误信息），然后重新运行发布；构建所做的修改已被重置。",
+		OnlyNonReleasableChangesRemediation: "自上次发布以来，没有需要发布的变更，因此无需操作。如果确实有内容需要发布，请先合并它；如果 kudet 配置中的 'non-releasable-changes' 路径覆盖了它，请缩小这些路径。",
+		ReleasedTooRecentlyRemediation:      "上一次发布刚刚完成，例如由重试的 CI 任务的先前运行完成，请在再次发布之前确认它已发布成功。请等待 kudet 配置中的 'min-release-interval' 过去，如果确实需要立即再次发布，请传入 '--force'。未做任何更改。",
+		ArtifactAnomalyRemediation:          "本次发布的构建产物与上一次发布的相比存在异常，表明构建可能出了问题，例如缺少某个平台的构建，或某个二进制文件的大小变化剧烈。请修复预发布脚本或构建后重新发布；如果这些差异是预期的，请传入 '--force'。未提交任何内容。",
+		ReleaseCancelledRemediation:         "本次发布在撤销窗口内被 'kudet cancel' 取消：其标签已被删除，其发布提交已被还原，因此跳过了发布后的步骤。请修复导致取消的问题后重新发布。",
+		NotOnReleaseBranchRemediation:       "请从发布分支的最新提交进行发布，例如在由推送到发布分支（而非拉取请求或合并队列）触发的 CI 工作流中发布；目前尚未推送任何内容。",
+		ReleaseNotApprovedRemediation:       "kudet 配置中 'approval' 的审批人没有在审批 issue 上批准本次发布：有人拒绝了它，或者没有人及时做出决定。未做任何更改；请处理他们的顾虑，或确认他们已收到通知，然后重新发布。",
+	},
+}
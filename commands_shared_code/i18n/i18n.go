@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"strings"
+)
+
+// Language is the language that user-facing messages are shown in, as its ISO 639-1 code
+type Language string
+
+const (
+	English Language = "en"
+	Chinese Language = "zh"
+)
+
+// SupportedLanguages are the languages that the message catalog has messages in
+var SupportedLanguages = []Language{English, Chinese}
+
+// The locale environment variables, in the order that they take precedence
+var localeEnvVars = []string{"LC_ALL", "LC_MESSAGES", "LANG"}
+
+// The language messages are shown in; like the log level, it's set once for the whole CLI before any command runs
+var currentLanguage = English
+
+// SetLanguage shows user-facing messages in the given language from now on
+func SetLanguage(language Language) {
+	currentLanguage = language
+}
+
+// GetLanguage returns the language that user-facing messages are shown in
+func GetLanguage() Language {
+	return currentLanguage
+}
+
+// DetectLanguage returns the language requested with the flag, if any, or else the one of the operator's locale; locales
+// in languages that aren't supported fall back to English, but requesting one with the flag is an error
+func DetectLanguage(langFlagValue string) (Language, error) {
+	if langFlagValue != "" {
+		language, isSupported := parseLanguage(langFlagValue)
+		if !isSupported {
+			return "", stacktrace.NewError("Language '%s' isn't supported; supported languages are '%s'", langFlagValue, SupportedLanguages)
+		}
+		return language, nil
+	}
+	for _, localeEnvVar := range localeEnvVars {
+		locale := os.Getenv(localeEnvVar)
+		if locale == "" {
+			continue
+		}
+		// The first locale variable that's set wins, even if its language isn't one we have messages in
+		language, isSupported := parseLanguage(locale)
+		if !isSupported {
+			return English, nil
+		}
+		return language, nil
+	}
+	return English, nil
+}
+
+// Sprintf formats the message in the current language, falling back to English if it hasn't been translated
+func Sprintf(messageId MessageId, args ...interface{}) string {
+	message, found := catalog[currentLanguage][messageId]
+	if !found {
+		message = catalog[English][messageId]
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// parseLanguage gets the language out of a locale like 'zh_CN.UTF-8', 'zh-Hans' or 'en'
+func parseLanguage(locale string) (Language, bool) {
+	languageCode := strings.ToLower(locale)
+	if separatorIdx := strings.IndexAny(languageCode, "_-.@"); separatorIdx >= 0 {
+		languageCode = languageCode[:separatorIdx]
+	}
+	for _, language := range SupportedLanguages {
+		if string(language) == languageCode {
+			return language, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"testing"
+)
+
+var formatVerbRegex = regexp.MustCompile(`%[a-z]`)
+
+func TestDetectLanguage(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "zh_CN.UTF-8")
+	language, err := DetectLanguage("")
+	require.NoError(t, err)
+	require.Equal(t, Chinese, language)
+
+	// The flag wins over the locale
+	language, err = DetectLanguage("en")
+	require.NoError(t, err)
+	require.Equal(t, English, language)
+
+	// The first locale variable that's set wins, even when we don't have messages in its language
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	language, err = DetectLanguage("")
+	require.NoError(t, err)
+	require.Equal(t, English, language)
+
+	_, err = DetectLanguage("fr")
+	require.Error(t, err)
+}
+
+func TestSprintf_FallsBackToEnglish(t *testing.T) {
+	defer SetLanguage(English)
+	SetLanguage(Chinese)
+	require.Equal(t, "提示：x", Sprintf(ReleaseErrorHint, "x"))
+
+	SetLanguage(Language("fr"))
+	require.Equal(t, "Hint: x", Sprintf(ReleaseErrorHint, "x"))
+}
+
+func TestCatalog_TranslationsMatchEnglish(t *testing.T) {
+	for language, messages := range catalog {
+		for messageId, message := range messages {
+			englishMessage, found := catalog[English][messageId]
+			require.True(t, found, "Message '%s' in language '%s' has no English original", messageId, language)
+			require.Equal(t, formatVerbRegex.FindAllString(englishMessage, -1), formatVerbRegex.FindAllString(message, -1), "Message '%s' in language '%s' takes different arguments to the English one", messageId, language)
+		}
+	}
+}
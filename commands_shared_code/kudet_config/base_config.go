@@ -0,0 +1,172 @@
+package kudet_config
+
+import (
+	"bytes"
+	"github.com/kurtosis-tech/stacktrace"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// The file, next to the .kudet.yml, that locks the base config it extends to a commit of the base's repo; it holds a
+	// copy of the base as of that commit, so loading the config never needs the network and updates show up in review
+	BaseConfigLockFilename = ".kudet-base.lock.yml"
+
+	baseConfigLockHeadComment = "Generated by 'kudet config update-base' from the base config that " + KudetConfigFilename + " extends; don't edit it by hand"
+)
+
+// BaseConfigLock is the base config that a repo's config extends, as of the commit that its ref resolved to when it was
+// locked
+type BaseConfigLock struct {
+	Url        string `yaml:"url"`
+	Path       string `yaml:"path"`
+	Ref        string `yaml:"ref"`
+	CommitHash string `yaml:"commit"`
+
+	// The base config itself, kept as YAML so that the repo's config can be merged into it key by key
+	Config yaml.Node `yaml:"config"`
+}
+
+// GetPath returns the path of the base config in its repo
+func (extendsConfig ExtendsConfig) GetPath() string {
+	if extendsConfig.Path == "" {
+		return KudetConfigFilename
+	}
+	return extendsConfig.Path
+}
+
+// NewBaseConfigLock locks the base config that the given config extends to its contents as of the commit that its ref
+// resolved to; the base must be a valid config on its own, and can't extend another config in turn
+func NewBaseConfigLock(extendsConfig ExtendsConfig, commitHash string, baseConfigBytes []byte) (*BaseConfigLock, error) {
+	baseDoc, err := parseKudetConfigDocument(baseConfigBytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the base config")
+	}
+	for idx := 0; idx+1 < len(baseDoc.root.Content); idx += 2 {
+		if baseDoc.root.Content[idx].Value == ExtendsKey {
+			return nil, stacktrace.NewError("The base config has its own '%s', but base configs can't extend other configs", ExtendsKey)
+		}
+	}
+	if _, err := ParseKudetConfig(baseConfigBytes); err != nil {
+		return nil, stacktrace.Propagate(err, "The base config is invalid on its own")
+	}
+	return &BaseConfigLock{
+		Url:        extendsConfig.Url,
+		Path:       extendsConfig.GetPath(),
+		Ref:        extendsConfig.Ref,
+		CommitHash: commitHash,
+		Config:     *baseDoc.root,
+	}, nil
+}
+
+// ExtendKudetConfig merges the repo's config into the locked base config: mappings are merged key by key, all the way
+// down, while the repo's other values, lists included, replace the base's. The lock must be of the base that the repo's
+// config extends.
+func (lock *BaseConfigLock) ExtendKudetConfig(configBytes []byte) (*KudetConfig, error) {
+	config, err := ParseKudetConfig(configBytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the kudet config that extends the base config")
+	}
+	if config.Extends.Url != lock.Url || config.Extends.GetPath() != lock.Path || config.Extends.Ref != lock.Ref {
+		return nil, stacktrace.NewError(
+			"The kudet config extends '%s' at '%s' of '%s', but '%s' locks '%s' at '%s' of '%s'; run 'kudet config update-base' to lock the base that it extends now",
+			config.Extends.GetPath(),
+			config.Extends.Ref,
+			config.Extends.Url,
+			BaseConfigLockFilename,
+			lock.Path,
+			lock.Ref,
+			lock.Url,
+		)
+	}
+	configDoc, err := parseKudetConfigDocument(configBytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the kudet config that extends the base config")
+	}
+	mergedConfigBytes, err := yaml.Marshal(mergeConfigNodes(&lock.Config, configDoc.root))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred serializing the kudet config merged into the base config")
+	}
+	mergedConfig, err := ParseKudetConfig(mergedConfigBytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "The kudet config merged into the base config at commit '%s' of '%s' is invalid", lock.CommitHash, lock.Url)
+	}
+	return mergedConfig, nil
+}
+
+// Save writes the lock next to the .kudet.yml at the root of the given repo
+func (lock *BaseConfigLock) Save(repoDirpath string) error {
+	lockNode := &yaml.Node{}
+	if err := lockNode.Encode(lock); err != nil {
+		return stacktrace.Propagate(err, "An error occurred encoding the base config lock")
+	}
+	lockNode.HeadComment = baseConfigLockHeadComment
+	buffer := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(yamlIndentSpaces)
+	if err := encoder.Encode(lockNode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred encoding the base config lock YAML")
+	}
+	if err := encoder.Close(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred flushing the base config lock YAML encoder")
+	}
+	lockFilepath := filepath.Join(repoDirpath, BaseConfigLockFilename)
+	if err := os.WriteFile(lockFilepath, buffer.Bytes(), configFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the base config lock to '%s'", lockFilepath)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func loadBaseConfigLock(repoDirpath string) (*BaseConfigLock, bool, error) {
+	lockFilepath := filepath.Join(repoDirpath, BaseConfigLockFilename)
+	lockBytes, err := os.ReadFile(lockFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, stacktrace.Propagate(err, "An error occurred reading the base config lock at '%s'", lockFilepath)
+	}
+	lock := &BaseConfigLock{}
+	decoder := yaml.NewDecoder(bytes.NewReader(lockBytes))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(lock); err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred parsing the base config lock at '%s'", lockFilepath)
+	}
+	return lock, true, nil
+}
+
+// mergeConfigNodes returns the override merged into the base, without modifying either: two mappings are merged key by
+// key, and anything else in the override replaces the base
+func mergeConfigNodes(base *yaml.Node, override *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		return override
+	}
+	merged := &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Tag:     base.Tag,
+		Content: append([]*yaml.Node{}, base.Content...),
+	}
+	// Mapping node content alternates key, value, key, value...
+	for overrideIdx := 0; overrideIdx+1 < len(override.Content); overrideIdx += 2 {
+		key := override.Content[overrideIdx]
+		value := override.Content[overrideIdx+1]
+		isInBase := false
+		for mergedIdx := 0; mergedIdx+1 < len(merged.Content); mergedIdx += 2 {
+			if merged.Content[mergedIdx].Value == key.Value {
+				merged.Content[mergedIdx+1] = mergeConfigNodes(merged.Content[mergedIdx+1], value)
+				isInBase = true
+				break
+			}
+		}
+		if !isInBase {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return merged
+}
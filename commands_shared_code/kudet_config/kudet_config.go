@@ -0,0 +1,1630 @@
+package kudet_config
+
+import (
+	"bytes"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/version_file_updater"
+	"github.com/kurtosis-tech/stacktrace"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// The optional file, relative to the root of the target repo, which configures how kudet operates on the repo
+	KudetConfigFilename = ".kudet.yml"
+
+	// The YAML keys of the config, for tools that edit the config document directly
+	MinKudetVersionKey                   = "min-kudet-version"
+	ReleaseBranchKey                     = "release-branch"
+	ChangelogFilepathKey                 = "changelog-filepath"
+	ChangelogTbdSkeletonFilepathKey      = "changelog-tbd-skeleton-filepath"
+	AdditionalChangelogFilepathsKey      = "additional-changelog-filepaths"
+	ChangelogMaxLineLengthKey            = "changelog-max-line-length"
+	PreReleaseScriptsFilepathKey         = "pre-release-scripts-filepath"
+	PreReleaseScriptsShellKey            = "pre-release-scripts-shell"
+	PreReleaseScriptsWritablePathsKey    = "pre-release-scripts-writable-paths"
+	MajorChangesSubheaderRegexKey        = "major-changes-subheader-regex"
+	ChangelogCategoriesKey               = "changelog-categories"
+	ChangelogCategorySubheaderKey        = "subheader"
+	ChangelogCategoryBumpKey             = "bump"
+	ChangelogCategoryForceReleaseKey     = "force-release"
+	BumpOverrideKey                      = "bump-override"
+	BumpOverrideApproversKey             = "approvers"
+	VersionTracksKey                     = "version-tracks"
+	VersionTrackNameKey                  = "name"
+	VersionTrackTagPrefixKey             = "tag-prefix"
+	VersionTrackTagTemplateKey           = "tag-template"
+	VersionTrackChangelogFilepathKey     = "changelog-filepath"
+	ApprovedReleaseNotesFilepathKey      = "approved-release-notes-filepath"
+	AllowedDirtyPathsKey                 = "allowed-dirty-paths"
+	SkipCiMarkerKey                      = "skip-ci-marker"
+	ReleaseCommitMessageTemplateKey      = "release-commit-message-template"
+	TagMessageTemplateKey                = "tag-message-template"
+	NotificationsKey                     = "notifications"
+	WebhookUrlsKey                       = "webhook-urls"
+	AnalyticsKey                         = "analytics"
+	AnalyticsEnabledKey                  = "enabled"
+	AnalyticsEndpointKey                 = "endpoint"
+	CiKey                                = "ci"
+	RequiredChecksKey                    = "required-checks"
+	SecurityAdvisoryKey                  = "security-advisory"
+	EcosystemKey                         = "ecosystem"
+	PackageKey                           = "package"
+	SeverityKey                          = "severity"
+	TagParsingKey                        = "tag-parsing"
+	AllowVPrefixKey                      = "allow-v-prefix"
+	AllowPrereleasesAndMetadataKey       = "allow-prereleases-and-metadata"
+	ForgeKey                             = "forge"
+	ForgeTypeKey                         = "type"
+	ForgeApiUrlKey                       = "api-url"
+	DownstreamKey                        = "downstream"
+	ManifestFilepathKey                  = "manifest-filepath"
+	NotifyOwnersKey                      = "notify-owners"
+	OwnershipKey                         = "ownership"
+	OwnersKey                            = "owners"
+	VersionFilesKey                      = "version-files"
+	VersionFileFilepathKey               = "filepath"
+	VersionFilePatternKey                = "pattern"
+	VersionFileBumpedByScriptsKey        = "bumped-by-scripts"
+	EnvironmentsKey                      = "environments"
+	GitopsRepoDirpathKey                 = "gitops-repo-dirpath"
+	GitopsBranchKey                      = "branch"
+	EnvironmentManifestsKey              = "manifests"
+	HealthCheckKey                       = "health-check"
+	HealthCheckUrlKey                    = "url"
+	HealthCheckDurationKey               = "duration"
+	HealthCheckIntervalKey               = "interval"
+	PreReleaseScriptSecretsKey           = "pre-release-script-secrets"
+	PreReleaseScriptArtifactsKey         = "pre-release-script-artifacts"
+	SecretNameKey                        = "name"
+	SecretSourceKey                      = "source"
+	SecretRefKey                         = "ref"
+	PolicyKey                            = "policy"
+	PolicyPathsKey                       = "paths"
+	PolicyQueryKey                       = "query"
+	PolicyWasmGatesKey                   = "wasm-gates"
+	TagSignaturesKey                     = "tag-signatures"
+	TagSignaturesKeyringKey              = "keyring"
+	ReleaseStateKey                      = "release-state"
+	ReleaseStateBackendKey               = "backend"
+	ReleaseStateUrlKey                   = "url"
+	DryRunStepsKey                       = "dry-run-steps"
+	MirrorsKey                           = "mirrors"
+	MirrorUrlKey                         = "url"
+	MirrorTokenEnvVarKey                 = "token-env-var"
+	MirrorOnFailureKey                   = "on-failure"
+	RequiredChangelogSectionsKey         = "required-changelog-sections"
+	RequiredChangelogSectionSubheaderKey = "subheader"
+	RequiredChangelogSectionPathsKey     = "paths"
+	RequiredChangelogSectionAckedByKey   = "acked-by"
+	ExtendsKey                           = "extends"
+	ExtendsUrlKey                        = "url"
+	ExtendsPathKey                       = "path"
+	ExtendsRefKey                        = "ref"
+	ExtendsTokenEnvVarKey                = "token-env-var"
+	BazelKey                             = "bazel"
+	BazelStampFilepathKey                = "stamp-filepath"
+	BazelTargetKey                       = "target"
+	BazelCommandKey                      = "command"
+	BazelArtifactsKey                    = "artifacts"
+	VersionSourceKey                     = "version-source"
+	VersionSourceUrlKey                  = "url"
+	VersionSourceRepositoryKey           = "repository"
+	VersionSourceTokenEnvVarKey          = "token-env-var"
+	AuditLogKey                          = "audit-log"
+	AuditLogUrlKey                       = "url"
+	AuditLogTokenEnvVarKey               = "token-env-var"
+	NonReleasableChangesKey              = "non-releasable-changes"
+	NonReleasableChangesPathsKey         = "paths"
+	NonReleasableChangesOnOnlyKey        = "on-only-non-releasable"
+	MinReleaseIntervalKey                = "min-release-interval"
+	UndoWindowKey                        = "undo-window"
+	TelemetryKey                         = "telemetry"
+	TelemetryOtlpEndpointKey             = "otlp-endpoint"
+	TelemetryHeadersEnvVarKey            = "headers-env-var"
+	GithubAppKey                         = "github-app"
+	GithubAppIdKey                       = "app-id"
+	GithubAppInstallationIdKey           = "installation-id"
+	GithubAppPrivateKeyEnvVarKey         = "private-key-env-var"
+	ApprovalKey                          = "approval"
+	ApprovalApproversKey                 = "approvers"
+	ApprovalTimeoutKey                   = "timeout"
+	PullRequestCommentsKey               = "pull-request-comments"
+	PullRequestCommentsEnabledKey        = "enabled"
+	MilestonesKey                        = "milestones"
+	MilestonesEnabledKey                 = "enabled"
+	MilestonesNextBumpKey                = "next-bump"
+
+	defaultReleaseBranch                   = "main"
+	defaultChangelogRelFilepath            = "docs/changelog.md"
+	defaultPreReleaseScriptsRelFilepath    = ".pre-release-scripts.txt"
+	defaultPreReleaseScriptsShellCmd       = "sh"
+	defaultPreReleaseScriptsShellCmdFlag   = "-c"
+	defaultPreReleaseScriptsParallelism    = 1
+	defaultApprovedReleaseNotesRelFilepath = "docs/approved-release-notes.md"
+	defaultDownstreamManifestRelFilepath   = "docs/downstream-consumers.yml"
+	defaultMajorChangesSubheaderRegex      = `^###*\s*[Mm]ajor\b.*$`
+	defaultGitopsBranch                    = "main"
+	defaultReleaseCommitMessageTemplate    = "Finalize changes for release version '{{.Version}}'"
+	defaultPolicyQuery                     = "data.kudet.release.deny"
+	defaultHealthCheckDuration             = 5 * time.Minute
+	defaultHealthCheckInterval             = 30 * time.Second
+	defaultFetchGracePeriod                = 1 * time.Minute
+	defaultBazelCmd                        = "bazel"
+	defaultBazelRunSubcmd                  = "run"
+	defaultBazelStampFlag                  = "--stamp"
+	defaultArtifactDiffMaxSizeRatio        = 2
+	defaultApprovalTimeout                 = 1 * time.Hour
+
+	// The advisory ecosystems that GitHub accepts
+	SecurityAdvisoryEcosystems = "actions,composer,erlang,go,maven,npm,nuget,other,pip,pub,rubygems,rust,swift"
+	SecurityAdvisorySeverities = "low,medium,high,critical"
+
+	GithubForgeType = "github"
+	GitlabForgeType = "gitlab"
+	ForgeTypes      = GithubForgeType + "," + GitlabForgeType
+
+	// Secrets for the pre-release scripts are read from one of kudet's environment variables, from a Vault KV secret at
+	// 'path#key', or from the output of a command, which covers cloud secret managers' CLIs
+	EnvSecretSource     = "env"
+	VaultSecretSource   = "vault"
+	CommandSecretSource = "command"
+	SecretSources       = EnvSecretSource + "," + VaultSecretSource + "," + CommandSecretSource
+
+	// The record of an in-progress release is kept in the repo's version control directory, or in an S3 or GCS bucket
+	// through the 'aws' and 'gcloud' CLIs so that a release that dies on one machine can be resumed from another
+	LocalReleaseStateBackend = "local"
+	S3ReleaseStateBackend    = "s3"
+	GcsReleaseStateBackend   = "gcs"
+	ReleaseStateBackends     = LocalReleaseStateBackend + "," + S3ReleaseStateBackend + "," + GcsReleaseStateBackend
+
+	// The release steps that reach outside the local repo, any of which can be made to only log what it would do; the steps
+	// that follow the push all are once it is, since nothing has been released for them to act on
+	PushDryRunStep                 = "push"
+	SecurityAdvisoryDryRunStep     = "security-advisory"
+	ExternalVersionFilesDryRunStep = "external-version-files"
+	PromotionDryRunStep            = "promotion"
+	ReleaseAssetsDryRunStep        = "release-assets"
+	DownstreamDryRunStep           = "downstream"
+	NotificationsDryRunStep        = "notifications"
+	AuditNoteDryRunStep            = "audit-note"
+	VersionSourceDryRunStep        = "version-source"
+	AuditLogDryRunStep             = "audit-log"
+	PullRequestCommentsDryRunStep  = "pull-request-comments"
+	MilestonesDryRunStep           = "milestones"
+	DryRunSteps                    = PushDryRunStep + "," + SecurityAdvisoryDryRunStep + "," + ExternalVersionFilesDryRunStep + "," + PromotionDryRunStep + "," + ReleaseAssetsDryRunStep + "," + DownstreamDryRunStep + "," + NotificationsDryRunStep + "," + AuditNoteDryRunStep + "," + VersionSourceDryRunStep + "," + AuditLogDryRunStep + "," + PullRequestCommentsDryRunStep + "," + MilestonesDryRunStep
+
+	// A mirror that a release fails to be pushed to is either logged for the operator to push by hand, or also fails the
+	// release, once everything else is done since origin already has it by then
+	// The manifests that packages' names, versions and dependencies on each other are read from
+	NpmPackageManifestFilename = "package.json"
+	GoPackageManifestFilename  = "go.mod"
+	PackageManifestFilenames   = NpmPackageManifestFilename + "," + GoPackageManifestFilename
+
+	WarnMirrorFailurePolicy = "warn"
+	FailMirrorFailurePolicy = "fail"
+	MirrorFailurePolicies   = WarnMirrorFailurePolicy + "," + FailMirrorFailurePolicy
+
+	// When only non-releasable files changed since the previous release, the release either goes ahead with a warning or
+	// is skipped, in which case 'kudet should-release' doesn't consider the repo due one either
+	WarnNonReleasableChangesPolicy = "warn"
+	SkipNonReleasableChangesPolicy = "skip"
+	NonReleasableChangesPolicies   = WarnNonReleasableChangesPolicy + "," + SkipNonReleasableChangesPolicy
+
+	// The version bumps that entries under a changelog category can ask for
+	MajorBump = "major"
+	MinorBump = "minor"
+	PatchBump = "patch"
+	Bumps     = MajorBump + "," + MinorBump + "," + PatchBump
+
+	// The name that the repo's main version line, with the plain 'X.Y.Z' tags and the main changelog, is picked by among
+	// the version tracks
+	MainVersionTrackName = "main"
+
+	// What version tracks' tag templates are rendered with to find what comes before and after the version in their tags,
+	// and the version that example tags are made with
+	tagTemplateVersionPlaceholder = "\x00"
+	sampleTagVersion              = "1.2.3"
+
+	// Vault secret refs are the secret's path, then this, then the key of the value within the secret
+	VaultSecretRefKeySeparator = "#"
+
+	httpScheme  = "http"
+	httpsScheme = "https"
+	// GCS URLs use 'gs' rather than the backend's name
+	gcsUrlScheme = "gs"
+)
+
+var envVarNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ReleaseCommitMessageData is what the release commit message template can refer to
+type ReleaseCommitMessageData struct {
+	// The version being released, e.g. '1.2.3'
+	Version string
+
+	// The version released before it, or '0.0.0' for the first release
+	PreviousVersion string
+
+	// The day of the release in UTC, e.g. '2022-06-01'
+	Date string
+}
+
+// TagTemplateData is what a version track's tag template can refer to
+type TagTemplateData struct {
+	// The version track's name, e.g. 'cli'
+	Scope string
+
+	// The version being tagged, e.g. '1.2.3'
+	Version string
+}
+
+// TagMessageData is what the release tags' message template can refer to
+type TagMessageData struct {
+	// The tag being created, e.g. '1.2.3' or 'v1.2.3'
+	TagName string
+
+	// The version being released, e.g. '1.2.3'
+	Version string
+
+	// The version released before it, or '0.0.0' for the first release
+	PreviousVersion string
+
+	// The day of the release in UTC, e.g. '2022-06-01'
+	Date string
+
+	// Who released it, e.g. 'Jane Doe <jane@example.com>'
+	Author string
+
+	// The number of entries in the release notes, and under each of their sections by title, e.g. 'Fixes'
+	EntryCount         int
+	SectionEntryCounts map[string]int
+}
+
+// KudetConfig is the parsed form of a repo's .kudet.yml; every field is optional and falls back to the historical default
+type KudetConfig struct {
+	// The shared base config that this one extends, e.g. a central repo's canonical pipeline; the base is read from the
+	// repo's lock of it rather than fetched whenever the config is loaded
+	Extends ExtendsConfig `yaml:"extends,omitempty"`
+
+	// The oldest kudet version that may operate on the repo, e.g. the one that introduced a key the config relies on;
+	// older kudet binaries refuse to load the config, rather than releasing without the behaviors it asks for
+	MinKudetVersion string `yaml:"min-kudet-version,omitempty"`
+
+	// The branch that releases are cut from
+	ReleaseBranch string `yaml:"release-branch,omitempty"`
+
+	// How long after origin was last fetched that releasing doesn't fetch it again, e.g. '10m' where fetches are rate
+	// limited; 0 fetches it on every release
+	FetchGracePeriod time.Duration `yaml:"fetch-grace-period,omitempty"`
+
+	// Path, relative to the repo root, of the changelog that gets validated and finalized on release
+	ChangelogFilepath string `yaml:"changelog-filepath,omitempty"`
+
+	// Path, relative to the repo root, of the skeleton written under the changelog's TBD header after each release, e.g.
+	// empty '### Features' and '### Fixes' subheaders showing contributors where entries go; empty leaves the header bare
+	ChangelogTbdSkeletonFilepath string `yaml:"changelog-tbd-skeleton-filepath,omitempty"`
+
+	// Paths, relative to the repo root, of other changelogs that are validated and finalized along with the main one, e.g.
+	// an internal changelog next to the user-facing one; breaking and major changes listed in any of them count. The
+	// release notes only come from the main changelog
+	AdditionalChangelogFilepaths []string `yaml:"additional-changelog-filepaths,omitempty"`
+
+	// The longest line, in bytes, that the changelogs are read with when they're finalized, which only streams their
+	// unreleased head rather than the whole of a multi-MB changelog; a longer line stops the release
+	ChangelogMaxLineLength int `yaml:"changelog-max-line-length,omitempty"`
+
+	// Subheaders that the changelog's TBD section must have entries under once the files they cover have changed since
+	// the previous release, e.g. 'API changes' whenever anything under 'api/' changed
+	RequiredChangelogSections []RequiredChangelogSectionConfig `yaml:"required-changelog-sections,omitempty"`
+
+	NonReleasableChanges NonReleasableChangesConfig `yaml:"non-releasable-changes,omitempty"`
+
+	// How long after the previous release's commit the next release is refused unless it's forced, so that a retried CI
+	// job doesn't cut a second release right after the first; 0 allows releasing at any time
+	MinReleaseInterval time.Duration `yaml:"min-release-interval,omitempty"`
+
+	// How long the release waits after pushing its tags before the post-release steps publish it any further, during
+	// which 'kudet cancel' can undo it; 0 publishes it straight away, and nothing can be cancelled
+	UndoWindow time.Duration `yaml:"undo-window,omitempty"`
+
+	// Path, relative to the repo root, of the file listing the scripts to run before the release commit
+	PreReleaseScriptsFilepath string `yaml:"pre-release-scripts-filepath,omitempty"`
+
+	// The command, and its arguments, that runs the inline commands in the pre-release scripts file; each inline command
+	// is passed to it as its last argument
+	PreReleaseScriptsShell []string `yaml:"pre-release-scripts-shell,omitempty"`
+
+	// Paths, relative to the repo root, that the pre-release scripts may write to; when set, the release stops as soon as
+	// a script modifies a file elsewhere in the worktree. Each is a glob, or a directory if it ends in '/'
+	PreReleaseScriptsWritablePaths []string `yaml:"pre-release-scripts-writable-paths,omitempty"`
+
+	// Regex matching changelog subheaders under the TBD header whose presence bumps the major version; empty disables
+	// major bumps from the changelog
+	MajorChangesSubheaderRegex string `yaml:"major-changes-subheader-regex,omitempty"`
+
+	// Changelog subheaders whose entries under the TBD header bump the version by at least their bump, on top of the
+	// breaking and major changes subheaders, e.g. 'Removed' bumping the major version
+	ChangelogCategories []ChangelogCategoryConfig `yaml:"changelog-categories,omitempty"`
+
+	// Who may override the computed bump with a 'kudet:bump=' trailer on a commit since the previous release
+	BumpOverride BumpOverrideConfig `yaml:"bump-override,omitempty"`
+
+	// Version lines released from the repo next to its main one, e.g. a CLI versioned independently of the Go module,
+	// each with its own tags, changelog and bump; they're released with 'kudet release --track'
+	VersionTracks []VersionTrackConfig `yaml:"version-tracks,omitempty"`
+
+	// Path, relative to the repo root, of the legal/marketing-approved copy of the release notes; when the file exists,
+	// the notes under the changelog's TBD header must match it
+	ApprovedReleaseNotesFilepath string `yaml:"approved-release-notes-filepath,omitempty"`
+
+	// Paths, relative to the repo root, that may have uncommitted changes when releasing, e.g. files regenerated by the
+	// pre-release scripts; they're committed along with the release. Each is a glob, or a directory if it ends in '/'
+	AllowedDirtyPaths []string `yaml:"allowed-dirty-paths,omitempty"`
+
+	// Appended to the release commit's message, e.g. '[skip ci]' or a 'skip-checks: true' trailer, so that pushing the
+	// release commit doesn't trigger a redundant CI run ahead of the one for the release tags; empty appends nothing
+	SkipCiMarker string `yaml:"skip-ci-marker,omitempty"`
+
+	// Go template of the release commit's message, which can refer to the fields of ReleaseCommitMessageData, e.g.
+	// 'REL-1 :bookmark: Release {{.Version}}'
+	ReleaseCommitMessageTemplate string `yaml:"release-commit-message-template,omitempty"`
+
+	// Go template of the annotated release tags' message, which can refer to the fields of TagMessageData, e.g.
+	// 'Release {{.Version}} by {{.Author}}: {{index .SectionEntryCounts "Fixes"}} fixes'; empty uses the tag's name
+	TagMessageTemplate string `yaml:"tag-message-template,omitempty"`
+
+	Notifications NotificationsConfig `yaml:"notifications,omitempty"`
+
+	Analytics AnalyticsConfig `yaml:"analytics,omitempty"`
+
+	AuditLog AuditLogConfig `yaml:"audit-log,omitempty"`
+
+	Telemetry TelemetryConfig `yaml:"telemetry,omitempty"`
+
+	Ci CiConfig `yaml:"ci,omitempty"`
+
+	TagParsing TagParsingConfig `yaml:"tag-parsing,omitempty"`
+
+	SecurityAdvisory SecurityAdvisoryConfig `yaml:"security-advisory,omitempty"`
+
+	Forge ForgeConfig `yaml:"forge,omitempty"`
+
+	GithubApp GithubAppConfig `yaml:"github-app,omitempty"`
+
+	Approval ApprovalConfig `yaml:"approval,omitempty"`
+
+	PullRequestComments PullRequestCommentsConfig `yaml:"pull-request-comments,omitempty"`
+
+	Milestones MilestonesConfig `yaml:"milestones,omitempty"`
+
+	Downstream DownstreamConfig `yaml:"downstream,omitempty"`
+
+	Ownership OwnershipConfig `yaml:"ownership,omitempty"`
+
+	// Files whose version string is bumped on each release, in place of hand-written pre-release scripts
+	VersionFiles []VersionFileConfig `yaml:"version-files,omitempty"`
+
+	// The packages of a monorepo, which are released along with the repo at its version in the order of their
+	// dependencies on each other
+	Packages []PackageConfig `yaml:"packages,omitempty"`
+
+	Bazel BazelConfig `yaml:"bazel,omitempty"`
+
+	VersionSource VersionSourceConfig `yaml:"version-source,omitempty"`
+
+	Environments EnvironmentsConfig `yaml:"environments,omitempty"`
+
+	HealthCheck HealthCheckConfig `yaml:"health-check,omitempty"`
+
+	Policy PolicyConfig `yaml:"policy,omitempty"`
+
+	TagSignatures TagSignaturesConfig `yaml:"tag-signatures,omitempty"`
+
+	ReleaseState ReleaseStateConfig `yaml:"release-state,omitempty"`
+
+	// Remotes that the release branch and tags are pushed to once origin has them, e.g. an internal GitLab mirror
+	Mirrors []MirrorConfig `yaml:"mirrors,omitempty"`
+
+	// The release steps that only log what they would do, each one of DryRunSteps, e.g. to rehearse releases in a staging
+	// fork with the real config by committing and tagging locally but not pushing or notifying anyone
+	DryRunSteps []string `yaml:"dry-run-steps,omitempty"`
+
+	// The secrets that each pre-release script gets in its environment, keyed by the script's path as it's listed in the
+	// pre-release scripts file; they're resolved when the release runs and given to no other script
+	PreReleaseScriptSecrets map[string][]SecretConfig `yaml:"pre-release-script-secrets,omitempty"`
+
+	// The files that each pre-release script produces for the release (e.g. SBOMs or build logs), keyed by the script's
+	// path as it's listed in the pre-release scripts file; each is a glob relative to the repo root, and the matching
+	// files are collected once the script succeeds
+	PreReleaseScriptArtifacts map[string][]string `yaml:"pre-release-script-artifacts,omitempty"`
+
+	ArtifactDiff ArtifactDiffConfig `yaml:"artifact-diff,omitempty"`
+
+	// When each pre-release script runs relative to the others, keyed by the script's path as it's listed in the
+	// pre-release scripts file
+	PreReleaseScriptSchedule map[string]PreReleaseScriptScheduleConfig `yaml:"pre-release-script-schedule,omitempty"`
+
+	// How many pre-release scripts may run at once; above 1, the scripts only wait for those they depend on rather than
+	// for every script listed before them
+	PreReleaseScriptsParallelism int `yaml:"pre-release-scripts-parallelism,omitempty"`
+}
+
+// PreReleaseScriptScheduleConfig is what a pre-release script waits for, and what it's skipped without
+type PreReleaseScriptScheduleConfig struct {
+	// The scripts that must have finished before this one starts, as listed in the pre-release scripts file before it
+	DependsOn []string `yaml:"depends-on,omitempty"`
+
+	// If set, the script is skipped unless a file matching one of these changed since the previous release, or is
+	// modified in the worktree by the time it would run; each is a glob relative to the repo root, or a directory if it
+	// ends in '/' or '/**'
+	OnlyIfChanged []string `yaml:"only-if-changed,omitempty"`
+}
+
+// SecretConfig is a secret that's resolved from an external store when the release runs, and handed to a script as an
+// environment variable without ever being logged
+type SecretConfig struct {
+	// The environment variable that the script gets the secret in, e.g. 'NPM_TOKEN'
+	Name string `yaml:"name"`
+
+	// Where the secret is read from; one of SecretSources
+	Source string `yaml:"source"`
+
+	// Which secret to read: an environment variable's name, a Vault 'path#key' (e.g. 'secret/data/npm#token'), or a command
+	// for the pre-release scripts shell whose output is the secret (e.g. 'gcloud secrets versions access latest --secret=npm')
+	Ref string `yaml:"ref"`
+}
+
+// PolicyConfig is the Rego policy that each release's plan is checked against before anything is changed, so that
+// release rules can be encoded without changing kudet; it's evaluated with the 'opa' CLI
+type PolicyConfig struct {
+	// The Rego files, or directories of them, that make up the policy; relative to the repo root unless they're absolute,
+	// e.g. for a policy shared across an org. No policy is checked if it's empty
+	Paths []string `yaml:"paths,omitempty"`
+
+	// The query whose result is the set of reasons to refuse the release, e.g. 'data.kudet.release.deny'
+	Query string `yaml:"query,omitempty"`
+
+	// WebAssembly (WASI) modules that are run sandboxed with the 'wasmtime' CLI as extra gates, for custom logic that
+	// doesn't fit Rego; relative to the repo root unless they're absolute
+	WasmGates []string `yaml:"wasm-gates,omitempty"`
+}
+
+// TagSignaturesConfig is who's trusted to sign release tags, so that a tampered release history in a shared repo can't
+// make kudet base the next version on a tag that nobody trusted cut
+type TagSignaturesConfig struct {
+	// The armored PGP public keys trusted to sign release tags; relative to the repo root unless it's absolute. If it's
+	// set, the latest release's tags must be signed by one of them before the next version is based on it
+	KeyringFilepath string `yaml:"keyring,omitempty"`
+}
+
+// ReleaseStateConfig is where the record of an in-progress release is kept, along with the lock that stops two machines
+// from releasing the repo at once when it's kept in a bucket
+type ReleaseStateConfig struct {
+	// One of 'local' (the default), 's3' or 'gcs'
+	Backend string `yaml:"backend,omitempty"`
+
+	// For the 's3' and 'gcs' backends, the bucket and prefix that the objects are kept under, e.g.
+	// 's3://ci-state/kudet/my-repo'; each repo needs its own
+	Url string `yaml:"url,omitempty"`
+}
+
+// RequiredChangelogSectionConfig is a changelog subheader that changes to some of the repo's files must be listed under
+type RequiredChangelogSectionConfig struct {
+	// The subheader's text, e.g. 'API changes' for '### API changes'; it's matched ignoring case
+	Subheader string `yaml:"subheader"`
+
+	// The files whose changes need entries under the subheader; each is a glob relative to the repo root, or a directory
+	// if it ends in '/' or '/**'
+	Paths []string `yaml:"paths"`
+
+	// If set, one of the commits since the previous release must have an 'Acked-by:' trailer naming one of these, e.g.
+	// the team owning the files, for the entries to count as acknowledged by them
+	AckedBy []string `yaml:"acked-by,omitempty"`
+}
+
+// ChangelogCategoryConfig is a changelog subheader whose entries decide how much the version is bumped by
+type ChangelogCategoryConfig struct {
+	// The subheader's text, e.g. 'Deprecations' for '### Deprecations'; it's matched ignoring case, at any level
+	Subheader string `yaml:"subheader"`
+
+	// The least that entries under the subheader bump the version by; one of 'major', 'minor' or 'patch'
+	Bump string `yaml:"bump"`
+
+	// Whether entries under the subheader warrant a release even when only non-releasable files changed, e.g. for
+	// 'Security'
+	ForceRelease bool `yaml:"force-release,omitempty"`
+}
+
+// BumpOverrideConfig is who may override the version bump computed from the changelog, for the rare releases where the
+// subheaders and categories get it wrong, with a 'kudet:bump=major|minor|patch' trailer on a commit
+type BumpOverrideConfig struct {
+	// The git author emails, or names, of the approvers; trailers on commits authored by anyone else are ignored, as are
+	// all trailers if there are none
+	Approvers []string `yaml:"approvers,omitempty"`
+}
+
+// VersionTrackConfig is a version line of its own within the repo, whose version is bumped from its own changelog and
+// tagged with its own prefix, independently of the main version line's
+type VersionTrackConfig struct {
+	// What the track is picked by when releasing; 'main' is taken by the main version line
+	Name string `yaml:"name"`
+
+	// What the track's tags are made of along with the version, e.g. 'cli/v' for 'cli/v1.2.3'
+	TagPrefix string `yaml:"tag-prefix,omitempty"`
+
+	// A template of the track's tags that's used instead of the tag prefix, for tags that don't end with the version,
+	// e.g. '{{.Scope}}/v{{.Version}}' or 'releases/{{.Version}}/{{.Scope}}'; it can refer to the fields of
+	// TagTemplateData, and must refer to the version once
+	TagTemplate string `yaml:"tag-template,omitempty"`
+
+	// Path, relative to the repo root, of the track's changelog, which needs a TBD header like the main one
+	ChangelogFilepath string `yaml:"changelog-filepath"`
+}
+
+// NonReleasableChangesConfig is which of the repo's files don't warrant a release on their own, e.g. vendored
+// dependencies, generated docs and CI config, so that housekeeping commits don't bump the version for nothing
+type NonReleasableChangesConfig struct {
+	// The files whose changes don't warrant a release; each is a glob relative to the repo root, or a directory if it ends
+	// in '/' or '/**'. The changelogs never count either way, since their entries describe changes rather than being ones
+	Paths []string `yaml:"paths,omitempty"`
+
+	// What happens when every file changed since the previous release is one of them: one of 'warn' (the default) or
+	// 'skip'
+	OnOnlyNonReleasable string `yaml:"on-only-non-releasable,omitempty"`
+}
+
+// ExtendsConfig is where the base config that a repo's config extends lives
+type ExtendsConfig struct {
+	// The git repo that the base config is in, e.g. 'https://github.com/acme/release-configs.git'
+	Url string `yaml:"url,omitempty"`
+
+	// The path of the base config in that repo; defaults to its '.kudet.yml'
+	Path string `yaml:"path,omitempty"`
+
+	// The tag, branch or full commit hash of that repo that the base config is taken from, e.g. 'v3'; the commit that it
+	// resolves to is locked until the lock is updated
+	Ref string `yaml:"ref,omitempty"`
+
+	// The environment variable holding the token that the repo is read with, if it needs one
+	TokenEnvVar string `yaml:"token-env-var,omitempty"`
+}
+
+// MirrorConfig is a remote that receives the same branch and tag pushes as origin
+type MirrorConfig struct {
+	// The URL to push to, e.g. 'https://gitlab.internal.example.com/tooling/kudet.git'
+	Url string `yaml:"url"`
+
+	// The environment variable holding the token that the mirror accepts pushes with; if empty, the release's token is
+	// used like for origin
+	TokenEnvVar string `yaml:"token-env-var,omitempty"`
+
+	// One of 'warn' (the default) or 'fail'
+	OnFailure string `yaml:"on-failure,omitempty"`
+}
+
+// PackageConfig is a package of a monorepo, whose manifest gets the release's version along with its dependencies on the
+// repo's other packages
+type PackageConfig struct {
+	// Path, relative to the repo root, of the package's manifest: a 'package.json' or a 'go.mod'
+	ManifestFilepath string `yaml:"manifest"`
+
+	// A command run with the pre-release scripts shell from the package's directory once the packages it depends on have
+	// been released, e.g. 'npm publish'; nothing is run if it's empty
+	ReleaseCommand string `yaml:"release-command,omitempty"`
+}
+
+// BazelConfig is how a repo that builds with Bazel, or a Bazel-like build system such as Please, gets the release's
+// version stamped into its build and its release artifacts built, in place of a pre-release script doing both
+type BazelConfig struct {
+	// Path, relative to the repo root, of the file rewritten with the release's version as workspace status lines, e.g.
+	// 'STABLE_RELEASE_VERSION 1.2.3', for the workspace status command to print; nothing is stamped if it's empty
+	StampFilepath string `yaml:"stamp-filepath,omitempty"`
+
+	// The target that builds the release artifacts, e.g. '//:release-artifacts'; nothing is built if it's empty
+	Target string `yaml:"target,omitempty"`
+
+	// The command, and its arguments, that the target is passed to as its last argument, e.g. 'plz run' for Please
+	Command []string `yaml:"command,omitempty"`
+
+	// The files that the target produces for the release; each is a glob relative to the repo root, and the matching
+	// files are collected along with the pre-release scripts' artifacts once the target succeeds
+	Artifacts []string `yaml:"artifacts,omitempty"`
+}
+
+// VersionSourceConfig is the service that allocates the release's version, for repos whose version numbers are
+// allocated centrally rather than computed from the changelog
+type VersionSourceConfig struct {
+	// The endpoint that's POSTed the repository and the kind of version bump that the changelog asks for, and responds
+	// with the version to release; the version is computed from the changelog if it's empty
+	Url string `yaml:"url,omitempty"`
+
+	// How the repo is known to the service, e.g. 'kurtosis-tech/kudet'; if empty, it's the repo's path on origin's forge
+	Repository string `yaml:"repository,omitempty"`
+
+	// The environment variable holding the token that the service is requested with as a bearer token, if it needs one
+	TokenEnvVar string `yaml:"token-env-var,omitempty"`
+}
+
+// HealthCheckConfig is the endpoint polled once a release is out and promoted, to verify that its rollout is healthy
+type HealthCheckConfig struct {
+	// The endpoint, e.g. a service's health check or a metrics query, that responds with a 2xx status while healthy; no
+	// rollout is verified if it's empty
+	Url string `yaml:"url,omitempty"`
+
+	// How long the endpoint must stay healthy for the rollout to be verified, e.g. '10m'
+	Duration time.Duration `yaml:"duration,omitempty"`
+
+	// How often the endpoint is polled, e.g. '30s'
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+// EnvironmentsConfig describes the GitOps repo whose per-environment manifests pin the deployed version of this repo
+type EnvironmentsConfig struct {
+	// Path, relative to the repo root, of a clone of the GitOps repo; required if any manifests are configured
+	GitopsRepoDirpath string `yaml:"gitops-repo-dirpath,omitempty"`
+
+	// The GitOps repo's branch that promotions are committed and pushed to
+	Branch string `yaml:"branch,omitempty"`
+
+	Manifests []EnvironmentManifestConfig `yaml:"manifests,omitempty"`
+}
+
+// EnvironmentManifestConfig is the manifest pinning the version deployed to one environment
+type EnvironmentManifestConfig struct {
+	// The environment's name, e.g. 'staging'
+	Name string `yaml:"name"`
+
+	// Path of the manifest relative to the GitOps repo root, e.g. 'environments/staging/versions.yml'
+	Filepath string `yaml:"filepath"`
+
+	// The manifest line pinning the version, as a regex where '%s' stands for the version, e.g. 'kudet: "%s"'
+	Pattern string `yaml:"pattern"`
+
+	// Whether each release is promoted to the environment as soon as it's pushed; other environments are only promoted
+	// to on request
+	AutoPromote bool `yaml:"auto-promote,omitempty"`
+}
+
+// VersionFileConfig is a file containing the repo's version on a single line
+type VersionFileConfig struct {
+	// Path relative to the repo root; files outside the repo (e.g. a Homebrew formula in a tap checked out alongside)
+	// are only updated once the release has been pushed, and are left for the operator to commit
+	Filepath string `yaml:"filepath"`
+
+	// The line containing the version, as a regex where '%s' stands for the version, e.g. 'const Version = "%s"'
+	Pattern string `yaml:"pattern"`
+
+	// If set, the pre-release scripts bump the file rather than kudet, which only checks that they did
+	BumpedByScripts bool `yaml:"bumped-by-scripts,omitempty"`
+}
+
+// OwnershipConfig names who owns the repo, to be mentioned in release notifications
+type OwnershipConfig struct {
+	// Team handles or users, e.g. '@kurtosis-tech/tooling'; if empty, the owners of the catch-all '*' rule of the
+	// repo's CODEOWNERS are used
+	Owners []string `yaml:"owners,omitempty"`
+}
+
+// DownstreamConfig points at the repos and services that consume this repo, which each release reports on so that
+// breaking releases don't surprise their owners
+type DownstreamConfig struct {
+	// Path, relative to the repo root, of the manifest listing the downstream consumers; releases only report on them
+	// when the file exists
+	ManifestFilepath string `yaml:"manifest-filepath,omitempty"`
+
+	// Whether each consumer's webhook, if it has one, is sent the release notification
+	NotifyOwners bool `yaml:"notify-owners,omitempty"`
+}
+
+// ForgeConfig describes the code host that the origin remote lives on, which kudet uses for CI checks, releases, and
+// advisories; by default it's detected from the remote URL
+type ForgeConfig struct {
+	// Either 'github' or 'gitlab'; needed for self-hosted forges whose remote URL doesn't give them away
+	Type string `yaml:"type,omitempty"`
+
+	// The base URL of the forge's REST API, e.g. 'https://gitlab.example.com/api/v4'; if empty, it's derived from the
+	// remote URL's host
+	ApiUrl string `yaml:"api-url,omitempty"`
+}
+
+// GithubAppConfig has kudet authenticate as an installation of a GitHub App, minting short-lived installation tokens in
+// place of the token it's given, so that release workflows don't need long-lived personal access tokens
+type GithubAppConfig struct {
+	// The App's ID, from its settings page; no App is used if it's 0
+	AppId int64 `yaml:"app-id,omitempty"`
+
+	// The ID of the App's installation on the repo's owner; if 0, it's looked up from the repo
+	InstallationId int64 `yaml:"installation-id,omitempty"`
+
+	// The environment variable holding the App's PEM-encoded private key
+	PrivateKeyEnvVar string `yaml:"private-key-env-var,omitempty"`
+}
+
+// ApprovalConfig has each release wait for a human sign-off on a forge issue before anything is changed, so that repos
+// needing one can still release from CI without anyone at a keyboard
+type ApprovalConfig struct {
+	// The forge usernames of who may approve or deny releases, by commenting '/approve' or '/deny' on the approval issue
+	// that each release opens; no approval is waited for if it's empty
+	Approvers []string `yaml:"approvers,omitempty"`
+
+	// How long the release waits for one of the approvers to decide before it gives up, e.g. '4h'
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// PullRequestCommentsConfig has each release comment on the merged pull requests it includes, so that whoever watches
+// them learns which version their change went out in
+type PullRequestCommentsConfig struct {
+	// Whether the pull requests merged since the previous release are each commented on with the version, once the
+	// release is pushed
+	Enabled bool `yaml:"enabled,omitempty"`
+}
+
+// MilestonesConfig has each release close the forge milestone of its version and carry whatever is still open on it
+// over to the next version's milestone, as release managers otherwise do by hand
+type MilestonesConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// The bump from the released version that the next milestone is for, one of 'major', 'minor' or 'patch'
+	NextBump string `yaml:"next-bump,omitempty"`
+}
+
+// SecurityAdvisoryConfig describes the repo's package for the GitHub Security Advisories drafted for security releases
+type SecurityAdvisoryConfig struct {
+	// The GitHub advisory ecosystem of the package (e.g. 'go' or 'npm'); if empty, advisories list no affected package
+	Ecosystem string `yaml:"ecosystem,omitempty"`
+
+	// The package name within the ecosystem, e.g. the Go module path
+	Package string `yaml:"package,omitempty"`
+
+	// One of 'low', 'medium', 'high', or 'critical', used when a security release doesn't specify one
+	Severity string `yaml:"severity,omitempty"`
+}
+
+// TagParsingConfig decides which version tags count as previous releases when determining the next version; by
+// default, only plain 'X.Y.Z' tags do
+type TagParsingConfig struct {
+	// Whether 'vX.Y.Z' tags count, for repos whose history was only tagged with the 'v' prefix
+	AllowVPrefix bool `yaml:"allow-v-prefix,omitempty"`
+
+	// Whether tags with prerelease versions or build metadata (e.g. '1.2.3-rc.1' or '1.2.3+build.5') count
+	AllowPrereleasesAndMetadata bool `yaml:"allow-prereleases-and-metadata,omitempty"`
+}
+
+// CiConfig describes the repo's CI, which releases can be required to be green on
+type CiConfig struct {
+	// Names of the GitHub check runs or commit status contexts that must have passed on the commit being released; if
+	// empty, every check reported on the commit must have passed
+	RequiredChecks []string `yaml:"required-checks,omitempty"`
+}
+
+// NotificationsConfig configures who gets told about releases once they've been cut
+type NotificationsConfig struct {
+	// URLs that receive an HTTP POST with a JSON description of each successful release
+	WebhookUrls []string `yaml:"webhook-urls,omitempty"`
+}
+
+// AnalyticsConfig opts the repo into reporting anonymized kudet usage, so the tooling team can see which failures are
+// hit most in practice; nothing is reported unless it's explicitly enabled
+type AnalyticsConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// URL that receives an HTTP POST with a JSON usage event after each command
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+// AuditLogConfig is the backend that keeps the audit record of each release, e.g. a SIEM's HTTP collector or a service
+// in front of a database
+type AuditLogConfig struct {
+	// URL that receives an HTTP POST with the JSON audit record of each successful release; nothing is recorded if it's
+	// empty
+	Url string `yaml:"url,omitempty"`
+
+	// The environment variable holding the token that the backend accepts records with as a bearer token, if it needs one
+	TokenEnvVar string `yaml:"token-env-var,omitempty"`
+}
+
+// ArtifactDiffConfig compares the artifacts collected for each release with the assets of the previous release's forge
+// release, flagging anomalies such as an artifact that's missing or that changed size drastically
+type ArtifactDiffConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// How many times bigger or smaller than its previous release's an artifact can get before it's flagged
+	MaxSizeRatio float64 `yaml:"max-size-ratio,omitempty"`
+
+	// If true, anomalies fail the release before anything is committed, unless it's forced; otherwise they're warnings
+	FailOnAnomaly bool `yaml:"fail-on-anomaly,omitempty"`
+}
+
+// TelemetryConfig exports a trace of each release, with a span for each of its steps, and metrics of its outcome and
+// duration to an OpenTelemetry collector, so release durations and failures can be tracked across repos
+type TelemetryConfig struct {
+	// Base URL of the collector's OTLP/HTTP receiver, e.g. 'http://localhost:4318'; nothing is exported if it's empty
+	OtlpEndpoint string `yaml:"otlp-endpoint,omitempty"`
+
+	// The environment variable holding headers to export with, e.g. for authentication, in the 'key1=value1,key2=value2'
+	// format of OTEL_EXPORTER_OTLP_HEADERS
+	HeadersEnvVar string `yaml:"headers-env-var,omitempty"`
+}
+
+func NewDefaultKudetConfig() *KudetConfig {
+	return &KudetConfig{
+		ReleaseBranch:                defaultReleaseBranch,
+		FetchGracePeriod:             defaultFetchGracePeriod,
+		ChangelogFilepath:            defaultChangelogRelFilepath,
+		ChangelogMaxLineLength:       changelog.DefaultMaxLineLength,
+		PreReleaseScriptsFilepath:    defaultPreReleaseScriptsRelFilepath,
+		PreReleaseScriptsShell:       []string{defaultPreReleaseScriptsShellCmd, defaultPreReleaseScriptsShellCmdFlag},
+		PreReleaseScriptsParallelism: defaultPreReleaseScriptsParallelism,
+		MajorChangesSubheaderRegex:   defaultMajorChangesSubheaderRegex,
+		ApprovedReleaseNotesFilepath: defaultApprovedReleaseNotesRelFilepath,
+		ReleaseCommitMessageTemplate: defaultReleaseCommitMessageTemplate,
+		Downstream: DownstreamConfig{
+			ManifestFilepath: defaultDownstreamManifestRelFilepath,
+		},
+		Bazel: BazelConfig{
+			Command: []string{defaultBazelCmd, defaultBazelRunSubcmd, defaultBazelStampFlag},
+		},
+		ArtifactDiff: ArtifactDiffConfig{
+			MaxSizeRatio: defaultArtifactDiffMaxSizeRatio,
+		},
+		Environments: EnvironmentsConfig{
+			Branch: defaultGitopsBranch,
+		},
+		HealthCheck: HealthCheckConfig{
+			Duration: defaultHealthCheckDuration,
+			Interval: defaultHealthCheckInterval,
+		},
+		Policy: PolicyConfig{
+			Query: defaultPolicyQuery,
+		},
+		Approval: ApprovalConfig{
+			Timeout: defaultApprovalTimeout,
+		},
+		Milestones: MilestonesConfig{
+			NextBump: MinorBump,
+		},
+	}
+}
+
+// ExecuteReleaseCommitMessageTemplate renders the release commit message from the template
+func ExecuteReleaseCommitMessageTemplate(messageTemplate string, data ReleaseCommitMessageData) (string, error) {
+	parsedTemplate, err := template.New(ReleaseCommitMessageTemplateKey).Parse(messageTemplate)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred parsing the release commit message template")
+	}
+	message := &strings.Builder{}
+	if err := parsedTemplate.Execute(message, data); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred rendering the release commit message template")
+	}
+	return message.String(), nil
+}
+
+// ExecuteTagMessageTemplate renders a release tag's message from the template
+func ExecuteTagMessageTemplate(messageTemplate string, data TagMessageData) (string, error) {
+	parsedTemplate, err := template.New(TagMessageTemplateKey).Parse(messageTemplate)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred parsing the tag message template")
+	}
+	message := &strings.Builder{}
+	if err := parsedTemplate.Execute(message, data); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred rendering the tag message template")
+	}
+	return message.String(), nil
+}
+
+// GetTagPrefixAndSuffix gets what comes before and after the version in the track's tags, from its tag prefix or its
+// tag template
+func (trackConfig VersionTrackConfig) GetTagPrefixAndSuffix() (string, string, error) {
+	if trackConfig.TagTemplate == "" {
+		return trackConfig.TagPrefix, "", nil
+	}
+	parsedTemplate, err := template.New(VersionTrackTagTemplateKey).Parse(trackConfig.TagTemplate)
+	if err != nil {
+		return "", "", stacktrace.Propagate(err, "An error occurred parsing the tag template")
+	}
+	tag := &strings.Builder{}
+	if err := parsedTemplate.Execute(tag, TagTemplateData{Scope: trackConfig.Name, Version: tagTemplateVersionPlaceholder}); err != nil {
+		return "", "", stacktrace.Propagate(err, "An error occurred rendering the tag template")
+	}
+	tagParts := strings.Split(tag.String(), tagTemplateVersionPlaceholder)
+	if len(tagParts) != 2 {
+		return "", "", stacktrace.NewError("The tag template must refer to '.Version' exactly once, so that the version can be parsed back out of the tags")
+	}
+	return tagParts[0], tagParts[1], nil
+}
+
+// LoadKudetConfig reads the .kudet.yml at the root of the given repo, returning the default config if there isn't one;
+// if it extends a base config, it's merged into the repo's lock of the base
+func LoadKudetConfig(repoDirpath string) (*KudetConfig, error) {
+	configFilepath := filepath.Join(repoDirpath, KudetConfigFilename)
+	configBytes, err := os.ReadFile(configFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewDefaultKudetConfig(), nil
+		}
+		return nil, stacktrace.Propagate(err, "An error occurred reading the kudet config file at '%s'", configFilepath)
+	}
+	config, err := ParseKudetConfig(configBytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the kudet config file at '%s'", configFilepath)
+	}
+	if config.Extends == (ExtendsConfig{}) {
+		return config, nil
+	}
+	baseConfigLock, found, err := loadBaseConfigLock(repoDirpath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred loading the lock of the base config that the kudet config extends")
+	}
+	if !found {
+		return nil, stacktrace.NewError("The kudet config extends '%s' at '%s' of '%s', which hasn't been locked yet; run 'kudet config update-base'", config.Extends.GetPath(), config.Extends.Ref, config.Extends.Url)
+	}
+	extendedConfig, err := baseConfigLock.ExtendKudetConfig(configBytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred extending the base config with the kudet config file at '%s'", configFilepath)
+	}
+	return extendedConfig, nil
+}
+
+func ParseKudetConfig(configBytes []byte) (*KudetConfig, error) {
+	// Checked before the strict decoding, so that a config using keys that this kudet doesn't know yet gets the upgrade
+	// hint rather than an unknown key error
+	if err := checkMinKudetVersion(configBytes, kudet_version.KudetVersion); err != nil {
+		return nil, stacktrace.Propagate(err, "This kudet is too old for the kudet config")
+	}
+	config := NewDefaultKudetConfig()
+	decoder := yaml.NewDecoder(bytes.NewReader(configBytes))
+	// Unknown keys are almost always typos, which would otherwise silently fall back to default behaviour
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && err != io.EOF {
+		return nil, stacktrace.Propagate(err, "An error occurred decoding the kudet config YAML")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, stacktrace.Propagate(err, "The kudet config is invalid")
+	}
+	return config, nil
+}
+
+func (config *KudetConfig) Validate() error {
+	if err := config.Extends.validate(); err != nil {
+		return stacktrace.Propagate(err, "The config of the base config it extends is invalid")
+	}
+	if config.MinKudetVersion != "" {
+		if _, err := semver.NewVersion(config.MinKudetVersion); err != nil {
+			return stacktrace.Propagate(err, "The min kudet version '%s' isn't a semantic version", config.MinKudetVersion)
+		}
+	}
+	if strings.TrimSpace(config.ReleaseBranch) == "" {
+		return stacktrace.NewError("The release branch can't be empty")
+	}
+	if config.MinReleaseInterval < 0 {
+		return stacktrace.NewError("The min release interval can't be negative, but it's '%v'", config.MinReleaseInterval)
+	}
+	if config.UndoWindow < 0 {
+		return stacktrace.NewError("The undo window can't be negative, but it's '%v'", config.UndoWindow)
+	}
+	if config.FetchGracePeriod < 0 {
+		return stacktrace.NewError("The fetch grace period can't be negative, but it's '%v'", config.FetchGracePeriod)
+	}
+	if strings.TrimSpace(config.ChangelogFilepath) == "" {
+		return stacktrace.NewError("The changelog filepath can't be empty")
+	}
+	if config.ChangelogMaxLineLength < 1 {
+		return stacktrace.NewError("The changelog max line length must be at least 1 byte, but it's '%d'", config.ChangelogMaxLineLength)
+	}
+	for _, additionalChangelogFilepath := range config.AdditionalChangelogFilepaths {
+		if strings.TrimSpace(additionalChangelogFilepath) == "" {
+			return stacktrace.NewError("Additional changelog filepaths can't be empty")
+		}
+		if path.Clean(additionalChangelogFilepath) == path.Clean(config.ChangelogFilepath) {
+			return stacktrace.NewError("The main changelog '%s' can't also be an additional changelog", config.ChangelogFilepath)
+		}
+	}
+	for _, sectionConfig := range config.RequiredChangelogSections {
+		if err := sectionConfig.validate(); err != nil {
+			return stacktrace.Propagate(err, "The config of required changelog section '%s' is invalid", sectionConfig.Subheader)
+		}
+	}
+	seenCategorySubheaders := map[string]bool{}
+	for _, categoryConfig := range config.ChangelogCategories {
+		if err := categoryConfig.validate(); err != nil {
+			return stacktrace.Propagate(err, "The config of changelog category '%s' is invalid", categoryConfig.Subheader)
+		}
+		if seenCategorySubheaders[strings.ToLower(categoryConfig.Subheader)] {
+			return stacktrace.NewError("Changelog category '%s' is configured more than once", categoryConfig.Subheader)
+		}
+		seenCategorySubheaders[strings.ToLower(categoryConfig.Subheader)] = true
+	}
+	if err := config.BumpOverride.validate(); err != nil {
+		return stacktrace.Propagate(err, "The config of the bump override is invalid")
+	}
+	if err := config.validateVersionTracks(); err != nil {
+		return stacktrace.Propagate(err, "The config of the version tracks is invalid")
+	}
+	if err := config.NonReleasableChanges.validate(); err != nil {
+		return stacktrace.Propagate(err, "The config of the non-releasable changes is invalid")
+	}
+	if strings.TrimSpace(config.PreReleaseScriptsFilepath) == "" {
+		return stacktrace.NewError("The pre-release scripts filepath can't be empty")
+	}
+	for _, dryRunStep := range config.DryRunSteps {
+		if !isOneOf(dryRunStep, DryRunSteps) {
+			return stacktrace.NewError("Dry-run step '%s' must be one of '%s'", dryRunStep, DryRunSteps)
+		}
+	}
+	if len(config.PreReleaseScriptsShell) == 0 || strings.TrimSpace(config.PreReleaseScriptsShell[0]) == "" {
+		return stacktrace.NewError("The pre-release scripts shell needs a command to run inline commands with")
+	}
+	if config.PreReleaseScriptsParallelism < 1 {
+		return stacktrace.NewError("The pre-release scripts parallelism must be at least 1, but it's '%d'", config.PreReleaseScriptsParallelism)
+	}
+	if strings.TrimSpace(config.ApprovedReleaseNotesFilepath) == "" {
+		return stacktrace.NewError("The approved release notes filepath can't be empty")
+	}
+	if strings.TrimSpace(config.Downstream.ManifestFilepath) == "" {
+		return stacktrace.NewError("The downstream manifest filepath can't be empty")
+	}
+	if strings.TrimSpace(config.ReleaseCommitMessageTemplate) == "" {
+		return stacktrace.NewError("The release commit message template can't be empty")
+	}
+	if _, err := ExecuteReleaseCommitMessageTemplate(config.ReleaseCommitMessageTemplate, ReleaseCommitMessageData{}); err != nil {
+		return stacktrace.Propagate(err, "Release commit message template '%s' is invalid", config.ReleaseCommitMessageTemplate)
+	}
+	if config.TagMessageTemplate != "" {
+		if _, err := ExecuteTagMessageTemplate(config.TagMessageTemplate, TagMessageData{}); err != nil {
+			return stacktrace.Propagate(err, "Tag message template '%s' is invalid", config.TagMessageTemplate)
+		}
+	}
+	if _, err := regexp.Compile(config.MajorChangesSubheaderRegex); err != nil {
+		return stacktrace.Propagate(err, "Major changes subheader regex '%s' is invalid", config.MajorChangesSubheaderRegex)
+	}
+	for _, webhookUrl := range config.Notifications.WebhookUrls {
+		if err := validateHttpUrl(webhookUrl); err != nil {
+			return stacktrace.Propagate(err, "Notification webhook URL '%s' is invalid", webhookUrl)
+		}
+	}
+	for _, requiredCheck := range config.Ci.RequiredChecks {
+		if strings.TrimSpace(requiredCheck) == "" {
+			return stacktrace.NewError("Required CI check names can't be empty")
+		}
+	}
+	for _, writablePath := range config.PreReleaseScriptsWritablePaths {
+		if strings.TrimSpace(writablePath) == "" {
+			return stacktrace.NewError("Pre-release scripts' writable paths can't be empty")
+		}
+		if _, err := path.Match(writablePath, ""); err != nil {
+			return stacktrace.Propagate(err, "Pre-release scripts' writable path '%s' is an invalid glob", writablePath)
+		}
+	}
+	for _, allowedDirtyPath := range config.AllowedDirtyPaths {
+		if strings.TrimSpace(allowedDirtyPath) == "" {
+			return stacktrace.NewError("Allowed dirty paths can't be empty")
+		}
+		if _, err := path.Match(allowedDirtyPath, ""); err != nil {
+			return stacktrace.Propagate(err, "Allowed dirty path '%s' is an invalid glob", allowedDirtyPath)
+		}
+	}
+	for _, owner := range config.Ownership.Owners {
+		if strings.TrimSpace(owner) == "" {
+			return stacktrace.NewError("Repo owners can't be empty")
+		}
+	}
+	for _, versionFile := range config.VersionFiles {
+		if strings.TrimSpace(versionFile.Filepath) == "" {
+			return stacktrace.NewError("Version file paths can't be empty")
+		}
+		if err := version_file_updater.ValidatePatternFormatStr(versionFile.Pattern); err != nil {
+			return stacktrace.Propagate(err, "The version pattern of file '%s' is invalid", versionFile.Filepath)
+		}
+		isOutsideRepo := filepath.IsAbs(versionFile.Filepath) || strings.HasPrefix(path.Clean(filepath.ToSlash(versionFile.Filepath)), "../")
+		if versionFile.BumpedByScripts && isOutsideRepo {
+			return stacktrace.NewError("Version file '%s' is outside the repo, where the pre-release scripts' changes aren't released, so it can't be bumped by them", versionFile.Filepath)
+		}
+	}
+	if config.HealthCheck.Url != "" {
+		if err := validateHttpUrl(config.HealthCheck.Url); err != nil {
+			return stacktrace.Propagate(err, "Health check URL '%s' is invalid", config.HealthCheck.Url)
+		}
+	}
+	if config.HealthCheck.Interval <= 0 || config.HealthCheck.Duration < config.HealthCheck.Interval {
+		return stacktrace.NewError("The health check interval must be positive and no longer than its duration, but they're '%v' and '%v'", config.HealthCheck.Interval, config.HealthCheck.Duration)
+	}
+	for scriptRelFilepath, secrets := range config.PreReleaseScriptSecrets {
+		for _, secret := range secrets {
+			if err := secret.validate(); err != nil {
+				return stacktrace.Propagate(err, "A secret of pre-release script '%s' is invalid", scriptRelFilepath)
+			}
+		}
+	}
+	for scriptRelFilepath, artifactGlobs := range config.PreReleaseScriptArtifacts {
+		for _, artifactGlob := range artifactGlobs {
+			if strings.TrimSpace(artifactGlob) == "" {
+				return stacktrace.NewError("The artifacts of pre-release script '%s' can't be empty", scriptRelFilepath)
+			}
+			if _, err := path.Match(artifactGlob, ""); err != nil {
+				return stacktrace.Propagate(err, "Artifact '%s' of pre-release script '%s' is an invalid glob", artifactGlob, scriptRelFilepath)
+			}
+		}
+	}
+	if config.ArtifactDiff.MaxSizeRatio <= 1 {
+		return stacktrace.NewError("The artifact diff's max size ratio must be greater than 1, but it's '%v'", config.ArtifactDiff.MaxSizeRatio)
+	}
+	for scriptRelFilepath, scheduleConfig := range config.PreReleaseScriptSchedule {
+		if err := scheduleConfig.validate(); err != nil {
+			return stacktrace.Propagate(err, "The schedule of pre-release script '%s' is invalid", scriptRelFilepath)
+		}
+	}
+	manifestFilepaths := map[string]bool{}
+	for _, packageConfig := range config.Packages {
+		if err := packageConfig.validate(); err != nil {
+			return stacktrace.Propagate(err, "The config of package '%s' is invalid", packageConfig.ManifestFilepath)
+		}
+		if manifestFilepaths[path.Clean(packageConfig.ManifestFilepath)] {
+			return stacktrace.NewError("Package '%s' is configured more than once", packageConfig.ManifestFilepath)
+		}
+		manifestFilepaths[path.Clean(packageConfig.ManifestFilepath)] = true
+	}
+	if err := config.Bazel.validate(); err != nil {
+		return stacktrace.Propagate(err, "The Bazel config is invalid")
+	}
+	if err := config.VersionSource.validate(); err != nil {
+		return stacktrace.Propagate(err, "The version source config is invalid")
+	}
+	if err := config.Environments.validate(); err != nil {
+		return stacktrace.Propagate(err, "The environments config is invalid")
+	}
+	if err := config.Policy.validate(); err != nil {
+		return stacktrace.Propagate(err, "The policy config is invalid")
+	}
+	for _, mirrorConfig := range config.Mirrors {
+		if err := mirrorConfig.validate(); err != nil {
+			return stacktrace.Propagate(err, "The config of mirror '%s' is invalid", mirrorConfig.Url)
+		}
+	}
+	if err := config.ReleaseState.validate(); err != nil {
+		return stacktrace.Propagate(err, "The release state config is invalid")
+	}
+	advisoryConfig := config.SecurityAdvisory
+	if advisoryConfig.Ecosystem != "" && !isOneOf(advisoryConfig.Ecosystem, SecurityAdvisoryEcosystems) {
+		return stacktrace.NewError("Security advisory ecosystem '%s' must be one of '%s'", advisoryConfig.Ecosystem, SecurityAdvisoryEcosystems)
+	}
+	if (advisoryConfig.Ecosystem == "") != (strings.TrimSpace(advisoryConfig.Package) == "") {
+		return stacktrace.NewError("The security advisory ecosystem and package must be set together")
+	}
+	if advisoryConfig.Severity != "" && !isOneOf(advisoryConfig.Severity, SecurityAdvisorySeverities) {
+		return stacktrace.NewError("Security advisory severity '%s' must be one of '%s'", advisoryConfig.Severity, SecurityAdvisorySeverities)
+	}
+	if config.Forge.Type != "" && !isOneOf(config.Forge.Type, ForgeTypes) {
+		return stacktrace.NewError("Forge type '%s' must be one of '%s'", config.Forge.Type, ForgeTypes)
+	}
+	if config.Forge.ApiUrl != "" {
+		if err := validateHttpUrl(config.Forge.ApiUrl); err != nil {
+			return stacktrace.Propagate(err, "Forge API URL '%s' is invalid", config.Forge.ApiUrl)
+		}
+	}
+	if config.Analytics.Enabled {
+		if strings.TrimSpace(config.Analytics.Endpoint) == "" {
+			return stacktrace.NewError("An analytics endpoint is required when analytics are enabled")
+		}
+		if err := validateHttpUrl(config.Analytics.Endpoint); err != nil {
+			return stacktrace.Propagate(err, "Analytics endpoint '%s' is invalid", config.Analytics.Endpoint)
+		}
+	}
+	if config.AuditLog.Url != "" {
+		if err := validateHttpUrl(config.AuditLog.Url); err != nil {
+			return stacktrace.Propagate(err, "Audit log URL '%s' is invalid", config.AuditLog.Url)
+		}
+	}
+	if config.AuditLog.TokenEnvVar != "" && !envVarNameRegex.MatchString(config.AuditLog.TokenEnvVar) {
+		return stacktrace.NewError("Token environment variable '%s' must be a valid environment variable name", config.AuditLog.TokenEnvVar)
+	}
+	if config.Telemetry.OtlpEndpoint != "" {
+		if err := validateHttpUrl(config.Telemetry.OtlpEndpoint); err != nil {
+			return stacktrace.Propagate(err, "Telemetry OTLP endpoint '%s' is invalid", config.Telemetry.OtlpEndpoint)
+		}
+	}
+	if config.Telemetry.HeadersEnvVar != "" && !envVarNameRegex.MatchString(config.Telemetry.HeadersEnvVar) {
+		return stacktrace.NewError("Headers environment variable '%s' must be a valid environment variable name", config.Telemetry.HeadersEnvVar)
+	}
+	if err := config.GithubApp.validate(); err != nil {
+		return stacktrace.Propagate(err, "The GitHub App config is invalid")
+	}
+	if err := config.Approval.validate(); err != nil {
+		return stacktrace.Propagate(err, "The approval config is invalid")
+	}
+	if !isOneOf(config.Milestones.NextBump, Bumps) {
+		return stacktrace.NewError("Milestones' next bump '%s' must be one of '%s'", config.Milestones.NextBump, Bumps)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// checkMinKudetVersion refuses the config if it asks for a newer kudet than the given one; development builds aren't
+// versioned, so they're assumed to be new enough
+func checkMinKudetVersion(configBytes []byte, kudetVersion string) error {
+	versionConfig := &struct {
+		MinKudetVersion string `yaml:"min-kudet-version"`
+	}{}
+	// Malformed YAML is reported by the strict decoding that follows
+	if err := yaml.Unmarshal(configBytes, versionConfig); err != nil || versionConfig.MinKudetVersion == "" {
+		return nil
+	}
+	minVersion, err := semver.NewVersion(versionConfig.MinKudetVersion)
+	if err != nil {
+		return stacktrace.Propagate(err, "The kudet config's '%s' of '%s' isn't a semantic version", MinKudetVersionKey, versionConfig.MinKudetVersion)
+	}
+	if kudetVersion == kudet_version.DevelopmentVersion {
+		return nil
+	}
+	currentVersion, err := semver.NewVersion(kudetVersion)
+	if err != nil {
+		return stacktrace.Propagate(err, "This kudet's version '%s' isn't a semantic version, so it can't be checked against the kudet config's '%s'", kudetVersion, MinKudetVersionKey)
+	}
+	if currentVersion.LessThan(minVersion) {
+		return stacktrace.NewError("The kudet config requires kudet '%s' or newer, but this is kudet '%s'; upgrade it, e.g. with 'kudet self-update', and run it again", minVersion.Original(), kudetVersion)
+	}
+	return nil
+}
+
+func (appConfig GithubAppConfig) validate() error {
+	if appConfig == (GithubAppConfig{}) {
+		return nil
+	}
+	if appConfig.AppId <= 0 {
+		return stacktrace.NewError("The App ID must be set to a positive number, but it's '%d'", appConfig.AppId)
+	}
+	if appConfig.InstallationId < 0 {
+		return stacktrace.NewError("The installation ID can't be negative, but it's '%d'", appConfig.InstallationId)
+	}
+	if !envVarNameRegex.MatchString(appConfig.PrivateKeyEnvVar) {
+		return stacktrace.NewError("Private key environment variable '%s' must be a valid environment variable name", appConfig.PrivateKeyEnvVar)
+	}
+	return nil
+}
+
+func (approvalConfig ApprovalConfig) validate() error {
+	for _, approver := range approvalConfig.Approvers {
+		if strings.TrimSpace(approver) == "" {
+			return stacktrace.NewError("Approvers can't be empty")
+		}
+	}
+	if approvalConfig.Timeout <= 0 {
+		return stacktrace.NewError("The approval timeout must be positive, but it's '%v'", approvalConfig.Timeout)
+	}
+	return nil
+}
+
+func (environmentsConfig EnvironmentsConfig) validate() error {
+	if len(environmentsConfig.Manifests) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(environmentsConfig.GitopsRepoDirpath) == "" {
+		return stacktrace.NewError("A GitOps repo is required when environment manifests are configured")
+	}
+	if strings.TrimSpace(environmentsConfig.Branch) == "" {
+		return stacktrace.NewError("The GitOps branch can't be empty")
+	}
+	seenNames := map[string]bool{}
+	for _, manifest := range environmentsConfig.Manifests {
+		if strings.TrimSpace(manifest.Name) == "" {
+			return stacktrace.NewError("Environment names can't be empty")
+		}
+		if seenNames[manifest.Name] {
+			return stacktrace.NewError("Environment '%s' is configured more than once", manifest.Name)
+		}
+		seenNames[manifest.Name] = true
+		if strings.TrimSpace(manifest.Filepath) == "" {
+			return stacktrace.NewError("The manifest path of environment '%s' can't be empty", manifest.Name)
+		}
+		if err := version_file_updater.ValidatePatternFormatStr(manifest.Pattern); err != nil {
+			return stacktrace.Propagate(err, "The version pattern of environment '%s' is invalid", manifest.Name)
+		}
+	}
+	return nil
+}
+
+func validateHttpUrl(urlStr string) error {
+	parsedUrl, err := url.Parse(urlStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "'%s' isn't a valid URL", urlStr)
+	}
+	if parsedUrl.Scheme != httpScheme && parsedUrl.Scheme != httpsScheme {
+		return stacktrace.NewError("URL '%s' must use the '%s' or '%s' scheme", urlStr, httpScheme, httpsScheme)
+	}
+	return nil
+}
+
+func (secretConfig SecretConfig) validate() error {
+	if !envVarNameRegex.MatchString(secretConfig.Name) {
+		return stacktrace.NewError("Secret name '%s' must be a valid environment variable name", secretConfig.Name)
+	}
+	if !isOneOf(secretConfig.Source, SecretSources) {
+		return stacktrace.NewError("The source '%s' of secret '%s' must be one of '%s'", secretConfig.Source, secretConfig.Name, SecretSources)
+	}
+	if strings.TrimSpace(secretConfig.Ref) == "" {
+		return stacktrace.NewError("Secret '%s' needs a ref saying which secret to read", secretConfig.Name)
+	}
+	if secretConfig.Source == VaultSecretSource && !strings.Contains(secretConfig.Ref, VaultSecretRefKeySeparator) {
+		return stacktrace.NewError("The Vault ref '%s' of secret '%s' must be the secret's path and the key of its value, separated by '%s'", secretConfig.Ref, secretConfig.Name, VaultSecretRefKeySeparator)
+	}
+	return nil
+}
+
+func (scheduleConfig PreReleaseScriptScheduleConfig) validate() error {
+	for _, dependencyRelFilepath := range scheduleConfig.DependsOn {
+		if strings.TrimSpace(dependencyRelFilepath) == "" {
+			return stacktrace.NewError("Dependencies can't be empty")
+		}
+	}
+	for _, changedPath := range scheduleConfig.OnlyIfChanged {
+		if strings.TrimSpace(changedPath) == "" {
+			return stacktrace.NewError("The paths whose changes the script runs on can't be empty")
+		}
+		if _, err := path.Match(changedPath, ""); err != nil {
+			return stacktrace.Propagate(err, "The path '%s' whose changes the script runs on is an invalid glob", changedPath)
+		}
+	}
+	return nil
+}
+
+func (policyConfig PolicyConfig) validate() error {
+	for _, policyPath := range policyConfig.Paths {
+		if strings.TrimSpace(policyPath) == "" {
+			return stacktrace.NewError("Policy paths can't be empty")
+		}
+	}
+	if len(policyConfig.Paths) > 0 && strings.TrimSpace(policyConfig.Query) == "" {
+		return stacktrace.NewError("The policy query can't be empty")
+	}
+	for _, wasmGatePath := range policyConfig.WasmGates {
+		if strings.TrimSpace(wasmGatePath) == "" {
+			return stacktrace.NewError("WASM gate paths can't be empty")
+		}
+	}
+	return nil
+}
+
+func (releaseStateConfig ReleaseStateConfig) validate() error {
+	switch releaseStateConfig.Backend {
+	case "", LocalReleaseStateBackend:
+		if releaseStateConfig.Url != "" {
+			return stacktrace.NewError("A URL is only used by the '%s' and '%s' backends", S3ReleaseStateBackend, GcsReleaseStateBackend)
+		}
+	case S3ReleaseStateBackend, GcsReleaseStateBackend:
+		urlScheme := releaseStateConfig.Backend
+		if releaseStateConfig.Backend == GcsReleaseStateBackend {
+			urlScheme = gcsUrlScheme
+		}
+		parsedUrl, err := url.Parse(releaseStateConfig.Url)
+		if err != nil || parsedUrl.Scheme != urlScheme || parsedUrl.Host == "" {
+			return stacktrace.NewError("The '%s' backend needs the URL of the bucket and prefix to keep the release state under, like '%s://bucket/prefix', but it's '%s'", releaseStateConfig.Backend, urlScheme, releaseStateConfig.Url)
+		}
+	default:
+		return stacktrace.NewError("Release state backend '%s' must be one of '%s'", releaseStateConfig.Backend, ReleaseStateBackends)
+	}
+	return nil
+}
+
+func (sectionConfig RequiredChangelogSectionConfig) validate() error {
+	if strings.TrimSpace(sectionConfig.Subheader) == "" {
+		return stacktrace.NewError("Required changelog section subheaders can't be empty")
+	}
+	if len(sectionConfig.Paths) == 0 {
+		return stacktrace.NewError("The paths whose changes need entries under the subheader can't be empty")
+	}
+	for _, changedPath := range sectionConfig.Paths {
+		if strings.TrimSpace(changedPath) == "" {
+			return stacktrace.NewError("The paths whose changes need entries under the subheader can't be empty")
+		}
+		if _, err := path.Match(changedPath, ""); err != nil {
+			return stacktrace.Propagate(err, "The path '%s' whose changes need entries under the subheader is an invalid glob", changedPath)
+		}
+	}
+	for _, acker := range sectionConfig.AckedBy {
+		if strings.TrimSpace(acker) == "" {
+			return stacktrace.NewError("Who acknowledges the entries under the subheader can't be empty")
+		}
+	}
+	return nil
+}
+
+func (categoryConfig ChangelogCategoryConfig) validate() error {
+	if strings.TrimSpace(categoryConfig.Subheader) == "" {
+		return stacktrace.NewError("Changelog category subheaders can't be empty")
+	}
+	if !isOneOf(categoryConfig.Bump, Bumps) {
+		return stacktrace.NewError("The bump '%s' of changelog category '%s' must be one of '%s'", categoryConfig.Bump, categoryConfig.Subheader, Bumps)
+	}
+	return nil
+}
+
+func (overrideConfig BumpOverrideConfig) validate() error {
+	for _, approver := range overrideConfig.Approvers {
+		if strings.TrimSpace(approver) == "" {
+			return stacktrace.NewError("Bump override approvers can't be empty")
+		}
+	}
+	return nil
+}
+
+// validateVersionTracks makes sure that each track's tags and changelog are its own, so that no release of one track can
+// be mistaken for a release of another
+func (config *KudetConfig) validateVersionTracks() error {
+	seenNames := map[string]bool{MainVersionTrackName: true}
+	seenTagFormats := map[string]bool{}
+	seenChangelogFilepaths := map[string]bool{path.Clean(config.ChangelogFilepath): true}
+	for _, additionalChangelogFilepath := range config.AdditionalChangelogFilepaths {
+		seenChangelogFilepaths[path.Clean(additionalChangelogFilepath)] = true
+	}
+	for _, trackConfig := range config.VersionTracks {
+		if err := trackConfig.validate(); err != nil {
+			return stacktrace.Propagate(err, "The config of version track '%s' is invalid", trackConfig.Name)
+		}
+		if seenNames[trackConfig.Name] {
+			return stacktrace.NewError("Version track name '%s' is taken, either by another track or by the main version line", trackConfig.Name)
+		}
+		seenNames[trackConfig.Name] = true
+		// Validated above
+		tagPrefix, tagSuffix, _ := trackConfig.GetTagPrefixAndSuffix()
+		tagFormat := tagPrefix + sampleTagVersion + tagSuffix
+		if seenTagFormats[tagFormat] {
+			return stacktrace.NewError("Tags like '%s' are used by more than one version track", tagFormat)
+		}
+		seenTagFormats[tagFormat] = true
+		changelogFilepath := path.Clean(trackConfig.ChangelogFilepath)
+		if seenChangelogFilepaths[changelogFilepath] {
+			return stacktrace.NewError("Changelog '%s' of version track '%s' is already the changelog of another track, or of the main version line", trackConfig.ChangelogFilepath, trackConfig.Name)
+		}
+		seenChangelogFilepaths[changelogFilepath] = true
+	}
+	return nil
+}
+
+func (trackConfig VersionTrackConfig) validate() error {
+	if strings.TrimSpace(trackConfig.Name) == "" {
+		return stacktrace.NewError("Version track names can't be empty")
+	}
+	if (trackConfig.TagPrefix == "") == (trackConfig.TagTemplate == "") {
+		return stacktrace.NewError("Exactly one of '%s' and '%s' must be set", VersionTrackTagPrefixKey, VersionTrackTagTemplateKey)
+	}
+	tagPrefix, tagSuffix, err := trackConfig.GetTagPrefixAndSuffix()
+	if err != nil {
+		return stacktrace.Propagate(err, "The tag template '%s' is invalid", trackConfig.TagTemplate)
+	}
+	sampleTag := tagPrefix + sampleTagVersion + tagSuffix
+	if strings.ContainsAny(sampleTag, " \t\n") {
+		return stacktrace.NewError("The track's tags, like '%s', can't contain whitespace", sampleTag)
+	}
+	// The main version line's tags are the bare version, optionally with a 'v' prefix
+	if _, err := semver.StrictNewVersion(strings.TrimPrefix(sampleTag, "v")); err == nil {
+		return stacktrace.NewError("The track's tags, like '%s', would be mistaken for those of the main version line", sampleTag)
+	}
+	if strings.TrimSpace(trackConfig.ChangelogFilepath) == "" {
+		return stacktrace.NewError("The changelog filepath can't be empty")
+	}
+	if filepath.IsAbs(trackConfig.ChangelogFilepath) || strings.HasPrefix(path.Clean(filepath.ToSlash(trackConfig.ChangelogFilepath)), "../") {
+		return stacktrace.NewError("The changelog, '%s', must be inside the repo", trackConfig.ChangelogFilepath)
+	}
+	return nil
+}
+
+func (nonReleasableConfig NonReleasableChangesConfig) validate() error {
+	for _, nonReleasablePath := range nonReleasableConfig.Paths {
+		if strings.TrimSpace(nonReleasablePath) == "" {
+			return stacktrace.NewError("Non-releasable paths can't be empty")
+		}
+		if _, err := path.Match(nonReleasablePath, ""); err != nil {
+			return stacktrace.Propagate(err, "Non-releasable path '%s' is an invalid glob", nonReleasablePath)
+		}
+	}
+	if nonReleasableConfig.OnOnlyNonReleasable != "" && !isOneOf(nonReleasableConfig.OnOnlyNonReleasable, NonReleasableChangesPolicies) {
+		return stacktrace.NewError("What happens when only non-releasable files changed, '%s', must be one of '%s'", nonReleasableConfig.OnOnlyNonReleasable, NonReleasableChangesPolicies)
+	}
+	return nil
+}
+
+func (extendsConfig ExtendsConfig) validate() error {
+	if extendsConfig == (ExtendsConfig{}) {
+		return nil
+	}
+	if strings.TrimSpace(extendsConfig.Url) == "" {
+		return stacktrace.NewError("The URL of the repo that the base config is in can't be empty")
+	}
+	if strings.TrimSpace(extendsConfig.Ref) == "" {
+		return stacktrace.NewError("The ref that the base config is taken from can't be empty, so that the base only changes when it's updated on purpose")
+	}
+	if extendsConfig.Path != "" && (path.IsAbs(extendsConfig.Path) || strings.HasPrefix(path.Clean(extendsConfig.Path), "..")) {
+		return stacktrace.NewError("The path of the base config, '%s', must be relative to the root of its repo", extendsConfig.Path)
+	}
+	if extendsConfig.TokenEnvVar != "" && !envVarNameRegex.MatchString(extendsConfig.TokenEnvVar) {
+		return stacktrace.NewError("Token environment variable '%s' must be a valid environment variable name", extendsConfig.TokenEnvVar)
+	}
+	return nil
+}
+
+func (packageConfig PackageConfig) validate() error {
+	manifestFilepath := packageConfig.ManifestFilepath
+	if strings.TrimSpace(manifestFilepath) == "" {
+		return stacktrace.NewError("Package manifest paths can't be empty")
+	}
+	if filepath.IsAbs(manifestFilepath) || strings.HasPrefix(path.Clean(filepath.ToSlash(manifestFilepath)), "../") {
+		return stacktrace.NewError("The package manifest must be inside the repo")
+	}
+	if manifestFilename := path.Base(filepath.ToSlash(manifestFilepath)); !isOneOf(manifestFilename, PackageManifestFilenames) {
+		return stacktrace.NewError("Package manifest '%s' must be one of '%s'", manifestFilename, PackageManifestFilenames)
+	}
+	return nil
+}
+
+func (bazelConfig BazelConfig) validate() error {
+	if bazelConfig.StampFilepath != "" && (filepath.IsAbs(bazelConfig.StampFilepath) || strings.HasPrefix(path.Clean(filepath.ToSlash(bazelConfig.StampFilepath)), "../")) {
+		return stacktrace.NewError("The stamp file, '%s', must be inside the repo", bazelConfig.StampFilepath)
+	}
+	if len(bazelConfig.Command) == 0 || strings.TrimSpace(bazelConfig.Command[0]) == "" {
+		return stacktrace.NewError("The Bazel command can't be empty")
+	}
+	if len(bazelConfig.Artifacts) > 0 && strings.TrimSpace(bazelConfig.Target) == "" {
+		return stacktrace.NewError("Artifacts are configured, but there's no target to build them")
+	}
+	for _, artifactGlob := range bazelConfig.Artifacts {
+		if strings.TrimSpace(artifactGlob) == "" {
+			return stacktrace.NewError("The artifacts of the Bazel target can't be empty")
+		}
+		if _, err := path.Match(artifactGlob, ""); err != nil {
+			return stacktrace.Propagate(err, "Artifact '%s' of the Bazel target is an invalid glob", artifactGlob)
+		}
+	}
+	return nil
+}
+
+func (versionSourceConfig VersionSourceConfig) validate() error {
+	if versionSourceConfig.Url == "" {
+		if versionSourceConfig.Repository != "" || versionSourceConfig.TokenEnvVar != "" {
+			return stacktrace.NewError("A repository or token is configured, but there's no URL to request the version from")
+		}
+		return nil
+	}
+	if err := validateHttpUrl(versionSourceConfig.Url); err != nil {
+		return stacktrace.Propagate(err, "Version source URL '%s' is invalid", versionSourceConfig.Url)
+	}
+	if versionSourceConfig.TokenEnvVar != "" && !envVarNameRegex.MatchString(versionSourceConfig.TokenEnvVar) {
+		return stacktrace.NewError("Token environment variable '%s' must be a valid environment variable name", versionSourceConfig.TokenEnvVar)
+	}
+	return nil
+}
+
+func (mirrorConfig MirrorConfig) validate() error {
+	if strings.TrimSpace(mirrorConfig.Url) == "" {
+		return stacktrace.NewError("Mirror URLs can't be empty")
+	}
+	if mirrorConfig.TokenEnvVar != "" && !envVarNameRegex.MatchString(mirrorConfig.TokenEnvVar) {
+		return stacktrace.NewError("Token environment variable '%s' must be a valid environment variable name", mirrorConfig.TokenEnvVar)
+	}
+	if mirrorConfig.OnFailure != "" && !isOneOf(mirrorConfig.OnFailure, MirrorFailurePolicies) {
+		return stacktrace.NewError("Mirror failure policy '%s' must be one of '%s'", mirrorConfig.OnFailure, MirrorFailurePolicies)
+	}
+	return nil
+}
+
+// isOneOf reports whether the value is one of the comma-separated allowed values
+func isOneOf(value string, commaSeparatedAllowedValues string) bool {
+	for _, allowedValue := range strings.Split(commaSeparatedAllowedValues, ",") {
+		if value == allowedValue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package kudet_config
+
+import (
+	"bytes"
+	"github.com/kurtosis-tech/stacktrace"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+)
+
+const (
+	configFileMode   = 0644
+	yamlIndentSpaces = 2
+)
+
+// KudetConfigDocument is a .kudet.yml held as a YAML node tree rather than a struct, so that it can be edited and
+// written back without losing the comments and key ordering that the repo's maintainers put in it
+type KudetConfigDocument struct {
+	// The mapping node at the root of the document
+	root *yaml.Node
+	// The top-level document node, which carries any head comment of the file
+	document *yaml.Node
+}
+
+// LoadKudetConfigDocument reads the .kudet.yml at the root of the given repo, returning an empty document if none exists
+func LoadKudetConfigDocument(repoDirpath string) (*KudetConfigDocument, error) {
+	configFilepath := filepath.Join(repoDirpath, KudetConfigFilename)
+	configBytes, err := os.ReadFile(configFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newEmptyKudetConfigDocument(), nil
+		}
+		return nil, stacktrace.Propagate(err, "An error occurred reading the kudet config file at '%s'", configFilepath)
+	}
+	doc, err := parseKudetConfigDocument(configBytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the kudet config file at '%s'", configFilepath)
+	}
+	return doc, nil
+}
+
+// GetConfig validates the document and returns its parsed form
+func (doc *KudetConfigDocument) GetConfig() (*KudetConfig, error) {
+	configBytes, err := doc.serialize()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred serializing the kudet config document")
+	}
+	config, err := ParseKudetConfig(configBytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "The edited kudet config is invalid")
+	}
+	return config, nil
+}
+
+// Clone returns an independent copy of the document, so that edits can be tried out without affecting the original
+func (doc *KudetConfigDocument) Clone() (*KudetConfigDocument, error) {
+	configBytes, err := doc.serialize()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred serializing the kudet config document")
+	}
+	return parseKudetConfigDocument(configBytes)
+}
+
+// SetString sets the scalar at the given key path, creating intermediate mappings as needed
+func (doc *KudetConfigDocument) SetString(keyPath []string, value string) {
+	valueNode := getOrCreateValueNode(doc.root, keyPath)
+	valueNode.Kind = yaml.ScalarNode
+	valueNode.Tag = ""
+	valueNode.Style = 0
+	valueNode.Value = value
+	valueNode.Content = nil
+}
+
+// SetStringList sets the sequence at the given key path, creating intermediate mappings as needed
+func (doc *KudetConfigDocument) SetStringList(keyPath []string, values []string) {
+	valueNode := getOrCreateValueNode(doc.root, keyPath)
+	valueNode.Kind = yaml.SequenceNode
+	valueNode.Tag = ""
+	valueNode.Style = 0
+	valueNode.Value = ""
+	valueNode.Content = nil
+	for _, value := range values {
+		valueNode.Content = append(valueNode.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: value})
+	}
+}
+
+// Save validates the document and writes it to the .kudet.yml at the root of the given repo
+func (doc *KudetConfigDocument) Save(repoDirpath string) error {
+	if _, err := doc.GetConfig(); err != nil {
+		return stacktrace.Propagate(err, "Refusing to save an invalid kudet config")
+	}
+	configBytes, err := doc.serialize()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the kudet config document")
+	}
+	configFilepath := filepath.Join(repoDirpath, KudetConfigFilename)
+	if err := os.WriteFile(configFilepath, configBytes, configFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the kudet config file at '%s'", configFilepath)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newEmptyKudetConfigDocument() *KudetConfigDocument {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	document := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	return &KudetConfigDocument{root: root, document: document}
+}
+
+func parseKudetConfigDocument(configBytes []byte) (*KudetConfigDocument, error) {
+	if len(bytes.TrimSpace(configBytes)) == 0 {
+		return newEmptyKudetConfigDocument(), nil
+	}
+	document := &yaml.Node{}
+	if err := yaml.Unmarshal(configBytes, document); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the kudet config YAML")
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, stacktrace.NewError("Expected the kudet config to be a YAML mapping")
+	}
+	return &KudetConfigDocument{root: document.Content[0], document: document}, nil
+}
+
+func (doc *KudetConfigDocument) serialize() ([]byte, error) {
+	if len(doc.root.Content) == 0 {
+		return []byte{}, nil
+	}
+	buffer := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(yamlIndentSpaces)
+	if err := encoder.Encode(doc.document); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred encoding the kudet config YAML")
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred flushing the kudet config YAML encoder")
+	}
+	return buffer.Bytes(), nil
+}
+
+func getOrCreateValueNode(mapping *yaml.Node, keyPath []string) *yaml.Node {
+	current := mapping
+	for keyIdx, key := range keyPath {
+		var found *yaml.Node
+		// Mapping node content alternates key, value, key, value...
+		for idx := 0; idx+1 < len(current.Content); idx += 2 {
+			if current.Content[idx].Value == key {
+				found = current.Content[idx+1]
+				break
+			}
+		}
+		if found == nil {
+			found = &yaml.Node{Kind: yaml.MappingNode}
+			current.Content = append(current.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, found)
+		}
+		isLastKey := keyIdx == len(keyPath)-1
+		if found.Kind != yaml.MappingNode && !isLastKey {
+			found.Kind = yaml.MappingNode
+			found.Value = ""
+			found.Content = nil
+		}
+		current = found
+	}
+	return current
+}
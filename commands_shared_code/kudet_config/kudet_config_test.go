@@ -0,0 +1,691 @@
+package kudet_config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKudetConfig_EmptyFileUsesDefaults(t *testing.T) {
+	config, err := ParseKudetConfig([]byte(""))
+	require.NoError(t, err)
+	require.Equal(t, NewDefaultKudetConfig(), config)
+}
+
+func TestParseKudetConfig_OverridesDefaults(t *testing.T) {
+	configYaml := `
+# Releases are cut from master in this repo
+release-branch: master
+changelog-filepath: CHANGELOG.md
+`
+	config, err := ParseKudetConfig([]byte(configYaml))
+	require.NoError(t, err)
+	require.Equal(t, "master", config.ReleaseBranch)
+	require.Equal(t, "CHANGELOG.md", config.ChangelogFilepath)
+	require.Equal(t, defaultPreReleaseScriptsRelFilepath, config.PreReleaseScriptsFilepath)
+}
+
+func TestParseKudetConfig_RejectsUnknownKeys(t *testing.T) {
+	_, err := ParseKudetConfig([]byte("release-brnach: master\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_RejectsEmptyValues(t *testing.T) {
+	_, err := ParseKudetConfig([]byte("release-branch: \"\"\n"))
+	require.ErrorContains(t, err, "release branch can't be empty")
+}
+
+func TestKudetConfigDocument_EditsPreserveComments(t *testing.T) {
+	configYaml := `# Shared release settings
+release-branch: main # cut releases from here
+`
+	doc, err := parseKudetConfigDocument([]byte(configYaml))
+	require.NoError(t, err)
+	doc.SetString([]string{ReleaseBranchKey}, "develop")
+	doc.SetStringList([]string{NotificationsKey, WebhookUrlsKey}, []string{"https://hooks.example.com/release"})
+
+	serialized, err := doc.serialize()
+	require.NoError(t, err)
+	expectedYaml := `# Shared release settings
+release-branch: develop # cut releases from here
+notifications:
+  webhook-urls:
+    - https://hooks.example.com/release
+`
+	require.Equal(t, expectedYaml, string(serialized))
+
+	config, err := doc.GetConfig()
+	require.NoError(t, err)
+	require.Equal(t, "develop", config.ReleaseBranch)
+	require.Equal(t, []string{"https://hooks.example.com/release"}, config.Notifications.WebhookUrls)
+}
+
+func TestParseKudetConfig_AnalyticsRequireEndpointWhenEnabled(t *testing.T) {
+	_, err := ParseKudetConfig([]byte("analytics:\n  enabled: true\n"))
+	require.ErrorContains(t, err, "analytics endpoint is required")
+
+	config, err := ParseKudetConfig([]byte("analytics:\n  enabled: true\n  endpoint: https://metrics.example.com/kudet\n"))
+	require.NoError(t, err)
+	require.True(t, config.Analytics.Enabled)
+}
+
+func TestParseKudetConfig_ValidatesMajorChangesSubheaderRegex(t *testing.T) {
+	_, err := ParseKudetConfig([]byte("major-changes-subheader-regex: \"^###*\\\\s*(Major\"\n"))
+	require.ErrorContains(t, err, "Major changes subheader regex")
+
+	config, err := ParseKudetConfig([]byte("major-changes-subheader-regex: \"\"\n"))
+	require.NoError(t, err)
+	require.Empty(t, config.MajorChangesSubheaderRegex)
+}
+
+func TestParseKudetConfig_ValidatesSecurityAdvisory(t *testing.T) {
+	_, err := ParseKudetConfig([]byte("security-advisory:\n  severity: urgent\n"))
+	require.ErrorContains(t, err, "severity 'urgent'")
+
+	_, err = ParseKudetConfig([]byte("security-advisory:\n  ecosystem: go\n"))
+	require.ErrorContains(t, err, "must be set together")
+
+	config, err := ParseKudetConfig([]byte("security-advisory:\n  ecosystem: go\n  package: github.com/kurtosis-tech/kudet\n  severity: high\n"))
+	require.NoError(t, err)
+	require.Equal(t, "high", config.SecurityAdvisory.Severity)
+}
+
+func TestParseKudetConfig_ValidatesForge(t *testing.T) {
+	_, err := ParseKudetConfig([]byte("forge:\n  type: bitbucket\n"))
+	require.ErrorContains(t, err, "Forge type 'bitbucket'")
+
+	_, err = ParseKudetConfig([]byte("forge:\n  api-url: gitlab.example.com/api/v4\n"))
+	require.ErrorContains(t, err, "Forge API URL")
+
+	config, err := ParseKudetConfig([]byte("forge:\n  type: gitlab\n  api-url: https://gitlab.example.com/api/v4\n"))
+	require.NoError(t, err)
+	require.Equal(t, GitlabForgeType, config.Forge.Type)
+}
+
+func TestParseKudetConfig_DownstreamKeepsDefaultManifest(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("downstream:\n  notify-owners: true\n"))
+	require.NoError(t, err)
+	require.True(t, config.Downstream.NotifyOwners)
+	require.Equal(t, defaultDownstreamManifestRelFilepath, config.Downstream.ManifestFilepath)
+
+	_, err = ParseKudetConfig([]byte("downstream:\n  manifest-filepath: ''\n"))
+	require.ErrorContains(t, err, "downstream manifest filepath can't be empty")
+}
+
+func TestParseKudetConfig_ValidatesOwners(t *testing.T) {
+	_, err := ParseKudetConfig([]byte("ownership:\n  owners: ['@kurtosis-tech/tooling', ' ']\n"))
+	require.ErrorContains(t, err, "Repo owners can't be empty")
+}
+
+func TestParseKudetConfig_ValidatesVersionFiles(t *testing.T) {
+	_, err := ParseKudetConfig([]byte("version-files:\n  - filepath: version.go\n    pattern: 'const Version = \"\"'\n"))
+	require.ErrorContains(t, err, "version pattern of file 'version.go' is invalid")
+
+	_, err = ParseKudetConfig([]byte("version-files:\n  - pattern: 'version \"%s\"'\n"))
+	require.ErrorContains(t, err, "Version file paths can't be empty")
+
+	config, err := ParseKudetConfig([]byte("version-files:\n  - filepath: ../homebrew-tap/Formula/kudet.rb\n    pattern: 'version \"%s\"'\n"))
+	require.NoError(t, err)
+	require.Len(t, config.VersionFiles, 1)
+}
+
+func TestParseKudetConfig_ValidatesEnvironments(t *testing.T) {
+	manifests := "  manifests:\n    - name: staging\n      filepath: environments/staging/versions.yml\n      pattern: 'kudet: \"%s\"'\n      auto-promote: true\n"
+
+	_, err := ParseKudetConfig([]byte("environments:\n" + manifests))
+	require.ErrorContains(t, err, "GitOps repo is required")
+
+	_, err = ParseKudetConfig([]byte("environments:\n  gitops-repo-dirpath: ../gitops\n" + manifests + "    - name: staging\n      filepath: b.yml\n      pattern: '%s'\n"))
+	require.ErrorContains(t, err, "'staging' is configured more than once")
+
+	config, err := ParseKudetConfig([]byte("environments:\n  gitops-repo-dirpath: ../gitops\n" + manifests))
+	require.NoError(t, err)
+	require.Equal(t, "main", config.Environments.Branch)
+	require.True(t, config.Environments.Manifests[0].AutoPromote)
+}
+
+func TestParseKudetConfig_ValidatesAllowedDirtyPaths(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("allowed-dirty-paths:\n  - docs/generated/\n  - '*.pb.go'\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"docs/generated/", "*.pb.go"}, config.AllowedDirtyPaths)
+
+	_, err = ParseKudetConfig([]byte("allowed-dirty-paths:\n  - '[gen'\n"))
+	require.ErrorContains(t, err, "invalid glob")
+}
+
+func TestParseKudetConfig_ParsesHealthCheck(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("health-check:\n  url: https://status.example.com/healthz\n  duration: 10m\n"))
+	require.NoError(t, err)
+	require.Equal(t, "https://status.example.com/healthz", config.HealthCheck.Url)
+	require.Equal(t, 10*time.Minute, config.HealthCheck.Duration)
+	require.Equal(t, 30*time.Second, config.HealthCheck.Interval)
+
+	_, err = ParseKudetConfig([]byte("health-check:\n  url: https://status.example.com/healthz\n  duration: 10s\n  interval: 1m\n"))
+	require.ErrorContains(t, err, "no longer than its duration")
+}
+
+func TestParseKudetConfig_ValidatesPreReleaseScriptsShell(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("pre-release-scripts-shell: [bash, -eo, pipefail, -c]\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"bash", "-eo", "pipefail", "-c"}, config.PreReleaseScriptsShell)
+
+	_, err = ParseKudetConfig([]byte("pre-release-scripts-shell: []\n"))
+	require.ErrorContains(t, err, "pre-release scripts shell")
+}
+
+func TestParseKudetConfig_ValidatesPreReleaseScriptsWritablePaths(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("pre-release-scripts-writable-paths: [docs/generated/, setup.cfg]\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"docs/generated/", "setup.cfg"}, config.PreReleaseScriptsWritablePaths)
+
+	_, err = ParseKudetConfig([]byte("pre-release-scripts-writable-paths: ['[']\n"))
+	require.ErrorContains(t, err, "invalid glob")
+}
+
+func TestParseKudetConfig_ValidatesReleaseCommitMessageTemplate(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("release-commit-message-template: 'Release {{.Version}} on {{.Date}}'\n"))
+	require.NoError(t, err)
+	require.Equal(t, "Release {{.Version}} on {{.Date}}", config.ReleaseCommitMessageTemplate)
+
+	_, err = ParseKudetConfig([]byte("release-commit-message-template: 'Release {{.Version'\n"))
+	require.ErrorContains(t, err, "template")
+	_, err = ParseKudetConfig([]byte("release-commit-message-template: 'Release {{.Tag}}'\n"))
+	require.ErrorContains(t, err, "template")
+}
+
+func TestParseKudetConfig_ValidatesPreReleaseScriptSecrets(t *testing.T) {
+	configYaml := `
+pre-release-script-secrets:
+  scripts/publish.sh:
+    - name: NPM_TOKEN
+      source: vault
+      ref: secret/data/npm#token
+`
+	config, err := ParseKudetConfig([]byte(configYaml))
+	require.NoError(t, err)
+	require.Equal(t, []SecretConfig{{Name: "NPM_TOKEN", Source: VaultSecretSource, Ref: "secret/data/npm#token"}}, config.PreReleaseScriptSecrets["scripts/publish.sh"])
+
+	_, err = ParseKudetConfig([]byte("pre-release-script-secrets: {scripts/publish.sh: [{name: NPM-TOKEN, source: env, ref: NPM_TOKEN}]}\n"))
+	require.ErrorContains(t, err, "environment variable name")
+	_, err = ParseKudetConfig([]byte("pre-release-script-secrets: {scripts/publish.sh: [{name: NPM_TOKEN, source: vault, ref: secret/data/npm}]}\n"))
+	require.ErrorContains(t, err, "Vault ref")
+	_, err = ParseKudetConfig([]byte("pre-release-script-secrets: {scripts/publish.sh: [{name: NPM_TOKEN, source: keychain, ref: npm}]}\n"))
+	require.ErrorContains(t, err, "must be one of")
+}
+
+func TestParseKudetConfig_ValidatesPreReleaseScriptArtifacts(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("pre-release-script-artifacts: {scripts/build.sh: ['dist/*.spdx.json', build.log]}\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"dist/*.spdx.json", "build.log"}, config.PreReleaseScriptArtifacts["scripts/build.sh"])
+
+	_, err = ParseKudetConfig([]byte("pre-release-script-artifacts: {scripts/build.sh: ['[']}\n"))
+	require.ErrorContains(t, err, "invalid glob")
+}
+
+func TestParseKudetConfig_ValidatesPreReleaseScriptSchedule(t *testing.T) {
+	configYaml := `
+pre-release-scripts-parallelism: 4
+pre-release-script-schedule:
+  scripts/gen-api-client.sh:
+    depends-on: [scripts/gen-openapi-spec.sh]
+    only-if-changed: ['api/**']
+`
+	config, err := ParseKudetConfig([]byte(configYaml))
+	require.NoError(t, err)
+	require.Equal(t, 4, config.PreReleaseScriptsParallelism)
+	require.Equal(t, PreReleaseScriptScheduleConfig{
+		DependsOn:     []string{"scripts/gen-openapi-spec.sh"},
+		OnlyIfChanged: []string{"api/**"},
+	}, config.PreReleaseScriptSchedule["scripts/gen-api-client.sh"])
+
+	defaultConfig, err := ParseKudetConfig([]byte(""))
+	require.NoError(t, err)
+	require.Equal(t, 1, defaultConfig.PreReleaseScriptsParallelism)
+
+	_, err = ParseKudetConfig([]byte("pre-release-scripts-parallelism: -1\n"))
+	require.ErrorContains(t, err, "parallelism")
+	_, err = ParseKudetConfig([]byte("pre-release-script-schedule: {scripts/gen.sh: {only-if-changed: ['[']}}\n"))
+	require.ErrorContains(t, err, "invalid glob")
+}
+
+func TestParseKudetConfig_Policy(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("policy: {paths: [policies/, /etc/kudet/org.rego]}\n"))
+	require.NoError(t, err)
+	require.Equal(t, PolicyConfig{
+		Paths: []string{"policies/", "/etc/kudet/org.rego"},
+		Query: defaultPolicyQuery,
+	}, config.Policy)
+
+	_, err = ParseKudetConfig([]byte("policy: {paths: [policies/], query: ''}\n"))
+	require.ErrorContains(t, err, "query can't be empty")
+	_, err = ParseKudetConfig([]byte("policy: {paths: ['  ']}\n"))
+	require.ErrorContains(t, err, "paths can't be empty")
+
+	config, err = ParseKudetConfig([]byte("policy: {wasm-gates: [plugins/freeze.wasm]}\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"plugins/freeze.wasm"}, config.Policy.WasmGates)
+	_, err = ParseKudetConfig([]byte("policy: {wasm-gates: ['']}\n"))
+	require.ErrorContains(t, err, "WASM gate paths can't be empty")
+}
+
+func TestParseKudetConfig_TagSignatures(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("tag-signatures: {keyring: .github/release-keys.asc}\n"))
+	require.NoError(t, err)
+	require.Equal(t, ".github/release-keys.asc", config.TagSignatures.KeyringFilepath)
+
+	config, err = ParseKudetConfig([]byte(""))
+	require.NoError(t, err)
+	require.Empty(t, config.TagSignatures.KeyringFilepath)
+}
+
+func TestParseKudetConfig_ReleaseState(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("release-state: {backend: gcs, url: gs://ci-state/kudet/my-repo}\n"))
+	require.NoError(t, err)
+	require.Equal(t, GcsReleaseStateBackend, config.ReleaseState.Backend)
+
+	_, err = ParseKudetConfig([]byte("release-state: {backend: s3, url: gs://ci-state/kudet/my-repo}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("release-state: {backend: s3}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("release-state: {url: s3://ci-state}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("release-state: {backend: azure}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_DryRunSteps(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("dry-run-steps: [push, notifications]\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{PushDryRunStep, NotificationsDryRunStep}, config.DryRunSteps)
+
+	_, err = ParseKudetConfig([]byte("dry-run-steps: [commit]\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_Mirrors(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("mirrors:\n  - url: https://gitlab.internal.example.com/tooling/kudet.git\n    token-env-var: INTERNAL_GITLAB_TOKEN\n    on-failure: fail\n"))
+	require.NoError(t, err)
+	require.Equal(t, []MirrorConfig{{Url: "https://gitlab.internal.example.com/tooling/kudet.git", TokenEnvVar: "INTERNAL_GITLAB_TOKEN", OnFailure: FailMirrorFailurePolicy}}, config.Mirrors)
+
+	_, err = ParseKudetConfig([]byte("mirrors: [{url: ''}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("mirrors: [{url: 'https://example.com/x.git', on-failure: ignore}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("mirrors: [{url: 'https://example.com/x.git', token-env-var: 'NOT-A-VAR'}]\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_FetchGracePeriod(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("release-branch: main\n"))
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, config.FetchGracePeriod)
+
+	config, err = ParseKudetConfig([]byte("fetch-grace-period: 0s\n"))
+	require.NoError(t, err)
+	require.Zero(t, config.FetchGracePeriod)
+
+	_, err = ParseKudetConfig([]byte("fetch-grace-period: -1m\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_ChangelogMaxLineLength(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("release-branch: main\n"))
+	require.NoError(t, err)
+	require.Equal(t, 1024*1024, config.ChangelogMaxLineLength)
+
+	config, err = ParseKudetConfig([]byte("changelog-max-line-length: 8388608\n"))
+	require.NoError(t, err)
+	require.Equal(t, 8*1024*1024, config.ChangelogMaxLineLength)
+
+	_, err = ParseKudetConfig([]byte("changelog-max-line-length: -1\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_Packages(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("packages:\n  - manifest: packages/core/package.json\n    release-command: npm publish\n  - manifest: sdk/go.mod\n"))
+	require.NoError(t, err)
+	require.Equal(t, []PackageConfig{
+		{ManifestFilepath: "packages/core/package.json", ReleaseCommand: "npm publish"},
+		{ManifestFilepath: "sdk/go.mod"},
+	}, config.Packages)
+
+	_, err = ParseKudetConfig([]byte("packages: [{manifest: packages/core/Cargo.toml}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("packages: [{manifest: ../other/package.json}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("packages: [{manifest: sdk/go.mod}, {manifest: ./sdk/go.mod}]\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_Bazel(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("bazel:\n  stamp-filepath: tools/version_stamp.txt\n  target: //:release-artifacts\n  artifacts: [bazel-bin/release/*.tar.gz]\n"))
+	require.NoError(t, err)
+	require.Equal(t, BazelConfig{
+		StampFilepath: "tools/version_stamp.txt",
+		Target:        "//:release-artifacts",
+		Command:       []string{"bazel", "run", "--stamp"},
+		Artifacts:     []string{"bazel-bin/release/*.tar.gz"},
+	}, config.Bazel)
+
+	config, err = ParseKudetConfig([]byte("bazel: {target: '//:release', command: [plz, run]}\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"plz", "run"}, config.Bazel.Command)
+
+	_, err = ParseKudetConfig([]byte("bazel: {stamp-filepath: ../version.txt}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("bazel: {target: '//:release', command: []}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("bazel: {artifacts: [bazel-bin/*.tar.gz]}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_VersionSource(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("version-source:\n  url: https://versions.example.com/allocate\n  repository: kurtosis-tech/kudet\n  token-env-var: VERSION_SOURCE_TOKEN\n"))
+	require.NoError(t, err)
+	require.Equal(t, VersionSourceConfig{
+		Url:         "https://versions.example.com/allocate",
+		Repository:  "kurtosis-tech/kudet",
+		TokenEnvVar: "VERSION_SOURCE_TOKEN",
+	}, config.VersionSource)
+
+	_, err = ParseKudetConfig([]byte("version-source: {url: ftp://versions.example.com}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("version-source: {url: https://versions.example.com, token-env-var: 1-TOKEN}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("version-source: {repository: kurtosis-tech/kudet}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_AuditLog(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("audit-log:\n  url: https://siem.example.com/collector\n  token-env-var: AUDIT_LOG_TOKEN\n"))
+	require.NoError(t, err)
+	require.Equal(t, AuditLogConfig{Url: "https://siem.example.com/collector", TokenEnvVar: "AUDIT_LOG_TOKEN"}, config.AuditLog)
+
+	_, err = ParseKudetConfig([]byte("audit-log: {url: siem.example.com}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("audit-log: {url: https://siem.example.com, token-env-var: 'AUDIT LOG'}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_RequiredChangelogSections(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("required-changelog-sections:\n  - subheader: API changes\n    paths: [api/**]\n    acked-by: ['@kurtosis-tech/api']\n"))
+	require.NoError(t, err)
+	require.Equal(t, []RequiredChangelogSectionConfig{{Subheader: "API changes", Paths: []string{"api/**"}, AckedBy: []string{"@kurtosis-tech/api"}}}, config.RequiredChangelogSections)
+
+	_, err = ParseKudetConfig([]byte("required-changelog-sections: [{subheader: '', paths: [api/]}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("required-changelog-sections: [{subheader: API changes}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("required-changelog-sections: [{subheader: API changes, paths: ['api/[']}]\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_ChangelogCategories(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("changelog-categories:\n  - subheader: Deprecations\n    bump: minor\n  - subheader: Security\n    bump: patch\n    force-release: true\n"))
+	require.NoError(t, err)
+	require.Equal(t, []ChangelogCategoryConfig{
+		{Subheader: "Deprecations", Bump: MinorBump},
+		{Subheader: "Security", Bump: PatchBump, ForceRelease: true},
+	}, config.ChangelogCategories)
+
+	_, err = ParseKudetConfig([]byte("changelog-categories: [{subheader: Removed}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("changelog-categories: [{subheader: '', bump: major}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("changelog-categories: [{subheader: Removed, bump: major}, {subheader: removed, bump: minor}]\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_BumpOverride(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("bump-override:\n  approvers: [lead@example.com, Release Manager]\n"))
+	require.NoError(t, err)
+	require.Equal(t, BumpOverrideConfig{Approvers: []string{"lead@example.com", "Release Manager"}}, config.BumpOverride)
+
+	_, err = ParseKudetConfig([]byte("bump-override: {approvers: ['']}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_VersionTracks(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("version-tracks:\n  - name: cli\n    tag-prefix: cli/v\n    changelog-filepath: cli/changelog.md\n"))
+	require.NoError(t, err)
+	require.Equal(t, []VersionTrackConfig{{Name: "cli", TagPrefix: "cli/v", ChangelogFilepath: "cli/changelog.md"}}, config.VersionTracks)
+
+	_, err = ParseKudetConfig([]byte("version-tracks: [{name: main, tag-prefix: cli/v, changelog-filepath: cli/changelog.md}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("version-tracks: [{name: cli, tag-prefix: v, changelog-filepath: cli/changelog.md}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("version-tracks: [{name: cli, tag-prefix: cli/v, changelog-filepath: docs/changelog.md}]\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("version-tracks: [{name: cli, tag-prefix: cli/v, changelog-filepath: cli/changelog.md}, {name: sdk, tag-prefix: cli/v, changelog-filepath: sdk/changelog.md}]\n"))
+	require.Error(t, err)
+
+	config, err = ParseKudetConfig([]byte("version-tracks:\n  - name: cli\n    tag-template: 'releases/{{.Version}}/{{.Scope}}'\n    changelog-filepath: cli/changelog.md\n"))
+	require.NoError(t, err)
+	tagPrefix, tagSuffix, err := config.VersionTracks[0].GetTagPrefixAndSuffix()
+	require.NoError(t, err)
+	require.Equal(t, "releases/", tagPrefix)
+	require.Equal(t, "/cli", tagSuffix)
+
+	for _, invalidTrack := range []string{
+		// Exactly one of the tag prefix and template
+		"{name: cli, changelog-filepath: cli/changelog.md}",
+		"{name: cli, tag-prefix: cli/v, tag-template: '{{.Scope}}/v{{.Version}}', changelog-filepath: cli/changelog.md}",
+		// The version must be in there once
+		"{name: cli, tag-template: '{{.Scope}}/latest', changelog-filepath: cli/changelog.md}",
+		"{name: cli, tag-template: '{{.Version}}/{{.Version}}', changelog-filepath: cli/changelog.md}",
+		"{name: cli, tag-template: '{{.Scope', changelog-filepath: cli/changelog.md}",
+		// Tags that the main version line would take for its own
+		"{name: cli, tag-template: 'v{{.Version}}', changelog-filepath: cli/changelog.md}",
+		"{name: cli, tag-template: 'v{{.Version}}-{{.Scope}}', changelog-filepath: cli/changelog.md}",
+		"{name: cli, tag-template: '{{.Version}}+{{.Scope}}', changelog-filepath: cli/changelog.md}",
+		"{name: cli, tag-template: '{{.Scope}} {{.Version}}', changelog-filepath: cli/changelog.md}",
+	} {
+		_, err = ParseKudetConfig([]byte("version-tracks: [" + invalidTrack + "]\n"))
+		require.Error(t, err, invalidTrack)
+	}
+	_, err = ParseKudetConfig([]byte("version-tracks: [{name: cli, tag-prefix: cli/v, changelog-filepath: cli/changelog.md}, {name: sdk, tag-template: 'cli/v{{.Version}}', changelog-filepath: sdk/changelog.md}]\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_NonReleasableChanges(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("non-releasable-changes:\n  paths: [vendor/, docs/generated/**, .github/**]\n  on-only-non-releasable: skip\n"))
+	require.NoError(t, err)
+	require.Equal(t, NonReleasableChangesConfig{
+		Paths:               []string{"vendor/", "docs/generated/**", ".github/**"},
+		OnOnlyNonReleasable: SkipNonReleasableChangesPolicy,
+	}, config.NonReleasableChanges)
+
+	_, err = ParseKudetConfig([]byte("non-releasable-changes: {paths: ['vendor/[']}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("non-releasable-changes: {paths: [vendor/], on-only-non-releasable: fail}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_MinReleaseInterval(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("min-release-interval: 1h\n"))
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, config.MinReleaseInterval)
+
+	_, err = ParseKudetConfig([]byte("min-release-interval: -1h\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_UndoWindow(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("undo-window: 5m\n"))
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, config.UndoWindow)
+
+	_, err = ParseKudetConfig([]byte("undo-window: -5m\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_TagMessageTemplate(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("tag-message-template: 'Release {{.Version}}: {{index .SectionEntryCounts \"Fixes\"}} fixes'\n"))
+	require.NoError(t, err)
+	message, err := ExecuteTagMessageTemplate(config.TagMessageTemplate, TagMessageData{Version: "1.2.3", SectionEntryCounts: map[string]int{"Fixes": 2}})
+	require.NoError(t, err)
+	require.Equal(t, "Release 1.2.3: 2 fixes", message)
+
+	_, err = ParseKudetConfig([]byte("tag-message-template: 'Release {{.Nope}}'\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_GithubApp(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("github-app:\n  app-id: 1234\n  private-key-env-var: RELEASE_APP_PRIVATE_KEY\n"))
+	require.NoError(t, err)
+	require.Equal(t, GithubAppConfig{AppId: 1234, PrivateKeyEnvVar: "RELEASE_APP_PRIVATE_KEY"}, config.GithubApp)
+
+	_, err = ParseKudetConfig([]byte("github-app: {app-id: 1234}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("github-app: {private-key-env-var: RELEASE_APP_PRIVATE_KEY}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("github-app: {app-id: 1234, installation-id: -1, private-key-env-var: RELEASE_APP_PRIVATE_KEY}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_Approval(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("approval:\n  approvers: [octocat, hubot]\n"))
+	require.NoError(t, err)
+	require.Equal(t, ApprovalConfig{Approvers: []string{"octocat", "hubot"}, Timeout: time.Hour}, config.Approval)
+
+	config, err = ParseKudetConfig([]byte("approval: {approvers: [octocat], timeout: 4h}\n"))
+	require.NoError(t, err)
+	require.Equal(t, 4*time.Hour, config.Approval.Timeout)
+
+	_, err = ParseKudetConfig([]byte("approval: {approvers: ['']}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("approval: {approvers: [octocat], timeout: 0s}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_Milestones(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("milestones:\n  enabled: true\n"))
+	require.NoError(t, err)
+	require.Equal(t, MilestonesConfig{Enabled: true, NextBump: MinorBump}, config.Milestones)
+
+	config, err = ParseKudetConfig([]byte("milestones: {enabled: true, next-bump: patch}\n"))
+	require.NoError(t, err)
+	require.Equal(t, PatchBump, config.Milestones.NextBump)
+
+	_, err = ParseKudetConfig([]byte("milestones: {enabled: true, next-bump: huge}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_MinKudetVersion(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("min-kudet-version: 0.5.0\n"))
+	require.NoError(t, err)
+	require.Equal(t, "0.5.0", config.MinKudetVersion)
+	_, err = ParseKudetConfig([]byte("min-kudet-version: latest\n"))
+	require.Error(t, err)
+
+	originalKudetVersion := kudet_version.KudetVersion
+	kudet_version.KudetVersion = "0.4.2"
+	defer func() {
+		kudet_version.KudetVersion = originalKudetVersion
+	}()
+	_, err = ParseKudetConfig([]byte("min-kudet-version: 0.4.0\n"))
+	require.NoError(t, err)
+	// Keys added after this kudet get the upgrade hint rather than an unknown key error
+	_, err = ParseKudetConfig([]byte("min-kudet-version: 0.5.0\nsome-future-key: true\n"))
+	require.ErrorContains(t, err, "requires kudet '0.5.0' or newer")
+}
+
+func TestParseKudetConfig_Extends(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("extends:\n  url: https://github.com/acme/release-configs.git\n  path: kudet/base.yml\n  ref: v3\n"))
+	require.NoError(t, err)
+	require.Equal(t, ExtendsConfig{Url: "https://github.com/acme/release-configs.git", Path: "kudet/base.yml", Ref: "v3"}, config.Extends)
+
+	_, err = ParseKudetConfig([]byte("extends: {url: https://github.com/acme/release-configs.git}\n"))
+	require.ErrorContains(t, err, "ref")
+	_, err = ParseKudetConfig([]byte("extends: {ref: v3}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("extends: {url: https://github.com/acme/release-configs.git, ref: v3, path: ../base.yml}\n"))
+	require.Error(t, err)
+}
+
+func TestLoadKudetConfig_MergesLockedBaseConfig(t *testing.T) {
+	repoDirpath := t.TempDir()
+	extendsConfig := ExtendsConfig{Url: "https://github.com/acme/release-configs.git", Ref: "v3"}
+	baseConfigYaml := `
+release-branch: develop
+allowed-dirty-paths: [docs/]
+ci:
+  required-checks: [build, test]
+notifications:
+  webhook-urls: [https://hooks.example.com/releases]
+`
+	lock, err := NewBaseConfigLock(extendsConfig, "0123456789abcdef0123456789abcdef01234567", []byte(baseConfigYaml))
+	require.NoError(t, err)
+	require.NoError(t, lock.Save(repoDirpath))
+	configYaml := `
+extends:
+  url: https://github.com/acme/release-configs.git
+  ref: v3
+changelog-filepath: CHANGELOG.md
+allowed-dirty-paths: [generated/]
+ci:
+  required-checks: [lint]
+`
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, KudetConfigFilename), []byte(configYaml), 0644))
+
+	config, err := LoadKudetConfig(repoDirpath)
+	require.NoError(t, err)
+	require.Equal(t, "develop", config.ReleaseBranch)
+	require.Equal(t, "CHANGELOG.md", config.ChangelogFilepath)
+	// Lists are replaced rather than appended to, while mappings are merged
+	require.Equal(t, []string{"generated/"}, config.AllowedDirtyPaths)
+	require.Equal(t, []string{"lint"}, config.Ci.RequiredChecks)
+	require.Equal(t, []string{"https://hooks.example.com/releases"}, config.Notifications.WebhookUrls)
+	require.Equal(t, extendsConfig, config.Extends)
+}
+
+func TestLoadKudetConfig_BaseConfigMustBeLocked(t *testing.T) {
+	repoDirpath := t.TempDir()
+	configYaml := "extends: {url: https://github.com/acme/release-configs.git, ref: v3}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, KudetConfigFilename), []byte(configYaml), 0644))
+	_, err := LoadKudetConfig(repoDirpath)
+	require.ErrorContains(t, err, "hasn't been locked")
+
+	// Changing the ref needs the lock to be updated
+	lock, err := NewBaseConfigLock(ExtendsConfig{Url: "https://github.com/acme/release-configs.git", Ref: "v2"}, "0123456789abcdef0123456789abcdef01234567", []byte("release-branch: develop\n"))
+	require.NoError(t, err)
+	require.NoError(t, lock.Save(repoDirpath))
+	_, err = LoadKudetConfig(repoDirpath)
+	require.ErrorContains(t, err, "kudet config update-base")
+}
+
+func TestNewBaseConfigLock_ValidatesBaseConfig(t *testing.T) {
+	extendsConfig := ExtendsConfig{Url: "https://github.com/acme/release-configs.git", Ref: "v3"}
+	_, err := NewBaseConfigLock(extendsConfig, "0123456789abcdef0123456789abcdef01234567", []byte("extends: {url: https://github.com/acme/other.git, ref: v1}\n"))
+	require.ErrorContains(t, err, "can't extend other configs")
+	_, err = NewBaseConfigLock(extendsConfig, "0123456789abcdef0123456789abcdef01234567", []byte("release-brnach: develop\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_Telemetry(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("telemetry:\n  otlp-endpoint: https://otel.example.com:4318\n  headers-env-var: OTEL_EXPORTER_OTLP_HEADERS\n"))
+	require.NoError(t, err)
+	require.Equal(t, TelemetryConfig{OtlpEndpoint: "https://otel.example.com:4318", HeadersEnvVar: "OTEL_EXPORTER_OTLP_HEADERS"}, config.Telemetry)
+
+	_, err = ParseKudetConfig([]byte("telemetry: {otlp-endpoint: otel.example.com:4318}\n"))
+	require.Error(t, err)
+	_, err = ParseKudetConfig([]byte("telemetry: {otlp-endpoint: https://otel.example.com, headers-env-var: 'OTEL HEADERS'}\n"))
+	require.Error(t, err)
+}
+
+func TestParseKudetConfig_ArtifactDiff(t *testing.T) {
+	config, err := ParseKudetConfig([]byte("artifact-diff:\n  enabled: true\n  fail-on-anomaly: true\n"))
+	require.NoError(t, err)
+	require.Equal(t, ArtifactDiffConfig{Enabled: true, MaxSizeRatio: defaultArtifactDiffMaxSizeRatio, FailOnAnomaly: true}, config.ArtifactDiff)
+
+	config, err = ParseKudetConfig([]byte("artifact-diff: {enabled: true, max-size-ratio: 1.5}\n"))
+	require.NoError(t, err)
+	require.Equal(t, 1.5, config.ArtifactDiff.MaxSizeRatio)
+
+	_, err = ParseKudetConfig([]byte("artifact-diff: {enabled: true, max-size-ratio: 1}\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,7 @@
+package kudet_version
+
+// DevelopmentVersion is what locally-built binaries report, since only release builds have their version stamped in
+const DevelopmentVersion = "dev"
+
+// KudetVersion is the version of this kudet binary; Goreleaser overrides it at build time via -ldflags
+var KudetVersion = DevelopmentVersion
@@ -0,0 +1,147 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	runningStatus   = "running"
+	succeededStatus = "succeeded"
+	failedStatus    = "failed"
+
+	// CI systems set this, and their logs don't render terminal escapes well even when they allocate a TTY
+	ciEnvVar = "CI"
+
+	boldEscape  = "\033[1m"
+	greenEscape = "\033[32m"
+	redEscape   = "\033[31m"
+	resetEscape = "\033[0m"
+
+	succeededSymbol = "✔"
+	failedSymbol    = "✘"
+
+	summaryTableMinWidth = 0
+	summaryTableTabWidth = 8
+	summaryTablePadding  = 2
+	summaryTablePadChar  = ' '
+
+	durationRounding = 10 * time.Millisecond
+)
+
+// Tracker renders the steps of a long-running operation as they start and finish, with a summary table of how long
+// each took at the end; a nil Tracker renders nothing, so callers don't need to check for one
+type Tracker struct {
+	out io.Writer
+
+	// If true, steps are rendered with colors and symbols for a person watching; otherwise as plain lines for CI logs
+	isInteractive bool
+
+	now func() time.Time
+
+	steps []*step
+}
+
+type step struct {
+	name      string
+	status    string
+	startTime time.Time
+	duration  time.Duration
+}
+
+// NewTracker creates a tracker rendering to the given output
+func NewTracker(out io.Writer, isInteractive bool) *Tracker {
+	return &Tracker{
+		out:           out,
+		isInteractive: isInteractive,
+		now:           time.Now,
+		steps:         []*step{},
+	}
+}
+
+// IsInteractiveTerminal reports whether the file is a terminal that a person is watching, rather than a pipe, a file,
+// or a CI log
+func IsInteractiveTerminal(file *os.File) bool {
+	if os.Getenv(ciEnvVar) != "" {
+		return false
+	}
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return fileInfo.Mode()&os.ModeCharDevice != 0
+}
+
+// StartStep finishes the running step, if any, as succeeded and starts the named one
+func (tracker *Tracker) StartStep(name string) {
+	if tracker == nil {
+		return
+	}
+	tracker.finishRunningStep(succeededStatus)
+	newStep := &step{
+		name:      name,
+		status:    runningStatus,
+		startTime: tracker.now(),
+		duration:  0,
+	}
+	tracker.steps = append(tracker.steps, newStep)
+	if tracker.isInteractive {
+		fmt.Fprintf(tracker.out, "%s==> [%d] %s%s\n", boldEscape, len(tracker.steps), name, resetEscape)
+		return
+	}
+	fmt.Fprintf(tracker.out, "==> Step %d: %s\n", len(tracker.steps), name)
+}
+
+// Finish finishes the running step, as failed if the operation returned an error, and renders the summary table
+func (tracker *Tracker) Finish(err error) {
+	if tracker == nil || len(tracker.steps) == 0 {
+		return
+	}
+	if err != nil {
+		tracker.finishRunningStep(failedStatus)
+	} else {
+		tracker.finishRunningStep(succeededStatus)
+	}
+
+	fmt.Fprintln(tracker.out, "")
+	fmt.Fprintln(tracker.out, "Summary:")
+	table := tabwriter.NewWriter(tracker.out, summaryTableMinWidth, summaryTableTabWidth, summaryTablePadding, summaryTablePadChar, 0)
+	fmt.Fprintln(table, "STEP\tSTATUS\tDURATION")
+	totalDuration := time.Duration(0)
+	for _, trackedStep := range tracker.steps {
+		fmt.Fprintf(table, "%s\t%s\t%v\n", trackedStep.name, trackedStep.status, trackedStep.duration.Round(durationRounding))
+		totalDuration += trackedStep.duration
+	}
+	fmt.Fprintf(table, "Total\t\t%v\n", totalDuration.Round(durationRounding))
+	table.Flush()
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (tracker *Tracker) finishRunningStep(status string) {
+	if len(tracker.steps) == 0 {
+		return
+	}
+	runningStep := tracker.steps[len(tracker.steps)-1]
+	if runningStep.status != runningStatus {
+		return
+	}
+	runningStep.status = status
+	runningStep.duration = tracker.now().Sub(runningStep.startTime)
+	roundedDuration := runningStep.duration.Round(durationRounding)
+	if !tracker.isInteractive {
+		fmt.Fprintf(tracker.out, "<== %s %s in %v\n", runningStep.name, status, roundedDuration)
+		return
+	}
+	symbol, colorEscape := succeededSymbol, greenEscape
+	if status == failedStatus {
+		symbol, colorEscape = failedSymbol, redEscape
+	}
+	fmt.Fprintf(tracker.out, "%s%s %s %s in %v%s\n", colorEscape, symbol, runningStep.name, status, roundedDuration, resetEscape)
+}
@@ -0,0 +1,49 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTracker_RendersStepsAndSummary(t *testing.T) {
+	output := &bytes.Buffer{}
+	tracker := NewTracker(output, false)
+	currentTime := time.Unix(0, 0)
+	tracker.now = func() time.Time {
+		return currentTime
+	}
+
+	tracker.StartStep("Checks")
+	currentTime = currentTime.Add(1500 * time.Millisecond)
+	tracker.StartStep("Push")
+	currentTime = currentTime.Add(2 * time.Second)
+	tracker.Finish(errors.New("push rejected"))
+
+	expectedOutput := `==> Step 1: Checks
+<== Checks succeeded in 1.5s
+==> Step 2: Push
+<== Push failed in 2s
+
+Summary:
+STEP    STATUS     DURATION
+Checks  succeeded  1.5s
+Push    failed     2s
+Total              3.5s
+`
+	require.Equal(t, expectedOutput, output.String())
+}
+
+func TestTracker_NilRendersNothing(t *testing.T) {
+	var tracker *Tracker
+	tracker.StartStep("Checks")
+	tracker.Finish(nil)
+}
+
+func TestTracker_FinishWithoutStepsRendersNothing(t *testing.T) {
+	output := &bytes.Buffer{}
+	NewTracker(output, false).Finish(nil)
+	require.Empty(t, output.String())
+}
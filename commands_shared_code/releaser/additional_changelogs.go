@@ -0,0 +1,50 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"path/filepath"
+)
+
+// parseAdditionalChangelogs validates the changelogs kept alongside the main one exactly like it, returning the kinds of
+// change listed under their TBD headers
+func parseAdditionalChangelogs(repoDirpath string, changelogRelFilepaths []string, tbdSkeletonLines []string, rules *bumpRules) ([]*changelogChanges, error) {
+	allChanges := []*changelogChanges{}
+	for _, changelogRelFilepath := range changelogRelFilepaths {
+		changelogFilepath := filepath.Join(repoDirpath, changelogRelFilepath)
+		changelogFile, err := os.ReadFile(changelogFilepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading the additional changelog at '%s'", changelogFilepath)
+		}
+		changelogFile = pruneChangelogTbdSkeleton(changelogFile, tbdSkeletonLines)
+		changes, err := parseChangeLogFile(changelogFile, rules)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "The additional changelog at '%s' is invalid", changelogFilepath)
+		}
+		allChanges = append(allChanges, changes)
+	}
+	return allChanges, nil
+}
+
+// mergeChangelogChanges combines the kinds of change listed across several changelogs, so that a breaking or major
+// change, or a category, in any one of them bumps the version like it would in the main one
+func mergeChangelogChanges(changes *changelogChanges, otherChanges []*changelogChanges) *changelogChanges {
+	mergedChanges := *changes
+	for _, other := range otherChanges {
+		mergedChanges.hasBreakingChange = mergedChanges.hasBreakingChange || other.hasBreakingChange
+		mergedChanges.hasMajorChange = mergedChanges.hasMajorChange || other.hasMajorChange
+		mergedChanges.categories = mergeCategoryChanges(mergedChanges.categories, other.categories)
+	}
+	return &mergedChanges
+}
+
+// updateAdditionalChangelogs finalizes the changelogs kept alongside the main one with the same version header
+func updateAdditionalChangelogs(repoDirpath string, changelogRelFilepaths []string, releaseVersion string, tbdSkeletonLines []string, maxLineLength int) error {
+	for _, changelogRelFilepath := range changelogRelFilepaths {
+		changelogFilepath := filepath.Join(repoDirpath, changelogRelFilepath)
+		if err := updateChangelog(changelogFilepath, releaseVersion, tbdSkeletonLines, maxLineLength); err != nil {
+			return stacktrace.Propagate(err, "An error occurred updating the additional changelog at '%s'", changelogFilepath)
+		}
+	}
+	return nil
+}
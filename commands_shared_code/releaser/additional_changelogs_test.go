@@ -0,0 +1,29 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAdditionalChangelogs_BreakingChangeInAnyCounts(t *testing.T) {
+	repoDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDirpath, "internal"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "internal", "changelog.md"), []byte("# TBD\n### Breaking Changes\n* Renamed an internal API\n\n# 0.1.0\n* Initial\n"), 0644))
+
+	additionalChanges, err := parseAdditionalChangelogs(repoDirpath, []string{"internal/changelog.md"}, nil, nil)
+	require.NoError(t, err)
+	changes := mergeChangelogChanges(&changelogChanges{}, additionalChanges)
+	require.True(t, changes.hasBreakingChange)
+	require.False(t, changes.hasMajorChange)
+}
+
+func TestParseAdditionalChangelogs_EmptyTbdSectionIsInvalid(t *testing.T) {
+	repoDirpath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "internal-changelog.md"), []byte("# TBD\n\n# 0.1.0\n* Initial\n"), 0644))
+
+	_, err := parseAdditionalChangelogs(repoDirpath, []string{"internal-changelog.md"}, nil, nil)
+	require.ErrorContains(t, err, "internal-changelog.md")
+}
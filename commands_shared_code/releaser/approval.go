@@ -0,0 +1,161 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	// How often a release waiting for approval checks its approval issue for a decision
+	defaultApprovalPollInterval = 30 * time.Second
+
+	// What approvers start their comment with to decide on the release, optionally followed by their reasons
+	approveCommand = "/approve"
+	denyCommand    = "/deny"
+
+	approvalIssueTitleFormat = "Approve release '%s'"
+	approvalIssueBodyFormat  = "kudet is waiting to release '%s' until one of %s approves it: comment `%s` to release it, or `%s` to stop it. Nothing has been pushed yet, and the release is stopped if nobody decides within %v."
+)
+
+// approvalPollInterval is a variable so that tests don't have to wait for it
+var approvalPollInterval = defaultApprovalPollInterval
+
+// issueComment is a comment on a forge issue
+type issueComment struct {
+	// The commenter's username on the forge
+	author string
+
+	body string
+}
+
+// waitForApprovalIfNeeded opens an approval issue for the release and holds it back until one of the kudet config's
+// approvers approves it, failing with ErrReleaseNotApproved if one denies it or nobody decides within the timeout
+func (releaser *Releaser) waitForApprovalIfNeeded(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, releaseName string, releaseNotes string, isPushDryRun bool) error {
+	approvalConfig := kudetConfig.Approval
+	if len(approvalConfig.Approvers) == 0 {
+		return nil
+	}
+	// The sandbox's origin is a local stand-in, and nothing that needs signing off leaves the machine
+	if releaser.isSandbox || isPushDryRun {
+		logrus.Infof("Not asking for approval of release '%s', since it won't be pushed", releaseName)
+		return nil
+	}
+	releaser.startStep("Approval")
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the forge to ask for approval of release '%s' on", releaseName)
+	}
+	issueTitle := fmt.Sprintf(approvalIssueTitleFormat, releaseName)
+	issueNumber, issueUrl, err := releaseForge.openApprovalIssue(ctx, issueTitle, getApprovalIssueBody(releaseName, releaseNotes, approvalConfig))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the %s issue asking for approval of release '%s'", releaseForge.getName(), releaseName)
+	}
+	logrus.Infof("Waiting up to %v for one of '%s' to approve release '%s' at %s...", approvalConfig.Timeout, strings.Join(approvalConfig.Approvers, "', '"), releaseName, issueUrl)
+	approver, isApproved, err := waitForApproval(ctx, releaseForge, issueNumber, approvalConfig)
+	if err != nil {
+		closeApprovalIssue(releaseForge, issueNumber, issueUrl, "kudet stopped waiting for approval of this release because of an error, so it wasn't released.")
+		return stacktrace.Propagate(err, "An error occurred waiting for approval of release '%s' at %s", releaseName, issueUrl)
+	}
+	if approver == "" {
+		closeApprovalIssue(releaseForge, issueNumber, issueUrl, fmt.Sprintf("Nobody approved this release within %v, so it wasn't released.", approvalConfig.Timeout))
+		return stacktrace.NewErrorWithCode(ErrReleaseNotApproved.code, "Nobody approved release '%s' at %s within the kudet config's '%s.%s' of %v", releaseName, issueUrl, kudet_config.ApprovalKey, kudet_config.ApprovalTimeoutKey, approvalConfig.Timeout)
+	}
+	if !isApproved {
+		closeApprovalIssue(releaseForge, issueNumber, issueUrl, fmt.Sprintf("@%s denied this release, so it wasn't released.", approver))
+		return stacktrace.NewErrorWithCode(ErrReleaseNotApproved.code, "'%s' denied release '%s' at %s", approver, releaseName, issueUrl)
+	}
+	logrus.Infof("'%s' approved release '%s'", approver, releaseName)
+	closeApprovalIssue(releaseForge, issueNumber, issueUrl, fmt.Sprintf("@%s approved this release; releasing it.", approver))
+	return nil
+}
+
+// waitForApproval polls the approval issue until one of the approvers decides on the release or the timeout passes,
+// returning who decided and whether they approved; nobody decided if the approver is empty
+func waitForApproval(ctx context.Context, releaseForge forge, issueNumber int64, approvalConfig kudet_config.ApprovalConfig) (string, bool, error) {
+	deadline := time.Now().Add(approvalConfig.Timeout)
+	for {
+		comments, err := releaseForge.listIssueComments(ctx, issueNumber)
+		if err != nil {
+			return "", false, stacktrace.Propagate(err, "An error occurred listing the comments on the approval issue")
+		}
+		if approver, isApproved, found := getApprovalDecision(comments, approvalConfig.Approvers); found {
+			return approver, isApproved, nil
+		}
+		untilDeadline := time.Until(deadline)
+		if untilDeadline <= 0 {
+			return "", false, nil
+		}
+		pollInterval := approvalPollInterval
+		if untilDeadline < pollInterval {
+			pollInterval = untilDeadline
+		}
+		select {
+		case <-ctx.Done():
+			return "", false, stacktrace.Propagate(ctx.Err(), "The release was interrupted while waiting for approval")
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getApprovalDecision finds the first comment in which an approver approved or denied the release; everyone else's
+// comments are ignored
+func getApprovalDecision(comments []issueComment, approvers []string) (string, bool, bool) {
+	for _, comment := range comments {
+		if !isApprover(comment.author, approvers) {
+			continue
+		}
+		commentWords := strings.Fields(comment.body)
+		if len(commentWords) == 0 {
+			continue
+		}
+		switch strings.ToLower(commentWords[0]) {
+		case approveCommand:
+			return comment.author, true, true
+		case denyCommand:
+			return comment.author, false, true
+		}
+	}
+	return "", false, false
+}
+
+// isApprover matches forge usernames ignoring case, like the forges do, and with or without the '@' of a mention
+func isApprover(username string, approvers []string) bool {
+	for _, approver := range approvers {
+		if strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(approver), "@"), username) {
+			return true
+		}
+	}
+	return false
+}
+
+func getApprovalIssueBody(releaseName string, releaseNotes string, approvalConfig kudet_config.ApprovalConfig) string {
+	mentions := []string{}
+	for _, approver := range approvalConfig.Approvers {
+		mentions = append(mentions, "@"+strings.TrimPrefix(strings.TrimSpace(approver), "@"))
+	}
+	body := fmt.Sprintf(approvalIssueBodyFormat, releaseName, strings.Join(mentions, ", "), approveCommand, denyCommand, approvalConfig.Timeout)
+	if strings.TrimSpace(releaseNotes) != "" {
+		body += "\n\n## Release notes\n\n" + releaseNotes
+	}
+	return body
+}
+
+// closeApprovalIssue closes the issue once the release no longer waits on it; it isn't worth failing the release over,
+// so failures are only logged
+func closeApprovalIssue(releaseForge forge, issueNumber int64, issueUrl string, comment string) {
+	// The release's context may have been cancelled, which is when the issue most needs closing
+	if err := releaseForge.closeIssue(context.Background(), issueNumber, comment); err != nil {
+		logrus.Warnf("An error occurred closing approval issue %s; close it by hand: %v", issueUrl, err)
+	}
+}
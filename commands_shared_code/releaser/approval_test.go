@@ -0,0 +1,120 @@
+package releaser
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetApprovalDecision(t *testing.T) {
+	approvers := []string{"@Octocat", "hubot"}
+	_, _, found := getApprovalDecision([]issueComment{{author: "mallory", body: "/approve"}, {author: "hubot", body: "LGTM"}}, approvers)
+	require.False(t, found)
+
+	approver, isApproved, found := getApprovalDecision([]issueComment{{author: "octocat", body: "  /APPROVE ship it"}, {author: "hubot", body: "/deny"}}, approvers)
+	require.True(t, found)
+	require.True(t, isApproved)
+	require.Equal(t, "octocat", approver)
+
+	approver, isApproved, found = getApprovalDecision([]issueComment{{author: "hubot", body: "/deny the tests are flaky"}}, approvers)
+	require.True(t, found)
+	require.False(t, isApproved)
+	require.Equal(t, "hubot", approver)
+}
+
+func TestWaitForApprovalIfNeeded(t *testing.T) {
+	lock := &sync.Mutex{}
+	comments := []map[string]interface{}{}
+	commentListCount := 0
+	closingComments := []string{}
+	isClosed := false
+	githubApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/issues":
+			issueRequest := &githubIssueRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(issueRequest))
+			require.Equal(t, "Approve release '0.2.0'", issueRequest.Title)
+			require.Contains(t, issueRequest.Body, "@octocat")
+			require.Contains(t, issueRequest.Body, "* Added the widget")
+			_, err := w.Write([]byte(`{"number": 7, "html_url": "https://github.com/owner/repo/issues/7"}`))
+			require.NoError(t, err)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/issues/7/comments":
+			// The approver only gets round to it after a couple of polls
+			commentListCount++
+			if commentListCount == 2 {
+				comments = append(comments, map[string]interface{}{"user": map[string]string{"login": "mallory"}, "body": "/approve"})
+			}
+			if commentListCount == 3 {
+				comments = append(comments, map[string]interface{}{"user": map[string]string{"login": "octocat"}, "body": "/approve"})
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(comments))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/issues/7/comments":
+			commentRequest := &githubIssueCommentRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(commentRequest))
+			closingComments = append(closingComments, commentRequest.Body)
+			_, err := w.Write([]byte(`{}`))
+			require.NoError(t, err)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/owner/repo/issues/7":
+			isClosed = true
+			_, err := w.Write([]byte(`{}`))
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer githubApi.Close()
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{"https://github.com/owner/repo.git"}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.Forge = kudet_config.ForgeConfig{Type: kudet_config.GithubForgeType, ApiUrl: githubApi.URL}
+	releaser := NewReleaser(repoDirpath, "token")
+
+	originalPollInterval := approvalPollInterval
+	approvalPollInterval = 10 * time.Millisecond
+	defer func() {
+		approvalPollInterval = originalPollInterval
+	}()
+
+	// Nothing is asked for without approvers, or when nothing will be pushed
+	require.NoError(t, releaser.waitForApprovalIfNeeded(context.Background(), repository, kudetConfig, "0.2.0", "* Added the widget", false))
+	kudetConfig.Approval.Approvers = []string{"octocat"}
+	require.NoError(t, releaser.waitForApprovalIfNeeded(context.Background(), repository, kudetConfig, "0.2.0", "* Added the widget", true))
+	require.Zero(t, commentListCount)
+
+	require.NoError(t, releaser.waitForApprovalIfNeeded(context.Background(), repository, kudetConfig, "0.2.0", "* Added the widget", false))
+	require.Equal(t, 3, commentListCount)
+	require.Equal(t, []string{"@octocat approved this release; releasing it."}, closingComments)
+	require.True(t, isClosed)
+
+	// Only mallory has weighed in, who isn't an approver
+	lock.Lock()
+	comments = comments[:1]
+	commentListCount = 3
+	closingComments = []string{}
+	lock.Unlock()
+	kudetConfig.Approval.Timeout = 50 * time.Millisecond
+	err = releaser.waitForApprovalIfNeeded(context.Background(), repository, kudetConfig, "0.2.0", "* Added the widget", false)
+	require.Error(t, err)
+	releaseErr, found := GetReleaseError(err)
+	require.True(t, found)
+	require.Equal(t, ErrReleaseNotApproved, releaseErr)
+	require.Len(t, closingComments, 1)
+	require.Contains(t, closingComments[0], "Nobody approved this release")
+}
@@ -0,0 +1,319 @@
+package releaser
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// Stands in for the version in the names of artifacts and the files inside them, so that the same artifact of
+	// different releases is recognized, e.g. 'kudet_{version}_linux_amd64.tar.gz'
+	artifactVersionPlaceholder = "{version}"
+
+	// Smaller artifacts, e.g. checksum files, routinely change size drastically without anything being wrong
+	minArtifactSizeToCompare = 1024
+
+	bytesPerKibibyte = 1024
+)
+
+// artifactDiffReport is how the artifacts of a release differ from those of the previous release, which goes in the
+// release's metadata
+type artifactDiffReport struct {
+	PreviousVersion string `json:"previousVersion"`
+	// Every difference, e.g. an artifact that was added or changed size
+	Changes []string `json:"changes"`
+	// The differences that suggest the build went wrong, e.g. a missing platform build or a binary that doubled in size
+	Anomalies []string `json:"anomalies,omitempty"`
+}
+
+// artifactManifest is what's compared of an artifact: its size, the files inside it if it's an archive, and whether it
+// has its release's version in it, e.g. stamped into a binary
+type artifactManifest struct {
+	name string
+	size int
+
+	isArchive bool
+	// Slash-separated, with the release's version replaced by the placeholder
+	archivedFilepaths []string
+
+	embedsVersion bool
+}
+
+// diffReleaseArtifacts compares the artifacts collected for the release with the assets of the previous release's forge
+// release, besides its metadata. The diff is only there to inform, so previous assets that can't be fetched are logged
+// and nil is returned; it's up to the caller what to do about any anomalies.
+func (releaser *Releaser) diffReleaseArtifacts(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, artifactFilepaths []string, previousVersion string, version string) (*artifactDiffReport, error) {
+	manifests := []*artifactManifest{}
+	for _, artifactFilepath := range artifactFilepaths {
+		artifactContents, err := os.ReadFile(artifactFilepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading artifact '%s'", artifactFilepath)
+		}
+		manifest, err := newArtifactManifest(filepath.Base(artifactFilepath), artifactContents, version)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading the contents of artifact '%s'", artifactFilepath)
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		logrus.Warnf("Not comparing the release's artifacts with release '%s''s; an error occurred determining the forge they're on: %v", previousVersion, err)
+		return nil, nil
+	}
+	if !releaseForge.supportsReleaseAssets() {
+		logrus.Warnf("Not comparing the release's artifacts with release '%s''s, as %s doesn't support release assets", previousVersion, releaseForge.getName())
+		return nil, nil
+	}
+	previousAssets, err := releaseForge.getReleaseFileAssets(ctx, previousVersion)
+	if err != nil {
+		logrus.Warnf("Not comparing the release's artifacts with release '%s''s; an error occurred getting its assets: %v", previousVersion, err)
+		return nil, nil
+	}
+	delete(previousAssets, releaseMetadataFilename)
+	if len(previousAssets) == 0 {
+		logrus.Infof("Release '%s' has no assets to compare the release's artifacts with", previousVersion)
+		return nil, nil
+	}
+	previousManifests := []*artifactManifest{}
+	for assetName, assetContents := range previousAssets {
+		manifest, err := newArtifactManifest(assetName, assetContents, previousVersion)
+		if err != nil {
+			logrus.Warnf("Not comparing the release's artifacts with release '%s''s; an error occurred reading the contents of its asset '%s': %v", previousVersion, assetName, err)
+			return nil, nil
+		}
+		previousManifests = append(previousManifests, manifest)
+	}
+	return diffArtifacts(previousManifests, manifests, previousVersion, version, kudetConfig.ArtifactDiff.MaxSizeRatio), nil
+}
+
+// newArtifactManifest describes the artifact of the given release version; archives are recognized by their extension
+func newArtifactManifest(name string, contents []byte, version string) (*artifactManifest, error) {
+	manifest := &artifactManifest{
+		name:              name,
+		size:              len(contents),
+		isArchive:         false,
+		archivedFilepaths: nil,
+		embedsVersion:     bytes.Contains(contents, []byte(version)),
+	}
+	archivedFiles, isArchive, err := readArchivedFiles(name, contents)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading archive '%s'", name)
+	}
+	if !isArchive {
+		return manifest, nil
+	}
+	manifest.isArchive = true
+	manifest.archivedFilepaths = []string{}
+	for archivedFilepath, archivedFileContents := range archivedFiles {
+		manifest.archivedFilepaths = append(manifest.archivedFilepaths, strings.ReplaceAll(archivedFilepath, version, artifactVersionPlaceholder))
+		// Compressed archives hide the version of the binaries inside them
+		if bytes.Contains(archivedFileContents, []byte(version)) {
+			manifest.embedsVersion = true
+		}
+	}
+	sort.Strings(manifest.archivedFilepaths)
+	return manifest, nil
+}
+
+// diffArtifacts matches up the artifacts of the two releases by their names, with the versions left out, and reports how
+// they differ. Anomalies are artifacts that weren't built again, ones whose size changed by at least the max ratio,
+// archives that lost files, and artifacts that no longer have their release's version in them when they used to.
+func diffArtifacts(previousManifests []*artifactManifest, manifests []*artifactManifest, previousVersion string, version string, maxSizeRatio float64) *artifactDiffReport {
+	previousManifestsByKey := map[string]*artifactManifest{}
+	for _, manifest := range previousManifests {
+		previousManifestsByKey[strings.ReplaceAll(manifest.name, previousVersion, artifactVersionPlaceholder)] = manifest
+	}
+	manifestsByKey := map[string]*artifactManifest{}
+	for _, manifest := range manifests {
+		manifestsByKey[strings.ReplaceAll(manifest.name, version, artifactVersionPlaceholder)] = manifest
+	}
+	keys := []string{}
+	for key := range previousManifestsByKey {
+		keys = append(keys, key)
+	}
+	for key := range manifestsByKey {
+		if _, found := previousManifestsByKey[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	report := &artifactDiffReport{
+		PreviousVersion: previousVersion,
+		Changes:         []string{},
+		Anomalies:       nil,
+	}
+	for _, key := range keys {
+		previousManifest, hasPrevious := previousManifestsByKey[key]
+		manifest, hasCurrent := manifestsByKey[key]
+		if !hasCurrent {
+			report.Changes = append(report.Changes, fmt.Sprintf("Removed '%s'", previousManifest.name))
+			report.Anomalies = append(report.Anomalies, fmt.Sprintf("'%s' of release '%s' wasn't built for this release", previousManifest.name, previousVersion))
+			continue
+		}
+		if !hasPrevious {
+			report.Changes = append(report.Changes, fmt.Sprintf("Added '%s' (%s)", manifest.name, formatArtifactSize(manifest.size)))
+			continue
+		}
+
+		if manifest.size != previousManifest.size {
+			report.Changes = append(report.Changes, fmt.Sprintf("'%s' went from %s to %s", manifest.name, formatArtifactSize(previousManifest.size), formatArtifactSize(manifest.size)))
+			smallerSize, biggerSize := previousManifest.size, manifest.size
+			if smallerSize > biggerSize {
+				smallerSize, biggerSize = biggerSize, smallerSize
+			}
+			if biggerSize >= minArtifactSizeToCompare && float64(biggerSize) >= maxSizeRatio*float64(smallerSize) {
+				report.Anomalies = append(report.Anomalies, fmt.Sprintf("'%s' went from %s in release '%s' to %s, a change of at least the kudet config's max size ratio of %v", manifest.name, formatArtifactSize(previousManifest.size), previousVersion, formatArtifactSize(manifest.size), maxSizeRatio))
+			}
+		}
+		if previousManifest.isArchive && manifest.isArchive {
+			addedFilepaths, removedFilepaths := diffStrings(previousManifest.archivedFilepaths, manifest.archivedFilepaths)
+			if len(addedFilepaths) > 0 {
+				report.Changes = append(report.Changes, fmt.Sprintf("'%s' gained files '%s'", manifest.name, strings.Join(addedFilepaths, "', '")))
+			}
+			if len(removedFilepaths) > 0 {
+				report.Changes = append(report.Changes, fmt.Sprintf("'%s' lost files '%s'", manifest.name, strings.Join(removedFilepaths, "', '")))
+				report.Anomalies = append(report.Anomalies, fmt.Sprintf("'%s' no longer has files '%s' that it had in release '%s'", manifest.name, strings.Join(removedFilepaths, "', '"), previousVersion))
+			}
+		}
+		if previousManifest.embedsVersion && !manifest.embedsVersion {
+			report.Changes = append(report.Changes, fmt.Sprintf("'%s' no longer has its version in it", manifest.name))
+			report.Anomalies = append(report.Anomalies, fmt.Sprintf("'%s' doesn't have version '%s' in it, though it had version '%s' in it in release '%s'", manifest.name, version, previousVersion, previousVersion))
+		}
+	}
+	return report
+}
+
+// log lists the changes, and warns about the anomalies
+func (report *artifactDiffReport) log() {
+	if len(report.Changes) == 0 {
+		logrus.Infof("The release's artifacts are the same as release '%s''s", report.PreviousVersion)
+		return
+	}
+	logrus.Infof("The release's artifacts differ from release '%s''s:\n%s", report.PreviousVersion, "- "+strings.Join(report.Changes, "\n- "))
+	for _, anomaly := range report.Anomalies {
+		logrus.Warnf("Artifact anomaly: %s", anomaly)
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// readArchivedFiles reads the regular files in the artifact if it's a tarball or a zip, keyed by their slash-separated
+// paths; false means it isn't an archive
+func readArchivedFiles(name string, contents []byte) (map[string][]byte, bool, error) {
+	lowerCaseName := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lowerCaseName, ".zip"):
+		files, err := readZipFiles(contents)
+		return files, true, err
+	case strings.HasSuffix(lowerCaseName, ".tar.gz"), strings.HasSuffix(lowerCaseName, ".tgz"):
+		gzipReader, err := gzip.NewReader(bytes.NewReader(contents))
+		if err != nil {
+			return nil, true, stacktrace.Propagate(err, "An error occurred decompressing the tarball")
+		}
+		defer gzipReader.Close()
+		files, err := readTarFiles(gzipReader)
+		return files, true, err
+	case strings.HasSuffix(lowerCaseName, ".tar"):
+		files, err := readTarFiles(bytes.NewReader(contents))
+		return files, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+func readTarFiles(tarballReader io.Reader) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	tarReader := tar.NewReader(tarballReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading the tarball")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		fileContents, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading '%s' in the tarball", header.Name)
+		}
+		files[strings.TrimPrefix(header.Name, "./")] = fileContents
+	}
+}
+
+func readZipFiles(contents []byte) (map[string][]byte, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(contents), int64(len(contents)))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the zip")
+	}
+	files := map[string][]byte{}
+	for _, zipFile := range zipReader.File {
+		if !zipFile.Mode().IsRegular() {
+			continue
+		}
+		fileReader, err := zipFile.Open()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred opening '%s' in the zip", zipFile.Name)
+		}
+		fileContents, err := io.ReadAll(fileReader)
+		fileReader.Close()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading '%s' in the zip", zipFile.Name)
+		}
+		files[zipFile.Name] = fileContents
+	}
+	return files, nil
+}
+
+// diffStrings returns what's only in the new strings, and what's only in the old ones
+func diffStrings(oldStrs []string, newStrs []string) ([]string, []string) {
+	isOld := map[string]bool{}
+	for _, oldStr := range oldStrs {
+		isOld[oldStr] = true
+	}
+	isNew := map[string]bool{}
+	addedStrs := []string{}
+	for _, newStr := range newStrs {
+		isNew[newStr] = true
+		if !isOld[newStr] {
+			addedStrs = append(addedStrs, newStr)
+		}
+	}
+	removedStrs := []string{}
+	for _, oldStr := range oldStrs {
+		if !isNew[oldStr] {
+			removedStrs = append(removedStrs, oldStr)
+		}
+	}
+	return addedStrs, removedStrs
+}
+
+func formatArtifactSize(size int) string {
+	if size < bytesPerKibibyte {
+		return fmt.Sprintf("%d B", size)
+	}
+	if size < bytesPerKibibyte*bytesPerKibibyte {
+		return fmt.Sprintf("%.1f KiB", float64(size)/bytesPerKibibyte)
+	}
+	return fmt.Sprintf("%.1f MiB", float64(size)/(bytesPerKibibyte*bytesPerKibibyte))
+}
@@ -0,0 +1,135 @@
+package releaser
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewArtifactManifest(t *testing.T) {
+	tarball := newTestTarball(t, map[string]string{"./kudet": "kudet version 0.2.0", "README.md": "Kudet"})
+	manifest, err := newArtifactManifest("kudet_0.2.0_linux_amd64.tar.gz", tarball, "0.2.0")
+	require.NoError(t, err)
+	require.True(t, manifest.isArchive)
+	require.Equal(t, []string{"README.md", "kudet"}, manifest.archivedFilepaths)
+	require.True(t, manifest.embedsVersion)
+	require.Equal(t, len(tarball), manifest.size)
+
+	manifest, err = newArtifactManifest("checksums.txt", []byte("abc  kudet_0.1.0_linux_amd64.tar.gz\n"), "0.2.0")
+	require.NoError(t, err)
+	require.False(t, manifest.isArchive)
+	require.False(t, manifest.embedsVersion)
+
+	_, err = newArtifactManifest("kudet.tar.gz", []byte("not a tarball"), "0.2.0")
+	require.Error(t, err)
+}
+
+func TestDiffArtifacts(t *testing.T) {
+	previousManifests := []*artifactManifest{
+		{name: "kudet_0.1.0_linux_amd64.tar.gz", size: 10 * 1024 * 1024, isArchive: true, archivedFilepaths: []string{"README.md", "kudet"}, embedsVersion: true},
+		{name: "kudet_0.1.0_darwin_arm64.tar.gz", size: 10 * 1024 * 1024, isArchive: true, archivedFilepaths: []string{"kudet"}, embedsVersion: true},
+		{name: "kudet_0.1.0_windows_amd64.zip", size: 10 * 1024 * 1024, isArchive: true, archivedFilepaths: []string{"kudet.exe"}, embedsVersion: true},
+		{name: "checksums.txt", size: 100, embedsVersion: true},
+	}
+	manifests := []*artifactManifest{
+		{name: "kudet_0.2.0_linux_amd64.tar.gz", size: 21 * 1024 * 1024, isArchive: true, archivedFilepaths: []string{"LICENSE", "kudet"}, embedsVersion: true},
+		{name: "kudet_0.2.0_darwin_arm64.tar.gz", size: 10 * 1024 * 1024, isArchive: true, archivedFilepaths: []string{"kudet"}, embedsVersion: false},
+		{name: "checksums.txt", size: 300, embedsVersion: true},
+		{name: "sbom.json", size: 2048, embedsVersion: true},
+	}
+	report := diffArtifacts(previousManifests, manifests, "0.1.0", "0.2.0", 2)
+	require.Equal(t, &artifactDiffReport{
+		PreviousVersion: "0.1.0",
+		Changes: []string{
+			"'checksums.txt' went from 100 B to 300 B",
+			"'kudet_0.2.0_darwin_arm64.tar.gz' no longer has its version in it",
+			"'kudet_0.2.0_linux_amd64.tar.gz' went from 10.0 MiB to 21.0 MiB",
+			"'kudet_0.2.0_linux_amd64.tar.gz' gained files 'LICENSE'",
+			"'kudet_0.2.0_linux_amd64.tar.gz' lost files 'README.md'",
+			"Removed 'kudet_0.1.0_windows_amd64.zip'",
+			"Added 'sbom.json' (2.0 KiB)",
+		},
+		Anomalies: []string{
+			"'kudet_0.2.0_darwin_arm64.tar.gz' doesn't have version '0.2.0' in it, though it had version '0.1.0' in it in release '0.1.0'",
+			"'kudet_0.2.0_linux_amd64.tar.gz' went from 10.0 MiB in release '0.1.0' to 21.0 MiB, a change of at least the kudet config's max size ratio of 2",
+			"'kudet_0.2.0_linux_amd64.tar.gz' no longer has files 'README.md' that it had in release '0.1.0'",
+			"'kudet_0.1.0_windows_amd64.zip' of release '0.1.0' wasn't built for this release",
+		},
+	}, report)
+
+	report = diffArtifacts(previousManifests[:1], []*artifactManifest{{name: "kudet_0.2.0_linux_amd64.tar.gz", size: 10 * 1024 * 1024, isArchive: true, archivedFilepaths: []string{"README.md", "kudet"}, embedsVersion: true}}, "0.1.0", "0.2.0", 2)
+	require.Empty(t, report.Changes)
+	require.Empty(t, report.Anomalies)
+}
+
+func TestDiffReleaseArtifacts(t *testing.T) {
+	previousTarball := newTestTarball(t, map[string]string{"kudet": "kudet version 0.1.0"})
+	var githubApiUrl string
+	githubApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases/tags/0.1.0":
+			_, err := w.Write([]byte(`{"id": 1, "assets": [{"name": "kudet_0.1.0_linux_amd64.tar.gz", "url": "` + githubApiUrl + `/repos/owner/repo/releases/assets/2"}, {"name": "release-metadata.json", "url": "` + githubApiUrl + `/repos/owner/repo/releases/assets/3"}]}`))
+			require.NoError(t, err)
+		case "/repos/owner/repo/releases/assets/2":
+			require.Equal(t, githubFileAssetContentType, r.Header.Get("Accept"))
+			_, err := w.Write(previousTarball)
+			require.NoError(t, err)
+		case "/repos/owner/repo/releases/assets/3":
+			_, err := w.Write([]byte(`{"version": "0.1.0"}`))
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer githubApi.Close()
+	githubApiUrl = githubApi.URL
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{"https://github.com/owner/repo.git"}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.Forge = kudet_config.ForgeConfig{Type: kudet_config.GithubForgeType, ApiUrl: githubApi.URL}
+	artifactsDirpath := t.TempDir()
+	artifactFilepath := filepath.Join(artifactsDirpath, "kudet_0.2.0_linux_amd64.tar.gz")
+	require.NoError(t, os.WriteFile(artifactFilepath, newTestTarball(t, map[string]string{"kudet": "kudet version " + strings.Repeat("0", 4096)}), 0644))
+	releaser := NewReleaser(repoDirpath, "token")
+
+	report, err := releaser.diffReleaseArtifacts(context.Background(), repository, kudetConfig, []string{artifactFilepath}, "0.1.0", "0.2.0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"'kudet_0.2.0_linux_amd64.tar.gz' doesn't have version '0.2.0' in it, though it had version '0.1.0' in it in release '0.1.0'"}, report.Anomalies)
+
+	// A previous release that can't be found leaves nothing to compare with
+	report, err = releaser.diffReleaseArtifacts(context.Background(), repository, kudetConfig, []string{artifactFilepath}, "0.0.9", "0.2.0")
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+func newTestTarball(t *testing.T, files map[string]string) []byte {
+	tarball := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(tarball)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, contents := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	return tarball.Bytes()
+}
@@ -0,0 +1,189 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	auditLogContentType    = "application/json"
+	auditLogRequestTimeout = 10 * time.Second
+)
+
+// AuditRecord is the JSON body POSTed to the audit log for each release, either by the release itself or when replaying
+// the releases made before the audit log was configured
+type AuditRecord struct {
+	Version         string `json:"version"`
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	CommitHash      string `json:"commitHash"`
+	// In RFC 3339 format and UTC
+	ReleasedAt string `json:"releasedAt"`
+	// Who released, as 'Name <email>', if it's known
+	ReleasedBy   string `json:"releasedBy,omitempty"`
+	KudetVersion string `json:"kudetVersion,omitempty"`
+	BumpReason   string `json:"bumpReason,omitempty"`
+	ReleaseNotes string `json:"releaseNotes,omitempty"`
+	// Whether the record was rebuilt from the repo's history rather than sent by the release, in which case whatever the
+	// history doesn't have is left out
+	IsReplayed bool `json:"isReplayed,omitempty"`
+}
+
+// recordReleaseInAuditLog sends the release's audit record to the audit log if one is configured; the release is
+// irreversible by the time it runs, so failures are only logged
+func recordReleaseInAuditLog(ctx context.Context, auditLogConfig kudet_config.AuditLogConfig, state *releaseState) {
+	if auditLogConfig.Url == "" {
+		return
+	}
+	logrus.Infof("Recording release '%s' in the audit log...", state.Version)
+	record := newAuditRecord(state, time.Now())
+	if _, err := ImportAuditRecords(ctx, auditLogConfig, []*AuditRecord{record}); err != nil {
+		exportCmd := "kudet audit export"
+		if state.PreviousVersion != "" {
+			exportCmd += " --since " + state.PreviousVersion
+		}
+		logrus.Errorf("ACTION REQUIRED: An error occurred recording release '%s' in the audit log; the release itself succeeded. Once the audit log is fixed, replay it with '%s | kudet audit import -':\n%v", state.Version, exportCmd, err)
+		return
+	}
+	logrus.Infof("Recorded release '%s' in the audit log", state.Version)
+}
+
+// ExportAuditRecords rebuilds the audit records of the repo's past releases, oldest first, from their tags, their audit
+// notes under refs/notes/kudet and the changelog at their release commits; prereleases and releases that the filter
+// leaves out aren't exported
+func ExportAuditRecords(repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, filter ReleaseListFilter) ([]*AuditRecord, error) {
+	releases, err := ListReleases(repository, kudetConfig.TagParsing, filter)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the repo's releases")
+	}
+	// The previous version is the one released before, whether or not the filter exports it
+	allReleases, err := ListReleases(repository, kudetConfig.TagParsing, ReleaseListFilter{ShouldIncludePrereleases: filter.ShouldIncludePrereleases})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the repo's releases")
+	}
+	previousVersions := map[string]string{}
+	for idx := 0; idx+1 < len(allReleases); idx++ {
+		previousVersions[allReleases[idx].Version] = allReleases[idx+1].Version
+	}
+
+	records := []*AuditRecord{}
+	for idx := len(releases) - 1; idx >= 0; idx-- {
+		record, err := getReplayedAuditRecord(repository, kudetConfig.ChangelogFilepath, releases[idx], previousVersions[releases[idx].Version])
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred rebuilding the audit record of release '%s'", releases[idx].Version)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// ImportAuditRecords sends the audit records to the audit log in order, stopping at the first that it doesn't accept;
+// it returns how many were sent, so that the rest can be replayed once the audit log is fixed
+func ImportAuditRecords(ctx context.Context, auditLogConfig kudet_config.AuditLogConfig, records []*AuditRecord) (int, error) {
+	if auditLogConfig.Url == "" {
+		return 0, stacktrace.NewError("No audit log is configured under '%s.%s' in the kudet config", kudet_config.AuditLogKey, kudet_config.AuditLogUrlKey)
+	}
+	token := ""
+	if auditLogConfig.TokenEnvVar != "" {
+		token = os.Getenv(auditLogConfig.TokenEnvVar)
+		if token == "" {
+			return 0, stacktrace.NewError("The audit log needs a token, but '%s' isn't set", auditLogConfig.TokenEnvVar)
+		}
+	}
+	httpClient := &http.Client{Timeout: auditLogRequestTimeout}
+	for idx, record := range records {
+		if err := sendAuditRecord(ctx, httpClient, auditLogConfig.Url, token, record); err != nil {
+			return idx, stacktrace.Propagate(err, "An error occurred sending the audit record of release '%s' to '%s'", record.Version, auditLogConfig.Url)
+		}
+	}
+	return len(records), nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newAuditRecord(state *releaseState, releasedAt time.Time) *AuditRecord {
+	return &AuditRecord{
+		Version:         state.Version,
+		PreviousVersion: state.PreviousVersion,
+		CommitHash:      state.ReleaseCommitHash,
+		ReleasedAt:      releasedAt.UTC().Format(time.RFC3339),
+		ReleasedBy:      state.ReleasedBy,
+		KudetVersion:    kudet_version.KudetVersion,
+		BumpReason:      state.BumpReason,
+		ReleaseNotes:    state.ReleaseNotes,
+	}
+}
+
+// getReplayedAuditRecord prefers what the release's audit note recorded, falling back on the release commit's date for
+// when it was released
+func getReplayedAuditRecord(repository vcs.Repository, changelogRelFilepath string, release ReleaseListing, previousVersion string) (*AuditRecord, error) {
+	record := &AuditRecord{
+		Version: release.Version,
+		// Empty for the repo's first release
+		PreviousVersion: previousVersion,
+		CommitHash:      release.CommitHash,
+		ReleasedAt:      release.Date.UTC().Format(time.RFC3339),
+		IsReplayed:      true,
+	}
+	note, found, err := repository.ReadNote(auditNotesRefName, release.CommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the audit note of release commit '%s'", release.CommitHash)
+	}
+	if found {
+		noteValues := parseReleaseAuditNote(note)
+		if releasedAt, err := time.Parse(time.RFC3339, noteValues[auditNoteReleasedAtKey]); err == nil {
+			record.ReleasedAt = releasedAt.UTC().Format(time.RFC3339)
+		}
+		record.ReleasedBy = noteValues[auditNoteReleasedByKey]
+		record.KudetVersion = noteValues[auditNoteKudetVersionKey]
+		record.BumpReason = noteValues[auditNoteBumpReasonKey]
+		// The audit note knows the previous version even if its tags have since been deleted
+		if notePreviousVersion := noteValues[auditNotePreviousVersionKey]; notePreviousVersion != "" {
+			record.PreviousVersion = notePreviousVersion
+		}
+	}
+	changelogFile, found, err := repository.ReadFileAtCommit(release.CommitHash, changelogRelFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading changelog '%s' at release commit '%s'", changelogRelFilepath, release.CommitHash)
+	}
+	if found {
+		record.ReleaseNotes = strings.Join(getVersionNotesLines(changelogFile, release.Version), "\n")
+	}
+	return record, nil
+}
+
+func sendAuditRecord(ctx context.Context, httpClient *http.Client, auditLogUrl string, token string, record *AuditRecord) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the audit record")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, auditLogUrl, bytes.NewReader(recordBytes))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred building the audit record request")
+	}
+	req.Header.Set("Content-Type", auditLogContentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred POSTing the audit record")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return stacktrace.NewError("The audit log responded with unexpected status '%s'", resp.Status)
+	}
+	return nil
+}
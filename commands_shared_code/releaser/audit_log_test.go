@@ -0,0 +1,90 @@
+package releaser
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportAuditRecords(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	author := &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)}
+	release := func(version string, changelog string) string {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte(changelog), 0644))
+		commitHash, err := repository.CommitAll("Release "+version, author)
+		require.NoError(t, err)
+		require.NoError(t, repository.CreateTag(version, commitHash, version))
+		return commitHash
+	}
+	firstReleaseCommitHash := release("0.1.0", "# TBD\n\n# 0.1.0\n* Initial\n")
+	secondReleaseCommitHash := release("0.2.0", "# TBD\n\n# 0.2.0\n### Breaking changes\n* Renamed a flag\n\n# 0.1.0\n* Initial\n")
+	// Only releases made since audit notes were introduced have one
+	state := &releaseState{Version: "0.2.0", PreviousVersion: "0.1.0", ReleasedBy: "Kudet <kudet@example.com>", BumpReason: "The TBD section has a breaking changes subheader, which bumps the minor version"}
+	require.NoError(t, repository.AddNote(auditNotesRefName, secondReleaseCommitHash, getReleaseAuditNote(state, time.Date(2022, 6, 3, 9, 30, 0, 0, time.UTC)), author))
+
+	kudetConfig := &kudet_config.KudetConfig{ChangelogFilepath: "changelog.md"}
+	records, err := ExportAuditRecords(repository, kudetConfig, ReleaseListFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, &AuditRecord{
+		Version:      "0.1.0",
+		CommitHash:   firstReleaseCommitHash,
+		ReleasedAt:   "2022-06-01T12:00:00Z",
+		ReleaseNotes: "* Initial",
+		IsReplayed:   true,
+	}, records[0])
+	require.Equal(t, "0.1.0", records[1].PreviousVersion)
+	require.Equal(t, "2022-06-03T09:30:00Z", records[1].ReleasedAt)
+	require.Equal(t, "Kudet <kudet@example.com>", records[1].ReleasedBy)
+	require.Equal(t, state.BumpReason, records[1].BumpReason)
+	require.Equal(t, "### Breaking changes\n* Renamed a flag", records[1].ReleaseNotes)
+
+	// The previous version of the first exported release is still the one released before it
+	records, err = ExportAuditRecords(repository, kudetConfig, ReleaseListFilter{Since: "0.1.0"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, "0.1.0", records[0].PreviousVersion)
+}
+
+func TestImportAuditRecords(t *testing.T) {
+	receivedVersions := []string{}
+	auditLog := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record := &AuditRecord{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(record))
+		if record.Version == "0.3.0" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		receivedVersions = append(receivedVersions, record.Version)
+	}))
+	defer auditLog.Close()
+
+	t.Setenv("AUDIT_LOG_TOKEN", "secret")
+	auditLogConfig := kudet_config.AuditLogConfig{Url: auditLog.URL, TokenEnvVar: "AUDIT_LOG_TOKEN"}
+	records := []*AuditRecord{{Version: "0.1.0"}, {Version: "0.2.0"}, {Version: "0.3.0"}, {Version: "0.4.0"}}
+	numImported, err := ImportAuditRecords(context.Background(), auditLogConfig, records)
+	require.ErrorContains(t, err, "release '0.3.0'")
+	require.Equal(t, 2, numImported)
+	require.Equal(t, []string{"0.1.0", "0.2.0"}, receivedVersions)
+
+	_, err = ImportAuditRecords(context.Background(), kudet_config.AuditLogConfig{}, records)
+	require.ErrorContains(t, err, "No audit log is configured")
+}
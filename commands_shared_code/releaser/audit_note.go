@@ -0,0 +1,113 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	// The notes ref that each release commit gets an audit note under, queryable with
+	// 'git notes --ref kudet show <tag>^{commit}'
+	auditNotesRefShortName = "kudet"
+	auditNotesRefName      = vcs.NotesRefPrefix + auditNotesRefShortName
+
+	requestedMajorBumpReason = "A major version bump was requested"
+	interactiveBumpReason    = "The version was chosen in the interactive release"
+
+	// The keys of the audit note's trailer-style 'Key: value' lines
+	auditNoteVersionKey         = "Version"
+	auditNotePreviousVersionKey = "Previous-version"
+	auditNoteReleasedByKey      = "Released-by"
+	auditNoteReleasedAtKey      = "Released-at"
+	auditNoteKudetVersionKey    = "Kudet-version"
+	auditNoteBumpReasonKey      = "Bump-reason"
+	auditNoteKeyValueSeparator  = ": "
+)
+
+// recordReleaseAuditNote attaches who released the release, when, with which kudet, and why its version was bumped the
+// way it was, to the release commit as a git note, and pushes the notes ref without forcing it so that past notes can't
+// be rewritten; the release is irreversible by the time it runs, so failures are only logged
+func recordReleaseAuditNote(ctx context.Context, repository vcs.Repository, state *releaseState) {
+	author, err := repository.GetAuthor()
+	if err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred getting the author to record the audit note of release '%s' as; the release itself succeeded:\n%v", state.Version, err)
+		return
+	}
+	author.When = time.Now()
+	logrus.Infof("Recording the audit note of release '%s' under '%s'...", state.Version, auditNotesRefName)
+	if err := addAndPushReleaseAuditNote(ctx, repository, state, author); err != nil {
+		logrus.Errorf(
+			"ACTION REQUIRED: An error occurred recording the audit note of release '%s'; the release itself succeeded. If notes were pushed from elsewhere in the meantime, merge them with 'git fetch %s %s:%s-remote && git notes --ref %s merge %s-remote', then push them with 'git push %s %s':\n%v",
+			state.Version,
+			originRemoteName,
+			auditNotesRefName,
+			auditNotesRefName,
+			auditNotesRefShortName,
+			auditNotesRefShortName,
+			originRemoteName,
+			auditNotesRefName,
+			err,
+		)
+		return
+	}
+	logrus.Infof("Recorded the audit note of release '%s'", state.Version)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func addAndPushReleaseAuditNote(ctx context.Context, repository vcs.Repository, state *releaseState, author *vcs.Signature) error {
+	if err := repository.AddNote(auditNotesRefName, state.ReleaseCommitHash, getReleaseAuditNote(state, author.When), author); err != nil {
+		return stacktrace.Propagate(err, "An error occurred adding the audit note to release commit '%s'", state.ReleaseCommitHash)
+	}
+	auditNotesRefSpec := fmt.Sprintf("%s:%s", auditNotesRefName, auditNotesRefName)
+	if err := repository.Push(ctx, auditNotesRefSpec); err != nil {
+		return stacktrace.Propagate(err, "An error occurred pushing '%s' to '%s'", auditNotesRefName, originRemoteName)
+	}
+	return nil
+}
+
+// getReleaseAuditNote formats the audit note as trailer-style 'Key: value' lines, leaving out what a release state
+// recorded by an older kudet doesn't have
+func getReleaseAuditNote(state *releaseState, releasedAt time.Time) string {
+	lines := []string{
+		getAuditNoteLine(auditNoteVersionKey, state.Version),
+	}
+	if state.PreviousVersion != "" {
+		lines = append(lines, getAuditNoteLine(auditNotePreviousVersionKey, state.PreviousVersion))
+	}
+	if state.ReleasedBy != "" {
+		lines = append(lines, getAuditNoteLine(auditNoteReleasedByKey, state.ReleasedBy))
+	}
+	lines = append(lines, getAuditNoteLine(auditNoteReleasedAtKey, releasedAt.UTC().Format(time.RFC3339)))
+	lines = append(lines, getAuditNoteLine(auditNoteKudetVersionKey, kudet_version.KudetVersion))
+	if state.BumpReason != "" {
+		lines = append(lines, getAuditNoteLine(auditNoteBumpReasonKey, state.BumpReason))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// parseReleaseAuditNote reads the audit note's 'Key: value' lines back, skipping any other lines
+func parseReleaseAuditNote(note string) map[string]string {
+	values := map[string]string{}
+	for _, line := range strings.Split(note, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), auditNoteKeyValueSeparator)
+		if !found {
+			continue
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+func getAuditNoteLine(key string, value string) string {
+	return key + auditNoteKeyValueSeparator + value
+}
@@ -0,0 +1,64 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReleaseAuditNote(t *testing.T) {
+	releasedAt := time.Date(2022, 6, 3, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	state := &releaseState{
+		Version:         "0.2.0",
+		PreviousVersion: "0.1.0",
+		ReleasedBy:      "Kudet <kudet@example.com>",
+		BumpReason:      requestedMajorBumpReason,
+	}
+	require.Equal(t, "Version: 0.2.0\nPrevious-version: 0.1.0\nReleased-by: Kudet <kudet@example.com>\nReleased-at: 2022-06-03T12:00:00Z\nKudet-version: "+kudet_version.KudetVersion+"\nBump-reason: A major version bump was requested\n", getReleaseAuditNote(state, releasedAt))
+
+	// Release states recorded before audit notes existed don't have who released or why
+	require.Equal(t, "Version: 0.2.0\nReleased-at: 2022-06-03T12:00:00Z\nKudet-version: "+kudet_version.KudetVersion+"\n", getReleaseAuditNote(&releaseState{Version: "0.2.0"}, releasedAt))
+}
+
+func TestAddAndPushReleaseAuditNote(t *testing.T) {
+	originDirpath := t.TempDir()
+	originRepository, err := git.PlainInit(originDirpath, true)
+	require.NoError(t, err)
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{originDirpath}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte("# 0.1.0\n"), 0644))
+	author := &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()}
+	firstReleaseCommitHash, err := repository.CommitAll("Release 0.1.0", author)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte("# 0.2.0\n\n# 0.1.0\n"), 0644))
+	secondReleaseCommitHash, err := repository.CommitAll("Release 0.2.0", author)
+	require.NoError(t, err)
+
+	for version, releaseCommitHash := range map[string]string{"0.1.0": firstReleaseCommitHash, "0.2.0": secondReleaseCommitHash} {
+		state := &releaseState{Version: version, ReleaseCommitHash: releaseCommitHash}
+		require.NoError(t, addAndPushReleaseAuditNote(context.Background(), repository, state, author))
+	}
+	// Each release's note is added on top of the ones pushed before it
+	originNotesRef, err := originRepository.Reference(auditNotesRefName, true)
+	require.NoError(t, err)
+	originNotesCommit, err := originRepository.CommitObject(originNotesRef.Hash())
+	require.NoError(t, err)
+	require.Len(t, originNotesCommit.ParentHashes, 1)
+	note, found, err := repository.ReadNote(auditNotesRefName, firstReleaseCommitHash)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Contains(t, note, "Version: 0.1.0\n")
+}
@@ -0,0 +1,95 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Bazel only rebuilds stamped targets when these change if their keys start with 'STABLE_'
+	bazelReleaseVersionStatusKey         = "STABLE_RELEASE_VERSION"
+	bazelPreviousReleaseVersionStatusKey = "STABLE_PREVIOUS_RELEASE_VERSION"
+
+	bazelStampFileMode = 0644
+)
+
+// runBazelReleaseStep stamps the release's version into the Bazel stamp file and runs the target that builds the
+// release artifacts, if they're configured, collecting the target's artifacts into the artifacts directory if one is
+// given. The artifacts already collected are given so that the target's can't overwrite them; the paths of the
+// target's collected copies are returned.
+func runBazelReleaseStep(ctx context.Context, repoDirpath string, bazelConfig kudet_config.BazelConfig, releaseVersion string, previousReleaseVersion string, artifactsDirpath string, collectedArtifactFilepaths []string) ([]string, error) {
+	if bazelConfig.StampFilepath != "" {
+		if err := writeBazelStampFile(filepath.Join(repoDirpath, bazelConfig.StampFilepath), releaseVersion, previousReleaseVersion); err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred stamping version '%s' into '%s'", releaseVersion, bazelConfig.StampFilepath)
+		}
+	}
+	if bazelConfig.Target == "" {
+		return nil, nil
+	}
+	if err := runBazelTarget(ctx, repoDirpath, bazelConfig, releaseVersion); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred building the release artifacts with Bazel target '%s'", bazelConfig.Target)
+	}
+	if artifactsDirpath == "" || len(bazelConfig.Artifacts) == 0 {
+		return nil, nil
+	}
+	collectedArtifactSources := map[string]string{}
+	for _, artifactFilepath := range collectedArtifactFilepaths {
+		collectedArtifactSources[filepath.Base(artifactFilepath)] = artifactFilepath
+	}
+	artifactFilepaths, err := collectArtifacts(repoDirpath, fmt.Sprintf("Bazel target '%s'", bazelConfig.Target), bazelConfig.Artifacts, artifactsDirpath, collectedArtifactSources)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred collecting the artifacts of Bazel target '%s'", bazelConfig.Target)
+	}
+	return artifactFilepaths, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// writeBazelStampFile writes the versions as workspace status lines, which the repo's workspace status command prints
+// for stamped targets to pick up
+func writeBazelStampFile(stampFilepath string, releaseVersion string, previousReleaseVersion string) error {
+	stampLines := []string{
+		fmt.Sprintf("%s %s", bazelReleaseVersionStatusKey, releaseVersion),
+		fmt.Sprintf("%s %s", bazelPreviousReleaseVersionStatusKey, previousReleaseVersion),
+	}
+	if err := os.WriteFile(stampFilepath, []byte(strings.Join(stampLines, "\n")+"\n"), bazelStampFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing stamp file '%s'", stampFilepath)
+	}
+	logrus.Debugf("Stamped version '%s' into '%s'", releaseVersion, stampFilepath)
+	return nil
+}
+
+func runBazelTarget(ctx context.Context, repoDirpath string, bazelConfig kudet_config.BazelConfig, releaseVersion string) error {
+	args := append(append([]string{}, bazelConfig.Command[1:]...), bazelConfig.Target)
+	// Cancelling the context kills the build, so that an interrupted release can roll back promptly
+	buildCmd := exec.CommandContext(ctx, bazelConfig.Command[0], args...)
+	buildCmd.Dir = repoDirpath
+	buildCmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", releaseVersionEnvVar, releaseVersion))
+	// Like the pre-release scripts' output, the build's is kept whatever the log level, as it's how a failure gets debugged
+	buildOutput := &bytes.Buffer{}
+	buildCmd.Stdout = buildOutput
+	buildCmd.Stderr = buildOutput
+
+	logrus.Debugf("Running Bazel command '%s' in '%s'", buildCmd.String(), buildCmd.Dir)
+	err := buildCmd.Run()
+	logrus.Tracef("Bazel command '%s' output:\n%s", buildCmd.String(), buildOutput.String())
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return stacktrace.Propagate(ctxErr, "Bazel command '%s' was interrupted", buildCmd.String())
+		}
+		return stacktrace.Propagate(err, "Bazel command '%s' failed with logs:\n%s", buildCmd.String(), buildOutput.String())
+	}
+	logrus.Debugf("Bazel command '%s' succeeded", buildCmd.String())
+	return nil
+}
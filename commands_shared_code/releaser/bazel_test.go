@@ -0,0 +1,46 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBazelReleaseStep(t *testing.T) {
+	repoDirpath := t.TempDir()
+	artifactsDirpath := filepath.Join(t.TempDir(), preReleaseScriptArtifactsDirname)
+	// Stands in for 'bazel run --stamp', with the target passed as the script's first argument
+	bazelConfig := kudet_config.BazelConfig{
+		StampFilepath: "version_stamp.txt",
+		Target:        "//:release-artifacts",
+		Command:       []string{"sh", "-c", `mkdir -p bazel-bin && echo "$1 $KUDET_RELEASE_VERSION" > bazel-bin/release.tar.gz`, "bazel"},
+		Artifacts:     []string{"bazel-bin/*.tar.gz"},
+	}
+
+	artifactFilepaths, err := runBazelReleaseStep(context.Background(), repoDirpath, bazelConfig, "0.2.0", "0.1.0", artifactsDirpath, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(artifactsDirpath, "release.tar.gz")}, artifactFilepaths)
+	artifact, err := os.ReadFile(artifactFilepaths[0])
+	require.NoError(t, err)
+	require.Equal(t, "//:release-artifacts 0.2.0\n", string(artifact))
+	stampFile, err := os.ReadFile(filepath.Join(repoDirpath, "version_stamp.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "STABLE_RELEASE_VERSION 0.2.0\nSTABLE_PREVIOUS_RELEASE_VERSION 0.1.0\n", string(stampFile))
+
+	// The target's artifacts can't overwrite the pre-release scripts'
+	_, err = runBazelReleaseStep(context.Background(), repoDirpath, bazelConfig, "0.2.0", "0.1.0", t.TempDir(), []string{filepath.Join(artifactsDirpath, "release.tar.gz")})
+	require.ErrorContains(t, err, "would both be collected as 'release.tar.gz'")
+}
+
+func TestRunBazelReleaseStep_FailingBuildIsAnError(t *testing.T) {
+	bazelConfig := kudet_config.BazelConfig{
+		Target:  "//:release-artifacts",
+		Command: []string{"sh", "-c", "echo 'ERROR: no such target' && exit 1", "bazel"},
+	}
+	_, err := runBazelReleaseStep(context.Background(), t.TempDir(), bazelConfig, "0.2.0", "0.1.0", t.TempDir(), nil)
+	require.ErrorContains(t, err, "ERROR: no such target")
+}
@@ -0,0 +1,27 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"regexp"
+)
+
+// Matched against a section's title, ignoring case and any leading emoji or punctuation (e.g. '💥 BREAKING CHANGES')
+var breakingChangesTitleRegex = regexp.MustCompile(`(?i)^\W*(break\w*|backwards?[- ]?incompatible\w*|incompatible\w*)`)
+
+// hasSectionWithEntries reports whether one of the sections, at any depth, matches and has entries; the entries of its
+// subsections count, e.g. those under a '#### CLI' header nested under '### Breaking changes'
+func hasSectionWithEntries(sections []*changelog.Section, isMatch func(section *changelog.Section) bool) bool {
+	for _, section := range sections {
+		if isMatch(section) && section.HasEntries() {
+			return true
+		}
+		if hasSectionWithEntries(section.Subsections, isMatch) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBreakingChangesSection(section *changelog.Section) bool {
+	return breakingChangesTitleRegex.MatchString(section.Title)
+}
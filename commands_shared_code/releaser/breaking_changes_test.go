@@ -0,0 +1,26 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasSectionWithEntries(t *testing.T) {
+	hasBreakingChanges := func(lines ...string) bool {
+		return hasSectionWithEntries(changelog.NewVersion("# TBD", lines).Sections, isBreakingChangesSection)
+	}
+
+	// A breaking changes section nested under another section still counts
+	require.True(t, hasBreakingChanges("### API", "#### Breaking Changes", "* Removed the v1 endpoint", "### Fixes", "* Fixed a typo"))
+
+	// An empty breaking changes section, e.g. one left over from the skeleton, doesn't count
+	require.False(t, hasBreakingChanges("### Breaking Changes", "", "### Features", "* Something"))
+
+	// The entries of a breaking changes section can be under its own subsections
+	require.True(t, hasBreakingChanges("### Breaking Changes", "#### CLI", "* Removed a flag"))
+
+	// A shallower header ends the breaking changes section
+	require.False(t, hasBreakingChanges("#### Breaking Changes", "### Features", "* Something"))
+}
@@ -0,0 +1,45 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"strings"
+)
+
+const (
+	bridgeScriptFileMode = 0755
+)
+
+// writeBridgeScript writes the commit and tag operations of a release as a shell script, for repos mirrored from
+// another VCS (e.g. SVN) whose bridge must apply them rather than having them pushed directly
+func writeBridgeScript(bridgeScriptFilepath string, commitMsg string, releaseTag string, releaseTagMsg string, vReleaseTag string, vReleaseTagMsg string) error {
+	script := renderBridgeScript(commitMsg, releaseTag, releaseTagMsg, vReleaseTag, vReleaseTagMsg)
+	if err := os.WriteFile(bridgeScriptFilepath, []byte(script), bridgeScriptFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the bridge script to '%s'", bridgeScriptFilepath)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func renderBridgeScript(commitMsg string, releaseTag string, releaseTagMsg string, vReleaseTag string, vReleaseTagMsg string) string {
+	lines := []string{
+		"#!/bin/sh",
+		fmt.Sprintf("# Generated by 'kudet release' for release version '%s'; run it from the repo root with the release changes in the worktree", releaseTag),
+		"set -eu",
+		"git add --all",
+		fmt.Sprintf("git commit --message %s", quoteForShell(commitMsg)),
+		fmt.Sprintf("git tag --annotate %s --message %s", quoteForShell(releaseTag), quoteForShell(releaseTagMsg)),
+		fmt.Sprintf("git tag --annotate %s --message %s", quoteForShell(vReleaseTag), quoteForShell(vReleaseTagMsg)),
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// quoteForShell single-quotes the string, which leaves nothing but single quotes themselves for the shell to interpret
+func quoteForShell(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", `'\''`) + "'"
+}
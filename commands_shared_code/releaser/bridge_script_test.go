@@ -0,0 +1,19 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBridgeScript(t *testing.T) {
+	expectedScript := `#!/bin/sh
+# Generated by 'kudet release' for release version '1.2.3'; run it from the repo root with the release changes in the worktree
+set -eu
+git add --all
+git commit --message 'Finalize changes for release version '\''1.2.3'\'''
+git tag --annotate '1.2.3' --message '1.2.3'
+git tag --annotate 'v1.2.3' --message 'v1.2.3'
+`
+	require.Equal(t, expectedScript, renderBridgeScript("Finalize changes for release version '1.2.3'", "1.2.3", "1.2.3", "v1.2.3", "v1.2.3"))
+}
@@ -0,0 +1,112 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+)
+
+// Matches 'kudet:bump=<bump>' trailers, e.g. in a squash-merged PR's description, optionally followed by the
+// justification for overriding the computed bump
+var bumpOverrideTrailerRegex = regexp.MustCompile(`(?im)^kudet:bump=(major|minor|patch)\b[ \t]*(.*?)\s*$`)
+
+// bumpOverride is an approver's say on how much the version is bumped by, which trumps what the changelog says
+type bumpOverride struct {
+	bumpType string
+
+	commitHash string
+
+	// The commit's author, as 'Name <email>'
+	approver string
+
+	// The rest of the trailer's line, or the commit's subject if there's nothing after the bump
+	justification string
+}
+
+// getBumpOverride finds the latest 'kudet:bump=' trailer on the commits since the previous release that's on a commit
+// authored by one of the approvers, or returns nil if there's none; trailers on anyone else's commits are ignored with
+// a warning. Without approvers, the commits aren't looked at at all, and before the first release there's no computed
+// bump to override.
+func getBumpOverride(repository vcs.Repository, overrideConfig kudet_config.BumpOverrideConfig, previousVersion string) (*bumpOverride, error) {
+	if len(overrideConfig.Approvers) == 0 {
+		return nil, nil
+	}
+	previousReleaseCommitHash, hasPreviousRelease, err := getReleaseCommitHash(repository, previousVersion)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the commit of release '%s'", previousVersion)
+	}
+	if !hasPreviousRelease {
+		return nil, nil
+	}
+	headCommitHash, err := repository.GetHeadCommitHash()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the HEAD commit")
+	}
+	commits, err := repository.GetCommits(previousReleaseCommitHash, headCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the commits since release '%s'", previousVersion)
+	}
+	// The commits are latest first, so the latest approved trailer wins
+	for _, commit := range commits {
+		matches := bumpOverrideTrailerRegex.FindAllStringSubmatch(commit.Message, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		approver := fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email)
+		if !isBumpOverrideApprover(commit.Author, overrideConfig.Approvers) {
+			logrus.Warnf("Ignoring the 'kudet:bump=' trailer on commit '%s', since its author %s isn't one of the approvers under '%s.%s' in the kudet config", commit.Hash, approver, kudet_config.BumpOverrideKey, kudet_config.BumpOverrideApproversKey)
+			continue
+		}
+		// A commit with several trailers means its last one
+		lastMatch := matches[len(matches)-1]
+		justification := lastMatch[2]
+		if justification == "" {
+			justification = strings.TrimSpace(strings.SplitN(commit.Message, "\n", 2)[0])
+		}
+		return &bumpOverride{
+			bumpType:      strings.ToLower(lastMatch[1]),
+			commitHash:    commit.Hash,
+			approver:      approver,
+			justification: justification,
+		}, nil
+	}
+	return nil, nil
+}
+
+// apply bumps the latest release's version by the override's bump
+func (override *bumpOverride) apply(latestReleaseVersion *semver.Version) semver.Version {
+	switch override.bumpType {
+	case majorBumpType:
+		return latestReleaseVersion.IncMajor()
+	case minorBumpType:
+		return latestReleaseVersion.IncMinor()
+	default:
+		return latestReleaseVersion.IncPatch()
+	}
+}
+
+// getBumpReason is what the audit note and the audit log record as the reason for the bump
+func (override *bumpOverride) getBumpReason() string {
+	return fmt.Sprintf("%s overrode the bump to %s in commit '%s': %s", override.approver, override.bumpType, override.commitHash, override.justification)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// isBumpOverrideApprover matches the author's email or name against the approvers, ignoring case
+func isBumpOverrideApprover(author vcs.Signature, approvers []string) bool {
+	for _, approver := range approvers {
+		approver = strings.TrimSpace(approver)
+		if strings.EqualFold(approver, author.Email) || strings.EqualFold(approver, author.Name) {
+			return true
+		}
+	}
+	return false
+}
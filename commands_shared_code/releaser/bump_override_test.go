@@ -0,0 +1,69 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBumpOverride(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	lead := &vcs.Signature{Name: "Lead", Email: "lead@example.com", When: time.Now()}
+	contributor := &vcs.Signature{Name: "Contributor", Email: "contributor@example.com", When: time.Now()}
+	commit := func(message string, author *vcs.Signature) string {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "main.go"), []byte(message), 0644))
+		commitHash, err := repository.CommitAll(message, author)
+		require.NoError(t, err)
+		return commitHash
+	}
+	require.NoError(t, repository.CreateTag("0.1.0", commit("Release 0.1.0", lead), "0.1.0"))
+	overrideConfig := kudet_config.BumpOverrideConfig{Approvers: []string{"LEAD@example.com"}}
+
+	override, err := getBumpOverride(repository, overrideConfig, "0.1.0")
+	require.NoError(t, err)
+	require.Nil(t, override)
+
+	approvedCommitHash := commit("Rename internal flags (#12)\n\nkudet:bump=patch Only internal APIs were renamed\n", lead)
+	// Only approvers can override the bump, however recent their trailer
+	commit("Remove the v1 API\n\nkudet:bump=major\n", contributor)
+	commit("Fix a typo", lead)
+	override, err = getBumpOverride(repository, overrideConfig, "0.1.0")
+	require.NoError(t, err)
+	require.Equal(t, &bumpOverride{
+		bumpType:      patchBumpType,
+		commitHash:    approvedCommitHash,
+		approver:      "Lead <lead@example.com>",
+		justification: "Only internal APIs were renamed",
+	}, override)
+	nextVersion := override.apply(semver.MustParse("0.1.0"))
+	require.Equal(t, "0.1.1", nextVersion.String())
+	require.Equal(t, "Lead <lead@example.com> overrode the bump to patch in commit '"+approvedCommitHash+"': Only internal APIs were renamed", override.getBumpReason())
+
+	// Without a justification on the trailer's line, the commit's subject is the justification
+	commit("Drop the deprecated flags\n\nkudet:bump=minor\n", lead)
+	override, err = getBumpOverride(repository, overrideConfig, "0.1.0")
+	require.NoError(t, err)
+	require.Equal(t, minorBumpType, override.bumpType)
+	require.Equal(t, "Drop the deprecated flags", override.justification)
+
+	override, err = getBumpOverride(repository, kudet_config.BumpOverrideConfig{}, "0.1.0")
+	require.NoError(t, err)
+	require.Nil(t, override)
+	override, err = getBumpOverride(repository, overrideConfig, noPreviousVersion)
+	require.NoError(t, err)
+	require.Nil(t, override)
+}
@@ -0,0 +1,162 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	breakingChangesBackfillSectionTitle = "Breaking changes"
+	featuresBackfillSectionTitle        = "Features"
+	fixesBackfillSectionTitle           = "Fixes"
+	otherChangesBackfillSectionTitle    = "Changes"
+	backfillSectionHeaderLevel          = 3
+
+	// Conventional commits whose type says they're breaking have it after the type, e.g. 'feat!: drop Go 1.17'
+	conventionalCommitBreakingMarker = "!"
+	conventionalCommitBreakingFooter = "BREAKING CHANGE"
+)
+
+// Matches conventional commit subjects, e.g. 'fix(cli): handle empty tags', capturing the type, the breaking change
+// marker and the description
+var conventionalCommitSubjectRegex = regexp.MustCompile(`^([a-zA-Z]+)(?:\([^)]*\))?(!?):\s*(.+)$`)
+
+// The order that the backfilled sections are listed in under each version
+var backfillSectionTitles = []string{
+	breakingChangesBackfillSectionTitle,
+	featuresBackfillSectionTitle,
+	fixesBackfillSectionTitle,
+	otherChangesBackfillSectionTitle,
+}
+
+// The conventional commit types of changes that don't concern the repo's users, which are left out of the changelog
+var housekeepingCommitTypes = map[string]bool{
+	"build": true,
+	"chore": true,
+	"ci":    true,
+	"style": true,
+	"test":  true,
+	"tests": true,
+}
+
+// BackfillChangelog reconstructs a changelog for a repo that was released without one, from its release tags and the
+// subjects of the commits that each release added, so that it can start being released with kudet. Conventional
+// commits are sorted into breaking changes, features and fixes, leaving out housekeeping like 'chore' and 'ci'
+// commits, and the others are listed as changes; merge commits are left out in favour of the commits they merged. The
+// changelog has an empty TBD header for the next release, to be filled in before releasing.
+func BackfillChangelog(repository vcs.Repository, tagParsingConfig kudet_config.TagParsingConfig) ([]byte, error) {
+	releases, err := ListReleases(repository, tagParsingConfig, ReleaseListFilter{})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the repo's releases")
+	}
+	ignoredVersions := []string{}
+	lines := []string{sectionHeaderPrefix + " " + changelog.UnreleasedVersionName, ""}
+	// The releases are listed latest first, while each release's changes are what it added to the one before it
+	for idx, release := range releases {
+		if release.IsIgnored {
+			ignoredVersions = append(ignoredVersions, release.Version)
+			continue
+		}
+		previousCommitHash := ""
+		for _, previousRelease := range releases[idx+1:] {
+			if !previousRelease.IsIgnored {
+				previousCommitHash = previousRelease.CommitHash
+				break
+			}
+		}
+		commits, err := repository.GetCommits(previousCommitHash, release.CommitHash)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred listing the commits of release '%s'", release.Version)
+		}
+		lines = append(lines, sectionHeaderPrefix+" "+release.Version)
+		lines = append(lines, getBackfilledReleaseLines(commits)...)
+		lines = append(lines, "")
+	}
+	if len(ignoredVersions) > 0 {
+		logrus.Warnf(
+			"Left out versions [%s], which the tag parsing config ignores when determining the latest release; set '%s.%s' or '%s.%s' in the kudet config to take them into account",
+			strings.Join(ignoredVersions, ", "),
+			kudet_config.TagParsingKey,
+			kudet_config.AllowVPrefixKey,
+			kudet_config.TagParsingKey,
+			kudet_config.AllowPrereleasesAndMetadataKey,
+		)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getBackfilledReleaseLines lists the release's commits under their sections, oldest first within each section
+func getBackfilledReleaseLines(commits []vcs.Commit) []string {
+	entriesBySectionTitle := map[string][]string{}
+	isListed := map[string]bool{}
+	for idx := len(commits) - 1; idx >= 0; idx-- {
+		commit := commits[idx]
+		if len(commit.ParentHashes) > 1 {
+			continue
+		}
+		sectionTitle, entry, isNotable := summarizeCommit(commit.Message)
+		if !isNotable || isListed[entry] {
+			continue
+		}
+		isListed[entry] = true
+		entriesBySectionTitle[sectionTitle] = append(entriesBySectionTitle[sectionTitle], entry)
+	}
+	lines := []string{}
+	for _, sectionTitle := range backfillSectionTitles {
+		entries := entriesBySectionTitle[sectionTitle]
+		if len(entries) == 0 {
+			continue
+		}
+		lines = append(lines, strings.Repeat(sectionHeaderPrefix, backfillSectionHeaderLevel)+" "+sectionTitle)
+		for _, entry := range entries {
+			lines = append(lines, "* "+entry)
+		}
+	}
+	return lines
+}
+
+// summarizeCommit turns the commit message's subject into a changelog entry and picks the section it goes under, or
+// returns false if the commit isn't worth listing
+func summarizeCommit(message string) (string, string, bool) {
+	subject := strings.TrimSpace(strings.SplitN(strings.TrimSpace(message), "\n", 2)[0])
+	if subject == "" {
+		return "", "", false
+	}
+	matches := conventionalCommitSubjectRegex.FindStringSubmatch(subject)
+	if matches == nil {
+		return otherChangesBackfillSectionTitle, capitalizeFirstLetter(subject), true
+	}
+	commitType := strings.ToLower(matches[1])
+	description := capitalizeFirstLetter(strings.TrimSpace(matches[3]))
+	if matches[2] == conventionalCommitBreakingMarker || strings.Contains(message, conventionalCommitBreakingFooter) {
+		return breakingChangesBackfillSectionTitle, description, true
+	}
+	if housekeepingCommitTypes[commitType] {
+		return "", "", false
+	}
+	switch commitType {
+	case "feat":
+		return featuresBackfillSectionTitle, description, true
+	case "fix":
+		return fixesBackfillSectionTitle, description, true
+	default:
+		return otherChangesBackfillSectionTitle, description, true
+	}
+}
+
+func capitalizeFirstLetter(str string) string {
+	firstRune, size := utf8.DecodeRuneInString(str)
+	return string(unicode.ToUpper(firstRune)) + str[size:]
+}
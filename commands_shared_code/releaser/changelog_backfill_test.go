@@ -0,0 +1,69 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillChangelog(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	commitCount := 0
+	commit := func(message string) string {
+		commitCount++
+		require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "main.go"), []byte(message), 0644))
+		commitHash, err := repository.CommitAll(message, &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now().Add(time.Duration(commitCount) * time.Second)})
+		require.NoError(t, err)
+		return commitHash
+	}
+	tag := func(tagName string, commitHash string) {
+		require.NoError(t, repository.CreateTag(tagName, commitHash, tagName))
+	}
+
+	tag("0.1.0", commit("Initial commit"))
+	commit("feat: add the widget")
+	commit("chore: bump dependencies")
+	commit("fix(cli): handle empty tags")
+	commit("refactor: split the parser\n\nBREAKING CHANGE: the parser package is gone")
+	commit("Fix a typo in the docs")
+	tag("1.0.0", commit("feat: add the widget"))
+	tag("1.0.1-rc.1", commit("docs: explain the widget"))
+	tag("v1.0.1", commit("fix: don't crash on empty input"))
+
+	expectedChangelog := "# TBD\n" +
+		"\n" +
+		"# 1.0.0\n" +
+		"### Breaking changes\n" +
+		"* Split the parser\n" +
+		"### Features\n" +
+		"* Add the widget\n" +
+		"### Fixes\n" +
+		"* Handle empty tags\n" +
+		"### Changes\n" +
+		"* Fix a typo in the docs\n" +
+		"\n" +
+		"# 0.1.0\n" +
+		"### Changes\n" +
+		"* Initial commit\n"
+	changelogContents, err := BackfillChangelog(repository, kudet_config.TagParsingConfig{})
+	require.NoError(t, err)
+	require.Equal(t, expectedChangelog, string(changelogContents))
+
+	// Releases only tagged with the 'v' prefix count once the tag parsing config lets them
+	changelogContents, err = BackfillChangelog(repository, kudet_config.TagParsingConfig{AllowVPrefix: true})
+	require.NoError(t, err)
+	require.Equal(t, "# TBD\n\n# 1.0.1\n### Fixes\n* Don't crash on empty input\n### Changes\n* Explain the widget\n\n"+expectedChangelog[len("# TBD\n\n"):], string(changelogContents))
+}
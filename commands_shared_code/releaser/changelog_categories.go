@@ -0,0 +1,100 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"regexp"
+	"strings"
+)
+
+// How much each bump bumps the version by, so that the highest that any change asks for wins
+var bumpTypeRanks = map[string]int{
+	patchBumpType: 0,
+	minorBumpType: 1,
+	majorBumpType: 2,
+}
+
+// bumpRules are what the kinds of change under the changelog's TBD header are detected by, on top of the breaking
+// changes subheaders that are always detected
+type bumpRules struct {
+	// Matched against the headers of major changes sections; major changes aren't detected if it's nil
+	majorChangesRegex *regexp.Regexp
+
+	categories []kudet_config.ChangelogCategoryConfig
+}
+
+// categoryChanges are the configured changelog categories that have entries under the TBD header
+type categoryChanges struct {
+	// The highest bump that the categories ask for, or empty if none has entries
+	bumpType string
+
+	// The subheader of the category that asks for the bump
+	bumpSubheader string
+
+	// The subheaders of the categories that force a release
+	forcedReleaseSubheaders []string
+}
+
+func newBumpRules(kudetConfig *kudet_config.KudetConfig) *bumpRules {
+	rules := &bumpRules{
+		categories: kudetConfig.ChangelogCategories,
+	}
+	if kudetConfig.MajorChangesSubheaderRegex != "" {
+		// Already validated along with the rest of the config
+		rules.majorChangesRegex = regexp.MustCompile(kudetConfig.MajorChangesSubheaderRegex)
+	}
+	return rules
+}
+
+// getCategoryChanges finds the categories with entries in the version's sections, at any level; categories listed
+// first win ties between the bumps they ask for
+func getCategoryChanges(version *changelog.Version, categoryConfigs []kudet_config.ChangelogCategoryConfig) categoryChanges {
+	changes := categoryChanges{
+		forcedReleaseSubheaders: []string{},
+	}
+	for _, categoryConfig := range categoryConfigs {
+		subheader := categoryConfig.Subheader
+		isCategorySection := func(section *changelog.Section) bool {
+			return strings.EqualFold(section.Title, subheader)
+		}
+		if !hasSectionWithEntries(version.Sections, isCategorySection) {
+			continue
+		}
+		if changes.bumpType == "" || bumpTypeRanks[categoryConfig.Bump] > bumpTypeRanks[changes.bumpType] {
+			changes.bumpType = categoryConfig.Bump
+			changes.bumpSubheader = subheader
+		}
+		if categoryConfig.ForceRelease {
+			changes.forcedReleaseSubheaders = append(changes.forcedReleaseSubheaders, subheader)
+		}
+	}
+	return changes
+}
+
+// mergeCategoryChanges combines the categories with entries across changelogs, keeping the first's subheader for ties
+func mergeCategoryChanges(changes categoryChanges, other categoryChanges) categoryChanges {
+	merged := categoryChanges{
+		bumpType:                changes.bumpType,
+		bumpSubheader:           changes.bumpSubheader,
+		forcedReleaseSubheaders: append([]string{}, changes.forcedReleaseSubheaders...),
+	}
+	for _, subheader := range other.forcedReleaseSubheaders {
+		if !isOneOfSubheaders(subheader, merged.forcedReleaseSubheaders) {
+			merged.forcedReleaseSubheaders = append(merged.forcedReleaseSubheaders, subheader)
+		}
+	}
+	if other.bumpType != "" && (merged.bumpType == "" || bumpTypeRanks[other.bumpType] > bumpTypeRanks[merged.bumpType]) {
+		merged.bumpType = other.bumpType
+		merged.bumpSubheader = other.bumpSubheader
+	}
+	return merged
+}
+
+func isOneOfSubheaders(subheader string, subheaders []string) bool {
+	for _, other := range subheaders {
+		if strings.EqualFold(subheader, other) {
+			return true
+		}
+	}
+	return false
+}
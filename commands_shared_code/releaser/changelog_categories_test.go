@@ -0,0 +1,54 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+var testChangelogCategories = []kudet_config.ChangelogCategoryConfig{
+	{Subheader: "Deprecations", Bump: kudet_config.MinorBump},
+	{Subheader: "Security", Bump: kudet_config.PatchBump, ForceRelease: true},
+	{Subheader: "Removed", Bump: kudet_config.MajorBump},
+}
+
+func TestParseChangeLogFile_Categories(t *testing.T) {
+	rules := &bumpRules{categories: testChangelogCategories}
+	latestReleaseVersion := semver.MustParse("1.2.3")
+
+	// Categories are matched ignoring case and at any level, but only count once they have entries
+	changes, err := parseChangeLogFile([]byte("# TBD\n### API\n#### deprecations\n* Deprecated the v1 endpoint\n### Removed\n\n# 1.2.3\n* Initial\n"), rules)
+	require.NoError(t, err)
+	require.Equal(t, kudet_config.MinorBump, changes.categories.bumpType)
+	require.Empty(t, changes.categories.forcedReleaseSubheaders)
+	require.Equal(t, "1.3.0", getNextReleaseVersion(latestReleaseVersion, changes, false).String())
+	require.Equal(t, "The TBD section has entries under the 'Deprecations' category, which bumps the minor version", getBumpReason(changes))
+
+	// The highest bump wins
+	changes, err = parseChangeLogFile([]byte("# TBD\n### Deprecations\n* Deprecated a flag\n### Removed\n* Removed a flag\n### Security\n* Fixed a CVE\n\n# 1.2.3\n* Initial\n"), rules)
+	require.NoError(t, err)
+	require.Equal(t, "2.0.0", getNextReleaseVersion(latestReleaseVersion, changes, false).String())
+	require.Equal(t, "The TBD section has entries under the 'Removed' category, which bumps the major version", getBumpReason(changes))
+	require.Equal(t, []string{"Security"}, changes.categories.forcedReleaseSubheaders)
+
+	// A category never lowers the bump that a breaking changes subheader asks for
+	changes, err = parseChangeLogFile([]byte("# TBD\n### Breaking changes\n* Renamed a flag\n### Security\n* Fixed a CVE\n\n# 1.2.3\n* Initial\n"), rules)
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0", getNextReleaseVersion(latestReleaseVersion, changes, false).String())
+	require.Equal(t, "The TBD section has a breaking changes subheader, which bumps the minor version", getBumpReason(changes))
+}
+
+func TestMergeChangelogChanges_Categories(t *testing.T) {
+	changes := &changelogChanges{categories: categoryChanges{bumpType: kudet_config.PatchBump, bumpSubheader: "Security", forcedReleaseSubheaders: []string{"Security"}}}
+	otherChanges := &changelogChanges{categories: categoryChanges{bumpType: kudet_config.MinorBump, bumpSubheader: "Deprecations", forcedReleaseSubheaders: []string{"security"}}}
+	mergedChanges := mergeChangelogChanges(changes, []*changelogChanges{otherChanges})
+	require.Equal(t, categoryChanges{bumpType: kudet_config.MinorBump, bumpSubheader: "Deprecations", forcedReleaseSubheaders: []string{"Security"}}, mergedChanges.categories)
+}
+
+func TestIsReleaseForced(t *testing.T) {
+	require.True(t, isReleaseForced([]byte("# TBD\n### Security\n* Fixed a CVE\n\n# 1.2.3\n"), testChangelogCategories))
+	require.False(t, isReleaseForced([]byte("# TBD\n### Security\n\n### Removed\n* Removed a flag\n\n# 1.2.3\n"), testChangelogCategories))
+	require.False(t, isReleaseForced([]byte("# 1.2.3\n### Security\n* Fixed a CVE\n"), testChangelogCategories))
+}
@@ -0,0 +1,128 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"regexp"
+	"strings"
+)
+
+var (
+	changelogSubheaderRegex = regexp.MustCompile(`^##+\s*(.*?)\s*$`)
+	ackedByTrailerRegex     = regexp.MustCompile(`(?im)^Acked-by:\s*(.+?)\s*$`)
+)
+
+// verifyRequiredChangelogSections checks that, for each of the required sections whose files changed since the previous
+// release, the changelog's TBD section has entries under the section's subheader, acknowledged by one of its owners if it
+// has any. Every section that isn't satisfied is reported at once. Before the first release nothing is required, since
+// every file is new.
+func verifyRequiredChangelogSections(repository vcs.Repository, changelogFile []byte, sectionConfigs []kudet_config.RequiredChangelogSectionConfig, previousVersion string) error {
+	if len(sectionConfigs) == 0 {
+		return nil
+	}
+	changedFilepaths, hasPreviousRelease, err := getFilepathsChangedSinceRelease(repository, previousVersion)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the files changed since the previous release")
+	}
+	if !hasPreviousRelease {
+		return nil
+	}
+	// Only read if a section that needs acknowledging is required
+	var commitMessages []string
+	problems := []string{}
+	for _, sectionConfig := range sectionConfigs {
+		changedFilepath, isChanged := getChangedFilepathMatch(changedFilepaths, sectionConfig.Paths)
+		if !isChanged {
+			continue
+		}
+		if len(getTbdSubheaderEntryLines(changelogFile, sectionConfig.Subheader)) == 0 {
+			problems = append(problems, fmt.Sprintf("'%s' changed since release '%s', but the changelog has no entries under a '%s' subheader", changedFilepath, previousVersion, sectionConfig.Subheader))
+			continue
+		}
+		if len(sectionConfig.AckedBy) == 0 {
+			continue
+		}
+		if commitMessages == nil {
+			commitMessages, err = getCommitMessagesSinceRelease(repository, previousVersion)
+			if err != nil {
+				return stacktrace.Propagate(err, "An error occurred getting the commit messages since release '%s'", previousVersion)
+			}
+		}
+		if !hasAckedByTrailer(commitMessages, sectionConfig.AckedBy) {
+			problems = append(problems, fmt.Sprintf("The entries under the '%s' subheader aren't acknowledged: no commit since release '%s' has an 'Acked-by:' trailer naming '%s'", sectionConfig.Subheader, previousVersion, strings.Join(sectionConfig.AckedBy, "', '")))
+		}
+	}
+	if len(problems) > 0 {
+		return stacktrace.NewErrorWithCode(ErrChangelogSectionMissing.code, "The changelog is missing sections that the changed files require:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getTbdSubheaderEntryLines returns the non-blank lines under the subheader in the changelog's TBD section, up to the
+// next header
+func getTbdSubheaderEntryLines(changelogFile []byte, subheader string) []string {
+	entryLines := []string{}
+	isInTbdSection := false
+	isInSubheader := false
+	scanner := newChangelogScanner(changelogFile)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if versionToBeReleasedPlaceholderHeaderRegex.MatchString(line) {
+			isInTbdSection = true
+			continue
+		}
+		if versionHeaderRegex.MatchString(line) {
+			break
+		}
+		if !isInTbdSection {
+			continue
+		}
+		if strings.HasPrefix(line, sectionHeaderPrefix) {
+			matches := changelogSubheaderRegex.FindStringSubmatch(line)
+			isInSubheader = matches != nil && strings.EqualFold(matches[1], subheader)
+			continue
+		}
+		if isInSubheader && strings.TrimSpace(line) != "" {
+			entryLines = append(entryLines, line)
+		}
+	}
+	return entryLines
+}
+
+func getCommitMessagesSinceRelease(repository vcs.Repository, version string) ([]string, error) {
+	releaseCommitHash, _, err := getReleaseCommitHash(repository, version)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the commit of release '%s'", version)
+	}
+	headCommitHash, err := repository.GetHeadCommitHash()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the HEAD commit")
+	}
+	commitMessages, err := repository.GetCommitMessages(releaseCommitHash, headCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the messages of the commits since release '%s'", version)
+	}
+	return commitMessages, nil
+}
+
+// hasAckedByTrailer reports whether one of the commit messages has an 'Acked-by:' trailer naming one of the ackers,
+// ignoring case
+func hasAckedByTrailer(commitMessages []string, ackers []string) bool {
+	for _, commitMessage := range commitMessages {
+		for _, matches := range ackedByTrailerRegex.FindAllStringSubmatch(commitMessage, -1) {
+			for _, acker := range ackers {
+				if strings.EqualFold(matches[1], acker) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRequiredChangelogSections(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	author := &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()}
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDirpath, "api"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "api", "api.proto"), []byte("v1\n"), 0644))
+	releaseCommitHash, err := repository.CommitAll("Release 0.1.0", author)
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.0", releaseCommitHash, "0.1.0"))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "api", "api.proto"), []byte("v2\n"), 0644))
+	_, err = repository.CommitAll("Change the API", author)
+	require.NoError(t, err)
+
+	sectionConfigs := []kudet_config.RequiredChangelogSectionConfig{{Subheader: "API changes", Paths: []string{"api/"}}}
+	changelogWithoutSection := []byte("# TBD\n### Fixes\n* Fixed a bug\n\n# 0.1.0\n### API changes\n* Initial API\n")
+	changelogWithSection := []byte("# TBD\n### api changes\n* Changed the API\n\n# 0.1.0\n* Initial release\n")
+	err = verifyRequiredChangelogSections(repository, changelogWithoutSection, sectionConfigs, "0.1.0")
+	releaseErr, found := GetReleaseError(err)
+	require.True(t, found)
+	require.Equal(t, ErrChangelogSectionMissing, releaseErr)
+	require.NoError(t, verifyRequiredChangelogSections(repository, changelogWithSection, sectionConfigs, "0.1.0"))
+	// Nothing is required before the first release
+	require.NoError(t, verifyRequiredChangelogSections(repository, changelogWithoutSection, sectionConfigs, noPreviousVersion))
+
+	sectionConfigs[0].AckedBy = []string{"@kurtosis-tech/api"}
+	require.Error(t, verifyRequiredChangelogSections(repository, changelogWithSection, sectionConfigs, "0.1.0"))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "notes.txt"), []byte("notes\n"), 0644))
+	_, err = repository.CommitAll("Add API notes\n\nAcked-by: @Kurtosis-Tech/API\n", author)
+	require.NoError(t, err)
+	require.NoError(t, verifyRequiredChangelogSections(repository, changelogWithSection, sectionConfigs, "0.1.0"))
+}
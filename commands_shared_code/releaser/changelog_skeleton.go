@@ -0,0 +1,123 @@
+package releaser
+
+import (
+	"bytes"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	notAHeaderLevel = -1
+	// The end of a section closes its subheaders like a header above them all would
+	endOfSectionHeaderLevel = 0
+)
+
+// readChangelogTbdSkeleton returns the lines of the skeleton that's written under the TBD header after each release, or
+// none if the repo doesn't configure one, in which case the TBD header is left bare
+func readChangelogTbdSkeleton(repoDirpath string, skeletonRelFilepath string) ([]string, error) {
+	if skeletonRelFilepath == "" {
+		return nil, nil
+	}
+	skeletonFilepath := filepath.Join(repoDirpath, skeletonRelFilepath)
+	skeleton, err := os.ReadFile(skeletonFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the changelog's TBD skeleton at '%s'", skeletonFilepath)
+	}
+	skeletonLines, err := parseChangelogTbdSkeleton(skeleton)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "The changelog's TBD skeleton at '%s' is invalid", skeletonFilepath)
+	}
+	return skeletonLines, nil
+}
+
+// pruneChangelogTbdSkeleton removes what's left of the skeleton from the changelog's TBD section: its placeholder lines,
+// and its subheaders with no entries under them. An untouched skeleton is then an empty TBD section, and the breaking or
+// major changes subheaders it offers only bump the version once something is listed under them.
+func pruneChangelogTbdSkeleton(changelogFile []byte, skeletonLines []string) []byte {
+	if len(skeletonLines) == 0 {
+		return changelogFile
+	}
+	skeletonLineSet := map[string]bool{}
+	for _, skeletonLine := range skeletonLines {
+		if strings.TrimSpace(skeletonLine) != "" {
+			skeletonLineSet[strings.TrimSpace(skeletonLine)] = true
+		}
+	}
+
+	lines := bytes.Split(changelogFile, []byte("\n"))
+	tbdSectionStartIdx := -1
+	tbdSectionEndIdx := len(lines)
+	for idx, line := range lines {
+		if tbdSectionStartIdx == -1 && versionToBeReleasedPlaceholderHeaderRegex.Match(line) {
+			tbdSectionStartIdx = idx + 1
+			continue
+		}
+		if tbdSectionStartIdx != -1 && versionHeaderRegex.Match(line) {
+			tbdSectionEndIdx = idx
+			break
+		}
+	}
+	if tbdSectionStartIdx == -1 {
+		return changelogFile
+	}
+
+	// Walking the section backwards, a skeleton subheader is dropped if the next line with something on it is a header of
+	// the same or a higher level, or the end of the section; dropped lines don't count, so empty nested subheaders all go
+	nextKeptHeaderLevel := endOfSectionHeaderLevel
+	keptTbdLines := [][]byte{}
+	for idx := tbdSectionEndIdx - 1; idx >= tbdSectionStartIdx; idx-- {
+		trimmedLine := string(bytes.TrimSpace(lines[idx]))
+		if trimmedLine == "" {
+			keptTbdLines = append([][]byte{lines[idx]}, keptTbdLines...)
+			continue
+		}
+		headerLevel := getHeaderLevel(trimmedLine)
+		isEmptySubheader := headerLevel != notAHeaderLevel && nextKeptHeaderLevel != notAHeaderLevel && nextKeptHeaderLevel <= headerLevel
+		if skeletonLineSet[trimmedLine] && (headerLevel == notAHeaderLevel || isEmptySubheader) {
+			continue
+		}
+		nextKeptHeaderLevel = headerLevel
+		keptTbdLines = append([][]byte{lines[idx]}, keptTbdLines...)
+	}
+
+	// Dropping lines leaves runs of blank lines behind
+	prunedTbdLines := [][]byte{}
+	for _, line := range keptTbdLines {
+		isBlank := len(bytes.TrimSpace(line)) == 0
+		if isBlank && len(prunedTbdLines) > 0 && len(bytes.TrimSpace(prunedTbdLines[len(prunedTbdLines)-1])) == 0 {
+			continue
+		}
+		prunedTbdLines = append(prunedTbdLines, line)
+	}
+
+	prunedLines := append([][]byte{}, lines[:tbdSectionStartIdx]...)
+	prunedLines = append(prunedLines, prunedTbdLines...)
+	prunedLines = append(prunedLines, lines[tbdSectionEndIdx:]...)
+	return bytes.Join(prunedLines, []byte("\n"))
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func parseChangelogTbdSkeleton(skeleton []byte) ([]string, error) {
+	skeletonLines := normalizeNotesLines(strings.Split(string(skeleton), "\n"))
+	for _, skeletonLine := range skeletonLines {
+		if versionToBeReleasedPlaceholderHeaderRegex.MatchString(skeletonLine) || versionHeaderRegex.MatchString(skeletonLine) {
+			return nil, stacktrace.NewError("The skeleton goes under the '%s' header, so it can't have a TBD or version header of its own, but it has '%s'", versionToBeReleasedPlaceholderHeaderStr, skeletonLine)
+		}
+	}
+	return skeletonLines, nil
+}
+
+// getHeaderLevel returns how many '#'s the Markdown header starts with, or notAHeaderLevel if the line isn't one
+func getHeaderLevel(trimmedLine string) int {
+	headerLevel := len(trimmedLine) - len(strings.TrimLeft(trimmedLine, sectionHeaderPrefix))
+	if headerLevel == 0 {
+		return notAHeaderLevel
+	}
+	return headerLevel
+}
@@ -0,0 +1,55 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/stretchr/testify/require"
+)
+
+const testTbdSkeleton = "### Features\n<!-- One line per feature -->\n\n### Fixes\n\n### Breaking changes\n"
+
+func TestPruneChangelogTbdSkeleton_UntouchedSkeletonIsEmpty(t *testing.T) {
+	skeletonLines, err := parseChangelogTbdSkeleton([]byte(testTbdSkeleton))
+	require.NoError(t, err)
+	changelog := "# TBD\n\n" + testTbdSkeleton + "\n# 0.1.0\n### Breaking changes\n* Old\n"
+
+	prunedChangelog := pruneChangelogTbdSkeleton([]byte(changelog), skeletonLines)
+	require.Equal(t, "# TBD\n\n# 0.1.0\n### Breaking changes\n* Old\n", string(prunedChangelog))
+	_, err = parseChangeLogFile(prunedChangelog, nil)
+	require.ErrorContains(t, err, "changelog.md is empty for the current release")
+}
+
+func TestPruneChangelogTbdSkeleton_KeepsFilledSubheaders(t *testing.T) {
+	skeletonLines, err := parseChangelogTbdSkeleton([]byte(testTbdSkeleton))
+	require.NoError(t, err)
+	changelog := "# TBD\n\n### Features\n<!-- One line per feature -->\n#### CLI\n* Added a flag\n\n### Fixes\n\n### Breaking changes\n\n# 0.1.0\n"
+
+	prunedChangelog := pruneChangelogTbdSkeleton([]byte(changelog), skeletonLines)
+	require.Equal(t, "# TBD\n\n### Features\n#### CLI\n* Added a flag\n\n# 0.1.0\n", string(prunedChangelog))
+	changes, err := parseChangeLogFile(prunedChangelog, nil)
+	require.NoError(t, err)
+	require.False(t, changes.hasBreakingChange)
+}
+
+func TestParseChangelogTbdSkeleton_RejectsVersionHeaders(t *testing.T) {
+	_, err := parseChangelogTbdSkeleton([]byte("### Features\n\n# TBD\n"))
+	require.Error(t, err)
+	_, err = parseChangelogTbdSkeleton([]byte("# 0.1.0\n"))
+	require.Error(t, err)
+}
+
+func TestUpdateChangelog_WritesTbdSkeleton(t *testing.T) {
+	skeletonLines, err := parseChangelogTbdSkeleton([]byte(testTbdSkeleton))
+	require.NoError(t, err)
+	changelogContents := "# TBD\n\n### Features\n* Added a thing\n\n### Fixes\n\n### Breaking changes\n\n# 0.1.0\n* Initial\n"
+
+	changelogFilepath := filepath.Join(t.TempDir(), "changelog.md")
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte(changelogContents), 0644))
+	require.NoError(t, updateChangelog(changelogFilepath, "0.1.1", skeletonLines, changelog.DefaultMaxLineLength))
+	updatedChangelog, err := os.ReadFile(changelogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, "# TBD\n\n"+testTbdSkeleton+"\n# 0.1.1\n\n### Features\n* Added a thing\n\n# 0.1.0\n* Initial\n", string(updatedChangelog))
+}
@@ -0,0 +1,85 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/stacktrace"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ciChecksTimeout = 30 * time.Second
+)
+
+type ciCheckState int
+
+const (
+	passedCiCheckState ciCheckState = iota
+	pendingCiCheckState
+	failedCiCheckState
+)
+
+// verifyCiIsGreen refuses the release unless the required checks (or every check, if none are named) have passed on the commit
+func verifyCiIsGreen(ctx context.Context, releaseForge forge, commitHash string, requiredChecks []string) error {
+	ctx, cancelFunc := context.WithTimeout(ctx, ciChecksTimeout)
+	defer cancelFunc()
+	checkStates, err := releaseForge.getCiCheckStates(ctx, commitHash)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the %s CI checks of commit '%s'", releaseForge.getName(), commitHash)
+	}
+	if err := evaluateCiChecks(checkStates, requiredChecks); err != nil {
+		return stacktrace.Propagate(err, "CI isn't green on commit '%s'", commitHash)
+	}
+	return nil
+}
+
+func evaluateCiChecks(checkStates map[string]ciCheckState, requiredChecks []string) error {
+	checksToEvaluate := requiredChecks
+	if len(checksToEvaluate) == 0 {
+		if len(checkStates) == 0 {
+			return stacktrace.NewError("No CI checks have been reported")
+		}
+		for checkName := range checkStates {
+			checksToEvaluate = append(checksToEvaluate, checkName)
+		}
+		sort.Strings(checksToEvaluate)
+	}
+
+	missingChecks := []string{}
+	pendingChecks := []string{}
+	failedChecks := []string{}
+	for _, checkName := range checksToEvaluate {
+		state, found := checkStates[checkName]
+		switch {
+		case !found:
+			missingChecks = append(missingChecks, checkName)
+		case state == pendingCiCheckState:
+			pendingChecks = append(pendingChecks, checkName)
+		case state == failedCiCheckState:
+			failedChecks = append(failedChecks, checkName)
+		}
+	}
+	if len(failedChecks) > 0 || len(pendingChecks) > 0 || len(missingChecks) > 0 {
+		return stacktrace.NewError(
+			"Failed checks: [%s]; pending checks: [%s]; required checks that haven't been reported: [%s]",
+			strings.Join(failedChecks, ", "),
+			strings.Join(pendingChecks, ", "),
+			strings.Join(missingChecks, ", "),
+		)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// worseCiCheckState lets a check reported several times (e.g. re-runs) count as its least favourable state
+func worseCiCheckState(checkStates map[string]ciCheckState, checkName string, state ciCheckState) ciCheckState {
+	if existingState, found := checkStates[checkName]; found && existingState > state {
+		return existingState
+	}
+	return state
+}
@@ -0,0 +1,24 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateCiChecks(t *testing.T) {
+	checkStates := map[string]ciCheckState{
+		"build": passedCiCheckState,
+		"lint":  pendingCiCheckState,
+		"e2e":   failedCiCheckState,
+		"docs":  passedCiCheckState,
+	}
+	require.NoError(t, evaluateCiChecks(checkStates, []string{"build", "docs"}))
+
+	err := evaluateCiChecks(checkStates, []string{"build", "lint", "e2e", "release"})
+	require.ErrorContains(t, err, "Failed checks: [e2e]; pending checks: [lint]; required checks that haven't been reported: [release]")
+
+	require.Error(t, evaluateCiChecks(checkStates, nil))
+	require.NoError(t, evaluateCiChecks(map[string]ciCheckState{"build": passedCiCheckState}, nil))
+	require.ErrorContains(t, evaluateCiChecks(map[string]ciCheckState{}, nil), "No CI checks")
+}
@@ -0,0 +1,137 @@
+package releaser
+
+import (
+	"bufio"
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// NoConfirmTimeout makes the prompt wait on the operator indefinitely
+	NoConfirmTimeout time.Duration = 0
+
+	// How often the operator is reminded how long they have left to answer
+	confirmCountdownInterval = 10 * time.Second
+)
+
+var affirmativeAnswers = map[string]bool{
+	"y":   true,
+	"yes": true,
+}
+
+// Anything in here is an explicit refusal; an empty answer is included because the prompt defaults to "no"
+var negativeAnswers = map[string]bool{
+	"":   true,
+	"n":  true,
+	"no": true,
+}
+
+// promptInput reads the operator's answers in the background, so that an interrupt or timeout while waiting on them
+// aborts the operation rather than leaving us blocked on input. It's shared by every question asked on the input, so a
+// line typed after an abandoned question goes to the next one rather than to a reader left behind by the first.
+type promptInput struct {
+	reader *bufio.Reader
+
+	startReadingOnce sync.Once
+	lineChan         chan string
+	// Set once the input fails or runs out, before lineChan is closed
+	err error
+}
+
+func newPromptInput(input io.Reader) *promptInput {
+	return &promptInput{
+		reader:           bufio.NewReader(input),
+		startReadingOnce: sync.Once{},
+		lineChan:         make(chan string),
+		err:              nil,
+	}
+}
+
+// NewPromptConfirmer returns a Confirmer that asks the operator its y/N question on the given input, re-asking on
+// anything it doesn't understand; a positive timeout aborts the operation if no answer arrives in time
+func NewPromptConfirmer(input io.Reader, timeout time.Duration) Confirmer {
+	answers := newPromptInput(input)
+	return func(ctx context.Context, question string) (bool, error) {
+		if timeout > NoConfirmTimeout {
+			var cancelFunc context.CancelFunc
+			ctx, cancelFunc = context.WithTimeout(ctx, timeout)
+			defer cancelFunc()
+		}
+		deadline, hasDeadline := ctx.Deadline()
+		lineChan := answers.getLines()
+
+		countdownTicker := time.NewTicker(confirmCountdownInterval)
+		defer countdownTicker.Stop()
+		logVerificationPrompt(question, hasDeadline, deadline)
+		for {
+			select {
+			case line, isOpen := <-lineChan:
+				if !isOpen {
+					return false, stacktrace.Propagate(answers.err, "An error occurred reading the answer to the confirmation prompt")
+				}
+				normalizedAnswer := strings.ToLower(strings.TrimSpace(line))
+				if affirmativeAnswers[normalizedAnswer] {
+					return true, nil
+				}
+				if negativeAnswers[normalizedAnswer] {
+					return false, nil
+				}
+				logrus.Warn(i18n.Sprintf(i18n.UnrecognizedConfirmAnswer, strings.TrimSpace(line)))
+				logVerificationPrompt(question, hasDeadline, deadline)
+			case <-countdownTicker.C:
+				if hasDeadline {
+					logrus.Info(i18n.Sprintf(i18n.ConfirmAutoAbortCountdown, time.Until(deadline).Round(time.Second)))
+				}
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return false, stacktrace.Propagate(ctx.Err(), "No answer to the confirmation prompt was given within %v", timeout)
+				}
+				return false, stacktrace.Propagate(ctx.Err(), "The operation was interrupted while waiting for confirmation")
+			}
+		}
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getLines starts reading the input on the first question asked, so that the default confirmer on stdin leaves it to
+// the rest of kudet for as long as nothing is asked
+func (answers *promptInput) getLines() <-chan string {
+	answers.startReadingOnce.Do(func() {
+		go answers.readLines()
+	})
+	return answers.lineChan
+}
+
+func (answers *promptInput) readLines() {
+	for {
+		line, err := answers.reader.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		if err != nil {
+			// Every question from here on gets the error, rather than waiting on input that won't come
+			answers.err = err
+			close(answers.lineChan)
+			return
+		}
+		answers.lineChan <- line
+	}
+}
+
+func logVerificationPrompt(question string, hasDeadline bool, deadline time.Time) {
+	if hasDeadline {
+		logrus.Info(i18n.Sprintf(i18n.ConfirmPromptWithDeadline, question, time.Until(deadline).Round(time.Second)))
+		return
+	}
+	logrus.Info(i18n.Sprintf(i18n.ConfirmPrompt, question))
+}
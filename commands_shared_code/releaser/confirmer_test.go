@@ -0,0 +1,66 @@
+package releaser
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptConfirmer_Answers(t *testing.T) {
+	isConfirmed, err := NewPromptConfirmer(strings.NewReader("y\n"), NoConfirmTimeout)(context.Background(), "Release new version '0.2.0'?")
+	require.NoError(t, err)
+	require.True(t, isConfirmed)
+
+	isConfirmed, err = NewPromptConfirmer(strings.NewReader("\n"), NoConfirmTimeout)(context.Background(), "Release new version '0.2.0'?")
+	require.NoError(t, err)
+	require.False(t, isConfirmed)
+
+	isConfirmed, err = NewPromptConfirmer(strings.NewReader("sure\nmaybe\nYES"), NoConfirmTimeout)(context.Background(), "Release new version '0.2.0'?")
+	require.NoError(t, err)
+	require.True(t, isConfirmed)
+}
+
+func TestPromptConfirmer_ClosedInputIsAnError(t *testing.T) {
+	_, err := NewPromptConfirmer(strings.NewReader(""), NoConfirmTimeout)(context.Background(), "Release new version '0.2.0'?")
+	require.Error(t, err)
+}
+
+func TestPromptConfirmer_TimesOut(t *testing.T) {
+	blockingReader, writer := io.Pipe()
+	defer writer.Close()
+	isConfirmed, err := NewPromptConfirmer(blockingReader, 10*time.Millisecond)(context.Background(), "Release new version '0.2.0'?")
+	require.ErrorContains(t, err, "No answer")
+	require.False(t, isConfirmed)
+}
+
+func TestPromptConfirmer_AsksSeveralQuestionsOnOneInput(t *testing.T) {
+	inputReader, inputWriter := io.Pipe()
+	confirmer := NewPromptConfirmer(inputReader, NoConfirmTimeout)
+
+	// An abandoned question mustn't leave anything behind that swallows the answers to the next ones
+	interruptedCtx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+	_, err := confirmer(interruptedCtx, "Release new version '0.2.0'?")
+	require.ErrorContains(t, err, "interrupted")
+
+	go func() {
+		for _, answer := range []string{"y\n", "n\n"} {
+			if _, err := inputWriter.Write([]byte(answer)); err != nil {
+				return
+			}
+		}
+		inputWriter.Close()
+	}()
+	isConfirmed, err := confirmer(context.Background(), "Release new version '0.2.1'?")
+	require.NoError(t, err)
+	require.True(t, isConfirmed)
+	isConfirmed, err = confirmer(context.Background(), "Release new version '0.2.2'?")
+	require.NoError(t, err)
+	require.False(t, isConfirmed)
+	_, err = confirmer(context.Background(), "Release new version '0.2.3'?")
+	require.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package releaser
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"strings"
+)
+
+const (
+	pathGlobChars = "*?["
+)
+
+// ConsumerNotes are the release notes of the releases between two versions, narrowed down to the changes that touched
+// some of the repo's paths, for the consumers of e.g. one of its SDKs
+type ConsumerNotes struct {
+	// The releases after the 'from' version up to the 'to' one that have entries touching the paths, latest first
+	Releases []ConsumerReleaseNotes
+
+	// The subject lines of the commits that touched the paths without adding a changelog entry, latest first
+	UnlistedCommitSubjects []string
+}
+
+// ConsumerReleaseNotes are the entries of a release's notes that touched the paths, under their subheaders
+type ConsumerReleaseNotes struct {
+	Version string
+	Lines   []string
+}
+
+// GetConsumerNotes returns the notes of the releases after fromVersion up to toVersion, or the latest release if it's
+// empty, keeping only the changelog entries that were added by commits touching one of the paths. A path is a file or
+// directory relative to the repo root, or a glob. Entries are matched by their text, so an entry reworded after it was
+// added is left out.
+func GetConsumerNotes(repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, fromVersion string, toVersion string, paths []string) (*ConsumerNotes, error) {
+	if toVersion == "" {
+		latestReleaseVersion, err := getLatestReleaseVersion(repository, kudetConfig.TagParsing)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the latest release's version")
+		}
+		toVersion = latestReleaseVersion.String()
+	}
+	fromSemver, err := semver.StrictNewVersion(fromVersion)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "'%s' isn't a semantic version", fromVersion)
+	}
+	toSemver, err := semver.StrictNewVersion(toVersion)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "'%s' isn't a semantic version", toVersion)
+	}
+	if !fromSemver.LessThan(toSemver) {
+		return nil, stacktrace.NewError("Version '%s' must be before version '%s'", fromVersion, toVersion)
+	}
+	fromCommitHash, err := getExistingReleaseCommitHash(repository, fromVersion)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the commit of release '%s'", fromVersion)
+	}
+	toCommitHash, err := getExistingReleaseCommitHash(repository, toVersion)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the commit of release '%s'", toVersion)
+	}
+
+	commits, err := repository.GetCommits(fromCommitHash, toCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the commits between releases '%s' and '%s'", fromVersion, toVersion)
+	}
+	isConsumerEntryLine := map[string]bool{}
+	unlistedCommitSubjects := []string{}
+	for _, commit := range commits {
+		// Merges are skipped, as the commits they merge are walked themselves
+		if len(commit.ParentHashes) != 1 {
+			continue
+		}
+		changedFilepaths, err := repository.GetChangedFilepaths(commit.ParentHashes[0], commit.Hash)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred listing the files changed by commit '%s'", commit.Hash)
+		}
+		if !isAnyFilepathUnderPaths(changedFilepaths, paths) {
+			continue
+		}
+		addedEntryLines, err := getAddedChangelogEntryLines(repository, kudetConfig.ChangelogFilepath, commit)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the changelog entries added by commit '%s'", commit.Hash)
+		}
+		if len(addedEntryLines) == 0 {
+			unlistedCommitSubjects = append(unlistedCommitSubjects, getCommitSubject(commit.Message))
+		}
+		for _, addedEntryLine := range addedEntryLines {
+			isConsumerEntryLine[addedEntryLine] = true
+		}
+	}
+
+	changelogFile, found, err := repository.ReadFileAtCommit(toCommitHash, kudetConfig.ChangelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading changelog '%s' of release '%s'", kudetConfig.ChangelogFilepath, toVersion)
+	}
+	if !found {
+		return nil, stacktrace.NewError("Release '%s' has no changelog at '%s'", toVersion, kudetConfig.ChangelogFilepath)
+	}
+	releases := []ConsumerReleaseNotes{}
+	for _, version := range getChangelogVersions(changelogFile) {
+		if !version.GreaterThan(fromSemver) || version.GreaterThan(toSemver) {
+			continue
+		}
+		lines := filterConsumerEntryLines(getVersionNotesLines(changelogFile, version.Original()), isConsumerEntryLine)
+		if len(lines) > 0 {
+			releases = append(releases, ConsumerReleaseNotes{Version: version.Original(), Lines: lines})
+		}
+	}
+	return &ConsumerNotes{
+		Releases:               releases,
+		UnlistedCommitSubjects: unlistedCommitSubjects,
+	}, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getExistingReleaseCommitHash(repository vcs.Repository, version string) (string, error) {
+	commitHash, found, err := getReleaseCommitHash(repository, version)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred resolving the tag of release '%s'", version)
+	}
+	if !found {
+		return "", stacktrace.NewError("Release '%s' has neither a '%s' nor a '%s%s' tag", version, version, vPrefix, version)
+	}
+	return commitHash, nil
+}
+
+// isAnyFilepathUnderPaths reports whether one of the files is, or is under, one of the paths, or matches one of them if
+// it's a glob
+func isAnyFilepathUnderPaths(filepaths []string, paths []string) bool {
+	for _, filepath := range filepaths {
+		for _, pathStr := range paths {
+			if strings.ContainsAny(pathStr, pathGlobChars) {
+				if isPathMatch(pathStr, filepath) {
+					return true
+				}
+				continue
+			}
+			dirpath := strings.TrimSuffix(pathStr, "/")
+			if filepath == dirpath || strings.HasPrefix(filepath, dirpath+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getAddedChangelogEntryLines returns the non-blank lines that the commit added to the changelog's TBD section, other
+// than headers
+func getAddedChangelogEntryLines(repository vcs.Repository, changelogRelFilepath string, commit vcs.Commit) ([]string, error) {
+	parentTbdLines, err := getTbdLinesAtCommit(repository, changelogRelFilepath, commit.ParentHashes[0])
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the changelog's TBD section at commit '%s'", commit.ParentHashes[0])
+	}
+	tbdLines, err := getTbdLinesAtCommit(repository, changelogRelFilepath, commit.Hash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the changelog's TBD section at commit '%s'", commit.Hash)
+	}
+	addedEntryLines := []string{}
+	for _, diffLine := range diffNotesLines(parentTbdLines, tbdLines) {
+		if !strings.HasPrefix(diffLine, addedLinePrefix) {
+			continue
+		}
+		addedLine := strings.TrimPrefix(diffLine, addedLinePrefix)
+		if strings.TrimSpace(addedLine) != "" && !strings.HasPrefix(addedLine, sectionHeaderPrefix) {
+			addedEntryLines = append(addedEntryLines, addedLine)
+		}
+	}
+	return addedEntryLines, nil
+}
+
+// getTbdLinesAtCommit returns the lines of the changelog's TBD section as of the commit, which are none if the changelog
+// didn't exist then
+func getTbdLinesAtCommit(repository vcs.Repository, changelogRelFilepath string, commitHash string) ([]string, error) {
+	changelogFile, found, err := repository.ReadFileAtCommit(commitHash, changelogRelFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading changelog '%s'", changelogRelFilepath)
+	}
+	if !found {
+		return []string{}, nil
+	}
+	tbdLines, err := getUnreleasedNotesLines(changelogFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the TBD section of changelog '%s'", changelogRelFilepath)
+	}
+	return tbdLines, nil
+}
+
+// getChangelogVersions returns the versions that the changelog has headers for, in the order they're listed
+func getChangelogVersions(changelogFile []byte) []*semver.Version {
+	versions := []*semver.Version{}
+	scanner := newChangelogScanner(changelogFile)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !versionHeaderRegex.MatchString(line) {
+			continue
+		}
+		version, err := semver.StrictNewVersion(strings.TrimSpace(strings.TrimPrefix(line, sectionHeaderPrefix)))
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// filterConsumerEntryLines keeps the release notes' entry lines that are consumer entries, along with the subheaders
+// that any of them are under
+func filterConsumerEntryLines(notesLines []string, isConsumerEntryLine map[string]bool) []string {
+	filteredLines := []string{}
+	pendingSubheader := ""
+	for _, line := range notesLines {
+		if strings.HasPrefix(line, sectionHeaderPrefix) {
+			pendingSubheader = line
+			continue
+		}
+		if !isConsumerEntryLine[line] {
+			continue
+		}
+		if pendingSubheader != "" {
+			if len(filteredLines) > 0 {
+				filteredLines = append(filteredLines, "")
+			}
+			filteredLines = append(filteredLines, pendingSubheader)
+			pendingSubheader = ""
+		}
+		filteredLines = append(filteredLines, line)
+	}
+	return filteredLines
+}
+
+func getCommitSubject(commitMessage string) string {
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(commitMessage), "\n", 2)[0])
+}
@@ -0,0 +1,77 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConsumerNotes(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	changelogFilepath := filepath.Join(repoDirpath, "changelog.md")
+	commit := func(message string, filesContents map[string]string) string {
+		for relFilepath, contents := range filesContents {
+			require.NoError(t, os.MkdirAll(filepath.Join(repoDirpath, filepath.Dir(relFilepath)), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, relFilepath), []byte(contents), 0644))
+		}
+		commitHash, err := repository.CommitAll(message, &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+		require.NoError(t, err)
+		return commitHash
+	}
+	release := func(version string) {
+		require.NoError(t, updateChangelog(changelogFilepath, version, nil, changelog.DefaultMaxLineLength))
+		require.NoError(t, repository.CreateTag(version, commit("Release "+version, nil), version))
+	}
+
+	commit("Initial commit", map[string]string{"changelog.md": "# TBD\n\n# 1.3.0\n* Old change\n", "sdk/typescript/index.ts": "v1", "engine/main.go": "v1"})
+	require.NoError(t, repository.CreateTag("1.3.0", commit("Release 1.3.0", map[string]string{"engine/main.go": "v1.3"}), "1.3.0"))
+	commit("Add SDK retries", map[string]string{"changelog.md": "# TBD\n### Features\n* SDK: add retries\n\n# 1.3.0\n* Old change\n", "sdk/typescript/index.ts": "v2"})
+	commit("Speed up scheduling", map[string]string{"changelog.md": "# TBD\n### Features\n* SDK: add retries\n* Engine: faster scheduling\n\n# 1.3.0\n* Old change\n", "engine/main.go": "v2"})
+	commit("Fix SDK typo\n\nIt was in the readme.", map[string]string{"sdk/typescript/README.md": "Typo fixed"})
+	release("1.4.0")
+	changelog, err := os.ReadFile(changelogFilepath)
+	require.NoError(t, err)
+	commit("Fix SDK timeouts", map[string]string{"changelog.md": "# TBD\n### Fixes\n* SDK: fix timeouts\n" + string(changelog)[len("# TBD\n"):], "sdk/typescript/index.ts": "v3"})
+	release("1.5.0")
+
+	kudetConfig := &kudet_config.KudetConfig{ChangelogFilepath: "changelog.md"}
+	notes, err := GetConsumerNotes(repository, kudetConfig, "1.3.0", "", []string{"sdk/typescript"})
+	require.NoError(t, err)
+	require.Equal(t, []ConsumerReleaseNotes{
+		{Version: "1.5.0", Lines: []string{"### Fixes", "* SDK: fix timeouts"}},
+		{Version: "1.4.0", Lines: []string{"### Features", "* SDK: add retries"}},
+	}, notes.Releases)
+	require.Equal(t, []string{"Fix SDK typo"}, notes.UnlistedCommitSubjects)
+
+	notes, err = GetConsumerNotes(repository, kudetConfig, "1.4.0", "1.5.0", []string{"engine/*.go"})
+	require.NoError(t, err)
+	require.Empty(t, notes.Releases)
+	require.Empty(t, notes.UnlistedCommitSubjects)
+
+	_, err = GetConsumerNotes(repository, kudetConfig, "1.5.0", "1.4.0", []string{"sdk/typescript"})
+	require.Error(t, err)
+	_, err = GetConsumerNotes(repository, kudetConfig, "1.2.0", "1.4.0", []string{"sdk/typescript"})
+	require.ErrorContains(t, err, "Release '1.2.0' has neither")
+}
+
+func TestIsAnyFilepathUnderPaths(t *testing.T) {
+	require.True(t, isAnyFilepathUnderPaths([]string{"docs/a.md", "sdk/typescript/src/index.ts"}, []string{"sdk/typescript"}))
+	require.True(t, isAnyFilepathUnderPaths([]string{"sdk/typescript"}, []string{"sdk/typescript/"}))
+	require.False(t, isAnyFilepathUnderPaths([]string{"sdk/typescript-legacy/index.ts"}, []string{"sdk/typescript"}))
+	require.True(t, isAnyFilepathUnderPaths([]string{"api/v1.proto"}, []string{"api/*.proto"}))
+	require.False(t, isAnyFilepathUnderPaths([]string{"api/v1/service.proto"}, []string{"api/*.proto"}))
+}
@@ -0,0 +1,160 @@
+package releaser
+
+import (
+	"bytes"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// downstreamManifest is the parsed form of the repo's list of downstream consumers; forges don't expose who depends on
+// a repo through their APIs, so teams maintain the list themselves
+type downstreamManifest struct {
+	Consumers []downstreamConsumer `yaml:"consumers"`
+}
+
+// downstreamConsumer is a repo or service that consumes the repo being released
+type downstreamConsumer struct {
+	Name string `yaml:"name"`
+
+	// Where the consumer lives, e.g. its repo URL, purely for the report
+	Repo string `yaml:"repo,omitempty"`
+
+	// Who to talk to about breaking changes, e.g. team handles or email addresses
+	Owners []string `yaml:"owners,omitempty"`
+
+	// URL that receives an HTTP POST with a JSON description of each release, when owner notifications are enabled
+	WebhookUrl string `yaml:"webhook-url,omitempty"`
+}
+
+// downstreamNotification is the JSON body POSTed to a consumer's webhook
+type downstreamNotification struct {
+	*releaseNotification
+	Consumer string   `json:"consumer"`
+	Owners   []string `json:"owners,omitempty"`
+	// Whether the release bumps the major or minor version rather than just the patch version
+	IsBreaking bool `json:"isBreaking"`
+}
+
+// loadDownstreamConsumers reads the repo's downstream manifest, returning no consumers if there isn't one
+func loadDownstreamConsumers(repoDirpath string, downstreamConfig kudet_config.DownstreamConfig) ([]downstreamConsumer, error) {
+	manifestFilepath := filepath.Join(repoDirpath, downstreamConfig.ManifestFilepath)
+	manifestBytes, err := os.ReadFile(manifestFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, stacktrace.Propagate(err, "An error occurred reading the downstream manifest at '%s'", manifestFilepath)
+	}
+	consumers, err := parseDownstreamManifest(manifestBytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the downstream manifest at '%s'", manifestFilepath)
+	}
+	return consumers, nil
+}
+
+// logBlastRadius lists the consumers that the release reaches, so the operator sees them before confirming
+func logBlastRadius(version string, isBreaking bool, consumers []downstreamConsumer) {
+	if len(consumers) == 0 {
+		return
+	}
+	releaseKind := "non-breaking"
+	if isBreaking {
+		releaseKind = "BREAKING"
+	}
+	logrus.Infof("Version '%s' is a %s release consumed downstream by:\n%s", version, releaseKind, renderDownstreamConsumers(consumers))
+}
+
+// reportReleaseDownstream summarizes the consumers that a successful release reaches and, if enabled, notifies their
+// owners; like the other notifications, failures are only logged because the release is irreversible by now
+func (releaser *Releaser) reportReleaseDownstream(downstreamConfig kudet_config.DownstreamConfig, notification *releaseNotification) {
+	consumers, err := loadDownstreamConsumers(releaser.repoDirpath, downstreamConfig)
+	if err != nil {
+		logrus.Warnf("An error occurred loading the downstream consumers of release '%s'; the release itself succeeded:\n%v", notification.Version, err)
+		return
+	}
+	if len(consumers) == 0 {
+		return
+	}
+	isBreaking := isBreakingRelease(notification.Version, notification.PreviousVersion)
+	logrus.Infof("Released version '%s' reaches %d downstream consumer(s):\n%s", notification.Version, len(consumers), renderDownstreamConsumers(consumers))
+	if !downstreamConfig.NotifyOwners {
+		return
+	}
+	logrus.Infof("Notifying downstream owners...")
+	httpClient := &http.Client{Timeout: notificationTimeout}
+	for _, consumer := range consumers {
+		if consumer.WebhookUrl == "" {
+			continue
+		}
+		consumerNotification := &downstreamNotification{
+			releaseNotification: notification,
+			Consumer:            consumer.Name,
+			Owners:              consumer.Owners,
+			IsBreaking:          isBreaking,
+		}
+		if err := sendReleaseNotification(httpClient, consumer.WebhookUrl, consumerNotification); err != nil {
+			logrus.Warnf("An error occurred notifying the owners of downstream consumer '%s' of release '%s'; the release itself succeeded:\n%v", consumer.Name, notification.Version, err)
+		}
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func parseDownstreamManifest(manifestBytes []byte) ([]downstreamConsumer, error) {
+	manifest := &downstreamManifest{}
+	decoder := yaml.NewDecoder(bytes.NewReader(manifestBytes))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(manifest); err != nil && err != io.EOF {
+		return nil, stacktrace.Propagate(err, "An error occurred decoding the downstream manifest YAML")
+	}
+	seenNames := map[string]bool{}
+	for _, consumer := range manifest.Consumers {
+		if strings.TrimSpace(consumer.Name) == "" {
+			return nil, stacktrace.NewError("Downstream consumers must have a name")
+		}
+		if seenNames[consumer.Name] {
+			return nil, stacktrace.NewError("Downstream consumer '%s' is listed more than once", consumer.Name)
+		}
+		seenNames[consumer.Name] = true
+	}
+	return manifest.Consumers, nil
+}
+
+// isBreakingRelease follows the changelog's convention that breaking changes bump at least the minor version
+func isBreakingRelease(version string, previousVersion string) bool {
+	parsedVersion, err := semver.NewVersion(version)
+	if err != nil {
+		return true
+	}
+	parsedPreviousVersion, err := semver.NewVersion(previousVersion)
+	if err != nil {
+		return true
+	}
+	return parsedVersion.Major() != parsedPreviousVersion.Major() || parsedVersion.Minor() != parsedPreviousVersion.Minor()
+}
+
+func renderDownstreamConsumers(consumers []downstreamConsumer) string {
+	lines := []string{}
+	for _, consumer := range consumers {
+		line := "  - " + consumer.Name
+		if consumer.Repo != "" {
+			line += " (" + consumer.Repo + ")"
+		}
+		if len(consumer.Owners) > 0 {
+			line += ", owned by " + strings.Join(consumer.Owners, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,41 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDownstreamManifest(t *testing.T) {
+	manifest := `consumers:
+  - name: kurtosis-cli
+    repo: https://github.com/kurtosis-tech/kurtosis-cli
+    owners: ["@kurtosis-tech/cli"]
+    webhook-url: https://hooks.example.com/cli
+  - name: engine
+`
+	consumers, err := parseDownstreamManifest([]byte(manifest))
+	require.NoError(t, err)
+	require.Len(t, consumers, 2)
+	require.Equal(t, []string{"@kurtosis-tech/cli"}, consumers[0].Owners)
+	require.Equal(
+		t,
+		"  - kurtosis-cli (https://github.com/kurtosis-tech/kurtosis-cli), owned by @kurtosis-tech/cli\n  - engine",
+		renderDownstreamConsumers(consumers),
+	)
+
+	_, err = parseDownstreamManifest([]byte("consumers:\n  - name: engine\n  - name: engine\n"))
+	require.ErrorContains(t, err, "listed more than once")
+
+	_, err = parseDownstreamManifest([]byte("consumers:\n  - repo: https://github.com/kurtosis-tech/engine\n"))
+	require.ErrorContains(t, err, "must have a name")
+
+	_, err = parseDownstreamManifest([]byte("consumers:\n  - name: engine\n    owner: someone\n"))
+	require.Error(t, err)
+}
+
+func TestIsBreakingRelease(t *testing.T) {
+	require.False(t, isBreakingRelease("0.3.2", "0.3.1"))
+	require.True(t, isBreakingRelease("0.4.0", "0.3.1"))
+	require.True(t, isBreakingRelease("1.0.0", "0.3.1"))
+}
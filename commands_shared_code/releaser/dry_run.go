@@ -0,0 +1,25 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/sirupsen/logrus"
+)
+
+// isDryRunStep is whether the kudet config has the release step only log what it would do
+func isDryRunStep(dryRunSteps []string, step string) bool {
+	for _, dryRunStep := range dryRunSteps {
+		if dryRunStep == step {
+			return true
+		}
+	}
+	return false
+}
+
+// skipDryRunStep logs that the release step is skipped if the kudet config dry-runs it, returning whether it is
+func skipDryRunStep(dryRunSteps []string, step string, stepDescription string) bool {
+	if !isDryRunStep(dryRunSteps, step) {
+		return false
+	}
+	logrus.Infof("Dry run: not %s, as the kudet config's '%s' asks", stepDescription, kudet_config.DryRunStepsKey)
+	return true
+}
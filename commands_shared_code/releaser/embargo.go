@@ -0,0 +1,180 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Embargoed release commits are held under this prefix rather than on the release branch or under tags, so that
+	// neither a plain 'git push' nor 'git push --tags' can leak them before the embargo lifts
+	embargoRefPrefix = "refs/kudet-embargo/"
+)
+
+// LiftEmbargo pushes an embargoed release prepared by an earlier 'Release' with the embargo option, publishing its
+// draft forge release if it has one, or creating its forge release on forges without drafts
+func (releaser *Releaser) LiftEmbargo(ctx context.Context, version string) error {
+	kudetConfig, err := releaser.getKudetConfig()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the kudet config for the repo")
+	}
+	releaseBranchName := kudetConfig.ReleaseBranch
+	if _, err := getEnvironmentsToPromote(kudetConfig.Environments, releaser.promotedEnvironmentNames); err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the environments to promote the release to")
+	}
+
+	repository, err := releaser.openRepository(releaser.repoDirpath, releaser.token)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	if err := releaser.authenticateAsGithubAppIfNeeded(ctx, repository, kudetConfig); err != nil {
+		return stacktrace.Propagate(err, "An error occurred authenticating as the kudet config's GitHub App")
+	}
+	stateStore := newReleaseStateStore(repository.GetMetadataDirpath(), kudetConfig.ReleaseState)
+	state, err := loadReleaseState(ctx, stateStore)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the embargoed release")
+	}
+	if state == nil || !state.IsEmbargoed || state.Version != version {
+		return stacktrace.NewError("No embargoed release of version '%s' was found at '%s'; embargoes can only be lifted from the clone that prepared them", version, stateStore.getLocation(releaseStateFilename))
+	}
+
+	isClean, currWorktreeStatusStr, err := repository.GetStatus()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while trying to retrieve the status of the worktree of the repository.")
+	}
+	if !isClean {
+		return stacktrace.NewError("The branch contains modified files. Please ensure the working tree is clean before lifting the embargo. Currently the status is '%s'\n", currWorktreeStatusStr)
+	}
+
+	logrus.Infof("Checking that '%s' hasn't moved since the embargoed release was prepared...", releaseBranchName)
+	if err := verifyRemoteBranchHasNotMoved(ctx, repository, releaseBranchName, state.BaseCommitHash); err != nil {
+		return stacktrace.Propagate(err, "Can't lift the embargo on version '%s' because its release commit no longer applies on top of the remote branch; abandon it by running 'kudet release-state abort' and deleting ref '%s%s', then release again", version, embargoRefPrefix, version)
+	}
+
+	if err := repository.CheckoutBranch(releaseBranchName); err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking out branch '%s'", releaseBranchName)
+	}
+	if err := repository.ResetHard(state.ReleaseCommitHash); err != nil {
+		return stacktrace.Propagate(err, "An error occurred moving '%s' onto embargoed release commit '%s'", releaseBranchName, state.ReleaseCommitHash)
+	}
+
+	// From here on the release is an ordinary in-progress one, which 'kudet release' resumes if we die partway through
+	state.IsEmbargoed = false
+	if err := saveReleaseState(ctx, stateStore, state); err != nil {
+		return stacktrace.Propagate(err, "An error occurred recording that the embargo on version '%s' is being lifted", version)
+	}
+	logrus.Infof("Pushing embargoed release '%s'...", version)
+	if err := resumeRelease(ctx, repository, releaseBranchName, state); err != nil {
+		return stacktrace.Propagate(err, "An error occurred pushing embargoed release '%s'; run 'kudet release' to finish pushing it", version)
+	}
+
+	if state.DraftReleaseId != 0 || state.IsForgeReleasePending {
+		if err := releaser.publishEmbargoedForgeRelease(ctx, repository, kudetConfig.Forge, state); err != nil {
+			logrus.Errorf("ACTION REQUIRED: Version '%s' was pushed, but an error occurred publishing its forge release; publish it by hand:\n%v", version, err)
+		}
+	}
+
+	if err := removeReleaseState(ctx, stateStore); err != nil {
+		return stacktrace.Propagate(err, "The embargo on version '%s' was lifted, but an error occurred cleaning up its release state", version)
+	}
+	if err := repository.DeleteRef(embargoRefPrefix + version); err != nil {
+		logrus.Warnf("An error occurred deleting staging ref '%s%s'; it can be deleted by hand:\n%v", embargoRefPrefix, version, err)
+	}
+
+	logrus.Infof("Release success.")
+	releaser.runPostReleaseSteps(ctx, repository, kudetConfig, state)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// stageEmbargoedRelease holds the committed release under a staging ref and drafts its forge release, recording it so
+// that 'kudet lift-embargo' can push it later; the caller moves the release branch back off the release commit
+func (releaser *Releaser) stageEmbargoedRelease(ctx context.Context, repository vcs.Repository, forgeConfig kudet_config.ForgeConfig, stateStore releaseStateStore, state *releaseState) error {
+	stagingRefName := embargoRefPrefix + state.Version
+	if err := repository.SetRef(stagingRefName, state.ReleaseCommitHash); err != nil {
+		return stacktrace.Propagate(err, "An error occurred holding the release commit under staging ref '%s'", stagingRefName)
+	}
+	shouldDeleteStagingRef := true
+	defer func() {
+		if shouldDeleteStagingRef {
+			if err := repository.DeleteRef(stagingRefName); err != nil {
+				logrus.Errorf("ACTION REQUIRED: An error occurred deleting staging ref '%s'. Please run 'git update-ref -d %s' to delete it manually.", stagingRefName, stagingRefName)
+			}
+		}
+	}()
+
+	releaseForge, err := releaser.getRemoteForge(repository, forgeConfig)
+	switch {
+	case err != nil:
+		logrus.Infof("No forge release is staged because the forge of remote '%s' couldn't be determined: %v", originRemoteName, stacktrace.RootCause(err))
+	case releaseForge.supportsDraftReleases():
+		logrus.Infof("Creating a draft %s release for version '%s'...", releaseForge.getName(), state.Version)
+		draftReleaseId, err := releaseForge.createRelease(ctx, state.Version, state.ReleaseNotes, true)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred creating the draft release of version '%s'", state.Version)
+		}
+		state.DraftReleaseId = draftReleaseId
+	default:
+		logrus.Infof("%s has no draft releases, so the release of version '%s' will be created when the embargo lifts", releaseForge.getName(), state.Version)
+		state.IsForgeReleasePending = true
+	}
+
+	state.IsEmbargoed = true
+	if err := saveReleaseState(ctx, stateStore, state); err != nil {
+		if state.DraftReleaseId != 0 {
+			logrus.Errorf("ACTION REQUIRED: The draft %s release of version '%s' was created but the embargo couldn't be recorded; delete the draft by hand.", releaseForge.getName(), state.Version)
+		}
+		return stacktrace.Propagate(err, "An error occurred recording the embargoed release")
+	}
+	shouldDeleteStagingRef = false
+
+	logrus.Infof(
+		"Version '%s' is prepared under embargo and nothing has been pushed; at the coordinated disclosure time, run 'kudet lift-embargo <token> %s' from this clone",
+		state.Version,
+		state.Version,
+	)
+	return nil
+}
+
+// publishEmbargoedForgeRelease publishes the draft release staged for the embargoed release, or creates the release
+// outright on forges without drafts
+func (releaser *Releaser) publishEmbargoedForgeRelease(ctx context.Context, repository vcs.Repository, forgeConfig kudet_config.ForgeConfig, state *releaseState) error {
+	releaseForge, err := releaser.getRemoteForge(repository, forgeConfig)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the forge to publish the release on")
+	}
+	if state.DraftReleaseId != 0 {
+		logrus.Infof("Publishing the draft %s release of version '%s'...", releaseForge.getName(), state.Version)
+		if err := releaseForge.publishDraftRelease(ctx, state.DraftReleaseId); err != nil {
+			return stacktrace.Propagate(err, "An error occurred publishing draft release '%d'", state.DraftReleaseId)
+		}
+		return nil
+	}
+	logrus.Infof("Creating the %s release of version '%s'...", releaseForge.getName(), state.Version)
+	if _, err := releaseForge.createRelease(ctx, state.Version, state.ReleaseNotes, false); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating the release of version '%s'", state.Version)
+	}
+	return nil
+}
+
+// getEmbargoedReleaseError explains how to proceed when a release is attempted while another is under embargo
+func getEmbargoedReleaseError(state *releaseState) error {
+	return stacktrace.NewErrorWithCode(
+		ErrReleaseEmbargoed.code,
+		"Version '%s' is prepared under embargo; run 'kudet lift-embargo <token> %s' at the disclosure time, or abandon it by running 'kudet release-state abort' and deleting ref '%s%s' (e.g. 'git update-ref -d %s%s')",
+		state.Version,
+		state.Version,
+		embargoRefPrefix,
+		state.Version,
+		embargoRefPrefix,
+		state.Version,
+	)
+}
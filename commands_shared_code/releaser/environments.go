@@ -0,0 +1,211 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/version_file_updater"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"path/filepath"
+	"strings"
+)
+
+// pinnedVersion is the version that an environment's manifest is expected to pin
+type pinnedVersion struct {
+	environment kudet_config.EnvironmentManifestConfig
+	version     string
+}
+
+// getEnvironmentsToPromote picks the environments a release is promoted to, in config order: those promoted to on every
+// release, plus the requested ones, which must all be configured
+func getEnvironmentsToPromote(environmentsConfig kudet_config.EnvironmentsConfig, requestedEnvironmentNames []string) ([]kudet_config.EnvironmentManifestConfig, error) {
+	isRequested := map[string]bool{}
+	for _, environmentName := range requestedEnvironmentNames {
+		isRequested[environmentName] = true
+	}
+	environments := []kudet_config.EnvironmentManifestConfig{}
+	for _, manifest := range environmentsConfig.Manifests {
+		if manifest.AutoPromote || isRequested[manifest.Name] {
+			environments = append(environments, manifest)
+		}
+		delete(isRequested, manifest.Name)
+	}
+	for _, environmentName := range requestedEnvironmentNames {
+		if isRequested[environmentName] {
+			return nil, stacktrace.NewError("Can't promote to environment '%s' as it isn't configured under '%s.%s' in the kudet config", environmentName, kudet_config.EnvironmentsKey, kudet_config.EnvironmentManifestsKey)
+		}
+	}
+	return environments, nil
+}
+
+// PromoteEnvironment promotes a version that's already deployed to one environment on to another, once the confirmer
+// approves it; the promotion is recorded as a commit in the GitOps repo
+func (releaser *Releaser) PromoteEnvironment(ctx context.Context, version string, fromEnvironmentName string, toEnvironmentName string) error {
+	kudetConfig, err := releaser.getKudetConfig()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the kudet config for the repo")
+	}
+	if fromEnvironmentName == toEnvironmentName {
+		return stacktrace.NewError("Can't promote environment '%s' to itself", fromEnvironmentName)
+	}
+	fromEnvironment, found := getEnvironment(kudetConfig.Environments, fromEnvironmentName)
+	if !found {
+		return stacktrace.NewError("Can't promote from environment '%s' as it isn't configured under '%s.%s' in the kudet config", fromEnvironmentName, kudet_config.EnvironmentsKey, kudet_config.EnvironmentManifestsKey)
+	}
+	toEnvironment, found := getEnvironment(kudetConfig.Environments, toEnvironmentName)
+	if !found {
+		return stacktrace.NewError("Can't promote to environment '%s' as it isn't configured under '%s.%s' in the kudet config", toEnvironmentName, kudet_config.EnvironmentsKey, kudet_config.EnvironmentManifestsKey)
+	}
+
+	logrus.Infof("Version '%s' is about to be promoted from environment '%s' to '%s'", version, fromEnvironmentName, toEnvironmentName)
+	isConfirmed, err := releaser.confirmer(ctx, i18n.Sprintf(i18n.ConfirmPromoteQuestion, version, fromEnvironmentName, toEnvironmentName))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting approval to promote version '%s'", version)
+	}
+	if !isConfirmed {
+		return stacktrace.NewError("Promotion of version '%s' to environment '%s' wasn't approved", version, toEnvironmentName)
+	}
+
+	commitMsg := fmt.Sprintf("Promote %s %s from %s to %s", filepath.Base(releaser.repoDirpath), version, fromEnvironmentName, toEnvironmentName)
+	expectedPinnedVersions := []pinnedVersion{{environment: fromEnvironment, version: version}}
+	if err := releaser.pinEnvironmentVersions(ctx, kudetConfig.Environments, []kudet_config.EnvironmentManifestConfig{toEnvironment}, version, commitMsg, expectedPinnedVersions); err != nil {
+		return stacktrace.Propagate(err, "An error occurred promoting version '%s' from environment '%s' to '%s'", version, fromEnvironmentName, toEnvironmentName)
+	}
+	logrus.Infof("Promoted version '%s' from environment '%s' to '%s'", version, fromEnvironmentName, toEnvironmentName)
+	return nil
+}
+
+// pinEnvironmentVersions pins the version in the manifests of the given environments, committing them to the GitOps
+// repo and pushing them to its branch; the GitOps repo is reset if anything fails before the push goes through. Nothing
+// is changed unless the manifests pin the expected versions beforehand.
+func (releaser *Releaser) pinEnvironmentVersions(ctx context.Context, environmentsConfig kudet_config.EnvironmentsConfig, environments []kudet_config.EnvironmentManifestConfig, version string, commitMsg string, expectedPinnedVersions []pinnedVersion) error {
+	gitopsRepoDirpath := getGitopsRepoDirpath(releaser.repoDirpath, environmentsConfig)
+	gitopsRepository, err := releaser.openRepository(gitopsRepoDirpath, releaser.token)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the GitOps repo at '%s'", gitopsRepoDirpath)
+	}
+	isClean, statusStr, err := gitopsRepository.GetStatus()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred retrieving the status of the GitOps repo")
+	}
+	if !isClean {
+		return stacktrace.NewError("The GitOps repo at '%s' contains modified files; its working tree must be clean to change environments. Currently the status is '%s'", gitopsRepoDirpath, statusStr)
+	}
+
+	branchName := environmentsConfig.Branch
+	if err := gitopsRepository.Fetch(ctx); err != nil {
+		return stacktrace.Propagate(err, "An error occurred fetching the GitOps repo")
+	}
+	remoteBranchName := fmt.Sprintf("%v/%v", originRemoteName, branchName)
+	remoteBranchHash, err := gitopsRepository.ResolveRevision(remoteBranchName)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing revision '%v' of the GitOps repo", remoteBranchName)
+	}
+	localBranchHash, err := gitopsRepository.ResolveRevision(branchName)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing revision '%v' of the GitOps repo", branchName)
+	}
+	if localBranchHash != remoteBranchHash {
+		return stacktrace.NewError("The GitOps repo's local '%s' branch is not in sync with '%s'; it must be in sync to change environments", branchName, remoteBranchName)
+	}
+	if err := gitopsRepository.CheckoutBranch(branchName); err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking out branch '%s' of the GitOps repo", branchName)
+	}
+	for _, expectedPinnedVersion := range expectedPinnedVersions {
+		manifestFilepath := filepath.Join(gitopsRepoDirpath, expectedPinnedVersion.environment.Filepath)
+		currentVersion, err := version_file_updater.GetVersionInFile(manifestFilepath, expectedPinnedVersion.environment.Pattern)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred reading the version deployed to environment '%s' from '%s'", expectedPinnedVersion.environment.Name, manifestFilepath)
+		}
+		if currentVersion != expectedPinnedVersion.version {
+			return stacktrace.NewError("Environment '%s' is on version '%s' rather than '%s'", expectedPinnedVersion.environment.Name, currentVersion, expectedPinnedVersion.version)
+		}
+	}
+	author, err := gitopsRepository.GetAuthor()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the author of the GitOps commit")
+	}
+
+	shouldResetBranch := true
+	defer func() {
+		if shouldResetBranch {
+			if err := gitopsRepository.ResetHard(remoteBranchHash); err != nil {
+				logrus.Errorf("ACTION REQUIRED: An error occurred resetting the GitOps repo's '%s' branch back to '%s'. Please run 'git reset --hard %s' in '%s' to reset it manually.", branchName, remoteBranchHash, remoteBranchHash, gitopsRepoDirpath)
+			}
+		}
+	}()
+
+	environmentNames := []string{}
+	for _, environment := range environments {
+		manifestFilepath := filepath.Join(gitopsRepoDirpath, environment.Filepath)
+		if err := version_file_updater.UpdateVersionInFile(manifestFilepath, environment.Pattern, version); err != nil {
+			return stacktrace.Propagate(err, "An error occurred pinning version '%s' in the manifest of environment '%s' at '%s'", version, environment.Name, manifestFilepath)
+		}
+		environmentNames = append(environmentNames, environment.Name)
+	}
+	isClean, _, err = gitopsRepository.GetStatus()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred retrieving the status of the GitOps repo after updating its manifests")
+	}
+	if isClean {
+		logrus.Infof("Environments '%s' were already on version '%s'", strings.Join(environmentNames, ", "), version)
+		return nil
+	}
+	if _, err := gitopsRepository.CommitAll(commitMsg, author); err != nil {
+		return stacktrace.Propagate(err, "An error occurred committing to the GitOps repo")
+	}
+	branchRefSpec := fmt.Sprintf("%s%s:%s%s", headRef, branchName, headRef, branchName)
+	if err := gitopsRepository.PushWithLease(ctx, branchRefSpec, headRef+branchName, remoteBranchHash); err != nil {
+		return stacktrace.Propagate(err, "An error occurred pushing to '%s'; if it moved in the meantime, try again", remoteBranchName)
+	}
+	shouldResetBranch = false
+	return nil
+}
+
+// promoteReleaseIfNeeded promotes the released version to its environments; by the time this runs the release is
+// irreversible, so failures are only logged for the operator to promote by hand. The promoted environments are returned.
+func (releaser *Releaser) promoteReleaseIfNeeded(ctx context.Context, environmentsConfig kudet_config.EnvironmentsConfig, version string) []string {
+	environments, err := getEnvironmentsToPromote(environmentsConfig, releaser.promotedEnvironmentNames)
+	if err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred determining the environments to promote version '%s' to; please promote it by hand:\n%v", version, err)
+		return nil
+	}
+	if len(environments) == 0 {
+		return nil
+	}
+	environmentNames := []string{}
+	for _, environment := range environments {
+		environmentNames = append(environmentNames, environment.Name)
+	}
+	logrus.Infof("Promoting version '%s' to environments '%s'...", version, strings.Join(environmentNames, ", "))
+	commitMsg := fmt.Sprintf("Promote %s %s to %s", filepath.Base(releaser.repoDirpath), version, strings.Join(environmentNames, ", "))
+	if err := releaser.pinEnvironmentVersions(ctx, environmentsConfig, environments, version, commitMsg, nil); err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred promoting version '%s' to environments '%s'; please promote it by hand:\n%v", version, strings.Join(environmentNames, ", "), err)
+		return nil
+	}
+	logrus.Infof("Promoted version '%s' to environments '%s'", version, strings.Join(environmentNames, ", "))
+	return environmentNames
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getEnvironment(environmentsConfig kudet_config.EnvironmentsConfig, environmentName string) (kudet_config.EnvironmentManifestConfig, bool) {
+	for _, manifest := range environmentsConfig.Manifests {
+		if manifest.Name == environmentName {
+			return manifest, true
+		}
+	}
+	return kudet_config.EnvironmentManifestConfig{}, false
+}
+
+func getGitopsRepoDirpath(repoDirpath string, environmentsConfig kudet_config.EnvironmentsConfig) string {
+	if filepath.IsAbs(environmentsConfig.GitopsRepoDirpath) {
+		return filepath.Clean(environmentsConfig.GitopsRepoDirpath)
+	}
+	return filepath.Join(repoDirpath, environmentsConfig.GitopsRepoDirpath)
+}
@@ -0,0 +1,38 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetEnvironmentsToPromote(t *testing.T) {
+	environmentsConfig := kudet_config.EnvironmentsConfig{
+		GitopsRepoDirpath: "../gitops",
+		Branch:            "main",
+		Manifests: []kudet_config.EnvironmentManifestConfig{
+			{Name: "staging", Filepath: "environments/staging/versions.yml", Pattern: "kudet: %s", AutoPromote: true},
+			{Name: "prod", Filepath: "environments/prod/versions.yml", Pattern: "kudet: %s"},
+		},
+	}
+
+	environments, err := getEnvironmentsToPromote(environmentsConfig, nil)
+	require.NoError(t, err)
+	require.Len(t, environments, 1)
+	require.Equal(t, "staging", environments[0].Name)
+
+	environments, err = getEnvironmentsToPromote(environmentsConfig, []string{"prod", "staging"})
+	require.NoError(t, err)
+	require.Len(t, environments, 2)
+	require.Equal(t, "staging", environments[0].Name)
+	require.Equal(t, "prod", environments[1].Name)
+
+	_, err = getEnvironmentsToPromote(environmentsConfig, []string{"production"})
+	require.ErrorContains(t, err, "environment 'production' as it isn't configured")
+}
+
+func TestGetEnvironmentsToPromote_NoEnvironments(t *testing.T) {
+	environments, err := getEnvironmentsToPromote(kudet_config.EnvironmentsConfig{}, nil)
+	require.NoError(t, err)
+	require.Empty(t, environments)
+}
@@ -0,0 +1,216 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/stacktrace"
+)
+
+// These codes are carried by the errors' stacktraces, and are never reused once released
+const (
+	dirtyWorktreeErrorCode stacktrace.ErrorCode = iota + 1
+	outOfSyncErrorCode
+	changelogInvalidErrorCode
+	pushRejectedErrorCode
+	invalidConfigErrorCode
+	releaseTagExistsErrorCode
+	ciNotGreenErrorCode
+	releaseNotesUnapprovedErrorCode
+	preReleaseScriptFailedErrorCode
+	releaseEmbargoedErrorCode
+	commitHookRejectedErrorCode
+	policyDeniedErrorCode
+	tagSignatureInvalidErrorCode
+	releaseLockedErrorCode
+	versionFileOutOfSyncErrorCode
+	mirrorPushFailedErrorCode
+	changelogSectionMissingErrorCode
+	buildFailedErrorCode
+	onlyNonReleasableChangesErrorCode
+	releasedTooRecentlyErrorCode
+	artifactAnomalyErrorCode
+	releaseCancelledErrorCode
+	notOnReleaseBranchErrorCode
+	releaseNotApprovedErrorCode
+)
+
+// ReleaseError is a kind of release failure that embedders can handle programmatically, along with what to do about it.
+// The releaser's errors are stacktraces that keep their kind as they're propagated, so use GetReleaseError rather
+// than errors.Is to find it.
+type ReleaseError struct {
+	code stacktrace.ErrorCode
+
+	// A short name for the kind of failure that won't change between versions, e.g. 'dirty-worktree'
+	Name string
+
+	remediationMessageId i18n.MessageId
+}
+
+var (
+	ErrDirtyWorktree = &ReleaseError{
+		code:                 dirtyWorktreeErrorCode,
+		Name:                 "dirty-worktree",
+		remediationMessageId: i18n.DirtyWorktreeRemediation,
+	}
+	ErrOutOfSync = &ReleaseError{
+		code:                 outOfSyncErrorCode,
+		Name:                 "out-of-sync",
+		remediationMessageId: i18n.OutOfSyncRemediation,
+	}
+	ErrChangelogInvalid = &ReleaseError{
+		code:                 changelogInvalidErrorCode,
+		Name:                 "changelog-invalid",
+		remediationMessageId: i18n.ChangelogInvalidRemediation,
+	}
+	ErrPushRejected = &ReleaseError{
+		code:                 pushRejectedErrorCode,
+		Name:                 "push-rejected",
+		remediationMessageId: i18n.PushRejectedRemediation,
+	}
+	ErrInvalidConfig = &ReleaseError{
+		code:                 invalidConfigErrorCode,
+		Name:                 "invalid-config",
+		remediationMessageId: i18n.InvalidConfigRemediation,
+	}
+	ErrReleaseTagExists = &ReleaseError{
+		code:                 releaseTagExistsErrorCode,
+		Name:                 "release-tag-exists",
+		remediationMessageId: i18n.ReleaseTagExistsRemediation,
+	}
+	ErrCiNotGreen = &ReleaseError{
+		code:                 ciNotGreenErrorCode,
+		Name:                 "ci-not-green",
+		remediationMessageId: i18n.CiNotGreenRemediation,
+	}
+	ErrReleaseNotesUnapproved = &ReleaseError{
+		code:                 releaseNotesUnapprovedErrorCode,
+		Name:                 "release-notes-unapproved",
+		remediationMessageId: i18n.ReleaseNotesUnapprovedRemediation,
+	}
+	ErrPreReleaseScriptFailed = &ReleaseError{
+		code:                 preReleaseScriptFailedErrorCode,
+		Name:                 "pre-release-script-failed",
+		remediationMessageId: i18n.PreReleaseScriptFailedRemediation,
+	}
+	ErrReleaseEmbargoed = &ReleaseError{
+		code:                 releaseEmbargoedErrorCode,
+		Name:                 "release-embargoed",
+		remediationMessageId: i18n.ReleaseEmbargoedRemediation,
+	}
+	ErrCommitHookRejected = &ReleaseError{
+		code:                 commitHookRejectedErrorCode,
+		Name:                 "commit-hook-rejected",
+		remediationMessageId: i18n.CommitHookRejectedRemediation,
+	}
+	ErrPolicyDenied = &ReleaseError{
+		code:                 policyDeniedErrorCode,
+		Name:                 "policy-denied",
+		remediationMessageId: i18n.PolicyDeniedRemediation,
+	}
+	ErrTagSignatureInvalid = &ReleaseError{
+		code:                 tagSignatureInvalidErrorCode,
+		Name:                 "tag-signature-invalid",
+		remediationMessageId: i18n.TagSignatureInvalidRemediation,
+	}
+	ErrReleaseLocked = &ReleaseError{
+		code:                 releaseLockedErrorCode,
+		Name:                 "release-locked",
+		remediationMessageId: i18n.ReleaseLockedRemediation,
+	}
+	ErrVersionFileOutOfSync = &ReleaseError{
+		code:                 versionFileOutOfSyncErrorCode,
+		Name:                 "version-file-out-of-sync",
+		remediationMessageId: i18n.VersionFileOutOfSyncRemediation,
+	}
+	ErrMirrorPushFailed = &ReleaseError{
+		code:                 mirrorPushFailedErrorCode,
+		Name:                 "mirror-push-failed",
+		remediationMessageId: i18n.MirrorPushFailedRemediation,
+	}
+	ErrChangelogSectionMissing = &ReleaseError{
+		code:                 changelogSectionMissingErrorCode,
+		Name:                 "changelog-section-missing",
+		remediationMessageId: i18n.ChangelogSectionMissingRemediation,
+	}
+	ErrBuildFailed = &ReleaseError{
+		code:                 buildFailedErrorCode,
+		Name:                 "build-failed",
+		remediationMessageId: i18n.BuildFailedRemediation,
+	}
+	ErrOnlyNonReleasableChanges = &ReleaseError{
+		code:                 onlyNonReleasableChangesErrorCode,
+		Name:                 "only-non-releasable-changes",
+		remediationMessageId: i18n.OnlyNonReleasableChangesRemediation,
+	}
+	ErrReleasedTooRecently = &ReleaseError{
+		code:                 releasedTooRecentlyErrorCode,
+		Name:                 "released-too-recently",
+		remediationMessageId: i18n.ReleasedTooRecentlyRemediation,
+	}
+	ErrArtifactAnomaly = &ReleaseError{
+		code:                 artifactAnomalyErrorCode,
+		Name:                 "artifact-anomaly",
+		remediationMessageId: i18n.ArtifactAnomalyRemediation,
+	}
+	ErrReleaseCancelled = &ReleaseError{
+		code:                 releaseCancelledErrorCode,
+		Name:                 "release-cancelled",
+		remediationMessageId: i18n.ReleaseCancelledRemediation,
+	}
+	ErrNotOnReleaseBranch = &ReleaseError{
+		code:                 notOnReleaseBranchErrorCode,
+		Name:                 "not-on-release-branch",
+		remediationMessageId: i18n.NotOnReleaseBranchRemediation,
+	}
+	ErrReleaseNotApproved = &ReleaseError{
+		code:                 releaseNotApprovedErrorCode,
+		Name:                 "release-not-approved",
+		remediationMessageId: i18n.ReleaseNotApprovedRemediation,
+	}
+
+	releaseErrorsByCode = map[stacktrace.ErrorCode]*ReleaseError{
+		dirtyWorktreeErrorCode:            ErrDirtyWorktree,
+		outOfSyncErrorCode:                ErrOutOfSync,
+		changelogInvalidErrorCode:         ErrChangelogInvalid,
+		pushRejectedErrorCode:             ErrPushRejected,
+		invalidConfigErrorCode:            ErrInvalidConfig,
+		releaseTagExistsErrorCode:         ErrReleaseTagExists,
+		ciNotGreenErrorCode:               ErrCiNotGreen,
+		releaseNotesUnapprovedErrorCode:   ErrReleaseNotesUnapproved,
+		preReleaseScriptFailedErrorCode:   ErrPreReleaseScriptFailed,
+		releaseEmbargoedErrorCode:         ErrReleaseEmbargoed,
+		commitHookRejectedErrorCode:       ErrCommitHookRejected,
+		policyDeniedErrorCode:             ErrPolicyDenied,
+		tagSignatureInvalidErrorCode:      ErrTagSignatureInvalid,
+		releaseLockedErrorCode:            ErrReleaseLocked,
+		versionFileOutOfSyncErrorCode:     ErrVersionFileOutOfSync,
+		mirrorPushFailedErrorCode:         ErrMirrorPushFailed,
+		changelogSectionMissingErrorCode:  ErrChangelogSectionMissing,
+		buildFailedErrorCode:              ErrBuildFailed,
+		onlyNonReleasableChangesErrorCode: ErrOnlyNonReleasableChanges,
+		releasedTooRecentlyErrorCode:      ErrReleasedTooRecently,
+		artifactAnomalyErrorCode:          ErrArtifactAnomaly,
+		releaseCancelledErrorCode:         ErrReleaseCancelled,
+		notOnReleaseBranchErrorCode:       ErrNotOnReleaseBranch,
+		releaseNotApprovedErrorCode:       ErrReleaseNotApproved,
+	}
+)
+
+func (releaseError *ReleaseError) Error() string {
+	return fmt.Sprintf("release error '%s'", releaseError.Name)
+}
+
+// GetRemediation returns what the operator can do to get the release through, in the CLI's language
+func (releaseError *ReleaseError) GetRemediation() string {
+	return i18n.Sprintf(releaseError.remediationMessageId)
+}
+
+// GetReleaseError returns the kind of release failure that the error returned by the releaser is, if it's a known one:
+//
+//	if releaseErr, found := releaser.GetReleaseError(err); found && releaseErr == releaser.ErrDirtyWorktree {
+//		...
+//	}
+func GetReleaseError(err error) (*ReleaseError, bool) {
+	releaseError, found := releaseErrorsByCode[stacktrace.GetCode(err)]
+	return releaseError, found
+}
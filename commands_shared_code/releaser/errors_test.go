@@ -0,0 +1,34 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetReleaseError_SurvivesPropagation(t *testing.T) {
+	err := stacktrace.NewErrorWithCode(ErrDirtyWorktree.code, "The branch contains modified files")
+	err = stacktrace.Propagate(err, "An error occurred releasing the repo")
+
+	releaseErr, found := GetReleaseError(err)
+	require.True(t, found)
+	require.Equal(t, ErrDirtyWorktree, releaseErr)
+	require.NotEmpty(t, releaseErr.GetRemediation())
+}
+
+func TestGetReleaseError_UnknownErrors(t *testing.T) {
+	_, found := GetReleaseError(stacktrace.NewError("Something else went wrong"))
+	require.False(t, found)
+
+	_, found = GetReleaseError(nil)
+	require.False(t, found)
+}
+
+func TestReleaseErrors_HaveUniqueNames(t *testing.T) {
+	names := map[string]bool{}
+	for code, releaseErr := range releaseErrorsByCode {
+		require.Equal(t, code, releaseErr.code)
+		require.False(t, names[releaseErr.Name], "Name '%s' is used by more than one release error", releaseErr.Name)
+		names[releaseErr.Name] = true
+	}
+}
@@ -0,0 +1,117 @@
+package releaser
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// The directory inside the VCS's metadata directory that holds, for each remote, when it was last fetched
+	lastFetchedDirname        = "kudet-last-fetch"
+	lastFetchedFileSuffix     = ".json"
+	lastFetchedDirMode        = 0755
+	lastFetchedFileMode       = 0644
+	legacyLastFetchedFilename = "last-fetch.txt"
+)
+
+// FetchPolicy is when the release fetches origin before checking that the release branch is in sync with it
+type FetchPolicy int
+
+const (
+	// FetchIfStale fetches unless the remote was fetched within the kudet config's fetch grace period
+	FetchIfStale FetchPolicy = iota
+
+	// AlwaysFetch fetches however recently the remote was last fetched
+	AlwaysFetch
+
+	// NeverFetch trusts the remote-tracking branches as they are, e.g. in air-gapped or rate-limited environments
+	// where they're updated out of band
+	NeverFetch
+)
+
+// lastFetch is when a remote was last fetched, and from where, so that re-pointing the remote fetches it again
+type lastFetch struct {
+	Url  string    `json:"url"`
+	Time time.Time `json:"time"`
+}
+
+// fetchRemoteIfNeeded fetches origin according to the policy, recording when it did in the metadata directory
+func fetchRemoteIfNeeded(ctx context.Context, repository vcs.Repository, metadataDirpath string, fetchPolicy FetchPolicy, gracePeriod time.Duration) error {
+	if fetchPolicy == NeverFetch {
+		logrus.Warnf("Not fetching %s, as requested; the release is checked against its remote-tracking branches as they are", originRemoteName)
+		return nil
+	}
+	remoteUrl, err := repository.GetRemoteUrl()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the URL of remote '%s'", originRemoteName)
+	}
+	lastFetchedFilepath := filepath.Join(metadataDirpath, lastFetchedDirname, originRemoteName+lastFetchedFileSuffix)
+	if fetchPolicy == FetchIfStale {
+		shouldFetch, err := isRemoteFetchStale(lastFetchedFilepath, remoteUrl, gracePeriod)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred determining whether remote '%s' needs fetching", originRemoteName)
+		}
+		if !shouldFetch {
+			logrus.Debugf("Not fetching %s, as it was fetched within the last %v", originRemoteName, gracePeriod)
+			return nil
+		}
+	}
+
+	if err := repository.Fetch(ctx); err != nil {
+		return stacktrace.Propagate(err, "An error occurred fetching from the remote repository.")
+	}
+	if err := saveLastFetch(lastFetchedFilepath, lastFetch{Url: remoteUrl, Time: time.Now()}); err != nil {
+		return stacktrace.Propagate(err, "An error occurred recording when remote '%s' was fetched", originRemoteName)
+	}
+	// The single file that all remotes used to share is superseded by the per-remote ones
+	if err := os.Remove(filepath.Join(metadataDirpath, legacyLastFetchedFilename)); err != nil && !os.IsNotExist(err) {
+		logrus.Debugf("An error occurred removing the superseded last-fetched file: %v", err)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func isRemoteFetchStale(lastFetchedFilepath string, remoteUrl string, gracePeriod time.Duration) (bool, error) {
+	lastFetchedBytes, err := os.ReadFile(lastFetchedFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			logrus.Debugf("There's no record of when the remote was last fetched at '%s'", lastFetchedFilepath)
+			return true, nil
+		}
+		return false, stacktrace.Propagate(err, "An error occurred reading when the remote was last fetched from '%s'", lastFetchedFilepath)
+	}
+	previousFetch := lastFetch{}
+	if err := json.Unmarshal(lastFetchedBytes, &previousFetch); err != nil {
+		// It's only a cache, so a corrupted one is refetched over
+		logrus.Debugf("Ignoring the unparseable record of when the remote was last fetched at '%s': %v", lastFetchedFilepath, err)
+		return true, nil
+	}
+	if previousFetch.Url != remoteUrl {
+		return true, nil
+	}
+	return time.Now().After(previousFetch.Time.Add(gracePeriod)), nil
+}
+
+func saveLastFetch(lastFetchedFilepath string, fetch lastFetch) error {
+	if err := os.MkdirAll(filepath.Dir(lastFetchedFilepath), lastFetchedDirMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating the directory of '%s'", lastFetchedFilepath)
+	}
+	fetchBytes, err := json.Marshal(fetch)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing when the remote was last fetched")
+	}
+	if err := os.WriteFile(lastFetchedFilepath, fetchBytes, lastFetchedFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing when the remote was last fetched to '%s'", lastFetchedFilepath)
+	}
+	return nil
+}
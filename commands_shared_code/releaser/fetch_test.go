@@ -0,0 +1,53 @@
+package releaser
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRemoteFetchStale(t *testing.T) {
+	lastFetchedFilepath := filepath.Join(t.TempDir(), lastFetchedDirname, "origin.json")
+	isStale, err := isRemoteFetchStale(lastFetchedFilepath, "https://example.com/repo.git", time.Minute)
+	require.NoError(t, err)
+	require.True(t, isStale)
+
+	require.NoError(t, saveLastFetch(lastFetchedFilepath, lastFetch{Url: "https://example.com/repo.git", Time: time.Now()}))
+	isStale, err = isRemoteFetchStale(lastFetchedFilepath, "https://example.com/repo.git", time.Minute)
+	require.NoError(t, err)
+	require.False(t, isStale)
+	isStale, err = isRemoteFetchStale(lastFetchedFilepath, "https://example.com/repo.git", 0)
+	require.NoError(t, err)
+	require.True(t, isStale)
+
+	// A remote that was re-pointed elsewhere is fetched again
+	isStale, err = isRemoteFetchStale(lastFetchedFilepath, "https://example.com/fork.git", time.Minute)
+	require.NoError(t, err)
+	require.True(t, isStale)
+}
+
+func TestFetchRemoteIfNeeded_FollowsPolicy(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	// Fetching from a remote that isn't a repo fails, which shows whether a fetch was attempted
+	remoteUrl := t.TempDir()
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{remoteUrl}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	metadataDirpath := repository.GetMetadataDirpath()
+	lastFetchedFilepath := filepath.Join(metadataDirpath, lastFetchedDirname, vcs.OriginRemoteName+lastFetchedFileSuffix)
+	require.NoError(t, saveLastFetch(lastFetchedFilepath, lastFetch{Url: remoteUrl, Time: time.Now()}))
+
+	require.NoError(t, fetchRemoteIfNeeded(context.Background(), repository, metadataDirpath, FetchIfStale, time.Minute))
+	require.NoError(t, fetchRemoteIfNeeded(context.Background(), repository, metadataDirpath, NeverFetch, 0))
+	require.Error(t, fetchRemoteIfNeeded(context.Background(), repository, metadataDirpath, AlwaysFetch, time.Minute))
+	require.Error(t, fetchRemoteIfNeeded(context.Background(), repository, metadataDirpath, FetchIfStale, 0))
+}
@@ -0,0 +1,259 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	githubDotComHost = "github.com"
+	gitlabDotComHost = "gitlab.com"
+
+	jsonContentType = "application/json"
+)
+
+// Matches 'https://[user@]host/path', 'ssh://git@host[:port]/path', and 'git@host:path' remote URLs, with or without
+// the '.git' suffix
+var remoteUrlRegex = regexp.MustCompile(`^(?:https?://(?:[^@/]+@)?([^/:]+)(?::[0-9]+)?/|ssh://(?:[^@/]+@)?([^/:]+)(?::[0-9]+)?/|[^@/]+@([^/:]+):)(.+?)(?:\.git)?/?$`)
+
+// forge is the code host that the origin remote lives on, which kudet talks to over its REST API for everything
+// beyond plain git
+type forge interface {
+	// getName is how the forge is referred to in logs and errors, e.g. 'GitHub'
+	getName() string
+
+	// getCiCheckStates gets the state of each CI check reported on the commit, keyed by check name
+	getCiCheckStates(ctx context.Context, commitHash string) (map[string]ciCheckState, error)
+
+	// supportsDraftReleases is whether releases can be created privately and published later
+	supportsDraftReleases() bool
+
+	// createRelease creates the forge release of the version's tag, returning its ID; draft releases are only
+	// possible if the forge supports them, and stay private until published
+	createRelease(ctx context.Context, version string, releaseNotes string, isDraft bool) (int64, error)
+
+	publishDraftRelease(ctx context.Context, releaseId int64) error
+
+	// supportsReleaseAssets is whether files can be attached to forge releases
+	supportsReleaseAssets() bool
+
+	// uploadReleaseJsonAsset attaches the asset, serialized as JSON, to the version's forge release, creating the
+	// release if there isn't one yet; it returns the asset's download URL
+	uploadReleaseJsonAsset(ctx context.Context, version string, releaseNotes string, assetName string, asset interface{}) (string, error)
+
+	// uploadReleaseFileAsset attaches the file's contents as they are to the version's forge release, creating the
+	// release if there isn't one yet; it returns the asset's download URL
+	uploadReleaseFileAsset(ctx context.Context, version string, releaseNotes string, assetName string, contents []byte) (string, error)
+
+	// getReleaseFileAssets downloads the files attached to the version's forge release, keyed by name
+	getReleaseFileAssets(ctx context.Context, version string) (map[string][]byte, error)
+
+	// deleteRelease deletes the version's forge release, draft or not, returning false if it has none; the tag is left
+	// alone
+	deleteRelease(ctx context.Context, version string) (bool, error)
+
+	// markReleaseSuperseded flags the version's forge release as one that shouldn't be used, prepending the notice to
+	// its description
+	markReleaseSuperseded(ctx context.Context, version string, notice string) error
+
+	// supportsSecurityAdvisories is whether the forge can host security advisories for security releases
+	supportsSecurityAdvisories() bool
+
+	// draftSecurityAdvisory drafts a private advisory for the vulnerability fixed in the version, returning its URL
+	draftSecurityAdvisory(ctx context.Context, advisoryConfig kudet_config.SecurityAdvisoryConfig, severity string, version string, releaseNotes string) (string, error)
+
+	// supportsApprovalIssues is whether releases can be approved by commenting on a forge issue
+	supportsApprovalIssues() bool
+
+	// openApprovalIssue opens an issue asking for a release's approval, returning its number and URL
+	openApprovalIssue(ctx context.Context, title string, body string) (int64, string, error)
+
+	// listIssueComments lists the comments on the issue, oldest first
+	listIssueComments(ctx context.Context, issueNumber int64) ([]issueComment, error)
+
+	// closeIssue leaves the comment on the issue and closes it
+	closeIssue(ctx context.Context, issueNumber int64, comment string) error
+
+	// supportsPullRequestComments is whether the pull requests that a commit was merged in can be found and commented on
+	supportsPullRequestComments() bool
+
+	// getMergedPullRequestNumbers gets the numbers of the merged pull requests that the commit is part of
+	getMergedPullRequestNumbers(ctx context.Context, commitHash string) ([]int64, error)
+
+	commentOnPullRequest(ctx context.Context, pullRequestNumber int64, comment string) error
+
+	// supportsMilestones is whether the forge groups issues into milestones that releases can roll forward
+	supportsMilestones() bool
+
+	// listOpenMilestones lists the milestones that haven't been closed
+	listOpenMilestones(ctx context.Context) ([]milestone, error)
+
+	// createMilestone creates an open milestone, returning its number
+	createMilestone(ctx context.Context, title string) (int64, error)
+
+	closeMilestone(ctx context.Context, milestoneNumber int64) error
+
+	// listOpenMilestoneIssueNumbers lists the numbers of the open issues and pull requests in the milestone
+	listOpenMilestoneIssueNumbers(ctx context.Context, milestoneNumber int64) ([]int64, error)
+
+	// setIssueMilestone moves the issue or pull request into the milestone
+	setIssueMilestone(ctx context.Context, issueNumber int64, milestoneNumber int64) error
+
+	// getMissingTokenScopes returns the scopes that releasing needs and the token lacks; false means the forge can't
+	// tell, e.g. for tokens that have fine-grained permissions rather than scopes
+	getMissingTokenScopes(ctx context.Context) ([]string, bool, error)
+}
+
+// getForge picks the forge implementation for the remote, using the configured forge type if there is one and
+// otherwise recognizing the remote's host
+func getForge(remoteUrl string, forgeConfig kudet_config.ForgeConfig, token string) (forge, error) {
+	host, repoPath, err := parseRemoteUrl(remoteUrl)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing remote URL '%s'", remoteUrl)
+	}
+	forgeType, err := getForgeType(host, forgeConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred determining the forge that hosts remote URL '%s'", remoteUrl)
+	}
+	switch forgeType {
+	case kudet_config.GithubForgeType:
+		return newGithubForge(host, repoPath, forgeConfig.ApiUrl, token)
+	case kudet_config.GitlabForgeType:
+		return newGitlabForge(host, repoPath, forgeConfig.ApiUrl, token), nil
+	default:
+		return nil, stacktrace.NewError("Unrecognized forge type '%s'; this is a bug in kudet", forgeType)
+	}
+}
+
+// getRemoteForge gets the forge that the repository's origin remote is on
+func (releaser *Releaser) getRemoteForge(repository vcs.Repository, forgeConfig kudet_config.ForgeConfig) (forge, error) {
+	remoteUrl, err := repository.GetRemoteUrl()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the URL of remote '%s'", originRemoteName)
+	}
+	releaseForge, err := getForge(remoteUrl, forgeConfig, releaser.token)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred determining the forge of remote '%s'", originRemoteName)
+	}
+	return releaseForge, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// parseRemoteUrl splits the remote URL into the forge's host and the repo's path on it (e.g. 'owner/repo')
+func parseRemoteUrl(remoteUrl string) (string, string, error) {
+	matches := remoteUrlRegex.FindStringSubmatch(remoteUrl)
+	if matches == nil {
+		return "", "", stacktrace.NewError("Remote URL '%s' isn't a recognized HTTPS or SSH URL", remoteUrl)
+	}
+	host := matches[1] + matches[2] + matches[3]
+	return strings.ToLower(host), matches[4], nil
+}
+
+func getForgeType(host string, forgeConfig kudet_config.ForgeConfig) (string, error) {
+	if forgeConfig.Type != "" {
+		return forgeConfig.Type, nil
+	}
+	switch {
+	case host == githubDotComHost:
+		return kudet_config.GithubForgeType, nil
+	// Self-hosted GitLab instances are conventionally on a 'gitlab.' subdomain
+	case host == gitlabDotComHost || strings.HasPrefix(host, "gitlab."):
+		return kudet_config.GitlabForgeType, nil
+	}
+	return "", stacktrace.NewError(
+		"Can't tell which forge host '%s' is; set '%s.%s' to one of '%s' in the kudet config",
+		host,
+		kudet_config.ForgeKey,
+		kudet_config.ForgeTypeKey,
+		kudet_config.ForgeTypes,
+	)
+}
+
+// sendForgeApiJson sends the request body (if not nil) as JSON with the given headers, and decodes the response into
+// the result (if not nil)
+func sendForgeApiJson(ctx context.Context, headers map[string]string, method string, url string, requestBody interface{}, result interface{}) error {
+	if requestBody == nil {
+		return sendForgeApiRequest(ctx, headers, method, url, "", nil, result)
+	}
+	requestBodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the request body for '%s'", url)
+	}
+	return sendForgeApiRequest(ctx, headers, method, url, jsonContentType, requestBodyBytes, result)
+}
+
+// sendForgeApiRequest sends the request body (if not nil) as the given content type with the given headers, and
+// decodes the JSON response into the result (if not nil)
+func sendForgeApiRequest(ctx context.Context, headers map[string]string, method string, url string, contentType string, requestBody []byte, result interface{}) error {
+	_, err := sendForgeApiRequestForHeaders(ctx, headers, method, url, contentType, requestBody, result)
+	return err
+}
+
+// getForgeApiBytes gets the response body as it is, e.g. a file being downloaded
+func getForgeApiBytes(ctx context.Context, headers map[string]string, url string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred building the request to '%s'", url)
+	}
+	for headerName, headerValue := range headers {
+		request.Header.Set(headerName, headerValue)
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred requesting '%s'", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, stacktrace.NewError("Request to '%s' returned unexpected status '%s'", url, resp.Status)
+	}
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the response from '%s'", url)
+	}
+	return responseBytes, nil
+}
+
+// sendForgeApiRequestForHeaders is sendForgeApiRequest for when the response's headers matter too
+func sendForgeApiRequestForHeaders(ctx context.Context, headers map[string]string, method string, url string, contentType string, requestBody []byte, result interface{}) (http.Header, error) {
+	var requestBodyReader io.Reader
+	if requestBody != nil {
+		requestBodyReader = bytes.NewReader(requestBody)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, requestBodyReader)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred building the request to '%s'", url)
+	}
+	for headerName, headerValue := range headers {
+		request.Header.Set(headerName, headerValue)
+	}
+	if requestBody != nil {
+		request.Header.Set("Content-Type", contentType)
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred requesting '%s'", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, stacktrace.NewError("Request to '%s' returned unexpected status '%s'", url, resp.Status)
+	}
+	if result == nil {
+		return resp.Header, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred decoding the response from '%s'", url)
+	}
+	return resp.Header, nil
+}
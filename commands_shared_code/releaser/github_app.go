@@ -0,0 +1,211 @@
+package releaser
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	githubRepoInstallationUrlFormat  = "%s/repos/%s/%s/installation"
+	githubInstallationTokenUrlFormat = "%s/app/installations/%d/access_tokens"
+
+	// GitHub refuses App JWTs that expire more than 10 minutes out, and ones issued in the future by its clock, so
+	// they're backdated to allow for clock drift
+	githubAppJwtLifetime  = 9 * time.Minute
+	githubAppJwtBackdate  = time.Minute
+	githubAppJwtAlgorithm = "RS256"
+	githubAppJwtType      = "JWT"
+
+	// Installation tokens last an hour; one that expires sooner than this is replaced before it's used again
+	githubAppTokenRefreshMargin = 10 * time.Minute
+
+	rsaPrivateKeyPemType   = "RSA PRIVATE KEY"
+	pkcs8PrivateKeyPemType = "PRIVATE KEY"
+)
+
+type githubAppJwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+}
+
+type githubAppJwtClaims struct {
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	Issuer    string `json:"iss"`
+}
+
+type githubInstallationResponse struct {
+	Id int64 `json:"id"`
+}
+
+type githubInstallationTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// githubAppTokenSource mints installation tokens for a GitHub App, reusing each one until it's about to expire
+type githubAppTokenSource struct {
+	appId          int64
+	installationId int64
+	privateKey     *rsa.PrivateKey
+
+	token          string
+	tokenExpiresAt time.Time
+}
+
+// authenticateAsGithubAppIfNeeded replaces the releaser's token, and the repository's, with an installation token of
+// the kudet config's GitHub App, minting a new one if the current one is about to expire. It's called whenever the
+// token is about to be used after a while, as releases can outlast an installation token.
+func (releaser *Releaser) authenticateAsGithubAppIfNeeded(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig) error {
+	appConfig := kudetConfig.GithubApp
+	// The sandbox's origin is a local stand-in, which the App has no installation on
+	if appConfig.AppId == 0 || releaser.isSandbox {
+		return nil
+	}
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the forge to authenticate as GitHub App '%d' with", appConfig.AppId)
+	}
+	github, ok := releaseForge.(*githubForge)
+	if !ok {
+		return stacktrace.NewError("The kudet config's '%s' only works with GitHub, but remote '%s' is on %s", kudet_config.GithubAppKey, originRemoteName, releaseForge.getName())
+	}
+	if releaser.githubAppTokenSource == nil {
+		tokenSource, err := newGithubAppTokenSource(appConfig)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred setting up authentication as GitHub App '%d'", appConfig.AppId)
+		}
+		releaser.githubAppTokenSource = tokenSource
+	}
+	token, err := releaser.githubAppTokenSource.getToken(ctx, github.apiUrlBase, github.owner, github.repo)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting an installation token of GitHub App '%d'", appConfig.AppId)
+	}
+	releaser.token = token
+	repository.SetToken(token)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newGithubAppTokenSource(appConfig kudet_config.GithubAppConfig) (*githubAppTokenSource, error) {
+	privateKeyPem := os.Getenv(appConfig.PrivateKeyEnvVar)
+	if privateKeyPem == "" {
+		return nil, stacktrace.NewError("The GitHub App's private key is read from environment variable '%s', which isn't set", appConfig.PrivateKeyEnvVar)
+	}
+	privateKey, err := parseRsaPrivateKey([]byte(privateKeyPem))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the GitHub App's private key from environment variable '%s'", appConfig.PrivateKeyEnvVar)
+	}
+	return &githubAppTokenSource{
+		appId:          appConfig.AppId,
+		installationId: appConfig.InstallationId,
+		privateKey:     privateKey,
+	}, nil
+}
+
+// getToken returns the current installation token, minting a new one first if there's none or it's about to expire;
+// the installation is looked up from the repo the first time if it wasn't configured
+func (source *githubAppTokenSource) getToken(ctx context.Context, apiUrlBase string, owner string, repo string) (string, error) {
+	if source.token != "" && time.Until(source.tokenExpiresAt) > githubAppTokenRefreshMargin {
+		return source.token, nil
+	}
+	jwt, err := source.newJwt(time.Now())
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred creating the JWT that authenticates as the GitHub App")
+	}
+	headers := map[string]string{
+		"Accept":        githubApiAcceptHeader,
+		"Authorization": "Bearer " + jwt,
+	}
+	if source.installationId == 0 {
+		installation := &githubInstallationResponse{}
+		installationUrl := fmt.Sprintf(githubRepoInstallationUrlFormat, apiUrlBase, owner, repo)
+		if err := sendForgeApiJson(ctx, headers, http.MethodGet, installationUrl, nil, installation); err != nil {
+			return "", stacktrace.Propagate(err, "An error occurred looking up the GitHub App's installation on '%s/%s'; is the App installed on it?", owner, repo)
+		}
+		source.installationId = installation.Id
+	}
+	tokenResponse := &githubInstallationTokenResponse{}
+	tokenUrl := fmt.Sprintf(githubInstallationTokenUrlFormat, apiUrlBase, source.installationId)
+	if err := sendForgeApiJson(ctx, headers, http.MethodPost, tokenUrl, nil, tokenResponse); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred minting a token for installation '%d' of the GitHub App", source.installationId)
+	}
+	if tokenResponse.Token == "" {
+		return "", stacktrace.NewError("GitHub returned no token for installation '%d' of the GitHub App", source.installationId)
+	}
+	logrus.Debugf("Minted a token for installation '%d' of GitHub App '%d', which expires at %s", source.installationId, source.appId, tokenResponse.ExpiresAt.Format(time.RFC3339))
+	source.token = tokenResponse.Token
+	source.tokenExpiresAt = tokenResponse.ExpiresAt
+	return source.token, nil
+}
+
+// newJwt creates the RS256-signed JWT that the GitHub App authenticates with when minting installation tokens
+func (source *githubAppTokenSource) newJwt(now time.Time) (string, error) {
+	headerJson, err := json.Marshal(&githubAppJwtHeader{Algorithm: githubAppJwtAlgorithm, Type: githubAppJwtType})
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred serializing the JWT header")
+	}
+	claimsJson, err := json.Marshal(&githubAppJwtClaims{
+		IssuedAt:  now.Add(-githubAppJwtBackdate).Unix(),
+		ExpiresAt: now.Add(githubAppJwtLifetime).Unix(),
+		Issuer:    strconv.FormatInt(source.appId, 10),
+	})
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred serializing the JWT claims")
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJson) + "." + base64.RawURLEncoding.EncodeToString(claimsJson)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, source.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred signing the JWT")
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRsaPrivateKey accepts the PKCS #1 keys that GitHub generates for Apps, as well as PKCS #8 conversions of them
+func parseRsaPrivateKey(privateKeyPem []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPem)
+	if block == nil {
+		return nil, stacktrace.NewError("The private key isn't PEM-encoded")
+	}
+	switch block.Type {
+	case rsaPrivateKeyPemType:
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred parsing the PKCS #1 private key")
+		}
+		return privateKey, nil
+	case pkcs8PrivateKeyPemType:
+		parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred parsing the PKCS #8 private key")
+		}
+		privateKey, ok := parsedKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, stacktrace.NewError("The private key isn't an RSA key, which GitHub Apps use")
+		}
+		return privateKey, nil
+	default:
+		return nil, stacktrace.NewError("The PEM block is a '%s' rather than a private key", block.Type)
+	}
+}
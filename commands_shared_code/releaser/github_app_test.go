@@ -0,0 +1,87 @@
+package releaser
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubAppTokenSource(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs1Pem := pem.EncodeToMemory(&pem.Block{Type: rsaPrivateKeyPemType, Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	parsedKey, err := parseRsaPrivateKey(pkcs1Pem)
+	require.NoError(t, err)
+	require.True(t, privateKey.Equal(parsedKey))
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	parsedKey, err = parseRsaPrivateKey(pem.EncodeToMemory(&pem.Block{Type: pkcs8PrivateKeyPemType, Bytes: pkcs8Bytes}))
+	require.NoError(t, err)
+	require.True(t, privateKey.Equal(parsedKey))
+	_, err = parseRsaPrivateKey([]byte("not a key"))
+	require.Error(t, err)
+
+	tokenExpiresAt := time.Now().Add(time.Hour)
+	mintedTokenCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		jwtParts := strings.Split(strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer "), ".")
+		require.Len(t, jwtParts, 3)
+		signature, err := base64.RawURLEncoding.DecodeString(jwtParts[2])
+		require.NoError(t, err)
+		digest := sha256.Sum256([]byte(jwtParts[0] + "." + jwtParts[1]))
+		require.NoError(t, rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, digest[:], signature))
+		claimsJson, err := base64.RawURLEncoding.DecodeString(jwtParts[1])
+		require.NoError(t, err)
+		claims := &githubAppJwtClaims{}
+		require.NoError(t, json.Unmarshal(claimsJson, claims))
+		require.Equal(t, "42", claims.Issuer)
+		require.Less(t, claims.IssuedAt, time.Now().Unix())
+		require.LessOrEqual(t, claims.ExpiresAt-claims.IssuedAt, int64((10 * time.Minute).Seconds()))
+
+		switch {
+		case request.Method == http.MethodGet && request.URL.Path == "/repos/owner/repo/installation":
+			fmt.Fprint(writer, `{"id": 7}`)
+		case request.Method == http.MethodPost && request.URL.Path == "/app/installations/7/access_tokens":
+			mintedTokenCount++
+			writer.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(writer, `{"token": "installation-token-%d", "expires_at": "%s"}`, mintedTokenCount, tokenExpiresAt.Format(time.RFC3339))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source := &githubAppTokenSource{appId: 42, privateKey: privateKey}
+	token, err := source.getToken(context.Background(), server.URL, "owner", "repo")
+	require.NoError(t, err)
+	require.Equal(t, "installation-token-1", token)
+	require.Equal(t, int64(7), source.installationId)
+
+	// Tokens are reused until they're about to expire
+	token, err = source.getToken(context.Background(), server.URL, "owner", "repo")
+	require.NoError(t, err)
+	require.Equal(t, "installation-token-1", token)
+
+	source.tokenExpiresAt = time.Now().Add(githubAppTokenRefreshMargin / 2)
+	token, err = source.getToken(context.Background(), server.URL, "owner", "repo")
+	require.NoError(t, err)
+	require.Equal(t, "installation-token-2", token)
+
+	unknownInstallationSource := &githubAppTokenSource{appId: 42, installationId: 8, privateKey: privateKey}
+	_, err = unknownInstallationSource.getToken(context.Background(), server.URL, "owner", "repo")
+	require.Error(t, err)
+}
@@ -0,0 +1,624 @@
+package releaser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	githubDotComApiUrlBase = "https://api.github.com"
+	// GitHub Enterprise Server serves its API under this path of the instance's host
+	githubEnterpriseApiPath = "/api/v3"
+	githubApiAcceptHeader   = "application/vnd.github+json"
+	// Release assets other than JSON are uploaded as opaque files
+	githubFileAssetContentType = "application/octet-stream"
+
+	// Enough for any repo we have; checks beyond the first page are ignored
+	githubApiPageSize = 100
+
+	githubCheckRunsUrlFormat          = "%s/repos/%s/%s/commits/%s/check-runs?per_page=%d"
+	githubCommitStatusUrlFormat       = "%s/repos/%s/%s/commits/%s/status?per_page=%d"
+	githubReleasesUrlFormat           = "%s/repos/%s/%s/releases"
+	githubReleaseUrlFormat            = "%s/repos/%s/%s/releases/%d"
+	githubReleaseByTagUrlFormat       = "%s/repos/%s/%s/releases/tags/%s"
+	githubSecurityAdvisoriesUrlFormat = "%s/repos/%s/%s/security-advisories"
+	githubRepoUrlFormat               = "%s/repos/%s/%s"
+	githubIssuesUrlFormat             = "%s/repos/%s/%s/issues"
+	githubIssueUrlFormat              = "%s/repos/%s/%s/issues/%d"
+	githubIssueCommentsUrlFormat      = "%s/repos/%s/%s/issues/%d/comments"
+	githubCommitPullsUrlFormat        = "%s/repos/%s/%s/commits/%s/pulls?per_page=%d"
+	githubMilestonesUrlFormat         = "%s/repos/%s/%s/milestones"
+	githubMilestoneUrlFormat          = "%s/repos/%s/%s/milestones/%d"
+	githubMilestoneIssuesUrlFormat    = "%s/repos/%s/%s/issues?milestone=%d&state=open&per_page=%d"
+
+	// Paginated listings link to their next page in this header, e.g. '<https://api.github.com/...&page=2>; rel="next"'
+	githubLinkHeaderName = "Link"
+	githubNextPageRel    = `rel="next"`
+	// Guards against listings that link back to themselves
+	githubMaxApiPages = 100
+
+	// Lists the scopes of classic tokens; fine-grained tokens and GitHub App tokens have permissions instead, and
+	// responses to them don't have it
+	githubOAuthScopesHeaderName = "X-OAuth-Scopes"
+	githubRepoScope             = "repo"
+	// Enough instead of 'repo' for public repos
+	githubPublicRepoScope = "public_repo"
+
+	completedCheckRunStatus = "completed"
+	successCommitState      = "success"
+	pendingCommitState      = "pending"
+	closedIssueState        = "closed"
+	openMilestoneState      = "open"
+	closedMilestoneState    = "closed"
+)
+
+// Check run conclusions that don't block a release
+var passingCheckRunConclusions = map[string]bool{
+	"success": true,
+	"neutral": true,
+	"skipped": true,
+}
+
+type githubCheckRunsResponse struct {
+	CheckRuns []struct {
+		Name       string `json:"name"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	} `json:"check_runs"`
+}
+
+type githubCombinedStatusResponse struct {
+	Statuses []struct {
+		Context string `json:"context"`
+		State   string `json:"state"`
+	} `json:"statuses"`
+}
+
+type githubReleaseRequest struct {
+	TagName string `json:"tag_name,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Body    string `json:"body,omitempty"`
+	Draft   bool   `json:"draft"`
+}
+
+// githubReleaseUpdateRequest is kept apart from githubReleaseRequest so that updates leave the draft flag alone
+type githubReleaseUpdateRequest struct {
+	Body       string `json:"body"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+type githubReleaseResponse struct {
+	Id      int64  `json:"id"`
+	TagName string `json:"tag_name"`
+	Body    string `json:"body"`
+	// A URI template like 'https://uploads.github.com/repos/owner/repo/releases/1/assets{?name,label}'
+	UploadUrl string `json:"upload_url"`
+	Assets    []struct {
+		Name string `json:"name"`
+		// The asset's API URL, which serves its contents when asked for them as an octet stream
+		Url string `json:"url"`
+	} `json:"assets"`
+}
+
+type githubReleaseAssetResponse struct {
+	BrowserDownloadUrl string `json:"browser_download_url"`
+}
+
+type githubRepoResponse struct {
+	Private bool `json:"private"`
+}
+
+type githubIssueRequest struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	State string `json:"state,omitempty"`
+}
+
+type githubIssueResponse struct {
+	Number  int64  `json:"number"`
+	HtmlUrl string `json:"html_url"`
+}
+
+type githubIssueCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type githubIssueCommentResponse struct {
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Body string `json:"body"`
+}
+
+// githubIssueMilestoneRequest is kept apart from githubIssueRequest so that moving an issue leaves the rest of it alone
+type githubIssueMilestoneRequest struct {
+	Milestone int64 `json:"milestone"`
+}
+
+type githubMilestoneRequest struct {
+	Title string `json:"title,omitempty"`
+	State string `json:"state,omitempty"`
+}
+
+type githubMilestoneResponse struct {
+	Number int64  `json:"number"`
+	Title  string `json:"title"`
+}
+
+type githubPullRequestResponse struct {
+	Number int64 `json:"number"`
+	// Null until the pull request is merged
+	MergedAt *string `json:"merged_at"`
+}
+
+type githubSecurityAdvisoryRequest struct {
+	Summary         string                                `json:"summary"`
+	Description     string                                `json:"description"`
+	Severity        string                                `json:"severity"`
+	Vulnerabilities []githubSecurityAdvisoryVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type githubSecurityAdvisoryVulnerability struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	VulnerableVersionRange string `json:"vulnerable_version_range"`
+	PatchedVersions        string `json:"patched_versions"`
+}
+
+type githubSecurityAdvisoryResponse struct {
+	GhsaId  string `json:"ghsa_id"`
+	HtmlUrl string `json:"html_url"`
+}
+
+// githubForge is a forge backed by the REST API of github.com or a GitHub Enterprise Server instance
+type githubForge struct {
+	apiUrlBase string
+	owner      string
+	repo       string
+	token      string
+}
+
+func newGithubForge(host string, repoPath string, apiUrlBase string, token string) (*githubForge, error) {
+	pathSegments := strings.Split(repoPath, "/")
+	if len(pathSegments) != 2 {
+		return nil, stacktrace.NewError("GitHub repo path '%s' should be of the form 'owner/repo'", repoPath)
+	}
+	if apiUrlBase == "" {
+		apiUrlBase = githubDotComApiUrlBase
+		if host != githubDotComHost {
+			apiUrlBase = "https://" + host + githubEnterpriseApiPath
+		}
+	}
+	return &githubForge{
+		apiUrlBase: strings.TrimSuffix(apiUrlBase, "/"),
+		owner:      pathSegments[0],
+		repo:       pathSegments[1],
+		token:      token,
+	}, nil
+}
+
+func (github *githubForge) getName() string {
+	return "GitHub"
+}
+
+// getCiCheckStates merges the commit's check runs (GitHub Actions and apps) and commit statuses (e.g. CircleCI)
+func (github *githubForge) getCiCheckStates(ctx context.Context, commitHash string) (map[string]ciCheckState, error) {
+	checkStates := map[string]ciCheckState{}
+
+	checkRuns := &githubCheckRunsResponse{}
+	checkRunsUrl := fmt.Sprintf(githubCheckRunsUrlFormat, github.apiUrlBase, github.owner, github.repo, commitHash, githubApiPageSize)
+	if err := github.sendApiJson(ctx, http.MethodGet, checkRunsUrl, nil, checkRuns); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the check runs")
+	}
+	for _, checkRun := range checkRuns.CheckRuns {
+		state := pendingCiCheckState
+		if checkRun.Status == completedCheckRunStatus {
+			state = failedCiCheckState
+			if passingCheckRunConclusions[checkRun.Conclusion] {
+				state = passedCiCheckState
+			}
+		}
+		checkStates[checkRun.Name] = worseCiCheckState(checkStates, checkRun.Name, state)
+	}
+
+	combinedStatus := &githubCombinedStatusResponse{}
+	statusUrl := fmt.Sprintf(githubCommitStatusUrlFormat, github.apiUrlBase, github.owner, github.repo, commitHash, githubApiPageSize)
+	if err := github.sendApiJson(ctx, http.MethodGet, statusUrl, nil, combinedStatus); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the commit statuses")
+	}
+	for _, status := range combinedStatus.Statuses {
+		state := failedCiCheckState
+		switch status.State {
+		case successCommitState:
+			state = passedCiCheckState
+		case pendingCommitState:
+			state = pendingCiCheckState
+		}
+		checkStates[status.Context] = worseCiCheckState(checkStates, status.Context, state)
+	}
+	return checkStates, nil
+}
+
+func (github *githubForge) supportsDraftReleases() bool {
+	return true
+}
+
+func (github *githubForge) createRelease(ctx context.Context, version string, releaseNotes string, isDraft bool) (int64, error) {
+	release, err := github.createGithubRelease(ctx, version, releaseNotes, isDraft)
+	if err != nil {
+		return 0, err
+	}
+	return release.Id, nil
+}
+
+func (github *githubForge) publishDraftRelease(ctx context.Context, releaseId int64) error {
+	releaseUrl := fmt.Sprintf(githubReleaseUrlFormat, github.apiUrlBase, github.owner, github.repo, releaseId)
+	if err := github.sendApiJson(ctx, http.MethodPatch, releaseUrl, &githubReleaseRequest{Draft: false}, nil); err != nil {
+		return stacktrace.Propagate(err, "An error occurred publishing draft release '%d'", releaseId)
+	}
+	return nil
+}
+
+func (github *githubForge) supportsReleaseAssets() bool {
+	return true
+}
+
+func (github *githubForge) uploadReleaseJsonAsset(ctx context.Context, version string, releaseNotes string, assetName string, asset interface{}) (string, error) {
+	assetBytes, err := json.Marshal(asset)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred serializing '%s'", assetName)
+	}
+	return github.uploadReleaseAsset(ctx, version, releaseNotes, assetName, jsonContentType, assetBytes)
+}
+
+func (github *githubForge) uploadReleaseFileAsset(ctx context.Context, version string, releaseNotes string, assetName string, contents []byte) (string, error) {
+	return github.uploadReleaseAsset(ctx, version, releaseNotes, assetName, githubFileAssetContentType, contents)
+}
+
+func (github *githubForge) getReleaseFileAssets(ctx context.Context, version string) (map[string][]byte, error) {
+	release := &githubReleaseResponse{}
+	releaseByTagUrl := fmt.Sprintf(githubReleaseByTagUrlFormat, github.apiUrlBase, github.owner, github.repo, version)
+	if err := github.sendApiJson(ctx, http.MethodGet, releaseByTagUrl, nil, release); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the GitHub release of version '%s'", version)
+	}
+	downloadHeaders := github.getApiHeaders()
+	downloadHeaders["Accept"] = githubFileAssetContentType
+	assetContentsByName := map[string][]byte{}
+	for _, asset := range release.Assets {
+		assetContents, err := getForgeApiBytes(ctx, downloadHeaders, asset.Url)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred downloading '%s' from the GitHub release of version '%s'", asset.Name, version)
+		}
+		assetContentsByName[asset.Name] = assetContents
+	}
+	return assetContentsByName, nil
+}
+
+// deleteRelease looks the release up among the latest releases rather than by its tag, since drafts can't be looked up
+// by tag
+func (github *githubForge) deleteRelease(ctx context.Context, version string) (bool, error) {
+	releases := []*githubReleaseResponse{}
+	releasesUrl := fmt.Sprintf(githubReleasesUrlFormat+"?per_page=%d", github.apiUrlBase, github.owner, github.repo, githubApiPageSize)
+	if err := github.sendApiJson(ctx, http.MethodGet, releasesUrl, nil, &releases); err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred listing the GitHub releases")
+	}
+	for _, release := range releases {
+		if release.TagName != version {
+			continue
+		}
+		releaseUrl := fmt.Sprintf(githubReleaseUrlFormat, github.apiUrlBase, github.owner, github.repo, release.Id)
+		if err := github.sendApiJson(ctx, http.MethodDelete, releaseUrl, nil, nil); err != nil {
+			return false, stacktrace.Propagate(err, "An error occurred deleting the GitHub release of version '%s'", version)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// markReleaseSuperseded also marks the release as a prerelease, which takes it out of the running for 'latest release'
+func (github *githubForge) markReleaseSuperseded(ctx context.Context, version string, notice string) error {
+	release := &githubReleaseResponse{}
+	releaseByTagUrl := fmt.Sprintf(githubReleaseByTagUrlFormat, github.apiUrlBase, github.owner, github.repo, version)
+	if err := github.sendApiJson(ctx, http.MethodGet, releaseByTagUrl, nil, release); err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the GitHub release of version '%s'", version)
+	}
+	releaseUrl := fmt.Sprintf(githubReleaseUrlFormat, github.apiUrlBase, github.owner, github.repo, release.Id)
+	updateRequest := &githubReleaseUpdateRequest{
+		Body:       notice + "\n\n" + release.Body,
+		Prerelease: true,
+	}
+	if err := github.sendApiJson(ctx, http.MethodPatch, releaseUrl, updateRequest, nil); err != nil {
+		return stacktrace.Propagate(err, "An error occurred marking the GitHub release of version '%s' as superseded", version)
+	}
+	return nil
+}
+
+func (github *githubForge) supportsSecurityAdvisories() bool {
+	return true
+}
+
+// draftSecurityAdvisory drafts a GitHub Security Advisory, which stays private until it's published on GitHub
+func (github *githubForge) draftSecurityAdvisory(ctx context.Context, advisoryConfig kudet_config.SecurityAdvisoryConfig, severity string, version string, releaseNotes string) (string, error) {
+	advisoryRequest := newSecurityAdvisoryRequest(github.repo, advisoryConfig, severity, version, releaseNotes)
+	advisory := &githubSecurityAdvisoryResponse{}
+	advisoriesUrl := fmt.Sprintf(githubSecurityAdvisoriesUrlFormat, github.apiUrlBase, github.owner, github.repo)
+	if err := github.sendApiJson(ctx, http.MethodPost, advisoriesUrl, advisoryRequest, advisory); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred drafting the security advisory for version '%s'", version)
+	}
+	return advisory.HtmlUrl, nil
+}
+
+func (github *githubForge) supportsApprovalIssues() bool {
+	return true
+}
+
+func (github *githubForge) openApprovalIssue(ctx context.Context, title string, body string) (int64, string, error) {
+	issue := &githubIssueResponse{}
+	issuesUrl := fmt.Sprintf(githubIssuesUrlFormat, github.apiUrlBase, github.owner, github.repo)
+	if err := github.sendApiJson(ctx, http.MethodPost, issuesUrl, &githubIssueRequest{Title: title, Body: body}, issue); err != nil {
+		return 0, "", stacktrace.Propagate(err, "An error occurred opening GitHub issue '%s'", title)
+	}
+	return issue.Number, issue.HtmlUrl, nil
+}
+
+// listIssueComments only lists the first page of comments, which is plenty for an approval issue
+func (github *githubForge) listIssueComments(ctx context.Context, issueNumber int64) ([]issueComment, error) {
+	commentResponses := []githubIssueCommentResponse{}
+	commentsUrl := fmt.Sprintf(githubIssueCommentsUrlFormat+"?per_page=%d", github.apiUrlBase, github.owner, github.repo, issueNumber, githubApiPageSize)
+	if err := github.sendApiJson(ctx, http.MethodGet, commentsUrl, nil, &commentResponses); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the comments on GitHub issue #%d", issueNumber)
+	}
+	comments := []issueComment{}
+	for _, commentResponse := range commentResponses {
+		comments = append(comments, issueComment{author: commentResponse.User.Login, body: commentResponse.Body})
+	}
+	return comments, nil
+}
+
+func (github *githubForge) closeIssue(ctx context.Context, issueNumber int64, comment string) error {
+	commentsUrl := fmt.Sprintf(githubIssueCommentsUrlFormat, github.apiUrlBase, github.owner, github.repo, issueNumber)
+	if err := github.sendApiJson(ctx, http.MethodPost, commentsUrl, &githubIssueCommentRequest{Body: comment}, nil); err != nil {
+		return stacktrace.Propagate(err, "An error occurred commenting on GitHub issue #%d", issueNumber)
+	}
+	issueUrl := fmt.Sprintf(githubIssueUrlFormat, github.apiUrlBase, github.owner, github.repo, issueNumber)
+	if err := github.sendApiJson(ctx, http.MethodPatch, issueUrl, &githubIssueRequest{State: closedIssueState}, nil); err != nil {
+		return stacktrace.Propagate(err, "An error occurred closing GitHub issue #%d", issueNumber)
+	}
+	return nil
+}
+
+func (github *githubForge) supportsPullRequestComments() bool {
+	return true
+}
+
+func (github *githubForge) getMergedPullRequestNumbers(ctx context.Context, commitHash string) ([]int64, error) {
+	pullRequestNumbers := []int64{}
+	pullsUrl := fmt.Sprintf(githubCommitPullsUrlFormat, github.apiUrlBase, github.owner, github.repo, commitHash, githubApiPageSize)
+	err := github.forEachApiPage(ctx, pullsUrl, func(page json.RawMessage) error {
+		pullRequests := []githubPullRequestResponse{}
+		if err := json.Unmarshal(page, &pullRequests); err != nil {
+			return stacktrace.Propagate(err, "An error occurred decoding the pull requests")
+		}
+		for _, pullRequest := range pullRequests {
+			if pullRequest.MergedAt != nil {
+				pullRequestNumbers = append(pullRequestNumbers, pullRequest.Number)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the GitHub pull requests of commit '%s'", commitHash)
+	}
+	return pullRequestNumbers, nil
+}
+
+// commentOnPullRequest comments through the issues API, since GitHub's pull requests are issues too
+func (github *githubForge) commentOnPullRequest(ctx context.Context, pullRequestNumber int64, comment string) error {
+	commentsUrl := fmt.Sprintf(githubIssueCommentsUrlFormat, github.apiUrlBase, github.owner, github.repo, pullRequestNumber)
+	if err := github.sendApiJson(ctx, http.MethodPost, commentsUrl, &githubIssueCommentRequest{Body: comment}, nil); err != nil {
+		return stacktrace.Propagate(err, "An error occurred commenting on GitHub pull request #%d", pullRequestNumber)
+	}
+	return nil
+}
+
+func (github *githubForge) supportsMilestones() bool {
+	return true
+}
+
+func (github *githubForge) listOpenMilestones(ctx context.Context) ([]milestone, error) {
+	milestones := []milestone{}
+	milestonesUrl := fmt.Sprintf(githubMilestonesUrlFormat+"?state=%s&per_page=%d", github.apiUrlBase, github.owner, github.repo, openMilestoneState, githubApiPageSize)
+	err := github.forEachApiPage(ctx, milestonesUrl, func(page json.RawMessage) error {
+		milestoneResponses := []githubMilestoneResponse{}
+		if err := json.Unmarshal(page, &milestoneResponses); err != nil {
+			return stacktrace.Propagate(err, "An error occurred decoding the milestones")
+		}
+		for _, milestoneResponse := range milestoneResponses {
+			milestones = append(milestones, milestone{number: milestoneResponse.Number, title: milestoneResponse.Title})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the open GitHub milestones")
+	}
+	return milestones, nil
+}
+
+func (github *githubForge) createMilestone(ctx context.Context, title string) (int64, error) {
+	milestoneResponse := &githubMilestoneResponse{}
+	milestonesUrl := fmt.Sprintf(githubMilestonesUrlFormat, github.apiUrlBase, github.owner, github.repo)
+	if err := github.sendApiJson(ctx, http.MethodPost, milestonesUrl, &githubMilestoneRequest{Title: title}, milestoneResponse); err != nil {
+		return 0, stacktrace.Propagate(err, "An error occurred creating GitHub milestone '%s'", title)
+	}
+	return milestoneResponse.Number, nil
+}
+
+func (github *githubForge) closeMilestone(ctx context.Context, milestoneNumber int64) error {
+	milestoneUrl := fmt.Sprintf(githubMilestoneUrlFormat, github.apiUrlBase, github.owner, github.repo, milestoneNumber)
+	if err := github.sendApiJson(ctx, http.MethodPatch, milestoneUrl, &githubMilestoneRequest{State: closedMilestoneState}, nil); err != nil {
+		return stacktrace.Propagate(err, "An error occurred closing GitHub milestone #%d", milestoneNumber)
+	}
+	return nil
+}
+
+// listOpenMilestoneIssueNumbers includes pull requests, which GitHub lists among the issues
+func (github *githubForge) listOpenMilestoneIssueNumbers(ctx context.Context, milestoneNumber int64) ([]int64, error) {
+	issueNumbers := []int64{}
+	issuesUrl := fmt.Sprintf(githubMilestoneIssuesUrlFormat, github.apiUrlBase, github.owner, github.repo, milestoneNumber, githubApiPageSize)
+	err := github.forEachApiPage(ctx, issuesUrl, func(page json.RawMessage) error {
+		issues := []githubIssueResponse{}
+		if err := json.Unmarshal(page, &issues); err != nil {
+			return stacktrace.Propagate(err, "An error occurred decoding the issues")
+		}
+		for _, issue := range issues {
+			issueNumbers = append(issueNumbers, issue.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the open issues of GitHub milestone #%d", milestoneNumber)
+	}
+	return issueNumbers, nil
+}
+
+func (github *githubForge) setIssueMilestone(ctx context.Context, issueNumber int64, milestoneNumber int64) error {
+	issueUrl := fmt.Sprintf(githubIssueUrlFormat, github.apiUrlBase, github.owner, github.repo, issueNumber)
+	if err := github.sendApiJson(ctx, http.MethodPatch, issueUrl, &githubIssueMilestoneRequest{Milestone: milestoneNumber}, nil); err != nil {
+		return stacktrace.Propagate(err, "An error occurred moving GitHub issue #%d to milestone #%d", issueNumber, milestoneNumber)
+	}
+	return nil
+}
+
+// getMissingTokenScopes checks classic tokens for the scope that lets them push to the repo and manage its releases
+func (github *githubForge) getMissingTokenScopes(ctx context.Context) ([]string, bool, error) {
+	repo := &githubRepoResponse{}
+	repoUrl := fmt.Sprintf(githubRepoUrlFormat, github.apiUrlBase, github.owner, github.repo)
+	respHeaders, err := sendForgeApiRequestForHeaders(ctx, github.getApiHeaders(), http.MethodGet, repoUrl, "", nil, repo)
+	if err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred getting GitHub repo '%s/%s' with the token", github.owner, github.repo)
+	}
+	if len(respHeaders.Values(githubOAuthScopesHeaderName)) == 0 {
+		return nil, false, nil
+	}
+	for _, scope := range strings.Split(respHeaders.Get(githubOAuthScopesHeaderName), ",") {
+		scope = strings.TrimSpace(scope)
+		if scope == githubRepoScope || (scope == githubPublicRepoScope && !repo.Private) {
+			return []string{}, true, nil
+		}
+	}
+	return []string{githubRepoScope}, true, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (github *githubForge) sendApiJson(ctx context.Context, method string, requestUrl string, requestBody interface{}, result interface{}) error {
+	return sendForgeApiJson(ctx, github.getApiHeaders(), method, requestUrl, requestBody, result)
+}
+
+// forEachApiPage gets each page of a paginated listing in turn, following the 'next' links in the responses' headers
+func (github *githubForge) forEachApiPage(ctx context.Context, firstPageUrl string, handlePage func(page json.RawMessage) error) error {
+	pageUrl := firstPageUrl
+	for pageNumber := 1; pageUrl != ""; pageNumber++ {
+		if pageNumber > githubMaxApiPages {
+			return stacktrace.NewError("Gave up on the listing at '%s' after %d pages", firstPageUrl, githubMaxApiPages)
+		}
+		page := json.RawMessage{}
+		respHeaders, err := sendForgeApiRequestForHeaders(ctx, github.getApiHeaders(), http.MethodGet, pageUrl, "", nil, &page)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting page %d of the listing at '%s'", pageNumber, firstPageUrl)
+		}
+		if err := handlePage(page); err != nil {
+			return stacktrace.Propagate(err, "An error occurred handling page %d of the listing at '%s'", pageNumber, firstPageUrl)
+		}
+		pageUrl = getNextPageUrl(respHeaders.Get(githubLinkHeaderName))
+	}
+	return nil
+}
+
+func (github *githubForge) getApiHeaders() map[string]string {
+	return map[string]string{
+		"Accept":        githubApiAcceptHeader,
+		"Authorization": "token " + github.token,
+	}
+}
+
+func (github *githubForge) uploadReleaseAsset(ctx context.Context, version string, releaseNotes string, assetName string, contentType string, contents []byte) (string, error) {
+	release := &githubReleaseResponse{}
+	releaseByTagUrl := fmt.Sprintf(githubReleaseByTagUrlFormat, github.apiUrlBase, github.owner, github.repo, version)
+	if err := github.sendApiJson(ctx, http.MethodGet, releaseByTagUrl, nil, release); err != nil {
+		// Most likely nobody has created the release yet; if the lookup failed for some other reason, so will this
+		createdRelease, createErr := github.createGithubRelease(ctx, version, releaseNotes, false)
+		if createErr != nil {
+			return "", stacktrace.Propagate(createErr, "No GitHub release of version '%s' could be found or created to upload '%s' to", version, assetName)
+		}
+		release = createdRelease
+	}
+	// The upload URL is a URI template whose parameters we fill in ourselves
+	uploadUrl := strings.SplitN(release.UploadUrl, "{", 2)[0] + "?name=" + url.QueryEscape(assetName)
+	releaseAsset := &githubReleaseAssetResponse{}
+	if err := sendForgeApiRequest(ctx, github.getApiHeaders(), http.MethodPost, uploadUrl, contentType, contents, releaseAsset); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred uploading '%s' to the GitHub release of version '%s'", assetName, version)
+	}
+	return releaseAsset.BrowserDownloadUrl, nil
+}
+
+func (github *githubForge) createGithubRelease(ctx context.Context, version string, releaseNotes string, isDraft bool) (*githubReleaseResponse, error) {
+	release := &githubReleaseResponse{}
+	releaseRequest := &githubReleaseRequest{
+		TagName: version,
+		Name:    version,
+		Body:    releaseNotes,
+		Draft:   isDraft,
+	}
+	releasesUrl := fmt.Sprintf(githubReleasesUrlFormat, github.apiUrlBase, github.owner, github.repo)
+	if err := github.sendApiJson(ctx, http.MethodPost, releasesUrl, releaseRequest, release); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred creating the GitHub release of version '%s'", version)
+	}
+	return release, nil
+}
+
+func newSecurityAdvisoryRequest(repo string, advisoryConfig kudet_config.SecurityAdvisoryConfig, severity string, version string, releaseNotes string) *githubSecurityAdvisoryRequest {
+	advisoryRequest := &githubSecurityAdvisoryRequest{
+		Summary:     fmt.Sprintf("Vulnerability in %s fixed in %s", repo, version),
+		Description: fmt.Sprintf("Fixed in version %s (tag `%s`).\n\n%s", version, version, releaseNotes),
+		Severity:    severity,
+	}
+	if advisoryConfig.Ecosystem != "" {
+		vulnerability := githubSecurityAdvisoryVulnerability{
+			VulnerableVersionRange: "< " + version,
+			PatchedVersions:        version,
+		}
+		vulnerability.Package.Ecosystem = advisoryConfig.Ecosystem
+		vulnerability.Package.Name = advisoryConfig.Package
+		advisoryRequest.Vulnerabilities = []githubSecurityAdvisoryVulnerability{vulnerability}
+	}
+	return advisoryRequest
+}
+
+// getNextPageUrl picks the 'next' link out of a Link header, returning empty on the last page
+func getNextPageUrl(linkHeader string) string {
+	for _, link := range strings.Split(linkHeader, ",") {
+		linkParts := strings.Split(link, ";")
+		isNextLink := false
+		for _, param := range linkParts[1:] {
+			if strings.TrimSpace(param) == githubNextPageRel {
+				isNextLink = true
+			}
+		}
+		if isNextLink {
+			return strings.Trim(strings.TrimSpace(linkParts[0]), "<>")
+		}
+	}
+	return ""
+}
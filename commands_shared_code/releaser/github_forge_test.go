@@ -0,0 +1,23 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSecurityAdvisoryRequest(t *testing.T) {
+	advisoryConfig := kudet_config.SecurityAdvisoryConfig{Ecosystem: "go", Package: "github.com/kurtosis-tech/kudet"}
+	advisoryRequest := newSecurityAdvisoryRequest("kudet", advisoryConfig, "high", "1.2.3", "* Fixed a path traversal")
+
+	require.Equal(t, "high", advisoryRequest.Severity)
+	require.Contains(t, advisoryRequest.Description, "Fixed in version 1.2.3")
+	require.Contains(t, advisoryRequest.Description, "* Fixed a path traversal")
+	require.Len(t, advisoryRequest.Vulnerabilities, 1)
+	require.Equal(t, "github.com/kurtosis-tech/kudet", advisoryRequest.Vulnerabilities[0].Package.Name)
+	require.Equal(t, "< 1.2.3", advisoryRequest.Vulnerabilities[0].VulnerableVersionRange)
+	require.Equal(t, "1.2.3", advisoryRequest.Vulnerabilities[0].PatchedVersions)
+
+	require.Empty(t, newSecurityAdvisoryRequest("kudet", kudet_config.SecurityAdvisoryConfig{}, "high", "1.2.3", "").Vulnerabilities)
+}
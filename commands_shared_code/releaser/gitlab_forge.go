@@ -0,0 +1,284 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// GitLab serves its API under this path of the instance's host, gitlab.com included
+	gitlabApiPath         = "/api/v4"
+	gitlabTokenHeaderName = "PRIVATE-TOKEN"
+
+	// Enough for any pipeline we have; statuses beyond the first page are ignored
+	gitlabApiPageSize = 100
+
+	gitlabCommitStatusesUrlFormat = "%s/projects/%s/repository/commits/%s/statuses?per_page=%d"
+	gitlabReleasesUrlFormat       = "%s/projects/%s/releases"
+	gitlabReleaseUrlFormat        = "%s/projects/%s/releases/%s"
+	// Describes the token that the request is made with, be it a personal, group or project access token
+	gitlabTokenSelfUrlFormat = "%s/personal_access_tokens/self"
+
+	gitlabSuccessStatus  = "success"
+	gitlabSkippedStatus  = "skipped"
+	gitlabFailedStatus   = "failed"
+	gitlabCanceledStatus = "canceled"
+)
+
+// The scopes that let tokens push to the project over HTTPS and manage its releases
+var gitlabReleaseTokenScopes = []string{"api", "write_repository"}
+
+type gitlabCommitStatus struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	AllowFailure bool   `json:"allow_failure"`
+}
+
+type gitlabReleaseRequest struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type gitlabReleaseUpdateRequest struct {
+	Description string `json:"description"`
+}
+
+type gitlabTokenResponse struct {
+	Scopes []string `json:"scopes"`
+}
+
+type gitlabReleaseResponse struct {
+	TagName     string `json:"tag_name"`
+	Description string `json:"description"`
+}
+
+// gitlabForge is a forge backed by the REST API of gitlab.com or a self-managed GitLab instance
+type gitlabForge struct {
+	apiUrlBase string
+	// The URL-encoded 'namespace/project' path, which the API accepts in place of the numeric project ID
+	encodedProjectPath string
+	token              string
+}
+
+func newGitlabForge(host string, projectPath string, apiUrlBase string, token string) *gitlabForge {
+	if apiUrlBase == "" {
+		apiUrlBase = "https://" + host + gitlabApiPath
+	}
+	return &gitlabForge{
+		apiUrlBase:         strings.TrimSuffix(apiUrlBase, "/"),
+		encodedProjectPath: url.PathEscape(projectPath),
+		token:              token,
+	}
+}
+
+func (gitlab *gitlabForge) getName() string {
+	return "GitLab"
+}
+
+// getCiCheckStates gets the statuses of the commit's latest pipeline jobs and any external commit statuses
+func (gitlab *gitlabForge) getCiCheckStates(ctx context.Context, commitHash string) (map[string]ciCheckState, error) {
+	statuses := []gitlabCommitStatus{}
+	statusesUrl := fmt.Sprintf(gitlabCommitStatusesUrlFormat, gitlab.apiUrlBase, gitlab.encodedProjectPath, commitHash, gitlabApiPageSize)
+	if err := gitlab.sendApiJson(ctx, http.MethodGet, statusesUrl, nil, &statuses); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the commit statuses")
+	}
+	return getGitlabCiCheckStates(statuses), nil
+}
+
+// supportsDraftReleases is false because GitLab releases are public as soon as they're created
+func (gitlab *gitlabForge) supportsDraftReleases() bool {
+	return false
+}
+
+// createRelease creates the release, which GitLab identifies by tag rather than by ID, so the returned ID is always 0
+func (gitlab *gitlabForge) createRelease(ctx context.Context, version string, releaseNotes string, isDraft bool) (int64, error) {
+	if isDraft {
+		return 0, stacktrace.NewError("GitLab doesn't support draft releases")
+	}
+	releaseRequest := &gitlabReleaseRequest{
+		TagName:     version,
+		Name:        version,
+		Description: releaseNotes,
+	}
+	releasesUrl := fmt.Sprintf(gitlabReleasesUrlFormat, gitlab.apiUrlBase, gitlab.encodedProjectPath)
+	if err := gitlab.sendApiJson(ctx, http.MethodPost, releasesUrl, releaseRequest, nil); err != nil {
+		return 0, stacktrace.Propagate(err, "An error occurred creating the GitLab release of version '%s'", version)
+	}
+	return 0, nil
+}
+
+func (gitlab *gitlabForge) publishDraftRelease(ctx context.Context, releaseId int64) error {
+	return stacktrace.NewError("GitLab doesn't support draft releases, so draft release '%d' can't be published", releaseId)
+}
+
+// supportsReleaseAssets is false because GitLab releases only link to assets hosted elsewhere
+func (gitlab *gitlabForge) supportsReleaseAssets() bool {
+	return false
+}
+
+func (gitlab *gitlabForge) uploadReleaseJsonAsset(ctx context.Context, version string, releaseNotes string, assetName string, asset interface{}) (string, error) {
+	return "", stacktrace.NewError("GitLab doesn't support uploading release assets")
+}
+
+func (gitlab *gitlabForge) uploadReleaseFileAsset(ctx context.Context, version string, releaseNotes string, assetName string, contents []byte) (string, error) {
+	return "", stacktrace.NewError("GitLab doesn't support uploading release assets")
+}
+
+func (gitlab *gitlabForge) getReleaseFileAssets(ctx context.Context, version string) (map[string][]byte, error) {
+	return nil, stacktrace.NewError("GitLab doesn't support release assets")
+}
+
+func (gitlab *gitlabForge) deleteRelease(ctx context.Context, version string) (bool, error) {
+	releases := []*gitlabReleaseResponse{}
+	releasesUrl := fmt.Sprintf(gitlabReleasesUrlFormat+"?per_page=%d", gitlab.apiUrlBase, gitlab.encodedProjectPath, gitlabApiPageSize)
+	if err := gitlab.sendApiJson(ctx, http.MethodGet, releasesUrl, nil, &releases); err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred listing the GitLab releases")
+	}
+	for _, release := range releases {
+		if release.TagName != version {
+			continue
+		}
+		releaseUrl := fmt.Sprintf(gitlabReleaseUrlFormat, gitlab.apiUrlBase, gitlab.encodedProjectPath, url.PathEscape(version))
+		if err := gitlab.sendApiJson(ctx, http.MethodDelete, releaseUrl, nil, nil); err != nil {
+			return false, stacktrace.Propagate(err, "An error occurred deleting the GitLab release of version '%s'", version)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (gitlab *gitlabForge) markReleaseSuperseded(ctx context.Context, version string, notice string) error {
+	release := &gitlabReleaseResponse{}
+	releaseUrl := fmt.Sprintf(gitlabReleaseUrlFormat, gitlab.apiUrlBase, gitlab.encodedProjectPath, url.PathEscape(version))
+	if err := gitlab.sendApiJson(ctx, http.MethodGet, releaseUrl, nil, release); err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the GitLab release of version '%s'", version)
+	}
+	updateRequest := &gitlabReleaseUpdateRequest{
+		Description: notice + "\n\n" + release.Description,
+	}
+	if err := gitlab.sendApiJson(ctx, http.MethodPut, releaseUrl, updateRequest, nil); err != nil {
+		return stacktrace.Propagate(err, "An error occurred marking the GitLab release of version '%s' as superseded", version)
+	}
+	return nil
+}
+
+// supportsSecurityAdvisories is false because GitLab has no API for drafting advisories on a project
+func (gitlab *gitlabForge) supportsSecurityAdvisories() bool {
+	return false
+}
+
+func (gitlab *gitlabForge) draftSecurityAdvisory(ctx context.Context, advisoryConfig kudet_config.SecurityAdvisoryConfig, severity string, version string, releaseNotes string) (string, error) {
+	return "", stacktrace.NewError("GitLab doesn't support drafting security advisories")
+}
+
+// supportsApprovalIssues is false until approvals are implemented with GitLab's issue notes
+func (gitlab *gitlabForge) supportsApprovalIssues() bool {
+	return false
+}
+
+func (gitlab *gitlabForge) openApprovalIssue(ctx context.Context, title string, body string) (int64, string, error) {
+	return 0, "", stacktrace.NewError("GitLab doesn't support approval issues")
+}
+
+func (gitlab *gitlabForge) listIssueComments(ctx context.Context, issueNumber int64) ([]issueComment, error) {
+	return nil, stacktrace.NewError("GitLab doesn't support approval issues")
+}
+
+func (gitlab *gitlabForge) closeIssue(ctx context.Context, issueNumber int64, comment string) error {
+	return stacktrace.NewError("GitLab doesn't support approval issues")
+}
+
+// supportsPullRequestComments is false until release comments are implemented with GitLab's merge request notes
+func (gitlab *gitlabForge) supportsPullRequestComments() bool {
+	return false
+}
+
+func (gitlab *gitlabForge) getMergedPullRequestNumbers(ctx context.Context, commitHash string) ([]int64, error) {
+	return nil, stacktrace.NewError("GitLab doesn't support pull request comments")
+}
+
+func (gitlab *gitlabForge) commentOnPullRequest(ctx context.Context, pullRequestNumber int64, comment string) error {
+	return stacktrace.NewError("GitLab doesn't support pull request comments")
+}
+
+// supportsMilestones is false until milestones are rolled forward with GitLab's milestones API
+func (gitlab *gitlabForge) supportsMilestones() bool {
+	return false
+}
+
+func (gitlab *gitlabForge) listOpenMilestones(ctx context.Context) ([]milestone, error) {
+	return nil, stacktrace.NewError("GitLab doesn't support milestones")
+}
+
+func (gitlab *gitlabForge) createMilestone(ctx context.Context, title string) (int64, error) {
+	return 0, stacktrace.NewError("GitLab doesn't support milestones")
+}
+
+func (gitlab *gitlabForge) closeMilestone(ctx context.Context, milestoneNumber int64) error {
+	return stacktrace.NewError("GitLab doesn't support milestones")
+}
+
+func (gitlab *gitlabForge) listOpenMilestoneIssueNumbers(ctx context.Context, milestoneNumber int64) ([]int64, error) {
+	return nil, stacktrace.NewError("GitLab doesn't support milestones")
+}
+
+func (gitlab *gitlabForge) setIssueMilestone(ctx context.Context, issueNumber int64, milestoneNumber int64) error {
+	return stacktrace.NewError("GitLab doesn't support milestones")
+}
+
+func (gitlab *gitlabForge) getMissingTokenScopes(ctx context.Context) ([]string, bool, error) {
+	token := &gitlabTokenResponse{}
+	tokenUrl := fmt.Sprintf(gitlabTokenSelfUrlFormat, gitlab.apiUrlBase)
+	if err := gitlab.sendApiJson(ctx, http.MethodGet, tokenUrl, nil, token); err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred getting the GitLab token's scopes")
+	}
+	tokenScopes := map[string]bool{}
+	for _, scope := range token.Scopes {
+		tokenScopes[scope] = true
+	}
+	missingScopes := []string{}
+	for _, scope := range gitlabReleaseTokenScopes {
+		if !tokenScopes[scope] {
+			missingScopes = append(missingScopes, scope)
+		}
+	}
+	return missingScopes, true, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (gitlab *gitlabForge) sendApiJson(ctx context.Context, method string, requestUrl string, requestBody interface{}, result interface{}) error {
+	headers := map[string]string{
+		gitlabTokenHeaderName: gitlab.token,
+	}
+	return sendForgeApiJson(ctx, headers, method, requestUrl, requestBody, result)
+}
+
+// getGitlabCiCheckStates treats jobs that are allowed to fail as passed, and jobs that haven't finished (including
+// manual jobs that were never run) as pending
+func getGitlabCiCheckStates(statuses []gitlabCommitStatus) map[string]ciCheckState {
+	checkStates := map[string]ciCheckState{}
+	for _, status := range statuses {
+		state := pendingCiCheckState
+		switch status.Status {
+		case gitlabSuccessStatus, gitlabSkippedStatus:
+			state = passedCiCheckState
+		case gitlabFailedStatus, gitlabCanceledStatus:
+			state = failedCiCheckState
+			if status.AllowFailure {
+				state = passedCiCheckState
+			}
+		}
+		checkStates[status.Name] = worseCiCheckState(checkStates, status.Name, state)
+	}
+	return checkStates
+}
@@ -0,0 +1,87 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// The rollout stayed healthy for the whole health check
+	verifiedRolloutStatus = "verified"
+	// The health check failed during the rollout, so the release may need rolling back
+	unhealthyRolloutStatus = "unhealthy"
+
+	healthCheckRequestTimeout = 10 * time.Second
+)
+
+// verifyRolloutHealthIfNeeded polls the configured health check for its whole duration once the release is out, returning
+// the rollout status to record for the release, or an empty one if it wasn't checked. The release is irreversible by
+// the time this runs, so an unhealthy rollout is raised for the operator to roll back rather than failing the release.
+func verifyRolloutHealthIfNeeded(ctx context.Context, healthCheckConfig kudet_config.HealthCheckConfig, version string, promotedEnvironmentNames []string) string {
+	if healthCheckConfig.Url == "" {
+		return ""
+	}
+	logrus.Infof("Checking the health of the rollout of version '%s' at '%s' for %v...", version, healthCheckConfig.Url, healthCheckConfig.Duration)
+	httpClient := &http.Client{Timeout: healthCheckRequestTimeout}
+	if err := pollHealthCheck(ctx, httpClient, healthCheckConfig); err != nil {
+		if ctx.Err() != nil {
+			logrus.Warnf("The health check of the rollout of version '%s' was interrupted, so the rollout is unverified", version)
+			return ""
+		}
+		rollbackSuggestion := "consider rolling back to the previous version"
+		if len(promotedEnvironmentNames) > 0 {
+			rollbackSuggestion = "consider rolling environments '" + strings.Join(promotedEnvironmentNames, ", ") + "' back to the previous version in the GitOps repo"
+		}
+		logrus.Errorf("ACTION REQUIRED: The rollout of version '%s' is unhealthy; %s:\n%v", version, rollbackSuggestion, err)
+		return unhealthyRolloutStatus
+	}
+	logrus.Infof("The rollout of version '%s' stayed healthy for %v; marking it verified", version, healthCheckConfig.Duration)
+	return verifiedRolloutStatus
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// pollHealthCheck polls the health check every interval until its duration has passed, failing on the first unhealthy
+// response
+func pollHealthCheck(ctx context.Context, httpClient *http.Client, healthCheckConfig kudet_config.HealthCheckConfig) error {
+	deadline := time.Now().Add(healthCheckConfig.Duration)
+	ticker := time.NewTicker(healthCheckConfig.Interval)
+	defer ticker.Stop()
+	for {
+		if err := checkHealth(ctx, httpClient, healthCheckConfig.Url); err != nil {
+			return stacktrace.Propagate(err, "The health check at '%s' failed", healthCheckConfig.Url)
+		}
+		if !time.Now().Before(deadline) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return stacktrace.Propagate(ctx.Err(), "The health check was interrupted")
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkHealth(ctx context.Context, httpClient *http.Client, healthCheckUrl string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthCheckUrl, nil)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred building the health check request")
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred requesting the health check")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return stacktrace.NewError("The health check responded with unhealthy status '%s'", resp.Status)
+	}
+	return nil
+}
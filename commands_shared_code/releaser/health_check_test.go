@@ -0,0 +1,39 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestVerifyRolloutHealthIfNeeded(t *testing.T) {
+	var numRequests int32
+	healthyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&numRequests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthyServer.Close()
+	unhealthyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthyServer.Close()
+
+	healthCheckConfig := kudet_config.HealthCheckConfig{
+		Url:      healthyServer.URL,
+		Duration: 30 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+	}
+	require.Equal(t, verifiedRolloutStatus, verifyRolloutHealthIfNeeded(context.Background(), healthCheckConfig, "0.2.0", nil))
+	require.GreaterOrEqual(t, atomic.LoadInt32(&numRequests), int32(3))
+
+	healthCheckConfig.Url = unhealthyServer.URL
+	require.Equal(t, unhealthyRolloutStatus, verifyRolloutHealthIfNeeded(context.Background(), healthCheckConfig, "0.2.0", []string{"staging"}))
+
+	healthCheckConfig.Url = ""
+	require.Empty(t, verifyRolloutHealthIfNeeded(context.Background(), healthCheckConfig, "0.2.0", nil))
+}
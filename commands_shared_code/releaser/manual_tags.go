@@ -0,0 +1,164 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"time"
+)
+
+// TagRelease creates the 'X.Y.Z' and 'vX.Y.Z' tags of a version on the given revision the way a release does, for
+// hotfixes that are released by hand rather than through the release flow. With pushing, the tags are pushed in the
+// order a release pushes them, and both are deleted again, locally and from origin, if either can't be pushed.
+func (releaser *Releaser) TagRelease(ctx context.Context, version string, revision string, shouldPush bool) error {
+	kudetConfig, err := releaser.getKudetConfig()
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the kudet config for the repo")
+	}
+	releaseVersion, err := semver.StrictNewVersion(version)
+	if err != nil {
+		return stacktrace.NewError("Version '%s' isn't a valid 'X.Y.Z' semantic version; give it without the '%s' prefix, which is tagged alongside it", version, vPrefix)
+	}
+
+	repository, err := releaser.openRepository(releaser.repoDirpath, releaser.token)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	if shouldPush {
+		if err := releaser.authenticateAsGithubAppIfNeeded(ctx, repository, kudetConfig); err != nil {
+			return stacktrace.Propagate(err, "An error occurred authenticating as the kudet config's GitHub App")
+		}
+	}
+	author, err := repository.GetAuthor()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the author to make the release tags as")
+	}
+	if armoredSigningKey := os.Getenv(tagSigningKeyEnvVar); armoredSigningKey != "" {
+		if err := repository.SetTagSigningKey(armoredSigningKey); err != nil {
+			return stacktrace.Propagate(err, "An error occurred setting the key in '%s' to sign the release tags with", tagSigningKeyEnvVar)
+		}
+	}
+	commitHash, err := repository.ResolveRevision(revision)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred resolving revision '%s' to tag", revision)
+	}
+
+	if err := verifyReleaseTagsDoNotExist(repository, version); err != nil {
+		return stacktrace.Propagate(err, "Refusing to tag version '%s'", version)
+	}
+	releaseTag := version
+	vReleaseTag := vPrefix + version
+	if shouldPush {
+		remoteRefHashes, err := repository.ListRemoteRefs(ctx)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred listing the refs of '%s'", originRemoteName)
+		}
+		for _, tagName := range []string{releaseTag, vReleaseTag} {
+			if _, found := remoteRefHashes[tagsPrefix+tagName]; found {
+				return stacktrace.NewErrorWithCode(ErrReleaseTagExists.code, "Tag '%s' already exists on '%s', meaning version '%s' was already at least partially released", tagName, originRemoteName, version)
+			}
+		}
+	}
+
+	tagNames, err := repository.ListTagNames()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred listing the repo's tags")
+	}
+	previousReleaseVersion, err := getReleaseVersionBefore(tagNames, kudetConfig.TagParsing, releaseVersion)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the release before version '%s'", version)
+	}
+	// A hand-made release has no changelog section of its own to count the entries of
+	tagTime := time.Now()
+	releaseTagMsg, err := getReleaseTagMessage(kudetConfig, releaseTag, version, previousReleaseVersion.String(), author, tagTime, nil)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the message of the release tag")
+	}
+	vReleaseTagMsg, err := getReleaseTagMessage(kudetConfig, vReleaseTag, version, previousReleaseVersion.String(), author, tagTime, nil)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the message of the 'v'-prefixed release tag")
+	}
+
+	logrus.Infof("Tagging commit '%s' as '%s' and '%s'...", commitHash, releaseTag, vReleaseTag)
+	if err := repository.CreateTag(releaseTag, commitHash, releaseTagMsg); err != nil {
+		return stacktrace.Propagate(err, "An error occurred while attempting to create git tag '%s'", releaseTag)
+	}
+	shouldDeleteLocalReleaseTag := true
+	defer func() {
+		if shouldDeleteLocalReleaseTag {
+			if err := repository.DeleteTag(releaseTag); err != nil {
+				logrus.Errorf("ACTION REQUIRED: An error occurred attempting to undo creation of tag '%s'. Please run 'git tag -d %s' to delete the tag manually.", releaseTag, releaseTag)
+			}
+		}
+	}()
+	if err := repository.CreateTag(vReleaseTag, commitHash, vReleaseTagMsg); err != nil {
+		return stacktrace.Propagate(err, "An error occurred while attempting to create git tag '%s'", vReleaseTag)
+	}
+	shouldDeleteLocalVPrefixedReleaseTag := true
+	defer func() {
+		if shouldDeleteLocalVPrefixedReleaseTag {
+			if err := repository.DeleteTag(vReleaseTag); err != nil {
+				logrus.Errorf("ACTION REQUIRED: An error occurred attempting to undo creation of tag '%s'. Please run 'git tag -d %s' to delete the tag manually.", vReleaseTag, vReleaseTag)
+			}
+		}
+	}()
+
+	if !shouldPush {
+		shouldDeleteLocalReleaseTag = false
+		shouldDeleteLocalVPrefixedReleaseTag = false
+		logrus.Infof("Created tags '%s' and '%s' locally; push them with 'git push %s %s %s', '%s' last since it triggers the release's CI", releaseTag, vReleaseTag, originRemoteName, vReleaseTag, releaseTag, releaseTag)
+		return nil
+	}
+
+	// Like a release, the 'v'-prefixed tag goes first, since the bare one is what triggers CI
+	logrus.Infof("Pushing tags '%s' and '%s' to '%s'...", vReleaseTag, releaseTag, originRemoteName)
+	vReleaseTagRefSpec := fmt.Sprintf("%s%s:%s%s", tagsPrefix, vReleaseTag, tagsPrefix, vReleaseTag)
+	if err := repository.Push(ctx, vReleaseTagRefSpec); err != nil {
+		return stacktrace.PropagateWithCode(err, ErrPushRejected.code, "An error occurred pushing tag '%s' to '%s'", vReleaseTag, originRemoteName)
+	}
+	shouldDeleteRemoteVPrefixedReleaseTag := true
+	defer func() {
+		if shouldDeleteRemoteVPrefixedReleaseTag {
+			emptyVReleaseTagRefSpec := fmt.Sprintf(":%s%s", tagsPrefix, vReleaseTag)
+			// The context may already be cancelled by the time we roll back, so cleanup must not depend on it
+			if err := repository.Push(context.Background(), emptyVReleaseTagRefSpec); err != nil {
+				logrus.Errorf("ACTION REQUIRED: An error occurred attempting to delete tag '%s' from '%s'. Please run 'git push --delete %s %s' to delete the tag manually.", vReleaseTag, originRemoteName, originRemoteName, vReleaseTag)
+			}
+		}
+	}()
+	releaseTagRefSpec := fmt.Sprintf("%s%s:%s%s", tagsPrefix, releaseTag, tagsPrefix, releaseTag)
+	if err := repository.Push(ctx, releaseTagRefSpec); err != nil {
+		return stacktrace.PropagateWithCode(err, ErrPushRejected.code, "An error occurred pushing tag '%s' to '%s'", releaseTag, originRemoteName)
+	}
+	shouldDeleteLocalReleaseTag = false
+	shouldDeleteLocalVPrefixedReleaseTag = false
+	shouldDeleteRemoteVPrefixedReleaseTag = false
+	logrus.Infof("Pushed tags '%s' and '%s' to '%s'", releaseTag, vReleaseTag, originRemoteName)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getReleaseVersionBefore returns the highest released version below the given one, which for hotfixes of older
+// versions isn't the latest release, or '0.0.0' if there's none; unlike getPreviousReleaseVersion, the given version
+// needn't have been released
+func getReleaseVersionBefore(tagNames []string, tagParsingConfig kudet_config.TagParsingConfig, releaseVersion *semver.Version) (*semver.Version, error) {
+	releasedVersions, _ := parseReleaseVersionTags(tagNames, tagParsingConfig)
+	previousReleaseVersion, err := semver.StrictNewVersion(noPreviousVersion)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred creating '%s' semantic version.", noPreviousVersion)
+	}
+	for _, releasedVersion := range releasedVersions {
+		if releasedVersion.LessThan(releaseVersion) && releasedVersion.GreaterThan(previousReleaseVersion) {
+			previousReleaseVersion = releasedVersion
+		}
+	}
+	return previousReleaseVersion, nil
+}
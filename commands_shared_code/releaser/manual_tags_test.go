@@ -0,0 +1,92 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagRelease(t *testing.T) {
+	repoDirpath := t.TempDir()
+	originDirpath := t.TempDir()
+	_, err := git.PlainInit(originDirpath, true)
+	require.NoError(t, err)
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{originDirpath}})
+	require.NoError(t, err)
+	xdgConfigDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(xdgConfigDirpath, "git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(xdgConfigDirpath, "git", "config"), []byte("[user]\n\tname = Kudet\n\temail = kudet@example.com\n"), 0644))
+	t.Setenv("XDG_CONFIG_HOME", xdgConfigDirpath)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	signature := &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()}
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "version.txt"), []byte("1.4.0\n"), 0644))
+	releaseCommitHash, err := repository.CommitAll("Release 1.4.0", signature)
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("1.4.0", releaseCommitHash, "1.4.0"))
+	require.NoError(t, repository.CreateTag("1.5.0", releaseCommitHash, "1.5.0"))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "version.txt"), []byte("1.4.1\n"), 0644))
+	hotfixCommitHash, err := repository.CommitAll("Fix the module", signature)
+	require.NoError(t, err)
+	branchName, _, err := repository.GetCheckedOutBranchName()
+	require.NoError(t, err)
+	require.NoError(t, gitRepository.Push(&git.PushOptions{RemoteName: vcs.OriginRemoteName, RefSpecs: []config.RefSpec{config.RefSpec("refs/heads/" + branchName + ":refs/heads/" + branchName)}}))
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.TagMessageTemplate = "{{ .TagName }} follows {{ .PreviousVersion }}"
+	tagReleaser := NewReleaser(repoDirpath, "token", WithKudetConfig(kudetConfig))
+	require.Error(t, tagReleaser.TagRelease(context.Background(), "v1.4.1", "HEAD", false))
+	require.Error(t, tagReleaser.TagRelease(context.Background(), "1.4.0", "HEAD", false))
+
+	// Without pushing, the tags are only created locally
+	require.NoError(t, tagReleaser.TagRelease(context.Background(), "1.4.1", "HEAD", false))
+	for _, tagName := range []string{"1.4.1", "v1.4.1"} {
+		commitHash, found, err := repository.GetTagCommitHash(tagName)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, hotfixCommitHash, commitHash)
+	}
+	tagObject, err := gitRepository.Tag("v1.4.1")
+	require.NoError(t, err)
+	annotatedTag, err := gitRepository.TagObject(tagObject.Hash())
+	require.NoError(t, err)
+	require.Equal(t, "v1.4.1 follows 1.4.0", strings.TrimSpace(annotatedTag.Message))
+	remoteRefHashes, err := repository.ListRemoteRefs(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, remoteRefHashes, tagsPrefix+"1.4.1")
+
+	require.NoError(t, tagReleaser.TagRelease(context.Background(), "1.4.2", releaseCommitHash, true))
+	remoteRefHashes, err = repository.ListRemoteRefs(context.Background())
+	require.NoError(t, err)
+	for _, tagName := range []string{"1.4.2", "v1.4.2"} {
+		_, found := remoteRefHashes[tagsPrefix+tagName]
+		require.True(t, found)
+		commitHash, found, err := repository.GetTagCommitHash(tagName)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, releaseCommitHash, commitHash)
+	}
+
+	// Tags already on origin are refused even if they're missing locally
+	require.NoError(t, repository.DeleteTag("1.4.2"))
+	require.NoError(t, repository.DeleteTag("v1.4.2"))
+	err = tagReleaser.TagRelease(context.Background(), "1.4.2", "HEAD", true)
+	require.Error(t, err)
+	releaseErr, found := GetReleaseError(err)
+	require.True(t, found)
+	require.Equal(t, ErrReleaseTagExists, releaseErr)
+	_, found, err = repository.GetTagCommitHash("1.4.2")
+	require.NoError(t, err)
+	require.False(t, found)
+}
@@ -0,0 +1,121 @@
+package releaser
+
+import (
+	"context"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+// milestone is a forge milestone, which groups the issues and pull requests planned for a version
+type milestone struct {
+	number int64
+	title  string
+}
+
+// rollMilestonesForwardIfNeeded closes the milestone of the released version once whatever is still open on it has
+// been moved to the next version's milestone, creating that one if it doesn't exist yet; like the other post-release
+// steps, failures are only logged
+func (releaser *Releaser) rollMilestonesForwardIfNeeded(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, state *releaseState) {
+	milestonesConfig := kudetConfig.Milestones
+	if !milestonesConfig.Enabled {
+		return
+	}
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		logrus.Warnf("Not rolling the milestone of '%s' forward, as an error occurred determining the forge: %v", state.Version, err)
+		return
+	}
+	if !releaseForge.supportsMilestones() {
+		logrus.Warnf("Not rolling the milestone of '%s' forward, as %s doesn't support milestones", state.Version, releaseForge.getName())
+		return
+	}
+	nextVersion, err := getNextMilestoneVersion(state.Version, milestonesConfig.NextBump)
+	if err != nil {
+		logrus.Warnf("Not rolling the milestone of '%s' forward, as an error occurred determining the next version: %v", state.Version, err)
+		return
+	}
+	if err := rollMilestonesForward(ctx, releaseForge, state.Version, nextVersion); err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred rolling the milestone of '%s' forward to '%s'; move its open issues and close it by hand:\n%v", state.Version, nextVersion, err)
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func rollMilestonesForward(ctx context.Context, releaseForge forge, version string, nextVersion string) error {
+	openMilestones, err := releaseForge.listOpenMilestones(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred listing the open milestones")
+	}
+	releasedMilestone, found := findVersionMilestone(openMilestones, version)
+	if !found {
+		logrus.Infof("There's no open milestone for '%s' to roll forward", version)
+		return nil
+	}
+	// The next milestone is named like the released one, with or without the 'v' prefix
+	nextMilestoneTitle := nextVersion
+	if releasedMilestone.title == vPrefix+version {
+		nextMilestoneTitle = vPrefix + nextVersion
+	}
+	nextMilestone, found := findVersionMilestone(openMilestones, nextVersion)
+	if !found {
+		logrus.Infof("Creating milestone '%s'...", nextMilestoneTitle)
+		nextMilestoneNumber, err := releaseForge.createMilestone(ctx, nextMilestoneTitle)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred creating milestone '%s'", nextMilestoneTitle)
+		}
+		nextMilestone = milestone{number: nextMilestoneNumber, title: nextMilestoneTitle}
+	}
+
+	openIssueNumbers, err := releaseForge.listOpenMilestoneIssueNumbers(ctx, releasedMilestone.number)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred listing the open issues of milestone '%s'", releasedMilestone.title)
+	}
+	if len(openIssueNumbers) > 0 {
+		logrus.Infof("Moving the %d open issues of milestone '%s' to '%s'...", len(openIssueNumbers), releasedMilestone.title, nextMilestone.title)
+	}
+	for _, issueNumber := range openIssueNumbers {
+		if err := releaseForge.setIssueMilestone(ctx, issueNumber, nextMilestone.number); err != nil {
+			return stacktrace.Propagate(err, "An error occurred moving issue #%d to milestone '%s'", issueNumber, nextMilestone.title)
+		}
+	}
+	if err := releaseForge.closeMilestone(ctx, releasedMilestone.number); err != nil {
+		return stacktrace.Propagate(err, "An error occurred closing milestone '%s'", releasedMilestone.title)
+	}
+	logrus.Infof("Closed milestone '%s' and rolled its open issues forward to '%s'", releasedMilestone.title, nextMilestone.title)
+	return nil
+}
+
+// findVersionMilestone finds the version's milestone, titled either 'X.Y.Z' or 'vX.Y.Z'
+func findVersionMilestone(milestones []milestone, version string) (milestone, bool) {
+	for _, candidate := range milestones {
+		if candidate.title == version || candidate.title == vPrefix+version {
+			return candidate, true
+		}
+	}
+	return milestone{}, false
+}
+
+func getNextMilestoneVersion(version string, nextBump string) (string, error) {
+	releasedVersion, err := semver.StrictNewVersion(version)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "'%s' isn't a valid semantic version", version)
+	}
+	var nextVersion semver.Version
+	switch nextBump {
+	case kudet_config.MajorBump:
+		nextVersion = releasedVersion.IncMajor()
+	case kudet_config.MinorBump:
+		nextVersion = releasedVersion.IncMinor()
+	case kudet_config.PatchBump:
+		nextVersion = releasedVersion.IncPatch()
+	default:
+		return "", stacktrace.NewError("Unrecognized bump '%s'; this is a bug in kudet", nextBump)
+	}
+	return nextVersion.String(), nil
+}
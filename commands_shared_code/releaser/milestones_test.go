@@ -0,0 +1,101 @@
+package releaser
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNextMilestoneVersion(t *testing.T) {
+	for nextBump, expectedVersion := range map[string]string{
+		kudet_config.MajorBump: "2.0.0",
+		kudet_config.MinorBump: "1.5.0",
+		kudet_config.PatchBump: "1.4.1",
+	} {
+		nextVersion, err := getNextMilestoneVersion("1.4.0", nextBump)
+		require.NoError(t, err)
+		require.Equal(t, expectedVersion, nextVersion)
+	}
+	_, err := getNextMilestoneVersion("v1.4.0", kudet_config.MinorBump)
+	require.Error(t, err)
+}
+
+func TestRollMilestonesForwardIfNeeded(t *testing.T) {
+	lock := &sync.Mutex{}
+	createdMilestoneTitles := []string{}
+	issueMilestones := map[string]int64{}
+	closedMilestonePaths := []string{}
+	githubApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/milestones":
+			require.Equal(t, "open", r.URL.Query().Get("state"))
+			_, err := w.Write([]byte(`[{"number": 2, "title": "Backlog"}, {"number": 3, "title": "v1.4.0"}]`))
+			require.NoError(t, err)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/milestones":
+			milestoneRequest := &githubMilestoneRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(milestoneRequest))
+			createdMilestoneTitles = append(createdMilestoneTitles, milestoneRequest.Title)
+			_, err := w.Write([]byte(`{"number": 4, "title": "v1.5.0"}`))
+			require.NoError(t, err)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/issues":
+			require.Equal(t, "3", r.URL.Query().Get("milestone"))
+			require.Equal(t, "open", r.URL.Query().Get("state"))
+			_, err := w.Write([]byte(`[{"number": 11}, {"number": 12}]`))
+			require.NoError(t, err)
+		case r.Method == http.MethodPatch && (r.URL.Path == "/repos/owner/repo/issues/11" || r.URL.Path == "/repos/owner/repo/issues/12"):
+			milestoneRequest := &githubIssueMilestoneRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(milestoneRequest))
+			issueMilestones[r.URL.Path] = milestoneRequest.Milestone
+			_, err := w.Write([]byte(`{}`))
+			require.NoError(t, err)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/owner/repo/milestones/3":
+			milestoneRequest := &githubMilestoneRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(milestoneRequest))
+			require.Equal(t, "closed", milestoneRequest.State)
+			// The issues have to be moved off the milestone before it's closed
+			require.Len(t, issueMilestones, 2)
+			closedMilestonePaths = append(closedMilestonePaths, r.URL.Path)
+			_, err := w.Write([]byte(`{}`))
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer githubApi.Close()
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{"https://github.com/owner/repo.git"}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.Forge = kudet_config.ForgeConfig{Type: kudet_config.GithubForgeType, ApiUrl: githubApi.URL}
+	releaser := NewReleaser(repoDirpath, "token")
+
+	// Nothing is touched unless it's enabled
+	releaser.rollMilestonesForwardIfNeeded(context.Background(), repository, kudetConfig, &releaseState{Version: "1.4.0"})
+	require.Empty(t, closedMilestonePaths)
+
+	kudetConfig.Milestones.Enabled = true
+	releaser.rollMilestonesForwardIfNeeded(context.Background(), repository, kudetConfig, &releaseState{Version: "1.4.0"})
+	require.Equal(t, []string{"v1.5.0"}, createdMilestoneTitles)
+	require.Equal(t, map[string]int64{"/repos/owner/repo/issues/11": 4, "/repos/owner/repo/issues/12": 4}, issueMilestones)
+	require.Equal(t, []string{"/repos/owner/repo/milestones/3"}, closedMilestonePaths)
+
+	// Releases without a milestone of their own leave the milestones alone
+	releaser.rollMilestonesForwardIfNeeded(context.Background(), repository, kudetConfig, &releaseState{Version: "1.4.1"})
+	require.Len(t, createdMilestoneTitles, 1)
+	require.Len(t, closedMilestonePaths, 1)
+}
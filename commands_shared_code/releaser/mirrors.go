@@ -0,0 +1,76 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// pushReleaseToMirrors pushes the release branch and the version's tags to each of the mirrors once origin has them.
+// The release is irreversible by then, so every mirror is attempted and failures are logged; they're only returned for
+// the mirrors whose failure policy is to fail the release.
+func (releaser *Releaser) pushReleaseToMirrors(ctx context.Context, repository vcs.Repository, mirrors []kudet_config.MirrorConfig, releaseBranchName string, version string) error {
+	if len(mirrors) == 0 {
+		return nil
+	}
+	releaseTag := version
+	vReleaseTag := vPrefix + version
+	// Same order as for origin, with the tag that triggers CI last
+	refSpecs := []string{
+		fmt.Sprintf("%s%s:%s%s", tagsPrefix, vReleaseTag, tagsPrefix, vReleaseTag),
+		fmt.Sprintf("%s%s:%s%s", headRef, releaseBranchName, headRef, releaseBranchName),
+		fmt.Sprintf("%s%s:%s%s", tagsPrefix, releaseTag, tagsPrefix, releaseTag),
+	}
+	failedMirrorUrls := []string{}
+	for _, mirror := range mirrors {
+		mirrorUrl := getMirrorDisplayUrl(mirror.Url)
+		logrus.Infof("Pushing release '%s' to mirror '%s'...", version, mirrorUrl)
+		if err := releaser.pushToMirror(ctx, repository, mirror, refSpecs); err != nil {
+			logrus.Errorf("ACTION REQUIRED: An error occurred pushing release '%s' to mirror '%s'; the release itself succeeded, so please push '%s' and tags '%s' and '%s' to the mirror by hand:\n%v", version, mirrorUrl, releaseBranchName, vReleaseTag, releaseTag, err)
+			if mirror.OnFailure == kudet_config.FailMirrorFailurePolicy {
+				failedMirrorUrls = append(failedMirrorUrls, mirrorUrl)
+			}
+			continue
+		}
+		logrus.Infof("Pushed release '%s' to mirror '%s'", version, mirrorUrl)
+	}
+	if len(failedMirrorUrls) > 0 {
+		return stacktrace.NewErrorWithCode(ErrMirrorPushFailed.code, "Release '%s' couldn't be pushed to mirrors '%s'", version, strings.Join(failedMirrorUrls, "', '"))
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (releaser *Releaser) pushToMirror(ctx context.Context, repository vcs.Repository, mirror kudet_config.MirrorConfig, refSpecs []string) error {
+	token := releaser.token
+	if mirror.TokenEnvVar != "" {
+		token = os.Getenv(mirror.TokenEnvVar)
+		if token == "" {
+			return stacktrace.NewError("The mirror's token is read from environment variable '%s', which isn't set", mirror.TokenEnvVar)
+		}
+	}
+	if err := repository.PushToUrl(ctx, mirror.Url, token, refSpecs...); err != nil {
+		return stacktrace.Propagate(err, "An error occurred pushing to the mirror")
+	}
+	return nil
+}
+
+// getMirrorDisplayUrl returns the mirror's URL without any credentials embedded in it, for logs
+func getMirrorDisplayUrl(mirrorUrl string) string {
+	parsedUrl, err := url.Parse(mirrorUrl)
+	if err != nil || parsedUrl.User == nil {
+		return mirrorUrl
+	}
+	parsedUrl.User = nil
+	return parsedUrl.String()
+}
@@ -0,0 +1,49 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"path"
+	"strings"
+)
+
+// getOnlyNonReleasableChangesProblem checks whether every file changed since the previous release is non-releasable,
+// e.g. only vendored dependencies or CI config, and if so returns a description of it. The changelogs and the approved
+// release notes are left out, since they describe the changes rather than being ones. Before the first release, and
+// when nothing changed at all, there's no problem to report.
+func getOnlyNonReleasableChangesProblem(repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, previousVersion string) (string, bool, error) {
+	nonReleasablePaths := kudetConfig.NonReleasableChanges.Paths
+	if len(nonReleasablePaths) == 0 {
+		return "", false, nil
+	}
+	changedFilepaths, hasPreviousRelease, err := getFilepathsChangedSinceRelease(repository, previousVersion)
+	if err != nil {
+		return "", false, stacktrace.Propagate(err, "An error occurred getting the files changed since the previous release")
+	}
+	if !hasPreviousRelease {
+		return "", false, nil
+	}
+	releaseDocFilepaths := map[string]bool{
+		path.Clean(kudetConfig.ChangelogFilepath):            true,
+		path.Clean(kudetConfig.ApprovedReleaseNotesFilepath): true,
+	}
+	for _, additionalChangelogFilepath := range kudetConfig.AdditionalChangelogFilepaths {
+		releaseDocFilepaths[path.Clean(additionalChangelogFilepath)] = true
+	}
+	numNonReleasableChanges := 0
+	for _, changedFilepath := range changedFilepaths {
+		if releaseDocFilepaths[changedFilepath] {
+			continue
+		}
+		if _, isNonReleasable := getChangedFilepathMatch([]string{changedFilepath}, nonReleasablePaths); !isNonReleasable {
+			return "", false, nil
+		}
+		numNonReleasableChanges++
+	}
+	if numNonReleasableChanges == 0 {
+		return "", false, nil
+	}
+	return fmt.Sprintf("The only files changed since release '%s' are non-releasable ones matching '%s'", previousVersion, strings.Join(nonReleasablePaths, "', '")), true, nil
+}
@@ -0,0 +1,63 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOnlyNonReleasableChangesProblem(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	author := &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()}
+	writeFile := func(relFilepath string, contents string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repoDirpath, relFilepath)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, relFilepath), []byte(contents), 0644))
+	}
+	writeFile("main.go", "package main\n")
+	writeFile("vendor/lib/lib.go", "package lib\n")
+	releaseCommitHash, err := repository.CommitAll("Release 0.1.0", author)
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.0", releaseCommitHash, "0.1.0"))
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.NonReleasableChanges.Paths = []string{"vendor/", ".github/**"}
+	// Nothing changed yet, which isn't for this check to report
+	_, isOnlyNonReleasable, err := getOnlyNonReleasableChangesProblem(repository, kudetConfig, "0.1.0")
+	require.NoError(t, err)
+	require.False(t, isOnlyNonReleasable)
+
+	// Changelog entries describing the housekeeping don't make it releasable
+	writeFile("vendor/lib/lib.go", "package lib\n\nconst Version = 2\n")
+	writeFile(".github/workflows/ci.yml", "on: push\n")
+	writeFile(kudetConfig.ChangelogFilepath, "# TBD\n* Bumped the vendored lib\n")
+	_, err = repository.CommitAll("Bump the vendored lib", author)
+	require.NoError(t, err)
+	problem, isOnlyNonReleasable, err := getOnlyNonReleasableChangesProblem(repository, kudetConfig, "0.1.0")
+	require.NoError(t, err)
+	require.True(t, isOnlyNonReleasable)
+	require.Equal(t, "The only files changed since release '0.1.0' are non-releasable ones matching 'vendor/', '.github/**'", problem)
+	// There's nothing to compare against before the first release
+	_, isOnlyNonReleasable, err = getOnlyNonReleasableChangesProblem(repository, kudetConfig, noPreviousVersion)
+	require.NoError(t, err)
+	require.False(t, isOnlyNonReleasable)
+
+	writeFile("main.go", "package main\n\nfunc main() {}\n")
+	_, err = repository.CommitAll("Add main", author)
+	require.NoError(t, err)
+	_, isOnlyNonReleasable, err = getOnlyNonReleasableChangesProblem(repository, kudetConfig, "0.1.0")
+	require.NoError(t, err)
+	require.False(t, isOnlyNonReleasable)
+}
@@ -0,0 +1,77 @@
+package releaser
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+const (
+	notificationContentType = "application/json"
+	notificationTimeout     = 10 * time.Second
+)
+
+// releaseNotification is the JSON body POSTed to each configured webhook after a successful release
+type releaseNotification struct {
+	Version         string `json:"version"`
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	CommitHash      string `json:"commitHash"`
+	Branch          string `json:"branch"`
+	// The repo's owners, for notification receivers to mention
+	RepoOwners []string `json:"repoOwners,omitempty"`
+	// The environments the release was promoted to in the GitOps repo
+	PromotedEnvironments []string `json:"promotedEnvironments,omitempty"`
+	// Whether the rollout passed its health check ('verified' or 'unhealthy'), if one is configured
+	RolloutStatus string `json:"rolloutStatus,omitempty"`
+}
+
+// newReleaseNotification describes the recorded release; the repo owners are left out if they can't be determined,
+// since the release has succeeded by the time this runs
+func (releaser *Releaser) newReleaseNotification(kudetConfig *kudet_config.KudetConfig, state *releaseState) *releaseNotification {
+	repoOwners, err := getRepoOwners(releaser.repoDirpath, kudetConfig.Ownership)
+	if err != nil {
+		logrus.Warnf("An error occurred determining the repo owners to mention in release notifications; they'll be left out:\n%v", err)
+	}
+	return &releaseNotification{
+		Version:         state.Version,
+		PreviousVersion: state.PreviousVersion,
+		CommitHash:      state.ReleaseCommitHash,
+		Branch:          kudetConfig.ReleaseBranch,
+		RepoOwners:      repoOwners,
+	}
+}
+
+// sendReleaseNotifications tells every webhook about the release; by the time this runs the release is irreversible,
+// so failures are logged rather than returned
+func sendReleaseNotifications(webhookUrls []string, notification *releaseNotification) {
+	if len(webhookUrls) == 0 {
+		return
+	}
+	logrus.Infof("Sending release notifications...")
+	httpClient := &http.Client{Timeout: notificationTimeout}
+	for _, webhookUrl := range webhookUrls {
+		if err := sendReleaseNotification(httpClient, webhookUrl, notification); err != nil {
+			logrus.Warnf("An error occurred notifying webhook '%s' of release '%s'; the release itself succeeded:\n%v", webhookUrl, notification.Version, err)
+		}
+	}
+}
+
+func sendReleaseNotification(httpClient *http.Client, webhookUrl string, notification interface{}) error {
+	notificationBytes, err := json.Marshal(notification)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the release notification")
+	}
+	resp, err := httpClient.Post(webhookUrl, notificationContentType, bytes.NewReader(notificationBytes))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred POSTing the release notification")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return stacktrace.NewError("The webhook responded with unexpected status '%s'", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/progress"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/telemetry"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"io"
+	"os"
+)
+
+// Confirmer is asked a yes/no question, already in the operator's language, to approve an operation like a release
+// before any changes are made, or before they're committed if the release's diff is previewed; returning false aborts
+// the operation
+type Confirmer func(ctx context.Context, question string) (bool, error)
+
+// Releaser cuts releases of a single repo; construct it with NewReleaser
+type Releaser struct {
+	repoDirpath string
+
+	// Token used to authenticate fetches and pushes to the remote
+	token string
+
+	shouldBumpMajorVersion bool
+
+	// The version tracks to release, where 'main' is the main version line; empty releases only the main version line
+	versionTrackNames []string
+
+	// If true, the release goes ahead even if the previous one was cut within the kudet config's minimum release interval
+	isForced bool
+
+	// If true, the release is refused unless CI has passed on the commit being released
+	shouldRequireGreenCi bool
+
+	// If true, release notes that differ from the approved copy only produce a warning
+	isReleaseNotesDiffAcknowledged bool
+
+	// If true, a security advisory is drafted on the forge for the release, with the given severity if it isn't empty
+	isSecurityRelease        bool
+	securityAdvisorySeverity string
+
+	// If true, the release is committed but held locally until its embargo is lifted
+	isEmbargoed bool
+
+	// If set, the release commit and tags are written to this script for a VCS bridge to apply, rather than being
+	// made and pushed directly
+	bridgeScriptFilepath string
+
+	// If set, the release's metadata is written to this directory once it succeeds
+	metadataDirpath string
+
+	// If true, the release's metadata is uploaded as an asset of its forge release once it succeeds
+	shouldUploadMetadata bool
+
+	// If true, the artifacts that the pre-release scripts produced are uploaded as assets of the forge release once it
+	// succeeds; they're collected into the metadata directory
+	shouldUploadArtifacts bool
+
+	// The environments the release is promoted to on top of those promoted to on every release
+	promotedEnvironmentNames []string
+
+	// If true, the environments the release was promoted to are rolled back if its rollout is unhealthy
+	shouldRollbackOnUnhealthy bool
+
+	// If true, the release is rehearsed in a temporary clone of the repo whose origin is a throwaway copy of the real one
+	isSandbox bool
+
+	// If true, the repo's commit hooks aren't run for the release commit, like 'git commit --no-verify'
+	shouldSkipCommitHooks bool
+
+	// When origin is fetched before checking that the release branch is in sync with it
+	fetchPolicy FetchPolicy
+
+	// If true, the release is confirmed once its changes are in the worktree, after the diff that will be committed is
+	// shown, rather than before any changes are made
+	shouldPreviewReleaseDiff bool
+
+	// If nil, no progress is rendered beyond the logs
+	progressTracker *progress.Tracker
+
+	// Records the release's trace for export to an OpenTelemetry collector; nil for rehearsals, which aren't exported
+	telemetryRecorder *telemetry.Recorder
+
+	// If nil, the config is loaded from the repo's .kudet.yml when releasing
+	kudetConfig *kudet_config.KudetConfig
+
+	confirmer Confirmer
+
+	// If set, the operator is walked through the release interactively, in place of the confirmer
+	wizard *releaseWizard
+
+	openRepository RepositoryOpener
+
+	// Mints the tokens used in place of the given one when the kudet config has a GitHub App; nil until it's needed
+	githubAppTokenSource *githubAppTokenSource
+}
+
+// RepositoryOpener opens the repo that's being released, authenticating remote operations with the given token
+type RepositoryOpener func(repoDirpath string, token string) (vcs.Repository, error)
+
+type ReleaserOption func(releaser *Releaser)
+
+// NewReleaser creates a releaser for the git repo rooted at the given directory, authenticating with the given token
+func NewReleaser(repoDirpath string, token string, opts ...ReleaserOption) *Releaser {
+	releaser := &Releaser{
+		repoDirpath:                    repoDirpath,
+		token:                          token,
+		shouldBumpMajorVersion:         false,
+		versionTrackNames:              nil,
+		isForced:                       false,
+		shouldRequireGreenCi:           false,
+		isReleaseNotesDiffAcknowledged: false,
+		isSecurityRelease:              false,
+		securityAdvisorySeverity:       "",
+		isEmbargoed:                    false,
+		bridgeScriptFilepath:           "",
+		metadataDirpath:                "",
+		shouldUploadMetadata:           false,
+		shouldUploadArtifacts:          false,
+		promotedEnvironmentNames:       nil,
+		shouldRollbackOnUnhealthy:      false,
+		isSandbox:                      false,
+		shouldSkipCommitHooks:          false,
+		fetchPolicy:                    FetchIfStale,
+		shouldPreviewReleaseDiff:       false,
+		progressTracker:                nil,
+		telemetryRecorder:              nil,
+		kudetConfig:                    nil,
+		confirmer:                      NewPromptConfirmer(os.Stdin, NoConfirmTimeout),
+		wizard:                         nil,
+		openRepository:                 vcs.OpenRepository,
+	}
+	for _, opt := range opts {
+		opt(releaser)
+	}
+	return releaser
+}
+
+// WithBumpMajorVersion bumps the major version regardless of what the changelog says
+func WithBumpMajorVersion(shouldBumpMajorVersion bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.shouldBumpMajorVersion = shouldBumpMajorVersion
+	}
+}
+
+// WithVersionTracks releases the named version tracks from the kudet config, together or on their own, where 'main'
+// names the main version line; without any, only the main version line is released
+func WithVersionTracks(versionTrackNames []string) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.versionTrackNames = versionTrackNames
+	}
+}
+
+// WithForce releases even if the previous release was cut within the kudet config's minimum release interval, which
+// otherwise refuses the release
+func WithForce(isForced bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.isForced = isForced
+	}
+}
+
+// WithRequireGreenCi refuses to release unless the CI checks named in the config (or all checks, if none are named)
+// have passed on the commit being released
+func WithRequireGreenCi(shouldRequireGreenCi bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.shouldRequireGreenCi = shouldRequireGreenCi
+	}
+}
+
+// WithReleaseNotesDiffAcknowledged releases even if the release notes differ from the repo's approved copy of them
+func WithReleaseNotesDiffAcknowledged(isReleaseNotesDiffAcknowledged bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.isReleaseNotesDiffAcknowledged = isReleaseNotesDiffAcknowledged
+	}
+}
+
+// WithSecurityRelease marks the release as fixing a vulnerability, drafting a GitHub Security Advisory for it; the
+// severity falls back to the one in the kudet config if it's empty
+func WithSecurityRelease(isSecurityRelease bool, severity string) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.isSecurityRelease = isSecurityRelease
+		releaser.securityAdvisorySeverity = severity
+	}
+}
+
+// WithEmbargo fully prepares the release but holds it locally, with its GitHub release as a draft, until LiftEmbargo
+// is called at the coordinated disclosure time
+func WithEmbargo(isEmbargoed bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.isEmbargoed = isEmbargoed
+	}
+}
+
+// WithBridgeScript prepares the release without committing, tagging, or pushing anything, and instead writes those
+// operations to a shell script at the given path; this is for repos mirrored from another VCS like SVN, where the
+// bridge must apply them
+func WithBridgeScript(bridgeScriptFilepath string) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.bridgeScriptFilepath = bridgeScriptFilepath
+	}
+}
+
+// WithMetadataOutput writes a release-metadata.json describing each successful release to the given directory, for
+// supply-chain tooling to pick up
+func WithMetadataOutput(metadataDirpath string) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.metadataDirpath = metadataDirpath
+	}
+}
+
+// WithMetadataUpload attaches the release-metadata.json of each successful release to its forge release, creating the
+// forge release if there isn't one
+func WithMetadataUpload(shouldUploadMetadata bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.shouldUploadMetadata = shouldUploadMetadata
+	}
+}
+
+// WithArtifactUpload attaches the artifacts that the pre-release scripts produced for each successful release to its
+// forge release, creating the forge release if there isn't one; it needs WithMetadataOutput, where they're collected
+func WithArtifactUpload(shouldUploadArtifacts bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.shouldUploadArtifacts = shouldUploadArtifacts
+	}
+}
+
+// WithPromotedEnvironments promotes the release to the named environments from the kudet config once it's pushed, on
+// top of those promoted to on every release
+func WithPromotedEnvironments(environmentNames []string) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.promotedEnvironmentNames = environmentNames
+	}
+}
+
+// WithRollbackOnUnhealthy rolls the environments the release was promoted to back to the previous release if the health
+// check finds its rollout unhealthy
+func WithRollbackOnUnhealthy(shouldRollbackOnUnhealthy bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.shouldRollbackOnUnhealthy = shouldRollbackOnUnhealthy
+	}
+}
+
+// WithSandbox rehearses the whole release, pushes included, in a temporary clone of the repo with a throwaway copy of
+// origin, leaving the repo and its remote untouched; the post-release steps are skipped
+func WithSandbox(isSandbox bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.isSandbox = isSandbox
+	}
+}
+
+// WithCommitHooksSkipped makes the release commit without running the repo's 'pre-commit' and 'commit-msg' git hooks,
+// like 'git commit --no-verify'; the bypass is logged
+func WithCommitHooksSkipped(shouldSkipCommitHooks bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.shouldSkipCommitHooks = shouldSkipCommitHooks
+	}
+}
+
+// WithFetchPolicy overrides when origin is fetched, which by default is unless it was fetched within the kudet
+// config's fetch grace period
+func WithFetchPolicy(fetchPolicy FetchPolicy) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.fetchPolicy = fetchPolicy
+	}
+}
+
+// WithReleaseDiffPreview moves the confirmation to after the pre-release scripts have run and the changelog has been
+// finalized, showing the diff of the release commit first so that the operator can catch a script that clobbered files;
+// declining resets the changes
+func WithReleaseDiffPreview(shouldPreviewReleaseDiff bool) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.shouldPreviewReleaseDiff = shouldPreviewReleaseDiff
+	}
+}
+
+// WithProgressTracker renders each step of the release as it runs, with a summary of how long each took at the end
+func WithProgressTracker(progressTracker *progress.Tracker) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.progressTracker = progressTracker
+	}
+}
+
+// WithKudetConfig uses the given config rather than loading the repo's .kudet.yml
+func WithKudetConfig(kudetConfig *kudet_config.KudetConfig) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.kudetConfig = kudetConfig
+	}
+}
+
+// WithConfirmer replaces the default interactive confirmation, e.g. to release unattended
+func WithConfirmer(confirmer Confirmer) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.confirmer = confirmer
+	}
+}
+
+// WithInteractiveWizard walks the operator through the release on the given input and output, in place of the
+// confirmer: the changelog's unreleased entries are shown and can be edited, the bump is chosen, and the changes are
+// previewed before they're confirmed. Any other confirmation is asked on the same input, by the wizard.
+func WithInteractiveWizard(input io.Reader, out io.Writer) ReleaserOption {
+	return func(releaser *Releaser) {
+		wizard := newReleaseWizard(input, out)
+		releaser.wizard = wizard
+		releaser.confirmer = wizard.confirm
+	}
+}
+
+// WithRepositoryOpener replaces the detection of the repo's version control system, e.g. to release through a custom
+// vcs.Repository implementation
+func WithRepositoryOpener(openRepository RepositoryOpener) ReleaserOption {
+	return func(releaser *Releaser) {
+		releaser.openRepository = openRepository
+	}
+}
+
+// AlwaysConfirm is a Confirmer that approves every operation without asking
+func AlwaysConfirm(ctx context.Context, question string) (bool, error) {
+	return true, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (releaser *Releaser) getKudetConfig() (*kudet_config.KudetConfig, error) {
+	if releaser.kudetConfig != nil {
+		if err := releaser.kudetConfig.Validate(); err != nil {
+			return nil, stacktrace.Propagate(err, "The provided kudet config is invalid")
+		}
+		return releaser.kudetConfig, nil
+	}
+	kudetConfig, err := kudet_config.LoadKudetConfig(releaser.repoDirpath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred loading the kudet config for the repo")
+	}
+	return kudetConfig, nil
+}
@@ -0,0 +1,75 @@
+package releaser
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	codeownersCatchAllPattern = "*"
+	codeownersCommentPrefix   = "#"
+	// GitLab's CODEOWNERS sections, e.g. '[Documentation] @docs-team', which we don't interpret
+	codeownersSectionPrefix = "["
+)
+
+// Where forges look for CODEOWNERS, relative to the repo root, in the order GitHub looks for it
+var codeownersRelFilepaths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+// getRepoOwners gets the configured repo owners, falling back to the catch-all owners in the repo's CODEOWNERS
+func getRepoOwners(repoDirpath string, ownershipConfig kudet_config.OwnershipConfig) ([]string, error) {
+	if len(ownershipConfig.Owners) > 0 {
+		return ownershipConfig.Owners, nil
+	}
+	for _, codeownersRelFilepath := range codeownersRelFilepaths {
+		codeownersFilepath := filepath.Join(repoDirpath, codeownersRelFilepath)
+		codeownersBytes, err := os.ReadFile(codeownersFilepath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, stacktrace.Propagate(err, "An error occurred reading the CODEOWNERS file at '%s'", codeownersFilepath)
+		}
+		return getCatchAllCodeowners(codeownersBytes), nil
+	}
+	return nil, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getCatchAllCodeowners gets the owners of the last '*' rule, since later CODEOWNERS rules take precedence
+func getCatchAllCodeowners(codeownersBytes []byte) []string {
+	var owners []string
+	scanner := bufio.NewScanner(bytes.NewReader(codeownersBytes))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, codeownersCommentPrefix) || strings.HasPrefix(line, codeownersSectionPrefix) {
+			continue
+		}
+		fields := strings.Fields(line)
+		if fields[0] != codeownersCatchAllPattern {
+			continue
+		}
+		owners = []string{}
+		for _, field := range fields[1:] {
+			// Owners can be followed by a trailing comment
+			if strings.HasPrefix(field, codeownersCommentPrefix) {
+				break
+			}
+			owners = append(owners, field)
+		}
+	}
+	return owners
+}
@@ -0,0 +1,20 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCatchAllCodeowners(t *testing.T) {
+	codeowners := `# Everything defaults to the core team
+*       @kurtosis-tech/core
+/docs/  @kurtosis-tech/docs
+
+[Tooling]
+*.go    @kurtosis-tech/tooling
+*       @kurtosis-tech/core @kurtosis-tech/tooling # both review everything now
+`
+	require.Equal(t, []string{"@kurtosis-tech/core", "@kurtosis-tech/tooling"}, getCatchAllCodeowners([]byte(codeowners)))
+	require.Nil(t, getCatchAllCodeowners([]byte("/docs/ @kurtosis-tech/docs\n")))
+}
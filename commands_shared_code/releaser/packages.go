@@ -0,0 +1,452 @@
+package releaser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	npmManifestNameKey    = "name"
+	npmManifestVersionKey = "version"
+
+	goModModuleDirective  = "module"
+	goModRequireDirective = "require"
+	goModuleVersionPrefix = "v"
+
+	packageManifestFileMode = 0644
+)
+
+var (
+	// The dependencies of an npm package that can be on other packages of the repo
+	npmManifestDependencyKeys = []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+
+	// Constraints pinning a version, optionally with a '^', '~', '>=' or '=' operator, are rewritten to the released
+	// version keeping their operator; others, like 'workspace:*' or ranges, are left alone
+	npmDependencyConstraintRegex = regexp.MustCompile(`^(\^|~|>=|=)?` + semverPatternStr + `$`)
+
+	// A requirement in a go.mod, either on a 'require' line or inside a 'require (...)' block
+	goModRequirementRegex = regexp.MustCompile(`^(\s*(?:require\s+)?)(\S+)(\s+)(v\S+)(.*)$`)
+)
+
+// packageManifest is what releasing a package of a monorepo needs from its manifest
+type packageManifest struct {
+	config kudet_config.PackageConfig
+
+	// The name that the repo's other packages depend on it by, e.g. '@acme/core' or 'github.com/acme/sdk'
+	name string
+
+	// The names of the packages it depends on, whether they're in the repo or not
+	dependencyNames []string
+}
+
+// releasePackages releases the repo's packages at the version in the order of their dependencies on each other: each
+// package's manifest gets the version, along with its dependencies on the packages released before it, then its release
+// command is run, so that a package is never released before a package it depends on
+func releasePackages(ctx context.Context, repoDirpath string, packageConfigs []kudet_config.PackageConfig, shell []string, releaseVersion string) error {
+	manifests, err := loadPackageManifests(repoDirpath, packageConfigs)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the packages' manifests")
+	}
+	orderedManifests, err := getPackageReleaseOrder(manifests)
+	if err != nil {
+		return stacktrace.Propagate(err, "The packages can't be released in the order of their dependencies")
+	}
+	packageNames := map[string]bool{}
+	for _, manifest := range manifests {
+		packageNames[manifest.name] = true
+	}
+
+	for _, manifest := range orderedManifests {
+		logrus.Infof("Releasing package '%s'...", manifest.name)
+		if err := updatePackageManifest(repoDirpath, manifest.config.ManifestFilepath, releaseVersion, packageNames); err != nil {
+			return stacktrace.Propagate(err, "An error occurred updating the manifest of package '%s' to version '%s'", manifest.name, releaseVersion)
+		}
+		if manifest.config.ReleaseCommand == "" {
+			continue
+		}
+		packageDirpath := filepath.Join(repoDirpath, filepath.Dir(manifest.config.ManifestFilepath))
+		releaseCommand := &preReleaseScript{inlineCommandLines: []string{manifest.config.ReleaseCommand}}
+		if err := runPreReleaseScript(ctx, releaseCommand, packageDirpath, shell, releaseVersion, nil); err != nil {
+			return stacktrace.Propagate(err, "An error occurred running the release command of package '%s'", manifest.name)
+		}
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func loadPackageManifests(repoDirpath string, packageConfigs []kudet_config.PackageConfig) ([]*packageManifest, error) {
+	manifests := []*packageManifest{}
+	manifestFilepathsByName := map[string]string{}
+	for _, packageConfig := range packageConfigs {
+		manifestFilepath := filepath.Join(repoDirpath, packageConfig.ManifestFilepath)
+		manifestContents, err := os.ReadFile(manifestFilepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading package manifest '%s'", manifestFilepath)
+		}
+		var name string
+		var dependencyNames []string
+		switch path.Base(filepath.ToSlash(packageConfig.ManifestFilepath)) {
+		case kudet_config.NpmPackageManifestFilename:
+			name, dependencyNames, err = parseNpmManifest(manifestContents)
+		case kudet_config.GoPackageManifestFilename:
+			name, dependencyNames, err = parseGoModManifest(manifestContents)
+		default:
+			err = stacktrace.NewError("Only '%s' package manifests are supported", kudet_config.PackageManifestFilenames)
+		}
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred parsing package manifest '%s'", packageConfig.ManifestFilepath)
+		}
+		if otherManifestFilepath, found := manifestFilepathsByName[name]; found {
+			return nil, stacktrace.NewError("Package manifests '%s' and '%s' are both for package '%s'", otherManifestFilepath, packageConfig.ManifestFilepath, name)
+		}
+		manifestFilepathsByName[name] = packageConfig.ManifestFilepath
+		manifests = append(manifests, &packageManifest{
+			config:          packageConfig,
+			name:            name,
+			dependencyNames: dependencyNames,
+		})
+	}
+	return manifests, nil
+}
+
+// getPackageReleaseOrder orders the packages so that each comes after the packages of the repo that it depends on,
+// keeping the configured order otherwise; packages that depend on each other in a cycle can't be ordered
+func getPackageReleaseOrder(manifests []*packageManifest) ([]*packageManifest, error) {
+	isPackage := map[string]bool{}
+	for _, manifest := range manifests {
+		isPackage[manifest.name] = true
+	}
+	isReleased := map[string]bool{}
+	orderedManifests := []*packageManifest{}
+	for len(orderedManifests) < len(manifests) {
+		// The first package in the configured order whose dependencies have all been released goes next
+		var nextManifest *packageManifest
+		for _, manifest := range manifests {
+			if isReleased[manifest.name] {
+				continue
+			}
+			isReady := true
+			for _, dependencyName := range manifest.dependencyNames {
+				if isPackage[dependencyName] && !isReleased[dependencyName] && dependencyName != manifest.name {
+					isReady = false
+					break
+				}
+			}
+			if isReady {
+				nextManifest = manifest
+				break
+			}
+		}
+		if nextManifest == nil {
+			cyclicPackageNames := []string{}
+			for _, manifest := range manifests {
+				if !isReleased[manifest.name] {
+					cyclicPackageNames = append(cyclicPackageNames, manifest.name)
+				}
+			}
+			return nil, stacktrace.NewError("Packages '%s' can't be ordered, as some of them depend on each other in a cycle", strings.Join(cyclicPackageNames, "', '"))
+		}
+		isReleased[nextManifest.name] = true
+		orderedManifests = append(orderedManifests, nextManifest)
+	}
+	return orderedManifests, nil
+}
+
+// updatePackageManifest rewrites the package's version, for manifests that have one, and its dependencies on the given
+// packages to the released version, leaving the rest of the manifest as it's formatted
+func updatePackageManifest(repoDirpath string, manifestRelFilepath string, releaseVersion string, packageNames map[string]bool) error {
+	manifestFilepath := filepath.Join(repoDirpath, manifestRelFilepath)
+	manifestContents, err := os.ReadFile(manifestFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading package manifest '%s'", manifestFilepath)
+	}
+	var updatedManifestContents []byte
+	switch path.Base(filepath.ToSlash(manifestRelFilepath)) {
+	case kudet_config.NpmPackageManifestFilename:
+		updatedManifestContents, err = updateNpmManifest(manifestContents, releaseVersion, packageNames)
+	case kudet_config.GoPackageManifestFilename:
+		updatedManifestContents = updateGoModManifest(manifestContents, releaseVersion, packageNames)
+	default:
+		err = stacktrace.NewError("Only '%s' package manifests are supported", kudet_config.PackageManifestFilenames)
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred updating package manifest '%s'", manifestRelFilepath)
+	}
+	if err := os.WriteFile(manifestFilepath, updatedManifestContents, packageManifestFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing package manifest '%s'", manifestFilepath)
+	}
+	return nil
+}
+
+func parseNpmManifest(manifestContents []byte) (string, []string, error) {
+	manifest := map[string]json.RawMessage{}
+	if err := json.Unmarshal(manifestContents, &manifest); err != nil {
+		return "", nil, stacktrace.Propagate(err, "An error occurred parsing the manifest's JSON")
+	}
+	name := ""
+	if rawName, found := manifest[npmManifestNameKey]; found {
+		if err := json.Unmarshal(rawName, &name); err != nil {
+			return "", nil, stacktrace.Propagate(err, "The manifest's '%s' isn't a string", npmManifestNameKey)
+		}
+	}
+	if name == "" {
+		return "", nil, stacktrace.NewError("The manifest has no '%s'", npmManifestNameKey)
+	}
+	isDependency := map[string]bool{}
+	for _, dependencyKey := range npmManifestDependencyKeys {
+		rawDependencies, found := manifest[dependencyKey]
+		if !found {
+			continue
+		}
+		dependencies := map[string]string{}
+		if err := json.Unmarshal(rawDependencies, &dependencies); err != nil {
+			return "", nil, stacktrace.Propagate(err, "The manifest's '%s' isn't a map of package names to constraints", dependencyKey)
+		}
+		for dependencyName := range dependencies {
+			isDependency[dependencyName] = true
+		}
+	}
+	dependencyNames := []string{}
+	for dependencyName := range isDependency {
+		dependencyNames = append(dependencyNames, dependencyName)
+	}
+	sort.Strings(dependencyNames)
+	return name, dependencyNames, nil
+}
+
+// jsonStringReplacement replaces the quoted JSON string between the offsets with a value
+type jsonStringReplacement struct {
+	start int
+	end   int
+	value string
+}
+
+// updateNpmManifest replaces the string values of the manifest's version and of its constraints on the packages in
+// place, found by walking its JSON tokens, so that the manifest's formatting and key order are kept
+func updateNpmManifest(manifestContents []byte, releaseVersion string, packageNames map[string]bool) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(manifestContents))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, stacktrace.NewError("The manifest isn't a JSON object")
+	}
+	replacements := []jsonStringReplacement{}
+	for decoder.More() {
+		keyToken, err := decoder.Token()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading the manifest's JSON")
+		}
+		key, _ := keyToken.(string)
+		if key == npmManifestVersionKey {
+			replacement, isString, err := readJsonStringValue(decoder, manifestContents)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred reading the manifest's '%s'", npmManifestVersionKey)
+			}
+			if isString {
+				replacement.value = releaseVersion
+				replacements = append(replacements, replacement)
+			}
+			continue
+		}
+		if !isOneOfStrings(key, npmManifestDependencyKeys) {
+			if err := skipJsonValue(decoder); err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred reading the manifest's '%s'", key)
+			}
+			continue
+		}
+		dependencyReplacements, err := getNpmDependencyReplacements(decoder, manifestContents, releaseVersion, packageNames)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading the manifest's '%s'", key)
+		}
+		replacements = append(replacements, dependencyReplacements...)
+	}
+
+	updatedManifestContents := []byte{}
+	previousEnd := 0
+	for _, replacement := range replacements {
+		quotedValue, err := json.Marshal(replacement.value)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred quoting '%s'", replacement.value)
+		}
+		updatedManifestContents = append(updatedManifestContents, manifestContents[previousEnd:replacement.start]...)
+		updatedManifestContents = append(updatedManifestContents, quotedValue...)
+		previousEnd = replacement.end
+	}
+	return append(updatedManifestContents, manifestContents[previousEnd:]...), nil
+}
+
+// getNpmDependencyReplacements reads a map of dependencies to their constraints, returning the replacements of the
+// constraints on the packages that pin a version with the released version
+func getNpmDependencyReplacements(decoder *json.Decoder, manifestContents []byte, releaseVersion string, packageNames map[string]bool) ([]jsonStringReplacement, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, skipJsonValueFrom(decoder, token)
+	}
+	replacements := []jsonStringReplacement{}
+	for decoder.More() {
+		dependencyToken, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		dependencyName, _ := dependencyToken.(string)
+		replacement, isString, err := readJsonStringValue(decoder, manifestContents)
+		if err != nil {
+			return nil, err
+		}
+		if !isString || !packageNames[dependencyName] {
+			continue
+		}
+		match := npmDependencyConstraintRegex.FindStringSubmatch(replacement.value)
+		if match == nil {
+			logrus.Debugf("Leaving constraint '%s' on package '%s' alone, as it doesn't pin a version", replacement.value, dependencyName)
+			continue
+		}
+		replacement.value = match[1] + releaseVersion
+		replacements = append(replacements, replacement)
+	}
+	// The map's closing brace
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return replacements, nil
+}
+
+// readJsonStringValue reads the next value, returning it along with where its quoted form is if it's a string
+func readJsonStringValue(decoder *json.Decoder, contents []byte) (jsonStringReplacement, bool, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return jsonStringReplacement{}, false, err
+	}
+	value, isString := token.(string)
+	if !isString {
+		return jsonStringReplacement{}, false, skipJsonValueFrom(decoder, token)
+	}
+	// Versions and constraints have no escaped quotes, so the opening quote is the last one before the closing one
+	end := int(decoder.InputOffset())
+	start := bytes.LastIndexByte(contents[:end-1], '"')
+	return jsonStringReplacement{start: start, end: end, value: value}, true, nil
+}
+
+// skipJsonValue consumes the next value
+func skipJsonValue(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	return skipJsonValueFrom(decoder, token)
+}
+
+// skipJsonValueFrom consumes the rest of the value that the token starts, if it's an object or array
+func skipJsonValueFrom(decoder *json.Decoder, token json.Token) error {
+	if token != json.Delim('{') && token != json.Delim('[') {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+func parseGoModManifest(manifestContents []byte) (string, []string, error) {
+	name := ""
+	dependencyNames := []string{}
+	forEachGoModLine(manifestContents, func(line string, isInRequireBlock bool) string {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == goModModuleDirective {
+			name = strings.Trim(fields[1], `"`)
+		}
+		if match := getGoModRequirementMatch(line, isInRequireBlock); match != nil {
+			dependencyNames = append(dependencyNames, match[2])
+		}
+		return line
+	})
+	if name == "" {
+		return "", nil, stacktrace.NewError("The go.mod has no '%s' directive", goModModuleDirective)
+	}
+	return name, dependencyNames, nil
+}
+
+// updateGoModManifest rewrites the go.mod's requirements of the packages to the released version; go.mod files don't
+// have a version of their own
+func updateGoModManifest(manifestContents []byte, releaseVersion string, packageNames map[string]bool) []byte {
+	return forEachGoModLine(manifestContents, func(line string, isInRequireBlock bool) string {
+		match := getGoModRequirementMatch(line, isInRequireBlock)
+		if match == nil || !packageNames[match[2]] {
+			return line
+		}
+		return fmt.Sprintf("%s%s%s%s%s%s", match[1], match[2], match[3], goModuleVersionPrefix, releaseVersion, match[5])
+	})
+}
+
+// forEachGoModLine calls the function with each line of the go.mod, and whether it's inside a 'require (...)' block,
+// returning the go.mod with each line replaced by what the function returns
+func forEachGoModLine(manifestContents []byte, processLine func(line string, isInRequireBlock bool) string) []byte {
+	updatedLines := []string{}
+	isInRequireBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(manifestContents))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmedLine := strings.TrimSpace(line)
+		switch {
+		case isInRequireBlock && strings.HasPrefix(trimmedLine, ")"):
+			isInRequireBlock = false
+		case strings.HasPrefix(trimmedLine, goModRequireDirective) && strings.HasSuffix(trimmedLine, "("):
+			isInRequireBlock = true
+		default:
+			line = processLine(line, isInRequireBlock)
+		}
+		updatedLines = append(updatedLines, line)
+	}
+	updatedManifest := strings.Join(updatedLines, "\n")
+	if bytes.HasSuffix(manifestContents, []byte("\n")) {
+		updatedManifest += "\n"
+	}
+	return []byte(updatedManifest)
+}
+
+// getGoModRequirementMatch returns the requirement regex's match of the line, whose second group is the required
+// module and fourth group its version, if it's a requirement
+func getGoModRequirementMatch(line string, isInRequireBlock bool) []string {
+	if !isInRequireBlock && !strings.HasPrefix(strings.TrimSpace(line), goModRequireDirective+" ") {
+		return nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "//") {
+		return nil
+	}
+	return goModRequirementRegex.FindStringSubmatch(line)
+}
+
+func isOneOfStrings(value string, allowedValues []string) bool {
+	for _, allowedValue := range allowedValues {
+		if value == allowedValue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	coreTestManifest = `{
+  "name": "@acme/core",
+  "version": "1.2.0",
+  "dependencies": {
+    "left-pad": "^1.3.0"
+  }
+}
+`
+	uiTestManifest = `{
+  "name": "@acme/ui",
+  "scripts": {"build": "tsc", "version": "echo"},
+  "version": "1.2.0",
+  "peerDependencies": {"react": "^18.0.0", "@acme/core": "^1.2.0"},
+  "devDependencies": {"@acme/testing": "workspace:*"}
+}
+`
+	sdkTestGoMod = `module github.com/acme/sdk
+
+go 1.18
+
+require github.com/acme/proto v1.2.0
+
+require (
+	github.com/sirupsen/logrus v1.8.1
+	github.com/acme/api v1.2.0 // indirect
+)
+`
+)
+
+func TestGetPackageReleaseOrder(t *testing.T) {
+	manifests := []*packageManifest{
+		{name: "app", dependencyNames: []string{"ui", "core", "react"}},
+		{name: "ui", dependencyNames: []string{"core"}},
+		{name: "docs"},
+		{name: "core", dependencyNames: []string{"core"}},
+	}
+	orderedManifests, err := getPackageReleaseOrder(manifests)
+	require.NoError(t, err)
+	orderedNames := []string{}
+	for _, manifest := range orderedManifests {
+		orderedNames = append(orderedNames, manifest.name)
+	}
+	require.Equal(t, []string{"docs", "core", "ui", "app"}, orderedNames)
+
+	manifests[3].dependencyNames = []string{"app"}
+	_, err = getPackageReleaseOrder(manifests)
+	require.ErrorContains(t, err, "'app', 'ui', 'core'")
+}
+
+func TestUpdateNpmManifest(t *testing.T) {
+	updatedManifest, err := updateNpmManifest([]byte(uiTestManifest), "1.3.0", map[string]bool{"@acme/core": true, "@acme/testing": true})
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "name": "@acme/ui",
+  "scripts": {"build": "tsc", "version": "echo"},
+  "version": "1.3.0",
+  "peerDependencies": {"react": "^18.0.0", "@acme/core": "^1.3.0"},
+  "devDependencies": {"@acme/testing": "workspace:*"}
+}
+`, string(updatedManifest))
+}
+
+func TestUpdateGoModManifest(t *testing.T) {
+	name, dependencyNames, err := parseGoModManifest([]byte(sdkTestGoMod))
+	require.NoError(t, err)
+	require.Equal(t, "github.com/acme/sdk", name)
+	require.Equal(t, []string{"github.com/acme/proto", "github.com/sirupsen/logrus", "github.com/acme/api"}, dependencyNames)
+
+	updatedGoMod := updateGoModManifest([]byte(sdkTestGoMod), "1.3.0", map[string]bool{"github.com/acme/proto": true, "github.com/acme/api": true})
+	require.Equal(t, `module github.com/acme/sdk
+
+go 1.18
+
+require github.com/acme/proto v1.3.0
+
+require (
+	github.com/sirupsen/logrus v1.8.1
+	github.com/acme/api v1.3.0 // indirect
+)
+`, string(updatedGoMod))
+}
+
+func TestReleasePackages(t *testing.T) {
+	repoDirpath := t.TempDir()
+	for relFilepath, contents := range map[string]string{
+		"packages/ui/package.json":   uiTestManifest,
+		"packages/core/package.json": coreTestManifest,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDirpath, filepath.Dir(relFilepath)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, relFilepath), []byte(contents), 0644))
+	}
+	releaseLogFilepath := filepath.Join(t.TempDir(), "released.log")
+	// Each package logs its name as it's released, seeing the manifests of the packages released before it
+	releaseCommand := `grep '^  "version"' package.json | sed "s|^ *||; s|,$||" | sed "s|^|$(basename "$PWD") $KUDET_RELEASE_VERSION |" >> ` + releaseLogFilepath
+	packageConfigs := []kudet_config.PackageConfig{
+		{ManifestFilepath: "packages/ui/package.json", ReleaseCommand: releaseCommand},
+		{ManifestFilepath: "packages/core/package.json", ReleaseCommand: releaseCommand},
+	}
+
+	require.NoError(t, releasePackages(context.Background(), repoDirpath, packageConfigs, []string{"sh", "-c"}, "1.3.0"))
+	releaseLog, err := os.ReadFile(releaseLogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, "core 1.3.0 \"version\": \"1.3.0\"\nui 1.3.0 \"version\": \"1.3.0\"\n", string(releaseLog))
+	uiManifest, err := os.ReadFile(filepath.Join(repoDirpath, "packages/ui/package.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(uiManifest), `"@acme/core": "^1.3.0"`)
+}
@@ -0,0 +1,201 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"strings"
+)
+
+const (
+	DotGraphFormat     = "dot"
+	MermaidGraphFormat = "mermaid"
+	GraphFormats       = DotGraphFormat + "," + MermaidGraphFormat
+)
+
+type pipelineNodeKind int
+
+const (
+	stepPipelineNode pipelineNodeKind = iota
+	gatePipelineNode
+	hookPipelineNode
+)
+
+type pipelineNode struct {
+	id    string
+	label string
+	kind  pipelineNodeKind
+}
+
+// pipelineEdge leads from a node to one that runs after it; edges to hooks are drawn dashed, as hooks hang off their
+// step rather than following it
+type pipelineEdge struct {
+	fromId string
+	toId   string
+}
+
+type pipelineGraph struct {
+	nodes []pipelineNode
+	edges []pipelineEdge
+}
+
+// RenderPipelineGraph renders the release steps that 'kudet release' goes through in the given repo as a graph in the
+// given format, with the gates that can refuse the release and the hooks (WASM gates, pre-release scripts, git hooks
+// and webhooks) hanging off the steps that run them
+func RenderPipelineGraph(repoDirpath string, kudetConfig *kudet_config.KudetConfig, format string) (string, error) {
+	preReleaseScripts, err := getPreReleaseScripts(repoDirpath, kudetConfig.PreReleaseScriptsFilepath)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred getting the pre release scripts of the repo")
+	}
+	graph, err := getPipelineGraph(kudetConfig, preReleaseScripts)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred building the release pipeline graph")
+	}
+	switch format {
+	case DotGraphFormat:
+		return renderDotGraph(graph), nil
+	case MermaidGraphFormat:
+		return renderMermaidGraph(graph), nil
+	default:
+		return "", stacktrace.NewError("Graph format '%s' must be one of '%s'", format, GraphFormats)
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getPipelineGraph(kudetConfig *kudet_config.KudetConfig, preReleaseScripts []*preReleaseScript) (*pipelineGraph, error) {
+	graph := &pipelineGraph{}
+	previousStepId := ""
+	for stepIdx, step := range getReleaseSteps(kudetConfig, preReleaseScripts) {
+		stepId := fmt.Sprintf("step%d", stepIdx+1)
+		kind := stepPipelineNode
+		if step.isGate {
+			kind = gatePipelineNode
+		}
+		graph.nodes = append(graph.nodes, pipelineNode{id: stepId, label: fmt.Sprintf("%d. %s", stepIdx+1, step.title), kind: kind})
+		if previousStepId != "" {
+			graph.edges = append(graph.edges, pipelineEdge{fromId: previousStepId, toId: stepId})
+		}
+		previousStepId = stepId
+
+		switch step.title {
+		case releasePolicyStepTitle:
+			for wasmGateIdx, wasmGatePath := range kudetConfig.Policy.WasmGates {
+				addHook(graph, stepId, fmt.Sprintf("wasmGate%d", wasmGateIdx+1), fmt.Sprintf("WASM gate: %s", wasmGatePath))
+			}
+		case preReleaseScriptsStepTitle:
+			if err := addPreReleaseScriptHooks(graph, stepId, kudetConfig, preReleaseScripts); err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred adding the pre release scripts to the graph")
+			}
+		case releaseCommitStepTitle:
+			addHook(graph, stepId, "commitHooks", "git hooks: pre-commit, commit-msg")
+		case notificationsStepTitle:
+			for webhookIdx, webhookHost := range getWebhookHosts(kudetConfig.Notifications.WebhookUrls) {
+				addHook(graph, stepId, fmt.Sprintf("webhook%d", webhookIdx+1), fmt.Sprintf("webhook: %s", webhookHost))
+			}
+		}
+	}
+	return graph, nil
+}
+
+// addPreReleaseScriptHooks adds the pre-release scripts in the order they run: one after the other, or when they run
+// in parallel, each one after the scripts it depends on
+func addPreReleaseScriptHooks(graph *pipelineGraph, stepId string, kudetConfig *kudet_config.KudetConfig, preReleaseScripts []*preReleaseScript) error {
+	// Building the groups checks the dependencies, so that a misconfigured schedule shows up here rather than on release
+	if _, err := getPreReleaseScriptGroups(preReleaseScripts, kudetConfig.PreReleaseScriptSchedule, kudetConfig.PreReleaseScriptsParallelism); err != nil {
+		return stacktrace.Propagate(err, "The pre release scripts' schedule is misconfigured")
+	}
+	isParallel := kudetConfig.PreReleaseScriptsParallelism > 1
+	scriptIds := map[string]string{}
+	previousScriptId := stepId
+	for scriptIdx, script := range preReleaseScripts {
+		scriptId := fmt.Sprintf("script%d", scriptIdx+1)
+		label := fmt.Sprintf("inline command (line %d)", script.lineIdx+1)
+		scriptSchedule := kudet_config.PreReleaseScriptScheduleConfig{}
+		if !script.isInline() {
+			label = script.relFilepath
+			scriptSchedule = kudetConfig.PreReleaseScriptSchedule[script.relFilepath]
+			scriptIds[script.relFilepath] = scriptId
+		}
+		if len(scriptSchedule.OnlyIfChanged) > 0 {
+			label = fmt.Sprintf("%s (only if changed: %s)", label, strings.Join(scriptSchedule.OnlyIfChanged, ", "))
+		}
+		graph.nodes = append(graph.nodes, pipelineNode{id: scriptId, label: label, kind: hookPipelineNode})
+
+		if !isParallel {
+			graph.edges = append(graph.edges, pipelineEdge{fromId: previousScriptId, toId: scriptId})
+			previousScriptId = scriptId
+			continue
+		}
+		if len(scriptSchedule.DependsOn) == 0 {
+			graph.edges = append(graph.edges, pipelineEdge{fromId: stepId, toId: scriptId})
+			continue
+		}
+		for _, dependencyRelFilepath := range scriptSchedule.DependsOn {
+			graph.edges = append(graph.edges, pipelineEdge{fromId: scriptIds[dependencyRelFilepath], toId: scriptId})
+		}
+	}
+	return nil
+}
+
+func addHook(graph *pipelineGraph, stepId string, hookId string, label string) {
+	graph.nodes = append(graph.nodes, pipelineNode{id: hookId, label: label, kind: hookPipelineNode})
+	graph.edges = append(graph.edges, pipelineEdge{fromId: stepId, toId: hookId})
+}
+
+func renderDotGraph(graph *pipelineGraph) string {
+	nodeKinds := map[string]pipelineNodeKind{}
+	builder := &strings.Builder{}
+	builder.WriteString("digraph release {\n")
+	builder.WriteString("  rankdir=TB;\n")
+	for _, node := range graph.nodes {
+		nodeKinds[node.id] = node.kind
+		shape := "box"
+		switch node.kind {
+		case gatePipelineNode:
+			shape = "diamond"
+		case hookPipelineNode:
+			shape = "ellipse"
+		}
+		label := strings.ReplaceAll(strings.ReplaceAll(node.label, `\`, `\\`), `"`, `\"`)
+		builder.WriteString(fmt.Sprintf("  %s [label=\"%s\", shape=%s];\n", node.id, label, shape))
+	}
+	for _, edge := range graph.edges {
+		if nodeKinds[edge.toId] == hookPipelineNode {
+			builder.WriteString(fmt.Sprintf("  %s -> %s [style=dashed];\n", edge.fromId, edge.toId))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("  %s -> %s;\n", edge.fromId, edge.toId))
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+func renderMermaidGraph(graph *pipelineGraph) string {
+	nodeKinds := map[string]pipelineNodeKind{}
+	builder := &strings.Builder{}
+	builder.WriteString("flowchart TD\n")
+	for _, node := range graph.nodes {
+		nodeKinds[node.id] = node.kind
+		label := strings.ReplaceAll(node.label, `"`, "#quot;")
+		switch node.kind {
+		case gatePipelineNode:
+			builder.WriteString(fmt.Sprintf("  %s{\"%s\"}\n", node.id, label))
+		case hookPipelineNode:
+			builder.WriteString(fmt.Sprintf("  %s([\"%s\"])\n", node.id, label))
+		default:
+			builder.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", node.id, label))
+		}
+	}
+	for _, edge := range graph.edges {
+		if nodeKinds[edge.toId] == hookPipelineNode {
+			builder.WriteString(fmt.Sprintf("  %s -.-> %s\n", edge.fromId, edge.toId))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("  %s --> %s\n", edge.fromId, edge.toId))
+	}
+	return builder.String()
+}
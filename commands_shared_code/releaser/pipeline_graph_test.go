@@ -0,0 +1,46 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPipelineGraph_PreReleaseScripts(t *testing.T) {
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.PreReleaseScriptsParallelism = 2
+	kudetConfig.PreReleaseScriptSchedule = map[string]kudet_config.PreReleaseScriptScheduleConfig{
+		"scripts/build.sh": {DependsOn: []string{"scripts/spec.sh"}, OnlyIfChanged: []string{"api/**"}},
+	}
+	scripts := []*preReleaseScript{{relFilepath: "scripts/spec.sh"}, {relFilepath: "scripts/build.sh", lineIdx: 1}}
+
+	graph, err := getPipelineGraph(kudetConfig, scripts)
+	require.NoError(t, err)
+	require.Contains(t, graph.nodes, pipelineNode{id: "script2", label: "scripts/build.sh (only if changed: api/**)", kind: hookPipelineNode})
+	require.Contains(t, graph.edges, pipelineEdge{fromId: "script1", toId: "script2"})
+	require.NotContains(t, graph.edges, pipelineEdge{fromId: "step9", toId: "script2"})
+
+	kudetConfig.PreReleaseScriptSchedule["scripts/spec.sh"] = kudet_config.PreReleaseScriptScheduleConfig{DependsOn: []string{"scripts/build.sh"}}
+	_, err = getPipelineGraph(kudetConfig, scripts)
+	require.ErrorContains(t, err, "isn't listed before it")
+}
+
+func TestRenderMermaidGraph(t *testing.T) {
+	graph := &pipelineGraph{
+		nodes: []pipelineNode{
+			{id: "step1", label: "1. Checks", kind: gatePipelineNode},
+			{id: "step2", label: "2. Commit", kind: stepPipelineNode},
+			{id: "commitHooks", label: `git hooks "pre-commit"`, kind: hookPipelineNode},
+		},
+		edges: []pipelineEdge{{fromId: "step1", toId: "step2"}, {fromId: "step2", toId: "commitHooks"}},
+	}
+	require.Equal(t, `flowchart TD
+  step1{"1. Checks"}
+  step2["2. Commit"]
+  commitHooks(["git hooks #quot;pre-commit#quot;"])
+  step1 --> step2
+  step2 -.-> commitHooks
+`, renderMermaidGraph(graph))
+	require.Contains(t, renderDotGraph(graph), `commitHooks [label="git hooks \"pre-commit\"", shape=ellipse];`)
+}
@@ -0,0 +1,261 @@
+package releaser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	// Lines of the pre release scripts file starting with this are inline commands rather than script paths
+	inlineCommandPrefix = "$ "
+
+	// Inline commands have no arguments to get the version from, so every pre release script gets it in this too
+	releaseVersionEnvVar = "KUDET_RELEASE_VERSION"
+
+	// Stands in for the hash of a modified file that has been deleted
+	deletedFileHash = "deleted"
+
+	// A script's line can end with the checksum of the script's contents, e.g. 'scripts/bump.sh sha256:<hex>'
+	scriptChecksumPrefix = "sha256:"
+
+	preReleaseScriptsFileMode = 0644
+
+	windowsGoos = "windows"
+)
+
+// The interpreters of the scripts that Windows can't execute by themselves, keyed by their extension; elsewhere,
+// scripts are executed directly except for PowerShell ones, which are run with 'pwsh'. WASM modules are run with
+// 'wasmtime' everywhere.
+var windowsScriptInterpreters = map[string][]string{
+	".ps1": {"powershell", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File"},
+	".bat": {"cmd", "/C"},
+	".cmd": {"cmd", "/C"},
+	".sh":  {"sh"},
+}
+var powershellCoreInterpreter = []string{"pwsh", "-NoProfile", "-NonInteractive", "-File"}
+
+// preReleaseScript is one entry of the pre release scripts file: either a script in the repo, or an inline command that
+// gets run with the configured shell
+type preReleaseScript struct {
+	// Path of the script, relative to the repo root; empty for inline commands
+	relFilepath string
+
+	// The hex SHA-256 checksum that the script's contents must have, if it's pinned
+	pinnedChecksum string
+
+	// The index of the script's line in the pre release scripts file
+	lineIdx int
+
+	// The lines of the inline command, with their indentation removed
+	inlineCommandLines []string
+}
+
+// getPreReleaseScripts returns the scripts listed in the pre release scripts file, in order
+func getPreReleaseScripts(repoDirpath string, preReleaseScriptsRelFilepath string) ([]*preReleaseScript, error) {
+	preReleaseScriptsFilepath := filepath.Join(repoDirpath, preReleaseScriptsRelFilepath)
+	preReleaseScriptsFile, err := os.ReadFile(preReleaseScriptsFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred attempting to open file at provided path. Are you sure '%s' exists?", preReleaseScriptsFilepath)
+	}
+	return parsePreReleaseScripts(strings.Split(string(preReleaseScriptsFile), "\n")), nil
+}
+
+// PinPreReleaseScripts records the current checksum of each script in the repo's pre release scripts file, so that
+// releases refuse to run scripts that have changed since; the paths of the pinned scripts are returned
+func PinPreReleaseScripts(repoDirpath string, preReleaseScriptsRelFilepath string) ([]string, error) {
+	preReleaseScriptsFilepath := filepath.Join(repoDirpath, preReleaseScriptsRelFilepath)
+	preReleaseScriptsFile, err := os.ReadFile(preReleaseScriptsFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the pre release scripts file at '%s'", preReleaseScriptsFilepath)
+	}
+	lines := strings.Split(string(preReleaseScriptsFile), "\n")
+
+	pinnedScriptRelFilepaths := []string{}
+	for _, script := range parsePreReleaseScripts(lines) {
+		if script.isInline() {
+			// Inline commands are in the pre release scripts file itself, so there's nothing else to pin
+			continue
+		}
+		checksum, err := getScriptChecksum(repoDirpath, script.relFilepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the checksum of pre release script '%s'", script.relFilepath)
+		}
+		// Lines ending in CRLF keep doing so, so that the file isn't left with mixed line endings
+		lineEnding := ""
+		if strings.HasSuffix(lines[script.lineIdx], "\r") {
+			lineEnding = "\r"
+		}
+		lines[script.lineIdx] = fmt.Sprintf("%s %s%s%s", script.relFilepath, scriptChecksumPrefix, checksum, lineEnding)
+		pinnedScriptRelFilepaths = append(pinnedScriptRelFilepaths, script.relFilepath)
+	}
+	if err := os.WriteFile(preReleaseScriptsFilepath, []byte(strings.Join(lines, "\n")), preReleaseScriptsFileMode); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred writing the pinned checksums to '%s'", preReleaseScriptsFilepath)
+	}
+	return pinnedScriptRelFilepaths, nil
+}
+
+func (script *preReleaseScript) isInline() bool {
+	return len(script.inlineCommandLines) > 0
+}
+
+func (script *preReleaseScript) getInlineCommand() string {
+	return strings.Join(script.inlineCommandLines, "\n")
+}
+
+// getCmd returns the command that runs the script from the repo root: scripts get the version as their only argument,
+// while inline commands are passed to the shell as its last argument
+func (script *preReleaseScript) getCmd(ctx context.Context, repoDirpath string, shell []string, releaseVersion string) *exec.Cmd {
+	if script.isInline() {
+		shellArgs := append(append([]string{}, shell[1:]...), script.getInlineCommand())
+		return exec.CommandContext(ctx, shell[0], shellArgs...)
+	}
+	scriptFilepath := filepath.Join(repoDirpath, script.relFilepath)
+	interpreter := getScriptInterpreter(script.relFilepath, runtime.GOOS)
+	if len(interpreter) == 0 {
+		return exec.CommandContext(ctx, scriptFilepath, releaseVersion)
+	}
+	interpreterArgs := append(append([]string{}, interpreter[1:]...), scriptFilepath, releaseVersion)
+	return exec.CommandContext(ctx, interpreter[0], interpreterArgs...)
+}
+
+// getScriptInterpreter returns the command, and its arguments, that runs the script on the given OS when it can't be
+// executed by itself; nil means that it can be
+func getScriptInterpreter(relFilepath string, goos string) []string {
+	extension := strings.ToLower(filepath.Ext(relFilepath))
+	if extension == wasmModuleExtension {
+		return wasmScriptInterpreter
+	}
+	if goos == windowsGoos {
+		return windowsScriptInterpreters[extension]
+	}
+	if extension == ".ps1" {
+		return powershellCoreInterpreter
+	}
+	return nil
+}
+
+// getDescription describes the script for errors and the runbook
+func (script *preReleaseScript) getDescription(shell []string, releaseVersion string) string {
+	if script.isInline() {
+		return fmt.Sprintf("inline command '%s' run with '%s'", script.getInlineCommand(), strings.Join(shell, " "))
+	}
+	if interpreter := getScriptInterpreter(script.relFilepath, runtime.GOOS); len(interpreter) > 0 {
+		return fmt.Sprintf("command '%s %s %s'", strings.Join(interpreter, " "), script.relFilepath, releaseVersion)
+	}
+	return fmt.Sprintf("command '%s %s'", script.relFilepath, releaseVersion)
+}
+
+// getModifiedFileHashes snapshots the content of every modified file in the worktree, keyed by its path relative to the
+// repo root, so that the files a script writes to can be told apart from the ones that were already modified
+func getModifiedFileHashes(repository vcs.Repository, repoDirpath string) (map[string]string, error) {
+	modifiedFilepaths, err := repository.GetModifiedFilepaths()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the modified files of the worktree")
+	}
+	modifiedFileHashes := map[string]string{}
+	for _, modifiedFilepath := range modifiedFilepaths {
+		fileContents, err := os.ReadFile(filepath.Join(repoDirpath, modifiedFilepath))
+		if os.IsNotExist(err) {
+			modifiedFileHashes[modifiedFilepath] = deletedFileHash
+			continue
+		}
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading modified file '%s'", modifiedFilepath)
+		}
+		fileHash := sha256.Sum256(fileContents)
+		modifiedFileHashes[modifiedFilepath] = hex.EncodeToString(fileHash[:])
+	}
+	return modifiedFileHashes, nil
+}
+
+// getWrittenFilepaths returns the sorted paths of the files that changed between the two snapshots, including those
+// that were modified before and have been reverted since
+func getWrittenFilepaths(modifiedFileHashesBefore map[string]string, modifiedFileHashesAfter map[string]string) []string {
+	writtenFilepaths := []string{}
+	for filepath, hashAfter := range modifiedFileHashesAfter {
+		if hashBefore, found := modifiedFileHashesBefore[filepath]; !found || hashBefore != hashAfter {
+			writtenFilepaths = append(writtenFilepaths, filepath)
+		}
+	}
+	for filepath := range modifiedFileHashesBefore {
+		if _, found := modifiedFileHashesAfter[filepath]; !found {
+			writtenFilepaths = append(writtenFilepaths, filepath)
+		}
+	}
+	sort.Strings(writtenFilepaths)
+	return writtenFilepaths
+}
+
+// parsePreReleaseScripts parses the lines of the pre release scripts file. Each line is the path of a script relative to
+// the repo root, optionally followed by its pinned checksum, or, if it starts with '$ ', an inline command for the shell;
+// the lines indented under an inline command are part of it, so that it can span several lines.
+func parsePreReleaseScripts(lines []string) []*preReleaseScript {
+	scripts := []*preReleaseScript{}
+	var inlineScript *preReleaseScript
+	for lineIdx, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if inlineScript != nil && strings.TrimLeft(line, " \t") != line {
+			inlineScript.inlineCommandLines = append(inlineScript.inlineCommandLines, strings.TrimSpace(line))
+			continue
+		}
+		inlineScript = nil
+		if strings.HasPrefix(line, inlineCommandPrefix) {
+			inlineScript = &preReleaseScript{
+				inlineCommandLines: []string{strings.TrimSpace(strings.TrimPrefix(line, inlineCommandPrefix))},
+				lineIdx:            lineIdx,
+			}
+			scripts = append(scripts, inlineScript)
+			continue
+		}
+		script := &preReleaseScript{relFilepath: line, lineIdx: lineIdx}
+		if separatorIdx := strings.LastIndexAny(line, " \t"); separatorIdx >= 0 && strings.HasPrefix(line[separatorIdx+1:], scriptChecksumPrefix) {
+			script.relFilepath = strings.TrimRight(line[:separatorIdx], " \t")
+			script.pinnedChecksum = strings.ToLower(strings.TrimPrefix(line[separatorIdx+1:], scriptChecksumPrefix))
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts
+}
+
+// verifyPreReleaseScriptChecksums makes sure that every pinned script still has the contents it was pinned with
+func verifyPreReleaseScriptChecksums(repoDirpath string, scripts []*preReleaseScript) error {
+	changedScriptRelFilepaths := []string{}
+	for _, script := range scripts {
+		if script.pinnedChecksum == "" {
+			continue
+		}
+		checksum, err := getScriptChecksum(repoDirpath, script.relFilepath)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting the checksum of pre release script '%s'", script.relFilepath)
+		}
+		if checksum != script.pinnedChecksum {
+			changedScriptRelFilepaths = append(changedScriptRelFilepaths, script.relFilepath)
+		}
+	}
+	if len(changedScriptRelFilepaths) > 0 {
+		return stacktrace.NewError("Pre release scripts '%s' have changed since their checksums were pinned, so none of the scripts were run", strings.Join(changedScriptRelFilepaths, "', '"))
+	}
+	return nil
+}
+
+func getScriptChecksum(repoDirpath string, scriptRelFilepath string) (string, error) {
+	scriptContents, err := os.ReadFile(filepath.Join(repoDirpath, scriptRelFilepath))
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred reading pre release script '%s'", scriptRelFilepath)
+	}
+	checksum := sha256.Sum256(scriptContents)
+	return hex.EncodeToString(checksum[:]), nil
+}
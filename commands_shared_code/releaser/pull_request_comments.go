@@ -0,0 +1,88 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	releasedInCommentFormat = "Released in %s"
+)
+
+// commentOnReleasedPullRequestsIfNeeded tells each pull request merged since the previous release which version it
+// went out in; like the other post-release steps, failures are only logged
+func (releaser *Releaser) commentOnReleasedPullRequestsIfNeeded(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, state *releaseState) {
+	if !kudetConfig.PullRequestComments.Enabled {
+		return
+	}
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		logrus.Warnf("Not commenting on the pull requests released in '%s', as an error occurred determining the forge: %v", state.Version, err)
+		return
+	}
+	if !releaseForge.supportsPullRequestComments() {
+		logrus.Warnf("Not commenting on the pull requests released in '%s', which %s doesn't support", state.Version, releaseForge.getName())
+		return
+	}
+	// The first release would comment on every pull request ever merged
+	previousReleaseCommitHash, found, err := repository.GetTagCommitHash(state.PreviousVersion)
+	if err != nil {
+		logrus.Warnf("Not commenting on the pull requests released in '%s', as an error occurred finding the commit of previous release '%s': %v", state.Version, state.PreviousVersion, err)
+		return
+	}
+	if !found {
+		logrus.Infof("Not commenting on the pull requests released in '%s', as there's no previous release to find them since", state.Version)
+		return
+	}
+	commits, err := repository.GetCommits(previousReleaseCommitHash, state.ReleaseCommitHash)
+	if err != nil {
+		logrus.Warnf("Not commenting on the pull requests released in '%s', as an error occurred listing the commits since '%s': %v", state.Version, state.PreviousVersion, err)
+		return
+	}
+
+	logrus.Infof("Commenting on the pull requests released in '%s'...", state.Version)
+	pullRequestNumbers := getReleasedPullRequestNumbers(ctx, releaseForge, commits, state.ReleaseCommitHash)
+	comment := fmt.Sprintf(releasedInCommentFormat, state.Version)
+	numCommented := 0
+	for _, pullRequestNumber := range pullRequestNumbers {
+		if err := releaseForge.commentOnPullRequest(ctx, pullRequestNumber, comment); err != nil {
+			logrus.Warnf("An error occurred commenting on pull request #%d that it was released in '%s': %v", pullRequestNumber, state.Version, err)
+			continue
+		}
+		numCommented++
+	}
+	logrus.Infof("Commented on %d of the %d pull requests released in '%s'", numCommented, len(pullRequestNumbers), state.Version)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getReleasedPullRequestNumbers resolves the commits to the merged pull requests they're part of, oldest commit's
+// first, with each pull request once however many of its commits there are; the release commit has none
+func getReleasedPullRequestNumbers(ctx context.Context, releaseForge forge, commits []vcs.Commit, releaseCommitHash string) []int64 {
+	pullRequestNumbers := []int64{}
+	isFound := map[int64]bool{}
+	for idx := len(commits) - 1; idx >= 0; idx-- {
+		commitHash := commits[idx].Hash
+		if commitHash == releaseCommitHash {
+			continue
+		}
+		commitPullRequestNumbers, err := releaseForge.getMergedPullRequestNumbers(ctx, commitHash)
+		if err != nil {
+			logrus.Warnf("Not commenting on the pull requests of commit '%s', as an error occurred finding them: %v", commitHash, err)
+			continue
+		}
+		for _, pullRequestNumber := range commitPullRequestNumbers {
+			if !isFound[pullRequestNumber] {
+				isFound[pullRequestNumber] = true
+				pullRequestNumbers = append(pullRequestNumbers, pullRequestNumber)
+			}
+		}
+	}
+	return pullRequestNumbers
+}
@@ -0,0 +1,103 @@
+package releaser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNextPageUrl(t *testing.T) {
+	require.Equal(t, "https://api.github.com/repos/owner/repo/commits/abc/pulls?page=2", getNextPageUrl(`<https://api.github.com/repos/owner/repo/commits/abc/pulls?page=2>; rel="next", <https://api.github.com/repos/owner/repo/commits/abc/pulls?page=3>; rel="last"`))
+	require.Equal(t, "", getNextPageUrl(`<https://api.github.com/repos/owner/repo/commits/abc/pulls?page=1>; rel="prev"`))
+	require.Equal(t, "", getNextPageUrl(""))
+}
+
+func TestCommentOnReleasedPullRequestsIfNeeded(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{"https://github.com/owner/repo.git"}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	commitHashes := []string{}
+	for _, message := range []string{"Initial commit", "Add the widget", "Fix the widget", "Release 0.2.0"} {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte(message+"\n"), 0644))
+		commitHash, err := repository.CommitAll(message, &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+		require.NoError(t, err)
+		commitHashes = append(commitHashes, commitHash)
+	}
+	require.NoError(t, repository.CreateTag("0.1.0", commitHashes[0], "0.1.0"))
+
+	lock := &sync.Mutex{}
+	requestedCommitHashes := []string{}
+	commentsByPullRequest := map[string]string{}
+	var githubApi *httptest.Server
+	githubApi = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/commits/"+commitHashes[1]+"/pulls":
+			requestedCommitHashes = append(requestedCommitHashes, commitHashes[1])
+			// The merged pull request is on the second page, past an unmerged one
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=100&page=2>; rel="next"`, githubApi.URL, r.URL.Path))
+				_, err := w.Write([]byte(`[{"number": 6, "merged_at": null}]`))
+				require.NoError(t, err)
+				return
+			}
+			_, err := w.Write([]byte(`[{"number": 5, "merged_at": "2026-10-01T00:00:00Z"}]`))
+			require.NoError(t, err)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/commits/"+commitHashes[2]+"/pulls":
+			requestedCommitHashes = append(requestedCommitHashes, commitHashes[2])
+			_, err := w.Write([]byte(`[{"number": 5, "merged_at": "2026-10-01T00:00:00Z"}, {"number": 7, "merged_at": "2026-10-02T00:00:00Z"}]`))
+			require.NoError(t, err)
+		case r.Method == http.MethodPost && (r.URL.Path == "/repos/owner/repo/issues/5/comments" || r.URL.Path == "/repos/owner/repo/issues/7/comments"):
+			commentRequest := &githubIssueCommentRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(commentRequest))
+			_, found := commentsByPullRequest[r.URL.Path]
+			require.False(t, found)
+			commentsByPullRequest[r.URL.Path] = commentRequest.Body
+			_, err := w.Write([]byte(`{}`))
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer githubApi.Close()
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.Forge = kudet_config.ForgeConfig{Type: kudet_config.GithubForgeType, ApiUrl: githubApi.URL}
+	releaser := NewReleaser(repoDirpath, "token")
+	state := &releaseState{Version: "0.2.0", PreviousVersion: "0.1.0", ReleaseCommitHash: commitHashes[3]}
+
+	// Nothing is commented on unless it's enabled
+	releaser.commentOnReleasedPullRequestsIfNeeded(context.Background(), repository, kudetConfig, state)
+	require.Empty(t, requestedCommitHashes)
+
+	kudetConfig.PullRequestComments.Enabled = true
+	releaser.commentOnReleasedPullRequestsIfNeeded(context.Background(), repository, kudetConfig, state)
+	// Both pages of the first commit's pull requests, oldest commit first, and none for the release commit
+	require.Equal(t, []string{commitHashes[1], commitHashes[1], commitHashes[2]}, requestedCommitHashes)
+	require.Equal(t, map[string]string{
+		"/repos/owner/repo/issues/5/comments": "Released in 0.2.0",
+		"/repos/owner/repo/issues/7/comments": "Released in 0.2.0",
+	}, commentsByPullRequest)
+
+	// The first release has no previous release to look for pull requests since
+	requestedCommitHashes = []string{}
+	releaser.commentOnReleasedPullRequestsIfNeeded(context.Background(), repository, kudetConfig, &releaseState{Version: "0.1.0", PreviousVersion: "0.0.0", ReleaseCommitHash: commitHashes[0]})
+	require.Empty(t, requestedCommitHashes)
+}
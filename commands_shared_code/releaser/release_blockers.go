@@ -0,0 +1,304 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ReleaseBlocker is something that currently stops the repo from being released
+type ReleaseBlocker struct {
+	// The kind of release failure it would cause, or nil if it isn't a known one
+	Kind *ReleaseError
+
+	Problem string
+}
+
+// GetReleaseBlockers runs the release's checks and gates with the releaser's options, without fetching, committing or
+// writing anything, and returns everything that currently stops the repo from being released, in the order the
+// release would run into them. An empty list means the release would get as far as being confirmed. Checks that need
+// the next version are skipped while the changelog is invalid, since the version can't be determined until it's fixed.
+func (releaser *Releaser) GetReleaseBlockers(ctx context.Context) ([]ReleaseBlocker, error) {
+	repoDirpath := releaser.repoDirpath
+	kudetConfig, err := releaser.getKudetConfig()
+	if err != nil {
+		return nil, stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the kudet config for the repo")
+	}
+	releaseBranchName := kudetConfig.ReleaseBranch
+	repository, err := releaser.openRepository(repoDirpath, releaser.token)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	if err := releaser.authenticateAsGithubAppIfNeeded(ctx, repository, kudetConfig); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred authenticating as the kudet config's GitHub App")
+	}
+
+	blockers := []ReleaseBlocker{}
+	stateStore := newReleaseStateStore(repository.GetMetadataDirpath(), kudetConfig.ReleaseState)
+	if stateStore.isShared() {
+		lock, err := loadReleaseLock(ctx, stateStore)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred checking whether a release holds the release lock")
+		}
+		if lock != nil {
+			blockers = append(blockers, ReleaseBlocker{
+				Kind:    ErrReleaseLocked,
+				Problem: fmt.Sprintf("The release lock at '%s' has been held by %s since %s; if that release died, run 'kudet release-state unlock'", stateStore.getLocation(releaseLockFilename), lock.Holder, lock.AcquiredAt.Format(time.RFC3339)),
+			})
+		}
+	}
+	inProgressReleaseState, err := loadReleaseState(ctx, stateStore)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred checking for an in-progress release")
+	}
+	if inProgressReleaseState != nil && inProgressReleaseState.IsEmbargoed {
+		return append(blockers, newReleaseBlocker(ErrReleaseEmbargoed, "A release is under embargo", getEmbargoedReleaseError(inProgressReleaseState))), nil
+	}
+	if inProgressReleaseState != nil {
+		// None of the checks apply to resuming, which only pushes what was already committed and tagged
+		logrus.Infof("The release of version '%s' recorded at '%s' is in progress, so the next release resumes it", inProgressReleaseState.Version, stateStore.getLocation(releaseStateFilename))
+		return blockers, nil
+	}
+
+	isPushDryRun := isDryRunStep(kudetConfig.DryRunSteps, kudet_config.PushDryRunStep)
+	if releaser.bridgeScriptFilepath == "" && !isPushDryRun {
+		if err := repository.CheckPushAccess(ctx); err != nil {
+			blockers = append(blockers, newReleaseBlocker(ErrPushRejected, fmt.Sprintf("The token can't push to '%s'", originRemoteName), err))
+		}
+	}
+
+	worktreeBlockers, err := getWorktreeBlockers(repository, kudetConfig.AllowedDirtyPaths)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred checking the worktree")
+	}
+	blockers = append(blockers, worktreeBlockers...)
+
+	remoteRefHashes, err := repository.ListRemoteRefs(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the refs on remote '%s'", originRemoteName)
+	}
+	remoteReleaseBranchHash, isRemoteReleaseBranchFound := remoteRefHashes[headRef+releaseBranchName]
+	if !isRemoteReleaseBranchFound {
+		blockers = append(blockers, ReleaseBlocker{
+			Kind:    ErrOutOfSync,
+			Problem: fmt.Sprintf("There's no '%s' branch on remote '%s' to release", releaseBranchName, originRemoteName),
+		})
+	} else {
+		syncBlockers, err := getSyncBlockers(repository, releaseBranchName, remoteReleaseBranchHash)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred checking that '%s' is in sync with '%s'", releaseBranchName, originRemoteName)
+		}
+		blockers = append(blockers, syncBlockers...)
+	}
+
+	rules := newBumpRules(kudetConfig)
+	tbdSkeletonLines, err := readChangelogTbdSkeleton(repoDirpath, kudetConfig.ChangelogTbdSkeletonFilepath)
+	if err != nil {
+		return nil, stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred reading the changelog's TBD skeleton")
+	}
+	// Nil while the changelogs are invalid
+	var changes *changelogChanges
+	releaseNotesLines := []string{}
+	changelogFilepath := filepath.Join(repoDirpath, kudetConfig.ChangelogFilepath)
+	changelogFile, err := os.ReadFile(changelogFilepath)
+	if err != nil {
+		blockers = append(blockers, newReleaseBlocker(ErrChangelogInvalid, fmt.Sprintf("The changelog at '%s' can't be read", changelogFilepath), err))
+	} else {
+		changelogFile = pruneChangelogTbdSkeleton(changelogFile, tbdSkeletonLines)
+		changes, err = parseChangeLogFile(changelogFile, rules)
+		if err != nil {
+			blockers = append(blockers, newReleaseBlocker(ErrChangelogInvalid, fmt.Sprintf("The changelog at '%s' is invalid", changelogFilepath), err))
+		} else {
+			// The notes of a valid changelog can always be gotten
+			releaseNotesLines, _ = getUnreleasedNotesLines(changelogFile)
+			approvedReleaseNotesFilepath := filepath.Join(repoDirpath, kudetConfig.ApprovedReleaseNotesFilepath)
+			if err := verifyReleaseNotesMatchApprovedCopy(changelogFile, approvedReleaseNotesFilepath, releaser.isReleaseNotesDiffAcknowledged); err != nil {
+				blockers = append(blockers, newReleaseBlocker(ErrReleaseNotesUnapproved, "The release notes aren't approved", err))
+			}
+		}
+	}
+	additionalChangelogsChanges, err := parseAdditionalChangelogs(repoDirpath, kudetConfig.AdditionalChangelogFilepaths, tbdSkeletonLines, rules)
+	if err != nil {
+		blockers = append(blockers, newReleaseBlocker(ErrChangelogInvalid, "An additional changelog is invalid", err))
+		changes = nil
+	} else if changes != nil {
+		changes = mergeChangelogChanges(changes, additionalChangelogsChanges)
+	}
+
+	latestReleaseVersion, err := getLatestReleaseVersion(repository, kudetConfig.TagParsing)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the latest release version")
+	}
+	if kudetConfig.TagSignatures.KeyringFilepath != "" {
+		if err := verifyReleaseTagSignatures(repository, repoDirpath, kudetConfig.TagSignatures, latestReleaseVersion.String()); err != nil {
+			blockers = append(blockers, newReleaseBlocker(ErrTagSignatureInvalid, fmt.Sprintf("The tags of release '%s' aren't signed by a trusted key", latestReleaseVersion.String()), err))
+		}
+	}
+	repoVersionFiles, _ := splitVersionFiles(repoDirpath, kudetConfig.VersionFiles)
+	if len(repoVersionFiles) > 0 && latestReleaseVersion.String() != noPreviousVersion {
+		if err := verifyVersionFilesHaveVersion(repoDirpath, repoVersionFiles, latestReleaseVersion.String()); err != nil {
+			blockers = append(blockers, newReleaseBlocker(ErrVersionFileOutOfSync, fmt.Sprintf("The version files are out of sync with release '%s'", latestReleaseVersion.String()), err))
+		}
+	}
+	if changes != nil {
+		if err := verifyRequiredChangelogSections(repository, changelogFile, kudetConfig.RequiredChangelogSections, latestReleaseVersion.String()); err != nil {
+			blockers = append(blockers, newReleaseBlocker(ErrChangelogSectionMissing, "The changed files need changelog sections", err))
+		}
+	}
+	// Categories that force a release can only be found in a valid changelog
+	isReleaseForced := changes != nil && len(changes.categories.forcedReleaseSubheaders) > 0
+	if kudetConfig.NonReleasableChanges.OnOnlyNonReleasable == kudet_config.SkipNonReleasableChangesPolicy && !isReleaseForced {
+		problem, isOnlyNonReleasable, err := getOnlyNonReleasableChangesProblem(repository, kudetConfig, latestReleaseVersion.String())
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred checking whether anything releasable changed since release '%s'", latestReleaseVersion.String())
+		}
+		if isOnlyNonReleasable {
+			blockers = append(blockers, ReleaseBlocker{Kind: ErrOnlyNonReleasableChanges, Problem: problem})
+		}
+	}
+	if !releaser.isForced {
+		problem, isTooSoon, err := getReleasedTooRecentlyProblem(repository, kudetConfig.MinReleaseInterval, latestReleaseVersion.String(), time.Now())
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred checking when release '%s' was cut", latestReleaseVersion.String())
+		}
+		if isTooSoon {
+			blockers = append(blockers, ReleaseBlocker{Kind: ErrReleasedTooRecently, Problem: problem})
+		}
+	}
+	if changes == nil {
+		logrus.Warnf("Skipped the checks that need the next version, which can't be determined until the changelog is valid")
+		return blockers, nil
+	}
+	nextReleaseVersion := getNextReleaseVersion(latestReleaseVersion, changes, releaser.shouldBumpMajorVersion)
+	if !releaser.shouldBumpMajorVersion {
+		override, err := getBumpOverride(repository, kudetConfig.BumpOverride, latestReleaseVersion.String())
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred looking for an approved override of the version bump")
+		}
+		if override != nil {
+			nextReleaseVersion = override.apply(latestReleaseVersion)
+		}
+	}
+
+	if err := verifyReleaseTagsDoNotExist(repository, nextReleaseVersion.String()); err != nil {
+		blockers = append(blockers, newReleaseBlocker(ErrReleaseTagExists, fmt.Sprintf("Version '%s' was already tagged", nextReleaseVersion.String()), err))
+	}
+
+	if releaser.shouldRequireGreenCi && isRemoteReleaseBranchFound {
+		releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+		if err != nil {
+			blockers = append(blockers, newReleaseBlocker(nil, "CI can't be checked", err))
+		} else if err := verifyCiIsGreen(ctx, releaseForge, remoteReleaseBranchHash, kudetConfig.Ci.RequiredChecks); err != nil {
+			blockers = append(blockers, newReleaseBlocker(ErrCiNotGreen, fmt.Sprintf("CI isn't green on '%s/%s'", originRemoteName, releaseBranchName), err))
+		}
+	}
+
+	if len(kudetConfig.Policy.Paths) > 0 || len(kudetConfig.Policy.WasmGates) > 0 {
+		headCommitHash, err := repository.GetHeadCommitHash()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the commit at HEAD")
+		}
+		denials, err := releaser.getReleasePolicyDenials(ctx, repository, kudetConfig, &nextReleaseVersion, latestReleaseVersion, headCommitHash, releaseNotesLines)
+		if err != nil {
+			kind, _ := GetReleaseError(err)
+			blockers = append(blockers, newReleaseBlocker(kind, "The release policy can't be evaluated", err))
+		} else if len(denials) > 0 {
+			blockers = append(blockers, ReleaseBlocker{
+				Kind:    ErrPolicyDenied,
+				Problem: fmt.Sprintf("The release policy refuses to release version '%s':\n%s", nextReleaseVersion.String(), formatPolicyDenials(denials)),
+			})
+		}
+	}
+	return blockers, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// newReleaseBlocker describes the blocker with the root cause of the error that the check returned
+func newReleaseBlocker(kind *ReleaseError, problem string, err error) ReleaseBlocker {
+	return ReleaseBlocker{
+		Kind:    kind,
+		Problem: fmt.Sprintf("%s: %v", problem, stacktrace.RootCause(err)),
+	}
+}
+
+func getWorktreeBlockers(repository vcs.Repository, allowedDirtyPaths []string) ([]ReleaseBlocker, error) {
+	isClean, worktreeStatusStr, err := repository.GetStatus()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the status of the worktree")
+	}
+	if isClean {
+		return nil, nil
+	}
+	if len(allowedDirtyPaths) == 0 {
+		return []ReleaseBlocker{{
+			Kind:    ErrDirtyWorktree,
+			Problem: fmt.Sprintf("The worktree has uncommitted changes:\n%s", strings.TrimSpace(worktreeStatusStr)),
+		}}, nil
+	}
+	modifiedFilepaths, err := repository.GetModifiedFilepaths()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the modified files of the worktree")
+	}
+	disallowedDirtyFilepaths := getDisallowedDirtyFilepaths(modifiedFilepaths, allowedDirtyPaths)
+	if len(disallowedDirtyFilepaths) == 0 {
+		return nil, nil
+	}
+	return []ReleaseBlocker{{
+		Kind:    ErrDirtyWorktree,
+		Problem: fmt.Sprintf("The worktree has uncommitted changes outside the allowed dirty paths: '%s'", strings.Join(disallowedDirtyFilepaths, "', '")),
+	}}, nil
+}
+
+// getSyncBlockers compares the local release branch with the remote's as it is now, rather than as of the last fetch,
+// since nothing is fetched
+func getSyncBlockers(repository vcs.Repository, releaseBranchName string, remoteReleaseBranchHash string) ([]ReleaseBlocker, error) {
+	checkedOutBranchName, isOnBranch, err := repository.GetCheckedOutBranchName()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the checked out branch")
+	}
+	if description, isTemporary := getTemporaryRefDescription(headRef + checkedOutBranchName); isOnBranch && isTemporary {
+		return []ReleaseBlocker{{
+			Kind:    ErrNotOnReleaseBranch,
+			Problem: fmt.Sprintf("Branch '%s' is checked out, which is %s rather than release branch '%s'", checkedOutBranchName, description, releaseBranchName),
+		}}, nil
+	}
+	localReleaseBranchHash, err := repository.ResolveRevision(releaseBranchName)
+	if err != nil {
+		headCommitHash, err := repository.GetHeadCommitHash()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the commit at HEAD")
+		}
+		// The release creates the local branch when HEAD is detached on the remote's
+		if headCommitHash == remoteReleaseBranchHash {
+			return nil, nil
+		}
+		if ciRef, description, isTemporary := getCiTemporaryRef(); isTemporary {
+			return []ReleaseBlocker{{
+				Kind:    ErrNotOnReleaseBranch,
+				Problem: fmt.Sprintf("HEAD is detached on '%s', which is %s rather than release branch '%s'", ciRef, description, releaseBranchName),
+			}}, nil
+		}
+		return []ReleaseBlocker{{
+			Kind:    ErrOutOfSync,
+			Problem: fmt.Sprintf("There's no local '%s' branch, and HEAD isn't on commit '%s' of '%s/%s' to create it from", releaseBranchName, remoteReleaseBranchHash, originRemoteName, releaseBranchName),
+		}}, nil
+	}
+	if localReleaseBranchHash == remoteReleaseBranchHash {
+		return nil, nil
+	}
+	return []ReleaseBlocker{{
+		Kind:    ErrOutOfSync,
+		Problem: fmt.Sprintf("The local '%s' branch is on commit '%s' while '%s/%s' is on '%s'", releaseBranchName, localReleaseBranchHash, originRemoteName, releaseBranchName, remoteReleaseBranchHash),
+	}}, nil
+}
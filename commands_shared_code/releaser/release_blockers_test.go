@@ -0,0 +1,56 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReleaseBlockers(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	originDirpath := t.TempDir()
+	_, err = git.PlainInit(originDirpath, true)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{originDirpath}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	changelogFilepath := filepath.Join(repoDirpath, "changelog.md")
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte("# TBD\n### Fixes\n* Fixed a bug\n\n# 0.1.0\n* Initial release\n"), 0644))
+	_, err = repository.CommitAll("Initial commit", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	branchName, _, err := repository.GetCheckedOutBranchName()
+	require.NoError(t, err)
+	require.NoError(t, gitRepository.Push(&git.PushOptions{RemoteName: vcs.OriginRemoteName, RefSpecs: []config.RefSpec{config.RefSpec("refs/heads/" + branchName + ":refs/heads/" + branchName)}}))
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.ReleaseBranch = branchName
+	kudetConfig.ChangelogFilepath = "changelog.md"
+	releaser := NewReleaser(repoDirpath, "token", WithKudetConfig(kudetConfig))
+	blockers, err := releaser.GetReleaseBlockers(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, blockers)
+
+	// The changelog is both dirty and invalid, and the local branch moves ahead of the remote's
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "notes.txt"), []byte("notes\n"), 0644))
+	_, err = repository.CommitAll("Add notes", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte("# TBD\n\n# 0.1.0\n* Initial release\n"), 0644))
+	blockers, err = releaser.GetReleaseBlockers(context.Background())
+	require.NoError(t, err)
+	blockerKinds := []*ReleaseError{}
+	for _, blocker := range blockers {
+		blockerKinds = append(blockerKinds, blocker.Kind)
+	}
+	require.Equal(t, []*ReleaseError{ErrDirtyWorktree, ErrOutOfSync, ErrChangelogInvalid}, blockerKinds)
+}
@@ -0,0 +1,126 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	releaseDiffIndent = "  "
+
+	// Files whose changed lines would take more comparisons than this to diff, e.g. a regenerated lockfile, are only
+	// summarized, as the diff is a longest-common-subsequence one that's quadratic in the lines compared
+	maxReleaseDiffLineComparisons = 4_000_000
+)
+
+// confirmReleaseDiff shows the diff that the release commit will make, now that the release's changes are in the
+// worktree, then asks the wizard or the confirmer to approve it
+func (releaser *Releaser) confirmReleaseDiff(ctx context.Context, repository vcs.Repository, repoDirpath string, version string) (bool, error) {
+	headCommitHash, err := repository.GetHeadCommitHash()
+	if err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred getting the commit that the release commit will follow")
+	}
+	diffLines, err := getReleaseDiffLines(repository, repoDirpath, headCommitHash)
+	if err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred getting the diff of the release commit")
+	}
+	if releaser.wizard != nil {
+		return releaser.wizard.confirmRelease(version, diffLines)
+	}
+	logrus.Infof("The release commit of version '%s' will make these changes:\n%s", version, strings.Join(diffLines, "\n"))
+	return releaser.confirmer(ctx, i18n.Sprintf(i18n.ConfirmReleaseQuestion, version))
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getReleaseDiffLines describes each file with changes in the worktree, which the release commit will commit, by the
+// lines that differ from the given commit's copy of it
+func getReleaseDiffLines(repository vcs.Repository, repoDirpath string, baseCommitHash string) ([]string, error) {
+	modifiedRelFilepaths, err := repository.GetModifiedFilepaths()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the modified files of the worktree")
+	}
+	if len(modifiedRelFilepaths) == 0 {
+		return []string{"No files are changed."}, nil
+	}
+	diffLines := []string{}
+	for _, relFilepath := range modifiedRelFilepaths {
+		baseContents, isInBase, err := repository.ReadFileAtCommit(baseCommitHash, relFilepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading '%s' as of commit '%s'", relFilepath, baseCommitHash)
+		}
+		contents, err := os.ReadFile(filepath.Join(repoDirpath, filepath.FromSlash(relFilepath)))
+		isInWorktree := true
+		if os.IsNotExist(err) {
+			isInWorktree = false
+		} else if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading '%s'", relFilepath)
+		}
+		diffLines = append(diffLines, getFileDiffLines(relFilepath, baseContents, isInBase, contents, isInWorktree)...)
+	}
+	return diffLines, nil
+}
+
+func getFileDiffLines(relFilepath string, baseContents []byte, isInBase bool, contents []byte, isInWorktree bool) []string {
+	switch {
+	case !isInBase:
+		relFilepath += " (added)"
+	case !isInWorktree:
+		return []string{fmt.Sprintf("%s: deleted", relFilepath)}
+	}
+	if bytes.IndexByte(baseContents, 0) != -1 || bytes.IndexByte(contents, 0) != -1 {
+		return []string{fmt.Sprintf("%s: binary file changed", relFilepath)}
+	}
+	baseLines := splitDiffLines(baseContents)
+	lines := splitDiffLines(contents)
+	// Only the changed lines need the full diff, which keeps it cheap for the small edits that releases mostly make
+	numCommonPrefixLines := 0
+	for numCommonPrefixLines < len(baseLines) && numCommonPrefixLines < len(lines) && baseLines[numCommonPrefixLines] == lines[numCommonPrefixLines] {
+		numCommonPrefixLines++
+	}
+	baseLines, lines = baseLines[numCommonPrefixLines:], lines[numCommonPrefixLines:]
+	numCommonSuffixLines := 0
+	for numCommonSuffixLines < len(baseLines) && numCommonSuffixLines < len(lines) && baseLines[len(baseLines)-1-numCommonSuffixLines] == lines[len(lines)-1-numCommonSuffixLines] {
+		numCommonSuffixLines++
+	}
+	baseLines, lines = baseLines[:len(baseLines)-numCommonSuffixLines], lines[:len(lines)-numCommonSuffixLines]
+	if len(baseLines)*len(lines) > maxReleaseDiffLineComparisons {
+		return []string{fmt.Sprintf("%s: %d lines replaced with %d lines, too many to diff", relFilepath, len(baseLines), len(lines))}
+	}
+
+	changedLines := diffNotesLines(baseLines, lines)
+	if len(changedLines) == 0 && !isInBase {
+		return []string{fmt.Sprintf("%s: empty", relFilepath)}
+	}
+	if len(changedLines) == 0 {
+		return []string{fmt.Sprintf("%s: only line endings changed", relFilepath)}
+	}
+	fileDiffLines := []string{fmt.Sprintf("%s:", relFilepath)}
+	for _, diffLine := range changedLines {
+		fileDiffLines = append(fileDiffLines, releaseDiffIndent+diffLine)
+	}
+	return fileDiffLines
+}
+
+// splitDiffLines splits the contents into lines without their line endings, so that CRLF files diff like others
+func splitDiffLines(contents []byte) []string {
+	if len(contents) == 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	for idx, line := range lines {
+		lines[idx] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
@@ -0,0 +1,61 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReleaseDiffLines(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte(wizardTestChangelog), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "config.yml"), []byte("name: app\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "logo.png"), []byte("\x89PNG\x00"), 0644))
+	baseCommitHash, err := repository.CommitAll("Initial commit", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+
+	diffLines, err := getReleaseDiffLines(repository, repoDirpath, baseCommitHash)
+	require.NoError(t, err)
+	require.Equal(t, []string{"No files are changed."}, diffLines)
+
+	// What the changelog finalization and a clobbering pre-release script would leave behind
+	require.NoError(t, updateChangelog(filepath.Join(repoDirpath, "changelog.md"), "0.2.0", nil, changelog.DefaultMaxLineLength))
+	require.NoError(t, os.Remove(filepath.Join(repoDirpath, "config.yml")))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "logo.png"), []byte("\x89PNG\x00\x01"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "version.txt"), []byte("0.2.0\n"), 0644))
+
+	diffLines, err = getReleaseDiffLines(repository, repoDirpath, baseCommitHash)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"changelog.md:",
+		"  + ",
+		"  + # 0.2.0",
+		"config.yml: deleted",
+		"logo.png: binary file changed",
+		"version.txt (added):",
+		"  + 0.2.0",
+	}, diffLines)
+}
+
+func TestGetFileDiffLines(t *testing.T) {
+	require.Equal(t, []string{"a.txt:", "  - two", "  + 2"}, getFileDiffLines("a.txt", []byte("one\ntwo\nthree\n"), true, []byte("one\n2\nthree\n"), true))
+	require.Equal(t, []string{"a.txt: only line endings changed"}, getFileDiffLines("a.txt", []byte("one\ntwo\n"), true, []byte("one\r\ntwo\r\n"), true))
+	require.Equal(t, []string{".keep (added): empty"}, getFileDiffLines(".keep", nil, false, []byte{}, true))
+
+	hugeContents := []byte(strings.Repeat("line\n", 3000))
+	require.Equal(t, []string{"a.lock: 3000 lines replaced with 3000 lines, too many to diff"}, getFileDiffLines("a.lock", hugeContents, true, []byte(strings.Repeat("other\n", 3000)), true))
+}
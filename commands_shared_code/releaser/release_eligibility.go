@@ -0,0 +1,106 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"path/filepath"
+)
+
+// CheckReleaseEligibility checks whether the repo has something to release: entries under the changelog's TBD header,
+// and commits since the latest release's tag that change more than non-releasable files, where only those changing
+// skips the release and no changelog category forces one. The reasons there's nothing to release are returned, so a repo without any is due a release; this
+// is meant for scheduled workflows that only release when there's something to ship.
+func CheckReleaseEligibility(repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, repoDirpath string) ([]string, error) {
+	changelogFilepath := filepath.Join(repoDirpath, kudetConfig.ChangelogFilepath)
+	changelogFile, err := os.ReadFile(changelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the changelog at '%s'", changelogFilepath)
+	}
+	tbdSkeletonLines, err := readChangelogTbdSkeleton(repoDirpath, kudetConfig.ChangelogTbdSkeletonFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the changelog's TBD skeleton")
+	}
+	// An untouched skeleton isn't something to release
+	changelogFile = pruneChangelogTbdSkeleton(changelogFile, tbdSkeletonLines)
+	latestReleaseVersion, err := getLatestReleaseVersion(repository, kudetConfig.TagParsing)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the latest release version")
+	}
+	latestReleaseCommitHash, _, err := getReleaseCommitHash(repository, latestReleaseVersion.String())
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the commit of the latest release")
+	}
+	headCommitHash, err := repository.GetHeadCommitHash()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the HEAD commit")
+	}
+	reasons, err := getReleaseIneligibilityReasons(changelogFile, latestReleaseVersion.String(), latestReleaseCommitHash, headCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred checking the changelog at '%s' for unreleased changes", changelogFilepath)
+	}
+	// Housekeeping commits only hold off a release where the release itself would be skipped for them
+	if kudetConfig.NonReleasableChanges.OnOnlyNonReleasable == kudet_config.SkipNonReleasableChangesPolicy && !isReleaseForced(changelogFile, kudetConfig.ChangelogCategories) {
+		problem, isOnlyNonReleasable, err := getOnlyNonReleasableChangesProblem(repository, kudetConfig, latestReleaseVersion.String())
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred checking whether anything releasable changed since release '%s'", latestReleaseVersion.String())
+		}
+		if isOnlyNonReleasable {
+			reasons = append(reasons, problem)
+		}
+	}
+	return reasons, nil
+}
+
+// getReleaseCommitHash resolves the commit that the release of the given version was tagged on, trying its 'X.Y.Z'
+// tag before its 'vX.Y.Z' one, or returns false if there's no such release
+func getReleaseCommitHash(repository vcs.Repository, version string) (string, bool, error) {
+	if version == noPreviousVersion {
+		return "", false, nil
+	}
+	for _, tagName := range []string{version, vPrefix + version} {
+		commitHash, found, err := repository.GetTagCommitHash(tagName)
+		if err != nil {
+			return "", false, stacktrace.Propagate(err, "An error occurred resolving tag '%s'", tagName)
+		}
+		if found {
+			return commitHash, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getReleaseIneligibilityReasons returns why there's nothing to release; the latest release's commit is empty when
+// there's no previous release, in which case every commit is new
+func getReleaseIneligibilityReasons(changelogFile []byte, latestReleaseVersion string, latestReleaseCommitHash string, headCommitHash string) ([]string, error) {
+	reasons := []string{}
+	releaseNotesLines, err := getUnreleasedNotesLines(changelogFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the unreleased changes from the changelog")
+	}
+	if len(releaseNotesLines) == 0 {
+		reasons = append(reasons, fmt.Sprintf("The changelog has no entries under its '%s' header", versionToBeReleasedPlaceholderHeaderStr))
+	}
+	if latestReleaseCommitHash != "" && latestReleaseCommitHash == headCommitHash {
+		reasons = append(reasons, fmt.Sprintf("There are no commits since release '%s'", latestReleaseVersion))
+	}
+	return reasons, nil
+}
+
+// isReleaseForced reports whether the changelog's TBD section has entries under a category that forces a release; a
+// changelog that can't be parsed doesn't force anything, which the release itself then reports
+func isReleaseForced(changelogFile []byte, categoryConfigs []kudet_config.ChangelogCategoryConfig) bool {
+	parsedChangelog, err := changelog.Parse(changelogFile)
+	if err != nil || len(parsedChangelog.Versions) == 0 || !parsedChangelog.Versions[0].IsUnreleased() {
+		return false
+	}
+	return len(getCategoryChanges(parsedChangelog.Versions[0], categoryConfigs).forcedReleaseSubheaders) > 0
+}
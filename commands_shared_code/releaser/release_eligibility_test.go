@@ -0,0 +1,29 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReleaseIneligibilityReasons(t *testing.T) {
+	changelog := []byte("# TBD\n* Fix things\n\n# 1.2.3\n* Add things\n")
+	reasons, err := getReleaseIneligibilityReasons(changelog, "1.2.3", "aaaa", "bbbb")
+	require.NoError(t, err)
+	require.Empty(t, reasons)
+
+	// The first release has no tag to compare against, so every commit is new
+	reasons, err = getReleaseIneligibilityReasons(changelog, noPreviousVersion, "", "bbbb")
+	require.NoError(t, err)
+	require.Empty(t, reasons)
+}
+
+func TestGetReleaseIneligibilityReasons_NothingToRelease(t *testing.T) {
+	changelog := []byte("# TBD\n\n# 1.2.3\n* Add things\n")
+	reasons, err := getReleaseIneligibilityReasons(changelog, "1.2.3", "aaaa", "aaaa")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"The changelog has no entries under its '# TBD' header",
+		"There are no commits since release '1.2.3'",
+	}, reasons)
+}
@@ -0,0 +1,34 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"time"
+)
+
+// getReleasedTooRecentlyProblem checks whether the previous release's commit, which its tags are put on as soon as it's
+// made, was committed less than the minimum release interval before the given time, and if so returns a description of
+// it. Without a minimum interval, and before the first release, there's no problem to report.
+func getReleasedTooRecentlyProblem(repository vcs.Repository, minReleaseInterval time.Duration, previousVersion string, now time.Time) (string, bool, error) {
+	if minReleaseInterval <= 0 {
+		return "", false, nil
+	}
+	previousReleaseCommitHash, hasPreviousRelease, err := getReleaseCommitHash(repository, previousVersion)
+	if err != nil {
+		return "", false, stacktrace.Propagate(err, "An error occurred getting the commit of release '%s'", previousVersion)
+	}
+	if !hasPreviousRelease {
+		return "", false, nil
+	}
+	previousReleaseTime, err := repository.GetCommitTime(previousReleaseCommitHash)
+	if err != nil {
+		return "", false, stacktrace.Propagate(err, "An error occurred getting when release '%s' was committed", previousVersion)
+	}
+	sincePreviousRelease := now.Sub(previousReleaseTime)
+	if sincePreviousRelease >= minReleaseInterval {
+		return "", false, nil
+	}
+	return fmt.Sprintf("Release '%s' was cut %v ago, within the kudet config's '%s' of %v", previousVersion, sincePreviousRelease.Round(time.Second), kudet_config.MinReleaseIntervalKey, minReleaseInterval), true, nil
+}
@@ -0,0 +1,43 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReleasedTooRecentlyProblem(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "main.go"), []byte("package main\n"), 0644))
+	releaseTime := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	releaseCommitHash, err := repository.CommitAll("Finalize changes for release version '0.1.0'", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: releaseTime})
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("v0.1.0", releaseCommitHash, "v0.1.0"))
+
+	problem, isTooSoon, err := getReleasedTooRecentlyProblem(repository, time.Hour, "0.1.0", releaseTime.Add(10*time.Minute))
+	require.NoError(t, err)
+	require.True(t, isTooSoon)
+	require.Equal(t, "Release '0.1.0' was cut 10m0s ago, within the kudet config's 'min-release-interval' of 1h0m0s", problem)
+
+	_, isTooSoon, err = getReleasedTooRecentlyProblem(repository, time.Hour, "0.1.0", releaseTime.Add(time.Hour))
+	require.NoError(t, err)
+	require.False(t, isTooSoon)
+	_, isTooSoon, err = getReleasedTooRecentlyProblem(repository, 0, "0.1.0", releaseTime)
+	require.NoError(t, err)
+	require.False(t, isTooSoon)
+	_, isTooSoon, err = getReleasedTooRecentlyProblem(repository, time.Hour, noPreviousVersion, releaseTime)
+	require.NoError(t, err)
+	require.False(t, isTooSoon)
+}
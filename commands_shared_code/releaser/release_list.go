@@ -0,0 +1,153 @@
+package releaser
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReleaseListing is one of the versions that the repo's tags release
+type ReleaseListing struct {
+	Version string `json:"version"`
+
+	// The commit that the version's 'X.Y.Z' tag points at, or its 'vX.Y.Z' tag's if it only has that one
+	CommitHash string `json:"commitHash"`
+	// When that commit was committed
+	Date time.Time `json:"date"`
+
+	HasTag  bool `json:"hasTag"`
+	HasVTag bool `json:"hasVTag"`
+
+	IsPrerelease bool `json:"isPrerelease"`
+	// Whether the repo's tag parsing config excludes the version when determining the latest release
+	IsIgnored bool `json:"isIgnored"`
+}
+
+// ReleaseListFilter narrows down the releases that ListReleases returns; its zero value returns every release that
+// isn't a prerelease
+type ReleaseListFilter struct {
+	// If set, only the releases after this version, or committed at or after this date ('2006-01-02' for the start of
+	// that day, or RFC 3339), are returned
+	Since string
+
+	// If positive, only this many of the latest releases are returned
+	Limit int
+
+	ShouldIncludePrereleases bool
+}
+
+// ListReleases returns the versions that the repo's semver tags release, latest first, including those that the tag
+// parsing config ignores so that version detection can be debugged
+func ListReleases(repository vcs.Repository, tagParsingConfig kudet_config.TagParsingConfig, filter ReleaseListFilter) ([]ReleaseListing, error) {
+	tagNames, err := repository.ListTagNames()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the repo's tags")
+	}
+	versions := map[string]*semver.Version{}
+	releases := map[string]*ReleaseListing{}
+	for _, tagName := range tagNames {
+		versionStr := strings.TrimPrefix(tagName, vPrefix)
+		if !semverRegex.MatchString(versionStr) {
+			continue
+		}
+		version, err := semver.StrictNewVersion(versionStr)
+		if err != nil {
+			continue
+		}
+		commitHash, found, err := repository.GetTagCommitHash(tagName)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred resolving tag '%s'", tagName)
+		}
+		if !found {
+			continue
+		}
+		release, found := releases[versionStr]
+		if !found {
+			release = &ReleaseListing{
+				Version:      versionStr,
+				IsPrerelease: version.Prerelease() != "",
+			}
+			versions[versionStr] = version
+			releases[versionStr] = release
+		}
+		if tagName == versionStr {
+			release.HasTag = true
+			release.CommitHash = commitHash
+		} else {
+			release.HasVTag = true
+			if release.CommitHash == "" {
+				release.CommitHash = commitHash
+			}
+		}
+	}
+
+	sortedVersions := []*semver.Version{}
+	for _, version := range versions {
+		sortedVersions = append(sortedVersions, version)
+	}
+	sort.Sort(sort.Reverse(semver.Collection(sortedVersions)))
+	listings := []ReleaseListing{}
+	for _, version := range sortedVersions {
+		release := releases[version.Original()]
+		commitTime, err := repository.GetCommitTime(release.CommitHash)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting when commit '%s' of release '%s' was committed", release.CommitHash, release.Version)
+		}
+		release.Date = commitTime
+		release.IsIgnored = isReleaseIgnored(version, release.HasTag, tagParsingConfig)
+		listings = append(listings, *release)
+	}
+	return filterReleaseListings(listings, filter)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// isReleaseIgnored mirrors parseReleaseVersionTags for a version, given whether it has its 'X.Y.Z' tag
+func isReleaseIgnored(version *semver.Version, hasTag bool, tagParsingConfig kudet_config.TagParsingConfig) bool {
+	if !hasTag && !tagParsingConfig.AllowVPrefix {
+		return true
+	}
+	return (version.Prerelease() != "" || version.Metadata() != "") && !tagParsingConfig.AllowPrereleasesAndMetadata
+}
+
+// filterReleaseListings applies the filter to the releases, which are sorted latest first
+func filterReleaseListings(releases []ReleaseListing, filter ReleaseListFilter) ([]ReleaseListing, error) {
+	var sinceVersion *semver.Version
+	var sinceTime time.Time
+	if filter.Since != "" {
+		if version, err := semver.StrictNewVersion(strings.TrimPrefix(filter.Since, vPrefix)); err == nil {
+			sinceVersion = version
+		} else if sinceTime, err = time.Parse(time.RFC3339, filter.Since); err != nil {
+			sinceTime, err = time.ParseInLocation(simulationDateFormat, filter.Since, time.Local)
+			if err != nil {
+				return nil, stacktrace.NewError("'%s' is neither a version nor a date ('%s' or RFC 3339)", filter.Since, simulationDateFormat)
+			}
+		}
+	}
+	filteredReleases := []ReleaseListing{}
+	for _, release := range releases {
+		if filter.Limit > 0 && len(filteredReleases) == filter.Limit {
+			break
+		}
+		if release.IsPrerelease && !filter.ShouldIncludePrereleases {
+			continue
+		}
+		// Already parsed when the tags were listed
+		version := semver.MustParse(release.Version)
+		if sinceVersion != nil && !version.GreaterThan(sinceVersion) {
+			continue
+		}
+		if !sinceTime.IsZero() && release.Date.Before(sinceTime) {
+			continue
+		}
+		filteredReleases = append(filteredReleases, release)
+	}
+	return filteredReleases, nil
+}
@@ -0,0 +1,79 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListReleases(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte("# 0.1.0\n"), 0644))
+	commitTime := time.Date(2022, 6, 3, 12, 0, 0, 0, time.UTC)
+	commitHash, err := repository.CommitAll("Release 0.1.0", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: commitTime})
+	require.NoError(t, err)
+	for _, tagName := range []string{"0.1.0", "v0.1.0", "v0.2.0-rc.1", "not-a-version"} {
+		require.NoError(t, repository.CreateTag(tagName, commitHash, tagName))
+	}
+
+	releases, err := ListReleases(repository, kudet_config.TagParsingConfig{}, ReleaseListFilter{ShouldIncludePrereleases: true})
+	require.NoError(t, err)
+	require.Len(t, releases, 2)
+	require.Equal(t, ReleaseListing{Version: "0.2.0-rc.1", CommitHash: commitHash, HasVTag: true, IsPrerelease: true, IsIgnored: true}, withoutDate(releases[0]))
+	require.Equal(t, ReleaseListing{Version: "0.1.0", CommitHash: commitHash, HasTag: true, HasVTag: true}, withoutDate(releases[1]))
+	require.True(t, commitTime.Equal(releases[1].Date))
+
+	releases, err = ListReleases(repository, kudet_config.TagParsingConfig{}, ReleaseListFilter{})
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+}
+
+func TestFilterReleaseListings(t *testing.T) {
+	releases := []ReleaseListing{
+		{Version: "1.3.0-rc.1", Date: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC), IsPrerelease: true},
+		{Version: "1.2.0", Date: time.Date(2022, 6, 3, 12, 0, 0, 0, time.UTC)},
+		{Version: "1.1.0", Date: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Version: "1.0.0", Date: time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	testCases := []struct {
+		filter           ReleaseListFilter
+		expectedVersions []string
+	}{
+		{ReleaseListFilter{}, []string{"1.2.0", "1.1.0", "1.0.0"}},
+		{ReleaseListFilter{ShouldIncludePrereleases: true}, []string{"1.3.0-rc.1", "1.2.0", "1.1.0", "1.0.0"}},
+		{ReleaseListFilter{Limit: 2}, []string{"1.2.0", "1.1.0"}},
+		{ReleaseListFilter{Since: "v1.0.0"}, []string{"1.2.0", "1.1.0"}},
+		{ReleaseListFilter{Since: "2022-05-01T00:00:00Z"}, []string{"1.2.0", "1.1.0"}},
+		{ReleaseListFilter{Since: "1.0.0", Limit: 1}, []string{"1.2.0"}},
+	}
+	for _, testCase := range testCases {
+		filteredReleases, err := filterReleaseListings(releases, testCase.filter)
+		require.NoError(t, err)
+		versions := []string{}
+		for _, release := range filteredReleases {
+			versions = append(versions, release.Version)
+		}
+		require.Equal(t, testCase.expectedVersions, versions, "filter %+v", testCase.filter)
+	}
+
+	_, err := filterReleaseListings(releases, ReleaseListFilter{Since: "last week"})
+	require.Error(t, err)
+}
+
+func withoutDate(release ReleaseListing) ReleaseListing {
+	release.Date = time.Time{}
+	return release
+}
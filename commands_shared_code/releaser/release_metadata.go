@@ -0,0 +1,107 @@
+package releaser
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	releaseMetadataFilename   = "release-metadata.json"
+	releaseMetadataFileMode   = 0644
+	releaseMetadataJsonIndent = "  "
+)
+
+// releaseMetadata is the machine-readable provenance record of a release, for supply-chain tooling
+type releaseMetadata struct {
+	Version         string   `json:"version"`
+	PreviousVersion string   `json:"previousVersion,omitempty"`
+	CommitHash      string   `json:"commitHash"`
+	TagNames        []string `json:"tagNames"`
+	// When the release finished, in RFC 3339 format and UTC
+	Timestamp        string `json:"timestamp"`
+	ChangelogExcerpt string `json:"changelogExcerpt"`
+	KudetVersion     string `json:"kudetVersion"`
+	// Whether the rollout passed its health check, if one is configured
+	RolloutStatus string `json:"rolloutStatus,omitempty"`
+	// The names of the artifacts that the pre-release scripts produced, collected into the artifacts directory next to this file
+	Artifacts []string `json:"artifacts,omitempty"`
+	// How the artifacts differ from the previous release's, if the kudet config has them compared
+	ArtifactDiff *artifactDiffReport `json:"artifactDiff,omitempty"`
+}
+
+func newReleaseMetadata(state *releaseState, releasedAt time.Time) *releaseMetadata {
+	artifactNames := []string{}
+	for _, artifactFilepath := range state.ArtifactFilepaths {
+		artifactNames = append(artifactNames, filepath.Base(artifactFilepath))
+	}
+	return &releaseMetadata{
+		Version:          state.Version,
+		PreviousVersion:  state.PreviousVersion,
+		CommitHash:       state.ReleaseCommitHash,
+		TagNames:         []string{state.Version, vPrefix + state.Version},
+		Timestamp:        releasedAt.UTC().Format(time.RFC3339),
+		ChangelogExcerpt: state.ReleaseNotes,
+		KudetVersion:     kudet_version.KudetVersion,
+		Artifacts:        artifactNames,
+		ArtifactDiff:     state.ArtifactDiff,
+	}
+}
+
+// publishReleaseMetadataIfNeeded writes and uploads the metadata of a successful release as requested; the release is
+// irreversible by the time it runs, so failures are only logged
+func (releaser *Releaser) publishReleaseMetadataIfNeeded(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, state *releaseState, rolloutStatus string) {
+	if releaser.metadataDirpath == "" && !releaser.shouldUploadMetadata {
+		return
+	}
+	metadata := newReleaseMetadata(state, time.Now())
+	metadata.RolloutStatus = rolloutStatus
+
+	if releaser.metadataDirpath != "" {
+		metadataFilepath := filepath.Join(releaser.metadataDirpath, releaseMetadataFilename)
+		if err := writeReleaseMetadata(metadataFilepath, metadata); err != nil {
+			logrus.Errorf("ACTION REQUIRED: An error occurred writing the metadata of release '%s' to '%s'; the release itself succeeded:\n%v", state.Version, metadataFilepath, err)
+		} else {
+			logrus.Infof("Wrote the metadata of release '%s' to '%s'", state.Version, metadataFilepath)
+		}
+	}
+
+	if !releaser.shouldUploadMetadata || skipDryRunStep(kudetConfig.DryRunSteps, kudet_config.ReleaseAssetsDryRunStep, "uploading the release metadata") {
+		return
+	}
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred determining the forge to upload the metadata of release '%s' to; the release itself succeeded:\n%v", state.Version, err)
+		return
+	}
+	logrus.Infof("Uploading '%s' to the %s release of version '%s'...", releaseMetadataFilename, releaseForge.getName(), state.Version)
+	assetUrl, err := releaseForge.uploadReleaseJsonAsset(ctx, state.Version, state.ReleaseNotes, releaseMetadataFilename, metadata)
+	if err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred uploading the metadata of release '%s'; the release itself succeeded:\n%v", state.Version, err)
+		return
+	}
+	logrus.Infof("Uploaded release metadata to '%s'", assetUrl)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func writeReleaseMetadata(metadataFilepath string, metadata *releaseMetadata) error {
+	metadataBytes, err := json.MarshalIndent(metadata, "", releaseMetadataJsonIndent)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the release metadata")
+	}
+	if err := os.WriteFile(metadataFilepath, metadataBytes, releaseMetadataFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the release metadata file at '%s'", metadataFilepath)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package releaser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReleaseMetadata(t *testing.T) {
+	state := &releaseState{
+		Version:           "0.4.0",
+		PreviousVersion:   "0.3.2",
+		ReleaseCommitHash: "9fceb02d0ae598e95dc970b74767f19372d61af8",
+		ReleaseNotes:      "### Features\n* Added release metadata",
+	}
+	releasedAt := time.Date(2026, 10, 15, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	metadataFilepath := filepath.Join(t.TempDir(), releaseMetadataFilename)
+	require.NoError(t, writeReleaseMetadata(metadataFilepath, newReleaseMetadata(state, releasedAt)))
+
+	metadataBytes, err := os.ReadFile(metadataFilepath)
+	require.NoError(t, err)
+	metadata := &releaseMetadata{}
+	require.NoError(t, json.Unmarshal(metadataBytes, metadata))
+	require.Equal(t, &releaseMetadata{
+		Version:          "0.4.0",
+		PreviousVersion:  "0.3.2",
+		CommitHash:       "9fceb02d0ae598e95dc970b74767f19372d61af8",
+		TagNames:         []string{"0.4.0", "v0.4.0"},
+		Timestamp:        "2026-10-15T12:00:00Z",
+		ChangelogExcerpt: "### Features\n* Added release metadata",
+		KudetVersion:     kudet_version.KudetVersion,
+	}, metadata)
+}
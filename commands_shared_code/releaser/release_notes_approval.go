@@ -0,0 +1,134 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"strings"
+)
+
+const (
+	removedLinePrefix = "- "
+	addedLinePrefix   = "+ "
+)
+
+// verifyReleaseNotesMatchApprovedCopy compares the release notes under the changelog's TBD header against the approved
+// copy, if there is one, refusing the release on any difference unless the differences have been acknowledged
+func verifyReleaseNotesMatchApprovedCopy(changelogFile []byte, approvedCopyFilepath string, isDiffAcknowledged bool) error {
+	approvedCopy, err := os.ReadFile(approvedCopyFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return stacktrace.Propagate(err, "An error occurred reading the approved release notes at '%s'", approvedCopyFilepath)
+	}
+	releaseNotesLines, err := getUnreleasedNotesLines(changelogFile)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the release notes from the changelog")
+	}
+	diffLines := diffNotesLines(normalizeNotesLines(strings.Split(string(approvedCopy), "\n")), releaseNotesLines)
+	if len(diffLines) == 0 {
+		return nil
+	}
+	diffStr := strings.Join(diffLines, "\n")
+	if isDiffAcknowledged {
+		logrus.Warnf("Releasing despite the release notes differing from the approved copy at '%s':\n%s", approvedCopyFilepath, diffStr)
+		return nil
+	}
+	return stacktrace.NewError(
+		"The release notes differ from the approved copy at '%s' ('%s' lines are only in the approved copy, '%s' lines only in the changelog); get the changes approved or acknowledge them explicitly:\n%s",
+		approvedCopyFilepath,
+		strings.TrimSpace(removedLinePrefix),
+		strings.TrimSpace(addedLinePrefix),
+		diffStr,
+	)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getUnreleasedNotesLines returns the normalized lines between the changelog's TBD header and the previous version's header
+func getUnreleasedNotesLines(changelogFile []byte) ([]string, error) {
+	lines := []string{}
+	isInTbdSection := false
+	scanner := newChangelogScanner(changelogFile)
+	for scanner.Scan() {
+		if versionToBeReleasedPlaceholderHeaderRegex.Match(scanner.Bytes()) {
+			isInTbdSection = true
+			continue
+		}
+		if versionHeaderRegex.Match(scanner.Bytes()) {
+			break
+		}
+		if isInTbdSection {
+			lines = append(lines, scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while scanning the bytes of the changelog file.")
+	}
+	return normalizeNotesLines(lines), nil
+}
+
+// normalizeNotesLines drops trailing whitespace and leading and trailing blank lines, which don't change what readers see
+func normalizeNotesLines(lines []string) []string {
+	normalizedLines := []string{}
+	for _, line := range lines {
+		normalizedLines = append(normalizedLines, strings.TrimRight(line, " \t\r"))
+	}
+	for len(normalizedLines) > 0 && normalizedLines[0] == "" {
+		normalizedLines = normalizedLines[1:]
+	}
+	for len(normalizedLines) > 0 && normalizedLines[len(normalizedLines)-1] == "" {
+		normalizedLines = normalizedLines[:len(normalizedLines)-1]
+	}
+	return normalizedLines
+}
+
+// diffNotesLines returns the lines that differ between the two, in order, prefixed with whether they were removed or
+// added; it's a longest-common-subsequence diff, which is plenty for release notes
+func diffNotesLines(approvedLines []string, releaseNotesLines []string) []string {
+	numApproved := len(approvedLines)
+	numReleaseNotes := len(releaseNotesLines)
+	// commonLengths[i][j] is the length of the longest common subsequence of approvedLines[i:] and releaseNotesLines[j:]
+	commonLengths := make([][]int, numApproved+1)
+	for i := range commonLengths {
+		commonLengths[i] = make([]int, numReleaseNotes+1)
+	}
+	for i := numApproved - 1; i >= 0; i-- {
+		for j := numReleaseNotes - 1; j >= 0; j-- {
+			if approvedLines[i] == releaseNotesLines[j] {
+				commonLengths[i][j] = commonLengths[i+1][j+1] + 1
+			} else if commonLengths[i+1][j] >= commonLengths[i][j+1] {
+				commonLengths[i][j] = commonLengths[i+1][j]
+			} else {
+				commonLengths[i][j] = commonLengths[i][j+1]
+			}
+		}
+	}
+
+	diffLines := []string{}
+	i, j := 0, 0
+	for i < numApproved && j < numReleaseNotes {
+		switch {
+		case approvedLines[i] == releaseNotesLines[j]:
+			i++
+			j++
+		case commonLengths[i+1][j] >= commonLengths[i][j+1]:
+			diffLines = append(diffLines, removedLinePrefix+approvedLines[i])
+			i++
+		default:
+			diffLines = append(diffLines, addedLinePrefix+releaseNotesLines[j])
+			j++
+		}
+	}
+	for ; i < numApproved; i++ {
+		diffLines = append(diffLines, removedLinePrefix+approvedLines[i])
+	}
+	for ; j < numReleaseNotes; j++ {
+		diffLines = append(diffLines, addedLinePrefix+releaseNotesLines[j])
+	}
+	return diffLines
+}
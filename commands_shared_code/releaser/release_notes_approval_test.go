@@ -0,0 +1,33 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUnreleasedNotesLines(t *testing.T) {
+	changelog := `# TBD
+
+### Features
+* Added a thing
+
+# 0.1.0
+* Initial`
+
+	lines, err := getUnreleasedNotesLines([]byte(changelog))
+	require.NoError(t, err)
+	require.Equal(t, []string{"### Features", "* Added a thing"}, lines)
+}
+
+func TestDiffNotesLines(t *testing.T) {
+	approvedLines := []string{"### Features", "* Added a thing", "* Made it faster"}
+	releaseNotesLines := []string{"### Features", "* Added a thing", "* Made it much faster", "* Fixed a typo"}
+
+	require.Empty(t, diffNotesLines(approvedLines, approvedLines))
+	require.Equal(
+		t,
+		[]string{"- * Made it faster", "+ * Made it much faster", "+ * Fixed a typo"},
+		diffNotesLines(approvedLines, releaseNotesLines),
+	)
+}
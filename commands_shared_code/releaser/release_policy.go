@@ -0,0 +1,190 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	opaBinaryName = "opa"
+
+	majorBumpType = kudet_config.MajorBump
+	minorBumpType = kudet_config.MinorBump
+	patchBumpType = kudet_config.PatchBump
+)
+
+// releasePlan is what the release policy is evaluated against, as its input
+type releasePlan struct {
+	Version         string `json:"version"`
+	PreviousVersion string `json:"previousVersion"`
+	// One of 'major', 'minor' or 'patch'
+	BumpType string `json:"bumpType"`
+
+	ReleaseBranch string `json:"releaseBranch"`
+
+	// The files changed since the previous release's tag; empty for the first release
+	ChangedPaths   []string `json:"changedPaths"`
+	IsFirstRelease bool     `json:"isFirstRelease"`
+
+	// Who is cutting the release, as their git identity
+	Approver releasePlanApprover `json:"approver"`
+
+	// When the release is being cut, in UTC, with the weekday (e.g. 'Friday') and hour broken out for time windows
+	Time    string `json:"time"`
+	Weekday string `json:"weekday"`
+	Hour    int    `json:"hour"`
+
+	IsSecurityRelease bool `json:"isSecurityRelease"`
+	IsEmbargoed       bool `json:"isEmbargoed"`
+}
+
+type releasePlanApprover struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// opaEvalOutput is the part of 'opa eval --format json' output that holds the query's value
+type opaEvalOutput struct {
+	Result []struct {
+		Expressions []struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+// getReleasePlan describes the release about to be cut, for the release policy to judge
+func (releaser *Releaser) getReleasePlan(repository vcs.Repository, releaseBranch string, nextVersion *semver.Version, previousVersion *semver.Version, releaseTime time.Time) (*releasePlan, error) {
+	changedPaths, hasPreviousRelease, err := getFilepathsChangedSinceRelease(repository, previousVersion.String())
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the files changed since the previous release")
+	}
+	if changedPaths == nil {
+		changedPaths = []string{}
+	}
+	author, err := repository.GetAuthor()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting who is cutting the release")
+	}
+	releaseTime = releaseTime.UTC()
+	return &releasePlan{
+		Version:         nextVersion.String(),
+		PreviousVersion: previousVersion.String(),
+		BumpType:        getBumpType(previousVersion, nextVersion),
+		ReleaseBranch:   releaseBranch,
+		ChangedPaths:    changedPaths,
+		IsFirstRelease:  !hasPreviousRelease,
+		Approver: releasePlanApprover{
+			Name:  author.Name,
+			Email: author.Email,
+		},
+		Time:              releaseTime.Format(time.RFC3339),
+		Weekday:           releaseTime.Weekday().String(),
+		Hour:              releaseTime.Hour(),
+		IsSecurityRelease: releaser.isSecurityRelease,
+		IsEmbargoed:       releaser.isEmbargoed,
+	}, nil
+}
+
+// getReleasePolicyDenials judges the release about to be cut from the given commit against the release policy and the
+// WASM gates, returning the reasons they give to refuse it
+func (releaser *Releaser) getReleasePolicyDenials(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, nextVersion *semver.Version, previousVersion *semver.Version, headCommitHash string, releaseNotesLines []string) ([]string, error) {
+	plan, err := releaser.getReleasePlan(repository, kudetConfig.ReleaseBranch, nextVersion, previousVersion, time.Now())
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred describing the release for the release policy")
+	}
+	denials := []string{}
+	if len(kudetConfig.Policy.Paths) > 0 {
+		denials, err = evaluateReleasePolicy(ctx, releaser.repoDirpath, kudetConfig.Policy, plan)
+		if err != nil {
+			return nil, stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred evaluating the release policy in '%s'", strings.Join(kudetConfig.Policy.Paths, "', '"))
+		}
+	}
+	if len(kudetConfig.Policy.WasmGates) > 0 {
+		remoteUrl, err := repository.GetRemoteUrl()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the remote URL for the WASM gates")
+		}
+		wasmGateInput := &wasmGateInput{
+			Plan: plan,
+			Repo: wasmGateRepoFacts{
+				RemoteUrl:      remoteUrl,
+				HeadCommitHash: headCommitHash,
+				ReleaseNotes:   releaseNotesLines,
+			},
+		}
+		wasmGateDenials, err := runWasmGates(ctx, releaser.repoDirpath, kudetConfig.Policy.WasmGates, wasmGateInput)
+		if err != nil {
+			return nil, stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred running the WASM gates '%s'", strings.Join(kudetConfig.Policy.WasmGates, "', '"))
+		}
+		denials = append(denials, wasmGateDenials...)
+	}
+	return denials, nil
+}
+
+// evaluateReleasePolicy evaluates the policy's query against the release plan with the 'opa' CLI, returning the reasons
+// it gives to refuse the release; a query that's undefined, e.g. because no rule matched, refuses nothing
+func evaluateReleasePolicy(ctx context.Context, repoDirpath string, policyConfig kudet_config.PolicyConfig, plan *releasePlan) ([]string, error) {
+	planJson, err := json.Marshal(plan)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred serializing the release plan")
+	}
+	opaArgs := []string{"eval", "--format", "json", "--stdin-input"}
+	for _, policyPath := range policyConfig.Paths {
+		if !filepath.IsAbs(policyPath) {
+			policyPath = filepath.Join(repoDirpath, policyPath)
+		}
+		opaArgs = append(opaArgs, "--data", policyPath)
+	}
+	opaArgs = append(opaArgs, policyConfig.Query)
+
+	opaCmd := exec.CommandContext(ctx, opaBinaryName, opaArgs...)
+	opaCmd.Dir = repoDirpath
+	opaCmd.Stdin = bytes.NewReader(planJson)
+	stderr := &bytes.Buffer{}
+	opaCmd.Stderr = stderr
+	logrus.Debugf("Evaluating the release policy with '%s'", opaCmd.String())
+	output, err := opaCmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.Error); ok {
+			return nil, stacktrace.Propagate(err, "The '%s' CLI, which evaluates the release policy, couldn't be run; install it from https://www.openpolicyagent.org/docs/latest/#running-opa", opaBinaryName)
+		}
+		return nil, stacktrace.Propagate(err, "Evaluating the release policy failed with error output:\n%s", stderr.String())
+	}
+	return parseOpaEvalOutput(output, policyConfig.Query)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func parseOpaEvalOutput(output []byte, query string) ([]string, error) {
+	evalOutput := &opaEvalOutput{}
+	if err := json.Unmarshal(output, evalOutput); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the output of the release policy evaluation")
+	}
+	if len(evalOutput.Result) == 0 || len(evalOutput.Result[0].Expressions) == 0 {
+		logrus.Warnf("Release policy query '%s' is undefined, so it refuses nothing; check that '%s.%s' names a rule of the policy", query, kudet_config.PolicyKey, kudet_config.PolicyQueryKey)
+		return nil, nil
+	}
+	denials := []string{}
+	if err := json.Unmarshal(evalOutput.Result[0].Expressions[0].Value, &denials); err != nil {
+		return nil, stacktrace.Propagate(err, "Release policy query '%s' must evaluate to a set of strings giving the reasons to refuse the release", query)
+	}
+	return denials, nil
+}
+
+func formatPolicyDenials(denials []string) string {
+	return fmt.Sprintf("- %s", strings.Join(denials, "\n- "))
+}
@@ -0,0 +1,54 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOpaEvalOutput(t *testing.T) {
+	denials, err := parseOpaEvalOutput([]byte(`{"result":[{"expressions":[{"value":["No releases on Fridays"],"text":"data.kudet.release.deny"}]}]}`), "data.kudet.release.deny")
+	require.NoError(t, err)
+	require.Equal(t, []string{"No releases on Fridays"}, denials)
+
+	denials, err = parseOpaEvalOutput([]byte(`{"result":[{"expressions":[{"value":[]}]}]}`), "data.kudet.release.deny")
+	require.NoError(t, err)
+	require.Empty(t, denials)
+
+	// An undefined query has no result at all
+	denials, err = parseOpaEvalOutput([]byte(`{}`), "data.kudet.release.deny")
+	require.NoError(t, err)
+	require.Empty(t, denials)
+
+	_, err = parseOpaEvalOutput([]byte(`{"result":[{"expressions":[{"value":true}]}]}`), "data.kudet.release.allow")
+	require.ErrorContains(t, err, "must evaluate to a set of strings")
+}
+
+func TestEvaluateReleasePolicy(t *testing.T) {
+	// Stands in for the opa CLI, refusing major releases and echoing the policy path it was given
+	binDirpath := t.TempDir()
+	fakeOpa := `#!/bin/sh
+policy="$6"
+IFS= read -r plan
+case "$plan" in
+  *'"bumpType":"major"'*) echo "{\"result\":[{\"expressions\":[{\"value\":[\"Major releases need sign-off ($policy)\"]}]}]}" ;;
+  *) echo '{"result":[{"expressions":[{"value":[]}]}]}' ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(binDirpath, opaBinaryName), []byte(fakeOpa), 0755))
+	t.Setenv("PATH", binDirpath)
+	repoDirpath := t.TempDir()
+	policyConfig := kudet_config.PolicyConfig{Paths: []string{"policies/"}, Query: "data.kudet.release.deny"}
+
+	denials, err := evaluateReleasePolicy(context.Background(), repoDirpath, policyConfig, &releasePlan{Version: "2.0.0", BumpType: majorBumpType})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Major releases need sign-off (" + filepath.Join(repoDirpath, "policies") + ")"}, denials)
+
+	denials, err = evaluateReleasePolicy(context.Background(), repoDirpath, policyConfig, &releasePlan{Version: "1.2.4", BumpType: patchBumpType})
+	require.NoError(t, err)
+	require.Empty(t, denials)
+}
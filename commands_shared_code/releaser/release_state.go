@@ -0,0 +1,154 @@
+package releaser
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+)
+
+const (
+	// The name of the file, inside the Git directory unless the release state is kept in a bucket, which records an
+	// in-progress release so that a release run that dies partway through its pushes can be resumed rather than recomputed
+	releaseStateFilename   = "kudet-release-state.json"
+	releaseStateFileMode   = 0644
+	releaseStateJsonIndent = "  "
+)
+
+// releaseState is the on-disk record of a release that has been committed locally but whose pushes may not have all completed
+type releaseState struct {
+	Version         string `json:"version"`
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	// The commit that origin main was on before the release started
+	BaseCommitHash string `json:"baseCommitHash"`
+	// The "Finalize changes for release" commit that the release tags point at
+	ReleaseCommitHash string `json:"releaseCommitHash"`
+	// The notes under the changelog's TBD header that the release finalized
+	ReleaseNotes string `json:"releaseNotes,omitempty"`
+	// Embargoed releases are held locally until 'kudet lift-embargo' is run, rather than being resumed
+	IsEmbargoed bool `json:"isEmbargoed,omitempty"`
+	// The draft forge release staged for an embargoed release, if any; it's published when the embargo lifts
+	DraftReleaseId int64 `json:"draftReleaseId,omitempty"`
+	// On forges without draft releases, whether the forge release is to be created when the embargo lifts
+	IsForgeReleasePending bool `json:"isForgeReleasePending,omitempty"`
+	// The copies of the artifacts that the pre-release scripts produced, collected outside the repo
+	ArtifactFilepaths []string `json:"artifactFilepaths,omitempty"`
+	// Who released, as 'Name <email>', and why the version was bumped the way it was, for the release's audit note
+	ReleasedBy string `json:"releasedBy,omitempty"`
+	BumpReason string `json:"bumpReason,omitempty"`
+	// The tags of the version tracks released along with the version, which are pushed after its release tag
+	VersionTrackTags []string `json:"versionTrackTags,omitempty"`
+	// How the artifacts differ from the previous release's, if they were compared
+	ArtifactDiff *artifactDiffReport `json:"artifactDiff,omitempty"`
+}
+
+// remoteReleaseProgress describes which of the release's resources are already present on the remote
+type remoteReleaseProgress struct {
+	hasVPrefixedReleaseTag bool
+	hasReleaseCommit       bool
+	hasReleaseTag          bool
+
+	missingVersionTrackTags []string
+}
+
+func (progress remoteReleaseProgress) isComplete() bool {
+	return progress.hasVPrefixedReleaseTag && progress.hasReleaseCommit && progress.hasReleaseTag && len(progress.missingVersionTrackTags) == 0
+}
+
+// loadReleaseState returns the in-progress release state, or nil if no release is in progress
+func loadReleaseState(ctx context.Context, store releaseStateStore) (*releaseState, error) {
+	releaseStateBytes, found, err := store.read(ctx, releaseStateFilename)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the release state at '%s'", store.getLocation(releaseStateFilename))
+	}
+	if !found {
+		return nil, nil
+	}
+	state := &releaseState{}
+	if err := json.Unmarshal(releaseStateBytes, state); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the release state at '%s'; if no release is in progress it can be deleted", store.getLocation(releaseStateFilename))
+	}
+	return state, nil
+}
+
+func saveReleaseState(ctx context.Context, store releaseStateStore, state *releaseState) error {
+	releaseStateBytes, err := json.MarshalIndent(state, "", releaseStateJsonIndent)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the state of release '%s'", state.Version)
+	}
+	if err := store.write(ctx, releaseStateFilename, releaseStateBytes); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the release state at '%s'", store.getLocation(releaseStateFilename))
+	}
+	return nil
+}
+
+func removeReleaseState(ctx context.Context, store releaseStateStore) error {
+	if err := store.remove(ctx, releaseStateFilename); err != nil {
+		return stacktrace.Propagate(err, "An error occurred removing the release state at '%s'", store.getLocation(releaseStateFilename))
+	}
+	return nil
+}
+
+// getRemoteReleaseProgress uses the remote's refs to determine which of the release's pushes have already landed
+func getRemoteReleaseProgress(remoteRefHashes map[string]string, state *releaseState, releaseBranchName string) (remoteReleaseProgress, error) {
+	releaseTagRefName := tagsPrefix + state.Version
+	vReleaseTagRefName := tagsPrefix + vPrefix + state.Version
+	releaseBranchRefName := headRef + releaseBranchName
+
+	progress := remoteReleaseProgress{}
+	foundReleaseBranch := false
+	for remoteRefName, remoteRefHash := range remoteRefHashes {
+		switch remoteRefName {
+		case releaseTagRefName:
+			progress.hasReleaseTag = true
+		case vReleaseTagRefName:
+			progress.hasVPrefixedReleaseTag = true
+		case releaseBranchRefName:
+			foundReleaseBranch = true
+			remoteBranchHashStr := remoteRefHash
+			switch remoteBranchHashStr {
+			case state.ReleaseCommitHash:
+				progress.hasReleaseCommit = true
+			case state.BaseCommitHash:
+				progress.hasReleaseCommit = false
+			default:
+				return remoteReleaseProgress{}, stacktrace.NewError(
+					"The remote '%s' branch is on commit '%s', which is neither the commit the in-progress release '%s' started from ('%s') nor its release commit ('%s'); someone has pushed in the meantime so the release can't be resumed automatically",
+					releaseBranchName,
+					remoteBranchHashStr,
+					state.Version,
+					state.BaseCommitHash,
+					state.ReleaseCommitHash,
+				)
+			}
+		}
+	}
+	if !foundReleaseBranch {
+		return remoteReleaseProgress{}, stacktrace.NewError("Couldn't find the '%s' branch on the remote", releaseBranchName)
+	}
+	for _, versionTrackTag := range state.VersionTrackTags {
+		if _, found := remoteRefHashes[tagsPrefix+versionTrackTag]; !found {
+			progress.missingVersionTrackTags = append(progress.missingVersionTrackTags, versionTrackTag)
+		}
+	}
+	return progress, nil
+}
+
+// ensureLocalReleaseTag creates the given tag on the release commit if it doesn't already exist, and verifies it
+// points at the release commit if it does
+func ensureLocalReleaseTag(repository vcs.Repository, tagName string, releaseCommitHash string) error {
+	existingTagCommitHash, found, err := repository.GetTagCommitHash(tagName)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred resolving local tag '%s'", tagName)
+	}
+	if found {
+		if existingTagCommitHash != releaseCommitHash {
+			return stacktrace.NewError("Local tag '%s' points at commit '%s' rather than release commit '%s'", tagName, existingTagCommitHash, releaseCommitHash)
+		}
+		return nil
+	}
+	if err := repository.CreateTag(tagName, releaseCommitHash, tagName); err != nil {
+		return stacktrace.Propagate(err, "An error occurred recreating local tag '%s' on release commit '%s'", tagName, releaseCommitHash)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"time"
+)
+
+// ReleaseStateStatus is what's recorded about an in-progress release, and who holds the release lock, wherever the
+// repo keeps its release state
+type ReleaseStateStatus struct {
+	StateLocation string
+	// Empty if no release is in progress
+	InProgressVersion string
+	ReleaseCommitHash string
+	IsEmbargoed       bool
+
+	// Empty if the release state isn't kept where other machines can reach it, since only then is there a lock
+	LockLocation string
+	// Empty if no release holds the lock
+	LockHolder     string
+	LockAcquiredAt time.Time
+}
+
+// GetReleaseStateStatus reads the repo's release state and release lock, without changing either
+func GetReleaseStateStatus(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig) (*ReleaseStateStatus, error) {
+	stateStore := newReleaseStateStore(repository.GetMetadataDirpath(), kudetConfig.ReleaseState)
+	status := &ReleaseStateStatus{
+		StateLocation: stateStore.getLocation(releaseStateFilename),
+	}
+	state, err := loadReleaseState(ctx, stateStore)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred loading the release state")
+	}
+	if state != nil {
+		status.InProgressVersion = state.Version
+		status.ReleaseCommitHash = state.ReleaseCommitHash
+		status.IsEmbargoed = state.IsEmbargoed
+	}
+	if !stateStore.isShared() {
+		return status, nil
+	}
+	status.LockLocation = stateStore.getLocation(releaseLockFilename)
+	lock, err := loadReleaseLock(ctx, stateStore)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred loading the release lock")
+	}
+	if lock != nil {
+		status.LockHolder = lock.Holder
+		status.LockAcquiredAt = lock.AcquiredAt
+	}
+	return status, nil
+}
+
+// AbortInProgressRelease forgets the in-progress release, so that the next release starts afresh instead of resuming
+// it, and returns its version, or an empty string if no release was in progress. Nothing that was already pushed is
+// undone. It's refused while a release holds the lock, since that release could still be pushing.
+func AbortInProgressRelease(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig) (string, error) {
+	stateStore := newReleaseStateStore(repository.GetMetadataDirpath(), kudetConfig.ReleaseState)
+	if stateStore.isShared() {
+		lock, err := loadReleaseLock(ctx, stateStore)
+		if err != nil {
+			return "", stacktrace.Propagate(err, "An error occurred checking whether a release holds the release lock")
+		}
+		if lock != nil {
+			return "", stacktrace.NewErrorWithCode(ErrReleaseLocked.code, "The release lock at '%s' is held by %s since %s, which could still be pushing; if it died, unlock the release first", stateStore.getLocation(releaseLockFilename), lock.Holder, lock.AcquiredAt.Format(time.RFC3339))
+		}
+	}
+	state, err := loadReleaseState(ctx, stateStore)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred loading the release state")
+	}
+	if state == nil {
+		return "", nil
+	}
+	if err := removeReleaseState(ctx, stateStore); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred removing the state of the in-progress release of version '%s'", state.Version)
+	}
+	return state.Version, nil
+}
+
+// UnlockRelease removes the release lock left behind by a release that died without giving it up, returning whether
+// it was held; it's up to the caller to make sure that release isn't actually still running
+func UnlockRelease(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig) (bool, error) {
+	stateStore := newReleaseStateStore(repository.GetMetadataDirpath(), kudetConfig.ReleaseState)
+	if !stateStore.isShared() {
+		return false, nil
+	}
+	// The lock isn't parsed, so that one that's been corrupted can be removed too
+	_, wasLocked, err := stateStore.read(ctx, releaseLockFilename)
+	if err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred reading the release lock at '%s'", stateStore.getLocation(releaseLockFilename))
+	}
+	if err := stateStore.remove(ctx, releaseLockFilename); err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred removing the release lock at '%s'", stateStore.getLocation(releaseLockFilename))
+	}
+	return wasLocked, nil
+}
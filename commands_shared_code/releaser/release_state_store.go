@@ -0,0 +1,277 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// The name of the object recording who's releasing the repo, when its release state is kept in a bucket
+	releaseLockFilename = "kudet-release.lock"
+
+	stdioObjectPath = "-"
+)
+
+// releaseStateStore is where the record of an in-progress release and the release lock are kept, as named objects
+type releaseStateStore interface {
+	// read returns the object's contents, or false if it doesn't exist
+	read(ctx context.Context, name string) ([]byte, bool, error)
+
+	write(ctx context.Context, name string, contents []byte) error
+
+	// remove deletes the object, succeeding if it doesn't exist
+	remove(ctx context.Context, name string) error
+
+	// getLocation describes where the object is kept, for messages
+	getLocation(name string) string
+
+	// isShared is whether other machines can reach the store, in which case releases hold its lock while they run
+	isShared() bool
+}
+
+// releaseLock records who's releasing the repo, so that a second machine doesn't start a release at the same time
+type releaseLock struct {
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+}
+
+// bucketCli is how a cloud provider's CLI reads, writes and removes the objects of a bucket
+type bucketCli struct {
+	binaryName string
+	installUrl string
+
+	getReadArgs   func(objectUrl string) []string
+	getWriteArgs  func(objectUrl string) []string
+	getRemoveArgs func(objectUrl string) []string
+
+	// Parts of the CLI's error output that mean the object doesn't exist
+	notFoundMarkers []string
+}
+
+var s3BucketCli = bucketCli{
+	binaryName: "aws",
+	installUrl: "https://aws.amazon.com/cli/",
+	getReadArgs: func(objectUrl string) []string {
+		return []string{"s3", "cp", "--only-show-errors", objectUrl, stdioObjectPath}
+	},
+	getWriteArgs: func(objectUrl string) []string {
+		return []string{"s3", "cp", "--only-show-errors", stdioObjectPath, objectUrl}
+	},
+	getRemoveArgs: func(objectUrl string) []string {
+		return []string{"s3", "rm", "--only-show-errors", objectUrl}
+	},
+	notFoundMarkers: []string{"(404)", "NoSuchKey"},
+}
+
+var gcsBucketCli = bucketCli{
+	binaryName: "gcloud",
+	installUrl: "https://cloud.google.com/sdk/docs/install",
+	getReadArgs: func(objectUrl string) []string {
+		return []string{"storage", "cat", objectUrl}
+	},
+	getWriteArgs: func(objectUrl string) []string {
+		return []string{"storage", "cp", stdioObjectPath, objectUrl}
+	},
+	getRemoveArgs: func(objectUrl string) []string {
+		return []string{"storage", "rm", objectUrl}
+	},
+	notFoundMarkers: []string{"matched no objects", "No URLs matched"},
+}
+
+// newReleaseStateStore returns the store that the release state config picks, which by default is the repo's version
+// control directory
+func newReleaseStateStore(metadataDirpath string, releaseStateConfig kudet_config.ReleaseStateConfig) releaseStateStore {
+	switch releaseStateConfig.Backend {
+	case kudet_config.S3ReleaseStateBackend:
+		return &bucketReleaseStateStore{cli: s3BucketCli, url: releaseStateConfig.Url}
+	case kudet_config.GcsReleaseStateBackend:
+		return &bucketReleaseStateStore{cli: gcsBucketCli, url: releaseStateConfig.Url}
+	default:
+		return &localReleaseStateStore{dirpath: metadataDirpath}
+	}
+}
+
+// acquireReleaseLock takes the store's release lock, failing if another release holds it. The buckets' CLIs have no
+// way of creating an object only if it doesn't exist, so two releases starting in the same instant could both get it;
+// the lock is for runs that overlap, like a retried CI job starting while a stuck one is still pushing.
+func acquireReleaseLock(ctx context.Context, store releaseStateStore) error {
+	lock, err := loadReleaseLock(ctx, store)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking whether another release holds the release lock")
+	}
+	if lock != nil {
+		return stacktrace.NewErrorWithCode(
+			ErrReleaseLocked.code,
+			"The release lock at '%s' has been held by %s since %s",
+			store.getLocation(releaseLockFilename),
+			lock.Holder,
+			lock.AcquiredAt.Format(time.RFC3339),
+		)
+	}
+	lock = &releaseLock{
+		Holder:     getReleaseLockHolder(),
+		AcquiredAt: time.Now(),
+	}
+	lockBytes, err := json.MarshalIndent(lock, "", releaseStateJsonIndent)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the release lock")
+	}
+	if err := store.write(ctx, releaseLockFilename, lockBytes); err != nil {
+		return stacktrace.Propagate(err, "An error occurred taking the release lock at '%s'", store.getLocation(releaseLockFilename))
+	}
+	return nil
+}
+
+// giveUpReleaseLock removes the store's release lock; failing to is logged rather than returned, because the release it
+// guarded has finished one way or another by then. It doesn't take a context since the lock must be given up even when
+// the release was cancelled.
+func giveUpReleaseLock(store releaseStateStore) {
+	if err := store.remove(context.Background(), releaseLockFilename); err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred giving up the release lock at '%s'; run 'kudet release-state unlock' once you've checked that no release is running, otherwise the next release will refuse to start:\n%v", store.getLocation(releaseLockFilename), err)
+	}
+}
+
+// loadReleaseLock returns the store's release lock, or nil if no release holds it
+func loadReleaseLock(ctx context.Context, store releaseStateStore) (*releaseLock, error) {
+	lockBytes, found, err := store.read(ctx, releaseLockFilename)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the release lock at '%s'", store.getLocation(releaseLockFilename))
+	}
+	if !found {
+		return nil, nil
+	}
+	lock := &releaseLock{}
+	if err := json.Unmarshal(lockBytes, lock); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the release lock at '%s'; if no release is running it can be removed with 'kudet release-state unlock'", store.getLocation(releaseLockFilename))
+	}
+	return lock, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// localReleaseStateStore keeps the objects as files in a directory, which is the repo's version control directory
+type localReleaseStateStore struct {
+	dirpath string
+}
+
+func (store *localReleaseStateStore) read(ctx context.Context, name string) ([]byte, bool, error) {
+	contents, err := os.ReadFile(store.getLocation(name))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred reading '%s'", store.getLocation(name))
+	}
+	return contents, true, nil
+}
+
+func (store *localReleaseStateStore) write(ctx context.Context, name string, contents []byte) error {
+	if err := os.WriteFile(store.getLocation(name), contents, releaseStateFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing '%s'", store.getLocation(name))
+	}
+	return nil
+}
+
+func (store *localReleaseStateStore) remove(ctx context.Context, name string) error {
+	if err := os.Remove(store.getLocation(name)); err != nil && !os.IsNotExist(err) {
+		return stacktrace.Propagate(err, "An error occurred removing '%s'", store.getLocation(name))
+	}
+	return nil
+}
+
+func (store *localReleaseStateStore) getLocation(name string) string {
+	return filepath.Join(store.dirpath, name)
+}
+
+func (store *localReleaseStateStore) isShared() bool {
+	return false
+}
+
+// bucketReleaseStateStore keeps the objects under a prefix of an S3 or GCS bucket, through the provider's CLI, which
+// takes care of authenticating like it does for everything else in the CI job
+type bucketReleaseStateStore struct {
+	cli bucketCli
+	url string
+}
+
+func (store *bucketReleaseStateStore) read(ctx context.Context, name string) ([]byte, bool, error) {
+	output, err := store.runCli(ctx, store.cli.getReadArgs(store.getLocation(name)), nil)
+	if err != nil && store.isNotFoundError(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred reading '%s'", store.getLocation(name))
+	}
+	return output, true, nil
+}
+
+func (store *bucketReleaseStateStore) write(ctx context.Context, name string, contents []byte) error {
+	if _, err := store.runCli(ctx, store.cli.getWriteArgs(store.getLocation(name)), contents); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing '%s'", store.getLocation(name))
+	}
+	return nil
+}
+
+func (store *bucketReleaseStateStore) remove(ctx context.Context, name string) error {
+	if _, err := store.runCli(ctx, store.cli.getRemoveArgs(store.getLocation(name)), nil); err != nil && !store.isNotFoundError(err) {
+		return stacktrace.Propagate(err, "An error occurred removing '%s'", store.getLocation(name))
+	}
+	return nil
+}
+
+func (store *bucketReleaseStateStore) getLocation(name string) string {
+	return strings.TrimSuffix(store.url, "/") + "/" + name
+}
+
+func (store *bucketReleaseStateStore) isShared() bool {
+	return true
+}
+
+func (store *bucketReleaseStateStore) runCli(ctx context.Context, args []string, stdin []byte) ([]byte, error) {
+	cliCmd := exec.CommandContext(ctx, store.cli.binaryName, args...)
+	if stdin != nil {
+		cliCmd.Stdin = bytes.NewReader(stdin)
+	}
+	stderr := &bytes.Buffer{}
+	cliCmd.Stderr = stderr
+	logrus.Debugf("Running '%s'", cliCmd.String())
+	output, err := cliCmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.Error); ok {
+			return nil, stacktrace.Propagate(err, "The '%s' CLI, which the release state is kept with, couldn't be run; install it from %s", store.cli.binaryName, store.cli.installUrl)
+		}
+		return nil, stacktrace.Propagate(err, "'%s' failed with error output:\n%s", cliCmd.String(), strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+func (store *bucketReleaseStateStore) isNotFoundError(err error) bool {
+	for _, notFoundMarker := range store.cli.notFoundMarkers {
+		if strings.Contains(err.Error(), notFoundMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// getReleaseLockHolder describes the machine and process releasing, for whoever finds the lock held
+func getReleaseLockHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "an unknown host"
+	}
+	return fmt.Sprintf("process %d on %s", os.Getpid(), hostname)
+}
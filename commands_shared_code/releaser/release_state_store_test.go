@@ -0,0 +1,84 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+// Stands in for 'aws s3', keeping the bucket's objects as files named after the objects
+const fakeAwsCli = `#!/bin/sh
+object() { echo "$FAKE_BUCKET_DIRPATH/${1##*/}"; }
+case "$2" in
+  cp)
+    if [ "$4" = "-" ]; then
+      cat > "$(object "$5")"
+    elif [ -f "$(object "$4")" ]; then
+      cat "$(object "$4")"
+    else
+      echo "fatal error: An error occurred (404) when calling the HeadObject operation: Not Found" >&2
+      exit 1
+    fi ;;
+  rm) rm -f "$(object "$4")" ;;
+esac
+`
+
+func TestLocalReleaseStateStore(t *testing.T) {
+	ctx := context.Background()
+	store := newReleaseStateStore(t.TempDir(), kudet_config.ReleaseStateConfig{})
+	require.False(t, store.isShared())
+
+	state, err := loadReleaseState(ctx, store)
+	require.NoError(t, err)
+	require.Nil(t, state)
+
+	require.NoError(t, saveReleaseState(ctx, store, &releaseState{Version: "1.2.3", ReleaseCommitHash: "cccc"}))
+	state, err = loadReleaseState(ctx, store)
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3", state.Version)
+
+	require.NoError(t, removeReleaseState(ctx, store))
+	require.NoError(t, removeReleaseState(ctx, store))
+	state, err = loadReleaseState(ctx, store)
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
+
+func TestBucketReleaseStateStore_StateAndLock(t *testing.T) {
+	binDirpath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDirpath, s3BucketCli.binaryName), []byte(fakeAwsCli), 0755))
+	t.Setenv("PATH", binDirpath+string(os.PathListSeparator)+os.Getenv("PATH"))
+	bucketDirpath := t.TempDir()
+	t.Setenv("FAKE_BUCKET_DIRPATH", bucketDirpath)
+
+	ctx := context.Background()
+	store := newReleaseStateStore("", kudet_config.ReleaseStateConfig{
+		Backend: kudet_config.S3ReleaseStateBackend,
+		Url:     "s3://ci-state/kudet/my-repo/",
+	})
+	require.True(t, store.isShared())
+	require.Equal(t, "s3://ci-state/kudet/my-repo/kudet-release-state.json", store.getLocation(releaseStateFilename))
+
+	state, err := loadReleaseState(ctx, store)
+	require.NoError(t, err)
+	require.Nil(t, state)
+	require.NoError(t, saveReleaseState(ctx, store, &releaseState{Version: "1.2.3", ReleaseCommitHash: "cccc"}))
+	require.FileExists(t, filepath.Join(bucketDirpath, releaseStateFilename))
+	state, err = loadReleaseState(ctx, store)
+	require.NoError(t, err)
+	require.Equal(t, "cccc", state.ReleaseCommitHash)
+
+	require.NoError(t, acquireReleaseLock(ctx, store))
+	err = acquireReleaseLock(ctx, store)
+	require.Error(t, err)
+	releaseErr, found := GetReleaseError(err)
+	require.True(t, found)
+	require.Equal(t, ErrReleaseLocked, releaseErr)
+	giveUpReleaseLock(store)
+	require.NoFileExists(t, filepath.Join(bucketDirpath, releaseLockFilename))
+	require.NoError(t, acquireReleaseLock(ctx, store))
+}
@@ -0,0 +1,98 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+// releaseTagPushFailingRepository fails the push of the bare release tag, like a network drop after the branch push
+type releaseTagPushFailingRepository struct {
+	vcs.Repository
+	releaseTagRefSpec string
+}
+
+func (repository *releaseTagPushFailingRepository) Push(ctx context.Context, refSpecs ...string) error {
+	for _, refSpec := range refSpecs {
+		if refSpec == repository.releaseTagRefSpec {
+			return stacktrace.NewError("The connection to origin dropped")
+		}
+	}
+	return repository.Repository.Push(ctx, refSpecs...)
+}
+
+func TestRelease_KeepsStateWhenReleaseTagPushFails(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	originDirpath := t.TempDir()
+	_, err = git.PlainInit(originDirpath, true)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{originDirpath}})
+	require.NoError(t, err)
+	xdgConfigDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(xdgConfigDirpath, "git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(xdgConfigDirpath, "git", "config"), []byte("[user]\n\tname = Kudet\n\temail = kudet@example.com\n"), 0644))
+	t.Setenv("XDG_CONFIG_HOME", xdgConfigDirpath)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte("# TBD\n### Fixes\n* Fixed the module\n\n# 0.1.0\n* Initial release\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, ".pre-release-scripts.txt"), []byte(""), 0644))
+	initialCommitHash, err := repository.CommitAll("Initial commit", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.0", initialCommitHash, "0.1.0"))
+	branchName, _, err := repository.GetCheckedOutBranchName()
+	require.NoError(t, err)
+	require.NoError(t, gitRepository.Push(&git.PushOptions{RemoteName: vcs.OriginRemoteName, RefSpecs: []config.RefSpec{config.RefSpec("refs/heads/" + branchName + ":refs/heads/" + branchName), "refs/tags/*:refs/tags/*"}}))
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.ReleaseBranch = branchName
+	kudetConfig.ChangelogFilepath = "changelog.md"
+	openFailingRepository := func(repoDirpath string, token string) (vcs.Repository, error) {
+		repository, err := vcs.OpenRepository(repoDirpath, token)
+		if err != nil {
+			return nil, err
+		}
+		return &releaseTagPushFailingRepository{Repository: repository, releaseTagRefSpec: "refs/tags/0.1.1:refs/tags/0.1.1"}, nil
+	}
+	failingReleaser := NewReleaser(repoDirpath, "token", WithKudetConfig(kudetConfig), WithConfirmer(AlwaysConfirm), WithCommitHooksSkipped(true), WithRepositoryOpener(openFailingRepository))
+	require.Error(t, failingReleaser.Release(context.Background()))
+
+	// The release commit landed on origin, so it's kept, tags and state and all, for a re-run to finish
+	stateStore := newReleaseStateStore(repository.GetMetadataDirpath(), kudetConfig.ReleaseState)
+	state, err := loadReleaseState(context.Background(), stateStore)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	require.Equal(t, "0.1.1", state.Version)
+	headCommitHash, err := repository.GetHeadCommitHash()
+	require.NoError(t, err)
+	require.Equal(t, state.ReleaseCommitHash, headCommitHash)
+	remoteRefHashes, err := repository.ListRemoteRefs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, state.ReleaseCommitHash, remoteRefHashes["refs/heads/"+branchName])
+	require.Contains(t, remoteRefHashes, "refs/tags/v0.1.1")
+	require.NotContains(t, remoteRefHashes, "refs/tags/0.1.1")
+	for _, tagName := range []string{"0.1.1", "v0.1.1"} {
+		_, found, err := repository.GetTagCommitHash(tagName)
+		require.NoError(t, err)
+		require.True(t, found, tagName)
+	}
+
+	resumingReleaser := NewReleaser(repoDirpath, "token", WithKudetConfig(kudetConfig), WithConfirmer(AlwaysConfirm), WithCommitHooksSkipped(true))
+	require.NoError(t, resumingReleaser.Release(context.Background()))
+	remoteRefHashes, err = repository.ListRemoteRefs(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, remoteRefHashes, "refs/tags/0.1.1")
+	state, err = loadReleaseState(context.Background(), stateStore)
+	require.NoError(t, err)
+	require.Nil(t, state)
+}
@@ -0,0 +1,388 @@
+package releaser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	visualEditorEnvVar = "VISUAL"
+	editorEnvVar       = "EDITOR"
+	// Used when neither of the above is set, as git does
+	defaultEditor = "vi"
+
+	wizardEntriesFilePattern = "kudet-release-entries-*.md"
+	editedChangelogFileMode  = 0644
+
+	wizardIndent = "  "
+
+	// Marks the choice that enter picks, when choosing with the arrow keys
+	highlightedChoiceMarker = "> "
+
+	// newReleaseWizard's terminalFd when the input isn't a terminal
+	noTerminalFd = -1
+
+	// Keys as they arrive from a terminal in raw mode; the arrow keys are escape sequences, e.g. ESC [ A for up
+	ctrlCKey            = 0x03
+	escapeKey           = 0x1b
+	escapeSequenceStart = '['
+	// Sent instead of escapeSequenceStart by terminals in application cursor mode
+	escapeSequenceStartInApplicationMode = 'O'
+	upArrowKeySuffix                     = 'A'
+	downArrowKeySuffix                   = 'B'
+	carriageReturnKey                    = '\r'
+	lineFeedKey                          = '\n'
+
+	// ANSI escape codes for redrawing the choices in place
+	clearLineCode      = "\r\x1b[2K"
+	cursorUpCodeFormat = "\x1b[%dA"
+)
+
+// releaseWizard walks the operator through a release on the terminal: reviewing and editing the changelog's unreleased
+// entries, choosing the bump, and previewing the changes before confirming them. It reads all of the operator's input,
+// confirmations included, so nothing else reads from under it.
+type releaseWizard struct {
+	reader *bufio.Reader
+	out    io.Writer
+	// The input's file descriptor when it's a terminal, so the bump can be chosen with the arrow keys; when it isn't,
+	// e.g. input is piped in, the choices are numbered instead
+	terminalFd int
+}
+
+// bumpChoice is one of the versions the operator can bump to
+type bumpChoice struct {
+	bumpType string
+	version  semver.Version
+}
+
+func newReleaseWizard(input io.Reader, out io.Writer) *releaseWizard {
+	terminalFd := noTerminalFd
+	if inputFile, ok := input.(*os.File); ok && term.IsTerminal(int(inputFile.Fd())) {
+		terminalFd = int(inputFile.Fd())
+	}
+	return &releaseWizard{
+		reader:     bufio.NewReader(input),
+		out:        out,
+		terminalFd: terminalFd,
+	}
+}
+
+// reviewChangelog shows the unreleased entries of the changelog, offering to edit them in the operator's editor until
+// they're happy with them; the edits are left in the worktree to be released with the changelog
+func (wizard *releaseWizard) reviewChangelog(ctx context.Context, changelogFilepath string) error {
+	for {
+		changelogFile, err := os.ReadFile(changelogFilepath)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred reading the changelog at '%s'", changelogFilepath)
+		}
+		entriesLines, err := getUnreleasedNotesLines(changelogFile)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting the unreleased entries of the changelog")
+		}
+		fmt.Fprintln(wizard.out, i18n.Sprintf(i18n.WizardUnreleasedEntries, changelogFilepath))
+		for _, line := range entriesLines {
+			fmt.Fprintf(wizard.out, "%s%s\n", wizardIndent, line)
+		}
+		shouldEdit, err := wizard.askYesNo(i18n.Sprintf(i18n.WizardEditEntriesPrompt))
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred asking whether to edit the unreleased entries")
+		}
+		if !shouldEdit {
+			return nil
+		}
+		editedChangelogFile, err := editChangelogTbdSection(ctx, changelogFile)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred editing the unreleased entries of the changelog")
+		}
+		if err := os.WriteFile(changelogFilepath, editedChangelogFile, editedChangelogFileMode); err != nil {
+			return stacktrace.Propagate(err, "An error occurred writing the edited entries to the changelog at '%s'", changelogFilepath)
+		}
+	}
+}
+
+// chooseNextVersion asks the operator which part of the previous version to bump, defaulting to the changelog's
+// suggestion; smaller bumps aren't offered, since they'd hide the breaking changes that the changelog lists
+func (wizard *releaseWizard) chooseNextVersion(previousVersion *semver.Version, suggestedVersion semver.Version) (semver.Version, error) {
+	choices := []bumpChoice{}
+	for _, choice := range []bumpChoice{
+		{bumpType: patchBumpType, version: previousVersion.IncPatch()},
+		{bumpType: minorBumpType, version: previousVersion.IncMinor()},
+		{bumpType: majorBumpType, version: previousVersion.IncMajor()},
+	} {
+		if !choice.version.LessThan(&suggestedVersion) {
+			choices = append(choices, choice)
+		}
+	}
+	fmt.Fprintln(wizard.out, i18n.Sprintf(i18n.WizardChooseBump, previousVersion.String()))
+	// The suggestion is always the smallest choice
+	suggestedChoiceNumber := 1
+	choiceLabels := []string{}
+	for i, choice := range choices {
+		choiceLabel := fmt.Sprintf("%s (%s)", choice.version.String(), choice.bumpType)
+		if i+1 == suggestedChoiceNumber {
+			choiceLabel = fmt.Sprintf("%s - %s", choiceLabel, i18n.Sprintf(i18n.WizardSuggestedBump))
+		}
+		choiceLabels = append(choiceLabels, choiceLabel)
+	}
+	if wizard.terminalFd != noTerminalFd {
+		chosenIndex, err := wizard.pickChoiceOnTerminal(choiceLabels, suggestedChoiceNumber-1)
+		if err != nil {
+			return semver.Version{}, stacktrace.Propagate(err, "An error occurred reading the choice of bump")
+		}
+		return choices[chosenIndex].version, nil
+	}
+	for i, choiceLabel := range choiceLabels {
+		fmt.Fprintf(wizard.out, "%s%d) %s\n", wizardIndent, i+1, choiceLabel)
+	}
+	for {
+		answer, err := wizard.ask(i18n.Sprintf(i18n.WizardChooseBumpPrompt, suggestedChoiceNumber))
+		if err != nil {
+			return semver.Version{}, stacktrace.Propagate(err, "An error occurred reading the choice of bump")
+		}
+		if answer == "" {
+			return choices[suggestedChoiceNumber-1].version, nil
+		}
+		for i, choice := range choices {
+			if answer == strconv.Itoa(i+1) || strings.EqualFold(answer, choice.bumpType) {
+				return choice.version, nil
+			}
+		}
+		fmt.Fprintln(wizard.out, i18n.Sprintf(i18n.WizardUnrecognizedBumpChoice, answer))
+	}
+}
+
+// confirmRelease previews the changes that releasing the version will make, then asks the operator to confirm them
+func (wizard *releaseWizard) confirmRelease(version string, changesPreviewLines []string) (bool, error) {
+	fmt.Fprintln(wizard.out, i18n.Sprintf(i18n.WizardPreviewChanges, version))
+	for _, line := range changesPreviewLines {
+		fmt.Fprintf(wizard.out, "%s%s\n", wizardIndent, line)
+	}
+	isConfirmed, err := wizard.askYesNo(i18n.Sprintf(i18n.ConfirmPrompt, i18n.Sprintf(i18n.ConfirmReleaseQuestion, version)))
+	if err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred reading the answer to the release confirmation prompt")
+	}
+	return isConfirmed, nil
+}
+
+// confirm is the Confirmer of releases walked through by the wizard, asking on the wizard's own input so that the
+// confirmations and the wizard's questions don't compete for the operator's answers
+func (wizard *releaseWizard) confirm(ctx context.Context, question string) (bool, error) {
+	return wizard.askYesNo(i18n.Sprintf(i18n.ConfirmPrompt, question))
+}
+
+// getReleaseChangesPreviewLines describes what releasing the version will change in the repo: the diff that finalizing
+// the changelog makes to its top, and the version files that will be bumped
+func getReleaseChangesPreviewLines(repoDirpath string, changelogRelFilepath string, changelogMaxLineLength int, tbdSkeletonLines []string, repoVersionFiles []kudet_config.VersionFileConfig, version string) ([]string, error) {
+	changelogFilepath := filepath.Join(repoDirpath, changelogRelFilepath)
+	changelogFile, err := os.ReadFile(changelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the changelog at '%s'", changelogFilepath)
+	}
+	finalizedChangelog, err := finalizeSimulatedChangelog(changelogFile, version, tbdSkeletonLines, changelogMaxLineLength)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred finalizing a copy of the changelog for the preview")
+	}
+	// Finalizing only changes the changelog above the previous version's header, which is the first one until then
+	previewLines := []string{fmt.Sprintf("%s:", changelogRelFilepath)}
+	for _, diffLine := range diffNotesLines(getChangelogTbdSectionLines(changelogFile), getChangelogHeadLines(finalizedChangelog)) {
+		previewLines = append(previewLines, wizardIndent+diffLine)
+	}
+	for _, versionFile := range repoVersionFiles {
+		if versionFile.BumpedByScripts {
+			previewLines = append(previewLines, fmt.Sprintf("%s: bumped to '%s' by the pre-release scripts", versionFile.Filepath, version))
+			continue
+		}
+		previewLines = append(previewLines, fmt.Sprintf("%s: bumped to '%s'", versionFile.Filepath, version))
+	}
+	return previewLines, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (wizard *releaseWizard) ask(question string) (string, error) {
+	fmt.Fprintf(wizard.out, "%s ", question)
+	line, err := wizard.reader.ReadString('\n')
+	if err != nil && !(err == io.EOF && line != "") {
+		return "", stacktrace.Propagate(err, "An error occurred reading from the input")
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// askYesNo asks a y/N question, re-asking on anything it doesn't understand
+func (wizard *releaseWizard) askYesNo(question string) (bool, error) {
+	for {
+		answer, err := wizard.ask(question)
+		if err != nil {
+			return false, stacktrace.Propagate(err, "An error occurred reading the answer to '%s'", question)
+		}
+		normalizedAnswer := strings.ToLower(answer)
+		if affirmativeAnswers[normalizedAnswer] {
+			return true, nil
+		}
+		if negativeAnswers[normalizedAnswer] {
+			return false, nil
+		}
+		fmt.Fprintln(wizard.out, i18n.Sprintf(i18n.UnrecognizedConfirmAnswer, answer))
+	}
+}
+
+// pickChoiceOnTerminal lets the operator pick one of the choices with the arrow keys, putting the terminal in raw mode
+// meanwhile so that keys arrive as they're pressed rather than a line at a time
+func (wizard *releaseWizard) pickChoiceOnTerminal(choiceLabels []string, defaultIndex int) (int, error) {
+	previousTerminalState, err := term.MakeRaw(wizard.terminalFd)
+	if err != nil {
+		return 0, stacktrace.Propagate(err, "An error occurred putting the terminal in raw mode")
+	}
+	defer term.Restore(wizard.terminalFd, previousTerminalState)
+	return wizard.pickChoice(choiceLabels, defaultIndex)
+}
+
+// pickChoice moves the highlight between the choices on the up and down arrow keys until enter picks the highlighted
+// one, redrawing the choices in place; output is written as for a terminal in raw mode, where newlines don't return
+func (wizard *releaseWizard) pickChoice(choiceLabels []string, defaultIndex int) (int, error) {
+	fmt.Fprintf(wizard.out, "%s\r\n", i18n.Sprintf(i18n.WizardChooseBumpWithArrowKeys))
+	highlightedIndex := defaultIndex
+	wizard.drawChoices(choiceLabels, highlightedIndex)
+	for {
+		key, err := wizard.reader.ReadByte()
+		if err != nil {
+			return 0, stacktrace.Propagate(err, "An error occurred reading from the input")
+		}
+		switch key {
+		case carriageReturnKey, lineFeedKey:
+			return highlightedIndex, nil
+		case ctrlCKey:
+			// Raw mode keeps ctrl-c from interrupting us, so it's up to us to stop
+			return 0, stacktrace.NewError("The choice was interrupted")
+		case escapeKey:
+			escapeSequence := make([]byte, 2)
+			if _, err := io.ReadFull(wizard.reader, escapeSequence); err != nil {
+				return 0, stacktrace.Propagate(err, "An error occurred reading from the input")
+			}
+			if escapeSequence[0] != escapeSequenceStart && escapeSequence[0] != escapeSequenceStartInApplicationMode {
+				continue
+			}
+			switch {
+			case escapeSequence[1] == upArrowKeySuffix && highlightedIndex > 0:
+				highlightedIndex--
+			case escapeSequence[1] == downArrowKeySuffix && highlightedIndex < len(choiceLabels)-1:
+				highlightedIndex++
+			default:
+				continue
+			}
+			fmt.Fprintf(wizard.out, cursorUpCodeFormat, len(choiceLabels))
+			wizard.drawChoices(choiceLabels, highlightedIndex)
+		}
+	}
+}
+
+func (wizard *releaseWizard) drawChoices(choiceLabels []string, highlightedIndex int) {
+	for i, choiceLabel := range choiceLabels {
+		marker := strings.Repeat(" ", len(highlightedChoiceMarker))
+		if i == highlightedIndex {
+			marker = highlightedChoiceMarker
+		}
+		fmt.Fprintf(wizard.out, "%s%s%s%s\r\n", clearLineCode, wizardIndent, marker, choiceLabel)
+	}
+}
+
+// editChangelogTbdSection opens what's between the changelog's TBD header and its first version header in the
+// operator's editor, returning the changelog with the edited section in its place
+func editChangelogTbdSection(ctx context.Context, changelogFile []byte) ([]byte, error) {
+	lines := bytes.Split(changelogFile, []byte("\n"))
+	tbdHeaderIndex := -1
+	sectionEndIndex := len(lines)
+	for i, line := range lines {
+		if tbdHeaderIndex == -1 {
+			if versionToBeReleasedPlaceholderHeaderRegex.Match(line) {
+				tbdHeaderIndex = i
+			}
+			continue
+		}
+		if versionHeaderRegex.Match(line) {
+			sectionEndIndex = i
+			break
+		}
+	}
+	if tbdHeaderIndex == -1 {
+		return nil, stacktrace.NewErrorWithCode(ErrChangelogInvalid.code, "The changelog has no '%s' header to edit the entries under", versionToBeReleasedPlaceholderHeaderStr)
+	}
+
+	entriesFile, err := os.CreateTemp("", wizardEntriesFilePattern)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred creating a file to edit the entries in")
+	}
+	entriesFilepath := entriesFile.Name()
+	defer os.Remove(entriesFilepath)
+	// Editors expect files to end with a newline, which is dropped again when the edited entries are read back
+	_, err = entriesFile.Write(append(bytes.Join(lines[tbdHeaderIndex+1:sectionEndIndex], []byte("\n")), '\n'))
+	if closeErr := entriesFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred writing the entries to '%s'", entriesFilepath)
+	}
+	if err := runEditor(ctx, entriesFilepath); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred editing the entries in '%s'", entriesFilepath)
+	}
+	editedEntries, err := os.ReadFile(entriesFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the edited entries from '%s'", entriesFilepath)
+	}
+
+	editedLines := append([][]byte{}, lines[:tbdHeaderIndex+1]...)
+	editedLines = append(editedLines, bytes.Split(bytes.TrimSuffix(editedEntries, []byte("\n")), []byte("\n"))...)
+	editedLines = append(editedLines, lines[sectionEndIndex:]...)
+	return bytes.Join(editedLines, []byte("\n")), nil
+}
+
+// runEditor opens the file in the editor that '$VISUAL' or '$EDITOR' names, which may include arguments (e.g.
+// 'code --wait'), waiting for the operator to close it
+func runEditor(ctx context.Context, editedFilepath string) error {
+	editor := os.Getenv(visualEditorEnvVar)
+	if editor == "" {
+		editor = os.Getenv(editorEnvVar)
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+	editorArgs := strings.Fields(editor)
+	editorCmd := exec.CommandContext(ctx, editorArgs[0], append(editorArgs[1:], editedFilepath)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return stacktrace.Propagate(err, "Editor '%s' failed; set '%s' or '%s' to the editor to use", editor, visualEditorEnvVar, editorEnvVar)
+	}
+	return nil
+}
+
+// getChangelogTbdSectionLines returns the changelog's lines down to, but not including, its first version header, like
+// getChangelogHeadLines does for a finalized changelog
+func getChangelogTbdSectionLines(changelog []byte) []string {
+	lines := []string{}
+	scanner := newChangelogScanner(changelog)
+	for scanner.Scan() {
+		if versionHeaderRegex.Match(scanner.Bytes()) {
+			break
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return normalizeNotesLines(lines)
+}
@@ -0,0 +1,156 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+const wizardTestChangelog = `# TBD
+### Features
+* Added the thing
+
+# 0.1.0
+* Initial release
+`
+
+func TestReleaseWizard_ChooseNextVersion(t *testing.T) {
+	previousVersion := semver.MustParse("1.2.3")
+
+	// Pressing ENTER takes the changelog's suggestion
+	out := &bytes.Buffer{}
+	version, err := newReleaseWizard(strings.NewReader("\n"), out).chooseNextVersion(previousVersion, previousVersion.IncPatch())
+	require.NoError(t, err)
+	require.Equal(t, "1.2.4", version.String())
+	require.Contains(t, out.String(), "1) 1.2.4 (patch)")
+	require.Contains(t, out.String(), "3) 2.0.0 (major)")
+
+	// Choices are picked by number or by bump type, re-asking on anything else
+	version, err = newReleaseWizard(strings.NewReader("2\n"), &bytes.Buffer{}).chooseNextVersion(previousVersion, previousVersion.IncPatch())
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0", version.String())
+	version, err = newReleaseWizard(strings.NewReader("huge\nMAJOR\n"), &bytes.Buffer{}).chooseNextVersion(previousVersion, previousVersion.IncPatch())
+	require.NoError(t, err)
+	require.Equal(t, "2.0.0", version.String())
+}
+
+func TestReleaseWizard_ChooseNextVersionDoesNotOfferSmallerBumps(t *testing.T) {
+	previousVersion := semver.MustParse("1.2.3")
+	out := &bytes.Buffer{}
+	version, err := newReleaseWizard(strings.NewReader("patch\n1\n"), out).chooseNextVersion(previousVersion, previousVersion.IncMinor())
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0", version.String())
+	require.NotContains(t, out.String(), "1.2.4")
+}
+
+func TestReleaseWizard_PickChoiceWithArrowKeys(t *testing.T) {
+	choiceLabels := []string{"1.2.4 (patch)", "1.3.0 (minor)", "2.0.0 (major)"}
+
+	// Down twice, past the last choice, then back up once
+	out := &bytes.Buffer{}
+	chosenIndex, err := newReleaseWizard(strings.NewReader("\x1b[B\x1b[B\x1b[B\x1b[A\r"), out).pickChoice(choiceLabels, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, chosenIndex)
+	require.Contains(t, out.String(), "> 1.2.4 (patch)")
+	require.Contains(t, out.String(), "> 2.0.0 (major)")
+
+	// Enter takes the default, and up from the first choice stays put; terminals in application mode send ESC O A
+	chosenIndex, err = newReleaseWizard(strings.NewReader("\x1bOA\r"), &bytes.Buffer{}).pickChoice(choiceLabels, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, chosenIndex)
+
+	_, err = newReleaseWizard(strings.NewReader("\x1b[B\x03"), &bytes.Buffer{}).pickChoice(choiceLabels, 0)
+	require.Error(t, err)
+}
+
+func TestWithInteractiveWizard_ConfirmsOnTheWizardInput(t *testing.T) {
+	out := &bytes.Buffer{}
+	releaser := NewReleaser(t.TempDir(), "token", WithInteractiveWizard(strings.NewReader("y\n2\n"), out))
+
+	// Both read from the one input, so neither takes the other's answer
+	isConfirmed, err := releaser.confirmer(context.Background(), "Release new version 'cli/v1.1.0'?")
+	require.NoError(t, err)
+	require.True(t, isConfirmed)
+	require.Contains(t, out.String(), "Release new version 'cli/v1.1.0'? [y/N]")
+	previousVersion := semver.MustParse("1.2.3")
+	version, err := releaser.wizard.chooseNextVersion(previousVersion, previousVersion.IncPatch())
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0", version.String())
+}
+
+func TestReleaseWizard_ReviewChangelogEditsEntries(t *testing.T) {
+	t.Setenv(visualEditorEnvVar, "")
+	t.Setenv(editorEnvVar, "sed -i s/thing/widget/")
+	changelogFilepath := filepath.Join(t.TempDir(), "changelog.md")
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte(wizardTestChangelog), 0644))
+
+	out := &bytes.Buffer{}
+	require.NoError(t, newReleaseWizard(strings.NewReader("y\nn\n"), out).reviewChangelog(context.Background(), changelogFilepath))
+	changelogFile, err := os.ReadFile(changelogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, strings.Replace(wizardTestChangelog, "thing", "widget", 1), string(changelogFile))
+	// The entries are shown again after editing
+	require.Contains(t, out.String(), "* Added the widget")
+}
+
+func TestReleaseWizard_ReviewChangelogLeavesUneditedChangelogAlone(t *testing.T) {
+	changelogFilepath := filepath.Join(t.TempDir(), "changelog.md")
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte(wizardTestChangelog), 0644))
+
+	require.NoError(t, newReleaseWizard(strings.NewReader("\n"), &bytes.Buffer{}).reviewChangelog(context.Background(), changelogFilepath))
+	changelogFile, err := os.ReadFile(changelogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, wizardTestChangelog, string(changelogFile))
+}
+
+func TestEditChangelogTbdSection_UnchangedByNoOpEditor(t *testing.T) {
+	t.Setenv(visualEditorEnvVar, "true")
+	editedChangelogFile, err := editChangelogTbdSection(context.Background(), []byte(wizardTestChangelog))
+	require.NoError(t, err)
+	require.Equal(t, wizardTestChangelog, string(editedChangelogFile))
+}
+
+func TestEditChangelogTbdSection_FailingEditorIsAnError(t *testing.T) {
+	t.Setenv(visualEditorEnvVar, "false")
+	_, err := editChangelogTbdSection(context.Background(), []byte(wizardTestChangelog))
+	require.Error(t, err)
+}
+
+func TestGetReleaseChangesPreviewLines(t *testing.T) {
+	repoDirpath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte(wizardTestChangelog), 0644))
+	versionFiles := []kudet_config.VersionFileConfig{
+		{Filepath: "version.txt"},
+		{Filepath: "package.json", BumpedByScripts: true},
+	}
+
+	previewLines, err := getReleaseChangesPreviewLines(repoDirpath, "changelog.md", changelog.DefaultMaxLineLength, nil, versionFiles, "0.2.0")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"changelog.md:",
+		"  + ",
+		"  + # 0.2.0",
+		"version.txt: bumped to '0.2.0'",
+		"package.json: bumped to '0.2.0' by the pre-release scripts",
+	}, previewLines)
+}
+
+func TestReleaseWizard_ConfirmRelease(t *testing.T) {
+	out := &bytes.Buffer{}
+	isConfirmed, err := newReleaseWizard(strings.NewReader("maybe\ny\n"), out).confirmRelease("0.2.0", []string{"changelog.md:", "  + # 0.2.0"})
+	require.NoError(t, err)
+	require.True(t, isConfirmed)
+	require.Contains(t, out.String(), "+ # 0.2.0")
+
+	isConfirmed, err = newReleaseWizard(strings.NewReader("\n"), &bytes.Buffer{}).confirmRelease("0.2.0", nil)
+	require.NoError(t, err)
+	require.False(t, isConfirmed)
+}
@@ -0,0 +1,1462 @@
+package releaser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/telemetry"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	originRemoteName = vcs.OriginRemoteName
+
+	tagsPrefix = vcs.TagRefPrefix
+	headRef    = vcs.BranchRefPrefix
+	vPrefix    = "v"
+
+	expectedNumTBDHeaderLines         = 1
+	versionToBeReleasedPlaceholderStr = changelog.UnreleasedVersionName
+	sectionHeaderPrefix               = "#"
+	noPreviousVersion                 = "0.0.0"
+	semverPatternStr                  = changelog.SemverPattern
+	semverRegexStr                    = "^" + semverPatternStr + "$"
+
+	releaseCommitDateFormat = "2006-01-02"
+	changelogFileMode       = 0644
+)
+
+var (
+	versionToBeReleasedPlaceholderHeaderStr      = fmt.Sprintf("%s %s", sectionHeaderPrefix, versionToBeReleasedPlaceholderStr)
+	versionToBeReleasedPlaceholderHeaderRegexStr = fmt.Sprintf("^%s\\s*%s\\s*$", sectionHeaderPrefix, versionToBeReleasedPlaceholderStr)
+	versionHeaderRegexStr                        = fmt.Sprintf("^%s\\s*%s\\s*$", sectionHeaderPrefix, semverPatternStr)
+	semverRegex                                  = regexp.MustCompile(semverRegexStr)
+	versionToBeReleasedPlaceholderHeaderRegex    = regexp.MustCompile(versionToBeReleasedPlaceholderHeaderRegexStr)
+	versionHeaderRegex                           = regexp.MustCompile(versionHeaderRegexStr)
+	emptyLineRegex                               = regexp.MustCompile("^\\s*$")
+)
+
+// changelogChanges summarizes the kinds of change listed under the TBD header, which decide the next version
+type changelogChanges struct {
+	hasBreakingChange bool
+	hasMajorChange    bool
+
+	// The configured changelog categories with entries
+	categories categoryChanges
+
+	// The TBD version that the kinds of change were found in
+	unreleased *changelog.Version
+}
+
+// parseChangeLogFile validates the changelog and looks for the kinds of change in its TBD version alone. Breaking and
+// major changes, like the rules' categories, are sections at any level, with entries under them or under their own
+// subsections; major changes are only detected if the rules have a regex for their header, and nil rules only detect
+// breaking changes.
+func parseChangeLogFile(changelogFile []byte, rules *bumpRules) (*changelogChanges, error) {
+	parsedChangelog, err := changelog.Parse(changelogFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the changelog")
+	}
+
+	// Check if TBD is the first non-empty line - this is for extra caution.
+	for _, entry := range parsedChangelog.Preamble {
+		if !entry.IsBlank() {
+			return nil, stacktrace.NewError("TBD header is either missing or is not the first non empty line in changelog.md")
+		}
+	}
+	// No TBD header was found because the file is empty.
+	if len(parsedChangelog.Versions) == 0 {
+		return nil, stacktrace.NewError("Empty changelog file, please check the filepath again.")
+	}
+	unreleasedVersion := parsedChangelog.Versions[0]
+	if !unreleasedVersion.IsUnreleased() {
+		return nil, stacktrace.NewError("TBD header is either missing or is not the first non empty line in changelog.md")
+	}
+
+	if len(parsedChangelog.Versions) < 2 {
+		return nil, stacktrace.NewError("No previous release versions were detected in this changelog. Are you sure that the changelog is in sync with the release tags on this branch?")
+	}
+	if parsedChangelog.Versions[1].IsUnreleased() {
+		return nil, stacktrace.NewError("Found more than %d TBD headers, there can only be #d TBD header in the changelog", expectedNumTBDHeaderLines)
+	}
+
+	// if the TBD header is directly followed by the version header, it means that changelog.md is empty for upcoming release.
+	if unreleasedVersion.IsEmpty() {
+		return nil, stacktrace.NewError("changelog.md is empty for the current release, please check if the changes are merged and changelog.md is updated correctly.")
+	}
+
+	changes := &changelogChanges{
+		hasBreakingChange: hasSectionWithEntries(unreleasedVersion.Sections, isBreakingChangesSection),
+		unreleased:        unreleasedVersion,
+	}
+	if rules == nil {
+		return changes, nil
+	}
+	if rules.majorChangesRegex != nil {
+		changes.hasMajorChange = hasSectionWithEntries(unreleasedVersion.Sections, func(section *changelog.Section) bool {
+			return rules.majorChangesRegex.MatchString(section.HeaderLine)
+		})
+	}
+	changes.categories = getCategoryChanges(unreleasedVersion, rules.categories)
+	return changes, nil
+}
+
+// Release cuts a new release of the repo, rolling back everything it can if any step fails
+func (releaser *Releaser) Release(ctx context.Context) error {
+	var err error
+	if releaser.isSandbox {
+		// Rehearsals aren't releases, so they're left out of the telemetry
+		releaser.telemetryRecorder = nil
+		err = releaser.releaseInSandbox(ctx)
+	} else {
+		releaser.telemetryRecorder = telemetry.NewRecorder(releaseTelemetryRunName)
+		err = releaser.release(ctx)
+	}
+	releaser.progressTracker.Finish(err)
+	releaser.exportReleaseTelemetry(err)
+	return err
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (releaser *Releaser) release(ctx context.Context) error {
+	releaser.startStep("Checks")
+	logrus.Infof("Starting release process...")
+	repoDirpath := releaser.repoDirpath
+
+	kudetConfig, err := releaser.getKudetConfig()
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the kudet config for the repo")
+	}
+	releaseBranchName := kudetConfig.ReleaseBranch
+	isMainTrackReleased, versionTracks, err := getVersionTracksToRelease(kudetConfig.VersionTracks, releaser.versionTrackNames)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the version tracks to release")
+	}
+	// Embargoes and bridges hold the release commit back, which the version tracks' tags have no record to wait on
+	if len(versionTracks) > 0 && (releaser.isEmbargoed || releaser.bridgeScriptFilepath != "") {
+		return stacktrace.NewError("Version tracks can't be released in embargoed or bridged releases; release them once the main version line is out")
+	}
+	if !isMainTrackReleased && (releaser.shouldBumpMajorVersion || releaser.isSecurityRelease) {
+		return stacktrace.NewError("Major bumps and security releases are only for the main version line, which isn't being released; add '%s' to the version tracks to release", kudet_config.MainVersionTrackName)
+	}
+
+	logrus.Infof("Retrieving repository information...")
+	repository, err := releaser.openRepository(repoDirpath, releaser.token)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	if err := releaser.authenticateAsGithubAppIfNeeded(ctx, repository, kudetConfig); err != nil {
+		return stacktrace.Propagate(err, "An error occurred authenticating as the kudet config's GitHub App")
+	}
+	author, err := repository.GetAuthor()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the author to make release commits as")
+	}
+	metadataDirpath := repository.GetMetadataDirpath()
+	if armoredSigningKey := os.Getenv(tagSigningKeyEnvVar); armoredSigningKey != "" {
+		if err := repository.SetTagSigningKey(armoredSigningKey); err != nil {
+			return stacktrace.Propagate(err, "An error occurred setting the key in '%s' to sign the release tags with", tagSigningKeyEnvVar)
+		}
+	}
+
+	securityAdvisorySeverity := ""
+	if releaser.isSecurityRelease {
+		// Not every forge supports advisories, which we make sure of before releasing anything
+		securityAdvisorySeverity, err = getSecurityAdvisorySeverity(releaser.securityAdvisorySeverity, kudetConfig.SecurityAdvisory)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred determining the severity of the security release")
+		}
+		releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+		if err != nil {
+			return stacktrace.Propagate(err, "Security releases draft a security advisory, so they need a remote on a known forge")
+		}
+		if !releaseForge.supportsSecurityAdvisories() {
+			return stacktrace.NewError("Security releases draft a security advisory, which %s doesn't support", releaseForge.getName())
+		}
+	}
+	if len(kudetConfig.Approval.Approvers) > 0 && !releaser.isSandbox {
+		releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+		if err != nil {
+			return stacktrace.Propagate(err, "Releases that need approval ask for it on an issue, so they need a remote on a known forge")
+		}
+		if !releaseForge.supportsApprovalIssues() {
+			return stacktrace.NewError("Releases that need approval ask for it on an issue, which %s doesn't support", releaseForge.getName())
+		}
+	}
+
+	// Promotions happen after the push, by which point a typo'd environment could only be logged
+	if _, err := getEnvironmentsToPromote(kudetConfig.Environments, releaser.promotedEnvironmentNames); err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the environments to promote the release to")
+	}
+
+	if releaser.shouldUploadMetadata {
+		releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+		if err != nil {
+			return stacktrace.Propagate(err, "Uploading the release metadata needs a remote on a known forge")
+		}
+		if !releaseForge.supportsReleaseAssets() {
+			return stacktrace.NewError("Uploading the release metadata needs release assets, which %s doesn't support", releaseForge.getName())
+		}
+	}
+	if releaser.shouldUploadArtifacts {
+		if releaser.metadataDirpath == "" {
+			return stacktrace.NewError("Uploading the pre release scripts' artifacts needs a metadata directory to collect them into")
+		}
+		releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+		if err != nil {
+			return stacktrace.Propagate(err, "Uploading the pre release scripts' artifacts needs a remote on a known forge")
+		}
+		if !releaseForge.supportsReleaseAssets() {
+			return stacktrace.NewError("Uploading the pre release scripts' artifacts needs release assets, which %s doesn't support", releaseForge.getName())
+		}
+	}
+
+	isPushDryRun := isDryRunStep(kudetConfig.DryRunSteps, kudet_config.PushDryRunStep)
+	if isPushDryRun && releaser.isEmbargoed {
+		return stacktrace.NewError("Embargoed releases are staged by pushing them under '%s', which the kudet config's '%s' rules out", embargoRefPrefix, kudet_config.DryRunStepsKey)
+	}
+
+	// A token that can't push would otherwise only be found out once the scripts have run and the release is committed
+	// and tagged locally; bridged releases are pushed by the bridge instead
+	if releaser.bridgeScriptFilepath == "" && !isPushDryRun {
+		logrus.Infof("Checking that the token can push to %s...", originRemoteName)
+		if err := repository.CheckPushAccess(ctx); err != nil {
+			return stacktrace.PropagateWithCode(err, ErrPushRejected.code, "The token can't be used to push the release to '%s'", originRemoteName)
+		}
+	}
+
+	logrus.Infof("Conducting pre release checks...")
+	// A previous run may have died partway through pushing, in which case we pick up where it left off
+	stateStore := newReleaseStateStore(metadataDirpath, kudetConfig.ReleaseState)
+	if stateStore.isShared() {
+		if err := acquireReleaseLock(ctx, stateStore); err != nil {
+			return stacktrace.Propagate(err, "Another release of the repo may be running")
+		}
+		defer giveUpReleaseLock(stateStore)
+	}
+	releaseStateLocation := stateStore.getLocation(releaseStateFilename)
+	inProgressReleaseState, err := loadReleaseState(ctx, stateStore)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking for an in-progress release")
+	}
+	if inProgressReleaseState != nil && inProgressReleaseState.IsEmbargoed {
+		return getEmbargoedReleaseError(inProgressReleaseState)
+	}
+	if inProgressReleaseState != nil && isPushDryRun {
+		return stacktrace.NewError("Found in-progress release of version '%s' recorded at '%s', which can't be resumed without pushing; run 'kudet release-state abort' to start over", inProgressReleaseState.Version, releaseStateLocation)
+	}
+	if inProgressReleaseState != nil {
+		logrus.Infof("Found in-progress release of version '%s' recorded at '%s'; resuming it...", inProgressReleaseState.Version, releaseStateLocation)
+		releaser.telemetryRecorder.SetAttribute(versionTelemetryAttribute, inProgressReleaseState.Version)
+		releaser.startStep("Push")
+		if err := resumeRelease(ctx, repository, releaseBranchName, inProgressReleaseState); err != nil {
+			return stacktrace.Propagate(err, "An error occurred resuming the in-progress release of version '%s'; if you'd rather start over, run 'kudet release-state abort' and reset the local branch", inProgressReleaseState.Version)
+		}
+		if err := removeReleaseState(ctx, stateStore); err != nil {
+			return stacktrace.Propagate(err, "Resumed release of version '%s' succeeded, but an error occurred cleaning up its release state", inProgressReleaseState.Version)
+		}
+		logrus.Infof("Release success.")
+		if err := releaser.waitOutUndoWindowIfNeeded(ctx, repository, kudetConfig, inProgressReleaseState.Version); err != nil {
+			return stacktrace.Propagate(err, "Resumed release of version '%s' was pushed, but didn't make it through its undo window", inProgressReleaseState.Version)
+		}
+		// The undo window and the steps before it can outlast a GitHub App's installation token
+		if err := releaser.authenticateAsGithubAppIfNeeded(ctx, repository, kudetConfig); err != nil {
+			return stacktrace.Propagate(err, "Release of version '%s' was pushed, but an error occurred authenticating as the kudet config's GitHub App for the post-release steps", inProgressReleaseState.Version)
+		}
+		releaser.startStep("Post-release")
+		mirrorsErr := releaser.pushReleaseToMirrors(ctx, repository, kudetConfig.Mirrors, releaseBranchName, inProgressReleaseState.Version)
+		releaser.runPostReleaseSteps(ctx, repository, kudetConfig, inProgressReleaseState)
+		if mirrorsErr != nil {
+			return stacktrace.Propagate(mirrorsErr, "Resumed release of version '%s' succeeded, but it didn't reach all of its mirrors", inProgressReleaseState.Version)
+		}
+		return nil
+	}
+
+	// Check no staged or unstaged changes exist on the branch before release, other than in the files that are allowed
+	// to be dirty
+	isClean, currWorktreeStatusStr, err := repository.GetStatus()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while trying to retrieve the status of the worktree of the repository.")
+	}
+	if !isClean && len(kudetConfig.AllowedDirtyPaths) == 0 {
+		return stacktrace.NewErrorWithCode(ErrDirtyWorktree.code, "The branch contains modified files. Please ensure the working tree is clean before attempting to release. Currently the status is '%s'\n", currWorktreeStatusStr)
+	}
+	if !isClean {
+		modifiedFilepaths, err := repository.GetModifiedFilepaths()
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred listing the modified files of the worktree")
+		}
+		disallowedDirtyFilepaths := getDisallowedDirtyFilepaths(modifiedFilepaths, kudetConfig.AllowedDirtyPaths)
+		if len(disallowedDirtyFilepaths) > 0 {
+			return stacktrace.NewErrorWithCode(ErrDirtyWorktree.code, "The branch contains modified files outside the allowed dirty paths: '%s'. Please ensure they're committed or reverted before attempting to release.", strings.Join(disallowedDirtyFilepaths, "', '"))
+		}
+		logrus.Infof("Releasing with uncommitted changes to allowed dirty paths, which will be committed with the release: '%s'", strings.Join(modifiedFilepaths, "', '"))
+	}
+
+	if err := checkIsNotOnTemporaryRef(repository, releaseBranchName); err != nil {
+		return stacktrace.Propagate(err, "The checked out branch can't be released")
+	}
+
+	// CI checkouts (e.g. GitHub's actions/checkout) are shallow and tagless by default, which would make us miss the
+	// previous release's tag
+	isShallow, err := repository.IsShallow()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking whether the repository is a shallow clone")
+	}
+	if isShallow {
+		logrus.Infof("Repository is a shallow clone; fetching its full history and tags...")
+		if err := repository.Unshallow(ctx); err != nil {
+			return stacktrace.Propagate(err, "An error occurred unshallowing the repository")
+		}
+	}
+
+	logrus.Infof("Fetching origin if needed...")
+	if err := fetchRemoteIfNeeded(ctx, repository, metadataDirpath, releaser.fetchPolicy, kudetConfig.FetchGracePeriod); err != nil {
+		return stacktrace.Propagate(err, "An error occurred fetching origin")
+	}
+
+	logrus.Infof("Checking that %s and %s are in sync...", releaseBranchName, originRemoteName)
+	// Check that local main and remote main are in sync
+	localMainBranchName := releaseBranchName
+	remoteMainBranchName := fmt.Sprintf("%v/%v", originRemoteName, releaseBranchName)
+	remoteMainHash, err := repository.ResolveRevision(remoteMainBranchName)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing revision '%v'", remoteMainBranchName)
+	}
+	if err := ensureLocalBranchForDetachedHead(repository, releaseBranchName, remoteMainHash); err != nil {
+		return stacktrace.Propagate(err, "An error occurred preparing to release from a detached HEAD")
+	}
+	localMainHash, err := repository.ResolveRevision(localMainBranchName)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing revision '%v'", localMainBranchName)
+	}
+	isLocalMainInSyncWithRemoteMain := localMainHash == remoteMainHash
+	if !isLocalMainInSyncWithRemoteMain {
+		return stacktrace.NewErrorWithCode(ErrOutOfSync.code, "The local '%s' branch is not in sync with the '%s' '%s' branch. Must be in sync to conduct release process.", releaseBranchName, originRemoteName, releaseBranchName)
+	}
+
+	// Checking out refuses to carry uncommitted changes over, including those to allowed dirty paths
+	checkedOutBranchName, isOnBranch, err := repository.GetCheckedOutBranchName()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the checked out branch")
+	}
+	if !isOnBranch || checkedOutBranchName != releaseBranchName {
+		logrus.Infof("Checking out %s branch...", releaseBranchName)
+		if err := repository.CheckoutBranch(releaseBranchName); err != nil {
+			return stacktrace.Propagate(err, "Missing required '%v' branch locally, or it can't be checked out over the uncommitted changes. Please run 'git checkout %v'", releaseBranchName, releaseBranchName)
+		}
+	}
+
+	if !isMainTrackReleased {
+		return releaser.releaseVersionTracks(ctx, repository, kudetConfig, author, versionTracks, remoteMainHash, isPushDryRun)
+	}
+
+	// Conduct changelog file validation
+	changelogFilepath := filepath.Join(repoDirpath, kudetConfig.ChangelogFilepath)
+	if releaser.wizard != nil {
+		// Edited entries go through the same validation as the rest of the changelog
+		if err := releaser.wizard.reviewChangelog(ctx, changelogFilepath); err != nil {
+			return stacktrace.Propagate(err, "An error occurred reviewing the changelog's unreleased entries")
+		}
+	}
+	changelogFile, err := os.ReadFile(changelogFilepath)
+
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to read changelog file at provided path. Are you sure '%s' exists?", changelogFilepath)
+	}
+
+	rules := newBumpRules(kudetConfig)
+	tbdSkeletonLines, err := readChangelogTbdSkeleton(repoDirpath, kudetConfig.ChangelogTbdSkeletonFilepath)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred reading the changelog's TBD skeleton")
+	}
+	// What's left of the skeleton isn't part of the release
+	changelogFile = pruneChangelogTbdSkeleton(changelogFile, tbdSkeletonLines)
+	changelogChanges, err := parseChangeLogFile(changelogFile, rules)
+
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrChangelogInvalid.code, "The changelog at '%s' is invalid", changelogFilepath)
+	}
+	additionalChangelogsChanges, err := parseAdditionalChangelogs(repoDirpath, kudetConfig.AdditionalChangelogFilepaths, tbdSkeletonLines, rules)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrChangelogInvalid.code, "An error occurred validating the additional changelogs")
+	}
+	// Breaking and major changes count wherever they're listed
+	changelogChanges = mergeChangelogChanges(changelogChanges, additionalChangelogsChanges)
+
+	releaseNotesLines, err := getUnreleasedNotesLines(changelogFile)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrChangelogInvalid.code, "An error occurred getting the release notes from the changelog")
+	}
+	releaseNotes := strings.Join(releaseNotesLines, "\n")
+
+	approvedReleaseNotesFilepath := filepath.Join(repoDirpath, kudetConfig.ApprovedReleaseNotesFilepath)
+	if err := verifyReleaseNotesMatchApprovedCopy(changelogFile, approvedReleaseNotesFilepath, releaser.isReleaseNotesDiffAcknowledged); err != nil {
+		return stacktrace.PropagateWithCode(err, ErrReleaseNotesUnapproved.code, "Refusing to release with unapproved release notes")
+	}
+
+	logrus.Infof("Finished prererelease checks.")
+
+	releaser.startStep("Version")
+	logrus.Infof("Guessing next release version...")
+	latestReleaseVersion, err := getLatestReleaseVersion(repository, kudetConfig.TagParsing)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the latest release version.")
+	}
+	if kudetConfig.MinReleaseInterval > 0 {
+		problem, isTooSoon, err := getReleasedTooRecentlyProblem(repository, kudetConfig.MinReleaseInterval, latestReleaseVersion.String(), time.Now())
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred checking when release '%s' was cut", latestReleaseVersion.String())
+		}
+		if isTooSoon && releaser.isForced {
+			logrus.Warnf("%s; releasing anyway, as forced", problem)
+		} else if isTooSoon {
+			return stacktrace.NewErrorWithCode(ErrReleasedTooRecently.code, "Refusing to release: %s", problem)
+		}
+	}
+	if kudetConfig.TagSignatures.KeyringFilepath != "" {
+		logrus.Infof("Verifying the signatures of the tags of release '%s'...", latestReleaseVersion.String())
+		if err := verifyReleaseTagSignatures(repository, repoDirpath, kudetConfig.TagSignatures, latestReleaseVersion.String()); err != nil {
+			return stacktrace.PropagateWithCode(err, ErrTagSignatureInvalid.code, "Refusing to base the next version on release '%s', whose tags aren't signed by a trusted key", latestReleaseVersion.String())
+		}
+	}
+	nextReleaseVersion := getNextReleaseVersion(latestReleaseVersion, changelogChanges, releaser.shouldBumpMajorVersion)
+	bumpReason := getBumpReason(changelogChanges)
+	if releaser.shouldBumpMajorVersion {
+		bumpReason = requestedMajorBumpReason
+	} else {
+		override, err := getBumpOverride(repository, kudetConfig.BumpOverride, latestReleaseVersion.String())
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred looking for an approved override of the version bump")
+		}
+		if override != nil {
+			logrus.Warnf("Bumping the %s version instead of releasing '%s' as the changelog says, since %s overrode the bump in commit '%s': %s", override.bumpType, nextReleaseVersion.String(), override.approver, override.commitHash, override.justification)
+			nextReleaseVersion = override.apply(latestReleaseVersion)
+			bumpReason = override.getBumpReason()
+		}
+	}
+	isVersionAllocated := false
+	if kudetConfig.VersionSource.Url != "" && releaser.isSandbox {
+		// The service would have allocated the version for good, though the rehearsal never releases it
+		logrus.Infof("Not requesting the version from the version source in the sandbox, so the changelog's version is released")
+	} else if kudetConfig.VersionSource.Url != "" && !skipDryRunStep(kudetConfig.DryRunSteps, kudet_config.VersionSourceDryRunStep, "requesting the version from the version source, so the changelog's version is released") {
+		// Centrally allocated versions aren't ours to choose, so the wizard doesn't offer to change them
+		nextReleaseVersion, err = allocateReleaseVersion(ctx, repository, kudetConfig.VersionSource, latestReleaseVersion, nextReleaseVersion)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting the next release version from the version source")
+		}
+		bumpReason = allocatedBumpReason
+		isVersionAllocated = true
+	}
+	if releaser.wizard != nil && !isVersionAllocated {
+		guessedReleaseVersion := nextReleaseVersion
+		nextReleaseVersion, err = releaser.wizard.chooseNextVersion(latestReleaseVersion, nextReleaseVersion)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred choosing the next release version")
+		}
+		if !nextReleaseVersion.Equal(&guessedReleaseVersion) {
+			bumpReason = interactiveBumpReason
+		}
+	}
+	releaser.telemetryRecorder.SetAttribute(versionTelemetryAttribute, nextReleaseVersion.String())
+
+	// A version file that's drifted from the releases would be bumped, or trusted to be bumped by the scripts, from the
+	// wrong version; before the first release there's no version for them to have yet
+	repoVersionFiles, _ := splitVersionFiles(repoDirpath, kudetConfig.VersionFiles)
+	if len(repoVersionFiles) > 0 && latestReleaseVersion.String() != noPreviousVersion {
+		logrus.Infof("Checking that the version files have the previous version...")
+		if err := verifyVersionFilesHaveVersion(repoDirpath, repoVersionFiles, latestReleaseVersion.String()); err != nil {
+			return stacktrace.Propagate(err, "The version files are out of sync with release '%s'", latestReleaseVersion.String())
+		}
+	}
+
+	if len(kudetConfig.RequiredChangelogSections) > 0 {
+		logrus.Infof("Checking that the changelog has the sections that the changed files require...")
+		if err := verifyRequiredChangelogSections(repository, changelogFile, kudetConfig.RequiredChangelogSections, latestReleaseVersion.String()); err != nil {
+			return stacktrace.Propagate(err, "Refusing to release without the changelog sections that the changed files require")
+		}
+	}
+
+	if len(kudetConfig.NonReleasableChanges.Paths) > 0 {
+		logrus.Infof("Checking that something releasable changed since release '%s'...", latestReleaseVersion.String())
+		problem, isOnlyNonReleasable, err := getOnlyNonReleasableChangesProblem(repository, kudetConfig, latestReleaseVersion.String())
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred checking whether anything releasable changed since release '%s'", latestReleaseVersion.String())
+		}
+		forcedReleaseSubheaders := changelogChanges.categories.forcedReleaseSubheaders
+		switch {
+		case isOnlyNonReleasable && len(forcedReleaseSubheaders) > 0:
+			logrus.Infof("%s, but the changelog's entries under '%s' force a release", problem, strings.Join(forcedReleaseSubheaders, "', '"))
+		case isOnlyNonReleasable && kudetConfig.NonReleasableChanges.OnOnlyNonReleasable == kudet_config.SkipNonReleasableChangesPolicy:
+			return stacktrace.NewErrorWithCode(ErrOnlyNonReleasableChanges.code, "Skipping the release: %s", problem)
+		case isOnlyNonReleasable:
+			logrus.Warnf("%s; releasing anyway", problem)
+		}
+	}
+
+	if err := verifyReleaseTagsDoNotExist(repository, nextReleaseVersion.String()); err != nil {
+		return stacktrace.Propagate(err, "Refusing to recreate the tags of version '%s'", nextReleaseVersion.String())
+	}
+	versionTrackReleases, err := prepareVersionTrackReleases(repository, repoDirpath, versionTracks, rules, kudetConfig.TagParsing, tbdSkeletonLines)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred preparing the releases of the version tracks")
+	}
+	for _, trackRelease := range versionTrackReleases {
+		logrus.Infof("Releasing version '%s' of version track '%s', after '%s', along with version '%s'", trackRelease.version, trackRelease.track.Name, trackRelease.previousVersion, nextReleaseVersion.String())
+	}
+
+	if releaser.shouldRequireGreenCi {
+		logrus.Infof("Checking that CI is green on '%s'...", remoteMainBranchName)
+		releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred determining the forge to check CI on")
+		}
+		if err := verifyCiIsGreen(ctx, releaseForge, remoteMainHash, kudetConfig.Ci.RequiredChecks); err != nil {
+			return stacktrace.PropagateWithCode(err, ErrCiNotGreen.code, "Refusing to release because CI isn't green on '%s'", remoteMainBranchName)
+		}
+	}
+
+	if len(kudetConfig.Policy.Paths) > 0 || len(kudetConfig.Policy.WasmGates) > 0 {
+		logrus.Infof("Checking the release against the release policy...")
+		denials, err := releaser.getReleasePolicyDenials(ctx, repository, kudetConfig, &nextReleaseVersion, latestReleaseVersion, localMainHash, releaseNotesLines)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred checking the release against the release policy")
+		}
+		if len(denials) > 0 {
+			return stacktrace.NewErrorWithCode(ErrPolicyDenied.code, "The release policy refuses to release version '%s':\n%s", nextReleaseVersion.String(), formatPolicyDenials(denials))
+		}
+	}
+
+	downstreamConsumers, err := loadDownstreamConsumers(releaser.repoDirpath, kudetConfig.Downstream)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the downstream consumers to report the release's blast radius")
+	}
+	logBlastRadius(nextReleaseVersion.String(), isBreakingRelease(nextReleaseVersion.String(), latestReleaseVersion.String()), downstreamConsumers)
+
+	// When the release's diff is previewed, it's confirmed once its changes have been made instead
+	if !releaser.shouldPreviewReleaseDiff {
+		var isConfirmed bool
+		if releaser.wizard != nil {
+			changesPreviewLines, err := getReleaseChangesPreviewLines(repoDirpath, kudetConfig.ChangelogFilepath, kudetConfig.ChangelogMaxLineLength, tbdSkeletonLines, repoVersionFiles, nextReleaseVersion.String())
+			if err != nil {
+				return stacktrace.Propagate(err, "An error occurred previewing the changes that releasing version '%s' will make", nextReleaseVersion.String())
+			}
+			isConfirmed, err = releaser.wizard.confirmRelease(nextReleaseVersion.String(), changesPreviewLines)
+		} else {
+			isConfirmed, err = releaser.confirmer(ctx, i18n.Sprintf(i18n.ConfirmReleaseQuestion, strings.Join(append([]string{nextReleaseVersion.String()}, getVersionTrackTags(versionTrackReleases)...), "', '")))
+		}
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred confirming the release of version '%s'", nextReleaseVersion.String())
+		}
+		if !isConfirmed {
+			logrus.Infof("Release of version '%s' was not confirmed; aborting", nextReleaseVersion.String())
+			return nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return stacktrace.Propagate(err, "The release was cancelled before any changes were made")
+	}
+	releaseName := strings.Join(append([]string{nextReleaseVersion.String()}, getVersionTrackTags(versionTrackReleases)...), "', '")
+	if err := releaser.waitForApprovalIfNeeded(ctx, repository, kudetConfig, releaseName, releaseNotes, isPushDryRun); err != nil {
+		return stacktrace.Propagate(err, "Refusing to release version '%s' without approval", nextReleaseVersion.String())
+	}
+
+	shouldResetLocalBranch := true
+	defer func() {
+		if shouldResetLocalBranch {
+			// git reset --hard origin/main
+			if err := repository.ResetHard(remoteMainHash); err != nil {
+				logrus.Errorf("ACTION REQUIRED: Error occurred attempting to undo local changes made for release '%s'. Please run 'git reset --hard %s' to undo manually.", nextReleaseVersion.String(), remoteMainBranchName)
+			}
+		}
+	}()
+
+	releaser.startStep("Scripts")
+	logrus.Infof("Running prerelease scripts...")
+	artifactFilepaths, err := runPreReleaseScripts(ctx, repository, repoDirpath, kudetConfig, nextReleaseVersion.String(), latestReleaseVersion.String(), releaser.getArtifactsDirpath())
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrPreReleaseScriptFailed.code, "An error occurred while running prerelease scripts.")
+	}
+
+	// Version files outside the repo are only bumped once the release is out, since they aren't part of the release commit
+	if kudetBumpedVersionFiles := getVersionFilesBumpedByKudet(repoVersionFiles); len(kudetBumpedVersionFiles) > 0 {
+		logrus.Infof("Updating version files...")
+		if err := updateVersionFiles(repoDirpath, kudetBumpedVersionFiles, nextReleaseVersion.String()); err != nil {
+			return stacktrace.Propagate(err, "An error occurred updating the version files")
+		}
+	}
+	if len(repoVersionFiles) > 0 {
+		logrus.Infof("Checking that the version files have the next version...")
+		if err := verifyVersionFilesHaveVersion(repoDirpath, repoVersionFiles, nextReleaseVersion.String()); err != nil {
+			return stacktrace.Propagate(err, "A version file wasn't bumped to the next version; if a pre-release script is meant to bump it, check that it does")
+		}
+	}
+	if len(kudetConfig.Packages) > 0 {
+		logrus.Infof("Releasing the packages in the order of their dependencies...")
+		if err := releasePackages(ctx, repoDirpath, kudetConfig.Packages, kudetConfig.PreReleaseScriptsShell, nextReleaseVersion.String()); err != nil {
+			return stacktrace.Propagate(err, "An error occurred releasing the packages")
+		}
+	}
+	// The build comes last so that the release artifacts are built from the version files and scripts' output as released
+	if kudetConfig.Bazel.StampFilepath != "" || kudetConfig.Bazel.Target != "" {
+		logrus.Infof("Building the release with Bazel...")
+		bazelArtifactFilepaths, err := runBazelReleaseStep(ctx, repoDirpath, kudetConfig.Bazel, nextReleaseVersion.String(), latestReleaseVersion.String(), releaser.getArtifactsDirpath(), artifactFilepaths)
+		if err != nil {
+			return stacktrace.PropagateWithCode(err, ErrBuildFailed.code, "An error occurred while building the release with Bazel")
+		}
+		artifactFilepaths = append(artifactFilepaths, bazelArtifactFilepaths...)
+	}
+	var artifactDiff *artifactDiffReport
+	if kudetConfig.ArtifactDiff.Enabled && len(artifactFilepaths) > 0 && latestReleaseVersion.String() != noPreviousVersion {
+		logrus.Infof("Comparing the release's artifacts with release '%s''s...", latestReleaseVersion.String())
+		artifactDiff, err = releaser.diffReleaseArtifacts(ctx, repository, kudetConfig, artifactFilepaths, latestReleaseVersion.String(), nextReleaseVersion.String())
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred comparing the release's artifacts with release '%s''s", latestReleaseVersion.String())
+		}
+	}
+	if artifactDiff != nil {
+		artifactDiff.log()
+		if len(artifactDiff.Anomalies) > 0 && kudetConfig.ArtifactDiff.FailOnAnomaly && releaser.isForced {
+			logrus.Warnf("Releasing despite the artifact anomalies, as forced")
+		} else if len(artifactDiff.Anomalies) > 0 && kudetConfig.ArtifactDiff.FailOnAnomaly {
+			return stacktrace.NewErrorWithCode(ErrArtifactAnomaly.code, "Refusing to release, as the release's artifacts have anomalies compared with release '%s''s:\n- %s", latestReleaseVersion.String(), strings.Join(artifactDiff.Anomalies, "\n- "))
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return stacktrace.Propagate(err, "The release was cancelled after running the prerelease scripts")
+	}
+
+	releaser.startStep("Changelog")
+	logrus.Infof("Updating the changelog...")
+	err = updateChangelog(changelogFilepath, nextReleaseVersion.String(), tbdSkeletonLines, kudetConfig.ChangelogMaxLineLength)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while updating the changelog file at '%s'", changelogFilepath)
+	}
+	if err := updateAdditionalChangelogs(repoDirpath, kudetConfig.AdditionalChangelogFilepaths, nextReleaseVersion.String(), tbdSkeletonLines, kudetConfig.ChangelogMaxLineLength); err != nil {
+		return stacktrace.Propagate(err, "An error occurred while updating the additional changelogs")
+	}
+	if err := finalizeVersionTrackChangelogs(repoDirpath, versionTrackReleases, tbdSkeletonLines, kudetConfig.ChangelogMaxLineLength); err != nil {
+		return stacktrace.Propagate(err, "An error occurred finalizing the changelogs of the version tracks")
+	}
+
+	if err := regenerateRunbookIfPresent(repoDirpath, kudetConfig); err != nil {
+		return stacktrace.Propagate(err, "An error occurred regenerating the release runbook")
+	}
+
+	if releaser.shouldPreviewReleaseDiff {
+		isConfirmed, err := releaser.confirmReleaseDiff(ctx, repository, repoDirpath, nextReleaseVersion.String())
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred confirming the changes of release version '%s'", nextReleaseVersion.String())
+		}
+		if !isConfirmed {
+			logrus.Infof("Release of version '%s' was not confirmed; aborting and resetting its changes to tracked files", nextReleaseVersion.String())
+			return nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return stacktrace.Propagate(err, "The release was cancelled before committing")
+	}
+
+	releaseTime := time.Now()
+	commitMsg, err := getReleaseCommitMessage(kudetConfig, nextReleaseVersion.String(), latestReleaseVersion.String(), releaseTime)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the message of the release commit")
+	}
+	releaseTag := nextReleaseVersion.String()
+	vReleaseTag := fmt.Sprintf("%s%s", vPrefix, nextReleaseVersion.String())
+	releaseTagMsg, err := getReleaseTagMessage(kudetConfig, releaseTag, nextReleaseVersion.String(), latestReleaseVersion.String(), author, releaseTime, releaseNotesLines)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the message of the release tag")
+	}
+	vReleaseTagMsg, err := getReleaseTagMessage(kudetConfig, vReleaseTag, nextReleaseVersion.String(), latestReleaseVersion.String(), author, releaseTime, releaseNotesLines)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the message of the 'v'-prefixed release tag")
+	}
+	if releaser.bridgeScriptFilepath != "" {
+		logrus.Infof("Writing the release commit and tag operations to bridge script '%s'...", releaser.bridgeScriptFilepath)
+		if err := writeBridgeScript(releaser.bridgeScriptFilepath, commitMsg, releaseTag, releaseTagMsg, vReleaseTag, vReleaseTagMsg); err != nil {
+			return stacktrace.Propagate(err, "An error occurred writing the bridge script for release version '%s'", nextReleaseVersion.String())
+		}
+		// The bridge commits the release changes, so they're left in the worktree for it
+		shouldResetLocalBranch = false
+		logrus.Infof("Release version '%s' is prepared; apply '%s' through the bridge to finish it", nextReleaseVersion.String(), releaser.bridgeScriptFilepath)
+		return nil
+	}
+
+	releaser.startStep("Commit")
+	if releaser.shouldSkipCommitHooks {
+		logrus.Warnf("Skipping the repo's git commit hooks for the release commit, as requested")
+	} else {
+		logrus.Infof("Running the repo's git commit hooks...")
+		commitMsg, err = repository.RunCommitHooks(ctx, commitMsg)
+		if err != nil {
+			return stacktrace.PropagateWithCode(err, ErrCommitHookRejected.code, "The repo's git commit hooks rejected the commit for release version '%s'", nextReleaseVersion.String())
+		}
+	}
+	logrus.Infof("Committing changes locally...")
+	author.When = time.Now()
+	releaseCommitHash, err := repository.CommitAll(commitMsg, author)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while committing the changes for release version '%s'", nextReleaseVersion.String())
+	}
+
+	inProgressReleaseState = &releaseState{
+		Version:           nextReleaseVersion.String(),
+		PreviousVersion:   latestReleaseVersion.String(),
+		BaseCommitHash:    remoteMainHash,
+		ReleaseCommitHash: releaseCommitHash,
+		ReleaseNotes:      releaseNotes,
+		ArtifactFilepaths: artifactFilepaths,
+		ReleasedBy:        fmt.Sprintf("%s <%s>", author.Name, author.Email),
+		BumpReason:        bumpReason,
+		VersionTrackTags:  getVersionTrackTags(versionTrackReleases),
+		ArtifactDiff:      artifactDiff,
+	}
+	if releaser.isEmbargoed {
+		// The release branch is reset back off the release commit on return, so that nothing can push it early
+		if err := releaser.stageEmbargoedRelease(ctx, repository, kudetConfig.Forge, stateStore, inProgressReleaseState); err != nil {
+			return stacktrace.Propagate(err, "An error occurred staging the embargoed release of version '%s'", nextReleaseVersion.String())
+		}
+		// Draft advisories are private, so drafting it now lets it be reviewed along with the reporters before disclosure
+		releaser.draftSecurityAdvisoryIfNeeded(ctx, repository, kudetConfig, securityAdvisorySeverity, nextReleaseVersion.String(), releaseNotes)
+		return nil
+	}
+
+	// From here on, record what we're releasing so that a run that dies without getting the chance to roll back can be resumed
+	if err := saveReleaseState(ctx, stateStore, inProgressReleaseState); err != nil {
+		return stacktrace.Propagate(err, "An error occurred recording the in-progress release state")
+	}
+	shouldRemoveReleaseState := true
+	defer func() {
+		// The release may have been cancelled, but its state still needs cleaning up unless it's left to be resumed
+		if !shouldRemoveReleaseState {
+			return
+		}
+		if err := removeReleaseState(context.Background(), stateStore); err != nil {
+			logrus.Errorf("ACTION REQUIRED: An error occurred removing the release state at '%s'. Please run 'kudet release-state abort', otherwise the next release will attempt to resume this one.", releaseStateLocation)
+		}
+	}()
+
+	releaser.startStep("Tag")
+	logrus.Infof("Setting next release version tag...")
+	// Set next release version tag
+	headCommitHash, err := repository.GetHeadCommitHash()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while attempting to get the ref to HEAD of the local repository.")
+	}
+	if err := repository.CreateTag(releaseTag, headCommitHash, releaseTagMsg); err != nil {
+		return stacktrace.Propagate(err, "An error occurred while attempting to create this git tag for the next release version '%s'", releaseTag)
+	}
+	shouldDeleteLocalReleaseTag := true
+	defer func() {
+		if shouldDeleteLocalReleaseTag {
+			if err := repository.DeleteTag(releaseTag); err != nil {
+				logrus.Errorf("ACTION REQUIRED: An error occurred attempting to undo creation of tag '%s'. Please run 'git tag -d %s' to delete the tag manually.", releaseTag, err)
+			}
+		}
+	}()
+	if err := repository.CreateTag(vReleaseTag, headCommitHash, vReleaseTagMsg); err != nil {
+		return stacktrace.Propagate(err, "An error occurred while attempting to create this git tag for the next release version '%s'", vReleaseTag)
+	}
+	shouldDeleteLocalVPrefixedReleaseTag := true
+	defer func() {
+		if shouldDeleteLocalVPrefixedReleaseTag {
+			if err := repository.DeleteTag(vReleaseTag); err != nil {
+				logrus.Errorf("ACTION REQUIRED: An error occurred attempting to undo creation of tag '%s'. Please run 'git tag -d %s' to delete the tag manually.", vReleaseTag, vReleaseTag)
+			}
+		}
+	}()
+	versionTrackTags, err := createVersionTrackTags(repository, versionTrackReleases, headCommitHash)
+	shouldDeleteLocalVersionTrackTags := true
+	defer func() {
+		if shouldDeleteLocalVersionTrackTags {
+			deleteLocalVersionTrackTags(repository, versionTrackTags)
+		}
+	}()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred tagging the releases of the version tracks")
+	}
+
+	// Someone may have merged while we were working, in which case the commit push would fail deep into the flow
+	releaser.startStep("Push")
+	if isPushDryRun {
+		// The release is left committed and tagged locally, for it to be inspected
+		logrus.Infof("Dry run: not pushing the release commit or tags '%s' and '%s' to '%s', as the kudet config's '%s' asks; they're left in the local repo", releaseTag, vReleaseTag, originRemoteName, kudet_config.DryRunStepsKey)
+		shouldResetLocalBranch = false
+		shouldDeleteLocalReleaseTag = false
+		shouldDeleteLocalVPrefixedReleaseTag = false
+		shouldDeleteLocalVersionTrackTags = false
+		logrus.Infof("Dry-run release success; the post-release steps are skipped since nothing was released.")
+		return nil
+	}
+	logrus.Infof("Checking that '%s' hasn't moved since the release started...", remoteMainBranchName)
+	if err := verifyRemoteBranchHasNotMoved(ctx, repository, releaseBranchName, remoteMainHash); err != nil {
+		return stacktrace.PropagateWithCode(err, ErrOutOfSync.code, "Refusing to push the release")
+	}
+
+	// The order in which we push resources to remote is: vReleaseTag -> Commits -> Release Tag
+	// This is important because we push in order of easiest to reverse to harder to reverse in case of failures
+	// Once the commits are pushed the release is resumed rather than reversed, and pushing Release Tag to remote is the
+	// point at which operations are irreversible due to CI being triggered
+
+	vReleaseTagRefSpec := fmt.Sprintf("%s%s:%s%s", tagsPrefix, vReleaseTag, tagsPrefix, vReleaseTag)
+	if err = repository.Push(ctx, vReleaseTagRefSpec); err != nil {
+		logrus.Errorf("An error occurred while pushing release tag: '%s' to '%s'.", vReleaseTag, remoteMainBranchName)
+	}
+	shouldDeleteRemoteVPrefixedReleaseTag := true
+	defer func() {
+		if shouldDeleteRemoteVPrefixedReleaseTag {
+			// git push origin :tagname
+			emptyVReleaseTagRefSpec := fmt.Sprintf(":%s%s", tagsPrefix, vReleaseTag)
+			// The release context may already be cancelled by the time we roll back, so cleanup must not depend on it
+			if err := repository.Push(context.Background(), emptyVReleaseTagRefSpec); err != nil {
+				logrus.Errorf("ACTION REQUIRED: An error occurred attempting to delete tag '%s' from '%s'. Please run 'git push --delete %s %s' to delete the tag manually.", vReleaseTag, originRemoteName, originRemoteName, vReleaseTag)
+			}
+		}
+	}()
+
+	logrus.Infof("Pushing release changes to '%s'...", remoteMainBranchName)
+	releaseBranchRefSpec := fmt.Sprintf("%s%s:%s%s", headRef, releaseBranchName, headRef, releaseBranchName)
+	// The lease closes the window between the check above and this push
+	if err = repository.PushWithLease(ctx, releaseBranchRefSpec, headRef+releaseBranchName, remoteMainHash); err != nil {
+		return stacktrace.PropagateWithCode(err, ErrPushRejected.code, "An error occurred while pushing release changes to '%s'; if it moved since the release started, re-run the release", remoteMainBranchName)
+	}
+	// Rolling back now would leave the release commit on the remote without its tags, so from here on the release is
+	// kept, along with its state, for a re-run to resume
+	shouldResetLocalBranch = false
+	shouldDeleteLocalReleaseTag = false
+	shouldDeleteLocalVPrefixedReleaseTag = false
+	shouldDeleteLocalVersionTrackTags = false
+	shouldDeleteRemoteVPrefixedReleaseTag = false
+	shouldRemoveReleaseState = false
+
+	logrus.Infof("Pushing release tags to '%s'...", remoteMainBranchName)
+	releaseTagRefSpec := fmt.Sprintf("%s%s:%s%s", tagsPrefix, releaseTag, tagsPrefix, releaseTag)
+	if err = repository.Push(ctx, releaseTagRefSpec); err != nil {
+		return stacktrace.PropagateWithCode(err, ErrPushRejected.code, "The release commit of version '%s' was pushed to '%s', but an error occurred pushing release tag '%s'; re-run the release to resume it from its state at '%s'", nextReleaseVersion.String(), remoteMainBranchName, releaseTag, releaseStateLocation)
+	}
+
+	// Like the release tag, the version tracks' tags trigger their releases' CI, so they're pushed once nothing else can
+	// fail; if the run dies before they are, resuming the release pushes whichever are missing
+	if err := pushVersionTrackTags(ctx, repository, versionTrackTags); err != nil {
+		return stacktrace.Propagate(err, "Release of version '%s' succeeded, but not all of the version tracks' tags were pushed; re-run the release to push the rest", nextReleaseVersion.String())
+	}
+	shouldRemoveReleaseState = true
+
+	logrus.Infof("Release success.")
+	if releaser.isSandbox {
+		logrus.Infof("Skipping the post-release steps in the sandbox, as they reach outside the repo")
+		return nil
+	}
+	if err := releaser.waitOutUndoWindowIfNeeded(ctx, repository, kudetConfig, nextReleaseVersion.String()); err != nil {
+		return stacktrace.Propagate(err, "Release of version '%s' was pushed, but didn't make it through its undo window", nextReleaseVersion.String())
+	}
+	// The undo window and the steps before it can outlast a GitHub App's installation token
+	if err := releaser.authenticateAsGithubAppIfNeeded(ctx, repository, kudetConfig); err != nil {
+		return stacktrace.Propagate(err, "Release of version '%s' was pushed, but an error occurred authenticating as the kudet config's GitHub App for the post-release steps", nextReleaseVersion.String())
+	}
+	releaser.startStep("Post-release")
+	// Mirrors come first, so that what's downstream of the release finds it on them too
+	mirrorsErr := releaser.pushReleaseToMirrors(ctx, repository, kudetConfig.Mirrors, releaseBranchName, nextReleaseVersion.String())
+	if releaser.isSecurityRelease && !skipDryRunStep(kudetConfig.DryRunSteps, kudet_config.SecurityAdvisoryDryRunStep, "drafting the security advisory") {
+		releaser.draftSecurityAdvisoryIfNeeded(ctx, repository, kudetConfig, securityAdvisorySeverity, nextReleaseVersion.String(), releaseNotes)
+	}
+	releaser.runPostReleaseSteps(ctx, repository, kudetConfig, inProgressReleaseState)
+	if mirrorsErr != nil {
+		return stacktrace.Propagate(mirrorsErr, "Release of version '%s' succeeded, but it didn't reach all of its mirrors", nextReleaseVersion.String())
+	}
+	return nil
+}
+
+// runPostReleaseSteps does everything that follows a pushed release; none of it can fail the release, which is
+// irreversible by the time this runs
+func (releaser *Releaser) runPostReleaseSteps(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, state *releaseState) {
+	dryRunSteps := kudetConfig.DryRunSteps
+	if !skipDryRunStep(dryRunSteps, kudet_config.AuditNoteDryRunStep, "recording the release's audit note") {
+		recordReleaseAuditNote(ctx, repository, state)
+	}
+	if !skipDryRunStep(dryRunSteps, kudet_config.AuditLogDryRunStep, "recording the release in the audit log") {
+		recordReleaseInAuditLog(ctx, kudetConfig.AuditLog, state)
+	}
+	if !skipDryRunStep(dryRunSteps, kudet_config.ExternalVersionFilesDryRunStep, "updating the version files outside the repo") {
+		updateExternalVersionFiles(releaser.repoDirpath, kudetConfig.VersionFiles, state.Version)
+	}
+	// Unpromoted releases are never rolled back
+	var promotedEnvironmentNames []string
+	if !skipDryRunStep(dryRunSteps, kudet_config.PromotionDryRunStep, "promoting the release to environments") {
+		promotedEnvironmentNames = releaser.promoteReleaseIfNeeded(ctx, kudetConfig.Environments, state.Version)
+	}
+	rolloutStatus := verifyRolloutHealthIfNeeded(ctx, kudetConfig.HealthCheck, state.Version, promotedEnvironmentNames)
+	rolloutStatus = releaser.rollbackUnhealthyReleaseIfNeeded(ctx, repository, kudetConfig, state, promotedEnvironmentNames, rolloutStatus)
+	releaser.uploadPreReleaseScriptArtifactsIfNeeded(ctx, repository, kudetConfig, state)
+	releaser.publishReleaseMetadataIfNeeded(ctx, repository, kudetConfig, state, rolloutStatus)
+	if !skipDryRunStep(dryRunSteps, kudet_config.PullRequestCommentsDryRunStep, "commenting on the released pull requests") {
+		releaser.commentOnReleasedPullRequestsIfNeeded(ctx, repository, kudetConfig, state)
+	}
+	if !skipDryRunStep(dryRunSteps, kudet_config.MilestonesDryRunStep, "rolling the milestone forward") {
+		releaser.rollMilestonesForwardIfNeeded(ctx, repository, kudetConfig, state)
+	}
+	notification := releaser.newReleaseNotification(kudetConfig, state)
+	notification.PromotedEnvironments = promotedEnvironmentNames
+	notification.RolloutStatus = rolloutStatus
+	if !skipDryRunStep(dryRunSteps, kudet_config.DownstreamDryRunStep, "reporting the release to its downstream consumers") {
+		releaser.reportReleaseDownstream(kudetConfig.Downstream, notification)
+	}
+	if !skipDryRunStep(dryRunSteps, kudet_config.NotificationsDryRunStep, "sending the release notifications") {
+		sendReleaseNotifications(kudetConfig.Notifications.WebhookUrls, notification)
+	}
+}
+
+// getNextReleaseVersion bumps the latest release's version according to the changes listed under the TBD header
+func getNextReleaseVersion(latestReleaseVersion *semver.Version, changes *changelogChanges, shouldBumpMajorVersion bool) semver.Version {
+	categoryBumpType := changes.categories.bumpType
+	if shouldBumpMajorVersion || changes.hasMajorChange || categoryBumpType == majorBumpType {
+		return latestReleaseVersion.IncMajor()
+	}
+	if changes.hasBreakingChange || categoryBumpType == minorBumpType {
+		return latestReleaseVersion.IncMinor()
+	}
+	return latestReleaseVersion.IncPatch()
+}
+
+func getLatestReleaseVersion(repository vcs.Repository, tagParsingConfig kudet_config.TagParsingConfig) (*semver.Version, error) {
+	tagNames, err := repository.ListTagNames()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while retrieving tags for repository.")
+	}
+	latestReleaseVersion, err := getLatestReleaseVersionOfTags(tagNames, tagParsingConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the latest release version of the repo's tags")
+	}
+	return latestReleaseVersion, nil
+}
+
+// getLatestReleaseVersionOfTags returns the highest version among the tags that count as releases, or '0.0.0' if none do
+func getLatestReleaseVersionOfTags(tagNames []string, tagParsingConfig kudet_config.TagParsingConfig) (*semver.Version, error) {
+	allTagSemVers, ignoredTagNames := parseReleaseVersionTags(tagNames, tagParsingConfig)
+	if len(ignoredTagNames) > 0 {
+		logrus.Warnf(
+			"Ignoring version tags [%s] when determining the latest release; set '%s.%s' or '%s.%s' in the kudet config to take them into account",
+			strings.Join(ignoredTagNames, ", "),
+			kudet_config.TagParsingKey,
+			kudet_config.AllowVPrefixKey,
+			kudet_config.TagParsingKey,
+			kudet_config.AllowPrereleasesAndMetadataKey,
+		)
+	}
+
+	var latestReleaseTagSemVer *semver.Version
+	if len(allTagSemVers) == 0 {
+		var err error
+		latestReleaseTagSemVer, err = semver.StrictNewVersion(noPreviousVersion)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred creating '%s' semantic version.", noPreviousVersion)
+		}
+	} else {
+		sort.Sort(sort.Reverse(semver.Collection(allTagSemVers)))
+		latestReleaseTagSemVer = allTagSemVers[0]
+	}
+
+	return latestReleaseTagSemVer, nil
+}
+
+// parseReleaseVersionTags returns the versions of the tags that count as releases, along with the tags that look like
+// versions but are excluded by the tag parsing config; a 'vX.Y.Z' tag next to its 'X.Y.Z' twin isn't reported as ignored
+func parseReleaseVersionTags(tagNames []string, tagParsingConfig kudet_config.TagParsingConfig) ([]*semver.Version, []string) {
+	tagNameSet := map[string]bool{}
+	for _, tagName := range tagNames {
+		tagNameSet[tagName] = true
+	}
+
+	versions := []*semver.Version{}
+	ignoredTagNames := []string{}
+	for _, tagName := range tagNames {
+		versionStr := tagName
+		hasVPrefix := strings.HasPrefix(tagName, vPrefix)
+		if hasVPrefix {
+			versionStr = strings.TrimPrefix(tagName, vPrefix)
+		}
+		if !semverRegex.MatchString(versionStr) {
+			continue
+		}
+		version, err := semver.StrictNewVersion(versionStr)
+		if err != nil {
+			// Can't happen for strings matching the official semver regex, but a tag that can't be parsed isn't a release
+			continue
+		}
+		if hasVPrefix && !tagParsingConfig.AllowVPrefix {
+			if !tagNameSet[versionStr] {
+				ignoredTagNames = append(ignoredTagNames, tagName)
+			}
+			continue
+		}
+		if (version.Prerelease() != "" || version.Metadata() != "") && !tagParsingConfig.AllowPrereleasesAndMetadata {
+			ignoredTagNames = append(ignoredTagNames, tagName)
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, ignoredTagNames
+}
+
+// runPreReleaseScripts runs the pre-release scripts in the order of their dependency groups, skipping those whose paths
+// haven't changed since the previous release, and collects their artifacts into the artifacts directory if one is given,
+// returning the paths of the collected copies
+func runPreReleaseScripts(ctx context.Context, repository vcs.Repository, preReleaseScriptsDirpath string, kudetConfig *kudet_config.KudetConfig, releaseVersion string, previousReleaseVersion string, artifactsDirpath string) ([]string, error) {
+	scripts, err := getPreReleaseScripts(preReleaseScriptsDirpath, kudetConfig.PreReleaseScriptsFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the pre release scripts to run")
+	}
+	// Every script is checked before any of them runs, so that a tampered script can't have run by the time we notice
+	if err := verifyPreReleaseScriptChecksums(preReleaseScriptsDirpath, scripts); err != nil {
+		return nil, stacktrace.Propagate(err, "The pre release scripts don't match the checksums pinned in '%s'; if the changes are expected, run 'kudet pin-scripts' and commit the result", kudetConfig.PreReleaseScriptsFilepath)
+	}
+	secretValuesByScript, err := resolvePreReleaseScriptSecrets(ctx, preReleaseScriptsDirpath, kudetConfig, scripts)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the pre release scripts' secrets")
+	}
+	if err := verifyPreReleaseScriptArtifactsConfig(kudetConfig, scripts); err != nil {
+		return nil, stacktrace.Propagate(err, "The pre release scripts' artifacts are misconfigured")
+	}
+	if artifactsDirpath == "" && len(kudetConfig.PreReleaseScriptArtifacts) > 0 {
+		logrus.Infof("The pre release scripts' artifacts aren't collected, as the release has no metadata directory to collect them into")
+	}
+	parallelism := kudetConfig.PreReleaseScriptsParallelism
+	scriptGroups, err := getPreReleaseScriptGroups(scripts, kudetConfig.PreReleaseScriptSchedule, parallelism)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "The pre release scripts' schedule is misconfigured")
+	}
+	filepathsChangedSinceRelease, hasPreviousRelease, err := getFilepathsChangedSinceRelease(repository, previousReleaseVersion)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the files changed since the previous release")
+	}
+	shell := kudetConfig.PreReleaseScriptsShell
+	writablePaths := kudetConfig.PreReleaseScriptsWritablePaths
+	artifactFilepaths := []string{}
+	collectedArtifactSources := map[string]string{}
+
+	for _, scriptGroup := range scriptGroups {
+		// Files that earlier scripts wrote count as changed for the scripts that run after them
+		modifiedFilepaths, err := repository.GetModifiedFilepaths()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred listing the modified files of the worktree")
+		}
+		changedFilepaths := append(append([]string{}, filepathsChangedSinceRelease...), modifiedFilepaths...)
+		scriptsToRun := []*preReleaseScript{}
+		for _, script := range scriptGroup {
+			onlyIfChangedPaths := kudetConfig.PreReleaseScriptSchedule[script.relFilepath].OnlyIfChanged
+			if script.isInline() || len(onlyIfChangedPaths) == 0 || !hasPreviousRelease {
+				scriptsToRun = append(scriptsToRun, script)
+				continue
+			}
+			if changedFilepath, found := getChangedFilepathMatch(changedFilepaths, onlyIfChangedPaths); found {
+				logrus.Debugf("Running pre release script '%s' as '%s' changed since release '%s'", script.relFilepath, changedFilepath, previousReleaseVersion)
+				scriptsToRun = append(scriptsToRun, script)
+				continue
+			}
+			logrus.Infof("Skipping pre release script '%s' as none of '%s' changed since release '%s'", script.relFilepath, strings.Join(onlyIfChangedPaths, "', '"), previousReleaseVersion)
+		}
+		if len(scriptsToRun) == 0 {
+			continue
+		}
+		scriptDescriptions := []string{}
+		for _, script := range scriptsToRun {
+			scriptDescriptions = append(scriptDescriptions, script.getDescription(shell, releaseVersion))
+		}
+		groupDescription := strings.Join(scriptDescriptions, ", ")
+
+		var modifiedFileHashesBefore map[string]string
+		if len(writablePaths) > 0 {
+			modifiedFileHashesBefore, err = getModifiedFileHashes(repository, preReleaseScriptsDirpath)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred recording the modified files before running pre release script %s", groupDescription)
+			}
+		}
+
+		if err := runPreReleaseScriptGroup(ctx, scriptsToRun, parallelism, func(scriptCtx context.Context, script *preReleaseScript) error {
+			return runPreReleaseScript(scriptCtx, script, preReleaseScriptsDirpath, shell, releaseVersion, secretValuesByScript[script.relFilepath])
+		}); err != nil {
+			return nil, err
+		}
+
+		if len(writablePaths) > 0 {
+			modifiedFileHashesAfter, err := getModifiedFileHashes(repository, preReleaseScriptsDirpath)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred recording the modified files after running pre release script %s", groupDescription)
+			}
+			writtenFilepaths := getWrittenFilepaths(modifiedFileHashesBefore, modifiedFileHashesAfter)
+			if disallowedFilepaths := getDisallowedDirtyFilepaths(writtenFilepaths, writablePaths); len(disallowedFilepaths) > 0 {
+				return nil, stacktrace.NewError("Pre release script %s wrote to '%s', which aren't among the pre-release scripts' writable paths '%s'", groupDescription, strings.Join(disallowedFilepaths, "', '"), strings.Join(writablePaths, "', '"))
+			}
+		}
+
+		for _, script := range scriptsToRun {
+			artifactGlobs := kudetConfig.PreReleaseScriptArtifacts[script.relFilepath]
+			if artifactsDirpath == "" || script.isInline() || len(artifactGlobs) == 0 {
+				continue
+			}
+			scriptArtifactFilepaths, err := collectArtifacts(preReleaseScriptsDirpath, fmt.Sprintf("pre release script '%s'", script.relFilepath), artifactGlobs, artifactsDirpath, collectedArtifactSources)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred collecting the artifacts of pre release script %s", script.getDescription(shell, releaseVersion))
+			}
+			artifactFilepaths = append(artifactFilepaths, scriptArtifactFilepaths...)
+		}
+	}
+
+	return artifactFilepaths, nil
+}
+
+// runPreReleaseScript runs one pre-release script with the release version and its own secrets in its environment
+func runPreReleaseScript(ctx context.Context, script *preReleaseScript, preReleaseScriptsDirpath string, shell []string, releaseVersion string, secretValues map[string]string) error {
+	// Cancelling the context kills a running script, so that an interrupted release can roll back promptly
+	scriptCmd := script.getCmd(ctx, preReleaseScriptsDirpath, shell, releaseVersion)
+	scriptCmd.Dir = preReleaseScriptsDirpath
+	scriptCmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", releaseVersionEnvVar, releaseVersion))
+	// Each script only gets its own secrets, which are never logged
+	scriptCmd.Env = append(scriptCmd.Env, getSecretEnvVars(secretValues)...)
+	// The output is kept whatever the log level, as a failing script's output is how its failure gets debugged
+	scriptOutput := &bytes.Buffer{}
+	scriptCmd.Stdout = scriptOutput
+	scriptCmd.Stderr = scriptOutput
+	scriptDescription := script.getDescription(shell, releaseVersion)
+
+	logrus.Debugf("Running pre release script command '%s' in '%s'", scriptCmd.String(), scriptCmd.Dir)
+	err := scriptCmd.Run()
+	scriptOutputStr := maskSecrets(scriptOutput.String(), secretValues)
+	logrus.Tracef("Pre release script command '%s' output:\n%s", scriptCmd.String(), scriptOutputStr)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return stacktrace.Propagate(ctxErr, "Pre release script %s was interrupted", scriptDescription)
+		}
+		castedErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return stacktrace.Propagate(err, "Pre release script %s failed with an unrecognized error", scriptDescription)
+		}
+		return stacktrace.Propagate(castedErr, "Pre release script %s returned logs:\n%s", scriptDescription, scriptOutputStr)
+	}
+	logrus.Debugf("Pre release script command '%s' succeeded", scriptCmd.String())
+	return nil
+}
+
+// getReleaseCommitMessage renders the message of the release commit from the configured template, with the skip CI
+// marker in a paragraph of its own so that it also works as a trailer
+func getReleaseCommitMessage(kudetConfig *kudet_config.KudetConfig, releaseVersion string, previousReleaseVersion string, releaseTime time.Time) (string, error) {
+	commitMsg, err := kudet_config.ExecuteReleaseCommitMessageTemplate(kudetConfig.ReleaseCommitMessageTemplate, kudet_config.ReleaseCommitMessageData{
+		Version:         releaseVersion,
+		PreviousVersion: previousReleaseVersion,
+		Date:            releaseTime.UTC().Format(releaseCommitDateFormat),
+	})
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred rendering the release commit message for version '%s'", releaseVersion)
+	}
+	skipCiMarker := strings.TrimSpace(kudetConfig.SkipCiMarker)
+	if skipCiMarker == "" {
+		return commitMsg, nil
+	}
+	return fmt.Sprintf("%s\n\n%s", commitMsg, skipCiMarker), nil
+}
+
+// updateChangelog inserts the release's version header beneath the TBD header, which then gets the skeleton for the next
+// release's entries if there is one, after pruning what's left of the skeleton from the released entries. Only the
+// changelog's head, down to the previous release's header, is parsed and rewritten; the released versions below it are
+// copied over as they are, however big the changelog has grown.
+func updateChangelog(changelogFilepath string, releaseVersion string, tbdSkeletonLines []string, maxLineLength int) error {
+	changelogFile, err := os.Open(changelogFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to open changelog file at provided path. Are you sure '%s' exists?", changelogFilepath)
+	}
+	defer changelogFile.Close()
+	head, releasedVersionsOffset, err := changelog.ParseHead(changelogFile, maxLineLength)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing the head of the changelog at '%s'", changelogFilepath)
+	}
+	head, err = changelog.Parse(pruneChangelogTbdSkeleton(head.Render(), tbdSkeletonLines))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing the head of the changelog at '%s'", changelogFilepath)
+	}
+
+	// Check that first line contains version to be released placeholder header
+	if len(head.Preamble) > 0 || len(head.Versions) == 0 || !head.Versions[0].IsUnreleased() {
+		return stacktrace.NewError("No '%s' found in the first line of the changelog. Check the changelog at '%s' is in the correct format.", versionToBeReleasedPlaceholderHeaderStr, changelogFilepath)
+	}
+	unreleasedVersion := head.Versions[0]
+
+	// The unreleased entries go under the new version header, as they're written
+	releasedVersion := *unreleasedVersion
+	releasedVersion.HeaderLine = fmt.Sprintf("%s %s", sectionHeaderPrefix, releaseVersion)
+	releasedVersion.Name = releaseVersion
+
+	// A fresh TBD header takes their place, with the skeleton for the next release's entries between empty lines
+	nextUnreleasedLines := []string{""}
+	if len(tbdSkeletonLines) > 0 {
+		nextUnreleasedLines = append(append(nextUnreleasedLines, tbdSkeletonLines...), "")
+	}
+	nextUnreleasedVersion := changelog.NewVersion(unreleasedVersion.HeaderLine, nextUnreleasedLines)
+	head.Versions = append([]*changelog.Version{nextUnreleasedVersion, &releasedVersion}, head.Versions[1:]...)
+
+	writeChangelog := func(writer io.Writer) error {
+		if _, err := writer.Write(head.Render()); err != nil {
+			return stacktrace.Propagate(err, "An error occurred writing the head of the changelog")
+		}
+		if releasedVersionsOffset < 0 {
+			return nil
+		}
+		if _, err := io.WriteString(writer, head.LineEnding); err != nil {
+			return stacktrace.Propagate(err, "An error occurred writing the head of the changelog")
+		}
+		releasedVersionsSize, err := copyReleasedVersions(writer, changelogFile, releasedVersionsOffset)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred copying the released versions of the changelog")
+		}
+		// The released history is never rewritten, so losing any of it to a short read would go unnoticed until it's released
+		fileInfo, err := changelogFile.Stat()
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting the size of the changelog")
+		}
+		if expectedSize := fileInfo.Size() - releasedVersionsOffset; releasedVersionsSize != expectedSize {
+			return stacktrace.NewError("Copied %d bytes of released versions from the changelog rather than %d", releasedVersionsSize, expectedSize)
+		}
+		return nil
+	}
+	if err := writeFileAtomically(changelogFilepath, writeChangelog); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the updated changelog file at '%s'", changelogFilepath)
+	}
+	return nil
+}
+
+// newChangelogScanner scans the lines of a changelog that's already in memory, which can be as long as the changelog
+// itself rather than limited to bufio's default token size
+func newChangelogScanner(changelogFile []byte) *bufio.Scanner {
+	scanner := bufio.NewScanner(bytes.NewReader(changelogFile))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), len(changelogFile)+1)
+	return scanner
+}
+
+func copyReleasedVersions(writer io.Writer, changelogFile *os.File, releasedVersionsOffset int64) (int64, error) {
+	if _, err := changelogFile.Seek(releasedVersionsOffset, io.SeekStart); err != nil {
+		return 0, stacktrace.Propagate(err, "An error occurred seeking to the first released version at byte %d", releasedVersionsOffset)
+	}
+	numBytesCopied, err := io.Copy(writer, changelogFile)
+	if err != nil {
+		return 0, stacktrace.Propagate(err, "An error occurred copying the released versions")
+	}
+	return numBytesCopied, nil
+}
+
+// writeFileAtomically replaces the file's contents with what's written to a temporary file next to it, syncing that to
+// disk and renaming it over the file, so that a failure or crash partway through leaves the old contents or the new ones
+// but never a truncated file that gets committed. The file keeps its permissions.
+func writeFileAtomically(fileToWriteFilepath string, writeContents func(writer io.Writer) error) error {
+	fileMode := os.FileMode(changelogFileMode)
+	if fileInfo, err := os.Stat(fileToWriteFilepath); err == nil {
+		fileMode = fileInfo.Mode().Perm()
+	}
+	dirpath := filepath.Dir(fileToWriteFilepath)
+	tempFile, err := os.CreateTemp(dirpath, "."+filepath.Base(fileToWriteFilepath)+".*")
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating a temporary file in '%s'", dirpath)
+	}
+	tempFilepath := tempFile.Name()
+	shouldRemoveTempFile := true
+	defer func() {
+		if shouldRemoveTempFile {
+			tempFile.Close()
+			os.Remove(tempFilepath)
+		}
+	}()
+	bufferedWriter := bufio.NewWriter(tempFile)
+	if err := writeContents(bufferedWriter); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing temporary file '%s'", tempFilepath)
+	}
+	if err := bufferedWriter.Flush(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing temporary file '%s'", tempFilepath)
+	}
+	if err := tempFile.Chmod(fileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred setting the permissions of temporary file '%s'", tempFilepath)
+	}
+	if err := tempFile.Sync(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred syncing temporary file '%s' to disk", tempFilepath)
+	}
+	if err := tempFile.Close(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred closing temporary file '%s'", tempFilepath)
+	}
+	if err := os.Rename(tempFilepath, fileToWriteFilepath); err != nil {
+		return stacktrace.Propagate(err, "An error occurred renaming temporary file '%s' to '%s'", tempFilepath, fileToWriteFilepath)
+	}
+	shouldRemoveTempFile = false
+	// The rename is only durable once the directory is synced too, which isn't possible on every platform (e.g. Windows),
+	// and the file is complete whether or not it is
+	if dir, err := os.Open(dirpath); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// resumeRelease finishes the pushes of a release whose local commit and tags were created by a previous run that
+// died before completing, pushing in the same vReleaseTag -> Commits -> Release Tag -> version track tags order as a
+// fresh release
+func resumeRelease(ctx context.Context, repository vcs.Repository, releaseBranchName string, state *releaseState) error {
+	headCommitHash, err := repository.GetHeadCommitHash()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while attempting to get the ref to HEAD of the local repository.")
+	}
+	if headCommitHash != state.ReleaseCommitHash {
+		return stacktrace.NewError("Local HEAD is on commit '%s' rather than release commit '%s'; until it's pushed, the release commit only exists where the release was cut, so if that clone is gone, run 'kudet release-state abort' and release again", headCommitHash, state.ReleaseCommitHash)
+	}
+
+	releaseTag := state.Version
+	vReleaseTag := fmt.Sprintf("%s%s", vPrefix, state.Version)
+	for _, tagName := range append([]string{releaseTag, vReleaseTag}, state.VersionTrackTags...) {
+		if err := ensureLocalReleaseTag(repository, tagName, state.ReleaseCommitHash); err != nil {
+			return stacktrace.Propagate(err, "An error occurred making sure local tag '%s' exists", tagName)
+		}
+	}
+
+	remoteRefHashes, err := repository.ListRemoteRefs(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred listing the refs on remote '%s'", originRemoteName)
+	}
+	progress, err := getRemoteReleaseProgress(remoteRefHashes, state, releaseBranchName)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining which parts of the release have already been pushed to '%s'", originRemoteName)
+	}
+	if progress.isComplete() {
+		logrus.Infof("Version '%s' has already been fully released to '%s'; nothing left to push", state.Version, originRemoteName)
+		return nil
+	}
+
+	var refSpecsToPush []string
+	if !progress.hasVPrefixedReleaseTag {
+		refSpecsToPush = append(refSpecsToPush, fmt.Sprintf("%s%s:%s%s", tagsPrefix, vReleaseTag, tagsPrefix, vReleaseTag))
+	}
+	if !progress.hasReleaseCommit {
+		refSpecsToPush = append(refSpecsToPush, fmt.Sprintf("%s%s:%s%s", headRef, releaseBranchName, headRef, releaseBranchName))
+	}
+	if !progress.hasReleaseTag {
+		refSpecsToPush = append(refSpecsToPush, fmt.Sprintf("%s%s:%s%s", tagsPrefix, releaseTag, tagsPrefix, releaseTag))
+	}
+	for _, versionTrackTag := range progress.missingVersionTrackTags {
+		refSpecsToPush = append(refSpecsToPush, fmt.Sprintf("%s%s:%s%s", tagsPrefix, versionTrackTag, tagsPrefix, versionTrackTag))
+	}
+	// Each push is done separately to preserve the push ordering guarantees of a fresh release
+	for _, refSpec := range refSpecsToPush {
+		logrus.Infof("Pushing '%s' to '%s'...", refSpec, originRemoteName)
+		if err := repository.Push(ctx, refSpec); err != nil {
+			return stacktrace.Propagate(err, "An error occurred pushing '%s' to '%s'", refSpec, originRemoteName)
+		}
+	}
+	return nil
+}
+
+// getDisallowedDirtyFilepaths picks out the modified files that don't match any of the allowed dirty paths, which are
+// globs or, if they end in '/', directories
+func getDisallowedDirtyFilepaths(modifiedFilepaths []string, allowedDirtyPaths []string) []string {
+	disallowedDirtyFilepaths := []string{}
+	for _, modifiedFilepath := range modifiedFilepaths {
+		isAllowed := false
+		for _, allowedDirtyPath := range allowedDirtyPaths {
+			if strings.HasSuffix(allowedDirtyPath, "/") {
+				isAllowed = strings.HasPrefix(modifiedFilepath, allowedDirtyPath)
+			} else {
+				// Invalid globs are refused when the config is loaded
+				isAllowed, _ = path.Match(allowedDirtyPath, modifiedFilepath)
+			}
+			if isAllowed {
+				break
+			}
+		}
+		if !isAllowed {
+			disallowedDirtyFilepaths = append(disallowedDirtyFilepaths, modifiedFilepath)
+		}
+	}
+	return disallowedDirtyFilepaths
+}
+
+// ensureLocalBranchForDetachedHead lets a detached HEAD on the remote release branch's commit, which is what CI
+// checkouts produce, be released by creating the local release branch there if it doesn't exist
+func ensureLocalBranchForDetachedHead(repository vcs.Repository, releaseBranchName string, remoteReleaseBranchHash string) error {
+	_, isOnBranch, err := repository.GetCheckedOutBranchName()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the checked out branch")
+	}
+	if isOnBranch {
+		return nil
+	}
+	headCommitHash, err := repository.GetHeadCommitHash()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while attempting to get the ref to HEAD of the local repository.")
+	}
+	if headCommitHash != remoteReleaseBranchHash {
+		if ciRef, description, isTemporary := getCiTemporaryRef(); isTemporary {
+			return stacktrace.NewErrorWithCode(ErrNotOnReleaseBranch.code, "HEAD is detached on commit '%s' of '%s', which is %s rather than release branch '%s'; refusing to release code that hasn't been merged yet", headCommitHash, ciRef, description, releaseBranchName)
+		}
+		return stacktrace.NewErrorWithCode(ErrNotOnReleaseBranch.code, "HEAD is detached on commit '%s' rather than on '%s/%s' ('%s'); check out '%s' to release", headCommitHash, originRemoteName, releaseBranchName, remoteReleaseBranchHash, releaseBranchName)
+	}
+	if _, err := repository.ResolveRevision(headRef + releaseBranchName); err == nil {
+		// The existing branch gets checked against the remote like any other
+		return nil
+	}
+	logrus.Infof("HEAD is detached on '%s/%s'; creating local branch '%s' there...", originRemoteName, releaseBranchName, releaseBranchName)
+	if err := repository.SetRef(headRef+releaseBranchName, headCommitHash); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating local branch '%s' on commit '%s'", releaseBranchName, headCommitHash)
+	}
+	return nil
+}
+
+// verifyRemoteBranchHasNotMoved checks the remote directly, rather than our possibly-stale remote-tracking branch, for
+// commits pushed to the release branch since the release started
+func verifyRemoteBranchHasNotMoved(ctx context.Context, repository vcs.Repository, releaseBranchName string, expectedCommitHash string) error {
+	remoteRefHashes, err := repository.ListRemoteRefs(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred listing the refs on remote '%s'", originRemoteName)
+	}
+	return checkRemoteBranchHasNotMoved(remoteRefHashes, releaseBranchName, expectedCommitHash)
+}
+
+func checkRemoteBranchHasNotMoved(remoteRefHashes map[string]string, releaseBranchName string, expectedCommitHash string) error {
+	remoteBranchHash, found := remoteRefHashes[headRef+releaseBranchName]
+	if !found {
+		return stacktrace.NewError("Couldn't find the '%s' branch on remote '%s'", releaseBranchName, originRemoteName)
+	}
+	if remoteBranchHash != expectedCommitHash {
+		return stacktrace.NewError("The '%s' branch on remote '%s' moved from commit '%s' to '%s' while the release was running; nothing has been pushed, so pull the new commits and re-run the release", releaseBranchName, originRemoteName, expectedCommitHash, remoteBranchHash)
+	}
+	return nil
+}
+
+// verifyReleaseTagsDoNotExist makes sure we don't try to recreate the tags of a version that has already been (partially) released
+func verifyReleaseTagsDoNotExist(repository vcs.Repository, releaseVersion string) error {
+	for _, tagName := range []string{releaseVersion, vPrefix + releaseVersion} {
+		_, found, err := repository.GetTagCommitHash(tagName)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred checking whether tag '%s' already exists", tagName)
+		}
+		if found {
+			return stacktrace.NewErrorWithCode(ErrReleaseTagExists.code, "Tag '%s' for the next release version already exists locally, meaning version '%s' was already at least partially released. Make sure both the '%s' and '%s%s' tags exist on '%s' and point at the same commit before releasing again.", tagName, releaseVersion, releaseVersion, vPrefix, releaseVersion, originRemoteName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,491 @@
+package releaser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemverRegex(t *testing.T) {
+	validStrings := []string{"0.0.0", "1.26.11234", "0.1.11", "1.2.3", "1.2.3-rc.1", "1.2.3+build.5", "1.2.3-beta+exp.sha.5114f85"}
+	invalidStrings := []string{" 0.0.0", "1.1", ".5.6", "1.2.", "..", "0.0.0 ", "1x2x3", "01.2.3", "v1.2.3", "1.2.3-"}
+
+	testRegexPattern(t, "Semver", semverRegexStr, validStrings, invalidStrings)
+}
+
+func TestVersionToBeReplacedPlaceholderHeaderRegex(t *testing.T) {
+	validStrings := []string{"# TBD", "# TBD  ", "#TBD"}
+	invalidStrings := []string{"## TBD", "# TD "}
+
+	testRegexPattern(t, "Version to Be Replaced Placeholder Header", versionToBeReleasedPlaceholderHeaderRegexStr, validStrings, invalidStrings)
+}
+
+func TestVersionHeaderRegex(t *testing.T) {
+	validStrings := []string{"# 1.54.2", "#1.5.2", "# 1.5.2-rc.1"}
+	invalidStrings := []string{"## 1.54.2", "1.5.2", "# ..", "# 1.52.", "# 1..25", "# 1.52", "# 1x5x2"}
+
+	testRegexPattern(t, "Version Header", versionHeaderRegexStr, validStrings, invalidStrings)
+}
+
+func TestBreakingChangesSubheader(t *testing.T) {
+	validStrings := []string{"### Breaking Changes", "### breaking changes", "### break", "## Breaking Chages", "###BreakingChanges", "### Break", "### 💥 BREAKING CHANGES", "## Backwards-incompatible changes", "#### Incompatible Changes"}
+	invalidStrings := []string{"Breaking Changes", "### Breking Changes", " ## Break", "### Non-breaking changes", "# Breaking Changes"}
+
+	for _, validString := range validStrings {
+		unreleased := changelog.NewVersion("# TBD", []string{validString, "* Something"})
+		require.True(t, hasSectionWithEntries(unreleased.Sections, isBreakingChangesSection), "Expected '%s' to be a breaking changes subheader", validString)
+	}
+	for _, invalidString := range invalidStrings {
+		unreleased := changelog.NewVersion("# TBD", []string{invalidString, "* Something"})
+		require.False(t, hasSectionWithEntries(unreleased.Sections, isBreakingChangesSection), "Expected '%s' not to be a breaking changes subheader", invalidString)
+	}
+}
+
+func Test_parseChangeLogFileNegativeTest(t *testing.T) {
+
+	// test inputs
+	noVersionFound :=
+		`#TBD
+* Something
+* Something else`
+
+	tbdNotPresent :=
+		`
+* Something
+* Something else`
+
+	multipleTBDFound :=
+		`# TBD
+* Something
+# TBD
+* Something else`
+
+	noNewUpdatesForCurrentRelease :=
+		`# TBD
+
+		
+# 0.1.0
+* Something else
+# 0.1.1
+- Foo
+`
+
+	outOfPlaceTBD :=
+		` 
+
+# 0.1.1
+## Breaking Changes
+- Something
+# 0.1.0
+# TBD
+`
+
+	noChangesBetweenTbdAndLastVersion :=
+		`# TBD
+# 0.1.1
+## Breaking Changes
+* Something
+# 0.1.0
+- Bar
+`
+
+	type args struct {
+		changelogFile string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		wantErr  bool
+		errorMsg string
+	}{
+		{
+			name: "noVersionFound",
+			args: args{
+				changelogFile: noVersionFound,
+			},
+			wantErr:  true,
+			errorMsg: "No previous release versions were detected in this changelog",
+		},
+		{
+			name: "tbdNotPresent",
+			args: args{
+				changelogFile: tbdNotPresent,
+			},
+			wantErr:  true,
+			errorMsg: "TBD header is either missing or is not the first non empty line in changelog.md",
+		},
+		{
+			name: "multipleTBDFound",
+			args: args{
+				changelogFile: multipleTBDFound,
+			},
+			wantErr:  true,
+			errorMsg: fmt.Sprintf("Found more than %d TBD headers", expectedNumTBDHeaderLines),
+		},
+		{
+			name: "noNewUpdatesForCurrentRelease",
+			args: args{
+				changelogFile: noNewUpdatesForCurrentRelease,
+			},
+			wantErr:  true,
+			errorMsg: "changelog.md is empty for the current release",
+		},
+		{
+			name: "outOfPlaceTBD",
+			args: args{
+				changelogFile: outOfPlaceTBD,
+			},
+			wantErr:  true,
+			errorMsg: "TBD header is either missing or is not the first non empty line in changelog.md",
+		},
+		{
+			name: "noChangesBetweenTbdAndLastVersion",
+			args: args{
+				changelogFile: noChangesBetweenTbdAndLastVersion,
+			},
+			wantErr:  true,
+			errorMsg: "changelog.md is empty for the current release",
+		},
+	}
+	for _, changeLogText := range tests {
+		t.Run(changeLogText.name, func(t *testing.T) {
+			_, err := parseChangeLogFile([]byte(changeLogText.args.changelogFile), nil)
+			if changeLogText.wantErr {
+				require.NotNil(t, err)
+				require.ErrorContains(t, err, changeLogText.errorMsg, "parseChangeLogFileNegativeTest() should throw error")
+				return
+			}
+		})
+	}
+}
+
+func TestDoBreakingChangesExistIfChangelogIsValid(t *testing.T) {
+	onlyOneVersion :=
+		`#TBD
+* Something
+
+#0.1.0
+## Breaking Changes`
+
+	onlyOneVersionWithSpaces :=
+		`# TBD
+* Something
+
+# 0.1.0
+* Something`
+
+	onlyOneVersionTwoHashBreakingChanges :=
+		`#TBD
+* Something
+
+##Breaking Changes
+* Something else
+
+#0.1.0
+* Something`
+
+	onlyOneVersionThreeHashBreakingChanges :=
+		`#TBD
+* Something
+
+###Breaking Changes
+* Something else
+
+#0.1.0
+* Something`
+
+	onlyOneVersionFourHashBreakingChanges :=
+		`#TBD
+* Something
+
+####Breaking Changes
+* Something else
+
+#0.1.0
+* Something`
+
+	multipleVersions :=
+		`#TBD
+* Something
+
+#0.1.1
+* Something else
+
+#0.1.0
+* Something`
+
+	multipleVersionsBreakingChanges :=
+		`#TBD
+* Something
+
+### Breaking Changes
+* Something
+
+#0.1.1
+* Something else
+
+#0.1.0
+### Breaking Changes`
+
+	lowercaseBreakingChanges :=
+		`# TBD
+### breaking changes
+* Some breaks
+
+# 0.1.0
+* Something`
+
+	shouldHaveBreakingChanges := []string{onlyOneVersionTwoHashBreakingChanges, onlyOneVersionThreeHashBreakingChanges, onlyOneVersionFourHashBreakingChanges, multipleVersionsBreakingChanges, lowercaseBreakingChanges}
+	shouldNotHaveBreakingChanges := []string{onlyOneVersion, onlyOneVersionWithSpaces, multipleVersions}
+	testBreakingChangesExists(t, shouldHaveBreakingChanges, shouldNotHaveBreakingChanges)
+}
+
+func TestMajorChangesDetectedOnlyUnderTbdHeader(t *testing.T) {
+	majorChangesRegex := regexp.MustCompile(kudet_config.NewDefaultKudetConfig().MajorChangesSubheaderRegex)
+
+	majorChangesUnderTbd :=
+		`# TBD
+### Major Changes
+* Dropped the old API
+
+# 0.1.0
+* Something`
+
+	majorChangesInPreviousVersion :=
+		`# TBD
+### Breaking Changes
+* Something
+
+# 1.0.0
+### Major
+* Something else`
+
+	changes, err := parseChangeLogFile([]byte(majorChangesUnderTbd), &bumpRules{majorChangesRegex: majorChangesRegex})
+	require.NoError(t, err)
+	require.True(t, changes.hasMajorChange)
+
+	changes, err = parseChangeLogFile([]byte(majorChangesInPreviousVersion), &bumpRules{majorChangesRegex: majorChangesRegex})
+	require.NoError(t, err)
+	require.False(t, changes.hasMajorChange)
+	require.True(t, changes.hasBreakingChange)
+
+	changes, err = parseChangeLogFile([]byte(majorChangesUnderTbd), nil)
+	require.NoError(t, err)
+	require.False(t, changes.hasMajorChange)
+}
+
+func TestParseReleaseVersionTags(t *testing.T) {
+	tagNames := []string{"0.1.0", "v0.1.0", "v0.2.0", "0.3.0-rc.1", "0.3.0+build.5", "1x2x3", "release-0.4.0"}
+
+	versions, ignoredTagNames := parseReleaseVersionTags(tagNames, kudet_config.TagParsingConfig{})
+	require.Equal(t, []string{"0.1.0"}, versionStrings(versions))
+	require.Equal(t, []string{"v0.2.0", "0.3.0-rc.1", "0.3.0+build.5"}, ignoredTagNames)
+
+	versions, ignoredTagNames = parseReleaseVersionTags(tagNames, kudet_config.TagParsingConfig{
+		AllowVPrefix:                true,
+		AllowPrereleasesAndMetadata: true,
+	})
+	require.Equal(t, []string{"0.1.0", "0.1.0", "0.2.0", "0.3.0-rc.1", "0.3.0+build.5"}, versionStrings(versions))
+	require.Empty(t, ignoredTagNames)
+}
+
+func TestGetRemoteReleaseProgress(t *testing.T) {
+	baseCommitHash := "1111111111111111111111111111111111111111"
+	releaseCommitHash := "2222222222222222222222222222222222222222"
+	tagObjectHash := "3333333333333333333333333333333333333333"
+	state := &releaseState{Version: "0.2.0", BaseCommitHash: baseCommitHash, ReleaseCommitHash: releaseCommitHash}
+
+	onlyVTagPushed := map[string]string{
+		"refs/heads/main":  baseCommitHash,
+		"refs/tags/v0.2.0": tagObjectHash,
+	}
+	progress, err := getRemoteReleaseProgress(onlyVTagPushed, state, "main")
+	require.NoError(t, err)
+	require.Equal(t, remoteReleaseProgress{hasVPrefixedReleaseTag: true}, progress)
+	require.False(t, progress.isComplete())
+
+	commitPushed := map[string]string{
+		"refs/heads/main":  releaseCommitHash,
+		"refs/tags/v0.2.0": tagObjectHash,
+	}
+	progress, err = getRemoteReleaseProgress(commitPushed, state, "main")
+	require.NoError(t, err)
+	require.Equal(t, remoteReleaseProgress{hasVPrefixedReleaseTag: true, hasReleaseCommit: true}, progress)
+
+	fullyReleased := map[string]string{
+		"refs/heads/main":  releaseCommitHash,
+		"refs/tags/v0.2.0": tagObjectHash,
+		"refs/tags/0.2.0":  tagObjectHash,
+	}
+	progress, err = getRemoteReleaseProgress(fullyReleased, state, "main")
+	require.NoError(t, err)
+	require.True(t, progress.isComplete())
+
+	// The version tracks' tags are pushed last
+	state.VersionTrackTags = []string{"cli/v1.3.0"}
+	progress, err = getRemoteReleaseProgress(fullyReleased, state, "main")
+	require.NoError(t, err)
+	require.Equal(t, []string{"cli/v1.3.0"}, progress.missingVersionTrackTags)
+	require.False(t, progress.isComplete())
+
+	remoteMoved := map[string]string{
+		"refs/heads/main": "4444444444444444444444444444444444444444",
+	}
+	_, err = getRemoteReleaseProgress(remoteMoved, state, "main")
+	require.ErrorContains(t, err, "can't be resumed automatically")
+}
+
+func TestCheckRemoteBranchHasNotMoved(t *testing.T) {
+	startCommitHash := "1111111111111111111111111111111111111111"
+	require.NoError(t, checkRemoteBranchHasNotMoved(map[string]string{"refs/heads/main": startCommitHash}, "main", startCommitHash))
+
+	err := checkRemoteBranchHasNotMoved(map[string]string{"refs/heads/main": "2222222222222222222222222222222222222222"}, "main", startCommitHash)
+	require.ErrorContains(t, err, "re-run the release")
+
+	err = checkRemoteBranchHasNotMoved(map[string]string{"refs/heads/develop": startCommitHash}, "main", startCommitHash)
+	require.ErrorContains(t, err, "Couldn't find the 'main' branch")
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func testRegexPattern(t *testing.T, regexPatternName string, regexPatternStr string, validStrings []string, invalidStrings []string) {
+	regexPattern := regexp.MustCompile(regexPatternStr)
+
+	for _, str := range validStrings {
+		patternDetected := regexPattern.Match([]byte(str))
+		require.True(t, patternDetected, "%s Pattern was not detected in this string when it should have been: '%s'.", regexPatternName, str)
+	}
+
+	for _, str := range invalidStrings {
+		patternDetected := regexPattern.Match([]byte(str))
+		require.False(t, patternDetected, "%s Pattern was detected in this string when it should not have been: '%s'.", regexPatternName, str)
+	}
+}
+
+func versionStrings(versions []*semver.Version) []string {
+	result := []string{}
+	for _, version := range versions {
+		result = append(result, version.String())
+	}
+	return result
+}
+
+func testBreakingChangesExists(t *testing.T, validStrings []string, invalidStrings []string) {
+	for _, str := range validStrings {
+		changes, err := parseChangeLogFile([]byte(str), nil)
+		require.NoError(t, err, "An error occurred testing if breaking changes existed.")
+		require.True(t, changes.hasBreakingChange, "Breaking Changes were not detected in this string when it should have been:\n%s", str)
+	}
+
+	for _, str := range invalidStrings {
+		changes, err := parseChangeLogFile([]byte(str), nil)
+		require.NoError(t, err, "An error occurred testing if breaking changes existed.")
+		require.False(t, changes.hasBreakingChange, "Breaking Changes were detected in this string when it should not have been:\n%s", str)
+	}
+}
+
+func TestGetDisallowedDirtyFilepaths(t *testing.T) {
+	modifiedFilepaths := []string{
+		"api/service.pb.go",
+		"docs/generated/cli.md",
+		"docs/generated/nested/flags.md",
+		"main.go",
+	}
+	allowedDirtyPaths := []string{"docs/generated/", "api/*.pb.go"}
+	require.Equal(t, []string{"main.go"}, getDisallowedDirtyFilepaths(modifiedFilepaths, allowedDirtyPaths))
+	require.Equal(t, modifiedFilepaths, getDisallowedDirtyFilepaths(modifiedFilepaths, nil))
+	require.Empty(t, getDisallowedDirtyFilepaths(nil, allowedDirtyPaths))
+}
+
+func TestGetReleaseCommitMessage(t *testing.T) {
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	releaseTime := time.Date(2022, 6, 1, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+	getMessage := func() string {
+		commitMsg, err := getReleaseCommitMessage(kudetConfig, "1.2.3", "1.2.2", releaseTime)
+		require.NoError(t, err)
+		return commitMsg
+	}
+	require.Equal(t, "Finalize changes for release version '1.2.3'", getMessage())
+
+	kudetConfig.SkipCiMarker = " skip-checks: true\n"
+	require.Equal(t, "Finalize changes for release version '1.2.3'\n\nskip-checks: true", getMessage())
+
+	kudetConfig.SkipCiMarker = "[skip ci]"
+	kudetConfig.ReleaseCommitMessageTemplate = "REL-1 :bookmark: {{.PreviousVersion}} -> {{.Version}} ({{.Date}})"
+	require.Equal(t, "REL-1 :bookmark: 1.2.2 -> 1.2.3 (2022-06-02)\n\n[skip ci]", getMessage())
+}
+
+func TestUpdateChangelog_KeepsCrlfLineEndings(t *testing.T) {
+	changelogContents := "# TBD\r\n* Added a thing\r\n\r\n# 0.1.0\r\n* Initial\r\n"
+	changes, err := parseChangeLogFile([]byte(changelogContents), nil)
+	require.NoError(t, err)
+	require.False(t, changes.hasBreakingChange)
+
+	changelogFilepath := filepath.Join(t.TempDir(), "changelog.md")
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte(changelogContents), 0644))
+	require.NoError(t, updateChangelog(changelogFilepath, "0.1.1", nil, changelog.DefaultMaxLineLength))
+	updatedChangelog, err := os.ReadFile(changelogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, "# TBD\r\n\r\n# 0.1.1\r\n* Added a thing\r\n\r\n# 0.1.0\r\n* Initial\r\n", string(updatedChangelog))
+}
+
+func TestUpdateChangelog_OnlyReadsHead(t *testing.T) {
+	// Released versions are copied as they are, so their lines can be longer than the head's may be
+	releasedVersionsContents := "# 0.1.0\n* " + strings.Repeat("x", 100) + "\n"
+	changelogFilepath := filepath.Join(t.TempDir(), "changelog.md")
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte("# TBD\n* Added a thing\n\n"+releasedVersionsContents), 0644))
+	require.NoError(t, updateChangelog(changelogFilepath, "0.1.1", nil, 64))
+	updatedChangelog, err := os.ReadFile(changelogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, "# TBD\n\n# 0.1.1\n* Added a thing\n\n"+releasedVersionsContents, string(updatedChangelog))
+
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte("# TBD\n* "+strings.Repeat("x", 100)+"\n\n"+releasedVersionsContents), 0644))
+	require.ErrorContains(t, updateChangelog(changelogFilepath, "0.1.1", nil, 64), "longer than the max line length")
+}
+
+func TestWriteFileAtomically(t *testing.T) {
+	dirpath := t.TempDir()
+	changelogFilepath := filepath.Join(dirpath, "changelog.md")
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte("# TBD\n"), 0600))
+	require.NoError(t, writeFileAtomically(changelogFilepath, func(writer io.Writer) error {
+		_, err := io.WriteString(writer, "# TBD\n\n# 0.1.0\n")
+		return err
+	}))
+	updatedChangelog, err := os.ReadFile(changelogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, "# TBD\n\n# 0.1.0\n", string(updatedChangelog))
+	fileInfo, err := os.Stat(changelogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+
+	// A write that fails partway through leaves the file as it was, and no temporary file to be swept into the release
+	// commit
+	err = writeFileAtomically(changelogFilepath, func(writer io.Writer) error {
+		if _, err := io.WriteString(writer, "# TBD\n"); err != nil {
+			return err
+		}
+		return stacktrace.NewError("The disk is full")
+	})
+	require.ErrorContains(t, err, "The disk is full")
+	updatedChangelog, err = os.ReadFile(changelogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, "# TBD\n\n# 0.1.0\n", string(updatedChangelog))
+	dirEntries, err := os.ReadDir(dirpath)
+	require.NoError(t, err)
+	require.Len(t, dirEntries, 1)
+}
@@ -0,0 +1,168 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Any of the execute bits
+const executableFileModeBits = 0111
+
+// LintCheck is one of the prerequisites of releasing the repo, and whether the repo meets it
+type LintCheck struct {
+	Description string
+
+	IsPassed bool
+
+	// Why the check failed, or anything worth knowing about how it passed; empty if there's nothing to add
+	Detail string
+}
+
+// LintRepo checks that the repo meets what releasing it needs, so that new repos can find out before their first
+// release: a changelog with a TBD header, existing and executable pre release scripts, the origin remote, the git
+// author, the release branch and, if a token is given, the token's scopes. Nothing is fetched or written, and the
+// checks that need the repository fail if it can't be opened.
+func LintRepo(ctx context.Context, repoDirpath string, token string, kudetConfig *kudet_config.KudetConfig) []LintCheck {
+	checks := []LintCheck{
+		newLintCheck(fmt.Sprintf("Changelog '%s' exists with a TBD header", kudetConfig.ChangelogFilepath), getChangelogLintProblem(repoDirpath, kudetConfig.ChangelogFilepath)),
+		newLintCheck(fmt.Sprintf("The pre release scripts listed in '%s' exist and are executable", kudetConfig.PreReleaseScriptsFilepath), getPreReleaseScriptsLintProblem(repoDirpath, kudetConfig.PreReleaseScriptsFilepath)),
+	}
+
+	originRemoteDescription := fmt.Sprintf("Remote '%s' is configured", originRemoteName)
+	authorDescription := "Git's user.name and user.email are set"
+	releaseBranchDescription := fmt.Sprintf("Release branch '%s' exists", kudetConfig.ReleaseBranch)
+	tokenDescription := "The token has the scopes that releasing needs"
+	repository, err := vcs.OpenRepository(repoDirpath, token)
+	if err != nil {
+		checks = append(checks, newLintCheck(originRemoteDescription, fmt.Sprintf("The repo or its remote '%s' can't be opened; add the remote with 'git remote add %s <url>': %v", originRemoteName, originRemoteName, stacktrace.RootCause(err))))
+		repositoryProblem := fmt.Sprintf("Can't be checked until remote '%s' is configured", originRemoteName)
+		checks = append(checks, newLintCheck(authorDescription, repositoryProblem), newLintCheck(releaseBranchDescription, repositoryProblem))
+		if token != "" {
+			checks = append(checks, newLintCheck(tokenDescription, repositoryProblem))
+		}
+		return checks
+	}
+	checks = append(checks, newLintCheck(originRemoteDescription, ""))
+
+	authorProblem := ""
+	if _, err := repository.GetAuthor(); err != nil {
+		authorProblem = "Release commits and tags are made as git's configured user; set it with 'git config --global user.name <name>' and 'git config --global user.email <email>'"
+	}
+	checks = append(checks, newLintCheck(authorDescription, authorProblem))
+
+	releaseBranchProblem := ""
+	remoteReleaseBranchName := fmt.Sprintf("%v/%v", originRemoteName, kudetConfig.ReleaseBranch)
+	if _, err := repository.ResolveRevision(kudetConfig.ReleaseBranch); err != nil {
+		if _, err := repository.ResolveRevision(remoteReleaseBranchName); err != nil {
+			releaseBranchProblem = fmt.Sprintf("There's no '%s' branch locally or from remote '%s'; create it, or set '%s' in the kudet config to the branch that releases are made from", kudetConfig.ReleaseBranch, originRemoteName, kudet_config.ReleaseBranchKey)
+		}
+	}
+	checks = append(checks, newLintCheck(releaseBranchDescription, releaseBranchProblem))
+
+	if token != "" {
+		checks = append(checks, getTokenScopesLintCheck(ctx, repository, token, kudetConfig.Forge, tokenDescription))
+	}
+	return checks
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newLintCheck(description string, problem string) LintCheck {
+	return LintCheck{
+		Description: description,
+		IsPassed:    problem == "",
+		Detail:      problem,
+	}
+}
+
+// getChangelogLintProblem requires the TBD header to be the changelog's first non-empty line, as releasing does
+func getChangelogLintProblem(repoDirpath string, changelogRelFilepath string) string {
+	changelogFile, err := os.ReadFile(filepath.Join(repoDirpath, changelogRelFilepath))
+	if os.IsNotExist(err) {
+		return fmt.Sprintf("There's no changelog; create it with a '# %s' header for the entries of the next release, e.g. with 'kudet init'", changelog.UnreleasedVersionName)
+	}
+	if err != nil {
+		return fmt.Sprintf("The changelog can't be read: %v", stacktrace.RootCause(err))
+	}
+	parsedChangelog, err := changelog.Parse(changelogFile)
+	if err != nil {
+		return fmt.Sprintf("The changelog is invalid: %v", stacktrace.RootCause(err))
+	}
+	for _, entry := range parsedChangelog.Preamble {
+		if strings.TrimSpace(entry.Line) != "" {
+			return fmt.Sprintf("The changelog's first non-empty line is '%s' rather than its '# %s' header", entry.Line, changelog.UnreleasedVersionName)
+		}
+	}
+	if len(parsedChangelog.Versions) == 0 || !parsedChangelog.Versions[0].IsUnreleased() {
+		return fmt.Sprintf("The changelog doesn't start with a '# %s' header for the entries of the next release", changelog.UnreleasedVersionName)
+	}
+	return ""
+}
+
+// getPreReleaseScriptsLintProblem only requires the execute bit of the scripts that are run directly, rather than
+// through an interpreter
+func getPreReleaseScriptsLintProblem(repoDirpath string, preReleaseScriptsRelFilepath string) string {
+	scripts, err := getPreReleaseScripts(repoDirpath, preReleaseScriptsRelFilepath)
+	if err != nil {
+		return fmt.Sprintf("The pre release scripts file can't be read; create it, even if it's empty: %v", stacktrace.RootCause(err))
+	}
+	missingScriptRelFilepaths := []string{}
+	nonExecutableScriptRelFilepaths := []string{}
+	for _, script := range scripts {
+		if script.isInline() {
+			continue
+		}
+		scriptFileInfo, err := os.Stat(filepath.Join(repoDirpath, script.relFilepath))
+		if err != nil || scriptFileInfo.IsDir() {
+			missingScriptRelFilepaths = append(missingScriptRelFilepaths, script.relFilepath)
+			continue
+		}
+		isRunDirectly := runtime.GOOS != windowsGoos && len(getScriptInterpreter(script.relFilepath, runtime.GOOS)) == 0
+		if isRunDirectly && scriptFileInfo.Mode().Perm()&executableFileModeBits == 0 {
+			nonExecutableScriptRelFilepaths = append(nonExecutableScriptRelFilepaths, script.relFilepath)
+		}
+	}
+	problems := []string{}
+	if len(missingScriptRelFilepaths) > 0 {
+		problems = append(problems, fmt.Sprintf("Scripts '%s' don't exist", strings.Join(missingScriptRelFilepaths, "', '")))
+	}
+	if len(nonExecutableScriptRelFilepaths) > 0 {
+		problems = append(problems, fmt.Sprintf("Scripts '%s' aren't executable; run 'chmod +x' on them", strings.Join(nonExecutableScriptRelFilepaths, "', '")))
+	}
+	return strings.Join(problems, "; ")
+}
+
+func getTokenScopesLintCheck(ctx context.Context, repository vcs.Repository, token string, forgeConfig kudet_config.ForgeConfig, description string) LintCheck {
+	remoteUrl, err := repository.GetRemoteUrl()
+	if err != nil {
+		return newLintCheck(description, fmt.Sprintf("Remote '%s' has no URL: %v", originRemoteName, stacktrace.RootCause(err)))
+	}
+	remoteForge, err := getForge(remoteUrl, forgeConfig, token)
+	if err != nil {
+		return newLintCheck(description, fmt.Sprintf("The forge of remote '%s' can't be determined: %v", originRemoteName, stacktrace.RootCause(err)))
+	}
+	missingScopes, isKnown, err := remoteForge.getMissingTokenScopes(ctx)
+	if err != nil {
+		return newLintCheck(description, fmt.Sprintf("The token's scopes can't be gotten from %s: %v", remoteForge.getName(), stacktrace.RootCause(err)))
+	}
+	if !isKnown {
+		check := newLintCheck(description, "")
+		check.Detail = fmt.Sprintf("%s doesn't list the token's scopes, e.g. because it has fine-grained permissions; it needs write access to the repo's contents and releases", remoteForge.getName())
+		return check
+	}
+	if len(missingScopes) > 0 {
+		return newLintCheck(description, fmt.Sprintf("The token is missing %s scopes '%s'", remoteForge.getName(), strings.Join(missingScopes, "', '")))
+	}
+	return newLintCheck(description, "")
+}
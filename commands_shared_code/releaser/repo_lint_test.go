@@ -0,0 +1,92 @@
+package releaser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintRepo(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	writeFile := func(relFilepath string, contents string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repoDirpath, relFilepath)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, relFilepath), []byte(contents), 0644))
+	}
+	writeFile(kudetConfig.ChangelogFilepath, "# Changelog\n# TBD\n")
+	writeFile(kudetConfig.PreReleaseScriptsFilepath, "scripts/bump.sh\nscripts/missing.sh\n$ echo inline\n")
+	writeFile("scripts/bump.sh", "#!/bin/sh\n")
+
+	checks := LintRepo(context.Background(), repoDirpath, "token", kudetConfig)
+	require.Len(t, checks, 6)
+	require.False(t, checks[0].IsPassed)
+	require.Contains(t, checks[0].Detail, "The changelog's first non-empty line is '# Changelog'")
+	require.False(t, checks[1].IsPassed)
+	require.Equal(t, "Scripts 'scripts/missing.sh' don't exist; Scripts 'scripts/bump.sh' aren't executable; run 'chmod +x' on them", checks[1].Detail)
+	require.False(t, checks[2].IsPassed)
+	// Without the origin remote, nothing that needs the repository can be checked
+	for _, check := range checks[3:] {
+		require.False(t, check.IsPassed)
+		require.Equal(t, "Can't be checked until remote 'origin' is configured", check.Detail)
+	}
+
+	tokenScopes := "read:org, public_repo"
+	githubApi := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repos/owner/repo", r.URL.Path)
+		if tokenScopes != "" {
+			w.Header().Set(githubOAuthScopesHeaderName, tokenScopes)
+		}
+		_, err := w.Write([]byte(`{"private": true}`))
+		require.NoError(t, err)
+	}))
+	defer githubApi.Close()
+	kudetConfig.Forge = kudet_config.ForgeConfig{Type: kudet_config.GithubForgeType, ApiUrl: githubApi.URL}
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{"https://github.com/owner/repo.git"}})
+	require.NoError(t, err)
+	xdgConfigDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(xdgConfigDirpath, "git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(xdgConfigDirpath, "git", "config"), []byte("[user]\n\tname = Kudet\n\temail = kudet@example.com\n"), 0644))
+	t.Setenv("XDG_CONFIG_HOME", xdgConfigDirpath)
+	writeFile(kudetConfig.ChangelogFilepath, "\n# TBD\n")
+	require.NoError(t, os.Chmod(filepath.Join(repoDirpath, "scripts", "bump.sh"), 0755))
+	writeFile(kudetConfig.PreReleaseScriptsFilepath, "scripts/bump.sh\n$ echo inline\n")
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	_, err = repository.CommitAll("Initial commit", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+
+	checks = LintRepo(context.Background(), repoDirpath, "token", kudetConfig)
+	require.Len(t, checks, 6)
+	for _, check := range checks[:4] {
+		require.True(t, check.IsPassed, check.Description)
+	}
+	// The initial commit is on go-git's default branch, which isn't the default release branch
+	require.False(t, checks[4].IsPassed)
+	require.False(t, checks[5].IsPassed)
+	require.Equal(t, "The token is missing GitHub scopes 'repo'", checks[5].Detail)
+
+	kudetConfig.ReleaseBranch = "master"
+	tokenScopes = ""
+	checks = LintRepo(context.Background(), repoDirpath, "token", kudetConfig)
+	for _, check := range checks {
+		require.True(t, check.IsPassed, check.Description)
+	}
+	// Fine-grained tokens have no scopes to check
+	require.Contains(t, checks[5].Detail, "GitHub doesn't list the token's scopes")
+
+	// The token's scopes are only checked when there's a token
+	checks = LintRepo(context.Background(), repoDirpath, "", kudetConfig)
+	require.Len(t, checks, 5)
+}
@@ -0,0 +1,191 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	rollbackNotificationEvent = "rollback"
+
+	// The rollout was unhealthy, and the environments it was promoted to were rolled back
+	rolledBackRolloutStatus = "rolled-back"
+)
+
+// rollbackNotification is the JSON body POSTed to each configured webhook when environments are rolled back off a
+// release
+type rollbackNotification struct {
+	Event               string   `json:"event"`
+	Version             string   `json:"version"`
+	RolledBackToVersion string   `json:"rolledBackToVersion"`
+	Environments        []string `json:"environments"`
+	Reason              string   `json:"reason"`
+	RepoOwners          []string `json:"repoOwners,omitempty"`
+}
+
+// RollbackEnvironments pins the named environments, which must be on the version, back to the release before it once
+// the confirmer approves; the version's forge release is marked as superseded and the webhooks are notified
+func (releaser *Releaser) RollbackEnvironments(ctx context.Context, version string, environmentNames []string, reason string) error {
+	kudetConfig, err := releaser.getKudetConfig()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the kudet config for the repo")
+	}
+	if len(environmentNames) == 0 {
+		return stacktrace.NewError("At least one environment to roll back is required")
+	}
+	environments := []kudet_config.EnvironmentManifestConfig{}
+	for _, environmentName := range environmentNames {
+		environment, found := getEnvironment(kudetConfig.Environments, environmentName)
+		if !found {
+			return stacktrace.NewError("Can't roll back environment '%s' as it isn't configured under '%s.%s' in the kudet config", environmentName, kudet_config.EnvironmentsKey, kudet_config.EnvironmentManifestsKey)
+		}
+		environments = append(environments, environment)
+	}
+
+	repository, err := releaser.openRepository(releaser.repoDirpath, releaser.token)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	if err := releaser.authenticateAsGithubAppIfNeeded(ctx, repository, kudetConfig); err != nil {
+		return stacktrace.Propagate(err, "An error occurred authenticating as the kudet config's GitHub App")
+	}
+	tagNames, err := repository.ListTagNames()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred listing the repo's tags")
+	}
+	previousVersion, err := getPreviousReleaseVersion(tagNames, kudetConfig.TagParsing, version)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred determining the release to roll back to")
+	}
+
+	logrus.Infof("Environments '%s' are about to be rolled back from version '%s' to '%s'", strings.Join(environmentNames, ", "), version, previousVersion)
+	isConfirmed, err := releaser.confirmer(ctx, i18n.Sprintf(i18n.ConfirmRollbackQuestion, strings.Join(environmentNames, "', '"), version, previousVersion))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting approval to roll back version '%s'", version)
+	}
+	if !isConfirmed {
+		return stacktrace.NewError("Rollback of version '%s' wasn't approved", version)
+	}
+	if err := releaser.rollbackRelease(ctx, repository, kudetConfig, environments, version, previousVersion, reason); err != nil {
+		return stacktrace.Propagate(err, "An error occurred rolling back version '%s'", version)
+	}
+	return nil
+}
+
+// rollbackRelease pins the environments back to the previous version, then marks the version's forge release as
+// superseded and notifies the webhooks; once the environments are rolled back, failures are only logged
+func (releaser *Releaser) rollbackRelease(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, environments []kudet_config.EnvironmentManifestConfig, version string, previousVersion string, reason string) error {
+	environmentNames := []string{}
+	expectedPinnedVersions := []pinnedVersion{}
+	for _, environment := range environments {
+		environmentNames = append(environmentNames, environment.Name)
+		expectedPinnedVersions = append(expectedPinnedVersions, pinnedVersion{environment: environment, version: version})
+	}
+	logrus.Infof("Rolling environments '%s' back from version '%s' to '%s'...", strings.Join(environmentNames, ", "), version, previousVersion)
+	commitMsg := fmt.Sprintf("Roll back %s %s to %s in %s\n\n%s", filepath.Base(releaser.repoDirpath), version, previousVersion, strings.Join(environmentNames, ", "), reason)
+	if err := releaser.pinEnvironmentVersions(ctx, kudetConfig.Environments, environments, previousVersion, commitMsg, expectedPinnedVersions); err != nil {
+		return stacktrace.Propagate(err, "An error occurred pinning environments '%s' back to version '%s'", strings.Join(environmentNames, ", "), previousVersion)
+	}
+	logrus.Infof("Rolled environments '%s' back to version '%s'", strings.Join(environmentNames, ", "), previousVersion)
+
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred determining the forge to mark release '%s' as superseded on; please mark it by hand:\n%v", version, err)
+	} else {
+		notice := fmt.Sprintf("**Superseded:** this release was rolled back to %s. %s", previousVersion, reason)
+		if err := releaseForge.markReleaseSuperseded(ctx, version, notice); err != nil {
+			logrus.Errorf("ACTION REQUIRED: An error occurred marking the %s release of version '%s' as superseded; please mark it by hand:\n%v", releaseForge.getName(), version, err)
+		} else {
+			logrus.Infof("Marked the %s release of version '%s' as superseded", releaseForge.getName(), version)
+		}
+	}
+
+	repoOwners, err := getRepoOwners(releaser.repoDirpath, kudetConfig.Ownership)
+	if err != nil {
+		logrus.Warnf("An error occurred determining the repo owners to mention in rollback notifications; they'll be left out:\n%v", err)
+	}
+	sendRollbackNotifications(kudetConfig.Notifications.WebhookUrls, &rollbackNotification{
+		Event:               rollbackNotificationEvent,
+		Version:             version,
+		RolledBackToVersion: previousVersion,
+		Environments:        environmentNames,
+		Reason:              reason,
+		RepoOwners:          repoOwners,
+	})
+	return nil
+}
+
+// rollbackUnhealthyReleaseIfNeeded rolls the environments that an unhealthy release was promoted to back to the previous
+// release, if the releaser was asked to; the returned rollout status records whether it did
+func (releaser *Releaser) rollbackUnhealthyReleaseIfNeeded(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, state *releaseState, promotedEnvironmentNames []string, rolloutStatus string) string {
+	if rolloutStatus != unhealthyRolloutStatus || !releaser.shouldRollbackOnUnhealthy || len(promotedEnvironmentNames) == 0 {
+		return rolloutStatus
+	}
+	if state.PreviousVersion == "" || state.PreviousVersion == noPreviousVersion {
+		logrus.Errorf("ACTION REQUIRED: Version '%s' is the first release, so there's nothing to roll environments '%s' back to; please fix them by hand", state.Version, strings.Join(promotedEnvironmentNames, ", "))
+		return rolloutStatus
+	}
+	environments := []kudet_config.EnvironmentManifestConfig{}
+	for _, environmentName := range promotedEnvironmentNames {
+		environment, _ := getEnvironment(kudetConfig.Environments, environmentName)
+		environments = append(environments, environment)
+	}
+	reason := fmt.Sprintf("The rollout of %s failed its health check.", state.Version)
+	if err := releaser.rollbackRelease(ctx, repository, kudetConfig, environments, state.Version, state.PreviousVersion, reason); err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred rolling back unhealthy version '%s'; please roll it back by hand with 'kudet rollback-env':\n%v", state.Version, err)
+		return rolloutStatus
+	}
+	return rolledBackRolloutStatus
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getPreviousReleaseVersion finds the latest release before the version, which must itself be a release
+func getPreviousReleaseVersion(tagNames []string, tagParsingConfig kudet_config.TagParsingConfig, version string) (string, error) {
+	releaseVersion, err := semver.StrictNewVersion(version)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "'%s' isn't a valid semantic version", version)
+	}
+	versions, _ := parseReleaseVersionTags(tagNames, tagParsingConfig)
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+	isReleased := false
+	for _, candidateVersion := range versions {
+		if candidateVersion.Equal(releaseVersion) {
+			isReleased = true
+			continue
+		}
+		if isReleased && candidateVersion.LessThan(releaseVersion) {
+			return candidateVersion.String(), nil
+		}
+	}
+	if !isReleased {
+		return "", stacktrace.NewError("Version '%s' hasn't been released", version)
+	}
+	return "", stacktrace.NewError("Version '%s' is the first release, so there's nothing to roll back to", version)
+}
+
+func sendRollbackNotifications(webhookUrls []string, notification *rollbackNotification) {
+	if len(webhookUrls) == 0 {
+		return
+	}
+	logrus.Infof("Sending rollback notifications...")
+	httpClient := &http.Client{Timeout: notificationTimeout}
+	for _, webhookUrl := range webhookUrls {
+		if err := sendReleaseNotification(httpClient, webhookUrl, notification); err != nil {
+			logrus.Warnf("An error occurred notifying webhook '%s' of the rollback of version '%s':\n%v", webhookUrl, notification.Version, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetPreviousReleaseVersion(t *testing.T) {
+	tagNames := []string{"0.1.0", "0.2.0", "0.2.1", "0.3.0-rc.1", "1.0.0", "not-a-version"}
+
+	previousVersion, err := getPreviousReleaseVersion(tagNames, kudet_config.TagParsingConfig{}, "1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "0.2.1", previousVersion)
+
+	previousVersion, err = getPreviousReleaseVersion(tagNames, kudet_config.TagParsingConfig{}, "0.2.0")
+	require.NoError(t, err)
+	require.Equal(t, "0.1.0", previousVersion)
+
+	_, err = getPreviousReleaseVersion(tagNames, kudet_config.TagParsingConfig{}, "0.1.0")
+	require.Error(t, err)
+
+	_, err = getPreviousReleaseVersion(tagNames, kudet_config.TagParsingConfig{}, "0.4.0")
+	require.Error(t, err)
+
+	_, err = getPreviousReleaseVersion(tagNames, kudet_config.TagParsingConfig{}, "v1")
+	require.Error(t, err)
+}
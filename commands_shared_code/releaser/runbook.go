@@ -0,0 +1,733 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// The file, relative to the repo root, that the runbook gets rendered into
+	RunbookFilename = "RELEASE.md"
+
+	runbookFileMode        = 0644
+	runbookGeneratedMarker = "<!-- This file is generated by `kudet runbook` from the repo's kudet configuration; do not edit it by hand -->"
+
+	// The steps that hooks hang off in the pipeline graph
+	releasePolicyStepTitle     = "Release policy"
+	preReleaseScriptsStepTitle = "Pre-release scripts"
+	releaseCommitStepTitle     = "Release commit and tags"
+	notificationsStepTitle     = "Notifications"
+)
+
+// releaseStep is a human-readable description of one phase of the release flow, in the order Release executes them
+type releaseStep struct {
+	title       string
+	description []string
+
+	// Whether the step only checks that the release may go ahead, refusing it otherwise
+	isGate bool
+}
+
+// RenderRunbook renders a Markdown description of exactly what 'kudet release' will do in the given repo
+func RenderRunbook(repoDirpath string, kudetConfig *kudet_config.KudetConfig) (string, error) {
+	preReleaseScripts, err := getPreReleaseScripts(repoDirpath, kudetConfig.PreReleaseScriptsFilepath)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred getting the pre release scripts of the repo")
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString(runbookGeneratedMarker + "\n\n")
+	builder.WriteString("# Release runbook\n\n")
+	builder.WriteString("Releases of this repo are cut by running `kudet release <token>` from the repo root. The release performs the following steps in order; if any step fails or the release is interrupted with Ctrl-C, the local branch is reset, local tags are deleted, and the pushed `v`-prefixed tag is deleted from the remote.\n")
+	for idx, step := range getReleaseSteps(kudetConfig, preReleaseScripts) {
+		builder.WriteString(fmt.Sprintf("\n## %d. %s\n\n", idx+1, step.title))
+		for _, line := range step.description {
+			builder.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+	}
+	return builder.String(), nil
+}
+
+// regenerateRunbookIfPresent keeps an existing runbook in line with the current configuration, so that it gets
+// updated as part of the release commit; repos without a runbook are left untouched
+func regenerateRunbookIfPresent(repoDirpath string, kudetConfig *kudet_config.KudetConfig) error {
+	runbookFilepath := filepath.Join(repoDirpath, RunbookFilename)
+	if _, err := os.Stat(runbookFilepath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return stacktrace.Propagate(err, "An error occurred checking for a runbook at '%s'", runbookFilepath)
+	}
+	runbook, err := RenderRunbook(repoDirpath, kudetConfig)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred rendering the release runbook")
+	}
+	if err := os.WriteFile(runbookFilepath, []byte(runbook), runbookFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the release runbook to '%s'", runbookFilepath)
+	}
+	return nil
+}
+
+func getReleaseSteps(kudetConfig *kudet_config.KudetConfig, preReleaseScripts []*preReleaseScript) []releaseStep {
+	releaseBranchName := kudetConfig.ReleaseBranch
+	remoteReleaseBranchName := fmt.Sprintf("%s/%s", originRemoteName, releaseBranchName)
+	preReleaseScriptsShellStr := strings.Join(kudetConfig.PreReleaseScriptsShell, " ")
+
+	preReleaseScriptLines := []string{
+		fmt.Sprintf("Each script listed in `%s` is executed from the repo root with the new version as its only argument, and each inline command with `%s`; both get the new version in `%s`.", kudetConfig.PreReleaseScriptsFilepath, preReleaseScriptsShellStr, releaseVersionEnvVar),
+	}
+	if kudetConfig.PreReleaseScriptsParallelism > 1 {
+		preReleaseScriptLines = append(preReleaseScriptLines, fmt.Sprintf("Up to %d scripts run at a time, each once the scripts it depends on have finished; inline commands don't wait for any.", kudetConfig.PreReleaseScriptsParallelism))
+	}
+	if len(kudetConfig.PreReleaseScriptsWritablePaths) > 0 {
+		preReleaseScriptLines = append(preReleaseScriptLines, fmt.Sprintf("After each script, or group of scripts run at the same time, the files written to must all be in `%s`, or the release stops.", strings.Join(kudetConfig.PreReleaseScriptsWritablePaths, "`, `")))
+	}
+	if len(preReleaseScripts) == 0 {
+		preReleaseScriptLines = append(preReleaseScriptLines, "No scripts are currently configured.")
+	}
+	for _, script := range preReleaseScripts {
+		if script.isInline() {
+			preReleaseScriptLines = append(preReleaseScriptLines, fmt.Sprintf("Inline command:\n\n  ```\n  %s\n  ```", strings.Join(script.inlineCommandLines, "\n  ")))
+			continue
+		}
+		for _, secret := range kudetConfig.PreReleaseScriptSecrets[script.relFilepath] {
+			preReleaseScriptLines = append(preReleaseScriptLines, fmt.Sprintf("`%s` gets secret `%s` from %s, resolved before any script runs.", script.relFilepath, secret.Name, secret.Source))
+		}
+		scriptSchedule := kudetConfig.PreReleaseScriptSchedule[script.relFilepath]
+		if len(scriptSchedule.DependsOn) > 0 {
+			preReleaseScriptLines = append(preReleaseScriptLines, fmt.Sprintf("`%s` depends on `%s`.", script.relFilepath, strings.Join(scriptSchedule.DependsOn, "`, `")))
+		}
+		if len(scriptSchedule.OnlyIfChanged) > 0 {
+			preReleaseScriptLines = append(preReleaseScriptLines, fmt.Sprintf("`%s` only runs if `%s` changed since the previous release.", script.relFilepath, strings.Join(scriptSchedule.OnlyIfChanged, "`, `")))
+		}
+		if artifactGlobs := kudetConfig.PreReleaseScriptArtifacts[script.relFilepath]; len(artifactGlobs) > 0 {
+			preReleaseScriptLines = append(preReleaseScriptLines, fmt.Sprintf("`%s` produces artifacts `%s`, collected once it succeeds if there's a `--metadata-dir`.", script.relFilepath, strings.Join(artifactGlobs, "`, `")))
+		}
+		if script.pinnedChecksum != "" {
+			preReleaseScriptLines = append(preReleaseScriptLines, fmt.Sprintf("`%s <new version>`, whose SHA-256 checksum must be `%s` or no script is run", script.relFilepath, script.pinnedChecksum))
+			continue
+		}
+		preReleaseScriptLines = append(preReleaseScriptLines, fmt.Sprintf("`%s <new version>`", script.relFilepath))
+	}
+
+	return []releaseStep{
+		{
+			title: "Resume an interrupted release",
+			description: append(
+				getReleaseStateLines(kudetConfig),
+				"If the recorded release is under embargo, the release is refused until `kudet lift-embargo <token> <version>` pushes it.",
+			),
+		},
+		{
+			title:  "Pre-release checks",
+			isGate: true,
+			description: []string{
+				"The global git config must have `user.name` and `user.email` set.",
+				fmt.Sprintf("The `%s` remote must exist.", originRemoteName),
+				getTokenLine(kudetConfig),
+				getCleanWorktreeLine(kudetConfig),
+			},
+		},
+		{
+			title: "Fetch",
+			description: []string{
+				"A shallow clone is first deepened to its full history, with all tags, since the latest release can't be determined without them.",
+				fmt.Sprintf("`%s` is fetched unless it was fetched within the last %v from its current URL, or always with `--force-fetch`; with `--no-fetch`, it isn't fetched and its remote-tracking branches are trusted as they are.", originRemoteName, kudetConfig.FetchGracePeriod),
+			},
+		},
+		{
+			title:  "Branch checks",
+			isGate: true,
+			description: []string{
+				"A checked out merge queue or pull request branch (e.g. `gh-readonly-queue/*`) is refused, as is a detached HEAD that CI checked out from one, since it isn't merged yet.",
+				fmt.Sprintf("If HEAD is detached on `%s` and there's no local `%s` (as in CI checkouts), the local branch is created there.", remoteReleaseBranchName, releaseBranchName),
+				fmt.Sprintf("Local `%s` must be on the same commit as `%s`.", releaseBranchName, remoteReleaseBranchName),
+				fmt.Sprintf("`%s` is checked out, unless it already is.", releaseBranchName),
+			},
+		},
+		{
+			title:  "Changelog validation",
+			isGate: true,
+			description: append([]string{
+				fmt.Sprintf("The first non-empty line of `%s` must be the `%s` header, and it must be the only one.", kudetConfig.ChangelogFilepath, versionToBeReleasedPlaceholderHeaderStr),
+				"There must be at least one entry under it before the previous version's header.",
+				fmt.Sprintf("If `%s` exists, the notes under the TBD header must match it; differences are only allowed with `--acknowledge-notes-diff`.", kudetConfig.ApprovedReleaseNotesFilepath),
+				getAdditionalChangelogsValidationLine(kudetConfig),
+			}, getRequiredChangelogSectionLines(kudetConfig)...),
+		},
+		{
+			title: "Next version",
+			description: []string{
+				getPreviousVersionLine(kudetConfig),
+				getMinReleaseIntervalLine(kudetConfig),
+				getTagSignaturesLine(kudetConfig),
+				getMajorBumpLine(kudetConfig),
+				"Otherwise, a breaking changes subheader under the TBD header bumps the minor version, and anything else bumps the patch version.",
+				getChangelogCategoriesLine(kudetConfig),
+				getBumpOverrideLine(kudetConfig),
+				getVersionSourceLine(kudetConfig),
+				getNonReleasableChangesLine(kudetConfig),
+				"The release is refused if a tag for the next version already exists.",
+				getVersionTracksLine(kudetConfig),
+			},
+		},
+		{
+			title:       "CI check",
+			description: getCiCheckLines(kudetConfig),
+			isGate:      true,
+		},
+		{
+			title:       releasePolicyStepTitle,
+			description: getPolicyLines(kudetConfig),
+			isGate:      true,
+		},
+		{
+			title:  "Confirmation",
+			isGate: true,
+			description: []string{
+				fmt.Sprintf("If `%s` exists, the downstream consumers it lists are shown first, along with whether the release is breaking (a major or minor bump).", kudetConfig.Downstream.ManifestFilepath),
+				"The operator is asked to confirm the version to release with `y`; the default answer is no, which aborts the release.",
+				"With `--confirm-timeout`, the release is aborted if no answer arrives in time.",
+				"With `--preview-diff`, the confirmation is asked after the changelog finalization instead, once the diff of every changed file that the release commit will include has been shown; declining resets the changes to tracked files.",
+			},
+		},
+		{
+			title:       "Approval",
+			description: getApprovalLines(kudetConfig),
+			isGate:      true,
+		},
+		{
+			title:       preReleaseScriptsStepTitle,
+			description: preReleaseScriptLines,
+		},
+		{
+			title:       "Version files",
+			description: getVersionFileLines(kudetConfig),
+		},
+		{
+			title:       "Packages",
+			description: getPackageLines(kudetConfig),
+		},
+		{
+			title:       "Bazel build",
+			description: getBazelLines(kudetConfig),
+		},
+		{
+			title:       "Artifact diff",
+			description: getArtifactDiffLines(kudetConfig),
+		},
+		{
+			title: "Changelog finalization",
+			description: []string{
+				fmt.Sprintf("A header for the new version is inserted beneath the `%s` header of `%s`.", versionToBeReleasedPlaceholderHeaderStr, kudetConfig.ChangelogFilepath),
+				getAdditionalChangelogsFinalizationLine(kudetConfig),
+				getTbdSkeletonLine(kudetConfig),
+				fmt.Sprintf("If `%s` exists, it is regenerated.", RunbookFilename),
+			},
+		},
+		{
+			title: releaseCommitStepTitle,
+			description: []string{
+				"The repo's `pre-commit` and `commit-msg` git hooks, from `core.hooksPath` or `.git/hooks`, are run on the staged changes and the message as `git commit` would, unless `--no-verify` is passed.",
+				getReleaseCommitLine(kudetConfig),
+				getTagMessageLine(kudetConfig),
+				fmt.Sprintf("With `--embargo`, for security releases, the release stops here instead: the release commit is held under `%s<version>` rather than on `%s` or under tags, a draft forge release is created (on GitLab, which has no drafts, the release is created when the embargo lifts), and nothing is pushed until `kudet lift-embargo <token> <version>` is run from the same clone.", embargoRefPrefix, releaseBranchName),
+				"With `--bridge-script <path>`, for repos mirrored from SVN, the release stops here instead: the changes are left in the worktree and the commit and tag commands are written to the script for the bridge to apply, so nothing is pushed.",
+			},
+		},
+		{
+			title: "Push",
+			description: []string{
+				fmt.Sprintf("`%s` is re-checked on the remote itself; if anyone pushed to it since the release started, the release stops before pushing anything.", remoteReleaseBranchName),
+				fmt.Sprintf("`vX.Y.Z` is pushed to `%s`.", originRemoteName),
+				fmt.Sprintf("The release commit is pushed to `%s`, only if it's still on the commit the release started from.", remoteReleaseBranchName),
+				fmt.Sprintf("`X.Y.Z` is pushed to `%s`; this triggers CI and is the point of no return.", originRemoteName),
+				getDryRunStepsLine(kudetConfig),
+			},
+		},
+		{
+			title:       "Undo window",
+			description: getUndoWindowLines(kudetConfig),
+		},
+		{
+			title:       "Mirrors",
+			description: getMirrorLines(kudetConfig),
+		},
+		{
+			title: "Security advisory",
+			description: []string{
+				"Only with `--security`: a draft security advisory is created on the forge for the fixed version, with the release notes as its description, to be published once the release is public.",
+				"The severity comes from `--severity`, falling back to the kudet config; a security release without a severity, or on a forge without advisories (GitLab), is refused up front.",
+				"With `--embargo`, the advisory is drafted when the release is prepared rather than when it's pushed.",
+			},
+		},
+		{
+			title: "Audit note",
+			description: []string{
+				fmt.Sprintf("A git note is attached to the release commit under `%s`, recording the version, previous version, who released it and when, the kudet version and why the version was bumped the way it was.", auditNotesRefName),
+				fmt.Sprintf("`%s` is pushed to `%s` without forcing, so the notes of past releases can't be rewritten; failures are logged for the operator to fix by hand.", auditNotesRefName, originRemoteName),
+			},
+		},
+		{
+			title:       "Audit log",
+			description: getAuditLogLines(kudetConfig),
+		},
+		{
+			title:       "Environment promotion",
+			description: getEnvironmentPromotionLines(kudetConfig),
+		},
+		{
+			title:       "Rollout health check",
+			description: getHealthCheckLines(kudetConfig),
+		},
+		{
+			title: "Release metadata",
+			description: []string{
+				"Only with `--metadata-dir`: a `release-metadata.json` with the version, previous version, release commit, tags, timestamp, changelog excerpt, kudet version and rollout status is written to that directory.",
+				"Only with `--upload-metadata`: the same file is uploaded as an asset of the GitHub release, which is created if it doesn't exist yet; other forges are refused up front.",
+				fmt.Sprintf("Only with `--upload-artifacts`: the pre-release scripts' artifacts, collected into the `%s` directory under `--metadata-dir`, are uploaded as assets of the GitHub release the same way.", preReleaseScriptArtifactsDirname),
+				"Failures here are logged for the operator to fix by hand; they don't fail the release.",
+			},
+		},
+		{
+			title:       "Pull request comments",
+			description: getPullRequestCommentLines(kudetConfig),
+		},
+		{
+			title:       "Milestones",
+			description: getMilestoneLines(kudetConfig),
+		},
+		{
+			title:       notificationsStepTitle,
+			description: append(getNotificationLines(kudetConfig), getDownstreamNotificationLine(kudetConfig)),
+		},
+		{
+			title:       "Telemetry",
+			description: getTelemetryLines(kudetConfig),
+		},
+	}
+}
+
+func getPreviousVersionLine(kudetConfig *kudet_config.KudetConfig) string {
+	tagFormats := []string{"`X.Y.Z`"}
+	if kudetConfig.TagParsing.AllowVPrefix {
+		tagFormats = append(tagFormats, "`vX.Y.Z`")
+	}
+	if kudetConfig.TagParsing.AllowPrereleasesAndMetadata {
+		tagFormats = append(tagFormats, "prerelease or build metadata (e.g. `X.Y.Z-rc.1`)")
+	}
+	return fmt.Sprintf("The latest semver tag, counting %s tags, is taken as the previous version (`0.0.0` if there isn't one).", strings.Join(tagFormats, " and "))
+}
+
+func getTagSignaturesLine(kudetConfig *kudet_config.KudetConfig) string {
+	if kudetConfig.TagSignatures.KeyringFilepath == "" {
+		return "The previous version's tags aren't checked for signatures."
+	}
+	return fmt.Sprintf("The previous version's tags must be signed by one of the trusted keys in `%s`, or the release is refused.", kudetConfig.TagSignatures.KeyringFilepath)
+}
+
+func getReleaseStateLines(kudetConfig *kudet_config.KudetConfig) []string {
+	stateStore := newReleaseStateStore("", kudetConfig.ReleaseState)
+	if !stateStore.isShared() {
+		return []string{fmt.Sprintf("If a `%s` in the repo's version control directory (e.g. `.git`) records a release that was committed but not fully pushed, only its remaining pushes are performed and the release ends there.", releaseStateFilename)}
+	}
+	return []string{
+		fmt.Sprintf("The release lock `%s` is taken for the rest of the release, and the release is refused if another run already holds it.", stateStore.getLocation(releaseLockFilename)),
+		fmt.Sprintf("If `%s` records a release that was committed but not fully pushed, possibly on another machine, only its remaining pushes are performed and the release ends there.", stateStore.getLocation(releaseStateFilename)),
+	}
+}
+
+func getAdditionalChangelogsValidationLine(kudetConfig *kudet_config.KudetConfig) string {
+	if len(kudetConfig.AdditionalChangelogFilepaths) == 0 {
+		return "No other changelogs are kept alongside it."
+	}
+	return fmt.Sprintf("`%s` must pass the same checks, and a breaking or major changes subheader in any of them counts towards the next version; the release notes only come from `%s`.", strings.Join(kudetConfig.AdditionalChangelogFilepaths, "`, `"), kudetConfig.ChangelogFilepath)
+}
+
+func getRequiredChangelogSectionLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if len(kudetConfig.RequiredChangelogSections) == 0 {
+		return []string{"No changelog sections are required for changes to particular files."}
+	}
+	lines := []string{}
+	for _, sectionConfig := range kudetConfig.RequiredChangelogSections {
+		line := fmt.Sprintf("If `%s` changed since the previous release, there must be entries under a `%s` subheader under the TBD header.", strings.Join(sectionConfig.Paths, "`, `"), sectionConfig.Subheader)
+		if len(sectionConfig.AckedBy) > 0 {
+			line += fmt.Sprintf(" A commit since the previous release must have an `Acked-by:` trailer naming `%s`.", strings.Join(sectionConfig.AckedBy, "`, `"))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func getChangelogCategoriesLine(kudetConfig *kudet_config.KudetConfig) string {
+	if len(kudetConfig.ChangelogCategories) == 0 {
+		return "No other changelog categories bump the version."
+	}
+	categoryDescriptions := []string{}
+	for _, categoryConfig := range kudetConfig.ChangelogCategories {
+		categoryDescription := fmt.Sprintf("`%s` bumps at least the %s version", categoryConfig.Subheader, categoryConfig.Bump)
+		if categoryConfig.ForceRelease {
+			categoryDescription += " and forces a release"
+		}
+		categoryDescriptions = append(categoryDescriptions, categoryDescription)
+	}
+	return fmt.Sprintf("Entries under these subheaders bump the version too, the highest bump winning: %s.", strings.Join(categoryDescriptions, "; "))
+}
+
+func getBumpOverrideLine(kudetConfig *kudet_config.KudetConfig) string {
+	if len(kudetConfig.BumpOverride.Approvers) == 0 {
+		return "The computed bump can't be overridden by commit trailers."
+	}
+	return fmt.Sprintf("Unless `--bump-major` is passed, the latest `kudet:bump=major|minor|patch` trailer on a commit since the previous release authored by `%s` overrides the computed bump, and its justification is recorded as the bump's reason.", strings.Join(kudetConfig.BumpOverride.Approvers, "`, `"))
+}
+
+func getMinReleaseIntervalLine(kudetConfig *kudet_config.KudetConfig) string {
+	if kudetConfig.MinReleaseInterval <= 0 {
+		return "A release can follow the previous one at any time."
+	}
+	return fmt.Sprintf("The release is refused if the previous release's commit is less than %v old, unless `--force` is passed.", kudetConfig.MinReleaseInterval)
+}
+
+func getTagMessageLine(kudetConfig *kudet_config.KudetConfig) string {
+	if kudetConfig.TagMessageTemplate == "" {
+		return "Annotated tags `X.Y.Z` and `vX.Y.Z` are created on the release commit, with their names as their messages."
+	}
+	return fmt.Sprintf("Annotated tags `X.Y.Z` and `vX.Y.Z` are created on the release commit, with messages rendered from the template `%s`.", kudetConfig.TagMessageTemplate)
+}
+
+func getUndoWindowLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if kudetConfig.UndoWindow <= 0 {
+		return []string{"The post-release steps follow the push straight away, and the release can't be cancelled."}
+	}
+	return []string{
+		fmt.Sprintf("The post-release steps are held back for %v after the push, during which `kudet cancel <token> <version>` deletes the release's tags from `%s`, reverts its release commit and deletes its forge release, once approved.", kudetConfig.UndoWindow, originRemoteName),
+		"A release that's cancelled while waiting fails with the `release-cancelled` error and skips the post-release steps.",
+	}
+}
+
+func getApprovalLines(kudetConfig *kudet_config.KudetConfig) []string {
+	approvalConfig := kudetConfig.Approval
+	if len(approvalConfig.Approvers) == 0 {
+		return []string{"No approval is needed beyond the confirmation."}
+	}
+	return []string{
+		fmt.Sprintf("An issue asking for approval of the release, with its release notes, is opened on the forge, and the release waits until one of `%s` comments `%s` on it, for up to %v.", strings.Join(approvalConfig.Approvers, "`, `"), approveCommand, approvalConfig.Timeout),
+		fmt.Sprintf("If one of them comments `%s` instead, or nobody decides in time, the release fails with the `%s` error before anything is changed; other people's comments are ignored.", denyCommand, ErrReleaseNotApproved.Name),
+		"Releases whose push is a dry run, and sandboxed releases, don't wait for approval. GitLab doesn't support approval issues, so releases needing approval are refused up front there.",
+	}
+}
+
+func getVersionTracksLine(kudetConfig *kudet_config.KudetConfig) string {
+	if len(kudetConfig.VersionTracks) == 0 {
+		return "The repo has a single version line."
+	}
+	trackDescriptions := []string{}
+	for _, trackConfig := range kudetConfig.VersionTracks {
+		// Validated when the config was loaded
+		tagPrefix, tagSuffix, _ := trackConfig.GetTagPrefixAndSuffix()
+		trackDescriptions = append(trackDescriptions, fmt.Sprintf("`%s`, tagged `%sX.Y.Z%s` and bumped from `%s`", trackConfig.Name, tagPrefix, tagSuffix, trackConfig.ChangelogFilepath))
+	}
+	return fmt.Sprintf("With `--track`, these version tracks are released along with, or instead of, the `%s` version line, each bumped from its own changelog: %s.", kudet_config.MainVersionTrackName, strings.Join(trackDescriptions, "; "))
+}
+
+func getNonReleasableChangesLine(kudetConfig *kudet_config.KudetConfig) string {
+	nonReleasableConfig := kudetConfig.NonReleasableChanges
+	if len(nonReleasableConfig.Paths) == 0 {
+		return "Any change since the previous release warrants releasing."
+	}
+	if nonReleasableConfig.OnOnlyNonReleasable == kudet_config.SkipNonReleasableChangesPolicy {
+		return fmt.Sprintf("If the only files changed since the previous release, changelogs aside, match `%s`, the release is skipped unless a changelog category forces it.", strings.Join(nonReleasableConfig.Paths, "`, `"))
+	}
+	return fmt.Sprintf("If the only files changed since the previous release, changelogs aside, match `%s`, a warning is logged and the release goes ahead.", strings.Join(nonReleasableConfig.Paths, "`, `"))
+}
+
+func getAdditionalChangelogsFinalizationLine(kudetConfig *kudet_config.KudetConfig) string {
+	if len(kudetConfig.AdditionalChangelogFilepaths) == 0 {
+		return "No other changelogs are finalized."
+	}
+	return fmt.Sprintf("The same header is inserted in `%s`.", strings.Join(kudetConfig.AdditionalChangelogFilepaths, "`, `"))
+}
+
+func getMirrorLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if len(kudetConfig.Mirrors) == 0 {
+		return []string{"No mirrors are configured."}
+	}
+	lines := []string{"Once origin has the release, `vX.Y.Z`, the release commit and `X.Y.Z` are pushed to each mirror; failures are logged for the operator to push by hand, and only fail the release, once everything else is done, for mirrors set to `fail`."}
+	for _, mirror := range kudetConfig.Mirrors {
+		token := "the release token"
+		if mirror.TokenEnvVar != "" {
+			token = fmt.Sprintf("the token in `%s`", mirror.TokenEnvVar)
+		}
+		onFailure := kudet_config.WarnMirrorFailurePolicy
+		if mirror.OnFailure != "" {
+			onFailure = mirror.OnFailure
+		}
+		lines = append(lines, fmt.Sprintf("`%s` with %s, on failure `%s`", getMirrorDisplayUrl(mirror.Url), token, onFailure))
+	}
+	return lines
+}
+
+func getDryRunStepsLine(kudetConfig *kudet_config.KudetConfig) string {
+	if isDryRunStep(kudetConfig.DryRunSteps, kudet_config.PushDryRunStep) {
+		return "The kudet config dry-runs the push: the release commit and tags are left in the local repo, and nothing after this step runs."
+	}
+	if len(kudetConfig.DryRunSteps) == 0 {
+		return "No steps are dry-run."
+	}
+	return fmt.Sprintf("The kudet config dry-runs `%s`, which only log what they would do after the push.", strings.Join(kudetConfig.DryRunSteps, "`, `"))
+}
+
+func getTbdSkeletonLine(kudetConfig *kudet_config.KudetConfig) string {
+	if kudetConfig.ChangelogTbdSkeletonFilepath == "" {
+		return fmt.Sprintf("The `%s` header is left bare for the next release's entries.", versionToBeReleasedPlaceholderHeaderStr)
+	}
+	return fmt.Sprintf("The skeleton in `%s` is written under the `%s` header for the next release's entries; its placeholders and empty subheaders are pruned from the released entries first.", kudetConfig.ChangelogTbdSkeletonFilepath, versionToBeReleasedPlaceholderHeaderStr)
+}
+
+func getCleanWorktreeLine(kudetConfig *kudet_config.KudetConfig) string {
+	if len(kudetConfig.AllowedDirtyPaths) == 0 {
+		return "The worktree must have no staged or unstaged changes."
+	}
+	return fmt.Sprintf("The worktree must have no staged or unstaged changes outside `%s`; changes there are committed with the release.", strings.Join(kudetConfig.AllowedDirtyPaths, "`, `"))
+}
+
+func getTokenLine(kudetConfig *kudet_config.KudetConfig) string {
+	if kudetConfig.GithubApp.AppId == 0 {
+		return fmt.Sprintf("The `%s` remote must accept pushes with the token, which is checked without pushing anything, unless the release is bridged.", originRemoteName)
+	}
+	return fmt.Sprintf("Instead of the given token, an installation token of GitHub App `%d` is minted with the private key in `$%s`, and refreshed before the post-release steps; `%s` must accept pushes with it, which is checked without pushing anything, unless the release is bridged.", kudetConfig.GithubApp.AppId, kudetConfig.GithubApp.PrivateKeyEnvVar, originRemoteName)
+}
+
+func getMajorBumpLine(kudetConfig *kudet_config.KudetConfig) string {
+	if kudetConfig.MajorChangesSubheaderRegex == "" {
+		return "If `--bump-major` is passed, the major version is bumped."
+	}
+	return fmt.Sprintf("If `--bump-major` is passed or a subheader under the TBD header matches `%s`, the major version is bumped.", kudetConfig.MajorChangesSubheaderRegex)
+}
+
+func getVersionSourceLine(kudetConfig *kudet_config.KudetConfig) string {
+	if kudetConfig.VersionSource.Url == "" {
+		return "The bumped version is released as is."
+	}
+	return fmt.Sprintf("The kind of bump is sent to the version source at `%s`, and the version it allocates is released instead, as long as it's later than the previous version; the interactive release can't change it.", kudetConfig.VersionSource.Url)
+}
+
+func getReleaseCommitLine(kudetConfig *kudet_config.KudetConfig) string {
+	commitLine := fmt.Sprintf("All changes that aren't gitignored are committed with a message rendered from the template `%s`", kudetConfig.ReleaseCommitMessageTemplate)
+	if strings.TrimSpace(kudetConfig.SkipCiMarker) == "" {
+		return commitLine + "."
+	}
+	return fmt.Sprintf("%s, with `%s` appended as the last paragraph of the message so that CI skips the commit.", commitLine, strings.TrimSpace(kudetConfig.SkipCiMarker))
+}
+
+func getVersionFileLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if len(kudetConfig.VersionFiles) == 0 {
+		return []string{"No version files are configured."}
+	}
+	lines := []string{
+		"The version is bumped on the one line matching each pattern, with `%s` standing for the version; files outside the repo are only bumped once the release has been pushed, and are left for the operator to commit.",
+		"Before the release, the files inside the repo must have the previous version; after the pre-release scripts, they must all have the next version, or the release stops.",
+	}
+	for _, versionFile := range kudetConfig.VersionFiles {
+		bumper := ""
+		if versionFile.BumpedByScripts {
+			bumper = ", bumped by the pre-release scripts"
+		}
+		lines = append(lines, fmt.Sprintf("`%s` on the line matching `%s`%s", versionFile.Filepath, versionFile.Pattern, bumper))
+	}
+	return lines
+}
+
+func getPackageLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if len(kudetConfig.Packages) == 0 {
+		return []string{"No packages are configured."}
+	}
+	lines := []string{
+		"The packages are released at the repo's version in the order of their dependencies on each other, read from their manifests; packages that depend on each other in a cycle stop the release.",
+		"Each package's manifest gets the version, and its constraints on the repo's other packages that pin a version are rewritten to it, keeping their `^`, `~`, `>=` or `=` operator; the manifests are committed with the release.",
+	}
+	for _, packageConfig := range kudetConfig.Packages {
+		releaseCommand := ""
+		if packageConfig.ReleaseCommand != "" {
+			releaseCommand = fmt.Sprintf(", released with `%s` run from its directory with `%s`", packageConfig.ReleaseCommand, strings.Join(kudetConfig.PreReleaseScriptsShell, " "))
+		}
+		lines = append(lines, fmt.Sprintf("`%s`%s", packageConfig.ManifestFilepath, releaseCommand))
+	}
+	return lines
+}
+
+func getArtifactDiffLines(kudetConfig *kudet_config.KudetConfig) []string {
+	artifactDiffConfig := kudetConfig.ArtifactDiff
+	if !artifactDiffConfig.Enabled {
+		return []string{"The release's artifacts aren't compared with the previous release's."}
+	}
+	anomalyLine := "Anomalies are logged as warnings."
+	if artifactDiffConfig.FailOnAnomaly {
+		anomalyLine = "Anomalies fail the release before anything is committed, unless it's run with `--force`."
+	}
+	return []string{
+		"The artifacts collected for the release are compared with the assets of the previous release's forge release: which artifacts were added or removed, their sizes, the files inside archives, and whether they have their version in them. The diff goes in the release metadata.",
+		fmt.Sprintf("A missing artifact, an artifact that got %vx bigger or smaller, an archive that lost files, or an artifact that no longer has its version in it is an anomaly. %s", artifactDiffConfig.MaxSizeRatio, anomalyLine),
+	}
+}
+
+func getBazelLines(kudetConfig *kudet_config.KudetConfig) []string {
+	bazelConfig := kudetConfig.Bazel
+	if bazelConfig.StampFilepath == "" && bazelConfig.Target == "" {
+		return []string{"No Bazel build is configured."}
+	}
+	lines := []string{}
+	if bazelConfig.StampFilepath != "" {
+		lines = append(lines, fmt.Sprintf("`%s` is rewritten with the `%s` and `%s` workspace status lines for stamped targets.", bazelConfig.StampFilepath, bazelReleaseVersionStatusKey, bazelPreviousReleaseVersionStatusKey))
+	}
+	if bazelConfig.Target != "" {
+		lines = append(lines, fmt.Sprintf("`%s %s` is run from the repo root with the new version in `%s`; if it fails, the release stops.", strings.Join(bazelConfig.Command, " "), bazelConfig.Target, releaseVersionEnvVar))
+	}
+	if bazelConfig.Target != "" && len(bazelConfig.Artifacts) > 0 {
+		lines = append(lines, fmt.Sprintf("The files matching `%s` are collected along with the pre-release scripts' artifacts.", strings.Join(bazelConfig.Artifacts, "`, `")))
+	}
+	return lines
+}
+
+func getAuditLogLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if kudetConfig.AuditLog.Url == "" {
+		return []string{"No audit log is configured."}
+	}
+	return []string{
+		fmt.Sprintf("The release's audit record is POSTed as JSON to `%s`; failures are logged along with how to replay the record with `kudet audit export` and `kudet audit import`.", kudetConfig.AuditLog.Url),
+	}
+}
+
+func getEnvironmentPromotionLines(kudetConfig *kudet_config.KudetConfig) []string {
+	environmentsConfig := kudetConfig.Environments
+	if len(environmentsConfig.Manifests) == 0 {
+		return []string{"No environments are configured."}
+	}
+	lines := []string{
+		fmt.Sprintf("In the GitOps repo at `%s`, whose local `%s` branch must be clean and in sync with `%s/%s`, the version is pinned in the manifest of each environment promoted to, then committed and pushed to `%s`.", environmentsConfig.GitopsRepoDirpath, environmentsConfig.Branch, originRemoteName, environmentsConfig.Branch, environmentsConfig.Branch),
+		"The promoted environments are listed in the release notifications; failures are logged for the operator to promote by hand, and don't fail the release.",
+	}
+	for _, manifest := range environmentsConfig.Manifests {
+		promotion := fmt.Sprintf("only with `--promote %s`", manifest.Name)
+		if manifest.AutoPromote {
+			promotion = "on every release"
+		}
+		lines = append(lines, fmt.Sprintf("`%s`: `%s` on the line matching `%s`, %s", manifest.Name, manifest.Filepath, manifest.Pattern, promotion))
+	}
+	return lines
+}
+
+func getHealthCheckLines(kudetConfig *kudet_config.KudetConfig) []string {
+	healthCheckConfig := kudetConfig.HealthCheck
+	if healthCheckConfig.Url == "" {
+		return []string{"No health check is configured, so rollouts aren't verified."}
+	}
+	parsedUrl, err := url.Parse(healthCheckConfig.Url)
+	if err != nil {
+		return []string{"The configured health check URL is invalid."}
+	}
+	// Like webhooks, health check URLs can embed secrets, so only the host is rendered
+	return []string{
+		fmt.Sprintf("The health check on `%s` is polled every %v for %v; the rollout is marked `%s` if every response is a 2xx, and `%s` as soon as one isn't.", parsedUrl.Host, healthCheckConfig.Interval, healthCheckConfig.Duration, verifiedRolloutStatus, unhealthyRolloutStatus),
+		"An unhealthy rollout is raised for the operator to roll back with `kudet rollback-env`, and the rollout status is recorded in the release metadata and notifications; it doesn't fail the release.",
+		fmt.Sprintf("Only with `--rollback-on-unhealthy`: the environments an unhealthy release was promoted to are pinned back to the previous version, its forge release is marked superseded, the webhooks are notified, and the rollout is marked `%s`.", rolledBackRolloutStatus),
+	}
+}
+
+func getCiCheckLines(kudetConfig *kudet_config.KudetConfig) []string {
+	lines := []string{fmt.Sprintf("Only with `--require-green-ci`: the release is refused unless CI has passed on the `%s/%s` commit being released.", originRemoteName, kudetConfig.ReleaseBranch)}
+	if len(kudetConfig.Ci.RequiredChecks) == 0 {
+		return append(lines, "Every check reported on the commit must have passed.")
+	}
+	for _, requiredCheck := range kudetConfig.Ci.RequiredChecks {
+		lines = append(lines, fmt.Sprintf("Required check `%s`", requiredCheck))
+	}
+	return lines
+}
+
+func getPolicyLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if len(kudetConfig.Policy.Paths) == 0 && len(kudetConfig.Policy.WasmGates) == 0 {
+		return []string{"No release policy is configured."}
+	}
+	lines := []string{}
+	if len(kudetConfig.Policy.Paths) > 0 {
+		lines = append(
+			lines,
+			fmt.Sprintf("The release plan (version, previous version, bump type, release branch, files changed since the previous release, the releaser's git identity, and the time, weekday and hour in UTC) is evaluated with `opa eval` against the Rego policy in `%s`.", strings.Join(kudetConfig.Policy.Paths, "`, `")),
+			fmt.Sprintf("The release is refused with the reasons given by `%s`, if there are any.", kudetConfig.Policy.Query),
+		)
+	}
+	if len(kudetConfig.Policy.WasmGates) > 0 {
+		lines = append(
+			lines,
+			fmt.Sprintf("The WASM gates `%s` are run sandboxed with `wasmtime`, given the release plan, the remote URL, the commit being released and the release notes on stdin.", strings.Join(kudetConfig.Policy.WasmGates, "`, `")),
+			"The release is refused with the reasons the gates deny it for, if there are any.",
+		)
+	}
+	return lines
+}
+
+func getDownstreamNotificationLine(kudetConfig *kudet_config.KudetConfig) string {
+	if !kudetConfig.Downstream.NotifyOwners {
+		return "The downstream consumers reached by the release are summarized, but their owners aren't notified."
+	}
+	return "The downstream consumers reached by the release are summarized, and each one with a webhook is POSTed the release's JSON description along with its owners and whether the release is breaking."
+}
+
+func getTelemetryLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if kudetConfig.Telemetry.OtlpEndpoint == "" {
+		return []string{"No OpenTelemetry collector is configured."}
+	}
+	return []string{
+		fmt.Sprintf("Whether it succeeds or fails, the release's trace, with a span for each step, and metrics of its outcome and step durations are exported over OTLP/HTTP to `%s`; failures are only logged.", kudetConfig.Telemetry.OtlpEndpoint),
+	}
+}
+
+func getPullRequestCommentLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if !kudetConfig.PullRequestComments.Enabled {
+		return []string{"Pull requests aren't commented on."}
+	}
+	return []string{
+		fmt.Sprintf("Each pull request merged since the previous release, found from the commits since its tag, is commented on with `%s`; failures are logged but don't fail the release.", fmt.Sprintf(releasedInCommentFormat, "<new version>")),
+	}
+}
+
+func getMilestoneLines(kudetConfig *kudet_config.KudetConfig) []string {
+	if !kudetConfig.Milestones.Enabled {
+		return []string{"Milestones aren't rolled forward."}
+	}
+	return []string{
+		fmt.Sprintf("The open milestone titled with the new version, with or without the `v` prefix, is closed once its open issues and pull requests are moved to the milestone of the next %s version, which is created if it doesn't exist; failures are logged but don't fail the release.", kudetConfig.Milestones.NextBump),
+	}
+}
+
+func getNotificationLines(kudetConfig *kudet_config.KudetConfig) []string {
+	webhookUrls := kudetConfig.Notifications.WebhookUrls
+	if len(webhookUrls) == 0 {
+		return []string{"No notifications are configured."}
+	}
+	// Webhook URLs frequently embed secrets, so only their hosts are rendered
+	lines := []string{"A JSON description of the release, mentioning the repo's owners, is POSTed to each configured webhook; failures are logged but don't fail the release."}
+	for _, webhookHost := range getWebhookHosts(webhookUrls) {
+		lines = append(lines, fmt.Sprintf("Webhook on `%s`", webhookHost))
+	}
+	return lines
+}
+
+// getWebhookHosts returns the hosts of the webhooks that are valid URLs, which are all that get shown of them
+func getWebhookHosts(webhookUrls []string) []string {
+	webhookHosts := []string{}
+	for _, webhookUrl := range webhookUrls {
+		parsedUrl, err := url.Parse(webhookUrl)
+		if err != nil {
+			continue
+		}
+		webhookHosts = append(webhookHosts, parsedUrl.Host)
+	}
+	return webhookHosts
+}
@@ -0,0 +1,49 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+)
+
+const (
+	sandboxDirPattern = "kudet-sandbox-"
+)
+
+// releaseInSandbox rehearses the release in a clone of the repo whose origin is a throwaway copy of the real one, so
+// that everything up to and including the push runs for real without anything leaving the machine
+func (releaser *Releaser) releaseInSandbox(ctx context.Context) error {
+	// These talk to the forge, which the sandbox's stand-in origin isn't on
+	if releaser.shouldRequireGreenCi || releaser.isSecurityRelease || releaser.shouldUploadMetadata {
+		return stacktrace.NewError("Releases that check CI, draft security advisories, or upload their metadata talk to the forge, so they can't be rehearsed in a sandbox")
+	}
+	kudetConfig, err := releaser.getKudetConfig()
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the kudet config for the repo")
+	}
+
+	sandboxDirpath, err := os.MkdirTemp("", sandboxDirPattern)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating a directory for the sandbox")
+	}
+	defer func() {
+		if err := os.RemoveAll(sandboxDirpath); err != nil {
+			logrus.Warnf("An error occurred removing sandbox '%s'; it can be deleted by hand:\n%v", sandboxDirpath, err)
+		}
+	}()
+	logrus.Infof("Cloning the repo into sandbox '%s'...", sandboxDirpath)
+	sandboxRepoDirpath, err := vcs.CloneGitSandbox(ctx, releaser.repoDirpath, sandboxDirpath, kudetConfig.ReleaseBranch)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred cloning the repo into the sandbox; only git repos can be released in a sandbox")
+	}
+
+	sandboxReleaser := *releaser
+	sandboxReleaser.repoDirpath = sandboxRepoDirpath
+	if err := sandboxReleaser.release(ctx); err != nil {
+		return stacktrace.Propagate(err, "The release failed in the sandbox")
+	}
+	logrus.Infof("The release succeeded in the sandbox; nothing was pushed to '%s'", originRemoteName)
+	return nil
+}
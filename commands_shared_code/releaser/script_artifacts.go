@@ -0,0 +1,152 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// The directory under the metadata directory that the pre-release scripts' artifacts are collected into
+	preReleaseScriptArtifactsDirname = "artifacts"
+	artifactsDirMode                 = 0755
+	artifactFileMode                 = 0644
+)
+
+// getArtifactsDirpath returns where the pre-release scripts' artifacts are collected, which is nowhere if the release
+// has no metadata directory
+func (releaser *Releaser) getArtifactsDirpath() string {
+	if releaser.metadataDirpath == "" {
+		return ""
+	}
+	return filepath.Join(releaser.metadataDirpath, preReleaseScriptArtifactsDirname)
+}
+
+// verifyPreReleaseScriptArtifactsConfig checks that the artifacts are declared for scripts that will run, before any of
+// them does
+func verifyPreReleaseScriptArtifactsConfig(kudetConfig *kudet_config.KudetConfig, scripts []*preReleaseScript) error {
+	isListedScript := map[string]bool{}
+	for _, script := range scripts {
+		if !script.isInline() {
+			isListedScript[script.relFilepath] = true
+		}
+	}
+	for scriptRelFilepath := range kudetConfig.PreReleaseScriptArtifacts {
+		if !isListedScript[scriptRelFilepath] {
+			return stacktrace.NewError("Artifacts are configured for pre release script '%s', which isn't in '%s'", scriptRelFilepath, kudetConfig.PreReleaseScriptsFilepath)
+		}
+	}
+	return nil
+}
+
+// collectArtifacts copies the files that match the artifact globs of what produced them, e.g. "pre release script
+// 'scripts/build.sh'", into the artifacts directory, returning the paths of the copies. They're collected side by side
+// under their own names, as they'd be uploaded, so a name that was already collected, given as a map from name to the
+// file it came from, is an error.
+func collectArtifacts(repoDirpath string, producerDescription string, artifactGlobs []string, artifactsDirpath string, collectedArtifactSources map[string]string) ([]string, error) {
+	artifactRelFilepaths, err := getArtifactRelFilepaths(repoDirpath, artifactGlobs)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred finding the artifacts of %s", producerDescription)
+	}
+	if len(artifactRelFilepaths) == 0 {
+		logrus.Warnf("The %s didn't produce any of its artifacts '%v'", producerDescription, artifactGlobs)
+		return nil, nil
+	}
+	if err := os.MkdirAll(artifactsDirpath, artifactsDirMode); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred creating the artifacts directory '%s'", artifactsDirpath)
+	}
+
+	artifactFilepaths := []string{}
+	for _, artifactRelFilepath := range artifactRelFilepaths {
+		artifactName := path.Base(artifactRelFilepath)
+		if otherArtifactRelFilepath, found := collectedArtifactSources[artifactName]; found {
+			return nil, stacktrace.NewError("Artifacts '%s' and '%s' would both be collected as '%s'; give them different names", otherArtifactRelFilepath, artifactRelFilepath, artifactName)
+		}
+		artifactContents, err := os.ReadFile(filepath.Join(repoDirpath, artifactRelFilepath))
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading artifact '%s'", artifactRelFilepath)
+		}
+		artifactFilepath := filepath.Join(artifactsDirpath, artifactName)
+		if err := os.WriteFile(artifactFilepath, artifactContents, artifactFileMode); err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred collecting artifact '%s' into '%s'", artifactRelFilepath, artifactFilepath)
+		}
+		collectedArtifactSources[artifactName] = artifactRelFilepath
+		artifactFilepaths = append(artifactFilepaths, artifactFilepath)
+		logrus.Debugf("Collected artifact '%s' of %s into '%s'", artifactRelFilepath, producerDescription, artifactFilepath)
+	}
+	return artifactFilepaths, nil
+}
+
+// uploadPreReleaseScriptArtifactsIfNeeded attaches the artifacts collected for a successful release to its forge release
+// as requested; the release is irreversible by the time it runs, so failures are only logged
+func (releaser *Releaser) uploadPreReleaseScriptArtifactsIfNeeded(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, state *releaseState) {
+	if !releaser.shouldUploadArtifacts || len(state.ArtifactFilepaths) == 0 {
+		return
+	}
+	if skipDryRunStep(kudetConfig.DryRunSteps, kudet_config.ReleaseAssetsDryRunStep, "uploading the pre-release scripts' artifacts") {
+		return
+	}
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred determining the forge to upload the artifacts of release '%s' to; the release itself succeeded:\n%v", state.Version, err)
+		return
+	}
+	for _, artifactFilepath := range state.ArtifactFilepaths {
+		artifactName := filepath.Base(artifactFilepath)
+		artifactContents, err := os.ReadFile(artifactFilepath)
+		if err != nil {
+			logrus.Errorf("ACTION REQUIRED: An error occurred reading artifact '%s' of release '%s' to upload it; the release itself succeeded:\n%v", artifactFilepath, state.Version, err)
+			continue
+		}
+		logrus.Infof("Uploading '%s' to the %s release of version '%s'...", artifactName, releaseForge.getName(), state.Version)
+		assetUrl, err := releaseForge.uploadReleaseFileAsset(ctx, state.Version, state.ReleaseNotes, artifactName, artifactContents)
+		if err != nil {
+			logrus.Errorf("ACTION REQUIRED: An error occurred uploading artifact '%s' of release '%s'; the release itself succeeded:\n%v", artifactName, state.Version, err)
+			continue
+		}
+		logrus.Infof("Uploaded artifact to '%s'", assetUrl)
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getArtifactRelFilepaths returns the slash-separated paths, relative to the repo root, of the files matching the globs
+func getArtifactRelFilepaths(repoDirpath string, artifactGlobs []string) ([]string, error) {
+	isMatched := map[string]bool{}
+	for _, artifactGlob := range artifactGlobs {
+		matchingFilepaths, err := filepath.Glob(filepath.Join(repoDirpath, filepath.FromSlash(artifactGlob)))
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Artifact '%s' is an invalid glob", artifactGlob)
+		}
+		for _, matchingFilepath := range matchingFilepaths {
+			fileInfo, err := os.Stat(matchingFilepath)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred getting info on artifact '%s'", matchingFilepath)
+			}
+			// Directories would have to be archived first, which is left to the script
+			if !fileInfo.Mode().IsRegular() {
+				continue
+			}
+			relFilepath, err := filepath.Rel(repoDirpath, matchingFilepath)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred getting the path of artifact '%s' relative to the repo root", matchingFilepath)
+			}
+			isMatched[filepath.ToSlash(relFilepath)] = true
+		}
+	}
+	artifactRelFilepaths := []string{}
+	for relFilepath := range isMatched {
+		artifactRelFilepaths = append(artifactRelFilepaths, relFilepath)
+	}
+	sort.Strings(artifactRelFilepaths)
+	return artifactRelFilepaths, nil
+}
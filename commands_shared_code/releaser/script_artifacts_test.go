@@ -0,0 +1,46 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectArtifacts(t *testing.T) {
+	repoDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDirpath, "dist", "sboms.d"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "dist", "app.spdx.json"), []byte("sbom"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "build.log"), []byte("log"), 0644))
+	artifactsDirpath := filepath.Join(t.TempDir(), preReleaseScriptArtifactsDirname)
+
+	collectedArtifactSources := map[string]string{}
+	artifactFilepaths, err := collectArtifacts(repoDirpath, "pre release script 'scripts/build.sh'", []string{"dist/*", "build.log", "missing.txt"}, artifactsDirpath, collectedArtifactSources)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(artifactsDirpath, "build.log"), filepath.Join(artifactsDirpath, "app.spdx.json")}, artifactFilepaths)
+	sbom, err := os.ReadFile(filepath.Join(artifactsDirpath, "app.spdx.json"))
+	require.NoError(t, err)
+	require.Equal(t, "sbom", string(sbom))
+
+	// Another script's artifact can't overwrite one that was already collected
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "dist", "build.log"), []byte("other log"), 0644))
+	_, err = collectArtifacts(repoDirpath, "pre release script 'scripts/package.sh'", []string{"dist/build.log"}, artifactsDirpath, collectedArtifactSources)
+	require.ErrorContains(t, err, "would both be collected as 'build.log'")
+}
+
+func TestCollectArtifacts_NothingProduced(t *testing.T) {
+	artifactsDirpath := filepath.Join(t.TempDir(), preReleaseScriptArtifactsDirname)
+	artifactFilepaths, err := collectArtifacts(t.TempDir(), "pre release script 'scripts/build.sh'", []string{"dist/*"}, artifactsDirpath, map[string]string{})
+	require.NoError(t, err)
+	require.Empty(t, artifactFilepaths)
+	require.NoDirExists(t, artifactsDirpath)
+}
+
+func TestVerifyPreReleaseScriptArtifactsConfig(t *testing.T) {
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.PreReleaseScriptArtifacts = map[string][]string{"scripts/build.sh": {"build.log"}}
+	require.NoError(t, verifyPreReleaseScriptArtifactsConfig(kudetConfig, []*preReleaseScript{{relFilepath: "scripts/build.sh"}}))
+	require.ErrorContains(t, verifyPreReleaseScriptArtifactsConfig(kudetConfig, []*preReleaseScript{{relFilepath: "scripts/bump.sh"}}), "isn't in")
+}
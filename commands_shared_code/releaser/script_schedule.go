@@ -0,0 +1,176 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"path"
+	"strings"
+	"sync"
+)
+
+const (
+	// A path ending in this matches everything under the directory before it, e.g. 'api/**'
+	recursiveDirPathSuffix = "/**"
+)
+
+// getPreReleaseScriptGroups splits the scripts into groups that run one after the other, where the scripts of a group
+// can run at the same time. Run one at a time, each script is a group of its own in the order they're listed; run in
+// parallel, each group holds the scripts whose dependencies have all run in the groups before it.
+func getPreReleaseScriptGroups(scripts []*preReleaseScript, schedule map[string]kudet_config.PreReleaseScriptScheduleConfig, parallelism int) ([][]*preReleaseScript, error) {
+	groupIdxs := map[string]int{}
+	for _, script := range scripts {
+		if !script.isInline() {
+			groupIdxs[script.relFilepath] = -1
+		}
+	}
+	for scriptRelFilepath := range schedule {
+		if _, found := groupIdxs[scriptRelFilepath]; !found {
+			return nil, stacktrace.NewError("A schedule is configured for pre release script '%s', which isn't in the pre release scripts file", scriptRelFilepath)
+		}
+	}
+
+	groups := [][]*preReleaseScript{}
+	for _, script := range scripts {
+		if parallelism <= 1 {
+			groups = append(groups, []*preReleaseScript{script})
+		}
+		if script.isInline() {
+			// Inline commands can't be scheduled, so they only wait for what's listed before them when run one at a time
+			if parallelism > 1 {
+				groups = addToPreReleaseScriptGroup(groups, 0, script)
+			}
+			continue
+		}
+		groupIdx := 0
+		// Dependencies must be listed first, which also rules out cycles
+		for _, dependencyRelFilepath := range schedule[script.relFilepath].DependsOn {
+			dependencyGroupIdx, found := groupIdxs[dependencyRelFilepath]
+			if !found || dependencyGroupIdx < 0 {
+				return nil, stacktrace.NewError("Pre release script '%s' depends on '%s', which isn't listed before it in the pre release scripts file", script.relFilepath, dependencyRelFilepath)
+			}
+			if dependencyGroupIdx+1 > groupIdx {
+				groupIdx = dependencyGroupIdx + 1
+			}
+		}
+		if parallelism <= 1 {
+			groupIdxs[script.relFilepath] = len(groups) - 1
+			continue
+		}
+		groupIdxs[script.relFilepath] = groupIdx
+		groups = addToPreReleaseScriptGroup(groups, groupIdx, script)
+	}
+	return groups, nil
+}
+
+// getFilepathsChangedSinceRelease lists the files that changed between the release of the given version and HEAD, or
+// returns false if there's no such release to compare against
+func getFilepathsChangedSinceRelease(repository vcs.Repository, version string) ([]string, bool, error) {
+	releaseCommitHash, found, err := getReleaseCommitHash(repository, version)
+	if err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred getting the commit of release '%s'", version)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	headCommitHash, err := repository.GetHeadCommitHash()
+	if err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred getting the HEAD commit")
+	}
+	changedFilepaths, err := repository.GetChangedFilepaths(releaseCommitHash, headCommitHash)
+	if err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred listing the files changed since release '%s'", version)
+	}
+	return changedFilepaths, true, nil
+}
+
+// getChangedFilepathMatch returns the first of the changed files that matches one of the paths, or false if none does
+func getChangedFilepathMatch(changedFilepaths []string, onlyIfChangedPaths []string) (string, bool) {
+	for _, changedFilepath := range changedFilepaths {
+		for _, onlyIfChangedPath := range onlyIfChangedPaths {
+			if isPathMatch(onlyIfChangedPath, changedFilepath) {
+				return changedFilepath, true
+			}
+		}
+	}
+	return "", false
+}
+
+// runPreReleaseScriptGroup runs the scripts of a group, at most parallelism of them at a time, and waits for all of them
+// to finish. The first script to fail cancels the ones still running, as the release is rolled back anyway, and its
+// error is the one returned.
+func runPreReleaseScriptGroup(ctx context.Context, scripts []*preReleaseScript, parallelism int, runScript func(ctx context.Context, script *preReleaseScript) error) error {
+	if parallelism <= 1 || len(scripts) == 1 {
+		for _, script := range scripts {
+			if err := runScript(ctx, script); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	groupCtx, cancelGroup := context.WithCancel(ctx)
+	defer cancelGroup()
+	var firstErr error
+	firstErrMutex := &sync.Mutex{}
+	runSlots := make(chan struct{}, parallelism)
+	waitGroup := &sync.WaitGroup{}
+	for _, script := range scripts {
+		waitGroup.Add(1)
+		go func(script *preReleaseScript) {
+			defer waitGroup.Done()
+			runSlots <- struct{}{}
+			defer func() { <-runSlots }()
+			if groupCtx.Err() != nil {
+				return
+			}
+			err := runScript(groupCtx, script)
+			if err == nil {
+				return
+			}
+			firstErrMutex.Lock()
+			defer firstErrMutex.Unlock()
+			if firstErr == nil {
+				firstErr = err
+				cancelGroup()
+			}
+		}(script)
+	}
+	waitGroup.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	// The release being interrupted while its scripts wait to run is reported like it interrupting a running script
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return stacktrace.Propagate(ctxErr, "The pre release scripts were interrupted")
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func addToPreReleaseScriptGroup(groups [][]*preReleaseScript, groupIdx int, script *preReleaseScript) [][]*preReleaseScript {
+	for len(groups) <= groupIdx {
+		groups = append(groups, []*preReleaseScript{})
+	}
+	groups[groupIdx] = append(groups[groupIdx], script)
+	return groups
+}
+
+// isPathMatch reports whether the slash-separated file path matches the glob, or is under the directory if the path
+// ends in '/' or '/**'
+func isPathMatch(pathPattern string, filepath string) bool {
+	if strings.HasSuffix(pathPattern, recursiveDirPathSuffix) {
+		return strings.HasPrefix(filepath, strings.TrimSuffix(pathPattern, "**"))
+	}
+	if strings.HasSuffix(pathPattern, "/") {
+		return strings.HasPrefix(filepath, pathPattern)
+	}
+	// Invalid globs are refused when the config is loaded
+	isMatch, _ := path.Match(pathPattern, filepath)
+	return isMatch
+}
@@ -0,0 +1,101 @@
+package releaser
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPreReleaseScriptGroups(t *testing.T) {
+	spec := &preReleaseScript{relFilepath: "scripts/spec.sh"}
+	docs := &preReleaseScript{relFilepath: "scripts/docs.sh"}
+	inline := &preReleaseScript{inlineCommandLines: []string{"echo hi"}}
+	build := &preReleaseScript{relFilepath: "scripts/build.sh"}
+	scripts := []*preReleaseScript{spec, docs, inline, build}
+	schedule := map[string]kudet_config.PreReleaseScriptScheduleConfig{
+		"scripts/docs.sh":  {DependsOn: []string{"scripts/spec.sh"}},
+		"scripts/build.sh": {DependsOn: []string{"scripts/spec.sh"}},
+	}
+
+	groups, err := getPreReleaseScriptGroups(scripts, schedule, 4)
+	require.NoError(t, err)
+	require.Equal(t, [][]*preReleaseScript{{spec, inline}, {docs, build}}, groups)
+
+	// One at a time, the scripts keep the order they're listed in
+	groups, err = getPreReleaseScriptGroups(scripts, schedule, 1)
+	require.NoError(t, err)
+	require.Equal(t, [][]*preReleaseScript{{spec}, {docs}, {inline}, {build}}, groups)
+}
+
+func TestGetPreReleaseScriptGroups_Misconfigured(t *testing.T) {
+	scripts := []*preReleaseScript{{relFilepath: "scripts/spec.sh"}, {relFilepath: "scripts/build.sh"}}
+
+	_, err := getPreReleaseScriptGroups(scripts, map[string]kudet_config.PreReleaseScriptScheduleConfig{
+		"scripts/spec.sh": {DependsOn: []string{"scripts/build.sh"}},
+	}, 4)
+	require.ErrorContains(t, err, "isn't listed before it")
+
+	_, err = getPreReleaseScriptGroups(scripts, map[string]kudet_config.PreReleaseScriptScheduleConfig{
+		"scripts/build.sh": {DependsOn: []string{"scripts/build.sh"}},
+	}, 1)
+	require.ErrorContains(t, err, "isn't listed before it")
+
+	_, err = getPreReleaseScriptGroups(scripts, map[string]kudet_config.PreReleaseScriptScheduleConfig{
+		"scripts/bump.sh": {OnlyIfChanged: []string{"api/**"}},
+	}, 4)
+	require.ErrorContains(t, err, "isn't in the pre release scripts file")
+}
+
+func TestGetChangedFilepathMatch(t *testing.T) {
+	changedFilepaths := []string{"README.md", "api/v1/service.proto"}
+
+	changedFilepath, found := getChangedFilepathMatch(changedFilepaths, []string{"api/**"})
+	require.True(t, found)
+	require.Equal(t, "api/v1/service.proto", changedFilepath)
+	_, found = getChangedFilepathMatch(changedFilepaths, []string{"api/"})
+	require.True(t, found)
+	_, found = getChangedFilepathMatch(changedFilepaths, []string{"*.md"})
+	require.True(t, found)
+	_, found = getChangedFilepathMatch(changedFilepaths, []string{"api/*.proto", "apis/**", "docs/"})
+	require.False(t, found)
+}
+
+func TestRunPreReleaseScriptGroup(t *testing.T) {
+	scripts := []*preReleaseScript{{relFilepath: "a.sh"}, {relFilepath: "b.sh"}, {relFilepath: "c.sh"}}
+	runningCount := 0
+	maxRunningCount := 0
+	mutex := &sync.Mutex{}
+	err := runPreReleaseScriptGroup(context.Background(), scripts, 2, func(ctx context.Context, script *preReleaseScript) error {
+		mutex.Lock()
+		runningCount++
+		if runningCount > maxRunningCount {
+			maxRunningCount = runningCount
+		}
+		mutex.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mutex.Lock()
+		runningCount--
+		mutex.Unlock()
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, maxRunningCount)
+}
+
+func TestRunPreReleaseScriptGroup_FailureCancelsOthers(t *testing.T) {
+	scripts := []*preReleaseScript{{relFilepath: "slow.sh"}, {relFilepath: "failing.sh"}}
+	scriptErr := errors.New("failed")
+	err := runPreReleaseScriptGroup(context.Background(), scripts, 2, func(ctx context.Context, script *preReleaseScript) error {
+		if script.relFilepath == "failing.sh" {
+			return scriptErr
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.Equal(t, scriptErr, err)
+}
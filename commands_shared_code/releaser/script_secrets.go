@@ -0,0 +1,169 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/stacktrace"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	vaultAddrEnvVar      = "VAULT_ADDR"
+	vaultTokenEnvVar     = "VAULT_TOKEN"
+	vaultNamespaceEnvVar = "VAULT_NAMESPACE"
+
+	vaultTokenHeader     = "X-Vault-Token"
+	vaultNamespaceHeader = "X-Vault-Namespace"
+	vaultRequestTimeout  = 10 * time.Second
+
+	// What secrets are replaced with in the scripts' output, which gets logged and put in errors
+	maskedSecretStr = "***"
+)
+
+// vaultSecretResponse is the part of Vault's response to reading a secret that we use; KV version 2 secrets nest their
+// values under a second 'data'
+type vaultSecretResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// resolvePreReleaseScriptSecrets reads the secrets of each of the scripts, keyed by the script's path, before any script
+// runs so that a secret that can't be read stops the release before anything has changed
+func resolvePreReleaseScriptSecrets(ctx context.Context, repoDirpath string, kudetConfig *kudet_config.KudetConfig, scripts []*preReleaseScript) (map[string]map[string]string, error) {
+	secretValuesByScript := map[string]map[string]string{}
+	for _, script := range scripts {
+		if script.isInline() {
+			continue
+		}
+		secrets, found := kudetConfig.PreReleaseScriptSecrets[script.relFilepath]
+		if !found {
+			continue
+		}
+		secretValues := map[string]string{}
+		for _, secret := range secrets {
+			secretValue, err := resolveSecret(ctx, repoDirpath, kudetConfig.PreReleaseScriptsShell, secret)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred reading secret '%s' of pre release script '%s' from %s", secret.Name, script.relFilepath, secret.Source)
+			}
+			secretValues[secret.Name] = secretValue
+		}
+		secretValuesByScript[script.relFilepath] = secretValues
+	}
+	for scriptRelFilepath := range kudetConfig.PreReleaseScriptSecrets {
+		if _, found := secretValuesByScript[scriptRelFilepath]; !found {
+			return nil, stacktrace.NewError("Secrets are configured for pre release script '%s', which isn't in '%s'", scriptRelFilepath, kudetConfig.PreReleaseScriptsFilepath)
+		}
+	}
+	return secretValuesByScript, nil
+}
+
+// getSecretEnvVars returns the environment variables that hand the secrets to a script
+func getSecretEnvVars(secretValues map[string]string) []string {
+	envVars := []string{}
+	for name, value := range secretValues {
+		envVars = append(envVars, fmt.Sprintf("%s=%s", name, value))
+	}
+	return envVars
+}
+
+// maskSecrets hides the secrets' values in a script's output
+func maskSecrets(output string, secretValues map[string]string) string {
+	for _, value := range secretValues {
+		if value == "" {
+			continue
+		}
+		output = strings.ReplaceAll(output, value, maskedSecretStr)
+	}
+	return output
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func resolveSecret(ctx context.Context, repoDirpath string, shell []string, secret kudet_config.SecretConfig) (string, error) {
+	switch secret.Source {
+	case kudet_config.EnvSecretSource:
+		value, found := os.LookupEnv(secret.Ref)
+		if !found {
+			return "", stacktrace.NewError("Environment variable '%s' isn't set", secret.Ref)
+		}
+		return value, nil
+	case kudet_config.VaultSecretSource:
+		return readVaultSecret(ctx, secret.Ref)
+	case kudet_config.CommandSecretSource:
+		shellArgs := append(append([]string{}, shell[1:]...), secret.Ref)
+		secretCmd := exec.CommandContext(ctx, shell[0], shellArgs...)
+		secretCmd.Dir = repoDirpath
+		stderr := &bytes.Buffer{}
+		secretCmd.Stderr = stderr
+		// The output is the secret, so it's never logged
+		output, err := secretCmd.Output()
+		if err != nil {
+			return "", stacktrace.Propagate(err, "Secret command '%s' failed with error output:\n%s", secret.Ref, stderr.String())
+		}
+		return strings.TrimRight(string(output), "\r\n"), nil
+	}
+	// Unknown sources are refused when the config is loaded
+	return "", stacktrace.NewError("Unknown secret source '%s'", secret.Source)
+}
+
+// readVaultSecret reads the value under the key of a Vault secret, given as 'path#key', from the Vault that the standard
+// Vault environment variables point at
+func readVaultSecret(ctx context.Context, ref string) (string, error) {
+	vaultAddr := os.Getenv(vaultAddrEnvVar)
+	vaultToken := os.Getenv(vaultTokenEnvVar)
+	if vaultAddr == "" || vaultToken == "" {
+		return "", stacktrace.NewError("Reading secrets from Vault needs '%s' and '%s' to be set", vaultAddrEnvVar, vaultTokenEnvVar)
+	}
+	separatorIdx := strings.LastIndex(ref, kudet_config.VaultSecretRefKeySeparator)
+	secretPath := strings.Trim(ref[:separatorIdx], "/")
+	secretKey := ref[separatorIdx+len(kudet_config.VaultSecretRefKeySeparator):]
+
+	secretUrl := fmt.Sprintf("%s/v1/%s", strings.TrimRight(vaultAddr, "/"), secretPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretUrl, nil)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred building the request for Vault secret '%s'", secretPath)
+	}
+	req.Header.Set(vaultTokenHeader, vaultToken)
+	if vaultNamespace := os.Getenv(vaultNamespaceEnvVar); vaultNamespace != "" {
+		req.Header.Set(vaultNamespaceHeader, vaultNamespace)
+	}
+	httpClient := &http.Client{Timeout: vaultRequestTimeout}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred requesting Vault secret '%s'", secretPath)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", stacktrace.NewError("Vault responded to the request for secret '%s' with status '%s'", secretPath, resp.Status)
+	}
+	secretResponse := &vaultSecretResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(secretResponse); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred decoding Vault secret '%s'", secretPath)
+	}
+	return getVaultSecretValue(secretResponse, secretPath, secretKey)
+}
+
+func getVaultSecretValue(secretResponse *vaultSecretResponse, secretPath string, secretKey string) (string, error) {
+	secretData := secretResponse.Data
+	if nestedData, isKvV2 := secretData["data"].(map[string]interface{}); isKvV2 {
+		secretData = nestedData
+	}
+	value, found := secretData[secretKey]
+	if !found {
+		return "", stacktrace.NewError("Vault secret '%s' has no key '%s'", secretPath, secretKey)
+	}
+	valueStr, isString := value.(string)
+	if !isString {
+		return "", stacktrace.NewError("The value under key '%s' of Vault secret '%s' isn't a string", secretKey, secretPath)
+	}
+	return valueStr, nil
+}
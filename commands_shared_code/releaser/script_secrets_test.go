@@ -0,0 +1,66 @@
+package releaser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePreReleaseScriptSecrets(t *testing.T) {
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(vaultTokenHeader) != "vault-token" || r.URL.Path != "/v1/secret/data/npm" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"data": {"token": "npm-secret"}, "metadata": {"version": 3}}}`))
+	}))
+	defer vaultServer.Close()
+	t.Setenv(vaultAddrEnvVar, vaultServer.URL)
+	t.Setenv(vaultTokenEnvVar, "vault-token")
+	t.Setenv("CI_PYPI_TOKEN", "pypi-secret")
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.PreReleaseScriptSecrets = map[string][]kudet_config.SecretConfig{
+		"scripts/publish.sh": {
+			{Name: "NPM_TOKEN", Source: kudet_config.VaultSecretSource, Ref: "secret/data/npm#token"},
+			{Name: "PYPI_TOKEN", Source: kudet_config.EnvSecretSource, Ref: "CI_PYPI_TOKEN"},
+			{Name: "GCP_TOKEN", Source: kudet_config.CommandSecretSource, Ref: "echo gcp-secret"},
+		},
+	}
+	scripts := []*preReleaseScript{
+		{relFilepath: "scripts/bump.sh"},
+		{relFilepath: "scripts/publish.sh"},
+	}
+	secretValuesByScript, err := resolvePreReleaseScriptSecrets(context.Background(), t.TempDir(), kudetConfig, scripts)
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]string{
+		"scripts/publish.sh": {
+			"NPM_TOKEN":  "npm-secret",
+			"PYPI_TOKEN": "pypi-secret",
+			"GCP_TOKEN":  "gcp-secret",
+		},
+	}, secretValuesByScript)
+
+	// Secrets for a script that isn't run are almost certainly a typo
+	_, err = resolvePreReleaseScriptSecrets(context.Background(), t.TempDir(), kudetConfig, scripts[:1])
+	require.ErrorContains(t, err, "isn't in")
+}
+
+func TestGetVaultSecretValue_SupportsBothKvVersions(t *testing.T) {
+	kvV1Response := &vaultSecretResponse{Data: map[string]interface{}{"token": "v1-secret"}}
+	value, err := getVaultSecretValue(kvV1Response, "kv/npm", "token")
+	require.NoError(t, err)
+	require.Equal(t, "v1-secret", value)
+
+	_, err = getVaultSecretValue(kvV1Response, "kv/npm", "password")
+	require.Error(t, err)
+}
+
+func TestMaskSecrets(t *testing.T) {
+	secretValues := map[string]string{"NPM_TOKEN": "npm-secret", "EMPTY": ""}
+	require.Equal(t, "Publishing with *** as user ***...", maskSecrets("Publishing with npm-secret as user npm-secret...", secretValues))
+}
@@ -0,0 +1,48 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// getSecurityAdvisorySeverity picks the severity given for the release over the repo's default, failing before
+// anything is released if neither is usable
+func getSecurityAdvisorySeverity(releaseSeverity string, advisoryConfig kudet_config.SecurityAdvisoryConfig) (string, error) {
+	severity := releaseSeverity
+	if severity == "" {
+		severity = advisoryConfig.Severity
+	}
+	if severity == "" {
+		return "", stacktrace.NewError("Security releases need a severity, either given for the release or as '%s.%s' in the kudet config", kudet_config.SecurityAdvisoryKey, kudet_config.SeverityKey)
+	}
+	for _, allowedSeverity := range strings.Split(kudet_config.SecurityAdvisorySeverities, ",") {
+		if severity == allowedSeverity {
+			return severity, nil
+		}
+	}
+	return "", stacktrace.NewError("Security advisory severity '%s' must be one of '%s'", severity, kudet_config.SecurityAdvisorySeverities)
+}
+
+// draftSecurityAdvisoryIfNeeded drafts the advisory of a security release; since the fix has been committed by the
+// time it runs, failures are only logged for the operator to draft the advisory by hand
+func (releaser *Releaser) draftSecurityAdvisoryIfNeeded(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, severity string, version string, releaseNotes string) {
+	if !releaser.isSecurityRelease {
+		return
+	}
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred determining the forge to draft the security advisory for version '%s' on; please draft it by hand:\n%v", version, err)
+		return
+	}
+	logrus.Infof("Drafting a %s security advisory for version '%s'...", releaseForge.getName(), version)
+	advisoryUrl, err := releaseForge.draftSecurityAdvisory(ctx, kudetConfig.SecurityAdvisory, severity, version, releaseNotes)
+	if err != nil {
+		logrus.Errorf("ACTION REQUIRED: An error occurred drafting the security advisory for version '%s'; please draft it by hand:\n%v", version, err)
+		return
+	}
+	logrus.Infof("Drafted security advisory '%s'; publish it once the release is public", advisoryUrl)
+}
@@ -0,0 +1,26 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecurityAdvisorySeverity(t *testing.T) {
+	advisoryConfig := kudet_config.SecurityAdvisoryConfig{Severity: "medium"}
+
+	severity, err := getSecurityAdvisorySeverity("", advisoryConfig)
+	require.NoError(t, err)
+	require.Equal(t, "medium", severity)
+
+	severity, err = getSecurityAdvisorySeverity("critical", advisoryConfig)
+	require.NoError(t, err)
+	require.Equal(t, "critical", severity)
+
+	_, err = getSecurityAdvisorySeverity("urgent", advisoryConfig)
+	require.ErrorContains(t, err, "must be one of")
+
+	_, err = getSecurityAdvisorySeverity("", kudet_config.SecurityAdvisoryConfig{})
+	require.ErrorContains(t, err, "need a severity")
+}
@@ -0,0 +1,269 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"time"
+)
+
+const (
+	// A date on its own stands for the end of that day, in local time
+	simulationDateFormat = "2006-01-02"
+
+	simulatedChangelogFilePattern = "kudet-simulated-changelog-*.md"
+)
+
+// ReleaseSimulation is what 'kudet release' would have done to the release branch as of a past commit
+type ReleaseSimulation struct {
+	CommitHash string
+	CommitTime time.Time
+
+	PreviousVersion string
+	NextVersion     string
+	// Why the next version bumps the part of the previous one that it does
+	BumpReason string
+
+	ReleaseCommitMessage string
+
+	// The top of the finalized changelog, down to the previous version's header
+	FinalizedChangelogHeadLines []string
+}
+
+// SimulateRelease replays how the next version would have been decided and the changelog finalized by a release cut
+// at a past commit, given either as a revision or as a date ('2006-01-02' for the end of that day, or RFC 3339) of the
+// release branch's history. The current kudet config is used rather than the one at the time, so that changes to the
+// config can be checked against the repo's real history; releases it would refuse fail with the same kinds of error.
+func SimulateRelease(repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, asOf string) (*ReleaseSimulation, error) {
+	commitHash, err := resolveSimulatedCommit(repository, kudetConfig.ReleaseBranch, asOf)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred resolving the commit to simulate the release at")
+	}
+	commitTime, err := repository.GetCommitTime(commitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting when commit '%s' was made", commitHash)
+	}
+
+	changelogFile, found, err := repository.ReadFileAtCommit(commitHash, kudetConfig.ChangelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the changelog as of commit '%s'", commitHash)
+	}
+	if !found {
+		return nil, stacktrace.NewErrorWithCode(ErrChangelogInvalid.code, "There was no changelog at '%s' as of commit '%s'", kudetConfig.ChangelogFilepath, commitHash)
+	}
+	tbdSkeletonLines, err := readChangelogTbdSkeletonAtCommit(repository, commitHash, kudetConfig.ChangelogTbdSkeletonFilepath)
+	if err != nil {
+		return nil, stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred reading the changelog's TBD skeleton as of commit '%s'", commitHash)
+	}
+	changelogFile = pruneChangelogTbdSkeleton(changelogFile, tbdSkeletonLines)
+	rules := newBumpRules(kudetConfig)
+	changes, err := parseChangeLogFile(changelogFile, rules)
+	if err != nil {
+		return nil, stacktrace.PropagateWithCode(err, ErrChangelogInvalid.code, "The changelog at '%s' was invalid as of commit '%s'", kudetConfig.ChangelogFilepath, commitHash)
+	}
+	additionalChanges, err := parseAdditionalChangelogsAtCommit(repository, commitHash, kudetConfig.AdditionalChangelogFilepaths, tbdSkeletonLines, rules)
+	if err != nil {
+		return nil, stacktrace.PropagateWithCode(err, ErrChangelogInvalid.code, "An error occurred validating the additional changelogs as of commit '%s'", commitHash)
+	}
+	changes = mergeChangelogChanges(changes, additionalChanges)
+
+	previousVersion, err := getLatestReleaseVersionAsOf(repository, kudetConfig.TagParsing, commitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the latest release version as of commit '%s'", commitHash)
+	}
+	nextVersion := getNextReleaseVersion(previousVersion, changes, false)
+
+	finalizedChangelog, err := finalizeSimulatedChangelog(changelogFile, nextVersion.String(), tbdSkeletonLines, kudetConfig.ChangelogMaxLineLength)
+	if err != nil {
+		return nil, stacktrace.PropagateWithCode(err, ErrChangelogInvalid.code, "An error occurred finalizing the changelog as of commit '%s'", commitHash)
+	}
+	commitMsg, err := getReleaseCommitMessage(kudetConfig, nextVersion.String(), previousVersion.String(), commitTime)
+	if err != nil {
+		return nil, stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred rendering the release commit message")
+	}
+
+	return &ReleaseSimulation{
+		CommitHash:                  commitHash,
+		CommitTime:                  commitTime,
+		PreviousVersion:             previousVersion.String(),
+		NextVersion:                 nextVersion.String(),
+		BumpReason:                  getBumpReason(changes),
+		ReleaseCommitMessage:        commitMsg,
+		FinalizedChangelogHeadLines: getChangelogHeadLines(finalizedChangelog),
+	}, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func resolveSimulatedCommit(repository vcs.Repository, releaseBranchName string, asOf string) (string, error) {
+	asOfTime, isTime := parseSimulationTime(asOf)
+	if !isTime {
+		commitHash, err := repository.ResolveRevision(asOf)
+		if err != nil {
+			return "", stacktrace.Propagate(err, "'%s' is neither a date nor a revision of the repo", asOf)
+		}
+		return commitHash, nil
+	}
+	releaseBranchHash, err := repository.ResolveRevision(releaseBranchName)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred resolving the '%s' branch", releaseBranchName)
+	}
+	commitHash, found, err := repository.GetLatestCommitAsOf(releaseBranchHash, asOfTime)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred looking up the '%s' branch as of '%s'", releaseBranchName, asOfTime.Format(time.RFC3339))
+	}
+	if !found {
+		return "", stacktrace.NewError("The '%s' branch had no commits yet as of '%s'", releaseBranchName, asOfTime.Format(time.RFC3339))
+	}
+	return commitHash, nil
+}
+
+// parseSimulationTime parses the time to simulate the release at, or returns false if it isn't one
+func parseSimulationTime(asOf string) (time.Time, bool) {
+	if asOfTime, err := time.Parse(time.RFC3339, asOf); err == nil {
+		return asOfTime, true
+	}
+	asOfDate, err := time.ParseInLocation(simulationDateFormat, asOf, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return asOfDate.AddDate(0, 0, 1).Add(-time.Nanosecond), true
+}
+
+// getLatestReleaseVersionAsOf returns the latest release version among the tags on the commit or its ancestors
+func getLatestReleaseVersionAsOf(repository vcs.Repository, tagParsingConfig kudet_config.TagParsingConfig, commitHash string) (*semver.Version, error) {
+	tagNames, err := repository.ListTagNames()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the repo's tags")
+	}
+	reachableTagNames := []string{}
+	for _, tagName := range tagNames {
+		tagCommitHash, found, err := repository.GetTagCommitHash(tagName)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred resolving tag '%s'", tagName)
+		}
+		if !found {
+			continue
+		}
+		isReachable, err := repository.IsAncestor(tagCommitHash, commitHash)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred checking whether tag '%s' was on commit '%s' or before it", tagName, commitHash)
+		}
+		if isReachable {
+			reachableTagNames = append(reachableTagNames, tagName)
+		}
+	}
+	latestReleaseVersion, err := getLatestReleaseVersionOfTags(reachableTagNames, tagParsingConfig)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the latest release version of the tags as of commit '%s'", commitHash)
+	}
+	return latestReleaseVersion, nil
+}
+
+// readChangelogTbdSkeletonAtCommit returns the lines of the changelog's TBD skeleton as of the commit, or none if the
+// repo didn't have it yet
+func readChangelogTbdSkeletonAtCommit(repository vcs.Repository, commitHash string, skeletonRelFilepath string) ([]string, error) {
+	if skeletonRelFilepath == "" {
+		return nil, nil
+	}
+	skeleton, found, err := repository.ReadFileAtCommit(commitHash, skeletonRelFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading '%s' as of commit '%s'", skeletonRelFilepath, commitHash)
+	}
+	if !found {
+		return nil, nil
+	}
+	skeletonLines, err := parseChangelogTbdSkeleton(skeleton)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "The changelog's TBD skeleton at '%s' was invalid as of commit '%s'", skeletonRelFilepath, commitHash)
+	}
+	return skeletonLines, nil
+}
+
+// parseAdditionalChangelogsAtCommit validates the additional changelogs as of the commit, skipping those the repo didn't
+// have yet
+func parseAdditionalChangelogsAtCommit(repository vcs.Repository, commitHash string, changelogRelFilepaths []string, tbdSkeletonLines []string, rules *bumpRules) ([]*changelogChanges, error) {
+	allChanges := []*changelogChanges{}
+	for _, changelogRelFilepath := range changelogRelFilepaths {
+		changelogFile, found, err := repository.ReadFileAtCommit(commitHash, changelogRelFilepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading the additional changelog at '%s' as of commit '%s'", changelogRelFilepath, commitHash)
+		}
+		if !found {
+			continue
+		}
+		changelogFile = pruneChangelogTbdSkeleton(changelogFile, tbdSkeletonLines)
+		changes, err := parseChangeLogFile(changelogFile, rules)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "The additional changelog at '%s' was invalid as of commit '%s'", changelogRelFilepath, commitHash)
+		}
+		allChanges = append(allChanges, changes)
+	}
+	return allChanges, nil
+}
+
+// finalizeSimulatedChangelog finalizes a copy of the changelog exactly like a release does, leaving the repo untouched
+func finalizeSimulatedChangelog(changelogFile []byte, releaseVersion string, tbdSkeletonLines []string, maxLineLength int) ([]byte, error) {
+	simulatedChangelogFile, err := os.CreateTemp("", simulatedChangelogFilePattern)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred creating a file to finalize a copy of the changelog in")
+	}
+	simulatedChangelogFilepath := simulatedChangelogFile.Name()
+	defer os.Remove(simulatedChangelogFilepath)
+	_, err = simulatedChangelogFile.Write(changelogFile)
+	if closeErr := simulatedChangelogFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred copying the changelog to '%s'", simulatedChangelogFilepath)
+	}
+	if err := updateChangelog(simulatedChangelogFilepath, releaseVersion, tbdSkeletonLines, maxLineLength); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred finalizing the copy of the changelog at '%s'", simulatedChangelogFilepath)
+	}
+	finalizedChangelog, err := os.ReadFile(simulatedChangelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the finalized copy of the changelog at '%s'", simulatedChangelogFilepath)
+	}
+	return finalizedChangelog, nil
+}
+
+func getBumpReason(changes *changelogChanges) string {
+	categoryBumpReason := fmt.Sprintf("The TBD section has entries under the '%s' category, which bumps the %s version", changes.categories.bumpSubheader, changes.categories.bumpType)
+	if changes.hasMajorChange {
+		return "The TBD section has a major changes subheader, which bumps the major version"
+	}
+	if changes.categories.bumpType == majorBumpType {
+		return categoryBumpReason
+	}
+	if changes.hasBreakingChange {
+		return "The TBD section has a breaking changes subheader, which bumps the minor version"
+	}
+	if changes.categories.bumpType == minorBumpType {
+		return categoryBumpReason
+	}
+	return "The TBD section has no breaking or major changes subheader, so the patch version is bumped"
+}
+
+// getChangelogHeadLines returns the changelog's lines down to, but not including, its second version header, which
+// for a finalized changelog is the section that the release added under the TBD header
+func getChangelogHeadLines(changelog []byte) []string {
+	lines := []string{}
+	numVersionHeadersFound := 0
+	scanner := newChangelogScanner(changelog)
+	for scanner.Scan() {
+		if versionHeaderRegex.Match(scanner.Bytes()) {
+			numVersionHeadersFound++
+			if numVersionHeadersFound > 1 {
+				break
+			}
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return normalizeNotesLines(lines)
+}
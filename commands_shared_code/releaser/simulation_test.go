@@ -0,0 +1,44 @@
+package releaser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSimulationTime(t *testing.T) {
+	asOfTime, isTime := parseSimulationTime("2022-06-03T16:00:00Z")
+	require.True(t, isTime)
+	require.Equal(t, time.Date(2022, 6, 3, 16, 0, 0, 0, time.UTC), asOfTime)
+
+	// A date stands for the whole of that day
+	asOfTime, isTime = parseSimulationTime("2022-06-03")
+	require.True(t, isTime)
+	require.Equal(t, time.Date(2022, 6, 4, 0, 0, 0, 0, time.Local), asOfTime.Add(time.Nanosecond))
+
+	_, isTime = parseSimulationTime("a1b2c3d")
+	require.False(t, isTime)
+	_, isTime = parseSimulationTime("1.2.3")
+	require.False(t, isTime)
+}
+
+func TestGetNextReleaseVersion(t *testing.T) {
+	latestReleaseVersion := semver.MustParse("1.2.3")
+	require.Equal(t, "1.2.4", getNextReleaseVersion(latestReleaseVersion, &changelogChanges{}, false).String())
+	require.Equal(t, "1.3.0", getNextReleaseVersion(latestReleaseVersion, &changelogChanges{hasBreakingChange: true}, false).String())
+	require.Equal(t, "2.0.0", getNextReleaseVersion(latestReleaseVersion, &changelogChanges{hasBreakingChange: true, hasMajorChange: true}, false).String())
+	require.Equal(t, "2.0.0", getNextReleaseVersion(latestReleaseVersion, &changelogChanges{}, true).String())
+}
+
+func TestGetChangelogHeadLines(t *testing.T) {
+	finalizedChangelog := []byte("# TBD\n\n# 1.3.0\n### Breaking Changes\n* Renamed things\n\n# 1.2.3\n* Fix things\n")
+	require.Equal(t, []string{
+		"# TBD",
+		"",
+		"# 1.3.0",
+		"### Breaking Changes",
+		"* Renamed things",
+	}, getChangelogHeadLines(finalizedChangelog))
+}
@@ -0,0 +1,164 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type TagProblemKind string
+
+const (
+	// The tag looks like a version but isn't valid semver, so the releaser never considers it
+	MalformedVersionTag TagProblemKind = "malformed"
+	// The tag is valid semver but the repo's tag parsing config excludes it when determining the latest release
+	IgnoredVersionTag TagProblemKind = "ignored"
+	// The 'X.Y.Z' and 'vX.Y.Z' tags of a version point at different commits
+	MismatchedTwinTags TagProblemKind = "mismatched-twins"
+	// Only one of the 'X.Y.Z' and 'vX.Y.Z' tags of a version exists
+	MissingTwinTag TagProblemKind = "missing-twin"
+)
+
+// Anything starting with something like '1.2' or 'v1.2' is assumed to be meant as a version
+var looksLikeVersionTagRegex = regexp.MustCompile(`^v?[0-9]+\.[0-9]`)
+
+// TagProblem is an inconsistency in a repo's version tags
+type TagProblem struct {
+	Kind    TagProblemKind
+	TagName string
+	// The commit the tag points at
+	CommitHash string
+	// For missing twins, the tag that should be created on the same commit
+	TwinTagName string
+	Description string
+}
+
+// IsBlocking is false for problems that are only informational, like prerelease tags that the repo deliberately
+// doesn't count as releases
+func (problem TagProblem) IsBlocking() bool {
+	return problem.Kind != IgnoredVersionTag
+}
+
+// AuditTags finds the repo's version tags that the releaser would misread or ignore, sorted by tag name
+func AuditTags(repository vcs.Repository, tagParsingConfig kudet_config.TagParsingConfig) ([]TagProblem, error) {
+	tagNames, err := repository.ListTagNames()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the repo's tags")
+	}
+	tagCommitHashes := map[string]string{}
+	for _, tagName := range tagNames {
+		if !looksLikeVersionTagRegex.MatchString(tagName) {
+			continue
+		}
+		commitHash, found, err := repository.GetTagCommitHash(tagName)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred resolving tag '%s'", tagName)
+		}
+		if found {
+			tagCommitHashes[tagName] = commitHash
+		}
+	}
+	return auditTagCommitHashes(tagCommitHashes, tagParsingConfig), nil
+}
+
+// CreateMissingTwinTags creates the missing twin tag for each of the problems that has one, returning the created tags
+func CreateMissingTwinTags(repository vcs.Repository, problems []TagProblem) ([]string, error) {
+	createdTagNames := []string{}
+	for _, problem := range problems {
+		if problem.Kind != MissingTwinTag {
+			continue
+		}
+		if err := repository.CreateTag(problem.TwinTagName, problem.CommitHash, problem.TwinTagName); err != nil {
+			return createdTagNames, stacktrace.Propagate(err, "An error occurred creating tag '%s' as the twin of '%s'", problem.TwinTagName, problem.TagName)
+		}
+		createdTagNames = append(createdTagNames, problem.TwinTagName)
+	}
+	return createdTagNames, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func auditTagCommitHashes(tagCommitHashes map[string]string, tagParsingConfig kudet_config.TagParsingConfig) []TagProblem {
+	tagNames := []string{}
+	for tagName := range tagCommitHashes {
+		if looksLikeVersionTagRegex.MatchString(tagName) {
+			tagNames = append(tagNames, tagName)
+		}
+	}
+	sort.Strings(tagNames)
+
+	_, ignoredTagNames := parseReleaseVersionTags(tagNames, tagParsingConfig)
+	isIgnored := map[string]bool{}
+	for _, tagName := range ignoredTagNames {
+		isIgnored[tagName] = true
+	}
+
+	problems := []TagProblem{}
+	for _, tagName := range tagNames {
+		commitHash := tagCommitHashes[tagName]
+		hasVPrefix := strings.HasPrefix(tagName, vPrefix)
+		versionStr := strings.TrimPrefix(tagName, vPrefix)
+		if !semverRegex.MatchString(versionStr) {
+			problems = append(problems, TagProblem{
+				Kind:        MalformedVersionTag,
+				TagName:     tagName,
+				CommitHash:  commitHash,
+				Description: "not a valid semantic version, so releases never consider it",
+			})
+			continue
+		}
+
+		version := semver.MustParse(versionStr)
+		isPlainRelease := version.Prerelease() == "" && version.Metadata() == ""
+
+		if isIgnored[tagName] {
+			reason := "prerelease and build metadata tags aren't counted"
+			if hasVPrefix && !tagParsingConfig.AllowVPrefix {
+				reason = "'v'-prefixed tags aren't counted"
+			}
+			problems = append(problems, TagProblem{
+				Kind:        IgnoredVersionTag,
+				TagName:     tagName,
+				CommitHash:  commitHash,
+				Description: fmt.Sprintf("ignored when determining the latest release because %s by the tag parsing config", reason),
+			})
+		}
+
+		// Only releases get twin tags; prerelease tags are usually made by hand
+		if !isPlainRelease {
+			continue
+		}
+		twinTagName := vPrefix + versionStr
+		if hasVPrefix {
+			twinTagName = versionStr
+		}
+		twinCommitHash, hasTwin := tagCommitHashes[twinTagName]
+		switch {
+		case !hasTwin:
+			problems = append(problems, TagProblem{
+				Kind:        MissingTwinTag,
+				TagName:     tagName,
+				CommitHash:  commitHash,
+				TwinTagName: twinTagName,
+				Description: fmt.Sprintf("has no '%s' twin", twinTagName),
+			})
+		// Mismatched pairs are only reported once, against the bare tag
+		case twinCommitHash != commitHash && !hasVPrefix:
+			problems = append(problems, TagProblem{
+				Kind:        MismatchedTwinTags,
+				TagName:     tagName,
+				CommitHash:  commitHash,
+				Description: fmt.Sprintf("points at commit '%s' but its twin '%s' points at '%s'", commitHash, twinTagName, twinCommitHash),
+			})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,44 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditTagCommitHashes(t *testing.T) {
+	tagCommitHashes := map[string]string{
+		"0.1.0":        "aaaa",
+		"v0.1.0":       "aaaa",
+		"0.2.0":        "bbbb",
+		"v0.2.0":       "cccc",
+		"v0.3.0":       "dddd",
+		"v1.2":         "eeee",
+		"1.2.3-hotfix": "ffff",
+		"release-1":    "9999",
+	}
+
+	problems := auditTagCommitHashes(tagCommitHashes, kudet_config.TagParsingConfig{})
+	problemKinds := map[string][]TagProblemKind{}
+	for _, problem := range problems {
+		problemKinds[problem.TagName] = append(problemKinds[problem.TagName], problem.Kind)
+	}
+	require.Equal(t, map[string][]TagProblemKind{
+		"0.2.0":        {MismatchedTwinTags},
+		"1.2.3-hotfix": {IgnoredVersionTag},
+		"v0.3.0":       {IgnoredVersionTag, MissingTwinTag},
+		"v1.2":         {MalformedVersionTag},
+	}, problemKinds)
+}
+
+func TestAuditTagCommitHashesReportsMissingTwins(t *testing.T) {
+	problems := auditTagCommitHashes(map[string]string{"0.1.0": "aaaa"}, kudet_config.TagParsingConfig{})
+	require.Equal(t, []TagProblem{{
+		Kind:        MissingTwinTag,
+		TagName:     "0.1.0",
+		CommitHash:  "aaaa",
+		TwinTagName: "v0.1.0",
+		Description: "has no 'v0.1.0' twin",
+	}}, problems)
+}
@@ -0,0 +1,77 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"strings"
+	"time"
+)
+
+// The prefixes of the lines that are entries in their own right, rather than the continuation of the one above
+var changelogEntryPrefixes = []string{"* ", "- ", "+ "}
+
+// getReleaseTagMessage renders the message of a release tag from the configured template, or uses the tag's name as
+// kudet always did when there's no template
+func getReleaseTagMessage(kudetConfig *kudet_config.KudetConfig, tagName string, releaseVersion string, previousReleaseVersion string, author *vcs.Signature, releaseTime time.Time, releaseNotesLines []string) (string, error) {
+	if kudetConfig.TagMessageTemplate == "" {
+		return tagName, nil
+	}
+	entryCount, sectionEntryCounts := getChangelogEntryCounts(releaseNotesLines)
+	message, err := kudet_config.ExecuteTagMessageTemplate(kudetConfig.TagMessageTemplate, kudet_config.TagMessageData{
+		TagName:            tagName,
+		Version:            releaseVersion,
+		PreviousVersion:    previousReleaseVersion,
+		Date:               releaseTime.UTC().Format(releaseCommitDateFormat),
+		Author:             fmt.Sprintf("%s <%s>", author.Name, author.Email),
+		EntryCount:         entryCount,
+		SectionEntryCounts: sectionEntryCounts,
+	})
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred rendering the message of tag '%s'", tagName)
+	}
+	if strings.TrimSpace(message) == "" {
+		return "", stacktrace.NewError("The kudet config's '%s' renders an empty message for tag '%s'", kudet_config.TagMessageTemplateKey, tagName)
+	}
+	return message, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getChangelogEntryCounts counts the entries in the release notes, and under each of their sections by title; entries
+// under a subsection only count towards the subsection
+func getChangelogEntryCounts(releaseNotesLines []string) (int, map[string]int) {
+	notes := changelog.NewVersion("# "+changelog.UnreleasedVersionName, releaseNotesLines)
+	entryCount := countChangelogEntries(notes.Entries)
+	sectionEntryCounts := map[string]int{}
+	var countSectionEntries func(sections []*changelog.Section)
+	countSectionEntries = func(sections []*changelog.Section) {
+		for _, section := range sections {
+			sectionEntryCount := countChangelogEntries(section.Entries)
+			sectionEntryCounts[section.Title] += sectionEntryCount
+			entryCount += sectionEntryCount
+			countSectionEntries(section.Subsections)
+		}
+	}
+	countSectionEntries(notes.Sections)
+	return entryCount, sectionEntryCounts
+}
+
+func countChangelogEntries(entries []*changelog.Entry) int {
+	count := 0
+	for _, entry := range entries {
+		line := strings.TrimSpace(entry.Line)
+		for _, prefix := range changelogEntryPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,40 @@
+package releaser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReleaseTagMessage(t *testing.T) {
+	author := &vcs.Signature{Name: "Kudet", Email: "kudet@example.com"}
+	releaseTime := time.Date(2022, 6, 1, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+	releaseNotesLines := []string{
+		"* Reworded the help",
+		"### Features",
+		"* Added retries",
+		"  which back off exponentially",
+		"### Fixes",
+		"* Fixed the module",
+		"- Fixed the CLI",
+		"#### Windows",
+		"* Fixed paths",
+	}
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	message, err := getReleaseTagMessage(kudetConfig, "v1.2.3", "1.2.3", "1.2.2", author, releaseTime, releaseNotesLines)
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.3", message)
+
+	kudetConfig.TagMessageTemplate = `{{.TagName}} ({{.PreviousVersion}} -> {{.Version}}) on {{.Date}} by {{.Author}}: {{.EntryCount}} changes, {{index .SectionEntryCounts "Features"}} features, {{index .SectionEntryCounts "Fixes"}} fixes, {{index .SectionEntryCounts "Windows"}} on Windows, {{index .SectionEntryCounts "Breaking Changes"}} breaking`
+	message, err = getReleaseTagMessage(kudetConfig, "v1.2.3", "1.2.3", "1.2.2", author, releaseTime, releaseNotesLines)
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.3 (1.2.2 -> 1.2.3) on 2022-06-02 by Kudet <kudet@example.com>: 5 changes, 1 features, 2 fixes, 1 on Windows, 0 breaking", message)
+
+	kudetConfig.TagMessageTemplate = `{{if .EntryCount}}{{.Version}}{{end}}`
+	_, err = getReleaseTagMessage(kudetConfig, "1.2.3", "1.2.3", "1.2.2", author, releaseTime, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// Holds the armored, unencrypted PGP private key that the release tags are signed with, if they're to be signed
+	tagSigningKeyEnvVar = "KUDET_TAG_SIGNING_KEY"
+)
+
+// verifyReleaseTagSignatures checks that each of the release's 'X.Y.Z' and 'vX.Y.Z' tags that exists is signed by one
+// of the trusted keys; there's nothing to check before the first release
+func verifyReleaseTagSignatures(repository vcs.Repository, repoDirpath string, tagSignaturesConfig kudet_config.TagSignaturesConfig, version string) error {
+	if version == noPreviousVersion {
+		return nil
+	}
+	armoredKeyRing, keyringFilepath, err := readTrustedKeyRing(repoDirpath, tagSignaturesConfig)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the keyring of trusted tag signing keys")
+	}
+	for _, tagName := range []string{version, vPrefix + version} {
+		_, found, err := repository.GetRefHash(tagsPrefix + tagName)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting tag '%s'", tagName)
+		}
+		if !found {
+			continue
+		}
+		signingKeyId, err := repository.VerifyTagSignature(tagName, armoredKeyRing)
+		if err != nil {
+			return stacktrace.Propagate(err, "Tag '%s' failed signature verification against the keyring at '%s'", tagName, keyringFilepath)
+		}
+		logrus.Infof("Tag '%s' is signed by trusted key '%s'", tagName, signingKeyId)
+	}
+	return nil
+}
+
+// readTrustedKeyRing returns the armored keyring of trusted tag signing keys, along with the path it was read from
+func readTrustedKeyRing(repoDirpath string, tagSignaturesConfig kudet_config.TagSignaturesConfig) (string, string, error) {
+	keyringFilepath := tagSignaturesConfig.KeyringFilepath
+	if !filepath.IsAbs(keyringFilepath) {
+		keyringFilepath = filepath.Join(repoDirpath, keyringFilepath)
+	}
+	armoredKeyRing, err := os.ReadFile(keyringFilepath)
+	if err != nil {
+		return "", "", stacktrace.Propagate(err, "An error occurred reading the keyring of trusted tag signing keys at '%s'", keyringFilepath)
+	}
+	return string(armoredKeyRing), keyringFilepath, nil
+}
@@ -0,0 +1,78 @@
+package releaser
+
+import (
+	"context"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_version"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/telemetry"
+	"github.com/sirupsen/logrus"
+	"os"
+)
+
+const (
+	releaseTelemetryRunName = "kudet release"
+
+	repositoryTelemetryAttribute = "vcs.repository"
+	versionTelemetryAttribute    = "kudet.version"
+	errorTelemetryAttribute      = "kudet.error"
+)
+
+// startStep marks the start of a release step, both in the rendered progress and in the release's trace
+func (releaser *Releaser) startStep(name string) {
+	releaser.progressTracker.StartStep(name)
+	releaser.telemetryRecorder.StartSpan(name)
+}
+
+// exportReleaseTelemetry finishes the release's trace and exports it, along with its metrics, to the OpenTelemetry
+// collector if one is configured; telemetry must never affect the release, so any problem is only logged
+func (releaser *Releaser) exportReleaseTelemetry(releaseErr error) {
+	recorder := releaser.telemetryRecorder
+	if recorder == nil {
+		return
+	}
+	if releaseError, found := GetReleaseError(releaseErr); found {
+		recorder.SetAttribute(errorTelemetryAttribute, releaseError.Name)
+	}
+	recorder.Finish(releaseErr)
+	kudetConfig, err := releaser.getKudetConfig()
+	if err != nil || kudetConfig.Telemetry.OtlpEndpoint == "" {
+		return
+	}
+	telemetryConfig := kudetConfig.Telemetry
+	headers := map[string]string{}
+	if telemetryConfig.HeadersEnvVar != "" {
+		headers, err = telemetry.ParseHeaders(os.Getenv(telemetryConfig.HeadersEnvVar))
+		if err != nil {
+			logrus.Warnf("Not exporting the release's telemetry; the headers in '%s' are invalid: %v", telemetryConfig.HeadersEnvVar, err)
+			return
+		}
+	}
+	resourceAttributes := map[string]string{
+		telemetry.ServiceNameAttribute:    "kudet",
+		telemetry.ServiceVersionAttribute: kudet_version.KudetVersion,
+	}
+	if repoPath, found := releaser.getTelemetryRepoPath(); found {
+		resourceAttributes[repositoryTelemetryAttribute] = repoPath
+	}
+	// The release's own context may have been cancelled by an interrupt, which is exactly the kind of release to export
+	if err := telemetry.Export(context.Background(), recorder, telemetryConfig.OtlpEndpoint, headers, resourceAttributes); err != nil {
+		logrus.Warnf("An error occurred exporting the release's telemetry to '%s': %v", telemetryConfig.OtlpEndpoint, err)
+	}
+}
+
+// getTelemetryRepoPath gets the repo's path on its forge (e.g. 'owner/repo'), by which releases are told apart across
+// repos; repos without a recognized origin are exported without one
+func (releaser *Releaser) getTelemetryRepoPath() (string, bool) {
+	repository, err := releaser.openRepository(releaser.repoDirpath, releaser.token)
+	if err != nil {
+		return "", false
+	}
+	remoteUrl, err := repository.GetRemoteUrl()
+	if err != nil {
+		return "", false
+	}
+	_, repoPath, err := parseRemoteUrl(remoteUrl)
+	if err != nil {
+		return "", false
+	}
+	return repoPath, true
+}
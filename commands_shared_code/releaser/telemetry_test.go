@@ -0,0 +1,41 @@
+package releaser
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelease_ExportsTelemetry(t *testing.T) {
+	payloadsByPath := map[string]string{}
+	authHeaders := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		payload, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		payloadsByPath[request.URL.Path] = string(payload)
+		authHeaders = append(authHeaders, request.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer abc")
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.Telemetry = kudet_config.TelemetryConfig{OtlpEndpoint: server.URL, HeadersEnvVar: "OTEL_EXPORTER_OTLP_HEADERS"}
+
+	// The directory isn't a repo, so the release fails in its checks
+	releaser := NewReleaser(t.TempDir(), "token", WithKudetConfig(kudetConfig))
+	require.Error(t, releaser.Release(context.Background()))
+	require.Equal(t, []string{"Bearer abc", "Bearer abc"}, authHeaders)
+	require.Contains(t, payloadsByPath["/v1/traces"], `"name":"kudet release"`)
+	require.Contains(t, payloadsByPath["/v1/traces"], `{"key":"kudet.failed_step","value":{"stringValue":"Checks"}}`)
+	require.Contains(t, payloadsByPath["/v1/metrics"], `"name":"kudet.runs"`)
+
+	// Rehearsals aren't exported
+	payloadsByPath = map[string]string{}
+	sandboxReleaser := NewReleaser(t.TempDir(), "token", WithKudetConfig(kudetConfig), WithSandbox(true))
+	require.Error(t, sandboxReleaser.Release(context.Background()))
+	require.Empty(t, payloadsByPath)
+}
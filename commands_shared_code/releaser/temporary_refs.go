@@ -0,0 +1,75 @@
+package releaser
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"regexp"
+)
+
+const (
+	// The ref that a GitHub Actions or GitLab merge request pipeline was triggered for, which tells what a detached HEAD
+	// was checked out from
+	githubRefEnvVar                 = "GITHUB_REF"
+	gitlabMergeRequestRefPathEnvVar = "CI_MERGE_REQUEST_REF_PATH"
+)
+
+// temporaryRef is a kind of ref that forges create to test changes before they're merged, so that releasing from one
+// would release code that isn't on the release branch
+type temporaryRef struct {
+	pattern     *regexp.Regexp
+	description string
+}
+
+var temporaryRefs = []temporaryRef{
+	{
+		pattern:     regexp.MustCompile(`^refs/heads/gh-readonly-queue/`),
+		description: "a GitHub merge queue branch",
+	},
+	{
+		pattern:     regexp.MustCompile(`^refs/pull/\d+/(head|merge)$`),
+		description: "a GitHub pull request ref",
+	},
+	{
+		pattern:     regexp.MustCompile(`^refs/merge-requests/\d+/(head|merge|train)$`),
+		description: "a GitLab merge request ref",
+	},
+}
+
+// checkIsNotOnTemporaryRef refuses to release from a checked out merge queue or pull request branch, which the release
+// would otherwise switch away from to release the release branch instead of what was checked out
+func checkIsNotOnTemporaryRef(repository vcs.Repository, releaseBranchName string) error {
+	checkedOutBranchName, isOnBranch, err := repository.GetCheckedOutBranchName()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the checked out branch")
+	}
+	if !isOnBranch {
+		// Detached checkouts are fine as long as they're on the release branch's commit, which is checked once fetched
+		return nil
+	}
+	checkedOutRef := headRef + checkedOutBranchName
+	if description, isTemporary := getTemporaryRefDescription(checkedOutRef); isTemporary {
+		return stacktrace.NewErrorWithCode(ErrNotOnReleaseBranch.code, "Branch '%s' is checked out, which is %s rather than release branch '%s'; refusing to release code that hasn't been merged yet", checkedOutBranchName, description, releaseBranchName)
+	}
+	return nil
+}
+
+// getCiTemporaryRef gets the temporary ref, if any, that the CI pipeline running the release checked out HEAD from
+func getCiTemporaryRef() (string, string, bool) {
+	for _, envVar := range []string{githubRefEnvVar, gitlabMergeRequestRefPathEnvVar} {
+		ciRef := os.Getenv(envVar)
+		if description, isTemporary := getTemporaryRefDescription(ciRef); isTemporary {
+			return ciRef, description, true
+		}
+	}
+	return "", "", false
+}
+
+func getTemporaryRefDescription(ref string) (string, bool) {
+	for _, temporaryRef := range temporaryRefs {
+		if temporaryRef.pattern.MatchString(ref) {
+			return temporaryRef.description, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,67 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIsNotOnTemporaryRef(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte("# TBD\n"), 0644))
+	commitHash, err := repository.CommitAll("Initial commit", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	releaseBranchName, _, err := repository.GetCheckedOutBranchName()
+	require.NoError(t, err)
+	require.NoError(t, checkIsNotOnTemporaryRef(repository, releaseBranchName))
+
+	// Other branches are checked out over by the release
+	require.NoError(t, repository.SetRef(headRef+"feature", commitHash))
+	require.NoError(t, repository.CheckoutBranch("feature"))
+	require.NoError(t, checkIsNotOnTemporaryRef(repository, releaseBranchName))
+
+	mergeQueueBranchName := "gh-readonly-queue/" + releaseBranchName + "/pr-12-" + commitHash
+	require.NoError(t, repository.SetRef(headRef+mergeQueueBranchName, commitHash))
+	require.NoError(t, repository.CheckoutBranch(mergeQueueBranchName))
+	err = checkIsNotOnTemporaryRef(repository, releaseBranchName)
+	require.Error(t, err)
+	releaseErr, found := GetReleaseError(err)
+	require.True(t, found)
+	require.Equal(t, ErrNotOnReleaseBranch, releaseErr)
+}
+
+func TestGetCiTemporaryRef(t *testing.T) {
+	t.Setenv(githubRefEnvVar, "")
+	t.Setenv(gitlabMergeRequestRefPathEnvVar, "")
+	_, _, isTemporary := getCiTemporaryRef()
+	require.False(t, isTemporary)
+
+	t.Setenv(githubRefEnvVar, "refs/heads/main")
+	_, _, isTemporary = getCiTemporaryRef()
+	require.False(t, isTemporary)
+
+	for _, ciRef := range []string{"refs/pull/12/merge", "refs/heads/gh-readonly-queue/main/pr-12-0123abc"} {
+		t.Setenv(githubRefEnvVar, ciRef)
+		temporaryRef, _, isTemporary := getCiTemporaryRef()
+		require.True(t, isTemporary)
+		require.Equal(t, ciRef, temporaryRef)
+	}
+
+	t.Setenv(githubRefEnvVar, "")
+	t.Setenv(gitlabMergeRequestRefPathEnvVar, "refs/merge-requests/3/head")
+	temporaryRef, _, isTemporary := getCiTemporaryRef()
+	require.True(t, isTemporary)
+	require.Equal(t, "refs/merge-requests/3/head", temporaryRef)
+}
@@ -0,0 +1,316 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// How often a release in its undo window checks whether it's been cancelled
+	defaultUndoWindowPollInterval = 15 * time.Second
+
+	cancelledReleaseCommitMessageFormat = "Revert release '%s'\n\nThe release was cancelled with 'kudet cancel' within its undo window."
+	revertedFileMode                    = 0644
+)
+
+// undoWindowPollInterval is a variable so that tests don't have to wait for it
+var undoWindowPollInterval = defaultUndoWindowPollInterval
+
+// CancelRelease undoes a release within the kudet config's undo window of its release commit, once the confirmer
+// approves: it deletes the release's tags from origin, pushes a commit reverting the release commit, and deletes the
+// release's forge release if it has one. A release that's still waiting out its undo window notices that its tag is
+// gone and skips the post-release steps.
+func (releaser *Releaser) CancelRelease(ctx context.Context, version string) error {
+	kudetConfig, err := releaser.getKudetConfig()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the kudet config for the repo")
+	}
+	if kudetConfig.UndoWindow <= 0 {
+		return stacktrace.NewError("Releases can only be cancelled within the kudet config's '%s', which isn't set", kudet_config.UndoWindowKey)
+	}
+	releaseBranchName := kudetConfig.ReleaseBranch
+
+	repository, err := releaser.openRepository(releaser.repoDirpath, releaser.token)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the repository")
+	}
+	if err := releaser.authenticateAsGithubAppIfNeeded(ctx, repository, kudetConfig); err != nil {
+		return stacktrace.Propagate(err, "An error occurred authenticating as the kudet config's GitHub App")
+	}
+	author, err := repository.GetAuthor()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the author to make the revert commit as")
+	}
+	isClean, currWorktreeStatusStr, err := repository.GetStatus()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while trying to retrieve the status of the worktree of the repository.")
+	}
+	if !isClean {
+		return stacktrace.NewError("The branch contains modified files. Please ensure the working tree is clean before cancelling a release. Currently the status is '%s'\n", currWorktreeStatusStr)
+	}
+	logrus.Infof("Fetching from '%s'...", originRemoteName)
+	if err := repository.Fetch(ctx); err != nil {
+		return stacktrace.Propagate(err, "An error occurred fetching from '%s'", originRemoteName)
+	}
+
+	releaseCommitHash, found, err := getReleaseCommitHash(repository, version)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the commit of release '%s'", version)
+	}
+	if !found {
+		return stacktrace.NewError("Version '%s' hasn't been released", version)
+	}
+	releaseTime, err := repository.GetCommitTime(releaseCommitHash)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting when release '%s' was committed", version)
+	}
+	if sinceRelease := time.Since(releaseTime); sinceRelease > kudetConfig.UndoWindow {
+		return stacktrace.NewError("Release '%s' was cut %v ago, past the kudet config's '%s' of %v; it may already be in use, so release a fix instead", version, sinceRelease.Round(time.Second), kudet_config.UndoWindowKey, kudetConfig.UndoWindow)
+	}
+	releaseTagNames, err := getTagNamesOnCommit(repository, releaseCommitHash)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the tags of release '%s'", version)
+	}
+
+	remoteRefHashes, err := repository.ListRemoteRefs(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred listing the refs on remote '%s'", originRemoteName)
+	}
+	remoteBranchHash, found := remoteRefHashes[headRef+releaseBranchName]
+	if !found {
+		return stacktrace.NewError("Couldn't find the '%s' branch on remote '%s'", releaseBranchName, originRemoteName)
+	}
+	if err := repository.CheckoutBranch(releaseBranchName); err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking out branch '%s'", releaseBranchName)
+	}
+	headCommitHash, err := repository.GetHeadCommitHash()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the local '%s' commit", releaseBranchName)
+	}
+	if headCommitHash != remoteBranchHash {
+		return stacktrace.NewError("The local '%s' branch is on commit '%s' but the one on '%s' is on '%s'; pull or push so that they're in sync, then cancel the release", releaseBranchName, headCommitHash, originRemoteName, remoteBranchHash)
+	}
+	revertedFileContents, err := getRevertedReleaseFileContents(repository, releaseCommitHash, remoteBranchHash)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred working out how to revert release commit '%s'", releaseCommitHash)
+	}
+
+	logrus.Infof("Release '%s' is about to be cancelled: tags '%s' will be deleted from '%s', release commit '%s' will be reverted on '%s', and its forge release will be deleted", version, strings.Join(releaseTagNames, "', '"), originRemoteName, releaseCommitHash, releaseBranchName)
+	isConfirmed, err := releaser.confirmer(ctx, i18n.Sprintf(i18n.ConfirmCancelQuestion, version))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting approval to cancel release '%s'", version)
+	}
+	if !isConfirmed {
+		return stacktrace.NewError("Cancelling release '%s' wasn't approved", version)
+	}
+
+	// The tags are what make it a release, and what a release waiting out its undo window watches, so they go first
+	logrus.Infof("Deleting the tags of release '%s' from '%s'...", version, originRemoteName)
+	for _, tagName := range releaseTagNames {
+		if err := repository.Push(ctx, fmt.Sprintf(":%s%s", tagsPrefix, tagName)); err != nil {
+			return stacktrace.Propagate(err, "An error occurred deleting tag '%s' from '%s'; run 'git push --delete %s %s' and cancel the release again", tagName, originRemoteName, originRemoteName, tagName)
+		}
+	}
+	for _, tagName := range releaseTagNames {
+		if err := repository.DeleteTag(tagName); err != nil {
+			logrus.Warnf("An error occurred deleting local tag '%s'; delete it with 'git tag -d %s': %v", tagName, tagName, err)
+		}
+	}
+
+	logrus.Infof("Reverting release commit '%s'...", releaseCommitHash)
+	if err := writeRevertedReleaseFiles(releaser.repoDirpath, revertedFileContents); err != nil {
+		return stacktrace.Propagate(err, "An error occurred reverting the files of release commit '%s'", releaseCommitHash)
+	}
+	commitMsg := fmt.Sprintf(cancelledReleaseCommitMessageFormat, version)
+	if skipCiMarker := strings.TrimSpace(kudetConfig.SkipCiMarker); skipCiMarker != "" {
+		commitMsg = fmt.Sprintf("%s\n\n%s", commitMsg, skipCiMarker)
+	}
+	author.When = time.Now()
+	if _, err := repository.CommitAll(commitMsg, author); err != nil {
+		return stacktrace.Propagate(err, "An error occurred committing the revert of release commit '%s'", releaseCommitHash)
+	}
+	releaseBranchRefSpec := fmt.Sprintf("%s%s:%s%s", headRef, releaseBranchName, headRef, releaseBranchName)
+	if err := repository.PushWithLease(ctx, releaseBranchRefSpec, headRef+releaseBranchName, remoteBranchHash); err != nil {
+		return stacktrace.Propagate(err, "The tags of release '%s' were deleted, but an error occurred pushing the revert of its release commit to '%s'; pull, then push the local revert commit", version, releaseBranchName)
+	}
+
+	// A release that died before cleaning up would otherwise push the tags again when resumed
+	stateStore := newReleaseStateStore(repository.GetMetadataDirpath(), kudetConfig.ReleaseState)
+	if state, err := loadReleaseState(ctx, stateStore); err == nil && state != nil && state.Version == version {
+		if err := removeReleaseState(ctx, stateStore); err != nil {
+			logrus.Errorf("ACTION REQUIRED: An error occurred removing the state of cancelled release '%s'; run 'kudet release-state abort' so that it isn't resumed:\n%v", version, err)
+		}
+	}
+
+	releaseForge, err := releaser.getRemoteForge(repository, kudetConfig.Forge)
+	if err != nil {
+		logrus.Warnf("Not deleting the forge release of '%s', as an error occurred determining the forge; delete it by hand if there is one: %v", version, err)
+		return nil
+	}
+	isDeleted, err := releaseForge.deleteRelease(ctx, version)
+	if err != nil {
+		return stacktrace.Propagate(err, "Release '%s' was cancelled, but an error occurred deleting its %s release; delete it by hand", version, releaseForge.getName())
+	}
+	if isDeleted {
+		logrus.Infof("Deleted the %s release of '%s'", releaseForge.getName(), version)
+	}
+	logrus.Infof("Release '%s' was cancelled", version)
+	return nil
+}
+
+// waitOutUndoWindowIfNeeded holds the post-release steps back for the kudet config's undo window, failing with
+// ErrReleaseCancelled if 'kudet cancel' undoes the release in the meantime
+func (releaser *Releaser) waitOutUndoWindowIfNeeded(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, version string) error {
+	if kudetConfig.UndoWindow <= 0 {
+		return nil
+	}
+	releaser.startStep("Undo window")
+	// The window is measured from the release commit, like 'kudet cancel' does, so a release resumed or pushed long
+	// after it was committed doesn't wait any longer than it can still be cancelled for
+	releaseCommitHash, found, err := getReleaseCommitHash(repository, version)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the commit of release '%s'", version)
+	}
+	if !found {
+		return stacktrace.NewError("Couldn't find the tags of release '%s' to wait out its undo window from", version)
+	}
+	releaseTime, err := repository.GetCommitTime(releaseCommitHash)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting when release '%s' was committed", version)
+	}
+	isCancelled, err := waitOutUndoWindow(ctx, repository, releaseTime.Add(kudetConfig.UndoWindow), version)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred waiting out the undo window; the post-release steps were skipped, so run them by hand")
+	}
+	if isCancelled {
+		return stacktrace.NewErrorWithCode(ErrReleaseCancelled.code, "Release '%s' was cancelled during its undo window, so the post-release steps were skipped", version)
+	}
+	return nil
+}
+
+// waitOutUndoWindow waits for the undo window to end at the deadline, returning true if the release was cancelled in
+// the meantime, which is seen by the release tag having gone from origin
+func waitOutUndoWindow(ctx context.Context, repository vcs.Repository, deadline time.Time, version string) (bool, error) {
+	logrus.Infof("Waiting until %s before publishing release '%s' any further; until then, 'kudet cancel %s' undoes it", deadline.Format(time.Kitchen), version, version)
+	for {
+		remoteRefHashes, err := repository.ListRemoteRefs(ctx)
+		if err != nil {
+			return false, stacktrace.Propagate(err, "An error occurred listing the refs on remote '%s'", originRemoteName)
+		}
+		if _, found := remoteRefHashes[tagsPrefix+version]; !found {
+			return true, nil
+		}
+		untilDeadline := time.Until(deadline)
+		if untilDeadline <= 0 {
+			return false, nil
+		}
+		pollInterval := undoWindowPollInterval
+		if untilDeadline < pollInterval {
+			pollInterval = untilDeadline
+		}
+		select {
+		case <-ctx.Done():
+			return false, stacktrace.Propagate(ctx.Err(), "The release was interrupted during its undo window")
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getTagNamesOnCommit gets the tags pointing at the commit, e.g. a release's tag, its 'v'-prefixed one and its version
+// tracks' tags
+func getTagNamesOnCommit(repository vcs.Repository, commitHash string) ([]string, error) {
+	allTagNames, err := repository.ListTagNames()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the repo's tags")
+	}
+	tagNames := []string{}
+	for _, tagName := range allTagNames {
+		tagCommitHash, found, err := repository.GetTagCommitHash(tagName)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred resolving tag '%s'", tagName)
+		}
+		if found && tagCommitHash == commitHash {
+			tagNames = append(tagNames, tagName)
+		}
+	}
+	sort.Strings(tagNames)
+	return tagNames, nil
+}
+
+// getRevertedReleaseFileContents gets what each file that the release commit changed contained before it, with nil
+// for the files it added. Files that were changed again since would lose those changes, so they're refused.
+func getRevertedReleaseFileContents(repository vcs.Repository, releaseCommitHash string, headCommitHash string) (map[string][]byte, error) {
+	isAncestor, err := repository.IsAncestor(releaseCommitHash, headCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred checking whether release commit '%s' is on the release branch", releaseCommitHash)
+	}
+	if !isAncestor {
+		return nil, stacktrace.NewError("Release commit '%s' isn't on the release branch", releaseCommitHash)
+	}
+	parentCommitHash, err := repository.ResolveRevision(releaseCommitHash + "~1")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the parent of release commit '%s'", releaseCommitHash)
+	}
+	releasedFilepaths, err := repository.GetChangedFilepaths(parentCommitHash, releaseCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the files that release commit '%s' changed", releaseCommitHash)
+	}
+	changedSinceFilepaths, err := repository.GetChangedFilepaths(releaseCommitHash, headCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the files changed since release commit '%s'", releaseCommitHash)
+	}
+	isChangedSince := map[string]bool{}
+	for _, changedFilepath := range changedSinceFilepaths {
+		isChangedSince[changedFilepath] = true
+	}
+	revertedFileContents := map[string][]byte{}
+	for _, releasedFilepath := range releasedFilepaths {
+		if isChangedSince[releasedFilepath] {
+			return nil, stacktrace.NewError("File '%s' was changed again since release commit '%s', so the release can't be cancelled automatically; revert the commit by hand", releasedFilepath, releaseCommitHash)
+		}
+		contents, found, err := repository.ReadFileAtCommit(parentCommitHash, releasedFilepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading '%s' from before release commit '%s'", releasedFilepath, releaseCommitHash)
+		}
+		if !found {
+			contents = nil
+		}
+		revertedFileContents[releasedFilepath] = contents
+	}
+	return revertedFileContents, nil
+}
+
+// writeRevertedReleaseFiles puts the files back as they were before the release commit, removing the ones it added
+func writeRevertedReleaseFiles(repoDirpath string, revertedFileContents map[string][]byte) error {
+	for relFilepath, contents := range revertedFileContents {
+		absFilepath := filepath.Join(repoDirpath, filepath.FromSlash(relFilepath))
+		if contents == nil {
+			if err := os.Remove(absFilepath); err != nil && !os.IsNotExist(err) {
+				return stacktrace.Propagate(err, "An error occurred removing '%s'", relFilepath)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(absFilepath), artifactsDirMode); err != nil {
+			return stacktrace.Propagate(err, "An error occurred creating the directory of '%s'", relFilepath)
+		}
+		if err := os.WriteFile(absFilepath, contents, revertedFileMode); err != nil {
+			return stacktrace.Propagate(err, "An error occurred writing '%s'", relFilepath)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelRelease(t *testing.T) {
+	repoDirpath := t.TempDir()
+	originDirpath := t.TempDir()
+	_, err := git.PlainInit(originDirpath, true)
+	require.NoError(t, err)
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{originDirpath}})
+	require.NoError(t, err)
+	xdgConfigDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(xdgConfigDirpath, "git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(xdgConfigDirpath, "git", "config"), []byte("[user]\n\tname = Kudet\n\temail = kudet@example.com\n"), 0644))
+	t.Setenv("XDG_CONFIG_HOME", xdgConfigDirpath)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	writeFile := func(relFilepath string, contents string) {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, relFilepath), []byte(contents), 0644))
+	}
+	unreleasedChangelog := "# TBD\n* Fixed the module\n\n# 0.1.0\n* Initial release\n"
+	writeFile("changelog.md", unreleasedChangelog)
+	initialCommitHash, err := repository.CommitAll("Initial commit", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.0", initialCommitHash, "0.1.0"))
+	writeFile("changelog.md", "# TBD\n\n# 0.1.1\n* Fixed the module\n\n# 0.1.0\n* Initial release\n")
+	writeFile("version.txt", "0.1.1\n")
+	releaseCommitHash, err := repository.CommitAll("Release 0.1.1", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.1", releaseCommitHash, "0.1.1"))
+	require.NoError(t, repository.CreateTag("v0.1.1", releaseCommitHash, "v0.1.1"))
+	branchName, _, err := repository.GetCheckedOutBranchName()
+	require.NoError(t, err)
+	require.NoError(t, gitRepository.Push(&git.PushOptions{RemoteName: vcs.OriginRemoteName, RefSpecs: []config.RefSpec{config.RefSpec("refs/heads/" + branchName + ":refs/heads/" + branchName), "refs/tags/*:refs/tags/*"}}))
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.ReleaseBranch = branchName
+	isConfirmed := false
+	askedQuestions := []string{}
+	confirmer := func(ctx context.Context, question string) (bool, error) {
+		askedQuestions = append(askedQuestions, question)
+		return isConfirmed, nil
+	}
+
+	// Without an undo window, releases can't be cancelled
+	require.Error(t, NewReleaser(repoDirpath, "token", WithKudetConfig(kudetConfig), WithConfirmer(confirmer)).CancelRelease(context.Background(), "0.1.1"))
+
+	kudetConfig.UndoWindow = 5 * time.Minute
+	cancelReleaser := NewReleaser(repoDirpath, "token", WithKudetConfig(kudetConfig), WithConfirmer(confirmer))
+	require.Error(t, cancelReleaser.CancelRelease(context.Background(), "0.1.2"))
+	require.Error(t, cancelReleaser.CancelRelease(context.Background(), "0.1.1"))
+	require.Equal(t, []string{"Cancel release '0.1.1', deleting its tags from origin and reverting its release commit?"}, askedQuestions)
+	_, found, err := repository.GetTagCommitHash("0.1.1")
+	require.NoError(t, err)
+	require.True(t, found)
+
+	// Origin isn't on a known forge, so there's no forge release to delete
+	isConfirmed = true
+	require.NoError(t, cancelReleaser.CancelRelease(context.Background(), "0.1.1"))
+	remoteRefHashes, err := repository.ListRemoteRefs(context.Background())
+	require.NoError(t, err)
+	for _, tagName := range []string{"0.1.1", "v0.1.1"} {
+		_, found = remoteRefHashes[tagsPrefix+tagName]
+		require.False(t, found)
+		_, found, err = repository.GetTagCommitHash(tagName)
+		require.NoError(t, err)
+		require.False(t, found)
+	}
+	_, found = remoteRefHashes[tagsPrefix+"0.1.0"]
+	require.True(t, found)
+	headCommitHash, err := repository.GetHeadCommitHash()
+	require.NoError(t, err)
+	require.Equal(t, headCommitHash, remoteRefHashes[headRef+branchName])
+	isAncestor, err := repository.IsAncestor(releaseCommitHash, headCommitHash)
+	require.NoError(t, err)
+	require.True(t, isAncestor)
+	changelog, err := os.ReadFile(filepath.Join(repoDirpath, "changelog.md"))
+	require.NoError(t, err)
+	require.Equal(t, unreleasedChangelog, string(changelog))
+	_, err = os.Stat(filepath.Join(repoDirpath, "version.txt"))
+	require.True(t, os.IsNotExist(err))
+	isClean, statusStr, err := repository.GetStatus()
+	require.NoError(t, err)
+	require.True(t, isClean, statusStr)
+}
+
+func TestWaitOutUndoWindow(t *testing.T) {
+	repoDirpath := t.TempDir()
+	originDirpath := t.TempDir()
+	_, err := git.PlainInit(originDirpath, true)
+	require.NoError(t, err)
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{originDirpath}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte("# 0.1.0\n"), 0644))
+	commitHash, err := repository.CommitAll("Release 0.1.0", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.0", commitHash, "0.1.0"))
+	require.NoError(t, gitRepository.Push(&git.PushOptions{RemoteName: vcs.OriginRemoteName, RefSpecs: []config.RefSpec{"refs/tags/*:refs/tags/*"}}))
+
+	originalPollInterval := undoWindowPollInterval
+	undoWindowPollInterval = 10 * time.Millisecond
+	defer func() {
+		undoWindowPollInterval = originalPollInterval
+	}()
+
+	isCancelled, err := waitOutUndoWindow(context.Background(), repository, time.Now().Add(50*time.Millisecond), "0.1.0")
+	require.NoError(t, err)
+	require.False(t, isCancelled)
+
+	isCancelled, err = waitOutUndoWindow(context.Background(), repository, time.Now().Add(time.Minute), "0.1.1")
+	require.NoError(t, err)
+	require.True(t, isCancelled)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancelFunc()
+	_, err = waitOutUndoWindow(ctx, repository, time.Now().Add(time.Minute), "0.1.0")
+	require.Error(t, err)
+}
+
+func TestWaitOutUndoWindowIfNeeded_MeasuresFromTheReleaseCommit(t *testing.T) {
+	repoDirpath := t.TempDir()
+	originDirpath := t.TempDir()
+	_, err := git.PlainInit(originDirpath, true)
+	require.NoError(t, err)
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{originDirpath}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte("# 0.1.0\n"), 0644))
+	// Released an hour ago, e.g. by a run that died before the post-release steps, and resumed now
+	commitHash, err := repository.CommitAll("Release 0.1.0", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.0", commitHash, "0.1.0"))
+	require.NoError(t, gitRepository.Push(&git.PushOptions{RemoteName: vcs.OriginRemoteName, RefSpecs: []config.RefSpec{"refs/tags/*:refs/tags/*"}}))
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.UndoWindow = 5 * time.Minute
+	// Past the window that 'kudet cancel' allows, so there's nothing left to wait for
+	waitStartTime := time.Now()
+	require.NoError(t, NewReleaser(repoDirpath, "token").waitOutUndoWindowIfNeeded(context.Background(), repository, kudetConfig, "0.1.0"))
+	require.Less(t, time.Since(waitStartTime), time.Minute)
+}
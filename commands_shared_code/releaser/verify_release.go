@@ -0,0 +1,257 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// releaseTag is what's known locally and on the remote about one of a release's tags
+type releaseTag struct {
+	name string
+	// The hashes of the tag's ref, which for annotated tags is the tag object rather than the commit; empty if it doesn't exist
+	localRefHash  string
+	remoteRefHash string
+	// The commit the local tag points at
+	commitHash string
+}
+
+// ReleaseVerification is the problems found with one of the repo's releases
+type ReleaseVerification struct {
+	Version  string
+	Problems []string
+}
+
+// releaseVerifier holds what's needed to verify any of the repo's releases, so that it's only loaded once when
+// auditing all of them
+type releaseVerifier struct {
+	repository  vcs.Repository
+	kudetConfig *kudet_config.KudetConfig
+
+	remoteRefHashes         map[string]string
+	remoteReleaseBranchHash string
+
+	changelogFile []byte
+
+	// Empty if the repo doesn't verify tag signatures
+	armoredKeyRing  string
+	keyringFilepath string
+}
+
+// VerifyRelease checks that a published release is consistent: its 'X.Y.Z' and 'vX.Y.Z' tags exist locally and on the
+// remote and point at the same commit, that commit is on the remote's release branch, the changelog has a header for
+// the version with notes under it, and, if the repo verifies tag signatures, the tags are signed by a trusted key. The
+// problems found are returned, so a release without any is consistent.
+func VerifyRelease(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, repoDirpath string, version string) ([]string, error) {
+	version = strings.TrimPrefix(version, vPrefix)
+	if !semverRegex.MatchString(version) {
+		return nil, stacktrace.NewError("'%s' isn't a valid semantic version", version)
+	}
+	verifier, err := newReleaseVerifier(ctx, repository, kudetConfig, repoDirpath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred loading what's needed to verify releases")
+	}
+	problems, err := verifier.getProblems(version)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred verifying release '%s'", version)
+	}
+	return problems, nil
+}
+
+// VerifyAllReleases runs the checks of VerifyRelease against every release the repo has tagged, oldest first, so that
+// past releases that have drifted from the current checks can be found in one pass
+func VerifyAllReleases(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, repoDirpath string) ([]ReleaseVerification, error) {
+	tagNames, err := repository.ListTagNames()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the repo's tags")
+	}
+	versions, _ := parseReleaseVersionTags(tagNames, kudetConfig.TagParsing)
+	sort.Sort(semver.Collection(versions))
+
+	verifier, err := newReleaseVerifier(ctx, repository, kudetConfig, repoDirpath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred loading what's needed to verify releases")
+	}
+	verifications := []ReleaseVerification{}
+	verifiedVersions := map[string]bool{}
+	for _, version := range versions {
+		// The 'X.Y.Z' and 'vX.Y.Z' tags of a release both parse to its version
+		if verifiedVersions[version.String()] {
+			continue
+		}
+		verifiedVersions[version.String()] = true
+		problems, err := verifier.getProblems(version.String())
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred verifying release '%s'", version.String())
+		}
+		verifications = append(verifications, ReleaseVerification{
+			Version:  version.String(),
+			Problems: problems,
+		})
+	}
+	return verifications, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newReleaseVerifier(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, repoDirpath string) (*releaseVerifier, error) {
+	// Fetching could update the local tags, hiding the very differences being checked for
+	remoteRefHashes, err := repository.ListRemoteRefs(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the refs on the remote")
+	}
+	remoteReleaseBranchHash, found := remoteRefHashes[headRef+kudetConfig.ReleaseBranch]
+	if !found {
+		return nil, stacktrace.NewError("Couldn't find the '%s' branch on the remote", kudetConfig.ReleaseBranch)
+	}
+
+	changelogFilepath := filepath.Join(repoDirpath, kudetConfig.ChangelogFilepath)
+	changelogFile, err := os.ReadFile(changelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading changelog '%s'", changelogFilepath)
+	}
+
+	verifier := &releaseVerifier{
+		repository:              repository,
+		kudetConfig:             kudetConfig,
+		remoteRefHashes:         remoteRefHashes,
+		remoteReleaseBranchHash: remoteReleaseBranchHash,
+		changelogFile:           changelogFile,
+	}
+	if kudetConfig.TagSignatures.KeyringFilepath != "" {
+		verifier.armoredKeyRing, verifier.keyringFilepath, err = readTrustedKeyRing(repoDirpath, kudetConfig.TagSignatures)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading the keyring of trusted tag signing keys")
+		}
+	}
+	return verifier, nil
+}
+
+func (verifier *releaseVerifier) getProblems(version string) ([]string, error) {
+	tags := []releaseTag{}
+	for _, tagName := range []string{version, vPrefix + version} {
+		tag := releaseTag{
+			name:          tagName,
+			remoteRefHash: verifier.remoteRefHashes[tagsPrefix+tagName],
+		}
+		localRefHash, found, err := verifier.repository.GetRefHash(tagsPrefix + tagName)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting local tag '%s'", tagName)
+		}
+		if found {
+			commitHash, _, err := verifier.repository.GetTagCommitHash(tagName)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred resolving local tag '%s'", tagName)
+			}
+			tag.localRefHash = localRefHash
+			tag.commitHash = commitHash
+		}
+		tags = append(tags, tag)
+	}
+	problems := getReleaseTagProblems(tags)
+
+	remoteReleaseBranchName := fmt.Sprintf("%v/%v", originRemoteName, verifier.kudetConfig.ReleaseBranch)
+	checkedCommitHashes := map[string]bool{}
+	for _, tag := range tags {
+		if tag.commitHash == "" || checkedCommitHashes[tag.commitHash] {
+			continue
+		}
+		checkedCommitHashes[tag.commitHash] = true
+		isOnReleaseBranch, err := verifier.repository.IsAncestor(tag.commitHash, verifier.remoteReleaseBranchHash)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred checking whether commit '%s' is on '%s'; has it been fetched?", tag.commitHash, remoteReleaseBranchName)
+		}
+		if !isOnReleaseBranch {
+			problems = append(problems, fmt.Sprintf("Commit '%s' that tag '%s' points at isn't on '%s'", tag.commitHash, tag.name, remoteReleaseBranchName))
+		}
+	}
+
+	if verifier.armoredKeyRing != "" {
+		for _, tag := range tags {
+			if tag.localRefHash == "" {
+				continue
+			}
+			if _, err := verifier.repository.VerifyTagSignature(tag.name, verifier.armoredKeyRing); err != nil {
+				logrus.Debugf("Tag '%s' failed signature verification:\n%v", tag.name, err)
+				problems = append(problems, fmt.Sprintf("Tag '%s' failed signature verification against the keyring at '%s': %v", tag.name, verifier.keyringFilepath, stacktrace.RootCause(err)))
+			}
+		}
+	}
+
+	changelogFilepath := verifier.kudetConfig.ChangelogFilepath
+	if !hasVersionHeader(verifier.changelogFile, version) {
+		problems = append(problems, fmt.Sprintf("Changelog '%s' has no '%s %s' header", changelogFilepath, sectionHeaderPrefix, version))
+	} else if len(getVersionNotesLines(verifier.changelogFile, version)) == 0 {
+		problems = append(problems, fmt.Sprintf("Changelog '%s' has no notes under its '%s %s' header", changelogFilepath, sectionHeaderPrefix, version))
+	}
+	return problems, nil
+}
+
+func getReleaseTagProblems(tags []releaseTag) []string {
+	problems := []string{}
+	for _, tag := range tags {
+		if tag.localRefHash == "" {
+			problems = append(problems, fmt.Sprintf("Tag '%s' doesn't exist locally", tag.name))
+		}
+		if tag.remoteRefHash == "" {
+			problems = append(problems, fmt.Sprintf("Tag '%s' doesn't exist on remote '%s'", tag.name, originRemoteName))
+		}
+		if tag.localRefHash != "" && tag.remoteRefHash != "" && tag.localRefHash != tag.remoteRefHash {
+			problems = append(problems, fmt.Sprintf("Tag '%s' is at '%s' on remote '%s' but at '%s' locally", tag.name, tag.remoteRefHash, originRemoteName, tag.localRefHash))
+		}
+	}
+	for idx, tag := range tags {
+		for _, otherTag := range tags[idx+1:] {
+			if tag.commitHash != "" && otherTag.commitHash != "" && tag.commitHash != otherTag.commitHash {
+				problems = append(problems, fmt.Sprintf("Tags '%s' and '%s' point at different commits, '%s' and '%s'", tag.name, otherTag.name, tag.commitHash, otherTag.commitHash))
+			}
+		}
+	}
+	return problems
+}
+
+func hasVersionHeader(changelogFile []byte, version string) bool {
+	scanner := newChangelogScanner(changelogFile)
+	for scanner.Scan() {
+		if isHeaderOfVersion(scanner.Text(), version) {
+			return true
+		}
+	}
+	return false
+}
+
+// getVersionNotesLines returns the normalized lines between the version's header and the next version's header
+func getVersionNotesLines(changelogFile []byte, version string) []string {
+	lines := []string{}
+	isInVersionSection := false
+	scanner := newChangelogScanner(changelogFile)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if isHeaderOfVersion(line, version) {
+			isInVersionSection = true
+			continue
+		}
+		if isInVersionSection && versionHeaderRegex.MatchString(line) {
+			break
+		}
+		if isInVersionSection {
+			lines = append(lines, line)
+		}
+	}
+	return normalizeNotesLines(lines)
+}
+
+func isHeaderOfVersion(line string, version string) bool {
+	return versionHeaderRegex.MatchString(line) && strings.TrimSpace(strings.TrimPrefix(line, sectionHeaderPrefix)) == version
+}
@@ -0,0 +1,43 @@
+package releaser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReleaseTagProblems_ConsistentTagsHaveNone(t *testing.T) {
+	tags := []releaseTag{
+		{name: "1.2.3", localRefHash: "aaaa", remoteRefHash: "aaaa", commitHash: "cccc"},
+		{name: "v1.2.3", localRefHash: "bbbb", remoteRefHash: "bbbb", commitHash: "cccc"},
+	}
+	require.Empty(t, getReleaseTagProblems(tags))
+}
+
+func TestGetReleaseTagProblems(t *testing.T) {
+	tags := []releaseTag{
+		{name: "1.2.3", localRefHash: "aaaa", remoteRefHash: "ffff", commitHash: "cccc"},
+		{name: "v1.2.3", localRefHash: "bbbb", commitHash: "dddd"},
+	}
+	require.Equal(t, []string{
+		"Tag '1.2.3' is at 'ffff' on remote 'origin' but at 'aaaa' locally",
+		"Tag 'v1.2.3' doesn't exist on remote 'origin'",
+		"Tags '1.2.3' and 'v1.2.3' point at different commits, 'cccc' and 'dddd'",
+	}, getReleaseTagProblems(tags))
+}
+
+func TestHasVersionHeader(t *testing.T) {
+	changelog := []byte("# TBD\n\n# 1.2.3\n* Fix things\n\n# 1.2.30\n")
+	require.True(t, hasVersionHeader(changelog, "1.2.3"))
+	require.True(t, hasVersionHeader(changelog, "1.2.30"))
+	require.False(t, hasVersionHeader(changelog, "1.2.4"))
+	require.False(t, hasVersionHeader(changelog, "1.2"))
+}
+
+func TestGetVersionNotesLines(t *testing.T) {
+	changelog := []byte("# TBD\n* Unreleased\n\n# 1.2.3\n\n* Fix things  \n\n# 1.2.2\n\n# 1.2.1\n* First\n")
+	require.Equal(t, []string{"* Fix things"}, getVersionNotesLines(changelog, "1.2.3"))
+	require.Empty(t, getVersionNotesLines(changelog, "1.2.2"))
+	require.Equal(t, []string{"* First"}, getVersionNotesLines(changelog, "1.2.1"))
+	require.Empty(t, getVersionNotesLines(changelog, "1.2.4"))
+}
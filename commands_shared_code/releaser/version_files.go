@@ -0,0 +1,101 @@
+package releaser
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/version_file_updater"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"path/filepath"
+	"strings"
+)
+
+// updateVersionFiles bumps the version in the given version files, whose paths are relative to the repo root
+func updateVersionFiles(repoDirpath string, versionFiles []kudet_config.VersionFileConfig, version string) error {
+	for _, versionFile := range versionFiles {
+		versionFilepath := getVersionFilepath(repoDirpath, versionFile)
+		if err := version_file_updater.UpdateVersionInFile(versionFilepath, versionFile.Pattern, version); err != nil {
+			return stacktrace.Propagate(err, "An error occurred updating the version in '%s'", versionFilepath)
+		}
+	}
+	return nil
+}
+
+// verifyVersionFilesHaveVersion checks that each of the version files has the version, reporting every one that doesn't
+// at once
+func verifyVersionFilesHaveVersion(repoDirpath string, versionFiles []kudet_config.VersionFileConfig, version string) error {
+	problems := []string{}
+	for _, versionFile := range versionFiles {
+		versionFilepath := getVersionFilepath(repoDirpath, versionFile)
+		fileVersion, err := version_file_updater.GetVersionInFile(versionFilepath, versionFile.Pattern)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("'%s' has no version on a line matching '%s': %v", versionFile.Filepath, versionFile.Pattern, stacktrace.RootCause(err)))
+			continue
+		}
+		if fileVersion != version {
+			problems = append(problems, fmt.Sprintf("'%s' has version '%s'", versionFile.Filepath, fileVersion))
+		}
+	}
+	if len(problems) > 0 {
+		return stacktrace.NewErrorWithCode(ErrVersionFileOutOfSync.code, "Version files don't have version '%s':\n%s", version, strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// getVersionFilesBumpedByKudet returns the version files that kudet bumps itself, rather than leaving them to the
+// pre-release scripts
+func getVersionFilesBumpedByKudet(versionFiles []kudet_config.VersionFileConfig) []kudet_config.VersionFileConfig {
+	kudetBumpedVersionFiles := []kudet_config.VersionFileConfig{}
+	for _, versionFile := range versionFiles {
+		if !versionFile.BumpedByScripts {
+			kudetBumpedVersionFiles = append(kudetBumpedVersionFiles, versionFile)
+		}
+	}
+	return kudetBumpedVersionFiles
+}
+
+// updateExternalVersionFiles bumps the version files outside the repo once the release is out; by then the release is
+// irreversible, so failures are only logged for the operator to fix by hand
+func updateExternalVersionFiles(repoDirpath string, versionFiles []kudet_config.VersionFileConfig, version string) {
+	_, externalVersionFiles := splitVersionFiles(repoDirpath, versionFiles)
+	if len(externalVersionFiles) == 0 {
+		return
+	}
+	logrus.Infof("Updating version files outside the repo...")
+	for _, versionFile := range externalVersionFiles {
+		versionFilepath := getVersionFilepath(repoDirpath, versionFile)
+		if err := version_file_updater.UpdateVersionInFile(versionFilepath, versionFile.Pattern, version); err != nil {
+			logrus.Errorf("ACTION REQUIRED: An error occurred updating the version in '%s' to '%s'; please update it by hand:\n%v", versionFilepath, version, err)
+			continue
+		}
+		logrus.Infof("Updated the version in '%s'; it's up to you to commit it", versionFilepath)
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// splitVersionFiles separates the version files inside the repo, which are bumped in the release commit, from those
+// outside it, which mustn't point at the release until it's been pushed
+func splitVersionFiles(repoDirpath string, versionFiles []kudet_config.VersionFileConfig) ([]kudet_config.VersionFileConfig, []kudet_config.VersionFileConfig) {
+	repoVersionFiles := []kudet_config.VersionFileConfig{}
+	externalVersionFiles := []kudet_config.VersionFileConfig{}
+	for _, versionFile := range versionFiles {
+		relFilepath, err := filepath.Rel(repoDirpath, getVersionFilepath(repoDirpath, versionFile))
+		if err != nil || relFilepath == ".." || strings.HasPrefix(relFilepath, ".."+string(filepath.Separator)) {
+			externalVersionFiles = append(externalVersionFiles, versionFile)
+			continue
+		}
+		repoVersionFiles = append(repoVersionFiles, versionFile)
+	}
+	return repoVersionFiles, externalVersionFiles
+}
+
+func getVersionFilepath(repoDirpath string, versionFile kudet_config.VersionFileConfig) string {
+	if filepath.IsAbs(versionFile.Filepath) {
+		return filepath.Clean(versionFile.Filepath)
+	}
+	return filepath.Join(repoDirpath, versionFile.Filepath)
+}
@@ -0,0 +1,54 @@
+package releaser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitVersionFiles(t *testing.T) {
+	versionFiles := []kudet_config.VersionFileConfig{
+		{Filepath: "version.go"},
+		{Filepath: "charts/kudet/../kudet/Chart.yaml"},
+		{Filepath: "../homebrew-tap/Formula/kudet.rb"},
+		{Filepath: "/etc/kudet/version"},
+		{Filepath: "..version"},
+	}
+	repoVersionFiles, externalVersionFiles := splitVersionFiles("/src/kudet", versionFiles)
+	require.Equal(t, []kudet_config.VersionFileConfig{versionFiles[0], versionFiles[1], versionFiles[4]}, repoVersionFiles)
+	require.Equal(t, []kudet_config.VersionFileConfig{versionFiles[2], versionFiles[3]}, externalVersionFiles)
+}
+
+func TestUpdateVersionFiles(t *testing.T) {
+	repoDirpath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "version.go"), []byte("package kudet\n\nconst Version = \"0.1.0\"\n"), 0644))
+	versionFiles := []kudet_config.VersionFileConfig{{Filepath: "version.go", Pattern: `const Version = "%s"`}}
+
+	require.NoError(t, updateVersionFiles(repoDirpath, versionFiles, "0.2.0"))
+	versionFileBytes, err := os.ReadFile(filepath.Join(repoDirpath, "version.go"))
+	require.NoError(t, err)
+	require.Equal(t, "package kudet\n\nconst Version = \"0.2.0\"\n", string(versionFileBytes))
+
+	require.Error(t, updateVersionFiles(repoDirpath, []kudet_config.VersionFileConfig{{Filepath: "missing.go", Pattern: `"%s"`}}, "0.2.0"))
+}
+
+func TestVerifyVersionFilesHaveVersion(t *testing.T) {
+	repoDirpath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "version.go"), []byte("package kudet\n\nconst Version = \"0.2.0\"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "package.json"), []byte("{\n  \"version\": \"0.1.0\"\n}\n"), 0644))
+	versionFiles := []kudet_config.VersionFileConfig{
+		{Filepath: "version.go", Pattern: `const Version = "%s"`},
+		{Filepath: "package.json", Pattern: `"version": "%s"`, BumpedByScripts: true},
+	}
+
+	require.NoError(t, verifyVersionFilesHaveVersion(repoDirpath, versionFiles[:1], "0.2.0"))
+	err := verifyVersionFilesHaveVersion(repoDirpath, versionFiles, "0.2.0")
+	require.ErrorContains(t, err, "'package.json' has version '0.1.0'")
+	releaseErr, found := GetReleaseError(err)
+	require.True(t, found)
+	require.Equal(t, ErrVersionFileOutOfSync, releaseErr)
+	require.Equal(t, versionFiles[:1], getVersionFilesBumpedByKudet(versionFiles))
+}
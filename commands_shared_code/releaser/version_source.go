@@ -0,0 +1,152 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	versionSourceContentType    = "application/json"
+	versionSourceRequestTimeout = 30 * time.Second
+	// Versions are short, so anything longer than this isn't a version response
+	maxVersionSourceResponseBytes = 64 * 1024
+
+	allocatedBumpReason = "The version was allocated by the version source"
+)
+
+// versionAllocationRequest is the JSON body POSTed to the version source to allocate the release's version
+type versionAllocationRequest struct {
+	Repository string `json:"repository"`
+	// One of 'major', 'minor' or 'patch', as the changelog, or --bump-major, asks for
+	BumpType string `json:"bumpType"`
+	// Empty before the repo's first release
+	PreviousVersion string `json:"previousVersion,omitempty"`
+	// The version that kudet computed itself, which the service is free to ignore
+	ProposedVersion string `json:"proposedVersion"`
+}
+
+// versionAllocationResponse is the JSON body that the version source responds with
+type versionAllocationResponse struct {
+	Version string `json:"version"`
+}
+
+// allocateReleaseVersion requests the release's version from the version source, which must be a later version than the
+// latest release's; the proposed version is what kudet computed from the changelog
+func allocateReleaseVersion(ctx context.Context, repository vcs.Repository, versionSourceConfig kudet_config.VersionSourceConfig, latestReleaseVersion *semver.Version, proposedReleaseVersion semver.Version) (semver.Version, error) {
+	repositoryName, err := getVersionSourceRepositoryName(repository, versionSourceConfig)
+	if err != nil {
+		return semver.Version{}, stacktrace.Propagate(err, "An error occurred determining the name that the version source knows the repo by")
+	}
+	request := &versionAllocationRequest{
+		Repository:      repositoryName,
+		BumpType:        getBumpType(latestReleaseVersion, &proposedReleaseVersion),
+		ProposedVersion: proposedReleaseVersion.String(),
+	}
+	if latestReleaseVersion.String() != noPreviousVersion {
+		request.PreviousVersion = latestReleaseVersion.String()
+	}
+	token := ""
+	if versionSourceConfig.TokenEnvVar != "" {
+		token = os.Getenv(versionSourceConfig.TokenEnvVar)
+		if token == "" {
+			return semver.Version{}, stacktrace.NewError("The version source needs a token, but '%s' isn't set", versionSourceConfig.TokenEnvVar)
+		}
+	}
+	logrus.Infof("Requesting a %s version for '%s' from the version source at '%s'...", request.BumpType, repositoryName, versionSourceConfig.Url)
+	httpClient := &http.Client{Timeout: versionSourceRequestTimeout}
+	allocatedVersionStr, err := requestVersionAllocation(ctx, httpClient, versionSourceConfig.Url, token, request)
+	if err != nil {
+		return semver.Version{}, stacktrace.Propagate(err, "An error occurred requesting the version from the version source at '%s'", versionSourceConfig.Url)
+	}
+	if !semverRegex.MatchString(allocatedVersionStr) {
+		return semver.Version{}, stacktrace.NewError("The version source allocated '%s', which isn't a semantic version", allocatedVersionStr)
+	}
+	allocatedVersion, err := semver.StrictNewVersion(allocatedVersionStr)
+	if err != nil {
+		return semver.Version{}, stacktrace.Propagate(err, "An error occurred parsing version '%s' allocated by the version source", allocatedVersionStr)
+	}
+	if !allocatedVersion.GreaterThan(latestReleaseVersion) {
+		return semver.Version{}, stacktrace.NewError("The version source allocated version '%s', which isn't later than the latest release's version '%s'", allocatedVersion.String(), latestReleaseVersion.String())
+	}
+	if !allocatedVersion.Equal(&proposedReleaseVersion) {
+		logrus.Infof("The version source allocated version '%s' rather than the changelog's '%s'", allocatedVersion.String(), proposedReleaseVersion.String())
+	}
+	return *allocatedVersion, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getVersionSourceRepositoryName(repository vcs.Repository, versionSourceConfig kudet_config.VersionSourceConfig) (string, error) {
+	if versionSourceConfig.Repository != "" {
+		return versionSourceConfig.Repository, nil
+	}
+	remoteUrl, err := repository.GetRemoteUrl()
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred getting the URL of remote '%s'", originRemoteName)
+	}
+	_, repoPath, err := parseRemoteUrl(remoteUrl)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Can't tell the repo's path on its forge; set '%s.%s' in the kudet config", kudet_config.VersionSourceKey, kudet_config.VersionSourceRepositoryKey)
+	}
+	return repoPath, nil
+}
+
+// getBumpType is which part of the latest release's version the next version bumps
+func getBumpType(latestReleaseVersion *semver.Version, nextReleaseVersion *semver.Version) string {
+	switch {
+	case nextReleaseVersion.Major() != latestReleaseVersion.Major():
+		return majorBumpType
+	case nextReleaseVersion.Minor() != latestReleaseVersion.Minor():
+		return minorBumpType
+	default:
+		return patchBumpType
+	}
+}
+
+func requestVersionAllocation(ctx context.Context, httpClient *http.Client, versionSourceUrl string, token string, request *versionAllocationRequest) (string, error) {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred serializing the version allocation request")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, versionSourceUrl, bytes.NewReader(requestBytes))
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred building the version allocation request")
+	}
+	req.Header.Set("Content-Type", versionSourceContentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred POSTing the version allocation request")
+	}
+	defer resp.Body.Close()
+	responseBytes, err := io.ReadAll(io.LimitReader(resp.Body, maxVersionSourceResponseBytes))
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred reading the version source's response")
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return "", stacktrace.NewError("The version source responded with unexpected status '%s': %s", resp.Status, string(responseBytes))
+	}
+	response := &versionAllocationResponse{}
+	if err := json.Unmarshal(responseBytes, response); err != nil {
+		return "", stacktrace.Propagate(err, "The version source's response isn't a JSON object with a 'version'")
+	}
+	if response.Version == "" {
+		return "", stacktrace.NewError("The version source's response has no 'version'")
+	}
+	return response.Version, nil
+}
@@ -0,0 +1,69 @@
+package releaser
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocateReleaseVersion(t *testing.T) {
+	allocatedVersion := "1.5.0"
+	requests := []versionAllocationRequest{}
+	versionSource := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		request := versionAllocationRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+		require.NoError(t, json.NewEncoder(w).Encode(&versionAllocationResponse{Version: allocatedVersion}))
+	}))
+	defer versionSource.Close()
+
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{"git@github.com:kurtosis-tech/kudet.git"}})
+	require.NoError(t, err)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+
+	t.Setenv("VERSION_SOURCE_TOKEN", "secret")
+	versionSourceConfig := kudet_config.VersionSourceConfig{Url: versionSource.URL, TokenEnvVar: "VERSION_SOURCE_TOKEN"}
+	latestReleaseVersion := semver.MustParse("1.3.2")
+	version, err := allocateReleaseVersion(context.Background(), repository, versionSourceConfig, latestReleaseVersion, *semver.MustParse("1.4.0"))
+	require.NoError(t, err)
+	require.Equal(t, "1.5.0", version.String())
+	require.Equal(t, []versionAllocationRequest{
+		{Repository: "kurtosis-tech/kudet", BumpType: minorBumpType, PreviousVersion: "1.3.2", ProposedVersion: "1.4.0"},
+	}, requests)
+
+	// The version source can't take the repo back to an earlier version
+	allocatedVersion = "1.3.2"
+	_, err = allocateReleaseVersion(context.Background(), repository, versionSourceConfig, latestReleaseVersion, *semver.MustParse("1.3.3"))
+	require.ErrorContains(t, err, "isn't later than")
+	allocatedVersion = "v1.6.0"
+	_, err = allocateReleaseVersion(context.Background(), repository, versionSourceConfig, latestReleaseVersion, *semver.MustParse("1.3.3"))
+	require.ErrorContains(t, err, "isn't a semantic version")
+
+	t.Setenv("VERSION_SOURCE_TOKEN", "wrong")
+	_, err = allocateReleaseVersion(context.Background(), repository, versionSourceConfig, latestReleaseVersion, *semver.MustParse("1.3.3"))
+	require.ErrorContains(t, err, "unexpected status")
+}
+
+func TestGetBumpType(t *testing.T) {
+	latestReleaseVersion := semver.MustParse("1.3.2")
+	require.Equal(t, majorBumpType, getBumpType(latestReleaseVersion, semver.MustParse("2.0.0")))
+	require.Equal(t, minorBumpType, getBumpType(latestReleaseVersion, semver.MustParse("1.4.0")))
+	require.Equal(t, patchBumpType, getBumpType(latestReleaseVersion, semver.MustParse("1.3.3")))
+}
@@ -0,0 +1,294 @@
+package releaser
+
+import (
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/i18n"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The release commit message of a release of only version tracks, since the release commit message template is about
+// the main version line
+const versionTracksReleaseCommitMessageFormat = "Finalize changes for release '%s'"
+
+// versionTrackRelease is the next release of one of the version tracks
+type versionTrackRelease struct {
+	track kudet_config.VersionTrackConfig
+
+	// What comes before and after the version in the track's tags
+	tagPrefix string
+	tagSuffix string
+
+	previousVersion string
+
+	version string
+}
+
+// getTag is the tag that the track's release is made with, e.g. 'cli/v1.2.3'
+func (trackRelease *versionTrackRelease) getTag() string {
+	return trackRelease.tagPrefix + trackRelease.version + trackRelease.tagSuffix
+}
+
+// getVersionTracksToRelease picks the version tracks with the given names, in the order they're configured in, and
+// whether the main version line is one of them; without names, only the main version line is released
+func getVersionTracksToRelease(trackConfigs []kudet_config.VersionTrackConfig, trackNames []string) (bool, []kudet_config.VersionTrackConfig, error) {
+	if len(trackNames) == 0 {
+		return true, nil, nil
+	}
+	isMainTrackReleased := false
+	pickedTrackNames := map[string]bool{}
+	for _, trackName := range trackNames {
+		if trackName == kudet_config.MainVersionTrackName {
+			isMainTrackReleased = true
+			continue
+		}
+		isConfigured := false
+		for _, trackConfig := range trackConfigs {
+			if trackConfig.Name == trackName {
+				isConfigured = true
+				break
+			}
+		}
+		if !isConfigured {
+			return false, nil, stacktrace.NewError("Version track '%s' isn't '%s' or one of the tracks under '%s' in the kudet config", trackName, kudet_config.MainVersionTrackName, kudet_config.VersionTracksKey)
+		}
+		pickedTrackNames[trackName] = true
+	}
+	pickedTrackConfigs := []kudet_config.VersionTrackConfig{}
+	for _, trackConfig := range trackConfigs {
+		if pickedTrackNames[trackConfig.Name] {
+			pickedTrackConfigs = append(pickedTrackConfigs, trackConfig)
+		}
+	}
+	return isMainTrackReleased, pickedTrackConfigs, nil
+}
+
+// prepareVersionTrackReleases validates the changelog of each track and bumps the track's latest version, out of the
+// tags with its prefix, by what its changelog lists; the changes listed in one track's changelog have no bearing on the
+// bumps of the others, or of the main version line
+func prepareVersionTrackReleases(repository vcs.Repository, repoDirpath string, trackConfigs []kudet_config.VersionTrackConfig, rules *bumpRules, tagParsingConfig kudet_config.TagParsingConfig, tbdSkeletonLines []string) ([]*versionTrackRelease, error) {
+	tagNames, err := repository.ListTagNames()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while retrieving tags for repository.")
+	}
+	trackReleases := []*versionTrackRelease{}
+	for _, trackConfig := range trackConfigs {
+		changelogFilepath := filepath.Join(repoDirpath, trackConfig.ChangelogFilepath)
+		changelogFile, err := os.ReadFile(changelogFilepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred reading the changelog of version track '%s' at '%s'", trackConfig.Name, changelogFilepath)
+		}
+		changelogFile = pruneChangelogTbdSkeleton(changelogFile, tbdSkeletonLines)
+		changes, err := parseChangeLogFile(changelogFile, rules)
+		if err != nil {
+			return nil, stacktrace.PropagateWithCode(err, ErrChangelogInvalid.code, "The changelog of version track '%s' at '%s' is invalid", trackConfig.Name, changelogFilepath)
+		}
+		tagPrefix, tagSuffix, err := trackConfig.GetTagPrefixAndSuffix()
+		if err != nil {
+			return nil, stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred getting the tags of version track '%s'", trackConfig.Name)
+		}
+		latestVersion, err := getLatestReleaseVersionOfTags(getVersionTrackTagVersions(tagNames, tagPrefix, tagSuffix), tagParsingConfig)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the latest release version of version track '%s'", trackConfig.Name)
+		}
+		nextVersion := getNextReleaseVersion(latestVersion, changes, false)
+		trackRelease := &versionTrackRelease{
+			track:           trackConfig,
+			tagPrefix:       tagPrefix,
+			tagSuffix:       tagSuffix,
+			previousVersion: latestVersion.String(),
+			version:         nextVersion.String(),
+		}
+		_, found, err := repository.GetTagCommitHash(trackRelease.getTag())
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred checking whether tag '%s' already exists", trackRelease.getTag())
+		}
+		if found {
+			return nil, stacktrace.NewErrorWithCode(ErrReleaseTagExists.code, "Tag '%s' for the next release of version track '%s' already exists locally, meaning it was already at least partially released; make sure it exists on '%s' before releasing again", trackRelease.getTag(), trackConfig.Name, originRemoteName)
+		}
+		trackReleases = append(trackReleases, trackRelease)
+	}
+	return trackReleases, nil
+}
+
+// getVersionTrackTags lists the tags of the releases of the version tracks, in the order the tracks are configured in
+func getVersionTrackTags(trackReleases []*versionTrackRelease) []string {
+	tagNames := []string{}
+	for _, trackRelease := range trackReleases {
+		tagNames = append(tagNames, trackRelease.getTag())
+	}
+	return tagNames
+}
+
+// finalizeVersionTrackChangelogs inserts each track's version header beneath the TBD header of its changelog
+func finalizeVersionTrackChangelogs(repoDirpath string, trackReleases []*versionTrackRelease, tbdSkeletonLines []string, maxLineLength int) error {
+	for _, trackRelease := range trackReleases {
+		changelogFilepath := filepath.Join(repoDirpath, trackRelease.track.ChangelogFilepath)
+		if err := updateChangelog(changelogFilepath, trackRelease.version, tbdSkeletonLines, maxLineLength); err != nil {
+			return stacktrace.Propagate(err, "An error occurred while updating the changelog of version track '%s' at '%s'", trackRelease.track.Name, changelogFilepath)
+		}
+	}
+	return nil
+}
+
+// createVersionTrackTags tags the release commit with the tags of the version tracks' releases, returning those it
+// created even if it fails, for them to be deleted
+func createVersionTrackTags(repository vcs.Repository, trackReleases []*versionTrackRelease, releaseCommitHash string) ([]string, error) {
+	createdTagNames := []string{}
+	for _, tagName := range getVersionTrackTags(trackReleases) {
+		if err := repository.CreateTag(tagName, releaseCommitHash, tagName); err != nil {
+			return createdTagNames, stacktrace.Propagate(err, "An error occurred while attempting to create git tag '%s'", tagName)
+		}
+		createdTagNames = append(createdTagNames, tagName)
+	}
+	return createdTagNames, nil
+}
+
+func deleteLocalVersionTrackTags(repository vcs.Repository, tagNames []string) {
+	for _, tagName := range tagNames {
+		if err := repository.DeleteTag(tagName); err != nil {
+			logrus.Errorf("ACTION REQUIRED: An error occurred attempting to undo creation of tag '%s'. Please run 'git tag -d %s' to delete the tag manually.", tagName, tagName)
+		}
+	}
+}
+
+// pushVersionTrackTags pushes the tags one at a time, like the release's other tags
+func pushVersionTrackTags(ctx context.Context, repository vcs.Repository, tagNames []string) error {
+	for _, tagName := range tagNames {
+		logrus.Infof("Pushing version track tag '%s' to '%s'...", tagName, originRemoteName)
+		if err := repository.Push(ctx, fmt.Sprintf("%s%s:%s%s", tagsPrefix, tagName, tagsPrefix, tagName)); err != nil {
+			return stacktrace.PropagateWithCode(err, ErrPushRejected.code, "An error occurred pushing tag '%s' to '%s'; push it with 'git push %s %s'", tagName, originRemoteName, originRemoteName, tagName)
+		}
+	}
+	return nil
+}
+
+// releaseVersionTracks releases the version tracks on their own, leaving the main version line as it is: their
+// changelogs are finalized in a release commit that's pushed along with their tags. The pre-release scripts, version
+// files, packages and post-release steps are all the main version line's, so none of them are run.
+func (releaser *Releaser) releaseVersionTracks(ctx context.Context, repository vcs.Repository, kudetConfig *kudet_config.KudetConfig, author *vcs.Signature, trackConfigs []kudet_config.VersionTrackConfig, remoteMainHash string, isPushDryRun bool) error {
+	repoDirpath := releaser.repoDirpath
+	releaseBranchName := kudetConfig.ReleaseBranch
+	remoteMainBranchName := fmt.Sprintf("%v/%v", originRemoteName, releaseBranchName)
+	tbdSkeletonLines, err := readChangelogTbdSkeleton(repoDirpath, kudetConfig.ChangelogTbdSkeletonFilepath)
+	if err != nil {
+		return stacktrace.PropagateWithCode(err, ErrInvalidConfig.code, "An error occurred reading the changelog's TBD skeleton")
+	}
+
+	releaser.startStep("Version")
+	trackReleases, err := prepareVersionTrackReleases(repository, repoDirpath, trackConfigs, newBumpRules(kudetConfig), kudetConfig.TagParsing, tbdSkeletonLines)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred preparing the releases of the version tracks")
+	}
+	for _, trackRelease := range trackReleases {
+		logrus.Infof("Releasing version '%s' of version track '%s', after '%s'", trackRelease.version, trackRelease.track.Name, trackRelease.previousVersion)
+	}
+	releaseTags := strings.Join(getVersionTrackTags(trackReleases), "', '")
+	isConfirmed, err := releaser.confirmer(ctx, i18n.Sprintf(i18n.ConfirmReleaseQuestion, releaseTags))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred confirming the release of '%s'", releaseTags)
+	}
+	if !isConfirmed {
+		logrus.Infof("Release of '%s' was not confirmed; aborting", releaseTags)
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return stacktrace.Propagate(err, "The release was cancelled before any changes were made")
+	}
+	// The version tracks' release notes are in their own changelogs, which the approvers can read on the release branch
+	if err := releaser.waitForApprovalIfNeeded(ctx, repository, kudetConfig, releaseTags, "", isPushDryRun); err != nil {
+		return stacktrace.Propagate(err, "Refusing to release '%s' without approval", releaseTags)
+	}
+
+	shouldResetLocalBranch := true
+	defer func() {
+		if shouldResetLocalBranch {
+			if err := repository.ResetHard(remoteMainHash); err != nil {
+				logrus.Errorf("ACTION REQUIRED: Error occurred attempting to undo local changes made for release '%s'. Please run 'git reset --hard %s' to undo manually.", releaseTags, remoteMainBranchName)
+			}
+		}
+	}()
+
+	releaser.startStep("Changelog")
+	logrus.Infof("Updating the changelogs of the version tracks...")
+	if err := finalizeVersionTrackChangelogs(repoDirpath, trackReleases, tbdSkeletonLines, kudetConfig.ChangelogMaxLineLength); err != nil {
+		return stacktrace.Propagate(err, "An error occurred finalizing the changelogs of the version tracks")
+	}
+
+	releaser.startStep("Commit")
+	commitMsg := fmt.Sprintf(versionTracksReleaseCommitMessageFormat, releaseTags)
+	if skipCiMarker := strings.TrimSpace(kudetConfig.SkipCiMarker); skipCiMarker != "" {
+		commitMsg = fmt.Sprintf("%s\n\n%s", commitMsg, skipCiMarker)
+	}
+	if releaser.shouldSkipCommitHooks {
+		logrus.Warnf("Skipping the repo's git commit hooks for the release commit, as requested")
+	} else {
+		logrus.Infof("Running the repo's git commit hooks...")
+		commitMsg, err = repository.RunCommitHooks(ctx, commitMsg)
+		if err != nil {
+			return stacktrace.PropagateWithCode(err, ErrCommitHookRejected.code, "The repo's git commit hooks rejected the commit for release '%s'", releaseTags)
+		}
+	}
+	logrus.Infof("Committing changes locally...")
+	author.When = time.Now()
+	releaseCommitHash, err := repository.CommitAll(commitMsg, author)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while committing the changes for release '%s'", releaseTags)
+	}
+
+	releaser.startStep("Tag")
+	createdTagNames, err := createVersionTrackTags(repository, trackReleases, releaseCommitHash)
+	shouldDeleteLocalTags := true
+	defer func() {
+		if shouldDeleteLocalTags {
+			deleteLocalVersionTrackTags(repository, createdTagNames)
+		}
+	}()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred tagging the releases of the version tracks")
+	}
+
+	releaser.startStep("Push")
+	if isPushDryRun {
+		logrus.Infof("Dry run: not pushing the release commit or tags '%s' to '%s', as the kudet config's '%s' asks; they're left in the local repo", releaseTags, originRemoteName, kudet_config.DryRunStepsKey)
+		shouldResetLocalBranch = false
+		shouldDeleteLocalTags = false
+		return nil
+	}
+	logrus.Infof("Checking that '%s' hasn't moved since the release started...", remoteMainBranchName)
+	if err := verifyRemoteBranchHasNotMoved(ctx, repository, releaseBranchName, remoteMainHash); err != nil {
+		return stacktrace.PropagateWithCode(err, ErrOutOfSync.code, "Refusing to push the release")
+	}
+	logrus.Infof("Pushing release changes to '%s'...", remoteMainBranchName)
+	releaseBranchRefSpec := fmt.Sprintf("%s%s:%s%s", headRef, releaseBranchName, headRef, releaseBranchName)
+	if err := repository.PushWithLease(ctx, releaseBranchRefSpec, headRef+releaseBranchName, remoteMainHash); err != nil {
+		return stacktrace.PropagateWithCode(err, ErrPushRejected.code, "An error occurred while pushing release changes to '%s'; if it moved since the release started, re-run the release", remoteMainBranchName)
+	}
+	// With the release commit pushed, the tags are all that's left, so they're kept for pushing by hand if need be
+	shouldResetLocalBranch = false
+	shouldDeleteLocalTags = false
+	if err := pushVersionTrackTags(ctx, repository, createdTagNames); err != nil {
+		return stacktrace.Propagate(err, "The release commit of '%s' was pushed, but not all of its tags were", releaseTags)
+	}
+	logrus.Infof("Release success.")
+	return nil
+}
+
+// getVersionTrackTagVersions strips the track's prefix and suffix off the tags that have both, leaving the versions to be
+// parsed like the main version line's tags
+func getVersionTrackTagVersions(tagNames []string, tagPrefix string, tagSuffix string) []string {
+	versionStrs := []string{}
+	for _, tagName := range tagNames {
+		if len(tagName) > len(tagPrefix)+len(tagSuffix) && strings.HasPrefix(tagName, tagPrefix) && strings.HasSuffix(tagName, tagSuffix) {
+			versionStrs = append(versionStrs, strings.TrimSuffix(strings.TrimPrefix(tagName, tagPrefix), tagSuffix))
+		}
+	}
+	return versionStrs
+}
@@ -0,0 +1,129 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/kudet_config"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/vcs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVersionTracksToRelease(t *testing.T) {
+	cliTrack := kudet_config.VersionTrackConfig{Name: "cli", TagPrefix: "cli/v", ChangelogFilepath: "cli/changelog.md"}
+	sdkTrack := kudet_config.VersionTrackConfig{Name: "sdk", TagPrefix: "sdk/v", ChangelogFilepath: "sdk/changelog.md"}
+	trackConfigs := []kudet_config.VersionTrackConfig{cliTrack, sdkTrack}
+
+	isMainTrackReleased, tracks, err := getVersionTracksToRelease(trackConfigs, nil)
+	require.NoError(t, err)
+	require.True(t, isMainTrackReleased)
+	require.Empty(t, tracks)
+
+	isMainTrackReleased, tracks, err = getVersionTracksToRelease(trackConfigs, []string{"sdk", "cli", "sdk"})
+	require.NoError(t, err)
+	require.False(t, isMainTrackReleased)
+	require.Equal(t, []kudet_config.VersionTrackConfig{cliTrack, sdkTrack}, tracks)
+
+	isMainTrackReleased, tracks, err = getVersionTracksToRelease(trackConfigs, []string{kudet_config.MainVersionTrackName, "sdk"})
+	require.NoError(t, err)
+	require.True(t, isMainTrackReleased)
+	require.Equal(t, []kudet_config.VersionTrackConfig{sdkTrack}, tracks)
+
+	_, _, err = getVersionTracksToRelease(trackConfigs, []string{"docs"})
+	require.ErrorContains(t, err, "Version track 'docs'")
+}
+
+func TestGetVersionTrackTagVersions(t *testing.T) {
+	tagNames := []string{"1.0.0", "v1.0.0", "cli/v1.1.0", "cli/v1.2.0", "releases/1.3.0/cli", "cli@1.4.0", "cli/v", "releases//cli"}
+	require.Equal(t, []string{"1.1.0", "1.2.0"}, getVersionTrackTagVersions(tagNames, "cli/v", ""))
+	require.Equal(t, []string{"1.3.0"}, getVersionTrackTagVersions(tagNames, "releases/", "/cli"))
+
+	scopedTrack := kudet_config.VersionTrackConfig{Name: "cli", TagTemplate: "{{.Scope}}@{{.Version}}", ChangelogFilepath: "cli/changelog.md"}
+	tagPrefix, tagSuffix, err := scopedTrack.GetTagPrefixAndSuffix()
+	require.NoError(t, err)
+	latestVersion, err := getLatestReleaseVersionOfTags(getVersionTrackTagVersions(tagNames, tagPrefix, tagSuffix), kudet_config.TagParsingConfig{})
+	require.NoError(t, err)
+	require.Equal(t, "1.4.0", latestVersion.String())
+}
+
+func TestReleaseVersionTracks(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	originDirpath := t.TempDir()
+	_, err = git.PlainInit(originDirpath, true)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: vcs.OriginRemoteName, URLs: []string{originDirpath}})
+	require.NoError(t, err)
+	xdgConfigDirpath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(xdgConfigDirpath, "git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(xdgConfigDirpath, "git", "config"), []byte("[user]\n\tname = Kudet\n\temail = kudet@example.com\n"), 0644))
+	t.Setenv("XDG_CONFIG_HOME", xdgConfigDirpath)
+	repository, err := vcs.OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	writeFile := func(relFilepath string, contents string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repoDirpath, relFilepath)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, relFilepath), []byte(contents), 0644))
+	}
+	readFile := func(relFilepath string) string {
+		contents, err := os.ReadFile(filepath.Join(repoDirpath, relFilepath))
+		require.NoError(t, err)
+		return string(contents)
+	}
+	mainChangelog := "# TBD\n### Fixes\n* Fixed the module\n\n# 0.1.0\n* Initial release\n"
+	writeFile("changelog.md", mainChangelog)
+	writeFile("cli/changelog.md", "# TBD\n### Breaking Changes\n* Renamed a flag\n\n# 1.0.0\n* Initial release\n")
+	writeFile(".pre-release-scripts.txt", "")
+	initialCommitHash, err := repository.CommitAll("Initial commit", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.0", initialCommitHash, "0.1.0"))
+	require.NoError(t, repository.CreateTag("cli/v1.0.0", initialCommitHash, "cli/v1.0.0"))
+	branchName, _, err := repository.GetCheckedOutBranchName()
+	require.NoError(t, err)
+	require.NoError(t, gitRepository.Push(&git.PushOptions{RemoteName: vcs.OriginRemoteName, RefSpecs: []config.RefSpec{config.RefSpec("refs/heads/" + branchName + ":refs/heads/" + branchName), "refs/tags/*:refs/tags/*"}}))
+
+	kudetConfig := kudet_config.NewDefaultKudetConfig()
+	kudetConfig.ReleaseBranch = branchName
+	kudetConfig.ChangelogFilepath = "changelog.md"
+	kudetConfig.VersionTracks = []kudet_config.VersionTrackConfig{{Name: "cli", TagPrefix: "cli/v", ChangelogFilepath: "cli/changelog.md"}}
+	confirmedQuestions := []string{}
+	confirmer := func(ctx context.Context, question string) (bool, error) {
+		confirmedQuestions = append(confirmedQuestions, question)
+		return true, nil
+	}
+
+	// Only the CLI is released, with its own bump, leaving the main version line's changelog and tags alone
+	cliReleaser := NewReleaser(repoDirpath, "token", WithKudetConfig(kudetConfig), WithConfirmer(confirmer), WithVersionTracks([]string{"cli"}), WithCommitHooksSkipped(true))
+	require.NoError(t, cliReleaser.Release(context.Background()))
+	require.Equal(t, []string{"Release new version 'cli/v1.1.0'?"}, confirmedQuestions)
+	require.Equal(t, mainChangelog, readFile("changelog.md"))
+	require.Contains(t, readFile("cli/changelog.md"), "# 1.1.0\n### Breaking Changes\n* Renamed a flag\n")
+	_, found, err := repository.GetTagCommitHash("0.1.1")
+	require.NoError(t, err)
+	require.False(t, found)
+	originRepository, err := git.PlainOpen(originDirpath)
+	require.NoError(t, err)
+	_, err = originRepository.Tag("cli/v1.1.0")
+	require.NoError(t, err)
+
+	// Both are released together, each bumped by its own changelog
+	writeFile("cli/changelog.md", "# TBD\n### Fixes\n* Fixed the CLI\n\n"+readFile("cli/changelog.md")[len("# TBD\n"):])
+	_, err = repository.CommitAll("Fix the CLI", &vcs.Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, gitRepository.Push(&git.PushOptions{RemoteName: vcs.OriginRemoteName, RefSpecs: []config.RefSpec{config.RefSpec("refs/heads/" + branchName + ":refs/heads/" + branchName)}}))
+	confirmedQuestions = []string{}
+	bothReleaser := NewReleaser(repoDirpath, "token", WithKudetConfig(kudetConfig), WithConfirmer(confirmer), WithVersionTracks([]string{kudet_config.MainVersionTrackName, "cli"}), WithCommitHooksSkipped(true))
+	require.NoError(t, bothReleaser.Release(context.Background()))
+	require.Equal(t, []string{"Release new version '0.1.1', 'cli/v1.1.1'?"}, confirmedQuestions)
+	require.Contains(t, readFile("changelog.md"), "# 0.1.1\n")
+	require.Contains(t, readFile("cli/changelog.md"), "# 1.1.1\n")
+	for _, tagName := range []string{"0.1.1", "v0.1.1", "cli/v1.1.1"} {
+		_, err = originRepository.Tag(tagName)
+		require.NoError(t, err, tagName)
+	}
+}
@@ -0,0 +1,95 @@
+package releaser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	wasmtimeBinaryName = "wasmtime"
+
+	wasmModuleExtension = ".wasm"
+)
+
+// WASM pre-release scripts get the repo root, which they're run from, as their only directory, and nothing from the
+// environment; the version is still their only argument
+var wasmScriptInterpreter = []string{wasmtimeBinaryName, "run", "--dir", "."}
+
+// wasmGateInput is what WASM gates read from stdin: the release plan that the release policy judges, plus the facts
+// about the repo that a sandboxed module has no other way to get at
+type wasmGateInput struct {
+	Plan *releasePlan      `json:"plan"`
+	Repo wasmGateRepoFacts `json:"repo"`
+}
+
+type wasmGateRepoFacts struct {
+	RemoteUrl      string `json:"remoteUrl"`
+	HeadCommitHash string `json:"headCommitHash"`
+	// The changelog's lines under the TBD header
+	ReleaseNotes []string `json:"releaseNotes"`
+}
+
+// wasmGateOutput is what WASM gates write to stdout; no output at all refuses nothing
+type wasmGateOutput struct {
+	Deny []string `json:"deny"`
+}
+
+// runWasmGates runs each WASM gate with the 'wasmtime' CLI, without access to the filesystem, network or environment,
+// and returns the reasons they give to refuse the release, each prefixed with the gate that gave it
+func runWasmGates(ctx context.Context, repoDirpath string, wasmGatePaths []string, input *wasmGateInput) ([]string, error) {
+	inputJson, err := json.Marshal(input)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred serializing the input of the WASM gates")
+	}
+	denials := []string{}
+	for _, wasmGatePath := range wasmGatePaths {
+		wasmGateFilepath := wasmGatePath
+		if !filepath.IsAbs(wasmGateFilepath) {
+			wasmGateFilepath = filepath.Join(repoDirpath, wasmGateFilepath)
+		}
+		wasmtimeCmd := exec.CommandContext(ctx, wasmtimeBinaryName, "run", wasmGateFilepath)
+		wasmtimeCmd.Dir = repoDirpath
+		wasmtimeCmd.Stdin = bytes.NewReader(inputJson)
+		stderr := &bytes.Buffer{}
+		wasmtimeCmd.Stderr = stderr
+		logrus.Debugf("Running WASM gate '%s' with '%s'", wasmGatePath, wasmtimeCmd.String())
+		output, err := wasmtimeCmd.Output()
+		if err != nil {
+			if _, ok := err.(*exec.Error); ok {
+				return nil, stacktrace.Propagate(err, "The '%s' CLI, which runs the WASM gates, couldn't be run; install it from https://wasmtime.dev", wasmtimeBinaryName)
+			}
+			return nil, stacktrace.Propagate(err, "WASM gate '%s' failed with error output:\n%s", wasmGatePath, stderr.String())
+		}
+		gateDenials, err := parseWasmGateOutput(output)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred parsing the output of WASM gate '%s'", wasmGatePath)
+		}
+		for _, denial := range gateDenials {
+			denials = append(denials, fmt.Sprintf("%s: %s", wasmGatePath, denial))
+		}
+	}
+	return denials, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func parseWasmGateOutput(output []byte) ([]string, error) {
+	if strings.TrimSpace(string(output)) == "" {
+		return nil, nil
+	}
+	gateOutput := &wasmGateOutput{}
+	if err := json.Unmarshal(output, gateOutput); err != nil {
+		return nil, stacktrace.Propagate(err, "WASM gates must write nothing, or a JSON object with a 'deny' list of reasons to refuse the release")
+	}
+	return gateOutput.Deny, nil
+}
@@ -0,0 +1,47 @@
+package releaser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWasmGateOutput(t *testing.T) {
+	denials, err := parseWasmGateOutput([]byte(`{"deny":["No releases during the freeze"]}`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"No releases during the freeze"}, denials)
+
+	denials, err = parseWasmGateOutput([]byte("\n"))
+	require.NoError(t, err)
+	require.Empty(t, denials)
+
+	_, err = parseWasmGateOutput([]byte("denied"))
+	require.ErrorContains(t, err, "'deny' list")
+}
+
+func TestRunWasmGates(t *testing.T) {
+	// Stands in for the wasmtime CLI, refusing major releases that the module it was given runs on
+	binDirpath := t.TempDir()
+	fakeWasmtime := `#!/bin/sh
+IFS= read -r input
+case "$2:$input" in
+  *freeze.wasm:*'"bumpType":"major"'*) echo '{"deny":["Major releases are frozen"]}' ;;
+  *) ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(binDirpath, wasmtimeBinaryName), []byte(fakeWasmtime), 0755))
+	t.Setenv("PATH", binDirpath)
+	repoDirpath := t.TempDir()
+	wasmGatePaths := []string{"plugins/freeze.wasm", "plugins/other.wasm"}
+
+	denials, err := runWasmGates(context.Background(), repoDirpath, wasmGatePaths, &wasmGateInput{Plan: &releasePlan{Version: "2.0.0", BumpType: majorBumpType}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"plugins/freeze.wasm: Major releases are frozen"}, denials)
+
+	denials, err = runWasmGates(context.Background(), repoDirpath, wasmGatePaths, &wasmGateInput{Plan: &releasePlan{Version: "1.2.4", BumpType: patchBumpType}})
+	require.NoError(t, err)
+	require.Empty(t, denials)
+}
@@ -0,0 +1,328 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/kurtosis-tech/stacktrace"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// OTLP over HTTP, with JSON rather than protobuf payloads, which every OpenTelemetry collector accepts
+	otlpContentType = "application/json"
+	otlpTracesPath  = "/v1/traces"
+	otlpMetricsPath = "/v1/metrics"
+	// Exporting happens after the run has finished, so it mustn't hold the operator up for long
+	exportTimeout = 5 * time.Second
+
+	serviceName          = "kudet"
+	instrumentationScope = "github.com/kurtosis-tech/kudet"
+
+	traceIdNumBytes = 16
+	spanIdNumBytes  = 8
+
+	internalSpanKind = 1
+	okStatusCode     = 1
+	errorStatusCode  = 2
+	// Each run is exported once, as a delta of one run
+	deltaAggregationTemporality = 1
+
+	ServiceNameAttribute    = "service.name"
+	ServiceVersionAttribute = "service.version"
+	OutcomeAttribute        = "kudet.outcome"
+	FailedStepAttribute     = "kudet.failed_step"
+	StepAttribute           = "kudet.step"
+
+	SucceededOutcome = "succeeded"
+	FailedOutcome    = "failed"
+
+	runsMetricName         = "kudet.runs"
+	runDurationMetricName  = "kudet.run.duration"
+	stepDurationMetricName = "kudet.step.duration"
+	secondsUnit            = "s"
+	runsUnit               = "{run}"
+)
+
+// Recorder records a run as a trace: a span for the whole run, with a span for each of its steps beneath it. A nil
+// Recorder records nothing, so callers don't need to check for one.
+type Recorder struct {
+	now func() time.Time
+
+	traceId string
+
+	run *span
+
+	steps []*span
+}
+
+type span struct {
+	id        string
+	name      string
+	startTime time.Time
+	endTime   time.Time
+	isFailed  bool
+
+	// Only the run's span has attributes of its own, e.g. the version it released
+	attributes map[string]string
+}
+
+// NewRecorder starts recording the run with the given name, e.g. 'kudet release'
+func NewRecorder(runName string) *Recorder {
+	recorder := &Recorder{
+		now:     time.Now,
+		traceId: newId(traceIdNumBytes),
+		run:     nil,
+		steps:   []*span{},
+	}
+	recorder.run = &span{
+		id:         newId(spanIdNumBytes),
+		name:       runName,
+		startTime:  recorder.now(),
+		endTime:    time.Time{},
+		isFailed:   false,
+		attributes: map[string]string{},
+	}
+	return recorder
+}
+
+// StartSpan ends the running step's span, if any, as succeeded and starts one for the named step
+func (recorder *Recorder) StartSpan(name string) {
+	if recorder == nil {
+		return
+	}
+	recorder.endRunningStep(false)
+	recorder.steps = append(recorder.steps, &span{
+		id:         newId(spanIdNumBytes),
+		name:       name,
+		startTime:  recorder.now(),
+		endTime:    time.Time{},
+		isFailed:   false,
+		attributes: nil,
+	})
+}
+
+// SetAttribute records something about the run as a whole on its span
+func (recorder *Recorder) SetAttribute(key string, value string) {
+	if recorder == nil {
+		return
+	}
+	recorder.run.attributes[key] = value
+}
+
+// Finish ends the running step's span and the run's span, as failed if the run returned an error
+func (recorder *Recorder) Finish(err error) {
+	if recorder == nil || !recorder.run.endTime.IsZero() {
+		return
+	}
+	recorder.endRunningStep(err != nil)
+	recorder.run.endTime = recorder.now()
+	recorder.run.isFailed = err != nil
+	recorder.run.attributes[OutcomeAttribute] = SucceededOutcome
+	if err != nil {
+		recorder.run.attributes[OutcomeAttribute] = FailedOutcome
+		if len(recorder.steps) > 0 {
+			recorder.run.attributes[FailedStepAttribute] = recorder.steps[len(recorder.steps)-1].name
+		}
+	}
+}
+
+// Export sends the finished run's trace, along with metrics of its outcome and of how long it and each of its steps
+// took, to the OTLP/HTTP endpoint (e.g. 'http://localhost:4318') with the given headers; the resource attributes
+// describe what ran, e.g. the repo, and go on both
+func Export(ctx context.Context, recorder *Recorder, endpoint string, headers map[string]string, resourceAttributes map[string]string) error {
+	if recorder == nil || recorder.run.endTime.IsZero() {
+		return stacktrace.NewError("Only finished runs can be exported")
+	}
+	resource := otlpResource{Attributes: newOtlpAttributes(resourceAttributes)}
+	scope := otlpScope{Name: instrumentationScope}
+	traces := otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource:   resource,
+		ScopeSpans: []otlpScopeSpans{{Scope: scope, Spans: recorder.getOtlpSpans()}},
+	}}}
+	if err := postOtlp(ctx, endpoint+otlpTracesPath, headers, traces); err != nil {
+		return stacktrace.Propagate(err, "An error occurred exporting the trace of '%s'", recorder.run.name)
+	}
+	metrics := otlpMetrics{ResourceMetrics: []otlpResourceMetrics{{
+		Resource:     resource,
+		ScopeMetrics: []otlpScopeMetrics{{Scope: scope, Metrics: recorder.getOtlpMetrics()}},
+	}}}
+	if err := postOtlp(ctx, endpoint+otlpMetricsPath, headers, metrics); err != nil {
+		return stacktrace.Propagate(err, "An error occurred exporting the metrics of '%s'", recorder.run.name)
+	}
+	return nil
+}
+
+// ParseHeaders parses headers in the 'key1=value1,key2=value2' format of OpenTelemetry's OTEL_EXPORTER_OTLP_HEADERS
+func ParseHeaders(headersStr string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, headerStr := range strings.Split(headersStr, ",") {
+		if strings.TrimSpace(headerStr) == "" {
+			continue
+		}
+		keyAndValue := strings.SplitN(headerStr, "=", 2)
+		if len(keyAndValue) != 2 || strings.TrimSpace(keyAndValue[0]) == "" {
+			return nil, stacktrace.NewError("Header '%s' isn't of the form 'key=value'", keyAndValue[0])
+		}
+		headers[strings.TrimSpace(keyAndValue[0])] = strings.TrimSpace(keyAndValue[1])
+	}
+	return headers, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (recorder *Recorder) endRunningStep(isFailed bool) {
+	if len(recorder.steps) == 0 {
+		return
+	}
+	runningStep := recorder.steps[len(recorder.steps)-1]
+	if !runningStep.endTime.IsZero() {
+		return
+	}
+	runningStep.endTime = recorder.now()
+	runningStep.isFailed = isFailed
+}
+
+func (recorder *Recorder) getOtlpSpans() []otlpSpan {
+	spans := []otlpSpan{newOtlpSpan(recorder.traceId, "", recorder.run)}
+	for _, step := range recorder.steps {
+		spans = append(spans, newOtlpSpan(recorder.traceId, recorder.run.id, step))
+	}
+	return spans
+}
+
+// getOtlpMetrics describes the run as a count of runs by outcome and failed step, from which failure rates and the
+// steps that fail most follow, and as gauges of how long the run and its steps took
+func (recorder *Recorder) getOtlpMetrics() []otlpMetric {
+	endTimeUnixNano := formatUnixNano(recorder.run.endTime)
+	outcomeAttributes := map[string]string{OutcomeAttribute: recorder.run.attributes[OutcomeAttribute]}
+	if failedStep, found := recorder.run.attributes[FailedStepAttribute]; found {
+		outcomeAttributes[FailedStepAttribute] = failedStep
+	}
+	runsMetric := otlpMetric{
+		Name: runsMetricName,
+		Unit: runsUnit,
+		Sum: &otlpSum{
+			DataPoints: []otlpDataPoint{{
+				Attributes:        newOtlpAttributes(outcomeAttributes),
+				StartTimeUnixNano: formatUnixNano(recorder.run.startTime),
+				TimeUnixNano:      endTimeUnixNano,
+				AsInt:             "1",
+			}},
+			AggregationTemporality: deltaAggregationTemporality,
+			IsMonotonic:            true,
+		},
+	}
+	runDurationMetric := otlpMetric{
+		Name: runDurationMetricName,
+		Unit: secondsUnit,
+		Gauge: &otlpGauge{DataPoints: []otlpDataPoint{{
+			Attributes:   newOtlpAttributes(outcomeAttributes),
+			TimeUnixNano: endTimeUnixNano,
+			AsDouble:     newFloat(recorder.run.endTime.Sub(recorder.run.startTime).Seconds()),
+		}}},
+	}
+	stepDataPoints := []otlpDataPoint{}
+	for _, step := range recorder.steps {
+		stepOutcome := SucceededOutcome
+		if step.isFailed {
+			stepOutcome = FailedOutcome
+		}
+		stepDataPoints = append(stepDataPoints, otlpDataPoint{
+			Attributes:   newOtlpAttributes(map[string]string{StepAttribute: step.name, OutcomeAttribute: stepOutcome}),
+			TimeUnixNano: formatUnixNano(step.endTime),
+			AsDouble:     newFloat(step.endTime.Sub(step.startTime).Seconds()),
+		})
+	}
+	stepDurationMetric := otlpMetric{
+		Name:  stepDurationMetricName,
+		Unit:  secondsUnit,
+		Gauge: &otlpGauge{DataPoints: stepDataPoints},
+	}
+	return []otlpMetric{runsMetric, runDurationMetric, stepDurationMetric}
+}
+
+func newOtlpSpan(traceId string, parentSpanId string, recordedSpan *span) otlpSpan {
+	status := otlpStatus{Code: okStatusCode}
+	if recordedSpan.isFailed {
+		status.Code = errorStatusCode
+	}
+	return otlpSpan{
+		TraceId:           traceId,
+		SpanId:            recordedSpan.id,
+		ParentSpanId:      parentSpanId,
+		Name:              recordedSpan.name,
+		Kind:              internalSpanKind,
+		StartTimeUnixNano: formatUnixNano(recordedSpan.startTime),
+		EndTimeUnixNano:   formatUnixNano(recordedSpan.endTime),
+		Attributes:        newOtlpAttributes(recordedSpan.attributes),
+		Status:            status,
+	}
+}
+
+// newOtlpAttributes sorts the attributes by key, so that payloads are the same from one export to the next
+func newOtlpAttributes(attributes map[string]string) []otlpAttribute {
+	keys := []string{}
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	otlpAttributes := []otlpAttribute{}
+	for _, key := range keys {
+		otlpAttributes = append(otlpAttributes, otlpAttribute{Key: key, Value: otlpAnyValue{StringValue: attributes[key]}})
+	}
+	return otlpAttributes
+}
+
+func postOtlp(ctx context.Context, url string, headers map[string]string, payload interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the OTLP payload")
+	}
+	ctxWithTimeout, cancelFunc := context.WithTimeout(ctx, exportTimeout)
+	defer cancelFunc()
+	request, err := http.NewRequestWithContext(ctxWithTimeout, http.MethodPost, url, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred building the OTLP request to '%s'", url)
+	}
+	request.Header.Set("Content-Type", otlpContentType)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred POSTing to '%s'", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return stacktrace.NewError("The OTLP endpoint '%s' responded with unexpected status '%s'", url, resp.Status)
+	}
+	return nil
+}
+
+// OTLP's JSON encoding has 64-bit integers as strings
+func formatUnixNano(timestamp time.Time) string {
+	return strconv.FormatInt(timestamp.UnixNano(), 10)
+}
+
+func newFloat(value float64) *float64 {
+	return &value
+}
+
+func newId(numBytes int) string {
+	idBytes := make([]byte, numBytes)
+	// The IDs only need to be unique, and an all-zero ID is invalid in OTLP, so failing to read randomness isn't fatal
+	if _, err := rand.Read(idBytes); err != nil {
+		idBytes[0] = 1
+	}
+	return hex.EncodeToString(idBytes)
+}
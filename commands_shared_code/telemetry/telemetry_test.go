@@ -0,0 +1,102 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	payloadsByPath := map[string][]byte{}
+	authHeaders := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		payload, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		payloadsByPath[request.URL.Path] = payload
+		authHeaders = append(authHeaders, request.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder("kudet release")
+	startTime := recorder.run.startTime
+	recorder.now = func() time.Time { return startTime.Add(2 * time.Second) }
+	recorder.StartSpan("Checkout")
+	recorder.now = func() time.Time { return startTime.Add(5 * time.Second) }
+	recorder.StartSpan("Push")
+	recorder.SetAttribute("kudet.version", "0.2.0")
+	recorder.Finish(stacktrace.NewError("The push was rejected"))
+	err := Export(context.Background(), recorder, server.URL, map[string]string{"Authorization": "Bearer abc"}, map[string]string{ServiceNameAttribute: "kudet"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Bearer abc", "Bearer abc"}, authHeaders)
+
+	traces := otlpTraces{}
+	require.NoError(t, json.Unmarshal(payloadsByPath[otlpTracesPath], &traces))
+	spans := traces.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 3)
+	runSpan := spans[0]
+	require.Equal(t, "kudet release", runSpan.Name)
+	require.Empty(t, runSpan.ParentSpanId)
+	require.Equal(t, errorStatusCode, runSpan.Status.Code)
+	require.Contains(t, runSpan.Attributes, otlpAttribute{Key: FailedStepAttribute, Value: otlpAnyValue{StringValue: "Push"}})
+	require.Contains(t, runSpan.Attributes, otlpAttribute{Key: "kudet.version", Value: otlpAnyValue{StringValue: "0.2.0"}})
+	require.Equal(t, "Checkout", spans[1].Name)
+	require.Equal(t, okStatusCode, spans[1].Status.Code)
+	require.Equal(t, "Push", spans[2].Name)
+	require.Equal(t, errorStatusCode, spans[2].Status.Code)
+	for _, stepSpan := range spans[1:] {
+		require.Equal(t, runSpan.TraceId, stepSpan.TraceId)
+		require.Equal(t, runSpan.SpanId, stepSpan.ParentSpanId)
+	}
+	require.Len(t, runSpan.TraceId, 2*traceIdNumBytes)
+	require.Equal(t, []otlpAttribute{{Key: ServiceNameAttribute, Value: otlpAnyValue{StringValue: "kudet"}}}, traces.ResourceSpans[0].Resource.Attributes)
+
+	metrics := otlpMetrics{}
+	require.NoError(t, json.Unmarshal(payloadsByPath[otlpMetricsPath], &metrics))
+	metricsByName := map[string]otlpMetric{}
+	for _, metric := range metrics.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		metricsByName[metric.Name] = metric
+	}
+	runsDataPoint := metricsByName[runsMetricName].Sum.DataPoints[0]
+	require.Equal(t, "1", runsDataPoint.AsInt)
+	require.Equal(t, []otlpAttribute{
+		{Key: FailedStepAttribute, Value: otlpAnyValue{StringValue: "Push"}},
+		{Key: OutcomeAttribute, Value: otlpAnyValue{StringValue: FailedOutcome}},
+	}, runsDataPoint.Attributes)
+	require.Equal(t, 5.0, *metricsByName[runDurationMetricName].Gauge.DataPoints[0].AsDouble)
+	stepDataPoints := metricsByName[stepDurationMetricName].Gauge.DataPoints
+	require.Len(t, stepDataPoints, 2)
+	require.Equal(t, 3.0, *stepDataPoints[0].AsDouble)
+	require.Equal(t, 0.0, *stepDataPoints[1].AsDouble)
+}
+
+func TestExport_RejectsUnfinishedRuns(t *testing.T) {
+	err := Export(context.Background(), NewRecorder("kudet release"), "http://localhost:4318", nil, nil)
+	require.ErrorContains(t, err, "Only finished runs")
+}
+
+func TestRecorder_NilIsNoOp(t *testing.T) {
+	var recorder *Recorder
+	recorder.StartSpan("Checkout")
+	recorder.SetAttribute("kudet.version", "0.2.0")
+	recorder.Finish(nil)
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("Authorization=Bearer abc=, x-team = platform,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"Authorization": "Bearer abc=", "x-team": "platform"}, headers)
+
+	headers, err = ParseHeaders("")
+	require.NoError(t, err)
+	require.Empty(t, headers)
+
+	_, err = ParseHeaders("Authorization")
+	require.ErrorContains(t, err, "'Authorization'")
+}
@@ -0,0 +1,121 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	coreConfigSection   = "core"
+	hooksPathConfigName = "hooksPath"
+	defaultHooksDirname = "hooks"
+
+	preCommitHookName = "pre-commit"
+	commitMsgHookName = "commit-msg"
+
+	// Where git itself leaves the message for the commit-msg hook to check or edit
+	commitEditMsgFilename = "COMMIT_EDITMSG"
+	commitEditMsgFileMode = 0644
+)
+
+// RunCommitHooks stages every change like CommitAll does, then runs the repo's 'pre-commit' and 'commit-msg' hooks the
+// way 'git commit' would, since go-git never runs them. The hooks come from 'core.hooksPath' if it's set, and '.git/hooks'
+// otherwise; hooks that don't exist or aren't executable are skipped, as git does. The message, as the commit-msg hook
+// left it, is returned.
+func (repo *gitRepository) RunCommitHooks(ctx context.Context, message string) (string, error) {
+	worktree, err := repo.repository.Worktree()
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred while trying to retrieve the worktree of the repository.")
+	}
+	if err := stageAll(worktree); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred staging the changes for the commit hooks to check")
+	}
+	worktreeDirpath := worktree.Filesystem.Root()
+	hooksDirpath, err := repo.getHooksDirpath(worktreeDirpath)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred determining the directory of the repo's git hooks")
+	}
+
+	if err := runGitHook(ctx, worktreeDirpath, hooksDirpath, preCommitHookName); err != nil {
+		return "", stacktrace.Propagate(err, "The '%s' hook rejected the commit", preCommitHookName)
+	}
+
+	commitEditMsgFilepath := filepath.Join(repo.metadataDirpath, commitEditMsgFilename)
+	// Like git, the hook gets the message with a trailing newline so that it can append lines (e.g. trailers) to it
+	if err := os.WriteFile(commitEditMsgFilepath, []byte(strings.TrimRight(message, "\n")+"\n"), commitEditMsgFileMode); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred writing the commit message to '%s' for the '%s' hook", commitEditMsgFilepath, commitMsgHookName)
+	}
+	if err := runGitHook(ctx, worktreeDirpath, hooksDirpath, commitMsgHookName, commitEditMsgFilepath); err != nil {
+		return "", stacktrace.Propagate(err, "The '%s' hook rejected the commit message '%s'", commitMsgHookName, message)
+	}
+	hookedMessage, err := os.ReadFile(commitEditMsgFilepath)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred reading back the commit message that the '%s' hook checked from '%s'", commitMsgHookName, commitEditMsgFilepath)
+	}
+	return strings.TrimRight(string(hookedMessage), "\n"), nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// getHooksDirpath resolves 'core.hooksPath' from the repo's config, then the global one, like git does
+func (repo *gitRepository) getHooksDirpath(worktreeDirpath string) (string, error) {
+	localConfig, err := repo.repository.Config()
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred reading the repo's git config")
+	}
+	hooksPath := localConfig.Raw.Section(coreConfigSection).Option(hooksPathConfigName)
+	if hooksPath == "" {
+		globalConfig, err := config.LoadConfig(config.GlobalScope)
+		if err != nil {
+			return "", stacktrace.Propagate(err, "An error occurred reading the global git config")
+		}
+		hooksPath = globalConfig.Raw.Section(coreConfigSection).Option(hooksPathConfigName)
+	}
+	if hooksPath == "" {
+		return filepath.Join(repo.metadataDirpath, defaultHooksDirname), nil
+	}
+	if filepath.IsAbs(hooksPath) {
+		return hooksPath, nil
+	}
+	// Relative hook paths are relative to the root of the worktree, as commit hooks are run from there
+	return filepath.Join(worktreeDirpath, hooksPath), nil
+}
+
+func runGitHook(ctx context.Context, worktreeDirpath string, hooksDirpath string, hookName string, args ...string) error {
+	hookFilepath := filepath.Join(hooksDirpath, hookName)
+	hookFileInfo, err := os.Stat(hookFilepath)
+	if os.IsNotExist(err) {
+		logrus.Debugf("The repo has no '%s' hook at '%s'", hookName, hookFilepath)
+		return nil
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking for a '%s' hook at '%s'", hookName, hookFilepath)
+	}
+	if hookFileInfo.Mode()&0111 == 0 {
+		logrus.Warnf("The '%s' hook at '%s' was ignored because it's not set as executable", hookName, hookFilepath)
+		return nil
+	}
+
+	hookCmd := exec.CommandContext(ctx, hookFilepath, args...)
+	hookCmd.Dir = worktreeDirpath
+	hookOutput := &bytes.Buffer{}
+	hookCmd.Stdout = hookOutput
+	hookCmd.Stderr = hookOutput
+	logrus.Debugf("Running git hook command '%s' in '%s'", hookCmd.String(), hookCmd.Dir)
+	err = hookCmd.Run()
+	logrus.Tracef("Git hook command '%s' output:\n%s", hookCmd.String(), hookOutput.String())
+	if err != nil {
+		return stacktrace.Propagate(err, "Git hook command '%s' failed with output:\n%s", hookCmd.String(), hookOutput.String())
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package vcs
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+// ReadGitFileAtRevision reads a file of the git repo at the URL as of a revision, which is a tag, a branch or a full
+// commit hash, returning its contents and the commit that the revision resolved to. The repo is cloned in memory, so
+// it's meant for small repos such as those holding shared configs; the token is only used if it isn't empty.
+func ReadGitFileAtRevision(ctx context.Context, remoteUrl string, token string, revision string, relFilepath string) ([]byte, string, error) {
+	cloneOpts := &git.CloneOptions{
+		URL:        remoteUrl,
+		NoCheckout: true,
+		Tags:       git.AllTags,
+		Progress:   getTraceProgressWriter(),
+	}
+	if token != "" {
+		cloneOpts.Auth = &http.BasicAuth{
+			Username: gitAuthUsername,
+			Password: token,
+		}
+	}
+	logrus.Debugf("Cloning '%s' to read '%s' as of '%s'", remoteUrl, relFilepath, revision)
+	repository, err := git.CloneContext(ctx, memory.NewStorage(), nil, cloneOpts)
+	if err != nil {
+		return nil, "", stacktrace.Propagate(err, "An error occurred cloning '%s'", remoteUrl)
+	}
+	commitHash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		// Only the default branch is checked out locally; the others are the clone's remote branches
+		commitHash, err = repository.ResolveRevision(plumbing.Revision(OriginRemoteName + "/" + revision))
+		if err != nil {
+			return nil, "", stacktrace.Propagate(err, "Revision '%s' isn't a tag, branch or full commit hash of '%s'", revision, remoteUrl)
+		}
+	}
+	commit, err := repository.CommitObject(*commitHash)
+	if err != nil {
+		return nil, "", stacktrace.Propagate(err, "An error occurred getting commit '%s' of '%s'", commitHash.String(), remoteUrl)
+	}
+	file, err := commit.File(relFilepath)
+	if err == object.ErrFileNotFound {
+		return nil, "", stacktrace.NewError("'%s' has no file '%s' as of '%s'", remoteUrl, relFilepath, revision)
+	}
+	if err != nil {
+		return nil, "", stacktrace.Propagate(err, "An error occurred getting file '%s' of commit '%s' of '%s'", relFilepath, commitHash.String(), remoteUrl)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, "", stacktrace.Propagate(err, "An error occurred reading file '%s' of commit '%s' of '%s'", relFilepath, commitHash.String(), remoteUrl)
+	}
+	return []byte(contents), commitHash.String(), nil
+}
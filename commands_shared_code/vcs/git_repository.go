@@ -0,0 +1,944 @@
+package vcs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	gitDirname = ".git"
+
+	// this is relative to the root of the target repo
+	gitIgnoreRelFilepath      = ".gitignore"
+	gitIgnoreCommentCharacter = "#"
+
+	// The username doesn't matter when authenticating with a token
+	gitAuthUsername = "git"
+
+	allTagsRefSpec = "+refs/tags/*:refs/tags/*"
+	// The name of the in-memory remote that pushes to a URL go through
+	urlRemoteName = "kudet-url"
+	// What 'git fetch --unshallow' asks for, meaning the entire history
+	unshallowDepth = 0x7fffffff
+
+	// What 'git notes add' says in the commits it makes to the notes ref
+	notesCommitMessage     = "Notes added by 'kudet'"
+	noteFanoutPrefixLength = 2
+)
+
+var emptyDomain []string = nil
+
+// gitRepository is a Repository backed by go-git
+type gitRepository struct {
+	metadataDirpath string
+	// Empty if the repo isn't on disk, in which case its tags aren't indexed between runs
+	gitDirpath   string
+	repository   *git.Repository
+	originRemote *git.Remote
+	auth         *http.BasicAuth
+	// Tags are left unsigned if it's nil
+	tagSigningKey *openpgp.Entity
+	// The index of the tags as of the last time they were looked up, if it's been built or read yet
+	tagIndex *gitTagIndex
+}
+
+func openGitRepository(repoDirpath string, token string) (Repository, error) {
+	repository, err := git.PlainOpen(repoDirpath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while attempting to open the existing git repository.")
+	}
+	gitDirpath := filepath.Join(repoDirpath, gitDirname)
+	gitRepo, err := newGitRepository(repository, gitDirpath, token)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred opening the git repository at '%s'", repoDirpath)
+	}
+	gitRepo.gitDirpath = gitDirpath
+	return gitRepo, nil
+}
+
+// OpenGitRepositoryWithStorage opens the git repo whose objects and refs are in the storer, with its worktree on the
+// given filesystem, so that repos can be operated on in memory (e.g. with go-git's memory.NewStorage and go-billy's
+// memfs.New) as well as on disk; kudet keeps its local state for the repo in the metadata directory
+func OpenGitRepositoryWithStorage(storer storage.Storer, worktreeFilesystem billy.Filesystem, metadataDirpath string, token string) (Repository, error) {
+	repository, err := git.Open(storer, worktreeFilesystem)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while attempting to open the git repository in the given storage.")
+	}
+	return newGitRepository(repository, metadataDirpath, token)
+}
+
+func newGitRepository(repository *git.Repository, metadataDirpath string, token string) (*gitRepository, error) {
+	originRemote, err := repository.Remote(OriginRemoteName)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting remote '%v' for repository; is the code pushed?", OriginRemoteName)
+	}
+	return &gitRepository{
+		metadataDirpath: metadataDirpath,
+		repository:      repository,
+		originRemote:    originRemote,
+		auth: &http.BasicAuth{
+			Username: gitAuthUsername,
+			Password: token,
+		},
+	}, nil
+}
+
+func (repo *gitRepository) GetMetadataDirpath() string {
+	return repo.metadataDirpath
+}
+
+func (repo *gitRepository) SetToken(token string) {
+	repo.auth.Password = token
+}
+
+func (repo *gitRepository) GetAuthor() (*Signature, error) {
+	globalRepoConfig, err := repo.repository.ConfigScoped(config.GlobalScope)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while attempting to retrieve the global git config for this repo.")
+	}
+	name := globalRepoConfig.User.Name
+	email := globalRepoConfig.User.Email
+	if name == "" || email == "" {
+		return nil, stacktrace.NewError("The following empty name or email were detected in global git config'name: %s', 'email: %s'. Make sure these are set for annotating release commits.", name, email)
+	}
+	return &Signature{Name: name, Email: email}, nil
+}
+
+func (repo *gitRepository) GetStatus() (bool, string, error) {
+	worktree, err := repo.repository.Worktree()
+	if err != nil {
+		return false, "", stacktrace.Propagate(err, "An error occurred while trying to retrieve the worktree of the repository.")
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return false, "", stacktrace.Propagate(err, "An error occurred while trying to retrieve the status of the worktree of the repository.")
+	}
+	return status.IsClean(), status.String(), nil
+}
+
+func (repo *gitRepository) GetModifiedFilepaths() ([]string, error) {
+	worktree, err := repo.repository.Worktree()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while trying to retrieve the worktree of the repository.")
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while trying to retrieve the status of the worktree of the repository.")
+	}
+	modifiedFilepaths := []string{}
+	for filepath, fileStatus := range status {
+		if fileStatus.Staging == git.Unmodified && fileStatus.Worktree == git.Unmodified {
+			continue
+		}
+		modifiedFilepaths = append(modifiedFilepaths, filepath)
+	}
+	sort.Strings(modifiedFilepaths)
+	return modifiedFilepaths, nil
+}
+
+func (repo *gitRepository) GetRemoteUrl() (string, error) {
+	remoteUrls := repo.originRemote.Config().URLs
+	if len(remoteUrls) == 0 {
+		return "", stacktrace.NewError("Remote '%s' has no URL", OriginRemoteName)
+	}
+	return remoteUrls[0], nil
+}
+
+func (repo *gitRepository) Fetch(ctx context.Context) error {
+	fetchOpts := &git.FetchOptions{RemoteName: OriginRemoteName, Auth: repo.auth, Progress: getTraceProgressWriter()}
+	logrus.Debugf("Fetching '%s' from remote '%s'", repo.originRemote.Config().Fetch, OriginRemoteName)
+	refHashesBeforeFetch := repo.getRefHashesForDebugLog()
+	err := repo.originRemote.FetchContext(ctx, fetchOpts)
+	// Even a failed fetch may have updated some of the tags
+	repo.invalidateTagIndex()
+	if err == git.NoErrAlreadyUpToDate {
+		logrus.Debugf("Remote '%s' had nothing new to fetch", OriginRemoteName)
+		return nil
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred fetching from the remote repository.")
+	}
+	repo.logRefUpdates(refHashesBeforeFetch)
+	return nil
+}
+
+func (repo *gitRepository) IsShallow() (bool, error) {
+	shallowCommitHashes, err := repo.repository.Storer.Shallow()
+	if err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred reading the shallow commits of the repository")
+	}
+	return len(shallowCommitHashes) > 0, nil
+}
+
+func (repo *gitRepository) Unshallow(ctx context.Context) error {
+	refSpecs := append([]config.RefSpec{}, repo.originRemote.Config().Fetch...)
+	refSpecs = append(refSpecs, allTagsRefSpec)
+	fetchOpts := &git.FetchOptions{
+		RemoteName: OriginRemoteName,
+		RefSpecs:   refSpecs,
+		Depth:      unshallowDepth,
+		Tags:       git.AllTags,
+		Auth:       repo.auth,
+		Progress:   getTraceProgressWriter(),
+	}
+	logrus.Debugf("Fetching the full history of '%s' from remote '%s'", refSpecs, OriginRemoteName)
+	err := repo.originRemote.FetchContext(ctx, fetchOpts)
+	repo.invalidateTagIndex()
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return stacktrace.Propagate(err, "An error occurred fetching the full history from the remote repository.")
+	}
+	// Like 'git fetch --unshallow', forget the old history boundaries now that everything behind them is present
+	if err := repo.repository.Storer.SetShallow(nil); err != nil {
+		return stacktrace.Propagate(err, "An error occurred marking the repository as no longer shallow")
+	}
+	return nil
+}
+
+func (repo *gitRepository) ListRemoteRefs(ctx context.Context) (map[string]string, error) {
+	remoteRefs, err := repo.originRemote.ListContext(ctx, &git.ListOptions{Auth: repo.auth})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the refs on remote '%s'", OriginRemoteName)
+	}
+	remoteRefHashes := map[string]string{}
+	for _, remoteRef := range remoteRefs {
+		remoteRefHashes[remoteRef.Name().String()] = remoteRef.Hash().String()
+		logrus.Tracef("Remote '%s' has ref '%s' at '%s'", OriginRemoteName, remoteRef.Name(), remoteRef.Hash())
+	}
+	logrus.Debugf("Listed %d refs on remote '%s'", len(remoteRefHashes), OriginRemoteName)
+	return remoteRefHashes, nil
+}
+
+func (repo *gitRepository) ResolveRevision(revision string) (string, error) {
+	hash, err := repo.repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred parsing revision '%v'", revision)
+	}
+	logrus.Debugf("Resolved revision '%s' to '%s'", revision, hash)
+	return hash.String(), nil
+}
+
+func (repo *gitRepository) GetCheckedOutBranchName() (string, bool, error) {
+	head, err := repo.repository.Head()
+	if err != nil {
+		return "", false, stacktrace.Propagate(err, "An error occurred while attempting to get the ref to HEAD of the local repository.")
+	}
+	if !head.Name().IsBranch() {
+		return "", false, nil
+	}
+	return head.Name().Short(), true, nil
+}
+
+func (repo *gitRepository) CheckoutBranch(branchName string) error {
+	worktree, err := repo.repository.Worktree()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while trying to retrieve the worktree of the repository.")
+	}
+	logrus.Debugf("Checking out branch '%s'", branchName)
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branchName)}); err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking out branch '%s'", branchName)
+	}
+	return nil
+}
+
+func (repo *gitRepository) ResetHard(commitHash string) error {
+	worktree, err := repo.repository.Worktree()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while trying to retrieve the worktree of the repository.")
+	}
+	// git reset --hard <commit>
+	logrus.Debugf("Hard-resetting to commit '%s'", commitHash)
+	if err := worktree.Reset(&git.ResetOptions{Mode: git.HardReset, Commit: plumbing.NewHash(commitHash)}); err != nil {
+		return stacktrace.Propagate(err, "An error occurred hard-resetting to commit '%s'", commitHash)
+	}
+	return nil
+}
+
+func (repo *gitRepository) CommitAll(message string, author *Signature) (string, error) {
+	worktree, err := repo.repository.Worktree()
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred while trying to retrieve the worktree of the repository.")
+	}
+
+	if err := stageAll(worktree); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred staging the changes to commit")
+	}
+	commitHash, err := worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  author.Name,
+			Email: author.Email,
+			When:  author.When,
+		},
+	})
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred while committing the changes")
+	}
+	logrus.Debugf("Committed all changes as '%s'", commitHash)
+	return commitHash.String(), nil
+}
+
+func (repo *gitRepository) GetHeadCommitHash() (string, error) {
+	head, err := repo.repository.Head()
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred while attempting to get the ref to HEAD of the local repository.")
+	}
+	return head.Hash().String(), nil
+}
+
+func (repo *gitRepository) ListTagNames() ([]string, error) {
+	tagIndex, err := repo.getTagIndex()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the index of the repo's tags")
+	}
+	tagNames := []string{}
+	for tagName := range tagIndex.CommitHashesByTagName {
+		tagNames = append(tagNames, tagName)
+	}
+	sort.Strings(tagNames)
+	return tagNames, nil
+}
+
+func (repo *gitRepository) GetTagCommitHash(tagName string) (string, bool, error) {
+	if tagIndex, err := repo.getTagIndex(); err == nil {
+		commitHash, found := tagIndex.CommitHashesByTagName[tagName]
+		if !found {
+			return "", false, nil
+		}
+		if commitHash != "" {
+			return commitHash, true, nil
+		}
+	}
+	commitHash, err := repo.repository.ResolveRevision(plumbing.Revision(TagRefPrefix + tagName))
+	if err == plumbing.ErrReferenceNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, stacktrace.Propagate(err, "An error occurred resolving tag '%s'", tagName)
+	}
+	return commitHash.String(), true, nil
+}
+
+func (repo *gitRepository) CreateTag(tagName string, commitHash string, message string) error {
+	if _, err := repo.repository.CreateTag(tagName, plumbing.NewHash(commitHash), &git.CreateTagOptions{Message: message, SignKey: repo.tagSigningKey}); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating tag '%s' on commit '%s'", tagName, commitHash)
+	}
+	repo.invalidateTagIndex()
+	logrus.Debugf("Created tag '%s' on commit '%s'", tagName, commitHash)
+	return nil
+}
+
+func (repo *gitRepository) SetTagSigningKey(armoredSigningKey string) error {
+	keyRing, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredSigningKey))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the tag signing key")
+	}
+	if len(keyRing) != 1 || keyRing[0].PrivateKey == nil {
+		return stacktrace.NewError("The tag signing key must be a single PGP private key, but %d key(s) were found", len(keyRing))
+	}
+	if keyRing[0].PrivateKey.Encrypted {
+		return stacktrace.NewError("The tag signing key is protected by a passphrase, which isn't supported")
+	}
+	repo.tagSigningKey = keyRing[0]
+	return nil
+}
+
+func (repo *gitRepository) VerifyTagSignature(tagName string, armoredKeyRing string) (string, error) {
+	tagRef, err := repo.repository.Reference(plumbing.ReferenceName(TagRefPrefix+tagName), false)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred getting tag '%s'", tagName)
+	}
+	tag, err := repo.repository.TagObject(tagRef.Hash())
+	if err == plumbing.ErrObjectNotFound {
+		return "", stacktrace.NewError("Tag '%s' is a lightweight tag, which can't be signed", tagName)
+	}
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred reading tag '%s'", tagName)
+	}
+	if tag.PGPSignature == "" {
+		return "", stacktrace.NewError("Tag '%s' isn't signed", tagName)
+	}
+	signingKey, err := tag.Verify(armoredKeyRing)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Tag '%s' isn't signed by any of the trusted keys", tagName)
+	}
+	return signingKey.PrimaryKey.KeyIdString(), nil
+}
+
+func (repo *gitRepository) GetRefHash(refName string) (string, bool, error) {
+	ref, err := repo.repository.Reference(plumbing.ReferenceName(refName), false)
+	if err == plumbing.ErrReferenceNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, stacktrace.Propagate(err, "An error occurred getting ref '%s'", refName)
+	}
+	return ref.Hash().String(), true, nil
+}
+
+func (repo *gitRepository) GetChangedFilepaths(fromCommitHash string, toCommitHash string) ([]string, error) {
+	fromTree, err := repo.getCommitTree(fromCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the files of commit '%s'", fromCommitHash)
+	}
+	toTree, err := repo.getCommitTree(toCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the files of commit '%s'", toCommitHash)
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred diffing commit '%s' against '%s'", fromCommitHash, toCommitHash)
+	}
+	isChanged := map[string]bool{}
+	for _, change := range changes {
+		// Renames change both the old and the new path
+		for _, changedFilepath := range []string{change.From.Name, change.To.Name} {
+			if changedFilepath != "" {
+				isChanged[changedFilepath] = true
+			}
+		}
+	}
+	changedFilepaths := []string{}
+	for changedFilepath := range isChanged {
+		changedFilepaths = append(changedFilepaths, changedFilepath)
+	}
+	sort.Strings(changedFilepaths)
+	logrus.Debugf("Found %d files changed between commits '%s' and '%s'", len(changedFilepaths), fromCommitHash, toCommitHash)
+	return changedFilepaths, nil
+}
+
+func (repo *gitRepository) IsAncestor(ancestorCommitHash string, descendantCommitHash string) (bool, error) {
+	ancestorCommit, err := repo.repository.CommitObject(plumbing.NewHash(ancestorCommitHash))
+	if err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred getting commit '%s'", ancestorCommitHash)
+	}
+	descendantCommit, err := repo.repository.CommitObject(plumbing.NewHash(descendantCommitHash))
+	if err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred getting commit '%s'", descendantCommitHash)
+	}
+	isAncestor, err := ancestorCommit.IsAncestor(descendantCommit)
+	if err != nil {
+		return false, stacktrace.Propagate(err, "An error occurred checking whether commit '%s' is an ancestor of '%s'", ancestorCommitHash, descendantCommitHash)
+	}
+	return isAncestor, nil
+}
+
+func (repo *gitRepository) GetCommitMessages(fromCommitHash string, toCommitHash string) ([]string, error) {
+	commits, err := repo.GetCommits(fromCommitHash, toCommitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the commits between '%s' and '%s'", fromCommitHash, toCommitHash)
+	}
+	messages := []string{}
+	for _, commit := range commits {
+		messages = append(messages, commit.Message)
+	}
+	return messages, nil
+}
+
+func (repo *gitRepository) GetCommits(fromCommitHash string, toCommitHash string) ([]Commit, error) {
+	isInFromHistory := map[plumbing.Hash]bool{}
+	if fromCommitHash != "" {
+		fromCommits, err := repo.repository.Log(&git.LogOptions{From: plumbing.NewHash(fromCommitHash)})
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred listing the history of commit '%s'", fromCommitHash)
+		}
+		err = fromCommits.ForEach(func(commit *object.Commit) error {
+			isInFromHistory[commit.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred walking the history of commit '%s'", fromCommitHash)
+		}
+	}
+	toCommits, err := repo.repository.Log(&git.LogOptions{From: plumbing.NewHash(toCommitHash)})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred listing the history of commit '%s'", toCommitHash)
+	}
+	commits := []Commit{}
+	err = toCommits.ForEach(func(commit *object.Commit) error {
+		if isInFromHistory[commit.Hash] {
+			return nil
+		}
+		parentHashes := []string{}
+		for _, parentHash := range commit.ParentHashes {
+			parentHashes = append(parentHashes, parentHash.String())
+		}
+		commits = append(commits, Commit{
+			Hash:         commit.Hash.String(),
+			Message:      commit.Message,
+			Author:       Signature{Name: commit.Author.Name, Email: commit.Author.Email, When: commit.Author.When},
+			ParentHashes: parentHashes,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred walking the history of commit '%s'", toCommitHash)
+	}
+	return commits, nil
+}
+
+func (repo *gitRepository) GetCommitTime(commitHash string) (time.Time, error) {
+	commit, err := repo.repository.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return time.Time{}, stacktrace.Propagate(err, "An error occurred getting commit '%s'", commitHash)
+	}
+	return commit.Committer.When, nil
+}
+
+func (repo *gitRepository) GetLatestCommitAsOf(commitHash string, asOf time.Time) (string, bool, error) {
+	commits, err := repo.repository.Log(&git.LogOptions{From: plumbing.NewHash(commitHash), Until: &asOf})
+	if err != nil {
+		return "", false, stacktrace.Propagate(err, "An error occurred listing the history of commit '%s'", commitHash)
+	}
+	// The history isn't walked in commit time order, so it's walked in full
+	var latestCommit *object.Commit
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if latestCommit == nil || commit.Committer.When.After(latestCommit.Committer.When) {
+			latestCommit = commit
+		}
+		return nil
+	})
+	if err != nil {
+		return "", false, stacktrace.Propagate(err, "An error occurred walking the history of commit '%s'", commitHash)
+	}
+	if latestCommit == nil {
+		return "", false, nil
+	}
+	return latestCommit.Hash.String(), true, nil
+}
+
+func (repo *gitRepository) ReadFileAtCommit(commitHash string, relFilepath string) ([]byte, bool, error) {
+	tree, err := repo.getCommitTree(commitHash)
+	if err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred getting the files of commit '%s'", commitHash)
+	}
+	file, err := tree.File(relFilepath)
+	if err == object.ErrFileNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred getting file '%s' of commit '%s'", relFilepath, commitHash)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, false, stacktrace.Propagate(err, "An error occurred reading file '%s' of commit '%s'", relFilepath, commitHash)
+	}
+	return []byte(contents), true, nil
+}
+
+func (repo *gitRepository) AddNote(notesRefName string, commitHash string, note string, author *Signature) error {
+	treeEntries := []object.TreeEntry{}
+	parentHashes := []plumbing.Hash{}
+	notesRef, err := repo.repository.Storer.Reference(plumbing.ReferenceName(notesRefName))
+	if err != nil && err != plumbing.ErrReferenceNotFound {
+		return stacktrace.Propagate(err, "An error occurred getting notes ref '%s'", notesRefName)
+	}
+	if err == nil {
+		notesTree, err := repo.getCommitTree(notesRef.Hash().String())
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting the notes of notes ref '%s'", notesRefName)
+		}
+		for _, treeEntry := range notesTree.Entries {
+			// Adding a note to a commit that has one replaces it, like 'git notes add -f'
+			if treeEntry.Name != commitHash {
+				treeEntries = append(treeEntries, treeEntry)
+			}
+		}
+		parentHashes = append(parentHashes, notesRef.Hash())
+	}
+
+	noteBlob := repo.repository.Storer.NewEncodedObject()
+	noteBlob.SetType(plumbing.BlobObject)
+	noteWriter, err := noteBlob.Writer()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening the note's blob for writing")
+	}
+	if _, err := io.WriteString(noteWriter, note); err != nil {
+		noteWriter.Close()
+		return stacktrace.Propagate(err, "An error occurred writing the note's blob")
+	}
+	if err := noteWriter.Close(); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the note's blob")
+	}
+	noteBlobHash, err := repo.repository.Storer.SetEncodedObject(noteBlob)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred storing the note's blob")
+	}
+	treeEntries = append(treeEntries, object.TreeEntry{Name: commitHash, Mode: filemode.Regular, Hash: noteBlobHash})
+	// Git requires tree entries in its own order, where directories sort as if their names ended with a slash
+	sort.Slice(treeEntries, func(i, j int) bool {
+		return getTreeEntrySortKey(treeEntries[i]) < getTreeEntrySortKey(treeEntries[j])
+	})
+	notesTreeHash, err := repo.storeObject(&object.Tree{Entries: treeEntries})
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred storing the tree of notes ref '%s'", notesRefName)
+	}
+
+	signature := object.Signature{Name: author.Name, Email: author.Email, When: author.When}
+	notesCommitHash, err := repo.storeObject(&object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      notesCommitMessage,
+		TreeHash:     notesTreeHash,
+		ParentHashes: parentHashes,
+	})
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred storing the commit of notes ref '%s'", notesRefName)
+	}
+	if err := repo.repository.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName(notesRefName), notesCommitHash)); err != nil {
+		return stacktrace.Propagate(err, "An error occurred pointing notes ref '%s' at commit '%s'", notesRefName, notesCommitHash)
+	}
+	logrus.Debugf("Added a note to commit '%s' under notes ref '%s'", commitHash, notesRefName)
+	return nil
+}
+
+func (repo *gitRepository) ReadNote(notesRefName string, commitHash string) (string, bool, error) {
+	notesRef, err := repo.repository.Storer.Reference(plumbing.ReferenceName(notesRefName))
+	if err == plumbing.ErrReferenceNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, stacktrace.Propagate(err, "An error occurred getting notes ref '%s'", notesRefName)
+	}
+	// Git spreads the notes of repos with many of them over directories named after the first bytes of the hash
+	notePaths := []string{commitHash}
+	if len(commitHash) > noteFanoutPrefixLength {
+		notePaths = append(notePaths, commitHash[:noteFanoutPrefixLength]+"/"+commitHash[noteFanoutPrefixLength:])
+	}
+	for _, notePath := range notePaths {
+		note, found, err := repo.ReadFileAtCommit(notesRef.Hash().String(), notePath)
+		if err != nil {
+			return "", false, stacktrace.Propagate(err, "An error occurred reading the note of commit '%s' under notes ref '%s'", commitHash, notesRefName)
+		}
+		if found {
+			return string(note), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (repo *gitRepository) DeleteTag(tagName string) error {
+	// git tag -d
+	logrus.Debugf("Deleting tag '%s'", tagName)
+	if err := repo.repository.DeleteTag(tagName); err != nil {
+		return stacktrace.Propagate(err, "An error occurred deleting tag '%s'", tagName)
+	}
+	repo.invalidateTagIndex()
+	return nil
+}
+
+func (repo *gitRepository) SetRef(refName string, commitHash string) error {
+	ref := plumbing.NewHashReference(plumbing.ReferenceName(refName), plumbing.NewHash(commitHash))
+	logrus.Debugf("Pointing ref '%s' at commit '%s'", refName, commitHash)
+	if err := repo.repository.Storer.SetReference(ref); err != nil {
+		return stacktrace.Propagate(err, "An error occurred pointing ref '%s' at commit '%s'", refName, commitHash)
+	}
+	if strings.HasPrefix(refName, TagRefPrefix) {
+		repo.invalidateTagIndex()
+	}
+	return nil
+}
+
+func (repo *gitRepository) DeleteRef(refName string) error {
+	logrus.Debugf("Deleting ref '%s'", refName)
+	if err := repo.repository.Storer.RemoveReference(plumbing.ReferenceName(refName)); err != nil {
+		return stacktrace.Propagate(err, "An error occurred deleting ref '%s'", refName)
+	}
+	if strings.HasPrefix(refName, TagRefPrefix) {
+		repo.invalidateTagIndex()
+	}
+	return nil
+}
+
+func (repo *gitRepository) CheckPushAccess(ctx context.Context) error {
+	remoteUrls := repo.originRemote.Config().URLs
+	if len(remoteUrls) == 0 {
+		return stacktrace.NewError("Remote '%s' has no URL to push to", OriginRemoteName)
+	}
+	endpoint, err := transport.NewEndpoint(remoteUrls[0])
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing the URL '%s' of remote '%s'", remoteUrls[0], OriginRemoteName)
+	}
+	transportClient, err := client.NewClient(endpoint)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting a client for the URL '%s' of remote '%s'", remoteUrls[0], OriginRemoteName)
+	}
+	logrus.Debugf("Checking that remote '%s' accepts pushes with the token", OriginRemoteName)
+	// Opening a push session and reading the refs the remote advertises is as far as 'git push --dry-run' goes on the
+	// wire, and it's where the remote refuses a token that can't push
+	session, err := transportClient.NewReceivePackSession(endpoint, repo.auth)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred opening a push session to remote '%s'", OriginRemoteName)
+	}
+	defer session.Close()
+	if _, err := session.AdvertisedReferencesContext(ctx); err != nil {
+		if err == transport.ErrAuthenticationRequired || err == transport.ErrAuthorizationFailed {
+			return stacktrace.Propagate(err, "Remote '%s' refused to let the token push; it must have write access to the repo", OriginRemoteName)
+		}
+		return stacktrace.Propagate(err, "An error occurred starting a push to remote '%s'", OriginRemoteName)
+	}
+	logrus.Debugf("Remote '%s' accepts pushes with the token", OriginRemoteName)
+	return nil
+}
+
+func (repo *gitRepository) Push(ctx context.Context, refSpecs ...string) error {
+	pushOpts := &git.PushOptions{
+		RemoteName: OriginRemoteName,
+		Auth:       repo.auth,
+		Progress:   getTraceProgressWriter(),
+	}
+	for _, refSpec := range refSpecs {
+		pushOpts.RefSpecs = append(pushOpts.RefSpecs, config.RefSpec(refSpec))
+	}
+	logrus.Debugf("Pushing '%s' to remote '%s'", strings.Join(refSpecs, ", "), OriginRemoteName)
+	err := repo.repository.PushContext(ctx, pushOpts)
+	if err == git.NoErrAlreadyUpToDate {
+		logrus.Debugf("Remote '%s' was already up to date with '%s'", OriginRemoteName, strings.Join(refSpecs, ", "))
+		return nil
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred pushing '%s' to '%s'", strings.Join(refSpecs, ", "), OriginRemoteName)
+	}
+	logrus.Debugf("Pushed '%s' to remote '%s'", strings.Join(refSpecs, ", "), OriginRemoteName)
+	return nil
+}
+
+func (repo *gitRepository) PushWithLease(ctx context.Context, refSpec string, remoteRefName string, expectedRemoteHash string) error {
+	pushOpts := &git.PushOptions{
+		RemoteName:        OriginRemoteName,
+		RefSpecs:          []config.RefSpec{config.RefSpec(refSpec)},
+		Auth:              repo.auth,
+		RequireRemoteRefs: []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", expectedRemoteHash, remoteRefName))},
+		Progress:          getTraceProgressWriter(),
+	}
+	logrus.Debugf("Pushing '%s' to remote '%s' with '%s' required to be at '%s'", refSpec, OriginRemoteName, remoteRefName, expectedRemoteHash)
+	err := repo.repository.PushContext(ctx, pushOpts)
+	if err == git.NoErrAlreadyUpToDate {
+		logrus.Debugf("Remote '%s' was already up to date with '%s'", OriginRemoteName, refSpec)
+		return nil
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred pushing '%s' to '%s' with '%s' required to be at '%s'", refSpec, OriginRemoteName, remoteRefName, expectedRemoteHash)
+	}
+	logrus.Debugf("Pushed '%s' to remote '%s'", refSpec, OriginRemoteName)
+	return nil
+}
+
+func (repo *gitRepository) PushToUrl(ctx context.Context, remoteUrl string, token string, refSpecs ...string) error {
+	// The remote only exists for the push, so the repo's config is left alone
+	urlRemote := git.NewRemote(repo.repository.Storer, &config.RemoteConfig{
+		Name: urlRemoteName,
+		URLs: []string{remoteUrl},
+	})
+	pushOpts := &git.PushOptions{
+		RemoteName: urlRemoteName,
+		Auth: &http.BasicAuth{
+			Username: gitAuthUsername,
+			Password: token,
+		},
+		Progress: getTraceProgressWriter(),
+	}
+	for _, refSpec := range refSpecs {
+		pushOpts.RefSpecs = append(pushOpts.RefSpecs, config.RefSpec(refSpec))
+	}
+	logrus.Debugf("Pushing '%s' to '%s'", strings.Join(refSpecs, ", "), remoteUrl)
+	err := urlRemote.PushContext(ctx, pushOpts)
+	if err == git.NoErrAlreadyUpToDate {
+		logrus.Debugf("'%s' was already up to date with '%s'", remoteUrl, strings.Join(refSpecs, ", "))
+		return nil
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred pushing '%s' to '%s'", strings.Join(refSpecs, ", "), remoteUrl)
+	}
+	logrus.Debugf("Pushed '%s' to '%s'", strings.Join(refSpecs, ", "), remoteUrl)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// stageAll stages every change in the worktree that isn't ignored
+func stageAll(worktree *git.Worktree) error {
+	// we have to manually populate the excludes because of https://github.com/kurtosis-tech/kudet/issues/22
+	// we should remove this piece when the above issue & bigger go-git issue gets resolved
+	logrus.Debugf("Populating excludes for the worktree by parsing the .gitignore file")
+	gitIgnorePatterns, err := readGitIgnorePatterns(worktree.Filesystem, gitIgnoreRelFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while reading the '%v' file", gitIgnoreRelFilepath)
+	}
+	for _, pattern := range gitIgnorePatterns {
+		worktree.Excludes = append(worktree.Excludes, gitignore.ParsePattern(pattern, emptyDomain))
+	}
+
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return stacktrace.Propagate(err, "An error occurred while adding files to the staging area")
+	}
+	// Adding doesn't stage files that were deleted from the worktree, so they're removed from the index by hand
+	status, err := worktree.Status()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while trying to retrieve the status of the worktree")
+	}
+	for filepath, fileStatus := range status {
+		if fileStatus.Worktree != git.Deleted {
+			continue
+		}
+		if _, err := worktree.Remove(filepath); err != nil {
+			return stacktrace.Propagate(err, "An error occurred staging the deletion of '%s'", filepath)
+		}
+	}
+	return nil
+}
+
+func (repo *gitRepository) getCommitTree(commitHash string) (*object.Tree, error) {
+	commit, err := repo.repository.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting commit '%s'", commitHash)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the tree of commit '%s'", commitHash)
+	}
+	return tree, nil
+}
+
+// storeObject encodes the object into the repo's object database, returning its hash
+func (repo *gitRepository) storeObject(gitObject object.Object) (plumbing.Hash, error) {
+	encodedObject := repo.repository.Storer.NewEncodedObject()
+	if err := gitObject.Encode(encodedObject); err != nil {
+		return plumbing.ZeroHash, stacktrace.Propagate(err, "An error occurred encoding the object")
+	}
+	objectHash, err := repo.repository.Storer.SetEncodedObject(encodedObject)
+	if err != nil {
+		return plumbing.ZeroHash, stacktrace.Propagate(err, "An error occurred storing the object")
+	}
+	return objectHash, nil
+}
+
+func getTreeEntrySortKey(treeEntry object.TreeEntry) string {
+	if treeEntry.Mode == filemode.Dir {
+		return treeEntry.Name + "/"
+	}
+	return treeEntry.Name
+}
+
+// getRefHashesForDebugLog snapshots the repo's refs so that a fetch's updates can be logged, which is only worth the
+// cost when debug logs are shown
+func (repo *gitRepository) getRefHashesForDebugLog() map[string]string {
+	refHashes := map[string]string{}
+	if !logrus.IsLevelEnabled(logrus.DebugLevel) {
+		return refHashes
+	}
+	refs, err := repo.repository.References()
+	if err != nil {
+		logrus.Debugf("An error occurred listing the repo's refs; fetched ref updates won't be logged: %v", err)
+		return refHashes
+	}
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference {
+			refHashes[ref.Name().String()] = ref.Hash().String()
+		}
+		return nil
+	})
+	return refHashes
+}
+
+// logRefUpdates logs each ref that was created or moved since the snapshot, like the output of 'git fetch'
+func (repo *gitRepository) logRefUpdates(refHashesBefore map[string]string) {
+	refHashesAfter := repo.getRefHashesForDebugLog()
+	refNames := []string{}
+	for refName := range refHashesAfter {
+		refNames = append(refNames, refName)
+	}
+	sort.Strings(refNames)
+	for _, refName := range refNames {
+		oldHash, found := refHashesBefore[refName]
+		newHash := refHashesAfter[refName]
+		if !found {
+			logrus.Debugf("Fetched new ref '%s' at '%s'", refName, newHash)
+		} else if oldHash != newHash {
+			logrus.Debugf("Fetched ref '%s' from '%s' to '%s'", refName, oldHash, newHash)
+		}
+	}
+}
+
+// readGitIgnorePatterns returns the patterns of the given .gitignore file on the worktree filesystem, which may not exist
+func readGitIgnorePatterns(worktreeFilesystem billy.Filesystem, gitIgnoreFilepath string) ([]string, error) {
+	gitIgnoreFile, err := worktreeFilesystem.Open(gitIgnoreFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, stacktrace.Propagate(err, "An error occurred opening '%s'", gitIgnoreFilepath)
+	}
+	defer gitIgnoreFile.Close()
+
+	patterns := []string{}
+	gitIgnoreFileScanner := bufio.NewScanner(gitIgnoreFile)
+	// split the file by lines
+	gitIgnoreFileScanner.Split(bufio.ScanLines)
+	for gitIgnoreFileScanner.Scan() {
+		pattern := gitIgnoreFileScanner.Text()
+		if isWhiteSpaceOrComment(pattern) {
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	if err := gitIgnoreFileScanner.Err(); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred scanning '%s'", gitIgnoreFilepath)
+	}
+	return patterns, nil
+}
+
+func isWhiteSpaceOrComment(pattern string) bool {
+	if strings.HasPrefix(pattern, gitIgnoreCommentCharacter) {
+		return true
+	}
+	return strings.TrimSpace(pattern) == ""
+}
+
+// getTraceProgressWriter returns where the progress messages that the remote sends during fetches and pushes go, which
+// is nowhere unless trace logs are shown
+func getTraceProgressWriter() io.Writer {
+	if !logrus.IsLevelEnabled(logrus.TraceLevel) {
+		return nil
+	}
+	return traceLogWriter{}
+}
+
+// traceLogWriter logs each line written to it at trace level
+type traceLogWriter struct{}
+
+func (writer traceLogWriter) Write(progressMessages []byte) (int, error) {
+	// Remotes redraw progress lines in place with carriage returns
+	for _, progressMessage := range strings.FieldsFunc(string(progressMessages), isLineBreak) {
+		if strings.TrimSpace(progressMessage) != "" {
+			logrus.Tracef("remote: %s", progressMessage)
+		}
+	}
+	return len(progressMessages), nil
+}
+
+func isLineBreak(character rune) bool {
+	return character == '\n' || character == '\r'
+}
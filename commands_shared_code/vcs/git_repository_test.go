@@ -0,0 +1,391 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWhiteSpaceOrPattern_IdentifiesComment(t *testing.T) {
+	testCase := "# this is a comment"
+	require.True(t, isWhiteSpaceOrComment(testCase))
+}
+
+func TestIsWhiteSpaceOrPattern_IdentifiesPureWhiteSpaceAndNewLines(t *testing.T) {
+	testCases := []string{
+		" ",
+		"    ",
+		"\n  ",
+	}
+	for _, testCase := range testCases {
+		require.True(t, isWhiteSpaceOrComment(testCase))
+	}
+}
+
+func TestIsWhiteSpaceOrPattern_IdentifiesActuallyUsefulIgnores(t *testing.T) {
+	testCases := []string{
+		"kurtosis_version/kurtosis_version.go",
+		" long file with spaces around it ",
+		"*.pyc",
+	}
+	for _, testCase := range testCases {
+		require.False(t, isWhiteSpaceOrComment(testCase))
+	}
+}
+
+func TestReadGitIgnorePatterns_MissingFileHasNoPatterns(t *testing.T) {
+	patterns, err := readGitIgnorePatterns(memfs.New(), gitIgnoreRelFilepath)
+	require.NoError(t, err)
+	require.Empty(t, patterns)
+}
+
+func TestGitRepository_CommitsAndTagsInMemory(t *testing.T) {
+	storer := memory.NewStorage()
+	worktreeFilesystem := memfs.New()
+	gitRepository, err := git.Init(storer, worktreeFilesystem)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: OriginRemoteName, URLs: []string{"https://github.com/kurtosis-tech/kudet.git"}})
+	require.NoError(t, err)
+	repository, err := OpenGitRepositoryWithStorage(storer, worktreeFilesystem, "", "token")
+	require.NoError(t, err)
+
+	require.NoError(t, util.WriteFile(worktreeFilesystem, "docs/changelog.md", []byte("# TBD\n"), 0644))
+	require.NoError(t, util.WriteFile(worktreeFilesystem, gitIgnoreRelFilepath, []byte("dist/\n"), 0644))
+	require.NoError(t, util.WriteFile(worktreeFilesystem, "dist/kudet", []byte("binary"), 0644))
+	modifiedFilepaths, err := repository.GetModifiedFilepaths()
+	require.NoError(t, err)
+	require.Equal(t, []string{".gitignore", "docs/changelog.md"}, modifiedFilepaths)
+
+	commitHash, err := repository.CommitAll("Initial commit", &Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	isClean, _, err := repository.GetStatus()
+	require.NoError(t, err)
+	require.True(t, isClean)
+
+	require.NoError(t, repository.CreateTag("0.1.0", commitHash, "0.1.0"))
+	tagCommitHash, found, err := repository.GetTagCommitHash("0.1.0")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, commitHash, tagCommitHash)
+
+	// The tag is annotated, so its ref points at the tag object rather than the commit
+	tagRefHash, found, err := repository.GetRefHash(TagRefPrefix + "0.1.0")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.NotEqual(t, commitHash, tagRefHash)
+	_, found, err = repository.GetRefHash(TagRefPrefix + "0.2.0")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, util.WriteFile(worktreeFilesystem, "docs/changelog.md", []byte("# TBD\n* Change\n"), 0644))
+	nextCommitHash, err := repository.CommitAll("Next commit", &Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	isAncestor, err := repository.IsAncestor(commitHash, nextCommitHash)
+	require.NoError(t, err)
+	require.True(t, isAncestor)
+	isAncestor, err = repository.IsAncestor(nextCommitHash, commitHash)
+	require.NoError(t, err)
+	require.False(t, isAncestor)
+
+	changedFilepaths, err := repository.GetChangedFilepaths(commitHash, nextCommitHash)
+	require.NoError(t, err)
+	require.Equal(t, []string{"docs/changelog.md"}, changedFilepaths)
+	commitMessages, err := repository.GetCommitMessages(commitHash, nextCommitHash)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Next commit"}, commitMessages)
+	commits, err := repository.GetCommits(commitHash, nextCommitHash)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	require.Equal(t, nextCommitHash, commits[0].Hash)
+	require.Equal(t, "Next commit", commits[0].Message)
+	require.Equal(t, "Kudet", commits[0].Author.Name)
+	require.Equal(t, "kudet@example.com", commits[0].Author.Email)
+	require.Equal(t, []string{commitHash}, commits[0].ParentHashes)
+
+	_, found, err = repository.ReadNote(NotesRefPrefix+"kudet", commitHash)
+	require.NoError(t, err)
+	require.False(t, found)
+	author := &Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()}
+	require.NoError(t, repository.AddNote(NotesRefPrefix+"kudet", commitHash, "Released by Kudet\n", author))
+	require.NoError(t, repository.AddNote(NotesRefPrefix+"kudet", nextCommitHash, "Draft\n", author))
+	require.NoError(t, repository.AddNote(NotesRefPrefix+"kudet", nextCommitHash, "Released again\n", author))
+	for noteCommitHash, expectedNote := range map[string]string{commitHash: "Released by Kudet\n", nextCommitHash: "Released again\n"} {
+		note, found, err := repository.ReadNote(NotesRefPrefix+"kudet", noteCommitHash)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, expectedNote, note)
+	}
+
+	changelog, found, err := repository.ReadFileAtCommit(commitHash, "docs/changelog.md")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "# TBD\n", string(changelog))
+	_, found, err = repository.ReadFileAtCommit(commitHash, "dist/kudet")
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestGitRepository_GetLatestCommitAsOf(t *testing.T) {
+	storer := memory.NewStorage()
+	worktreeFilesystem := memfs.New()
+	gitRepository, err := git.Init(storer, worktreeFilesystem)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: OriginRemoteName, URLs: []string{"https://github.com/kurtosis-tech/kudet.git"}})
+	require.NoError(t, err)
+	repository, err := OpenGitRepositoryWithStorage(storer, worktreeFilesystem, "", "token")
+	require.NoError(t, err)
+
+	firstCommitTime := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, util.WriteFile(worktreeFilesystem, "docs/changelog.md", []byte("# TBD\n"), 0644))
+	firstCommitHash, err := repository.CommitAll("First commit", &Signature{Name: "Kudet", Email: "kudet@example.com", When: firstCommitTime})
+	require.NoError(t, err)
+	require.NoError(t, util.WriteFile(worktreeFilesystem, "docs/changelog.md", []byte("# TBD\n* Change\n"), 0644))
+	secondCommitHash, err := repository.CommitAll("Second commit", &Signature{Name: "Kudet", Email: "kudet@example.com", When: firstCommitTime.AddDate(0, 0, 7)})
+	require.NoError(t, err)
+
+	commitTime, err := repository.GetCommitTime(firstCommitHash)
+	require.NoError(t, err)
+	require.True(t, firstCommitTime.Equal(commitTime))
+
+	commitHash, found, err := repository.GetLatestCommitAsOf(secondCommitHash, firstCommitTime.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, firstCommitHash, commitHash)
+	commitHash, found, err = repository.GetLatestCommitAsOf(secondCommitHash, firstCommitTime.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, secondCommitHash, commitHash)
+	_, found, err = repository.GetLatestCommitAsOf(secondCommitHash, firstCommitTime.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestGitRepository_SignsAndVerifiesTags(t *testing.T) {
+	storer := memory.NewStorage()
+	worktreeFilesystem := memfs.New()
+	gitRepository, err := git.Init(storer, worktreeFilesystem)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: OriginRemoteName, URLs: []string{"https://github.com/kurtosis-tech/kudet.git"}})
+	require.NoError(t, err)
+	repository, err := OpenGitRepositoryWithStorage(storer, worktreeFilesystem, "", "token")
+	require.NoError(t, err)
+	require.NoError(t, util.WriteFile(worktreeFilesystem, "docs/changelog.md", []byte("# TBD\n"), 0644))
+	commitHash, err := repository.CommitAll("Initial commit", &Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+
+	trustedKey, trustedPrivateKey, trustedPublicKey := newArmoredTestKey(t)
+	_, _, untrustedPublicKey := newArmoredTestKey(t)
+	require.NoError(t, repository.CreateTag("0.1.0", commitHash, "0.1.0"))
+	require.NoError(t, repository.SetTagSigningKey(trustedPrivateKey))
+	require.NoError(t, repository.CreateTag("0.2.0", commitHash, "0.2.0"))
+
+	signingKeyId, err := repository.VerifyTagSignature("0.2.0", trustedPublicKey)
+	require.NoError(t, err)
+	require.Equal(t, trustedKey.PrimaryKey.KeyIdString(), signingKeyId)
+	_, err = repository.VerifyTagSignature("0.2.0", untrustedPublicKey)
+	require.ErrorContains(t, err, "isn't signed by any of the trusted keys")
+	_, err = repository.VerifyTagSignature("0.1.0", trustedPublicKey)
+	require.ErrorContains(t, err, "isn't signed")
+
+	require.Error(t, repository.SetTagSigningKey(trustedPublicKey))
+}
+
+func TestGitRepository_CheckPushAccess(t *testing.T) {
+	remoteDirpath := t.TempDir()
+	_, err := git.PlainInit(remoteDirpath, true)
+	require.NoError(t, err)
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: OriginRemoteName, URLs: []string{remoteDirpath}})
+	require.NoError(t, err)
+
+	repository, err := openGitRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, repository.CheckPushAccess(context.Background()))
+
+	require.NoError(t, os.RemoveAll(remoteDirpath))
+	require.Error(t, repository.CheckPushAccess(context.Background()))
+}
+
+func TestGitRepository_PushToUrl(t *testing.T) {
+	mirrorDirpath := t.TempDir()
+	mirrorRepository, err := git.PlainInit(mirrorDirpath, true)
+	require.NoError(t, err)
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := openGitRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte("# TBD\n"), 0644))
+	commitHash, err := repository.CommitAll("Initial commit", &Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.0", commitHash, "0.1.0"))
+
+	require.NoError(t, repository.PushToUrl(context.Background(), mirrorDirpath, "mirror-token", TagRefPrefix+"0.1.0:"+TagRefPrefix+"0.1.0"))
+	// Pushing what the mirror already has isn't an error
+	require.NoError(t, repository.PushToUrl(context.Background(), mirrorDirpath, "mirror-token", TagRefPrefix+"0.1.0:"+TagRefPrefix+"0.1.0"))
+	_, err = mirrorRepository.Tag("0.1.0")
+	require.NoError(t, err)
+	remotes, err := gitRepository.Remotes()
+	require.NoError(t, err)
+	require.Len(t, remotes, 1)
+}
+
+func TestGitRepository_IndexesTags(t *testing.T) {
+	remoteDirpath := t.TempDir()
+	_, err := git.PlainInit(remoteDirpath, true)
+	require.NoError(t, err)
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: OriginRemoteName, URLs: []string{remoteDirpath}})
+	require.NoError(t, err)
+	repository, err := openGitRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "changelog.md"), []byte("# TBD\n"), 0644))
+	commitHash, err := repository.CommitAll("Initial commit", &Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("0.1.0", commitHash, "0.1.0"))
+
+	tagNames, err := repository.ListTagNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"0.1.0"}, tagNames)
+	tagIndexFilepath := filepath.Join(repoDirpath, gitDirname, tagIndexDirname, tagIndexFilename)
+	require.FileExists(t, tagIndexFilepath)
+
+	// Tags made outside kudet, e.g. with 'git tag', change the tag refs that the index was built from
+	_, err = gitRepository.CreateTag("cli/v0.1.0", plumbing.NewHash(commitHash), nil)
+	require.NoError(t, err)
+	tagNames, err = repository.ListTagNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"0.1.0", "cli/v0.1.0"}, tagNames)
+
+	// Later runs reuse the index kept on disk while the tag refs are unchanged, which this one's made-up tag shows
+	storedIndex, err := readTagIndex(tagIndexFilepath)
+	require.NoError(t, err)
+	storedIndex.CommitHashesByTagName["0.2.0"] = commitHash
+	require.NoError(t, writeTagIndex(tagIndexFilepath, storedIndex))
+	laterRepository, err := openGitRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	tagCommitHash, found, err := laterRepository.GetTagCommitHash("0.2.0")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, commitHash, tagCommitHash)
+
+	// Fetching drops the index, since it may have changed the tags
+	require.NoError(t, gitRepository.Push(&git.PushOptions{RemoteName: OriginRemoteName}))
+	require.NoError(t, laterRepository.Fetch(context.Background()))
+	require.NoFileExists(t, tagIndexFilepath)
+	_, found, err = laterRepository.GetTagCommitHash("0.2.0")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, laterRepository.DeleteTag("cli/v0.1.0"))
+	tagNames, err = laterRepository.ListTagNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"0.1.0"}, tagNames)
+}
+
+func TestReadGitFileAtRevision(t *testing.T) {
+	baseRepoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(baseRepoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: OriginRemoteName, URLs: []string{t.TempDir()}})
+	require.NoError(t, err)
+	repository, err := openGitRepository(baseRepoDirpath, "")
+	require.NoError(t, err)
+	signature := &Signature{Name: "Kudet", Email: "kudet@example.com", When: time.Now()}
+	require.NoError(t, os.WriteFile(filepath.Join(baseRepoDirpath, "base.yml"), []byte("release-branch: main\n"), 0644))
+	firstCommitHash, err := repository.CommitAll("Initial commit", signature)
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateTag("v1", firstCommitHash, "v1"))
+	require.NoError(t, repository.SetRef(BranchRefPrefix+"stable", firstCommitHash))
+	require.NoError(t, os.WriteFile(filepath.Join(baseRepoDirpath, "base.yml"), []byte("release-branch: develop\n"), 0644))
+	secondCommitHash, err := repository.CommitAll("Switch branches", signature)
+	require.NoError(t, err)
+
+	contents, commitHash, err := ReadGitFileAtRevision(context.Background(), baseRepoDirpath, "", "v1", "base.yml")
+	require.NoError(t, err)
+	require.Equal(t, "release-branch: main\n", string(contents))
+	require.Equal(t, firstCommitHash, commitHash)
+
+	// Branches other than the default one are found among the clone's remote branches
+	_, commitHash, err = ReadGitFileAtRevision(context.Background(), baseRepoDirpath, "", "stable", "base.yml")
+	require.NoError(t, err)
+	require.Equal(t, firstCommitHash, commitHash)
+
+	contents, commitHash, err = ReadGitFileAtRevision(context.Background(), baseRepoDirpath, "", secondCommitHash, "base.yml")
+	require.NoError(t, err)
+	require.Equal(t, "release-branch: develop\n", string(contents))
+	require.Equal(t, secondCommitHash, commitHash)
+
+	_, _, err = ReadGitFileAtRevision(context.Background(), baseRepoDirpath, "", "v1", "missing.yml")
+	require.ErrorContains(t, err, "has no file")
+	_, _, err = ReadGitFileAtRevision(context.Background(), baseRepoDirpath, "", "v2", "base.yml")
+	require.Error(t, err)
+}
+
+func TestGitRepository_RunCommitHooks(t *testing.T) {
+	repoDirpath := t.TempDir()
+	gitRepository, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = gitRepository.CreateRemote(&config.RemoteConfig{Name: OriginRemoteName, URLs: []string{"https://github.com/kurtosis-tech/kudet.git"}})
+	require.NoError(t, err)
+	repository, err := openGitRepository(repoDirpath, "token")
+	require.NoError(t, err)
+
+	// Without hooks, the message is left as it is
+	message, err := repository.RunCommitHooks(context.Background(), "Release 1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, "Release 1.2.3", message)
+
+	repoConfig, err := gitRepository.Config()
+	require.NoError(t, err)
+	repoConfig.Raw.Section("core").SetOption("hooksPath", "githooks")
+	require.NoError(t, gitRepository.SetConfig(repoConfig))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDirpath, "githooks"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "githooks", "commit-msg"), []byte("#!/bin/sh\necho 'Refs: REL-1' >> \"$1\"\n"), 0755))
+	message, err = repository.RunCommitHooks(context.Background(), "Release 1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, "Release 1.2.3\nRefs: REL-1", message)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDirpath, "githooks", "pre-commit"), []byte("#!/bin/sh\necho 'no releases on Fridays'\nexit 1\n"), 0755))
+	_, err = repository.RunCommitHooks(context.Background(), "Release 1.2.3\n")
+	require.ErrorContains(t, err, "no releases on Fridays")
+}
+
+// newArmoredTestKey generates a PGP key, returning it along with its armored private and public keys
+func newArmoredTestKey(t *testing.T) (*openpgp.Entity, string, string) {
+	key, err := openpgp.NewEntity("Kudet", "", "kudet@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	require.NoError(t, err)
+
+	armoredPrivateKey := &bytes.Buffer{}
+	armorWriter, err := armor.Encode(armoredPrivateKey, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, key.SerializePrivate(armorWriter, nil))
+	require.NoError(t, armorWriter.Close())
+
+	armoredPublicKey := &bytes.Buffer{}
+	armorWriter, err = armor.Encode(armoredPublicKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, key.Serialize(armorWriter))
+	require.NoError(t, armorWriter.Close())
+	return key, armoredPrivateKey.String(), armoredPublicKey.String()
+}
@@ -0,0 +1,68 @@
+package vcs
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/kurtosis-tech/stacktrace"
+	"path/filepath"
+)
+
+const (
+	sandboxRemoteDirname = "origin.git"
+	sandboxRepoDirname   = "repo"
+
+	// The remote of the sandbox's stand-in origin that points back at the repo being cloned
+	sandboxSourceRemoteName = "source"
+
+	// The stand-in origin gets origin's branches as of the repo's last fetch, along with every tag the repo has
+	sandboxRemoteBranchesRefSpec = "+" + remoteBranchRefPrefix + "*:" + BranchRefPrefix + "*"
+	sandboxTagsRefSpec           = "+" + TagRefPrefix + "*:" + TagRefPrefix + "*"
+
+	remoteBranchRefPrefix = "refs/remotes/" + OriginRemoteName + "/"
+)
+
+// CloneGitSandbox clones the git repo into the sandbox directory on the given branch, along with a throwaway bare repo
+// that stands in for its origin, so that a release can be rehearsed end to end without pushing anything for real. The
+// path of the sandbox clone is returned; only committed changes make it into the sandbox.
+func CloneGitSandbox(ctx context.Context, repoDirpath string, sandboxDirpath string, branchName string) (string, error) {
+	absRepoDirpath, err := filepath.Abs(repoDirpath)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred getting the absolute path of '%s'", repoDirpath)
+	}
+
+	sandboxRemoteDirpath := filepath.Join(sandboxDirpath, sandboxRemoteDirname)
+	sandboxRemote, err := git.PlainInit(sandboxRemoteDirpath, true)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred creating the sandbox's stand-in origin at '%s'", sandboxRemoteDirpath)
+	}
+	sourceRemote, err := sandboxRemote.CreateRemote(&config.RemoteConfig{
+		Name: sandboxSourceRemoteName,
+		URLs: []string{absRepoDirpath},
+	})
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred pointing the sandbox's stand-in origin at '%s'", absRepoDirpath)
+	}
+	fetchOpts := &git.FetchOptions{
+		RemoteName: sandboxSourceRemoteName,
+		RefSpecs:   []config.RefSpec{sandboxRemoteBranchesRefSpec, sandboxTagsRefSpec},
+		Tags:       git.NoTags,
+		Progress:   getTraceProgressWriter(),
+	}
+	if err := sourceRemote.FetchContext(ctx, fetchOpts); err != nil && err != git.NoErrAlreadyUpToDate {
+		return "", stacktrace.Propagate(err, "An error occurred copying the branches and tags of '%s' into the sandbox", absRepoDirpath)
+	}
+
+	sandboxRepoDirpath := filepath.Join(sandboxDirpath, sandboxRepoDirname)
+	cloneOpts := &git.CloneOptions{
+		URL:           sandboxRemoteDirpath,
+		ReferenceName: plumbing.NewBranchReferenceName(branchName),
+		Tags:          git.AllTags,
+		Progress:      getTraceProgressWriter(),
+	}
+	if _, err := git.PlainCloneContext(ctx, sandboxRepoDirpath, false, cloneOpts); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred cloning branch '%s' into the sandbox; has it been pushed to '%s'?", branchName, OriginRemoteName)
+	}
+	return sandboxRepoDirpath, nil
+}
@@ -0,0 +1,199 @@
+package vcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// The index is kept in kudet's own directory inside '.git', where git leaves it alone
+	tagIndexDirname  = "kudet"
+	tagIndexFilename = "tag-index.json"
+	tagIndexDirMode  = 0755
+	tagIndexFileMode = 0644
+
+	packedRefsFilename = "packed-refs"
+	looseTagsDirpath   = "refs/tags"
+)
+
+// gitTagIndex is the commit that each of the repo's tags points at, which is slow to work out afresh for repos with
+// thousands of tags (annotated ones especially) on every command, so it's kept between runs
+type gitTagIndex struct {
+	// Identifies the tag refs that the index was built from, which is only reused while they're unchanged
+	Key string `json:"key"`
+
+	// Tags that couldn't be resolved, e.g. to commits missing from a shallow clone, are indexed with no commit
+	CommitHashesByTagName map[string]string `json:"commitHashesByTagName"`
+}
+
+// getTagIndex gets the index of the repo's tags, reusing the one kept in memory or on disk while the tag refs haven't
+// changed since it was built, and otherwise rebuilding it; repos that aren't on disk are indexed for each call
+func (repo *gitRepository) getTagIndex() (*gitTagIndex, error) {
+	if repo.gitDirpath == "" {
+		return repo.buildTagIndex("")
+	}
+	key, err := getTagRefsKey(repo.gitDirpath)
+	if err != nil {
+		// e.g. in linked worktrees, whose '.git' is a file pointing at the actual git directory
+		logrus.Debugf("Not using the tag index, as an error occurred checking whether the tags changed: %v", err)
+		return repo.buildTagIndex("")
+	}
+	if repo.tagIndex != nil && repo.tagIndex.Key == key {
+		return repo.tagIndex, nil
+	}
+	tagIndexFilepath := repo.getTagIndexFilepath()
+	if storedIndex, err := readTagIndex(tagIndexFilepath); err == nil && storedIndex.Key == key {
+		repo.tagIndex = storedIndex
+		return storedIndex, nil
+	}
+	tagIndex, err := repo.buildTagIndex(key)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred indexing the repo's tags")
+	}
+	repo.tagIndex = tagIndex
+	// The index only saves time, so commands carry on without it
+	if err := writeTagIndex(tagIndexFilepath, tagIndex); err != nil {
+		logrus.Debugf("An error occurred writing the tag index to '%s': %v", tagIndexFilepath, err)
+	}
+	return tagIndex, nil
+}
+
+// invalidateTagIndex drops the index after kudet changed the tags, rather than relying on their files' modification
+// times having moved on
+func (repo *gitRepository) invalidateTagIndex() {
+	repo.tagIndex = nil
+	if repo.gitDirpath == "" {
+		return
+	}
+	if err := os.Remove(repo.getTagIndexFilepath()); err != nil && !os.IsNotExist(err) {
+		logrus.Debugf("An error occurred removing the tag index: %v", err)
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func (repo *gitRepository) getTagIndexFilepath() string {
+	return filepath.Join(repo.gitDirpath, tagIndexDirname, tagIndexFilename)
+}
+
+func (repo *gitRepository) buildTagIndex(key string) (*gitTagIndex, error) {
+	tagRefs, err := repo.repository.Tags()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while retrieving tags for repository.")
+	}
+	commitHashesByTagName := map[string]string{}
+	err = tagRefs.ForEach(func(tagRef *plumbing.Reference) error {
+		tagName := strings.TrimPrefix(tagRef.Name().String(), TagRefPrefix)
+		commitHash, err := repo.repository.ResolveRevision(plumbing.Revision(tagRef.Name()))
+		if err != nil {
+			// Left for GetTagCommitHash to report, if anything asks for the tag
+			logrus.Debugf("Not indexing the commit of tag '%s', as an error occurred resolving it: %v", tagName, err)
+			commitHashesByTagName[tagName] = ""
+			return nil
+		}
+		commitHashesByTagName[tagName] = commitHash.String()
+		return nil
+	})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while iterating through tagrefs in the repository.")
+	}
+	return &gitTagIndex{Key: key, CommitHashesByTagName: commitHashesByTagName}, nil
+}
+
+// getTagRefsKey identifies the state of the tag refs by the modification time and size of 'packed-refs', along with
+// the number of loose tag refs and the latest modification time among them, since git writes new and fetched tags as
+// loose refs until they're packed
+func getTagRefsKey(gitDirpath string) (string, error) {
+	packedRefsModTime := int64(0)
+	packedRefsSize := int64(0)
+	packedRefsInfo, err := os.Stat(filepath.Join(gitDirpath, packedRefsFilename))
+	if err != nil && !os.IsNotExist(err) {
+		return "", stacktrace.Propagate(err, "An error occurred checking the packed refs")
+	}
+	if err == nil {
+		packedRefsModTime = packedRefsInfo.ModTime().UnixNano()
+		packedRefsSize = packedRefsInfo.Size()
+	}
+	looseTagCount := 0
+	latestLooseTagModTime := int64(0)
+	err = filepath.WalkDir(filepath.Join(gitDirpath, filepath.FromSlash(looseTagsDirpath)), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		entryInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		// Directories' modification times change as tags are deleted from them
+		if modTime := entryInfo.ModTime().UnixNano(); modTime > latestLooseTagModTime {
+			latestLooseTagModTime = modTime
+		}
+		if !entry.IsDir() {
+			looseTagCount++
+		}
+		return nil
+	})
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred checking the loose tag refs")
+	}
+	return fmt.Sprintf("%d-%d-%d-%d", packedRefsModTime, packedRefsSize, looseTagCount, latestLooseTagModTime), nil
+}
+
+func readTagIndex(tagIndexFilepath string) (*gitTagIndex, error) {
+	tagIndexBytes, err := os.ReadFile(tagIndexFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the tag index at '%s'", tagIndexFilepath)
+	}
+	tagIndex := &gitTagIndex{}
+	if err := json.Unmarshal(tagIndexBytes, tagIndex); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the tag index at '%s'", tagIndexFilepath)
+	}
+	if tagIndex.CommitHashesByTagName == nil {
+		return nil, stacktrace.NewError("The tag index at '%s' has no tags", tagIndexFilepath)
+	}
+	return tagIndex, nil
+}
+
+// writeTagIndex writes the index through a temporary file, so that a command reading it concurrently never sees it
+// half-written
+func writeTagIndex(tagIndexFilepath string, tagIndex *gitTagIndex) error {
+	tagIndexBytes, err := json.Marshal(tagIndex)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the tag index")
+	}
+	if err := os.MkdirAll(filepath.Dir(tagIndexFilepath), tagIndexDirMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating the directory of the tag index")
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(tagIndexFilepath), tagIndexFilename+".*")
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating a temporary file for the tag index")
+	}
+	defer os.Remove(tempFile.Name())
+	_, writeErr := tempFile.Write(tagIndexBytes)
+	if closeErr := tempFile.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		return stacktrace.Propagate(writeErr, "An error occurred writing the tag index to '%s'", tempFile.Name())
+	}
+	if err := os.Chmod(tempFile.Name(), tagIndexFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred setting the permissions of the tag index")
+	}
+	if err := os.Rename(tempFile.Name(), tagIndexFilepath); err != nil {
+		return stacktrace.Propagate(err, "An error occurred moving the tag index into place at '%s'", tagIndexFilepath)
+	}
+	return nil
+}
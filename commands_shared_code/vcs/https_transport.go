@@ -0,0 +1,103 @@
+package vcs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+var httpProtocols = []string{"http", "https"}
+
+// HttpsTransportConfig is how kudet's HTTP(S) connections get through corporate networks, which often only let them out
+// through a proxy that inspects TLS with its own CA
+type HttpsTransportConfig struct {
+	// The URL of the proxy to connect through, e.g. 'http://proxy.example.com:3128'; if empty, the HTTPS_PROXY,
+	// HTTP_PROXY and NO_PROXY environment variables are respected
+	ProxyUrl string
+
+	// The path of a PEM file of CA certificates to trust on top of the system's
+	CaBundleFilepath string
+
+	// Whether to skip verifying servers' TLS certificates altogether, which leaves the token open to anyone in between
+	IsInsecureSkipVerify bool
+}
+
+// ConfigureHttpsTransport makes the git fetches and pushes over HTTP(S), along with the forge API and every other HTTP
+// request kudet makes, go through the given proxy and trust the given CAs
+func ConfigureHttpsTransport(config HttpsTransportConfig) error {
+	transport, err := newHttpTransport(config)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred building the HTTP transport")
+	}
+	if config.IsInsecureSkipVerify {
+		logrus.Warnf("!!! TLS certificates are NOT being verified: anyone between kudet and the remote can read and tamper with the pushes, and steal the token. Only use this to diagnose a broken CA setup, never for real releases !!!")
+	}
+	http.DefaultTransport = transport
+	httpClient := &http.Client{Transport: transport}
+	for _, protocol := range httpProtocols {
+		client.InstallProtocol(protocol, githttp.NewClient(httpClient))
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newHttpTransport(config HttpsTransportConfig) (*http.Transport, error) {
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, stacktrace.NewError("The default HTTP transport was unexpectedly replaced with a '%T'", http.DefaultTransport)
+	}
+	transport := defaultTransport.Clone()
+	if config.ProxyUrl != "" {
+		proxyUrl, err := url.Parse(config.ProxyUrl)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred parsing proxy URL '%s'", config.ProxyUrl)
+		}
+		if proxyUrl.Scheme == "" || proxyUrl.Host == "" {
+			return nil, stacktrace.NewError("Proxy URL '%s' needs a scheme and a host, e.g. 'http://proxy.example.com:3128'", config.ProxyUrl)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	} else {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+	tlsConfig := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	if config.CaBundleFilepath != "" {
+		rootCAs, err := getRootCAs(config.CaBundleFilepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred loading the CA bundle at '%s'", config.CaBundleFilepath)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	tlsConfig.InsecureSkipVerify = config.IsInsecureSkipVerify
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// getRootCAs adds the bundle's CAs to the system's, so that public servers stay trusted alongside the corporate ones
+func getRootCAs(caBundleFilepath string) (*x509.CertPool, error) {
+	caBundleBytes, err := os.ReadFile(caBundleFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the CA bundle")
+	}
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		logrus.Debugf("Trusting only the CA bundle's CAs, as an error occurred loading the system's: %v", err)
+		rootCAs = x509.NewCertPool()
+	}
+	if !rootCAs.AppendCertsFromPEM(caBundleBytes) {
+		return nil, stacktrace.NewError("The CA bundle contains no PEM-encoded certificates")
+	}
+	return rootCAs, nil
+}
@@ -0,0 +1,53 @@
+package vcs
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHttpTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	caBundleFilepath := filepath.Join(t.TempDir(), "ca.pem")
+	caBundleBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caBundleFilepath, caBundleBytes, 0644))
+
+	// The test server's self-signed certificate is only trusted once it's in the bundle
+	transport, err := newHttpTransport(HttpsTransportConfig{})
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	require.Error(t, err)
+
+	transport, err = newHttpTransport(HttpsTransportConfig{CaBundleFilepath: caBundleFilepath})
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	transport, err = newHttpTransport(HttpsTransportConfig{IsInsecureSkipVerify: true})
+	require.NoError(t, err)
+	resp, err = (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	transport, err = newHttpTransport(HttpsTransportConfig{ProxyUrl: "http://proxy.example.com:3128"})
+	require.NoError(t, err)
+	proxyUrl, err := transport.Proxy(httptest.NewRequest(http.MethodGet, "https://github.com", nil))
+	require.NoError(t, err)
+	require.Equal(t, "proxy.example.com:3128", proxyUrl.Host)
+
+	_, err = newHttpTransport(HttpsTransportConfig{ProxyUrl: "proxy.example.com"})
+	require.ErrorContains(t, err, "needs a scheme and a host")
+	require.NoError(t, os.WriteFile(caBundleFilepath, []byte("not a certificate"), 0644))
+	_, err = newHttpTransport(HttpsTransportConfig{CaBundleFilepath: caBundleFilepath})
+	require.ErrorContains(t, err, "no PEM-encoded certificates")
+}
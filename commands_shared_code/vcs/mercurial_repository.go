@@ -0,0 +1,187 @@
+package vcs
+
+import (
+	"context"
+	"github.com/kurtosis-tech/stacktrace"
+	"path/filepath"
+	"time"
+)
+
+const (
+	mercurialDirname = ".hg"
+)
+
+// mercurialRepository is a placeholder Repository for Mercurial repos, which lets them be detected and fail with a clear
+// error; each operation gets implemented (e.g. by shelling out to 'hg') as the release flow is brought up on Mercurial
+type mercurialRepository struct {
+	repoDirpath string
+}
+
+func openMercurialRepository(repoDirpath string, token string) (Repository, error) {
+	return &mercurialRepository{repoDirpath: repoDirpath}, nil
+}
+
+func (repo *mercurialRepository) GetMetadataDirpath() string {
+	return filepath.Join(repo.repoDirpath, mercurialDirname)
+}
+
+// SetToken does nothing, since no remote operations are supported yet
+func (repo *mercurialRepository) SetToken(token string) {
+}
+
+func (repo *mercurialRepository) GetAuthor() (*Signature, error) {
+	return nil, newMercurialNotSupportedError("getting the author")
+}
+
+func (repo *mercurialRepository) GetStatus() (bool, string, error) {
+	return false, "", newMercurialNotSupportedError("getting the status")
+}
+
+func (repo *mercurialRepository) GetModifiedFilepaths() ([]string, error) {
+	return nil, newMercurialNotSupportedError("listing modified files")
+}
+
+func (repo *mercurialRepository) GetRemoteUrl() (string, error) {
+	return "", newMercurialNotSupportedError("getting the remote URL")
+}
+
+func (repo *mercurialRepository) Fetch(ctx context.Context) error {
+	return newMercurialNotSupportedError("pulling")
+}
+
+func (repo *mercurialRepository) ListRemoteRefs(ctx context.Context) (map[string]string, error) {
+	return nil, newMercurialNotSupportedError("listing remote refs")
+}
+
+func (repo *mercurialRepository) ResolveRevision(revision string) (string, error) {
+	return "", newMercurialNotSupportedError("resolving revisions")
+}
+
+func (repo *mercurialRepository) CheckoutBranch(branchName string) error {
+	return newMercurialNotSupportedError("updating to a branch")
+}
+
+func (repo *mercurialRepository) ResetHard(commitHash string) error {
+	return newMercurialNotSupportedError("reverting changes")
+}
+
+func (repo *mercurialRepository) RunCommitHooks(ctx context.Context, message string) (string, error) {
+	return "", newMercurialNotSupportedError("running commit hooks")
+}
+
+func (repo *mercurialRepository) CommitAll(message string, author *Signature) (string, error) {
+	return "", newMercurialNotSupportedError("committing")
+}
+
+func (repo *mercurialRepository) GetHeadCommitHash() (string, error) {
+	return "", newMercurialNotSupportedError("getting the working directory's parent")
+}
+
+func (repo *mercurialRepository) ListTagNames() ([]string, error) {
+	return nil, newMercurialNotSupportedError("listing tags")
+}
+
+func (repo *mercurialRepository) GetTagCommitHash(tagName string) (string, bool, error) {
+	return "", false, newMercurialNotSupportedError("resolving tags")
+}
+
+func (repo *mercurialRepository) CreateTag(tagName string, commitHash string, message string) error {
+	return newMercurialNotSupportedError("tagging")
+}
+
+func (repo *mercurialRepository) GetRefHash(refName string) (string, bool, error) {
+	return "", false, newMercurialNotSupportedError("resolving refs")
+}
+
+func (repo *mercurialRepository) GetChangedFilepaths(fromCommitHash string, toCommitHash string) ([]string, error) {
+	return nil, newMercurialNotSupportedError("diffing revisions")
+}
+
+func (repo *mercurialRepository) IsAncestor(ancestorCommitHash string, descendantCommitHash string) (bool, error) {
+	return false, newMercurialNotSupportedError("checking ancestry")
+}
+
+func (repo *mercurialRepository) GetCommitMessages(fromCommitHash string, toCommitHash string) ([]string, error) {
+	return nil, newMercurialNotSupportedError("reading commit messages")
+}
+
+func (repo *mercurialRepository) GetCommits(fromCommitHash string, toCommitHash string) ([]Commit, error) {
+	return nil, newMercurialNotSupportedError("listing commits")
+}
+
+func (repo *mercurialRepository) GetCommitTime(commitHash string) (time.Time, error) {
+	return time.Time{}, newMercurialNotSupportedError("getting commit times")
+}
+
+func (repo *mercurialRepository) GetLatestCommitAsOf(commitHash string, asOf time.Time) (string, bool, error) {
+	return "", false, newMercurialNotSupportedError("looking up past commits")
+}
+
+func (repo *mercurialRepository) ReadFileAtCommit(commitHash string, relFilepath string) ([]byte, bool, error) {
+	return nil, false, newMercurialNotSupportedError("reading past files")
+}
+
+func (repo *mercurialRepository) IsShallow() (bool, error) {
+	return false, newMercurialNotSupportedError("checking for shallow clones")
+}
+
+func (repo *mercurialRepository) Unshallow(ctx context.Context) error {
+	return newMercurialNotSupportedError("unshallowing")
+}
+
+func (repo *mercurialRepository) GetCheckedOutBranchName() (string, bool, error) {
+	return "", false, newMercurialNotSupportedError("getting the checked out branch")
+}
+
+func (repo *mercurialRepository) SetTagSigningKey(armoredSigningKey string) error {
+	return newMercurialNotSupportedError("signing tags")
+}
+
+func (repo *mercurialRepository) VerifyTagSignature(tagName string, armoredKeyRing string) (string, error) {
+	return "", newMercurialNotSupportedError("verifying tag signatures")
+}
+
+func (repo *mercurialRepository) DeleteTag(tagName string) error {
+	return newMercurialNotSupportedError("removing tags")
+}
+
+func (repo *mercurialRepository) AddNote(notesRefName string, commitHash string, note string, author *Signature) error {
+	return newMercurialNotSupportedError("adding notes")
+}
+
+func (repo *mercurialRepository) ReadNote(notesRefName string, commitHash string) (string, bool, error) {
+	return "", false, newMercurialNotSupportedError("reading notes")
+}
+
+func (repo *mercurialRepository) SetRef(refName string, commitHash string) error {
+	return newMercurialNotSupportedError("setting refs")
+}
+
+func (repo *mercurialRepository) DeleteRef(refName string) error {
+	return newMercurialNotSupportedError("deleting refs")
+}
+
+func (repo *mercurialRepository) CheckPushAccess(ctx context.Context) error {
+	return newMercurialNotSupportedError("checking push access")
+}
+
+func (repo *mercurialRepository) Push(ctx context.Context, refSpecs ...string) error {
+	return newMercurialNotSupportedError("pushing")
+}
+
+func (repo *mercurialRepository) PushWithLease(ctx context.Context, refSpec string, remoteRefName string, expectedRemoteHash string) error {
+	return newMercurialNotSupportedError("pushing")
+}
+
+func (repo *mercurialRepository) PushToUrl(ctx context.Context, remoteUrl string, token string, refSpecs ...string) error {
+	return newMercurialNotSupportedError("pushing")
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newMercurialNotSupportedError(operationDescription string) error {
+	return stacktrace.NewError("Mercurial repos are detected but not yet supported; %s isn't implemented", operationDescription)
+}
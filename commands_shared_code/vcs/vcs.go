@@ -0,0 +1,194 @@
+package vcs
+
+import (
+	"context"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// The remote that releases are fetched from and pushed to
+	OriginRemoteName = "origin"
+
+	TagRefPrefix    = "refs/tags/"
+	BranchRefPrefix = "refs/heads/"
+	NotesRefPrefix  = "refs/notes/"
+)
+
+// Signature is the identity that commits and tags are made as
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// Commit is a commit in the repo's history
+type Commit struct {
+	Hash    string
+	Message string
+	Author  Signature
+	// The first parent is the one that a merge was made onto; root commits have none
+	ParentHashes []string
+}
+
+// Repository is the set of version control operations that the release flow is built on, so that repos on version
+// control systems other than git can be released the same way
+type Repository interface {
+	// GetMetadataDirpath returns the VCS's own directory inside the repo (e.g. '.git'), where kudet keeps its local state
+	GetMetadataDirpath() string
+
+	// GetAuthor returns the configured identity of the operator, which is an error if it isn't set
+	GetAuthor() (*Signature, error)
+
+	// GetStatus reports whether the worktree has no staged or unstaged changes, along with a human-readable status
+	GetStatus() (bool, string, error)
+
+	// GetModifiedFilepaths lists the slash-separated paths, relative to the repo root, of files with staged or unstaged
+	// changes, including untracked files
+	GetModifiedFilepaths() ([]string, error)
+
+	GetRemoteUrl() (string, error)
+
+	// SetToken replaces the token that remote operations authenticate with, e.g. once a short-lived one is refreshed
+	SetToken(token string)
+
+	Fetch(ctx context.Context) error
+
+	// IsShallow reports whether the clone has truncated history, like the ones CI checkouts make by default
+	IsShallow() (bool, error)
+
+	// Unshallow fetches the full history of the remote's branches along with all of its tags
+	Unshallow(ctx context.Context) error
+
+	// ListRemoteRefs returns the hash of every ref on the remote, keyed by full ref name (e.g. 'refs/tags/1.2.3')
+	ListRemoteRefs(ctx context.Context) (map[string]string, error)
+
+	// ResolveRevision returns the commit hash that a branch, remote branch (e.g. 'origin/main'), or ref points at
+	ResolveRevision(revision string) (string, error)
+
+	// GetCheckedOutBranchName returns the name of the checked out branch, or false if HEAD is detached
+	GetCheckedOutBranchName() (string, bool, error)
+
+	CheckoutBranch(branchName string) error
+
+	// ResetHard discards all local changes and moves the current branch to the given commit
+	ResetHard(commitHash string) error
+
+	// RunCommitHooks runs the repo's own checks on a commit of every change in the worktree with the given message, which
+	// the VCS wouldn't run for commits made by kudet, returning the message as the hooks left it
+	RunCommitHooks(ctx context.Context, message string) (string, error)
+
+	// CommitAll commits every change in the worktree that isn't ignored, returning the new commit's hash
+	CommitAll(message string, author *Signature) (string, error)
+
+	GetHeadCommitHash() (string, error)
+
+	// ListTagNames lists the repo's tags sorted by name; the git implementation keeps an index of them between runs
+	ListTagNames() ([]string, error)
+
+	// GetTagCommitHash returns the commit the tag points at, or false if the tag doesn't exist
+	GetTagCommitHash(tagName string) (string, bool, error)
+
+	CreateTag(tagName string, commitHash string, message string) error
+
+	// SetTagSigningKey makes the tags created from then on signed with the armored, unencrypted PGP private key
+	SetTagSigningKey(armoredSigningKey string) error
+
+	// VerifyTagSignature checks that the tag is signed by one of the keys in the armored PGP keyring, returning the ID of
+	// the key that signed it; lightweight and unsigned tags fail the check like invalid signatures do
+	VerifyTagSignature(tagName string, armoredKeyRing string) (string, error)
+
+	// GetRefHash returns the hash that the full ref name points at without peeling it, so for annotated tags it's the tag
+	// object's rather than the commit's like on the remote, or false if the ref doesn't exist
+	GetRefHash(refName string) (string, bool, error)
+
+	// GetChangedFilepaths lists the slash-separated paths, relative to the repo root, of the files that differ between
+	// the two commits, including added, deleted and renamed files
+	GetChangedFilepaths(fromCommitHash string, toCommitHash string) ([]string, error)
+
+	// GetCommitMessages returns the messages of the commits in the second commit's history that aren't in the first's,
+	// like 'git log from..to'
+	GetCommitMessages(fromCommitHash string, toCommitHash string) ([]string, error)
+
+	// GetCommits returns the commits in the second commit's history that aren't in the first's, like 'git log from..to',
+	// latest first; without a first commit, it's the second commit's whole history
+	GetCommits(fromCommitHash string, toCommitHash string) ([]Commit, error)
+
+	// GetCommitTime returns when the commit was committed
+	GetCommitTime(commitHash string) (time.Time, error)
+
+	// GetLatestCommitAsOf returns the most recently committed of the commit and its ancestors that was committed at or
+	// before the given time, or false if they were all committed after it
+	GetLatestCommitAsOf(commitHash string, asOf time.Time) (string, bool, error)
+
+	// ReadFileAtCommit returns the contents that the file, given by its slash-separated path relative to the repo root,
+	// had in the commit, or false if it didn't exist then
+	ReadFileAtCommit(commitHash string, relFilepath string) ([]byte, bool, error)
+
+	// IsAncestor reports whether the first commit is the second one or one of its ancestors
+	IsAncestor(ancestorCommitHash string, descendantCommitHash string) (bool, error)
+
+	DeleteTag(tagName string) error
+
+	// AddNote attaches the note to the commit under the notes ref (e.g. 'refs/notes/kudet'), replacing any note the
+	// commit already has there, like 'git notes --ref <ref> add -f'
+	AddNote(notesRefName string, commitHash string, note string, author *Signature) error
+
+	// ReadNote returns the note attached to the commit under the notes ref, or false if it has none, like
+	// 'git notes --ref <ref> show'
+	ReadNote(notesRefName string, commitHash string) (string, bool, error)
+
+	// SetRef points the given full ref name (e.g. 'refs/kudet-embargo/1.2.3') at the commit, creating it if needed
+	SetRef(refName string, commitHash string) error
+
+	DeleteRef(refName string) error
+
+	// CheckPushAccess verifies that the remote accepts pushes authenticated with the token, like 'git push --dry-run',
+	// without pushing anything
+	CheckPushAccess(ctx context.Context) error
+
+	// Push pushes each of the given refspecs (e.g. 'refs/tags/1.2.3:refs/tags/1.2.3', or ':refs/tags/1.2.3' to delete)
+	// to the remote; refs that are already up to date are not an error
+	Push(ctx context.Context, refSpecs ...string) error
+
+	// PushWithLease pushes the refspec only if the given ref on the remote is still at the expected hash, like
+	// 'git push --force-with-lease', so that changes pushed by someone else in the meantime are never clobbered
+	PushWithLease(ctx context.Context, refSpec string, remoteRefName string, expectedRemoteHash string) error
+
+	// PushToUrl pushes the refspecs to the remote at the URL rather than to origin, authenticating with the given token,
+	// e.g. to update a mirror of the repo
+	PushToUrl(ctx context.Context, remoteUrl string, token string, refSpecs ...string) error
+}
+
+// backend is a version control system that kudet knows how to operate on
+type backend struct {
+	metadataDirname string
+	open            func(repoDirpath string, token string) (Repository, error)
+}
+
+var backends = []backend{
+	{metadataDirname: gitDirname, open: openGitRepository},
+	{metadataDirname: mercurialDirname, open: openMercurialRepository},
+}
+
+// OpenRepository opens the repo rooted at the given directory with whichever VCS it uses, authenticating remote
+// operations with the given token
+func OpenRepository(repoDirpath string, token string) (Repository, error) {
+	for _, candidateBackend := range backends {
+		metadataDirpath := filepath.Join(repoDirpath, candidateBackend.metadataDirname)
+		if _, err := os.Stat(metadataDirpath); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, stacktrace.Propagate(err, "An error occurred checking for '%s'", metadataDirpath)
+		}
+		repository, err := candidateBackend.open(repoDirpath, token)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred opening the repository at '%s'", repoDirpath)
+		}
+		return repository, nil
+	}
+	return nil, stacktrace.NewError("No supported repository was found at '%s'. This means that this binary is not being run from the root of a repository.", repoDirpath)
+}
@@ -0,0 +1,25 @@
+package vcs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenRepository_DetectsMercurial(t *testing.T) {
+	repoDirpath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(repoDirpath, mercurialDirname), 0755))
+
+	repository, err := OpenRepository(repoDirpath, "token")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(repoDirpath, mercurialDirname), repository.GetMetadataDirpath())
+	_, err = repository.GetAuthor()
+	require.ErrorContains(t, err, "Mercurial repos are detected but not yet supported")
+}
+
+func TestOpenRepository_NoRepository(t *testing.T) {
+	_, err := OpenRepository(t.TempDir(), "token")
+	require.ErrorContains(t, err, "No supported repository")
+}
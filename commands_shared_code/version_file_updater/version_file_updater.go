@@ -0,0 +1,110 @@
+package version_file_updater
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/file_line_matcher"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	versionRegexStr               = "[0-9A-Za-z_./-]+"
+	formatStrReplacementSubstr    = "%s"
+	expectedNumSearchPatternLines = 1
+	versionCaptureGroupName       = "version"
+)
+
+var versionRegex = regexp.MustCompile(versionRegexStr)
+
+// ValidatePatternFormatStr checks that the pattern format string has the '%s' where the version goes, and is a valid
+// regex once the version pattern is substituted in
+func ValidatePatternFormatStr(patternFormatStr string) error {
+	if !strings.Contains(patternFormatStr, formatStrReplacementSubstr) {
+		return stacktrace.NewError("The replacement substring '%s' was not found in the provided match regex '%s' as required.", formatStrReplacementSubstr, patternFormatStr)
+	}
+	searchPatternStr := fmt.Sprintf(patternFormatStr, versionRegexStr)
+	if _, err := regexp.Compile(searchPatternStr); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating regex pattern of '%s'", searchPatternStr)
+	}
+	return nil
+}
+
+// UpdateVersionInFile replaces the version on the single line of the file matching the pattern format string, whose
+// '%s' stands for the version
+func UpdateVersionInFile(toUpdateFilepath string, patternFormatStr string, newVersion string) error {
+	fileToUpdateInfo, err := os.Stat(toUpdateFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stacktrace.Propagate(err, "No file exists at '%s'", toUpdateFilepath)
+		}
+		return stacktrace.Propagate(err, "An error occurred attempting to retrieve file info for file at '%s'", toUpdateFilepath)
+	}
+	if err := ValidatePatternFormatStr(patternFormatStr); err != nil {
+		return stacktrace.Propagate(err, "The pattern format string '%s' is invalid", patternFormatStr)
+	}
+	if !versionRegex.Match([]byte(newVersion)) {
+		return stacktrace.NewError("The provided version '%s' does not match the version regex '%s'", newVersion, versionRegexStr)
+	}
+
+	fileToUpdateMode := fileToUpdateInfo.Mode()
+	fileToUpdateBytes, err := os.ReadFile(toUpdateFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to read file at '%s'", toUpdateFilepath)
+	}
+
+	searchPatternStr := fmt.Sprintf(patternFormatStr, versionRegexStr)
+	searchPatternRegex := regexp.MustCompile(searchPatternStr)
+
+	replaceValue := fmt.Sprintf(patternFormatStr, newVersion)
+
+	matcher := file_line_matcher.FileLineMatcher{}
+	numLines, err := matcher.MatchNumLines(toUpdateFilepath, searchPatternRegex)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while trying to count the number of occurrences of '%s' in '%s'", searchPatternStr, toUpdateFilepath)
+	}
+	if numLines != expectedNumSearchPatternLines {
+		return stacktrace.NewError("An incorrect amount, '%d' of lines matching '%s' was found in '%s'. '%d' matching lines were expected.", numLines, searchPatternStr, toUpdateFilepath, expectedNumSearchPatternLines)
+	}
+
+	// TODO This reads a file of arbitrary size into memory, file should be updated via streaming via Scanner instead
+	updatedFileBytes := replaceLinesMatchingPattern(fileToUpdateBytes, searchPatternRegex, replaceValue)
+
+	err = os.WriteFile(toUpdateFilepath, updatedFileBytes, fileToUpdateMode)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to right the updated file contents to '%s'", toUpdateFilepath)
+	}
+	return nil
+}
+
+// GetVersionInFile reads the version from the single line of the file matching the pattern format string, whose '%s'
+// stands for the version
+func GetVersionInFile(filepath string, patternFormatStr string) (string, error) {
+	if err := ValidatePatternFormatStr(patternFormatStr); err != nil {
+		return "", stacktrace.Propagate(err, "The pattern format string '%s' is invalid", patternFormatStr)
+	}
+	fileBytes, err := os.ReadFile(filepath)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred attempting to read file at '%s'", filepath)
+	}
+	version, err := findVersion(fileBytes, patternFormatStr)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred finding the version in '%s'", filepath)
+	}
+	return version, nil
+}
+
+func findVersion(file []byte, patternFormatStr string) (string, error) {
+	searchPatternStr := fmt.Sprintf(patternFormatStr, "(?P<"+versionCaptureGroupName+">"+versionRegexStr+")")
+	searchPatternRegex := regexp.MustCompile(searchPatternStr)
+	matches := searchPatternRegex.FindAllSubmatch(file, -1)
+	if len(matches) != expectedNumSearchPatternLines {
+		return "", stacktrace.NewError("An incorrect amount, '%d' of lines matching '%s' was found. '%d' matching lines were expected.", len(matches), searchPatternStr, expectedNumSearchPatternLines)
+	}
+	return string(matches[0][searchPatternRegex.SubexpIndex(versionCaptureGroupName)]), nil
+}
+
+func replaceLinesMatchingPattern(file []byte, regexPat *regexp.Regexp, replacement string) []byte {
+	return regexPat.ReplaceAll(file, []byte(replacement))
+}
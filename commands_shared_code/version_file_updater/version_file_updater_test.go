@@ -1,4 +1,4 @@
-package updateversioninfile
+package version_file_updater
 
 import (
 	"fmt"
@@ -111,3 +111,19 @@ func testRegexPattern(t *testing.T, regexPatternName string, regexPatternStr str
 		require.False(t, patternDetected, "%s Pattern was detected in this string when it should not have been: '%s'.", regexPatternName, str)
 	}
 }
+
+func TestFindVersion(t *testing.T) {
+	file := `# Pinned by kudet
+kudet: "1.4.0"
+other: "9.9.9"`
+
+	version, err := findVersion([]byte(file), `kudet: "%s"`)
+	require.NoError(t, err)
+	require.Equal(t, "1.4.0", version)
+
+	_, err = findVersion([]byte(file), `missing: "%s"`)
+	require.Error(t, err)
+
+	_, err = findVersion([]byte(file+"\nkudet: \"1.3.0\""), `kudet: "%s"`)
+	require.Error(t, err)
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"github.com/kurtosis-tech/kudet/commands"
 	"github.com/sirupsen/logrus"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -12,7 +16,24 @@ func main() {
 		FullTimestamp: true,
 	})
 
-	if err := commands.RootCmd.Execute(); err != nil {
+	// Interrupting cancels the context rather than killing the process, so that in-flight operations like releases get
+	// the chance to roll back what they've done
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signalChan
+		// Restore the default behaviour so that a second interrupt kills a rollback that's hanging
+		signal.Stop(signalChan)
+		logrus.Warnf("Interrupt received; cleaning up (interrupt again to exit immediately)...")
+		cancelFunc()
+	}()
+
+	startTime := time.Now()
+	executedCmd, err := commands.RootCmd.ExecuteContextC(ctx)
+	commands.ReportUsage(executedCmd, err, time.Since(startTime))
+	if err != nil {
 		// We don't actually need to print the error because Cobra will do it for us
 		os.Exit(1)
 	}
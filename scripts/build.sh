@@ -73,6 +73,8 @@ if "${should_publish_arg}"; then
 #     See the CI config for details on how these get set
       export FURY_TOKEN="${GEMFURY_PUBLISH_TOKEN}"
       export GITHUB_TOKEN="${KURTOSISBOT_GITHUB_TOKEN}"
+      # Only 'goreleaser release' signs, so snapshot builds don't need the signing key
+      export GPG_FINGERPRINT="${KUDET_RELEASE_GPG_FINGERPRINT}"
 fi
 # ^^^^^^^^ Goreleaser variables ^^^^^^^^^^^^^^^^^^^
 